@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/k8scontrol/backend/internal/api"
+	"github.com/k8scontrol/backend/internal/auth"
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/internal/config"
 	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/internal/vault"
+	"github.com/k8scontrol/backend/pkg/redact"
+	"github.com/k8scontrol/backend/pkg/ssh"
 )
 
 // @title K8S Control API
@@ -17,46 +25,156 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 func main() {
+	// 로그에 비밀번호, 토큰 등이 남지 않도록 로그 출력에서 비밀값을 가림
+	log.SetOutput(redact.Writer(os.Stderr))
+
 	// 환경 변수 로드
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	// 서버 포트 설정
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// 설정 로드 (환경 변수 > CONFIG_FILE의 YAML > 기본값), 잘못된 설정이면 시작하지 않음
+	cfg, err := config.Load("")
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
+	api.AllowedOrigins = cfg.Server.CORSOrigins
+	api.Features = cfg.Features
+	ssh.SetDefaultTimeout(cfg.SSH.ConnectTimeout.Duration)
+	ssh.SetAgentSocket(cfg.SSH.AgentSocket)
+	ssh.ConfigureDefaultPool(ssh.PoolConfig{
+		IdleTimeout:          cfg.SSH.PoolIdleTimeout.Duration,
+		KeepAliveInterval:    cfg.SSH.KeepAliveInterval.Duration,
+		MaxSessionsPerClient: cfg.SSH.MaxSessionsPerClient,
+		HealthCheckTimeout:   5 * time.Second,
+	})
+	command.SetDefaultCommandTimeout(cfg.SSH.CommandTimeout.Duration)
+	command.SetFanOutLimits(cfg.Concurrency.FanOutDefault, cfg.Concurrency.FanOutMax)
 
 	// 데이터베이스 연결
-	dbConn, err := db.Connect()
+	dbConn, err := db.Connect(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer dbConn.Close()
 
+	// migrate 하위 명령어는 마이그레이션만 실행하고 종료
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(dbConn, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// 스키마 마이그레이션 적용 (여러 레플리카가 동시에 시작해도 DB 잠금으로 하나씩 적용)
+	if cfg.Database.AutoMigrate {
+		if _, err := db.Migrate(dbConn); err != nil {
+			log.Fatalf("Failed to migrate database schema: %v", err)
+		}
+	} else if status, err := db.GetMigrationStatus(dbConn); err != nil {
+		log.Fatalf("Failed to check database schema: %v", err)
+	} else if len(status.Pending) > 0 {
+		log.Fatalf("Database schema is at version %d but %d is required; run \"%s migrate\"", status.Version, status.Latest, os.Args[0])
+	}
+
+	// SSH 호스트 키를 DB에 고정 (최초 접속 시 신뢰)
+	ssh.SetHostKeyStore(db.NewHostKeyStore(dbConn))
+
+	// 자격 증명 마스터 키 로드 후, 교체된 키로 다시 암호화하고 남아 있는 평문 비밀값을 옮김
+	// production은 설정 검증에서 마스터 키를 요구하므로, 키 없이 실행되는 것은 development뿐
+	if keyring, err := vault.LoadKeyring(cfg.Credentials.MasterKey, cfg.Credentials.MasterKeyFile); err != nil {
+		if cfg.Env == "production" {
+			log.Fatalf("Failed to load credential master key: %v", err)
+		}
+		log.Printf("Credential vault disabled, saving servers and services with secrets will fail: %v", err)
+	} else {
+		vault.SetDefault(keyring)
+		if rotated, err := db.RotateCredentialKeys(dbConn); err != nil {
+			log.Printf("Failed to rotate credential keys: %v", err)
+		} else if rotated > 0 {
+			log.Printf("Re-encrypted %d credentials with the current master key", rotated)
+		}
+		if _, err := db.MigratePlaintextCredentials(dbConn); err != nil {
+			log.Printf("Failed to migrate plaintext credentials: %v", err)
+		}
+	}
+	ssh.SetCredentialResolver(db.NewCredentialStore(dbConn))
+
+	// 비동기 작업 상태를 DB에 저장하고, 실행하던 인스턴스가 종료되어 heartbeat가 끊긴 작업은 중단으로 표시
+	// 다른 복제본이 실행 중인 작업은 heartbeat가 갱신되므로 그대로 둠
+	command.SetJobStore(db.NewJobStore(dbConn))
+	if _, err := command.DefaultJobManager().RecoverInterrupted(); err != nil {
+		log.Printf("Failed to mark interrupted jobs: %v", err)
+	}
+	heartbeatCtx, stopHeartbeat := context.WithCancel(context.Background())
+	defer stopHeartbeat()
+	go command.DefaultJobManager().RunHeartbeat(heartbeatCtx)
+	log.Printf("Job instance ID: %s", command.DefaultJobManager().InstanceID())
+
+	// JWT 서명 키 설정과 첫 사용자 생성
+	secret, generated, err := auth.LoadSecret(cfg.Auth.JWTSecret)
+	if err != nil {
+		log.Fatalf("Failed to prepare JWT secret: %v", err)
+	}
+	if generated {
+		log.Printf("auth.jwt_secret (JWT_SECRET) is not set; using a random secret (sessions end on restart)")
+	}
+	tokens := auth.NewManager(secret)
+	if created, err := api.EnsureInitialUser(dbConn, cfg.Auth.AdminUsername, cfg.Auth.AdminPassword); err != nil {
+		log.Printf("Initial user not created: %v", err)
+	} else if created {
+		log.Printf("Created initial user %s", cfg.Auth.AdminUsername)
+	}
+	if user, err := db.EnsureAdminRole(dbConn); err != nil {
+		log.Printf("Failed to check admin role: %v", err)
+	} else if user != nil {
+		log.Printf("Granted global admin role to %s", user.Username)
+	}
+
+	// 종료 시 공유 SSH 연결 풀 정리
+	defer ssh.DefaultPool().Close()
+
 	// Gin 라우터 설정
 	router := gin.Default()
 
 	// CORS 설정
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "https://kc.mipllab.com", "http://kc.mipllab.com"},
+		AllowOrigins:     api.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
-	// API 라우트 설정
-	api.SetupRoutes(router, dbConn)
-	api.InfraRoutes(router, dbConn)
-	api.InfraDockerRoutes(router, dbConn)
-	api.InfraKubernetesRoutes(router, dbConn)
-	api.ServerRoutes(router, dbConn)
+	// 요청 본문의 비밀값을 로그와 응답에서 가림
+	router.Use(api.RedactSecrets())
+
+	// /api/v1 경로는 로그인한 사용자나 API 토큰으로만 호출 가능
+	router.Use(api.Authenticate(tokens, dbConn))
+
+	// API 라우트 설정 (핸들러는 연결한 DB의 저장소를 사용)
+	repos := db.NewRepositories(dbConn)
+	api.AuthRoutes(router, dbConn, tokens)
+	api.SetupRoutes(router, dbConn, repos)
+	api.InfraRoutes(router, dbConn, repos)
+	api.InfraDockerRoutes(router, dbConn, repos)
+	api.InfraKubernetesRoutes(router, dbConn, repos)
+	api.ServerRoutes(router, dbConn, repos)
+	api.JobRoutes(router, dbConn)
+	api.ActionRoutes(router, dbConn)
+	api.CredentialRoutes(router, dbConn)
+	api.AuditRoutes(router, dbConn)
+	api.ConfigRoutes(router, dbConn, cfg)
 
 	// 서버 시작
-	log.Printf("Server running on port %s", port)
-	if err := router.Run(":" + port); err != nil {
+	if tls := cfg.Server.TLS; tls.Enabled() {
+		log.Printf("Server running on %s (TLS, %s)", cfg.Server.ListenAddr, cfg.Env)
+		err = router.RunTLS(cfg.Server.ListenAddr, tls.CertFile, tls.KeyFile)
+	} else {
+		log.Printf("Server running on %s (%s)", cfg.Server.ListenAddr, cfg.Env)
+		err = router.Run(cfg.Server.ListenAddr)
+	}
+	if err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
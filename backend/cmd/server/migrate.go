@@ -0,0 +1,63 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+const migrateUsage = `usage: server migrate [up | down <version> | status]
+  up              apply all pending migrations (default)
+  down <version>  revert applied migrations down to <version> (0 reverts everything)
+  status          print the current schema version and pending migrations`
+
+// runMigrate는 migrate 하위 명령어를 실행합니다
+func runMigrate(dbConn *sql.DB, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := db.Migrate(dbConn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+	case "down":
+		if len(args) != 2 {
+			return fmt.Errorf("target version is required\n%s", migrateUsage)
+		}
+		target, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid target version %q\n%s", args[1], migrateUsage)
+		}
+		reverted, err := db.MigrateDown(dbConn, target)
+		if err != nil {
+			return err
+		}
+		for _, m := range reverted {
+			fmt.Printf("Reverted %04d_%s\n", m.Version, m.Name)
+		}
+	case "status":
+		status, err := db.GetMigrationStatus(dbConn)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schema version: %d (latest %d)\n", status.Version, status.Latest)
+		for _, m := range status.Pending {
+			fmt.Printf("Pending %04d_%s\n", m.Version, m.Name)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", command, migrateUsage)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
+)
+
+// ActionHandler는 /kubernetes, /docker 엔드포인트에서 실행할 수 있는 액션과 파라미터 스키마를 제공합니다
+type ActionHandler struct {
+	DB       *sql.DB
+	managers map[string]*command.CommandManager // 카테고리별 명령어 관리자
+}
+
+// NewActionHandler 새 ActionHandler 생성
+func NewActionHandler(db *sql.DB) *ActionHandler {
+	kubernetes := command.NewCommandManager()
+	command.RegisterKubernetesCommands(kubernetes)
+
+	docker := command.NewCommandManager()
+	command.RegisterDockerCommands(docker)
+
+	return &ActionHandler{
+		DB: db,
+		managers: map[string]*command.CommandManager{
+			"kubernetes": kubernetes,
+			"docker":     docker,
+		},
+	}
+}
+
+// ListActions는 카테고리별로 등록된 모든 액션의 정보를 반환합니다
+func (h *ActionHandler) ListActions(c *gin.Context) {
+	actions := make(map[string][]command.ActionInfo, len(h.managers))
+	for category, manager := range h.managers {
+		actions[category] = manager.DescribeActions()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"actions": actions,
+	})
+}
+
+// GetAction은 액션 하나의 파라미터 스키마를 반환합니다
+func (h *ActionHandler) GetAction(c *gin.Context) {
+	manager, ok := h.managers[c.Param("category")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "지원하지 않는 카테고리입니다: " + c.Param("category")})
+		return
+	}
+
+	info, ok := manager.DescribeAction(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "지원하지 않는 액션입니다: " + c.Param("name")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"action":  info,
+	})
+}
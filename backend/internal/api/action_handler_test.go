@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
+)
+
+func TestActionRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ActionRoutes(router, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/actions", nil))
+	var list struct {
+		Success bool                            `json:"success"`
+		Actions map[string][]command.ActionInfo `json:"actions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || !list.Success {
+		t.Fatalf("액션 목록 응답이 올바르지 않습니다: %d %s", w.Code, w.Body.String())
+	}
+	if len(list.Actions["kubernetes"]) == 0 || len(list.Actions["docker"]) == 0 {
+		t.Errorf("kubernetes, docker 액션이 모두 필요합니다: %+v", list.Actions)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/actions/kubernetes/joinMaster", nil))
+	var detail struct {
+		Action command.ActionInfo `json:"action"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("액션 조회 실패: %d %s", w.Code, w.Body.String())
+	}
+	if !detail.Action.Steps || len(detail.Action.Params) == 0 {
+		t.Errorf("joinMaster는 단계 템플릿이며 파라미터 스키마가 있어야 합니다: %+v", detail.Action)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/actions/docker/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("없는 액션은 404여야 합니다: %d", w.Code)
+	}
+}
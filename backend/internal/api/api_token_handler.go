@@ -0,0 +1,165 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/auth"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+const (
+	// defaultAPITokenDays는 만료일을 지정하지 않은 API 토큰의 유효 기간(일)입니다
+	defaultAPITokenDays = 90
+	// maxAPITokenDays는 API 토큰의 최대 유효 기간(일)입니다
+	maxAPITokenDays = 365
+)
+
+// APITokenHandler API 토큰 관리 핸들러
+type APITokenHandler struct {
+	DB *sql.DB
+}
+
+// NewAPITokenHandler 새 APITokenHandler 생성
+func NewAPITokenHandler(db *sql.DB) *APITokenHandler {
+	return &APITokenHandler{DB: db}
+}
+
+// GetAPITokens 현재 사용자의 API 토큰 목록 조회 (토큰 원문 제외)
+// 전역 관리자는 all=true로 모든 사용자의 토큰을 조회할 수 있습니다
+func (h *APITokenHandler) GetAPITokens(c *gin.Context) {
+	user, _ := CurrentUser(c)
+	userID := user.ID
+	if all, _ := strconv.ParseBool(c.Query("all")); all {
+		if !h.isGlobalAdmin(c, user) {
+			return
+		}
+		userID = 0
+	}
+
+	tokens, err := db.GetAPITokens(h.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "tokens": tokens})
+}
+
+// CreateAPIToken 현재 사용자의 API 토큰 생성
+// 토큰 원문은 이 응답에서만 확인할 수 있습니다
+func (h *APITokenHandler) CreateAPIToken(c *gin.Context) {
+	var req struct {
+		Name          string   `json:"name"`
+		Actions       []string `json:"actions"`
+		InfraIDs      []int    `json:"infra_ids"`
+		ExpiresInDays int      `json:"expires_in_days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "잘못된 요청 형식입니다: " + err.Error()})
+		return
+	}
+
+	var actions []string
+	for _, action := range req.Actions {
+		if action = strings.TrimSpace(action); action != "" && !strings.Contains(action, ",") {
+			actions = append(actions, action)
+		}
+	}
+	if strings.TrimSpace(req.Name) == "" || len(actions) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "name과 actions는 필수입니다"})
+		return
+	}
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPITokenDays
+	}
+	if days < 0 || days > maxAPITokenDays {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "expires_in_days는 1에서 365 사이여야 합니다"})
+		return
+	}
+
+	plaintext, hash, prefix, err := auth.NewAPIToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "토큰 생성 실패: " + err.Error()})
+		return
+	}
+	user, _ := CurrentUser(c)
+	token := db.APIToken{
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		Actions:   actions,
+		InfraIDs:  req.InfraIDs,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+	id, err := db.CreateAPIToken(h.DB, token, hash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	log.Printf("[API 토큰] 생성: %s (사용자: %s, 액션: %v, 인프라: %v)", token.Name, user.Username, token.Actions, token.InfraIDs)
+	token.ID = id
+	token.Username = user.Username
+	if token.InfraIDs == nil {
+		token.InfraIDs = []int{}
+	}
+	c.JSON(http.StatusCreated, gin.H{"success": true, "token": plaintext, "api_token": token})
+}
+
+// RevokeAPIToken API 토큰 폐기. 전역 관리자는 다른 사용자의 토큰도 폐기할 수 있습니다
+func (h *APITokenHandler) RevokeAPIToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "유효하지 않은 토큰 ID입니다"})
+		return
+	}
+
+	user, _ := CurrentUser(c)
+	ownerID := user.ID
+	if bindings, err := userRoleBindings(h.DB, user); err == nil && effectiveRole(bindings, accessScope{}, false) == db.RoleAdmin {
+		ownerID = 0
+	}
+	if err := db.RevokeAPIToken(h.DB, id, ownerID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "토큰을 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	log.Printf("[API 토큰] 폐기: %d (사용자: %s)", id, user.Username)
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// isGlobalAdmin은 사용자가 전역 관리자인지 확인하고, 아니면 403 응답을 보냅니다
+func (h *APITokenHandler) isGlobalAdmin(c *gin.Context, user AuthUser) bool {
+	bindings, err := userRoleBindings(h.DB, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "권한 조회 실패: " + err.Error()})
+		return false
+	}
+	if effectiveRole(bindings, accessScope{}, false) != db.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "error": "전역 admin 역할이 필요합니다"})
+		return false
+	}
+	return true
+}
+
+// RequireSession은 API 토큰이 아닌 로그인 세션으로 인증한 요청만 허용하는 미들웨어입니다
+// 토큰 발급, 비밀번호 변경처럼 토큰이 유출되어도 권한이 넓어지지 않아야 하는 경로에 사용합니다
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user, ok := CurrentUser(c); ok && user.Token != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"success": false, "error": "API 토큰으로는 호출할 수 없습니다"})
+			return
+		}
+		c.Next()
+	}
+}
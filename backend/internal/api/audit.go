@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"path"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/redact"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// auditRecordKey는 요청의 감사 기록을 gin 컨텍스트에 저장하는 키입니다
+const auditRecordKey = "k8scontrol.audit_record"
+
+const (
+	// maxAuditParamsSize는 감사 기록에 남기는 요청 파라미터 JSON의 최대 크기입니다
+	maxAuditParamsSize = 64 << 10
+	// maxAuditResponseSize는 오류 메시지를 찾기 위해 보관하는 응답 본문의 최대 크기입니다
+	maxAuditResponseSize = 64 << 10
+)
+
+// auditRecord는 요청 하나의 감사 기록입니다. 요청 시작 시 요청자와 대상을 채우고, 응답 후 결과를 채워 저장합니다
+type auditRecord struct {
+	db     *sql.DB
+	entry  db.AuditEntry
+	dryRun bool
+}
+
+// Audit는 액션 이름별 경로(/infra/joinMaster 등)의 요청을 감사 기록으로 남기는 미들웨어입니다
+// 경로의 마지막 부분을 액션 이름으로, JSON 요청 본문을 파라미터로 기록합니다
+// 권한 거부도 기록하도록 RequireAction보다 먼저 등록해야 합니다. 감사 기록 기능이 꺼져 있으면 기록하지 않습니다
+func Audit(database *sql.DB) gin.HandlerFunc {
+	return auditMiddleware(database, false)
+}
+
+// AuditCommands는 단일 엔드포인트(/kubernetes, /docker, /service) 요청을 감사 기록으로 남기는 미들웨어입니다
+// 요청 본문의 action, parameters, dry_run 필드를 기록합니다
+func AuditCommands(database *sql.DB) gin.HandlerFunc {
+	return auditMiddleware(database, true)
+}
+
+func auditMiddleware(database *sql.DB, commandRequest bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Features.AuditLog {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		params := requestParams(c)
+		action := path.Base(c.FullPath())
+		dryRun := false
+		if commandRequest {
+			action, _ = params["action"].(string)
+			dryRun, _ = params["dry_run"].(bool)
+			params, _ = params["parameters"].(map[string]interface{})
+		}
+
+		// 삭제 액션은 실행 후 대상을 조회할 수 없으므로 실행 전에 대상을 찾음
+		// 범위 불일치는 권한 검사에서 거부되며, 감사 기록에는 대상의 실제 소속 인프라를 남김
+		scope, _ := resolveActionScope(database, action, params)
+		record := &auditRecord{db: database, dryRun: dryRun, entry: db.AuditEntry{
+			Route:     c.FullPath(),
+			Action:    action,
+			InfraID:   scope.InfraID,
+			ServerID:  scope.ServerID,
+			ServiceID: scope.ServiceID,
+			Params:    auditParams(params),
+			ClientIP:  c.ClientIP(),
+		}}
+		if user, ok := CurrentUser(c); ok {
+			record.entry.UserID = user.ID
+			record.entry.Username = user.Username
+			if user.Token != nil {
+				record.entry.TokenID = user.Token.ID
+			}
+		}
+		c.Set(auditRecordKey, record)
+
+		var commands int64
+		c.Request = c.Request.WithContext(ssh.WithOutputHandler(c.Request.Context(), countCommands(&commands)))
+		w := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		record.finish("", w.Status(), w.body.Bytes(), atomic.LoadInt64(&commands), time.Since(start))
+	}
+}
+
+// finish는 요청 결과를 채워 감사 기록을 저장합니다
+// 비동기 작업의 결과는 jobID를 채워 요청 기록과 별도의 기록으로 저장합니다
+func (r *auditRecord) finish(jobID string, status int, body []byte, commands int64, duration time.Duration) {
+	if r.db == nil {
+		return
+	}
+	entry := r.complete(jobID, status, body, commands, duration)
+	if err := db.InsertAuditEntry(r.db, entry); err != nil {
+		log.Printf("[감사 기록] 저장 실패 (액션: %s, 사용자: %s): %v", entry.Action, entry.Username, err)
+	}
+}
+
+// complete는 응답 상태와 본문으로 결과를 채운 감사 기록을 반환합니다
+func (r *auditRecord) complete(jobID string, status int, body []byte, commands int64, duration time.Duration) db.AuditEntry {
+	entry := r.entry
+	entry.HTTPStatus = status
+	entry.CommandCount = int(commands)
+	entry.DurationMs = duration.Milliseconds()
+
+	success, errMsg, responseJobID := auditResponse(body)
+	entry.Error = redact.String(errMsg)
+	entry.JobID = jobID
+	if entry.JobID == "" {
+		entry.JobID = responseJobID
+	}
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		entry.Status = db.AuditDenied
+	case status >= http.StatusBadRequest || !success:
+		entry.Status = db.AuditFailure
+	case status == http.StatusAccepted && jobID == "":
+		entry.Status = db.AuditAccepted
+	case r.dryRun:
+		entry.Status = db.AuditDryRun
+	default:
+		entry.Status = db.AuditSuccess
+	}
+	return entry
+}
+
+// currentAuditRecord는 요청의 감사 기록을 반환합니다. 감사 대상 경로가 아니면 nil입니다
+func currentAuditRecord(c *gin.Context) *auditRecord {
+	value, ok := c.Get(auditRecordKey)
+	if !ok {
+		return nil
+	}
+	record, _ := value.(*auditRecord)
+	return record
+}
+
+// countCommands는 원격 명령어가 시작될 때마다 count를 늘리는 OutputHandler를 생성합니다
+func countCommands(count *int64) ssh.OutputHandler {
+	return func(event ssh.OutputEvent) {
+		if event.Type == ssh.EventCommandStart {
+			atomic.AddInt64(count, 1)
+		}
+	}
+}
+
+// auditParams는 비밀값을 가린 파라미터 JSON을 반환합니다. 너무 크면 크기만 기록합니다
+func auditParams(params map[string]interface{}) json.RawMessage {
+	if len(params) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(redact.Params(params))
+	if err != nil {
+		return nil
+	}
+	if len(data) > maxAuditParamsSize {
+		data, _ = json.Marshal(gin.H{"truncated": true, "size": len(data)})
+	}
+	return data
+}
+
+// auditResponse는 JSON 응답의 success, error, job_id 필드를 읽습니다. success 필드가 없으면 성공으로 봅니다
+func auditResponse(body []byte) (success bool, errMsg, jobID string) {
+	var resp struct {
+		Success *bool           `json:"success"`
+		Error   json.RawMessage `json:"error"`
+		JobID   string          `json:"job_id"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return true, "", ""
+	}
+	if len(resp.Error) > 0 && string(resp.Error) != "null" {
+		if err := json.Unmarshal(resp.Error, &errMsg); err != nil {
+			errMsg = string(resp.Error)
+		}
+	}
+	return resp.Success == nil || *resp.Success, errMsg, resp.JobID
+}
+
+// auditWriter는 응답의 오류 메시지를 감사 기록에 남기기 위해 응답 본문 앞부분을 보관하는 gin.ResponseWriter입니다
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	if remaining := maxAuditResponseSize - w.body.Len(); remaining > 0 {
+		if len(data) > remaining {
+			w.body.Write(data[:remaining])
+		} else {
+			w.body.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
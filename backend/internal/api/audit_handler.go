@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+const (
+	// maxAuditPageSize는 감사 기록 조회 한 번에 반환하는 최대 개수입니다
+	maxAuditPageSize = 1000
+	// maxAuditExportSize는 감사 기록 내보내기 한 번에 포함하는 최대 개수입니다
+	maxAuditExportSize = 100000
+)
+
+// auditCSVHeader는 감사 기록 CSV 내보내기의 열 이름입니다
+var auditCSVHeader = []string{
+	"id", "created_at", "username", "user_id", "token_id", "client_ip", "route", "action",
+	"infra_id", "server_id", "service_id", "status", "http_status", "command_count", "duration_ms",
+	"job_id", "error", "params",
+}
+
+// AuditHandler 감사 기록 조회 핸들러
+type AuditHandler struct {
+	DB *sql.DB
+}
+
+// NewAuditHandler 새 AuditHandler 생성
+func NewAuditHandler(db *sql.DB) *AuditHandler {
+	return &AuditHandler{DB: db}
+}
+
+// GetAuditLog 조건에 맞는 감사 기록을 최근 순으로 조회
+// 쿼리 파라미터: user, action, infra_id, server_id, status, from, to (RFC3339 또는 2006-01-02), limit, offset
+// from은 그 시각을 포함하고 to는 포함하지 않습니다 (날짜만 지정한 to는 그날을 포함)
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	filter, err := parseAuditFilter(c, maxAuditPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	entries, err := db.GetAuditEntries(h.DB, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "entries": entries, "limit": filter.Limit, "offset": filter.Offset})
+}
+
+// ExportAuditLog 조건에 맞는 감사 기록을 파일로 내보냄 (format=csv(기본) 또는 json)
+func (h *AuditHandler) ExportAuditLog(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "format은 csv 또는 json이어야 합니다"})
+		return
+	}
+	filter, err := parseAuditFilter(c, maxAuditExportSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if c.Query("limit") == "" {
+		filter.Limit = maxAuditExportSize
+	}
+
+	entries, err := db.GetAuditEntries(h.DB, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if format == "json" {
+		c.JSON(http.StatusOK, entries)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := writeAuditCSV(c.Writer, entries); err != nil {
+		c.Error(err)
+	}
+}
+
+// writeAuditCSV는 감사 기록을 CSV로 씁니다
+func writeAuditCSV(w io.Writer, entries []db.AuditEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(auditCSVHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		record := []string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.Format(time.RFC3339Nano),
+			e.Username,
+			strconv.Itoa(e.UserID),
+			optionalID(e.TokenID),
+			e.ClientIP,
+			e.Route,
+			e.Action,
+			optionalID(e.InfraID),
+			optionalID(e.ServerID),
+			optionalID(e.ServiceID),
+			e.Status,
+			strconv.Itoa(e.HTTPStatus),
+			strconv.Itoa(e.CommandCount),
+			strconv.FormatInt(e.DurationMs, 10),
+			e.JobID,
+			e.Error,
+			string(e.Params),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// optionalID는 0이면 빈 문자열, 아니면 숫자 문자열을 반환합니다
+func optionalID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+// parseAuditFilter는 쿼리 파라미터를 감사 기록 조회 조건으로 변환합니다. limit은 maxLimit을 넘을 수 없습니다
+func parseAuditFilter(c *gin.Context, maxLimit int) (db.AuditFilter, error) {
+	filter := db.AuditFilter{
+		Username: c.Query("user"),
+		Action:   c.Query("action"),
+		Status:   c.Query("status"),
+		Limit:    db.DefaultAuditLimit,
+	}
+
+	ints := []struct {
+		name string
+		dest *int
+	}{
+		{"infra_id", &filter.InfraID},
+		{"server_id", &filter.ServerID},
+		{"limit", &filter.Limit},
+		{"offset", &filter.Offset},
+	}
+	for _, p := range ints {
+		value := c.Query(p.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return filter, fmt.Errorf("%s는 0 이상의 숫자여야 합니다", p.name)
+		}
+		*p.dest = n
+	}
+	if filter.Limit == 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from")); err != nil {
+		return filter, fmt.Errorf("from: %w", err)
+	}
+	if filter.To, err = parseAuditTime(c.Query("to")); err != nil {
+		return filter, fmt.Errorf("to: %w", err)
+	}
+	// 날짜만 지정한 to는 그날 전체를 포함
+	if len(c.Query("to")) == len("2006-01-02") {
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// parseAuditTime은 RFC3339 시각이나 날짜(2006-01-02, 서버 시간대의 자정)를 해석합니다. 빈 문자열은 zero 값입니다
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("RFC3339 시각 또는 YYYY-MM-DD 날짜여야 합니다: %s", value)
+	}
+	return t, nil
+}
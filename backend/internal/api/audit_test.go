@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/redact"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got db.AuditEntry
+	capture := func(c *gin.Context) {
+		got = currentAuditRecord(c).entry
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	}
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(authUserKey, testAdmin) })
+	router.POST("/api/v1/infra/joinWorker", Audit(nil), capture)
+	router.POST("/api/v1/kubernetes", AuditCommands(nil), capture)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		action string
+		want   db.AuditEntry
+	}{
+		{
+			"경로 액션", "/api/v1/infra/joinWorker",
+			`{"server_id": 5, "password": "s3cret", "hops": [{"host": "10.0.0.1"}]}`,
+			"joinWorker", db.AuditEntry{ServerID: 5},
+		},
+		{
+			"단일 엔드포인트", "/api/v1/kubernetes",
+			`{"action": "getPodLogs", "parameters": {"infra_id": 7, "password": "s3cret"}}`,
+			ActionGetPodLogs, db.AuditEntry{InfraID: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = db.AuditEntry{}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if got.Action != tt.action || got.InfraID != tt.want.InfraID || got.ServerID != tt.want.ServerID {
+				t.Errorf("entry = %+v", got)
+			}
+			if got.Username != testAdmin.Username || got.Route != tt.path {
+				t.Errorf("사용자, 경로 = %s, %s", got.Username, got.Route)
+			}
+			if strings.Contains(string(got.Params), "s3cret") {
+				t.Errorf("비밀값이 기록되었습니다: %s", got.Params)
+			}
+		})
+	}
+}
+
+func TestAuditRecordComplete(t *testing.T) {
+	tests := []struct {
+		name       string
+		dryRun     bool
+		jobID      string
+		status     int
+		body       string
+		wantStatus string
+		wantError  string
+		wantJobID  string
+	}{
+		{"성공", false, "", http.StatusOK, `{"success": true}`, db.AuditSuccess, "", ""},
+		{"실패 응답", false, "", http.StatusOK, `{"success": false, "error": "설치 실패"}`, db.AuditFailure, "설치 실패", ""},
+		{"오류 상태", false, "", http.StatusInternalServerError, `{"error": "password=hunter2 거부"}`, db.AuditFailure, "password=" + redact.Mask + " 거부", ""},
+		{"권한 거부", false, "", http.StatusForbidden, `{"success": false, "error": "권한 없음"}`, db.AuditDenied, "권한 없음", ""},
+		{"비동기 시작", false, "", http.StatusAccepted, `{"success": true, "job_id": "abc"}`, db.AuditAccepted, "", "abc"},
+		{"비동기 작업 결과", false, "abc", http.StatusOK, `{"success": true}`, db.AuditSuccess, "", "abc"},
+		{"드라이런", true, "", http.StatusOK, `{"success": true}`, db.AuditDryRun, "", ""},
+		{"JSON 아닌 응답", false, "", http.StatusOK, "plain text", db.AuditSuccess, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &auditRecord{dryRun: tt.dryRun, entry: db.AuditEntry{Action: "joinWorker"}}
+			entry := record.complete(tt.jobID, tt.status, []byte(tt.body), 3, 1500*time.Millisecond)
+			if entry.Status != tt.wantStatus || entry.JobID != tt.wantJobID || entry.HTTPStatus != tt.status {
+				t.Errorf("entry = %+v", entry)
+			}
+			if entry.Error != tt.wantError {
+				t.Errorf("Error = %q, want %q", entry.Error, tt.wantError)
+			}
+			if entry.CommandCount != 3 || entry.DurationMs != 1500 {
+				t.Errorf("CommandCount, DurationMs = %d, %d", entry.CommandCount, entry.DurationMs)
+			}
+		})
+	}
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parse := func(query string, maxLimit int) (db.AuditFilter, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/audit?"+query, nil)
+		return parseAuditFilter(c, maxLimit)
+	}
+
+	filter, err := parse("user=alice&action=deleteWorker&infra_id=3&status=failure&from=2026-10-01T00:00:00Z&to=2026-10-02&offset=20", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Username != "alice" || filter.Action != "deleteWorker" || filter.InfraID != 3 || filter.Status != db.AuditFailure || filter.Offset != 20 {
+		t.Errorf("filter = %+v", filter)
+	}
+	if filter.Limit != db.DefaultAuditLimit {
+		t.Errorf("Limit = %d, want %d", filter.Limit, db.DefaultAuditLimit)
+	}
+	if !filter.From.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("From = %v", filter.From)
+	}
+	// 날짜만 지정한 to는 그날 전체를 포함
+	if want := time.Date(2026, 10, 3, 0, 0, 0, 0, time.Local); !filter.To.Equal(want) {
+		t.Errorf("To = %v, want %v", filter.To, want)
+	}
+
+	if filter, _ := parse("limit=5000", 1000); filter.Limit != 1000 {
+		t.Errorf("Limit = %d, want 1000", filter.Limit)
+	}
+	for _, query := range []string{"infra_id=abc", "offset=-1", "from=yesterday"} {
+		if _, err := parse(query, 1000); err == nil {
+			t.Errorf("%s: 오류가 없습니다", query)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/auth"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+// authUserKey는 인증된 사용자를 gin 컨텍스트에 저장하는 키입니다
+const authUserKey = "k8scontrol.auth_user"
+
+// publicPaths는 로그인 없이 호출할 수 있는 /api/v1 경로입니다
+var publicPaths = map[string]bool{
+	"/api/v1/auth/login":   true,
+	"/api/v1/auth/refresh": true,
+}
+
+// AuthUser는 요청을 보낸 인증된 사용자입니다
+type AuthUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	// Roles가 nil이면 권한 검사 시 DB에서 사용자의 역할을 조회합니다
+	Roles []db.RoleBinding `json:"-"`
+	// Token은 API 토큰으로 인증한 경우 토큰의 허용 범위입니다 (사용자 세션이면 nil)
+	Token *TokenScope `json:"-"`
+}
+
+// TokenScope는 API 토큰으로 호출할 수 있는 액션과 인프라입니다
+// 토큰의 권한은 토큰 소유자의 역할을 넘지 않으며, 역할 확인과 함께 추가로 적용됩니다
+type TokenScope struct {
+	ID       int
+	Actions  []string
+	InfraIDs []int
+}
+
+// allows는 토큰으로 action을 scope 대상에 실행할 수 있는지 확인합니다
+// 인프라가 제한된 토큰은 대상 인프라를 알 수 없는 액션(목록 조회, 인프라 생성 등)을 호출할 수 없습니다
+func (t *TokenScope) allows(action string, scope accessScope) bool {
+	allowed := false
+	for _, a := range t.Actions {
+		if a == action {
+			allowed = true
+			break
+		}
+	}
+	if !allowed || len(t.InfraIDs) == 0 {
+		return allowed
+	}
+	for _, id := range t.InfraIDs {
+		if id == scope.InfraID {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate는 /api/v1 경로의 요청에 유효한 액세스 토큰이나 API 토큰이 있는지 확인하는 미들웨어입니다
+// 토큰은 "Authorization: Bearer <token>" 헤더로 전달하며, 헤더를 지정할 수 없는 SSE, WebSocket 구독(GET)은
+// access_token 쿼리 파라미터도 허용합니다. 로그인, 토큰 갱신 경로와 /api/v1 밖의 경로(swagger 등)는 검사하지 않습니다
+func Authenticate(tokens *auth.Manager, database *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, "/api/v1/") || publicPaths[path] || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		token := bearerToken(c)
+		if token == "" {
+			abortUnauthorized(c, "인증이 필요합니다")
+			return
+		}
+		if auth.IsAPIToken(token) {
+			user, msg := authenticateAPIToken(database, token)
+			if msg != "" {
+				abortUnauthorized(c, msg)
+				return
+			}
+			c.Set(authUserKey, user)
+			c.Next()
+			return
+		}
+		claims, err := tokens.Parse(token, auth.TokenAccess)
+		if err != nil {
+			msg := "유효하지 않은 인증 토큰입니다"
+			if errors.Is(err, auth.ErrExpiredToken) {
+				msg = "인증 토큰이 만료되었습니다"
+			}
+			abortUnauthorized(c, msg)
+			return
+		}
+
+		c.Set(authUserKey, AuthUser{ID: claims.UserID, Username: claims.Username})
+		c.Next()
+	}
+}
+
+// authenticateAPIToken은 API 토큰을 확인하고 토큰 소유자를 반환합니다. 실패하면 오류 메시지를 반환합니다
+func authenticateAPIToken(database *sql.DB, token string) (AuthUser, string) {
+	if database == nil {
+		return AuthUser{}, "API 토큰을 확인할 수 없습니다"
+	}
+	apiToken, err := db.GetAPITokenByHash(database, auth.HashAPIToken(token))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[API 토큰] 조회 실패: %v", err)
+		}
+		return AuthUser{}, "유효하지 않은 API 토큰입니다"
+	}
+	if apiToken.RevokedAt != nil {
+		return AuthUser{}, "폐기된 API 토큰입니다"
+	}
+	if time.Now().After(apiToken.ExpiresAt) {
+		return AuthUser{}, "만료된 API 토큰입니다"
+	}
+	if err := db.TouchAPIToken(database, apiToken.ID); err != nil {
+		log.Printf("[API 토큰] 마지막 사용 시간 갱신 실패: %v", err)
+	}
+
+	return AuthUser{
+		ID:       apiToken.UserID,
+		Username: apiToken.Username,
+		Token:    &TokenScope{ID: apiToken.ID, Actions: apiToken.Actions, InfraIDs: apiToken.InfraIDs},
+	}, ""
+}
+
+// bearerToken은 요청에서 액세스 토큰을 꺼냅니다
+func bearerToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+		return strings.TrimSpace(token)
+	}
+	if c.Request.Method == http.MethodGet {
+		return c.Query("access_token")
+	}
+	return ""
+}
+
+func abortUnauthorized(c *gin.Context, msg string) {
+	c.Header("WWW-Authenticate", `Bearer realm="k8scontrol"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "error": msg})
+}
+
+// CurrentUser는 요청을 보낸 인증된 사용자를 반환합니다
+func CurrentUser(c *gin.Context) (AuthUser, bool) {
+	value, ok := c.Get(authUserKey)
+	if !ok {
+		return AuthUser{}, false
+	}
+	user, ok := value.(AuthUser)
+	return user, ok
+}
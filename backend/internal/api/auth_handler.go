@@ -0,0 +1,344 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/auth"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+// AuthHandler 로그인, 토큰 갱신, 사용자 관리 API 핸들러
+type AuthHandler struct {
+	DB     *sql.DB
+	Tokens *auth.Manager
+}
+
+// NewAuthHandler 새 AuthHandler 생성
+func NewAuthHandler(db *sql.DB, tokens *auth.Manager) *AuthHandler {
+	return &AuthHandler{DB: db, Tokens: tokens}
+}
+
+// credentialsRequest는 로그인, 사용자 생성 요청 본문입니다
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Login 사용자 이름과 비밀번호로 로그인하고 액세스/리프레시 토큰 발급
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req credentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Username == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "username과 password는 필수입니다"})
+		return
+	}
+
+	user, err := db.GetUserByUsername(h.DB, req.Username)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	// 사용자가 없는 경우와 비밀번호가 틀린 경우를 구분하지 않음
+	if err == sql.ErrNoRows || !auth.CheckPassword(user.PasswordHash, req.Password) {
+		log.Printf("[로그인 실패] 사용자: %s, IP: %s", req.Username, c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "사용자 이름 또는 비밀번호가 올바르지 않습니다"})
+		return
+	}
+
+	if err := db.TouchUserLogin(h.DB, user.ID); err != nil {
+		log.Printf("[로그인] 마지막 로그인 시간 갱신 실패: %v", err)
+	}
+	log.Printf("[로그인] 사용자: %s, IP: %s", user.Username, c.ClientIP())
+	h.issueTokens(c, user)
+}
+
+// Refresh 리프레시 토큰으로 새 액세스/리프레시 토큰 발급
+// 사용한 리프레시 토큰은 폐기되므로 응답으로 받은 새 리프레시 토큰을 사용해야 합니다
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "refresh_token은 필수입니다"})
+		return
+	}
+
+	claims, err := h.Tokens.Parse(req.RefreshToken, auth.TokenRefresh)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err := db.ConsumeRefreshToken(h.DB, claims.Id, claims.UserID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrRefreshTokenInvalid) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(h.DB, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "사용자를 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	h.issueTokens(c, user)
+}
+
+// issueTokens는 액세스 토큰과 리프레시 토큰을 발급하고 리프레시 토큰 ID를 저장합니다
+func (h *AuthHandler) issueTokens(c *gin.Context, user db.User) {
+	accessToken, accessClaims, err := h.Tokens.Issue(user.ID, user.Username, auth.TokenAccess)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "토큰 발급 실패: " + err.Error()})
+		return
+	}
+	refreshToken, refreshClaims, err := h.Tokens.Issue(user.ID, user.Username, auth.TokenRefresh)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "토큰 발급 실패: " + err.Error()})
+		return
+	}
+	if err := db.SaveRefreshToken(h.DB, refreshClaims.Id, user.ID, time.Unix(refreshClaims.ExpiresAt, 0)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "토큰 저장 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"token_type":    "Bearer",
+		"access_token":  accessToken,
+		"expires_in":    accessClaims.ExpiresAt - accessClaims.IssuedAt,
+		"refresh_token": refreshToken,
+		"user":          user,
+	})
+}
+
+// Logout 사용자의 리프레시 토큰을 모두 폐기
+// 이미 발급된 액세스 토큰은 만료될 때까지 유효합니다
+func (h *AuthHandler) Logout(c *gin.Context) {
+	user, _ := CurrentUser(c)
+	if err := db.RevokeUserRefreshTokens(h.DB, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// Me 현재 로그인한 사용자 정보 조회
+func (h *AuthHandler) Me(c *gin.Context) {
+	current, _ := CurrentUser(c)
+	user, err := db.GetUserByID(h.DB, current.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "사용자를 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "user": user})
+}
+
+// ChangePassword 현재 사용자의 비밀번호 변경
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "잘못된 요청 형식입니다: " + err.Error()})
+		return
+	}
+
+	current, _ := CurrentUser(c)
+	user, err := db.GetUserByID(h.DB, current.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "현재 비밀번호가 올바르지 않습니다"})
+		return
+	}
+	hash, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err := db.UpdateUserPassword(h.DB, user.ID, hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// GetUsers 사용자 목록 조회
+func (h *AuthHandler) GetUsers(c *gin.Context) {
+	users, err := db.GetUsers(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "users": users})
+}
+
+// CreateUser 사용자 생성
+func (h *AuthHandler) CreateUser(c *gin.Context) {
+	var req credentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Username) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "username과 password는 필수입니다"})
+		return
+	}
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if _, err := db.GetUserByUsername(h.DB, req.Username); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "error": "이미 존재하는 사용자 이름입니다"})
+		return
+	}
+
+	id, err := db.CreateUser(h.DB, strings.TrimSpace(req.Username), hash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	user, err := db.GetUserByID(h.DB, id)
+	if err != nil {
+		c.JSON(http.StatusCreated, gin.H{"success": true, "id": id})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"success": true, "user": user})
+}
+
+// DeleteUser 사용자 삭제 (자기 자신은 삭제할 수 없음)
+func (h *AuthHandler) DeleteUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "유효하지 않은 사용자 ID입니다"})
+		return
+	}
+	if current, _ := CurrentUser(c); current.ID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "자기 자신은 삭제할 수 없습니다"})
+		return
+	}
+	if err := db.DeleteUser(h.DB, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "사용자를 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// GetRoles 역할 부여 목록 조회
+func (h *AuthHandler) GetRoles(c *gin.Context) {
+	bindings, err := db.GetRoleBindings(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "roles": bindings})
+}
+
+// GrantRole 사용자에게 역할 부여
+// infra_id나 service_id를 지정하면 해당 인프라, 서비스에만 적용되고, 둘 다 없으면 전역 역할이 됩니다
+func (h *AuthHandler) GrantRole(c *gin.Context) {
+	var req struct {
+		UserID    int    `json:"user_id"`
+		Role      string `json:"role"`
+		InfraID   int    `json:"infra_id"`
+		ServiceID int    `json:"service_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "잘못된 요청 형식입니다: " + err.Error()})
+		return
+	}
+	if !db.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "role은 viewer, operator, admin 중 하나여야 합니다"})
+		return
+	}
+	if req.InfraID != 0 && req.ServiceID != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "infra_id와 service_id는 함께 지정할 수 없습니다"})
+		return
+	}
+	if _, err := db.GetUserByID(h.DB, req.UserID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "사용자를 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	binding := db.RoleBinding{UserID: req.UserID, Role: req.Role, ScopeType: db.ScopeGlobal}
+	switch {
+	case req.InfraID != 0:
+		binding.ScopeType, binding.ScopeID = db.ScopeInfra, req.InfraID
+	case req.ServiceID != 0:
+		binding.ScopeType, binding.ScopeID = db.ScopeService, req.ServiceID
+	}
+	if err := db.GrantRole(h.DB, binding); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	current, _ := CurrentUser(c)
+	log.Printf("[역할 부여] %s → 사용자 %d: %s (%s %d)", current.Username, binding.UserID, binding.Role, binding.ScopeType, binding.ScopeID)
+	c.JSON(http.StatusOK, gin.H{"success": true, "role": binding})
+}
+
+// RevokeRole 역할 부여 삭제
+func (h *AuthHandler) RevokeRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "유효하지 않은 역할 ID입니다"})
+		return
+	}
+	if err := db.RevokeRole(h.DB, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "역할을 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// EnsureInitialUser는 사용자가 한 명도 없을 때 username, password로 첫 사용자를 전역 관리자로 생성합니다
+// 사용자가 이미 있으면 아무것도 하지 않으며, 생성했으면 true를 반환합니다
+func EnsureInitialUser(database *sql.DB, username, password string) (bool, error) {
+	count, err := db.CountUsers(database)
+	if err != nil || count > 0 {
+		return false, err
+	}
+	if username == "" || password == "" {
+		return false, errors.New("등록된 사용자가 없습니다. ADMIN_USERNAME, ADMIN_PASSWORD 환경 변수로 첫 사용자를 생성하세요")
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	id, err := db.CreateUser(database, username, hash)
+	if err != nil {
+		return false, err
+	}
+	// 첫 사용자는 전역 관리자
+	if err := db.GrantRole(database, db.RoleBinding{UserID: id, Role: db.RoleAdmin, ScopeType: db.ScopeGlobal}); err != nil {
+		return true, err
+	}
+	return true, nil
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/auth"
+)
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := auth.NewManager([]byte("test-secret"))
+
+	router := gin.New()
+	router.Use(Authenticate(tokens, nil))
+	router.GET("/api/v1/auth/me", func(c *gin.Context) {
+		user, _ := CurrentUser(c)
+		c.JSON(http.StatusOK, gin.H{"success": true, "user": user.Username})
+	})
+	router.POST("/api/v1/kubernetes", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"success": true}) })
+	router.POST("/api/v1/auth/login", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"success": true}) })
+	router.GET("/swagger/index.html", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	access, _, _ := tokens.Issue(1, "alice", auth.TokenAccess)
+	refresh, _, _ := tokens.Issue(1, "alice", auth.TokenRefresh)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		want   int
+	}{
+		{"토큰 없음", http.MethodPost, "/api/v1/kubernetes", "", http.StatusUnauthorized},
+		{"액세스 토큰", http.MethodPost, "/api/v1/kubernetes", "Bearer " + access, http.StatusOK},
+		{"리프레시 토큰은 거부", http.MethodPost, "/api/v1/kubernetes", "Bearer " + refresh, http.StatusUnauthorized},
+		{"Bearer 아닌 스킴", http.MethodPost, "/api/v1/kubernetes", "Basic " + access, http.StatusUnauthorized},
+		{"POST는 쿼리 토큰 불가", http.MethodPost, "/api/v1/kubernetes?access_token=" + access, "", http.StatusUnauthorized},
+		{"GET 쿼리 토큰", http.MethodGet, "/api/v1/auth/me?access_token=" + access, "", http.StatusOK},
+		{"로그인은 공개", http.MethodPost, "/api/v1/auth/login", "", http.StatusOK},
+		{"API 밖 경로", http.MethodGet, "/swagger/index.html", "", http.StatusOK},
+		{"DB 없이 API 토큰 불가", http.MethodPost, "/api/v1/kubernetes", "Bearer " + auth.APITokenPrefix + "abc", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/internal/vault"
+)
+
+// CredentialHandler 자격 증명 관련 API 핸들러
+// 비밀값은 요청으로만 받으며, 응답에는 저장된 비밀값의 이름만 포함합니다
+type CredentialHandler struct {
+	DB *sql.DB
+}
+
+// NewCredentialHandler 새 CredentialHandler 생성
+func NewCredentialHandler(db *sql.DB) *CredentialHandler {
+	return &CredentialHandler{DB: db}
+}
+
+// credentialRequest는 자격 증명 생성, 수정 요청 본문입니다
+type credentialRequest struct {
+	Name     string            `json:"name"`
+	Kind     string            `json:"kind"`
+	Username string            `json:"username"`
+	Secrets  map[string]string `json:"secrets"`
+}
+
+// GetCredentials 자격 증명 목록 조회 (비밀값 제외)
+func (h *CredentialHandler) GetCredentials(c *gin.Context) {
+	credentials, err := db.GetCredentials(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "credentials": credentials})
+}
+
+// CreateCredential 자격 증명 생성
+func (h *CredentialHandler) CreateCredential(c *gin.Context) {
+	var req credentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "잘못된 요청 형식입니다: " + err.Error()})
+		return
+	}
+	if req.Name == "" || len(req.Secrets) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "name과 secrets는 필수입니다"})
+		return
+	}
+	if req.Kind != db.CredentialSSH && req.Kind != db.CredentialGit {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "kind는 ssh 또는 git이어야 합니다"})
+		return
+	}
+
+	id, err := db.CreateCredential(h.DB, db.Credential{
+		Name:     req.Name,
+		Kind:     req.Kind,
+		Username: req.Username,
+		Secrets:  req.Secrets,
+	})
+	if err != nil {
+		c.JSON(credentialErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	cred, err := db.GetCredential(h.DB, id)
+	if err != nil {
+		c.JSON(http.StatusCreated, gin.H{"success": true, "id": id})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"success": true, "credential": cred})
+}
+
+// UpdateCredential 자격 증명 수정
+// secrets를 생략하면 이름과 사용자 이름만 바꾸고 저장된 비밀값은 유지합니다
+func (h *CredentialHandler) UpdateCredential(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "유효하지 않은 자격 증명 ID입니다"})
+		return
+	}
+	var req credentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "잘못된 요청 형식입니다: " + err.Error()})
+		return
+	}
+
+	existing, err := db.GetCredential(h.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "자격 증명을 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if req.Name != "" {
+		existing.Name = req.Name
+	}
+	if req.Username != "" {
+		existing.Username = req.Username
+	}
+	existing.Secrets = req.Secrets
+
+	if err := db.UpdateCredential(h.DB, id, existing); err != nil {
+		c.JSON(credentialErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	cred, err := db.GetCredential(h.DB, id)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": true, "id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "credential": cred})
+}
+
+// DeleteCredential 자격 증명 삭제
+func (h *CredentialHandler) DeleteCredential(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "유효하지 않은 자격 증명 ID입니다"})
+		return
+	}
+	if err := db.DeleteCredential(h.DB, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "자격 증명을 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// RotateCredentialKeys 이전 마스터 키로 암호화된 데이터 키를 현재 마스터 키로 다시 암호화
+// 새 마스터 키를 키 목록의 맨 앞에 추가하고 서버를 재시작한 뒤 호출합니다
+func (h *CredentialHandler) RotateCredentialKeys(c *gin.Context) {
+	rotated, err := db.RotateCredentialKeys(h.DB)
+	if err != nil {
+		log.Printf("[자격 증명] 마스터 키 교체 실패: %v", err)
+		c.JSON(credentialErrorStatus(err), gin.H{"success": false, "error": err.Error(), "rotated": rotated})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "rotated": rotated})
+}
+
+// credentialErrorStatus는 마스터 키가 설정되지 않은 경우 503, 그 외에는 500을 반환합니다
+func credentialErrorStatus(err error) int {
+	if errors.Is(err, vault.ErrNoMasterKey) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,13 +17,14 @@ import (
 	"github.com/k8scontrol/backend/internal/command"
 	"github.com/k8scontrol/backend/internal/db"
 	"github.com/k8scontrol/backend/internal/utils"
+	"github.com/k8scontrol/backend/pkg/redact"
 	"github.com/k8scontrol/backend/pkg/ssh"
-	gossh "golang.org/x/crypto/ssh"
 )
 
 // DockerHandler는 도커 관련 API 요청을 처리하는 핸들러입니다
 type DockerHandler struct {
 	db         *sql.DB
+	repos      *db.Repositories
 	cmdManager *command.CommandManager
 }
 
@@ -60,17 +62,20 @@ const (
 type DockerCommandRequest struct {
 	Action     string                 `json:"action"`
 	Parameters map[string]interface{} `json:"parameters"`
+	// DryRun이 true이면 명령어를 실행하지 않고 대상 서버와 렌더링된 명령어(비밀값은 가려짐)만 반환합니다
+	DryRun bool `json:"dry_run"`
 }
 
 // NewDockerHandler는 새로운 DockerHandler 인스턴스를 생성합니다
-func NewDockerHandler(db *sql.DB) *DockerHandler {
+func NewDockerHandler(database *sql.DB, repos *db.Repositories) *DockerHandler {
 	cmdManager := command.NewCommandManager()
 
 	// 도커 명령어 등록
 	command.RegisterDockerCommands(cmdManager)
 
 	return &DockerHandler{
-		db:         db,
+		db:         database,
+		repos:      repos,
 		cmdManager: cmdManager,
 	}
 }
@@ -87,7 +92,7 @@ func (h *DockerHandler) HandleRequest(c *gin.Context) {
 	}
 
 	// 액션 요청 로그 기록
-	log.Printf("[Docker API 요청] 액션: %s, 파라미터: %+v", request.Action, request.Parameters)
+	log.Printf("[Docker API 요청] 액션: %s, 파라미터: %+v", request.Action, redact.Params(request.Parameters))
 
 	// 필수 파라미터 검증
 	if request.Action == "" {
@@ -98,6 +103,17 @@ func (h *DockerHandler) HandleRequest(c *gin.Context) {
 		return
 	}
 
+	// 사용자의 인프라별 역할로 액션 실행 권한 확인
+	if !authorizeAction(c, h.db, request.Action, request.Parameters) {
+		return
+	}
+
+	// 드라이런: 명령어를 실행하지 않고 실행 계획만 반환
+	if request.DryRun {
+		h.handleDryRun(c, request)
+		return
+	}
+
 	// 액션 처리
 	switch request.Action {
 	// 도커 서버 관련 액션
@@ -192,7 +208,7 @@ func (h *DockerHandler) handleGetDockerServer(c *gin.Context, request DockerComm
 	}
 
 	// 인프라 ID로 서버 조회
-	servers, err := db.GetServersByInfraID(h.db, infraID)
+	servers, err := h.repos.Servers.GetByInfraID(infraID)
 	if err != nil {
 		log.Printf("[도커 서버 조회 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -262,7 +278,7 @@ func (h *DockerHandler) handleGetDockerInfo(c *gin.Context, request DockerComman
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetInfo(serverID)
 	if err != nil {
 		log.Printf("[도커 정보 조회 오류] 서버 정보 가져오기 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -321,35 +337,32 @@ func (h *DockerHandler) handleGetContainers(c *gin.Context, request DockerComman
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
+	composeProject, _ := request.Parameters["compose_project"].(string)
+	if composeProject != "" && !validateShellValues(c, shellValue{"compose_project", command.FormatContainerID, composeProject}) {
+		return
 	}
-
-	composeProject := request.Parameters["compose_project"]
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 컨테이너 상태 정보 가져오기
 	var containerCmd string
-	if composeProject != nil {
+	if composeProject != "" {
 		// 프로젝트 라벨을 사용하여 필터링
-		containerCmd = fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"label=com.docker.compose.project=%s\" --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'",
-			password, composeProject)
+		containerCmd = command.Render("sudo docker ps -a --filter %s --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'",
+			"label=com.docker.compose.project="+composeProject)
 	} else {
 		// 모든 컨테이너 조회
-		containerCmd = fmt.Sprintf("echo '%s' | sudo -S docker ps -a --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'",
-			password)
+		containerCmd = "sudo docker ps -a --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'"
 	}
 
-	containerResults, err := sshUtils.ExecuteCommands(hops, []string{containerCmd}, 30000)
+	containerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{containerCmd}, 30000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "컨테이너 상태 정보를 가져오는 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -383,9 +396,9 @@ func (h *DockerHandler) handleGetContainers(c *gin.Context, request DockerComman
 	}
 
 	// 이미지 정보 가져오기 (프로젝트 관련 컨테이너의 이미지만 필터링)
-	imageCmd := fmt.Sprintf("echo '%s' | sudo -S docker images --format '{{.Repository}}\t{{.Tag}}\t{{.Size}}\t{{.CreatedSince}}'", password)
+	imageCmd := "sudo docker images --format '{{.Repository}}\t{{.Tag}}\t{{.Size}}\t{{.CreatedSince}}'"
 
-	imageResults, err := sshUtils.ExecuteCommands(hops, []string{imageCmd}, 30000)
+	imageResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{imageCmd}, 30000)
 
 	var images []map[string]interface{}
 	if err == nil {
@@ -407,8 +420,7 @@ func (h *DockerHandler) handleGetContainers(c *gin.Context, request DockerComman
 					if len(fields) >= 4 {
 						imageName := fields[0] + ":" + fields[1]
 						// 프로젝트 관련 컨테이너의 이미지만 포함
-						composeProjectStr, ok := composeProject.(string)
-						if !ok || composeProjectStr == "" || projectImageIDs[imageName] {
+						if composeProject == "" || projectImageIDs[imageName] {
 							image := map[string]interface{}{
 								"repository": fields[0],
 								"tag":        fields[1],
@@ -425,17 +437,16 @@ func (h *DockerHandler) handleGetContainers(c *gin.Context, request DockerComman
 
 	// 네트워크 정보 가져오기 (프로젝트 관련 네트워크만 필터링)
 	var networkCmd string
-	if composeProject != nil {
+	if composeProject != "" {
 		// 프로젝트 이름으로 네트워크 필터링
-		networkCmd = fmt.Sprintf("echo '%s' | sudo -S docker network ls --filter \"name=%s\" --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'",
-			password, composeProject)
+		networkCmd = command.Render("sudo docker network ls --filter %s --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'",
+			"name="+composeProject)
 	} else {
 		// 모든 네트워크 조회
-		networkCmd = fmt.Sprintf("echo '%s' | sudo -S docker network ls --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'",
-			password)
+		networkCmd = "sudo docker network ls --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'"
 	}
 
-	networkResults, err := sshUtils.ExecuteCommands(hops, []string{networkCmd}, 30000)
+	networkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{networkCmd}, 30000)
 
 	var networks []map[string]interface{}
 	if err == nil {
@@ -463,17 +474,16 @@ func (h *DockerHandler) handleGetContainers(c *gin.Context, request DockerComman
 
 	// 볼륨 정보 가져오기 (프로젝트 관련 볼륨만 필터링)
 	var volumeCmd string
-	if composeProject != nil {
+	if composeProject != "" {
 		// 프로젝트 이름으로 볼륨 필터링
-		volumeCmd = fmt.Sprintf("echo '%s' | sudo -S docker volume ls --filter \"name=%s\" --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'",
-			password, composeProject)
+		volumeCmd = command.Render("sudo docker volume ls --filter %s --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'",
+			"name="+composeProject)
 	} else {
 		// 모든 볼륨 조회
-		volumeCmd = fmt.Sprintf("echo '%s' | sudo -S docker volume ls --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'",
-			password)
+		volumeCmd = "sudo docker volume ls --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'"
 	}
 
-	volumeResults, err := sshUtils.ExecuteCommands(hops, []string{volumeCmd}, 30000)
+	volumeResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{volumeCmd}, 30000)
 
 	var volumes []map[string]interface{}
 	if err == nil {
@@ -539,21 +549,28 @@ func (h *DockerHandler) handleStopContainer(c *gin.Context, request DockerComman
 
 func (h *DockerHandler) handleControlContainer(c *gin.Context, request DockerCommandRequest) {
 	// 필수 필드 검증
-	if request.Parameters["container_id"] == "" {
+	containerID, _ := request.Parameters["container_id"].(string)
+	if containerID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "컨테이너 ID는 필수 항목입니다."})
 		return
 	}
 
-	if request.Parameters["action_type"] == "" {
+	actionType, _ := request.Parameters["action_type"].(string)
+	if actionType == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "액션 타입은 필수 항목입니다."})
 		return
 	}
 
-	if request.Parameters["action_type"] != "stop" && request.Parameters["action_type"] != "restart" {
+	if actionType != "stop" && actionType != "restart" {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "액션 타입은 'stop' 또는 'restart'여야 합니다."})
 		return
 	}
 
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c, shellValue{"container_id", command.FormatContainerID, containerID}) {
+		return
+	}
+
 	var hops []ssh.HopConfig
 	if hopsData, ok := request.Parameters["hops"].([]interface{}); ok && len(hopsData) > 0 {
 		hops = make([]ssh.HopConfig, len(hopsData))
@@ -575,6 +592,7 @@ func (h *DockerHandler) handleControlContainer(c *gin.Context, request DockerCom
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -591,24 +609,15 @@ func (h *DockerHandler) handleControlContainer(c *gin.Context, request DockerCom
 		return
 	}
 
-	containerID := request.Parameters["container_id"]
-	actionType := request.Parameters["action_type"]
-
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 컨테이너 존재 여부 확인
-	checkCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter id=%s --format '{{.ID}}'", password, containerID)
-	checkResults, err := sshUtils.ExecuteCommands(hops, []string{checkCmd}, 30000)
+	checkCmd := command.Render("sudo docker ps -a --filter %s --format '{{.ID}}'", "id="+containerID)
+	checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkCmd}, 30000)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "컨테이너 확인 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -634,12 +643,12 @@ func (h *DockerHandler) handleControlContainer(c *gin.Context, request DockerCom
 	// 액션 실행
 	var actionCmd string
 	if actionType == "stop" {
-		actionCmd = fmt.Sprintf("echo '%s' | sudo -S docker stop %s", password, containerID)
+		actionCmd = command.Render("sudo docker stop %s", containerID)
 	} else {
-		actionCmd = fmt.Sprintf("echo '%s' | sudo -S docker restart %s", password, containerID)
+		actionCmd = command.Render("sudo docker restart %s", containerID)
 	}
 
-	results, err := sshUtils.ExecuteCommands(hops, []string{actionCmd}, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{actionCmd}, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var formattedResults []map[string]interface{}
@@ -719,6 +728,7 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -729,18 +739,18 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 		}
 	}
 
-	repoURL := request.Parameters["repo_url"].(string)
-	usernameRepo := request.Parameters["username_repo"].(string)
-	passwordRepo := request.Parameters["password_repo"].(string)
+	repoURL, _ := request.Parameters["repo_url"].(string)
+	usernameRepo, _ := request.Parameters["username_repo"].(string)
+	passwordRepo, _ := request.Parameters["password_repo"].(string)
+	branch, _ := request.Parameters["branch"].(string)
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"repo_url", command.FormatGitURL, repoURL},
+		shellValue{"branch", command.FormatGitRef, branch}) {
+		return
 	}
 
-	branch := request.Parameters["branch"]
-
 	// 저장소 이름 추출 (URL의 마지막 부분)
 	repoName := extractRepoName(repoURL)
 
@@ -751,8 +761,8 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 	sshUtils := utils.NewSSHUtils()
 
 	// 현재 실행 중인 컨테이너 상태 확인 (작업 전)
-	initialContainerCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
-	initialContainerResults, _ := sshUtils.ExecuteCommands(hops, []string{initialContainerCmd}, 30000)
+	initialContainerCmd := "sudo docker ps -a"
+	initialContainerResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{initialContainerCmd}, 30000)
 	var initialContainers string
 	if len(initialContainerResults) > 0 {
 		initialContainers = initialContainerResults[0].Output
@@ -763,51 +773,33 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 
 	// 1. 작업 디렉토리 생성 및 이전 빌드 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S mkdir -p %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S chown $(whoami):$(whoami) %s", password, workDir))
+		command.Render("sudo rm -rf %s", workDir),
+		command.Render("sudo mkdir -p %s", workDir),
+		command.Render("sudo chown $(whoami):$(whoami) %s", workDir))
 
 	// 2. 필요한 도구 설치 확인 (Git)
 	commands = append(commands,
-		fmt.Sprintf("which git || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y git)", password, password))
+		"which git || (sudo apt-get update && sudo apt-get install -y git)")
 
 	// 3. 저장소 클론 (Git 인증 오류 로깅 개선)
-	gitCmd := ""
-	if usernameRepo != "" && passwordRepo != "" {
-		// URL에서 프로토콜 부분 제거
-		repoNoProtocol := strings.TrimPrefix(repoURL, "https://")
-		repoNoProtocol = strings.TrimPrefix(repoNoProtocol, "http://")
-
-		// @ 문자가 포함된 사용자명 처리 (이메일 주소 등)
-		encodedUsername := usernameRepo
-		if strings.Contains(encodedUsername, "@") {
-			// 사용자명이 이메일인 경우 URL 인코딩 적용
-			encodedUsername = strings.ReplaceAll(encodedUsername, "@", "%40")
-		}
-
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s https://%s:%s@%s . 2>&1 || echo 'Git 클론 실패'",
-			workDir,
-			branch,
-			encodedUsername,
-			passwordRepo,
-			repoNoProtocol)
-	} else {
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s %s . 2>&1 || echo 'Git 클론 실패'",
-			workDir, branch, repoURL)
+	gitCmd, err := gitCloneCommand(workDir, repoURL, branch, usernameRepo, passwordRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
 	}
 	commands = append(commands, gitCmd)
 
 	// 4. 클론 성공 확인 및 디렉토리 내용 확인
-	commands = append(commands, fmt.Sprintf("cd %s && ls -la", workDir))
+	commands = append(commands, command.Render("cd %s && ls -la", workDir))
 
 	// 5. docker-compose 파일 존재하는지 확인하고 내용 확인
-	composeCheckCmd := fmt.Sprintf("cd %s && ([ -f docker-compose.yml ] && echo 'FOUND_YML' && cat docker-compose.yml) || ([ -f docker-compose.yaml ] && echo 'FOUND_YAML' && cat docker-compose.yaml) || echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다.'", workDir)
+	composeCheckCmd := command.Render("cd %s && ([ -f docker-compose.yml ] && echo 'FOUND_YML' && cat docker-compose.yml) || ([ -f docker-compose.yaml ] && echo 'FOUND_YAML' && cat docker-compose.yaml) || echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다.'", workDir)
 	commands = append(commands, composeCheckCmd)
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands, 300000) // 5분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "명령 실행 중 오류가 발생했습니다: " + err.Error(),
 			"logs":    formatResults(results),
@@ -891,13 +883,13 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 	// 6.1. 첫 번째 전략: 일반적인 docker-compose down 명령 (많은 경우 작동)
 	// 두 파일 확장자 모두 시도
 	// YML 파일로 시도
-	composeDownCmdYml := fmt.Sprintf("cd %s && [ -f docker-compose.yml ] && echo '%s' | sudo -S docker-compose -f docker-compose.yml down -v --remove-orphans 2>&1 || echo 'YML_FILE_NOT_FOUND'",
-		workDir, password)
+	composeDownCmdYml := command.Render("cd %s && [ -f docker-compose.yml ] && sudo docker-compose -f docker-compose.yml down -v --remove-orphans 2>&1 || echo 'YML_FILE_NOT_FOUND'",
+		workDir)
 	downerCommands = append(downerCommands, composeDownCmdYml)
 
 	// YAML 파일로 시도
-	composeDownCmdYaml := fmt.Sprintf("cd %s && [ -f docker-compose.yaml ] && echo '%s' | sudo -S docker-compose -f docker-compose.yaml down -v --remove-orphans 2>&1 || echo 'YAML_FILE_NOT_FOUND'",
-		workDir, password)
+	composeDownCmdYaml := command.Render("cd %s && [ -f docker-compose.yaml ] && sudo docker-compose -f docker-compose.yaml down -v --remove-orphans 2>&1 || echo 'YAML_FILE_NOT_FOUND'",
+		workDir)
 	downerCommands = append(downerCommands, composeDownCmdYaml)
 
 	// 6.2. 두 번째 전략: container-name으로 컨테이너 직접 제거
@@ -906,10 +898,10 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 	if len(containerNames) > 0 {
 		// 추출된 컨테이너 이름으로 직접 제거 명령 추가
 		for _, containerName := range containerNames {
-			stopCmd := fmt.Sprintf("echo '%s' | sudo -S docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
-			rmCmd := fmt.Sprintf("echo '%s' | sudo -S docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
+			stopCmd := command.Render("sudo docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
+			rmCmd := command.Render("sudo docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
 			downerCommands = append(downerCommands, stopCmd, rmCmd)
 		}
 	}
@@ -920,23 +912,23 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 	if len(containersInPS) > 0 {
 		for _, containerName := range containersInPS {
 			// 앞의 명령에서 이미 제거되었을 수 있으므로 오류 무시
-			stopCmd := fmt.Sprintf("echo '%s' | sudo -S docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
-			rmCmd := fmt.Sprintf("echo '%s' | sudo -S docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
+			stopCmd := command.Render("sudo docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
+			rmCmd := command.Render("sudo docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
 			downerCommands = append(downerCommands, stopCmd, rmCmd)
 		}
 	}
 
 	// 6.4. 전략 간 중간 확인을 위한 컨테이너 상태 명령
-	checkContainerCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
+	checkContainerCmd := "sudo docker ps -a"
 	downerCommands = append(downerCommands, checkContainerCmd)
 
 	// 7. 임시 폴더 정리
-	downerCommands = append(downerCommands, fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir))
+	downerCommands = append(downerCommands, command.Render("sudo rm -rf %s", workDir))
 
 	// 명령 실행
-	downResults, err := sshUtils.ExecuteCommands(hops, downerCommands, 180000) // 3분 타임아웃
+	downResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, downerCommands, 180000) // 3분 타임아웃
 
 	// 최종 컨테이너 상태와 각 전략의 결과 분석
 	var finalContainerStatus string
@@ -1001,8 +993,8 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 		// 두 번째 전략 (container_name) 결과 분석
 		if containerNames != nil && len(containerNames) > 0 {
 			for _, name := range containerNames {
-				if strings.Contains(result.Command, fmt.Sprintf("docker stop %s", name)) ||
-					strings.Contains(result.Command, fmt.Sprintf("docker rm -f %s", name)) {
+				if strings.Contains(result.Command, command.Render("docker stop %s", name)) ||
+					strings.Contains(result.Command, command.Render("docker rm -f %s", name)) {
 					if !strings.Contains(result.Output, "COMMAND_FAILED") {
 						removeStrategy.ContainerNameSuccess = true
 						if removeStrategy.RemovedByStrategy == "" {
@@ -1010,17 +1002,17 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 						}
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.SuccessCommands = append(removeStrategy.SuccessCommands, cmd)
 					} else {
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.FailedCommands = append(removeStrategy.FailedCommands, cmd)
 					}
@@ -1031,8 +1023,8 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 		// 세 번째 전략 (PS 매치) 결과 분석
 		if containersInPS != nil && len(containersInPS) > 0 {
 			for _, name := range containersInPS {
-				if strings.Contains(result.Command, fmt.Sprintf("docker stop %s", name)) ||
-					strings.Contains(result.Command, fmt.Sprintf("docker rm -f %s", name)) {
+				if strings.Contains(result.Command, command.Render("docker stop %s", name)) ||
+					strings.Contains(result.Command, command.Render("docker rm -f %s", name)) {
 					if !strings.Contains(result.Output, "COMMAND_FAILED") {
 						removeStrategy.PSMatchSuccess = true
 						if removeStrategy.RemovedByStrategy == "" {
@@ -1040,17 +1032,17 @@ func (h *DockerHandler) handleRemoveContainer(c *gin.Context, request DockerComm
 						}
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.SuccessCommands = append(removeStrategy.SuccessCommands, cmd)
 					} else {
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.FailedCommands = append(removeStrategy.FailedCommands, cmd)
 					}
@@ -1193,6 +1185,7 @@ func (h *DockerHandler) handleRemoveOneDockerContainer(c *gin.Context, request D
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -1203,34 +1196,36 @@ func (h *DockerHandler) handleRemoveOneDockerContainer(c *gin.Context, request D
 		}
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := hops[len(hops)-1].Password
+	requestedID, _ := request.Parameters["container_id"].(string)
+	if !validateShellValues(c, shellValue{"container_id", command.FormatContainerID, requestedID}) {
+		return
+	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 먼저 모든 컨테이너 목록 확인 (디버깅 용도)
-	listAllCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
-	listResults, _ := sshUtils.ExecuteCommands(hops, []string{listAllCmd}, 30000)
+	listAllCmd := "sudo docker ps -a"
+	listResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{listAllCmd}, 30000)
 	allContainers := ""
 	if len(listResults) > 0 {
 		allContainers = listResults[0].Output
 	}
 
 	// 1. 컨테이너 존재 여부 확인 (ID로 검색)
-	checkContainerByIDCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"id=%s\" --format \"{{.ID}}|{{.Names}}|{{.Status}}\"",
-		password, request.Parameters["container_id"])
+	checkContainerByIDCmd := command.Render("sudo docker ps -a --filter %s --format '{{.ID}}|{{.Names}}|{{.Status}}'",
+		"id="+requestedID)
 
 	// 2. 컨테이너 존재 여부 확인 (이름으로 검색)
-	checkContainerByNameCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"name=%s\" --format \"{{.ID}}|{{.Names}}|{{.Status}}\"",
-		password, request.Parameters["container_id"])
+	checkContainerByNameCmd := command.Render("sudo docker ps -a --filter %s --format '{{.ID}}|{{.Names}}|{{.Status}}'",
+		"name="+requestedID)
 
 	// 두 명령 실행
 	checkCommands := []string{checkContainerByIDCmd, checkContainerByNameCmd}
-	checkResults, err := sshUtils.ExecuteCommands(hops, checkCommands, 30000)
+	checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, checkCommands, 30000)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success":              false,
 			"error":                "컨테이너 확인 중 오류가 발생했습니다: " + err.Error(),
 			"debug_all_containers": allContainers,
@@ -1286,7 +1281,7 @@ func (h *DockerHandler) handleRemoveOneDockerContainer(c *gin.Context, request D
 			"success":              false,
 			"container_exists":     false,
 			"error":                "컨테이너를 찾을 수 없습니다",
-			"requested_id":         request.Parameters["container_id"],
+			"requested_id":         requestedID,
 			"debug_all_containers": allContainers,
 			"id_search_result":     idSearchResult,
 			"name_search_result":   nameSearchResult,
@@ -1298,27 +1293,27 @@ func (h *DockerHandler) handleRemoveOneDockerContainer(c *gin.Context, request D
 	var commands []string
 
 	// 중지 명령 (이미 중지된 경우에도 오류 무시)
-	stopCmd := fmt.Sprintf("echo '%s' | sudo -S docker stop %s 2>&1 || echo 'CONTAINER_ALREADY_STOPPED'",
-		password, containerID)
+	stopCmd := command.Render("sudo docker stop %s 2>&1 || echo 'CONTAINER_ALREADY_STOPPED'",
+		containerID)
 	commands = append(commands, stopCmd)
 
 	// 삭제 명령 (항상 강제 삭제)
-	rmCmd := fmt.Sprintf("echo '%s' | sudo -S docker rm -f %s 2>&1",
-		password, containerID)
+	rmCmd := command.Render("sudo docker rm -f %s 2>&1",
+		containerID)
 	commands = append(commands, rmCmd)
 
 	// 삭제 확인 명령 - 단순한 방식으로 변경: 해당 ID의 컨테이너가 있는지 직접 확인
 	// -q 옵션은 컨테이너 ID만 반환, wc -l로 개수 카운트
-	checkRemovedCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"id=%s\" -q | wc -l",
-		password, containerID)
+	checkRemovedCmd := command.Render("sudo docker ps -a --filter %s -q | wc -l",
+		"id="+containerID)
 	commands = append(commands, checkRemovedCmd)
 
 	// 삭제 후 컨테이너 목록 확인 (디버깅 용도)
-	listAfterCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
+	listAfterCmd := "sudo docker ps -a"
 	commands = append(commands, listAfterCmd)
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 60000) // 1분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands, 60000) // 1분 타임아웃
 
 	// 결과 분석
 	var stopOutput, rmOutput, checkOutput, listAfterOutput string
@@ -1404,6 +1399,7 @@ func (h *DockerHandler) handleCreateContainer(c *gin.Context, request DockerComm
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -1414,21 +1410,25 @@ func (h *DockerHandler) handleCreateContainer(c *gin.Context, request DockerComm
 		}
 	}
 
-	repoURL := request.Parameters["repo_url"].(string)
-	usernameRepo := request.Parameters["username_repo"].(string)
-	passwordRepo := request.Parameters["password_repo"].(string)
-	forceRecreate := request.Parameters["force_recreate"].(bool)
-	dockerRegistry := request.Parameters["docker_registry"].(string)
-	dockerUsername := request.Parameters["docker_username"].(string)
-	dockerPassword := request.Parameters["docker_password"].(string)
+	repoURL, _ := request.Parameters["repo_url"].(string)
+	usernameRepo, _ := request.Parameters["username_repo"].(string)
+	passwordRepo, _ := request.Parameters["password_repo"].(string)
+	forceRecreate, _ := request.Parameters["force_recreate"].(bool)
+	dockerRegistry, _ := request.Parameters["docker_registry"].(string)
+	dockerUsername, _ := request.Parameters["docker_username"].(string)
+	dockerPassword, _ := request.Parameters["docker_password"].(string)
+	branch, _ := request.Parameters["branch"].(string)
+	composeProject, _ := request.Parameters["compose_project"].(string)
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"repo_url", command.FormatGitURL, repoURL},
+		shellValue{"branch", command.FormatGitRef, branch}) {
+		return
+	}
+	if composeProject != "" && !validateShellValues(c, shellValue{"compose_project", command.FormatContainerID, composeProject}) {
+		return
 	}
-
-	branch := request.Parameters["branch"]
 
 	// 저장소 이름 추출 (URL의 마지막 부분)
 	repoName := extractRepoName(repoURL)
@@ -1447,8 +1447,6 @@ func (h *DockerHandler) handleCreateContainer(c *gin.Context, request DockerComm
 		composePathStr = strings.TrimPrefix(composePathStr, "./")
 	}
 
-	composeProject := request.Parameters["compose_project"].(string)
-
 	// 컴포즈 프로젝트 이름 설정 (지정되지 않은 경우 저장소 이름 사용)
 	if composeProject == "" {
 		composeProject = repoName
@@ -1465,48 +1463,30 @@ func (h *DockerHandler) handleCreateContainer(c *gin.Context, request DockerComm
 
 	// 1. 작업 디렉토리 생성 및 이전 빌드 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S mkdir -p %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S chown $(whoami):$(whoami) %s", password, workDir)) // 디렉토리 권한 변경
+		command.Render("sudo rm -rf %s", workDir),
+		command.Render("sudo mkdir -p %s", workDir),
+		command.Render("sudo chown $(whoami):$(whoami) %s", workDir)) // 디렉토리 권한 변경
 
 	// 2. 필요한 도구 설치 확인 (Git)
 	commands = append(commands,
-		fmt.Sprintf("which git || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y git)", password, password))
+		"which git || (sudo apt-get update && sudo apt-get install -y git)")
 
 	// 3. Docker Compose 경로 확인 및 설치
 	commands = append(commands,
 		"which docker-compose || echo 'DOCKER_COMPOSE_NOT_FOUND'",
 		"which /snap/bin/docker-compose || echo 'SNAP_DOCKER_COMPOSE_NOT_FOUND'",
-		fmt.Sprintf("which docker-compose || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y docker-compose)", password, password))
+		"which docker-compose || (sudo apt-get update && sudo apt-get install -y docker-compose)")
 
 	// 4. 저장소 클론 (Git 인증 오류 로깅 개선)
-	gitCmd := ""
-	if usernameRepo != "" && passwordRepo != "" {
-		// URL에서 프로토콜 부분 제거
-		repoNoProtocol := strings.TrimPrefix(repoURL, "https://")
-		repoNoProtocol = strings.TrimPrefix(repoNoProtocol, "http://")
-
-		// @ 문자가 포함된 사용자명 처리 (이메일 주소 등)
-		encodedUsername := usernameRepo
-		if strings.Contains(encodedUsername, "@") {
-			// 사용자명이 이메일인 경우 URL 인코딩 적용
-			encodedUsername = strings.ReplaceAll(encodedUsername, "@", "%40")
-		}
-
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s https://%s:%s@%s . 2>&1 || echo 'Git 클론 실패'",
-			workDir,
-			branch,
-			encodedUsername,
-			passwordRepo,
-			repoNoProtocol)
-	} else {
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s %s . 2>&1 || echo 'Git 클론 실패'",
-			workDir, branch, repoURL)
+	gitCmd, err := gitCloneCommand(workDir, repoURL, branch, usernameRepo, passwordRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
 	}
 	commands = append(commands, gitCmd)
 
 	// 5. 클론 성공 확인 및 디렉토리 내용 확인
-	commands = append(commands, fmt.Sprintf("cd %s && ls -la", workDir))
+	commands = append(commands, command.Render("cd %s && ls -la", workDir))
 
 	// 6. docker-compose.yml 파일 존재 확인 (절대 경로 사용)
 	checkComposePath := composePath
@@ -1517,30 +1497,30 @@ func (h *DockerHandler) handleCreateContainer(c *gin.Context, request DockerComm
 	yamlPath := fmt.Sprintf("%s/docker-compose.yaml", workDir)
 
 	// 둘 중 하나라도 있으면 그 파일 사용
-	commands = append(commands, fmt.Sprintf("if [ -f %s ]; then echo 'YML_EXISTS'; elif [ -f %s ]; then echo 'YAML_EXISTS'; else echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다!'; fi", ymlPath, yamlPath))
+	commands = append(commands, command.Render("if [ -f %s ]; then echo 'YML_EXISTS'; elif [ -f %s ]; then echo 'YAML_EXISTS'; else echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다!'; fi", ymlPath, yamlPath))
 
 	// 7. 기존 컨테이너 정리 (강제 재생성 옵션이 켜져있는 경우)
 	if forceRecreate {
-		downCommand := fmt.Sprintf("cd %s && echo '%s' | sudo -S docker-compose -p %s down",
-			workDir, password, composeProject)
+		downCommand := command.Render("cd %s && sudo docker-compose -p %s down",
+			workDir, composeProject)
 		commands = append(commands, downCommand)
 	}
 
 	// 8.5 Docker 레지스트리 로그인 (필요한 경우)
 	if dockerRegistry != "" && dockerUsername != "" && dockerPassword != "" {
 		// Docker 로그인 명령 추가
-		loginCmd := fmt.Sprintf("echo '%s' | docker login %s -u %s --password-stdin",
+		loginCmd := command.Render("printf '%%s' %s | docker login %s -u %s --password-stdin",
 			dockerPassword, dockerRegistry, dockerUsername)
 		commands = append(commands, loginCmd)
 	}
 
 	// 9. Docker Compose로 빌드 및 실행
-	commands = append(commands, fmt.Sprintf("cd %s && echo '%s' | sudo -S docker-compose -p %s up -d --build",
-		workDir, password, composeProject))
+	commands = append(commands, command.Render("cd %s && sudo docker-compose -p %s up -d --build",
+		workDir, composeProject))
 
 	// 10. 컨테이너 상태 확인
-	commands = append(commands, fmt.Sprintf("cd %s && echo '%s' | sudo -S docker-compose -p %s ps",
-		workDir, password, composeProject))
+	commands = append(commands, command.Render("cd %s && sudo docker-compose -p %s ps",
+		workDir, composeProject))
 
 	// 11. 도커 이미지 및 컨테이너 목록 확인 (디버깅용)
 	commands = append(commands,
@@ -1549,12 +1529,12 @@ func (h *DockerHandler) handleCreateContainer(c *gin.Context, request DockerComm
 
 	// 12. 임시 빌드 폴더 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir))
+		command.Render("sudo rm -rf %s", workDir))
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 600000) // 10분 타임아웃
+	results, err := sshUtils.ExecuteCommandsStream(c.Request.Context(), hops, commands, 600000, logOutputHandler("컨테이너 생성")) // 10분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "명령 실행 중 오류가 발생했습니다: " + err.Error(),
 			"logs":    formatResults(results),
@@ -1922,7 +1902,7 @@ func (h *DockerHandler) handleCreateDockerServer(c *gin.Context, request DockerC
 	}
 
 	// 데이터베이스에 서버 생성
-	serverID, err := db.CreateServer(h.db, serverInput)
+	serverID, err := h.repos.Servers.Create(serverInput)
 	if err != nil {
 		log.Printf("[도커 서버 생성 오류] 데이터베이스 저장 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1933,7 +1913,7 @@ func (h *DockerHandler) handleCreateDockerServer(c *gin.Context, request DockerC
 	}
 
 	// 생성된 서버 정보 조회
-	createdServer, err := db.GetServerInfo(h.db, serverID)
+	createdServer, err := h.repos.Servers.GetInfo(serverID)
 	if err != nil {
 		log.Printf("[도커 서버 생성 알림] 서버가 생성되었으나 조회 실패: %v", err)
 		// 서버 생성은 성공했으므로 성공 응답 반환
@@ -1967,7 +1947,7 @@ func (h *DockerHandler) handleCheckServerStatus(c *gin.Context, request DockerCo
 
 	// 서버 ID가 제공된 경우 서버 정보 조회
 	if serverID > 0 {
-		server, err := db.GetServerByID(h.db, serverID)
+		server, err := h.repos.Servers.GetByID(serverID)
 		if err != nil {
 			log.Printf("[서버 상태 확인 경고] 서버 정보 조회 실패: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "서버 정보를 조회할 수 없습니다."})
@@ -1999,6 +1979,7 @@ func (h *DockerHandler) handleCheckServerStatus(c *gin.Context, request DockerCo
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	}
@@ -2017,10 +1998,10 @@ func (h *DockerHandler) handleCheckServerStatus(c *gin.Context, request DockerCo
 	cmd := "echo '===START==='; " +
 		"if command -v docker >/dev/null; then echo 'DOCKER_INSTALLED=true'; else echo 'DOCKER_INSTALLED=false'; fi; " +
 		"if systemctl status docker | grep -q 'Active: active (running)'; then echo 'DOCKER_RUNNING=true'; else echo 'DOCKER_RUNNING=false'; fi; " +
-		"echo '" + hops[len(hops)-1].Password + "' | sudo -S docker info | grep 'Server Version' || echo 'DOCKER_VERSION=NotFound'; " +
-		"echo '" + hops[len(hops)-1].Password + "' | sudo -S docker ps --format '{{.Names}}' || echo 'DOCKER_CONTAINERS=NotFound'; " +
-		"echo '" + hops[len(hops)-1].Password + "' | sudo -S docker system df || echo 'DOCKER_DISK_USAGE=NotFound'; " +
-		"echo '" + hops[len(hops)-1].Password + "' | sudo -S docker network ls --format '{{.Name}}' || echo 'DOCKER_NETWORKS=NotFound'; " +
+		"sudo docker info | grep 'Server Version' || echo 'DOCKER_VERSION=NotFound'; " +
+		"sudo docker ps --format '{{.Names}}' || echo 'DOCKER_CONTAINERS=NotFound'; " +
+		"sudo docker system df || echo 'DOCKER_DISK_USAGE=NotFound'; " +
+		"sudo docker network ls --format '{{.Name}}' || echo 'DOCKER_NETWORKS=NotFound'; " +
 		"echo '===END==='"
 
 	// 최대 10번 재시도
@@ -2032,18 +2013,18 @@ func (h *DockerHandler) handleCheckServerStatus(c *gin.Context, request DockerCo
 		log.Printf("[서버 상태 확인] 시도 %d/10", attempt)
 		startTime := time.Now()
 
-		results, err := sshUtils.ExecuteCommands(hops, []string{cmd}, 20000)
+		results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{cmd}, 20000)
 		executionTime := time.Since(startTime)
 
 		if err != nil {
 			// 오류 타입에 따라 더 상세한 로그 추가
-			if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "timed out") {
+			if errors.Is(err, ssh.ErrConnectionTimeout) {
 				log.Printf("[서버 상태 확인 오류] 시도 %d 타임아웃 발생 (소요시간: %v): %v",
 					attempt, executionTime, err)
-			} else if strings.Contains(err.Error(), "connection") || strings.Contains(err.Error(), "connect") {
+			} else if errors.Is(err, ssh.ErrConnectionRefused) || errors.Is(err, ssh.ErrHostNotFound) || errors.Is(err, ssh.ErrTunnelingFailed) {
 				log.Printf("[서버 상태 확인 오류] 시도 %d 연결 실패 (소요시간: %v): %v",
 					attempt, executionTime, err)
-			} else if strings.Contains(err.Error(), "authentication") || strings.Contains(err.Error(), "auth") {
+			} else if errors.Is(err, ssh.ErrAuthenticationFailed) {
 				log.Printf("[서버 상태 확인 오류] 시도 %d 인증 실패 (소요시간: %v): %v",
 					attempt, executionTime, err)
 			} else {
@@ -2114,7 +2095,7 @@ func (h *DockerHandler) handleCheckServerStatus(c *gin.Context, request DockerCo
 
 	// 서버 ID가 제공된 경우 DB에 마지막 확인 시간 업데이트
 	if serverID > 0 {
-		err := db.UpdateServerLastChecked(h.db, serverID, now)
+		err := h.repos.Servers.UpdateLastChecked(serverID, now)
 		if err != nil {
 			log.Printf("[서버 상태 확인 경고] 마지막 확인 시간 업데이트 실패: %v", err)
 			// 업데이트 실패해도 계속 진행
@@ -2162,6 +2143,7 @@ func (h *DockerHandler) handleInstallDocker(c *gin.Context, request DockerComman
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -2178,19 +2160,13 @@ func (h *DockerHandler) handleInstallDocker(c *gin.Context, request DockerComman
 		return
 	}
 
-	// 3. 마지막 hop의 비밀번호 추출 (sudo 실행용)
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	// Ubuntu 버전 확인 명령어
 	checkUbuntuVersionCmd := "lsb_release -rs || cat /etc/os-release | grep VERSION_ID | cut -d'\"' -f2"
 
 	sshUtils := utils.NewSSHUtils()
-	versionResults, err := sshUtils.ExecuteCommands(hops, []string{checkUbuntuVersionCmd}, 30000)
+	versionResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkUbuntuVersionCmd}, 30000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Ubuntu 버전 확인 중 오류가 발생했습니다: " + err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "Ubuntu 버전 확인 중 오류가 발생했습니다: " + err.Error()})
 		return
 	}
 
@@ -2204,11 +2180,11 @@ func (h *DockerHandler) handleInstallDocker(c *gin.Context, request DockerComman
 
 	// Docker가 이미 설치되어 있는지 확인
 	checkDockerExistsCmd := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-		fmt.Sprintf("echo '%s' | sudo -S docker ps -a 2>/dev/null | grep -v CONTAINER | wc -l || echo '0'", password), // 실행 중인 컨테이너 수 확인
+		"sudo docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+		"sudo docker ps -a 2>/dev/null | grep -v CONTAINER | wc -l || echo '0'", // 실행 중인 컨테이너 수 확인
 	}
 
-	existResults, _ := sshUtils.ExecuteCommands(hops, checkDockerExistsCmd, 30000)
+	existResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, checkDockerExistsCmd, 30000)
 	dockerExists := false
 	containerCount := 0
 
@@ -2251,72 +2227,72 @@ func (h *DockerHandler) handleInstallDocker(c *gin.Context, request DockerComman
 	// 공통 설치 준비 명령어
 	prepCommands := []string{
 		// 패키지 시스템 초기화 및 손상된 패키지 수정
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update > /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y ca-certificates curl gnupg software-properties-common apt-transport-https >> /tmp/docker_install.log 2>&1", password),
+		"sudo apt-get update > /tmp/docker_install.log 2>&1",
+		"sudo apt-get install -y ca-certificates curl gnupg software-properties-common apt-transport-https >> /tmp/docker_install.log 2>&1",
 		// APT 패키지 상태 복구 명령
-		fmt.Sprintf("echo '%s' | sudo -S apt-get -f install >> /tmp/docker_install.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S dpkg --configure -a >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get -f install >> /tmp/docker_install.log 2>&1 || true",
+		"sudo dpkg --configure -a >> /tmp/docker_install.log 2>&1 || true",
 	}
 	installDockerCommands = append(installDockerCommands, prepCommands...)
 
 	// Docker 공식 설치 스크립트 사용 (가장 안정적인 방법)
 	dockerScriptCommands := []string{
 		// 기존 설치 파일 및 디렉토리 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.gpg /etc/apt/keyrings/docker.asc /tmp/docker.gpg >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.gpg /etc/apt/keyrings/docker.asc /tmp/docker.gpg >> /tmp/docker_install.log 2>&1 || true",
 		// 기존 도커 관련 패키지 제거
-		fmt.Sprintf("echo '%s' | sudo -S apt-get remove -y docker docker-engine docker.io containerd runc >> /tmp/docker_install.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get autoremove -y >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get remove -y docker docker-engine docker.io containerd runc >> /tmp/docker_install.log 2>&1 || true",
+		"sudo apt-get autoremove -y >> /tmp/docker_install.log 2>&1 || true",
 		// APT 업데이트
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get update >> /tmp/docker_install.log 2>&1 || true",
 		// Docker 공식 설치 스크립트 다운로드 및 실행
-		fmt.Sprintf("echo '%s' | sudo -S curl -fsSL https://get.docker.com -o /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S sh /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1", password),
+		"sudo curl -fsSL https://get.docker.com -o /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1",
+		"sudo sh /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1",
 		// Docker 서비스 시작 및 활성화
-		fmt.Sprintf("echo '%s' | sudo -S systemctl start docker >> /tmp/docker_install.log 2>&1 || echo '%s' | sudo -S service docker start >> /tmp/docker_install.log 2>&1 || true", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable docker >> /tmp/docker_install.log 2>&1 || echo '%s' | sudo -S service docker enable >> /tmp/docker_install.log 2>&1 || true", password, password),
+		"sudo systemctl start docker >> /tmp/docker_install.log 2>&1 || sudo service docker start >> /tmp/docker_install.log 2>&1 || true",
+		"sudo systemctl enable docker >> /tmp/docker_install.log 2>&1 || sudo service docker enable >> /tmp/docker_install.log 2>&1 || true",
 		// 현재 사용자를 도커 그룹에 추가
-		fmt.Sprintf("echo '%s' | sudo -S groupadd -f docker >> /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S usermod -aG docker $(whoami) >> /tmp/docker_install.log 2>&1", password),
+		"sudo groupadd -f docker >> /tmp/docker_install.log 2>&1",
+		"sudo usermod -aG docker $(whoami) >> /tmp/docker_install.log 2>&1",
 		// 설치 확인
 		"echo '도커 설치 시도 완료' >> /tmp/docker_install.log",
-		fmt.Sprintf("echo '%s' | sudo -S docker --version >> /tmp/docker_install.log 2>&1 || echo '도커 명령어 실행 실패' >> /tmp/docker_install.log", password),
+		"sudo docker --version >> /tmp/docker_install.log 2>&1 || echo '도커 명령어 실행 실패' >> /tmp/docker_install.log",
 	}
 
 	installDockerCommands = append(installDockerCommands, dockerScriptCommands...)
 
 	// 도커 설치 실행
-	_, err = sshUtils.ExecuteCommands(hops, installDockerCommands, 300000) // 5분 타임아웃
+	_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, installDockerCommands, 300000) // 5분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "도커 설치 중 오류가 발생했습니다: " + err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "도커 설치 중 오류가 발생했습니다: " + err.Error()})
 		return
 	}
 
 	// 모든 패키지 업데이트 및 재시도
 	retryDockerCommands := []string{
 		// 패키지 업데이트 및 업그레이드
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update >> /tmp/docker_install_retry.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get upgrade -y >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo apt-get update >> /tmp/docker_install_retry.log 2>&1 || true",
+		"sudo apt-get upgrade -y >> /tmp/docker_install_retry.log 2>&1 || true",
 		// 도커 패키지 직접 설치 (표준 방식)
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y docker-ce docker-ce-cli containerd.io docker-compose-plugin >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo apt-get install -y docker-ce docker-ce-cli containerd.io docker-compose-plugin >> /tmp/docker_install_retry.log 2>&1 || true",
 		// 서비스 시작
-		fmt.Sprintf("echo '%s' | sudo -S systemctl start docker >> /tmp/docker_install_retry.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable docker >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo systemctl start docker >> /tmp/docker_install_retry.log 2>&1 || true",
+		"sudo systemctl enable docker >> /tmp/docker_install_retry.log 2>&1 || true",
 	}
 
-	_, retryErr := sshUtils.ExecuteCommands(hops, retryDockerCommands, 300000)
+	_, retryErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, retryDockerCommands, 300000)
 	if retryErr != nil {
 		log.Println("도커 재설치 중 오류 발생:", retryErr)
 	}
 
 	// 설치 성공 여부 확인
 	checkDockerCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install.log || echo '로그 파일을 읽을 수 없습니다.'", password),
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install_retry.log 2>/dev/null || echo '재시도 로그 없음'", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl status docker 2>/dev/null || echo '%s' | sudo -S service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'", password, password),
+		"sudo docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+		"sudo cat /tmp/docker_install.log || echo '로그 파일을 읽을 수 없습니다.'",
+		"sudo cat /tmp/docker_install_retry.log 2>/dev/null || echo '재시도 로그 없음'",
+		"sudo systemctl status docker 2>/dev/null || sudo service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'",
 	}
 
-	checkResults, checkErr := sshUtils.ExecuteCommands(hops, checkDockerCommands, 30000)
+	checkResults, checkErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, checkDockerCommands, 30000)
 
 	dockerInstalled := false
 	logContent := "도커 설치 로그를 가져올 수 없습니다."
@@ -2370,21 +2346,21 @@ func (h *DockerHandler) handleInstallDocker(c *gin.Context, request DockerComman
 
 		snapInstallCommands := []string{
 			// 스냅으로 도커 설치 시도
-			fmt.Sprintf("echo '%s' | sudo -S snap install docker >> /tmp/docker_install_snap.log 2>&1", password),
+			"sudo snap install docker >> /tmp/docker_install_snap.log 2>&1",
 			// 버전 확인
-			fmt.Sprintf("echo '%s' | sudo -S docker --version >> /tmp/docker_install_snap.log 2>&1 || echo '스냅 도커 명령어 실행 실패' >> /tmp/docker_install_snap.log", password),
+			"sudo docker --version >> /tmp/docker_install_snap.log 2>&1 || echo '스냅 도커 명령어 실행 실패' >> /tmp/docker_install_snap.log",
 		}
 
-		_, snapErr := sshUtils.ExecuteCommands(hops, snapInstallCommands, 180000) // 3분 타임아웃
+		_, snapErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, snapInstallCommands, 180000) // 3분 타임아웃
 
 		// 스냅 설치 결과 확인
 		if snapErr == nil {
 			snapCheckCommands := []string{
-				fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-				fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install_snap.log || echo '스냅 로그 파일을 읽을 수 없습니다.'", password),
+				"sudo docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+				"sudo cat /tmp/docker_install_snap.log || echo '스냅 로그 파일을 읽을 수 없습니다.'",
 			}
 
-			snapResults, _ := sshUtils.ExecuteCommands(hops, snapCheckCommands, 30000)
+			snapResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, snapCheckCommands, 30000)
 
 			if len(snapResults) >= 2 {
 				dockerInstalled = strings.Contains(snapResults[0].Output, "Docker version") ||
@@ -2448,6 +2424,7 @@ func (h *DockerHandler) handleUninstallDocker(c *gin.Context, request DockerComm
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -2464,60 +2441,57 @@ func (h *DockerHandler) handleUninstallDocker(c *gin.Context, request DockerComm
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := hops[len(hops)-1].Password
-
 	// 도커 제거 명령어 목록
 	commands := []string{
 		// 환경 설정
 		"export DEBIAN_FRONTEND=noninteractive",
-		fmt.Sprintf("echo 'DOCKER_OPTS=\"--dns 8.8.8.8 --dns 8.8.4.4\"' | sudo -S DEBIAN_FRONTEND=noninteractive tee /etc/default/docker > /dev/null 2>&1"),
+		fmt.Sprintf("echo 'DOCKER_OPTS=\"--dns 8.8.8.8 --dns 8.8.4.4\"' | sudo DEBIAN_FRONTEND=noninteractive tee /etc/default/docker > /dev/null 2>&1"),
 
 		// 모든 컨테이너 중지 및 삭제
-		fmt.Sprintf("echo '%s' | sudo -S docker container stop $(echo '%s' | sudo -S docker container ls -aq) > /dev/null 2>&1 || true", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S docker container rm -f $(echo '%s' | sudo -S docker container ls -aq) > /dev/null 2>&1 || true", password, password),
+		"sudo docker container stop $(sudo docker container ls -aq) > /dev/null 2>&1 || true",
+		"sudo docker container rm -f $(sudo docker container ls -aq) > /dev/null 2>&1 || true",
 
 		// 모든 이미지 삭제
-		fmt.Sprintf("echo '%s' | sudo -S docker image rm -f $(echo '%s' | sudo -S docker image ls -aq) > /dev/null 2>&1 || true", password, password),
+		"sudo docker image rm -f $(sudo docker image ls -aq) > /dev/null 2>&1 || true",
 
 		// 모든 볼륨 삭제
-		fmt.Sprintf("echo '%s' | sudo -S docker volume rm $(echo '%s' | sudo -S docker volume ls -q) > /dev/null 2>&1 || true", password, password),
+		"sudo docker volume rm $(sudo docker volume ls -q) > /dev/null 2>&1 || true",
 
 		// 모든 네트워크 삭제 (bridge, host, none 제외)
-		fmt.Sprintf("echo '%s' | sudo -S docker network rm $(echo '%s' | sudo -S docker network ls | awk '/bridge|host|none/ {next} {print $1}') > /dev/null 2>&1 || true", password, password),
+		"sudo docker network rm $(sudo docker network ls | awk '/bridge|host|none/ {next} {print $1}') > /dev/null 2>&1 || true",
 
 		// 도커 서비스 중지 및 제거
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl daemon-reload > /dev/null 2>&1 || true", password),
+		"sudo systemctl stop docker > /dev/null 2>&1 || true",
+		"sudo systemctl disable docker > /dev/null 2>&1 || true",
+		"sudo systemctl daemon-reload > /dev/null 2>&1 || true",
 
 		// 도커 패키지 및 관련 파일 제거
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get remove --purge -y docker-ce docker-ce-cli containerd.io docker-buildx-plugin docker-compose-plugin > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/docker /var/lib/containerd /etc/docker ~/.docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.asc > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/systemd/system/docker.service /etc/systemd/system/docker.socket > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S groupdel docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -f $(which docker 2>/dev/null) /usr/local/bin/docker /usr/sbin/docker > /dev/null 2>&1 || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get remove --purge -y docker-ce docker-ce-cli containerd.io docker-buildx-plugin docker-compose-plugin > /dev/null 2>&1 || true",
+		"sudo rm -rf /var/lib/docker /var/lib/containerd /etc/docker ~/.docker > /dev/null 2>&1 || true",
+		"sudo rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.asc > /dev/null 2>&1 || true",
+		"sudo rm -rf /etc/systemd/system/docker.service /etc/systemd/system/docker.socket > /dev/null 2>&1 || true",
+		"sudo groupdel docker > /dev/null 2>&1 || true",
+		"sudo rm -f $(which docker 2>/dev/null) /usr/local/bin/docker /usr/sbin/docker > /dev/null 2>&1 || true",
 
 		// snap으로 설치된 도커 제거
-		fmt.Sprintf("echo '%s' | sudo -S snap remove docker > /dev/null 2>&1 || true", password),
+		"sudo snap remove docker > /dev/null 2>&1 || true",
 
 		// dpkg로 설치된 도커 관련 패키지 제거
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive dpkg --purge $(dpkg -l | awk '/docker/{print $2}') > /dev/null 2>&1 || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive dpkg --purge $(dpkg -l | awk '/docker/{print $2}') > /dev/null 2>&1 || true",
 
 		// 시스템 정리
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoremove -y > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoclean -y > /dev/null 2>&1 || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoremove -y > /dev/null 2>&1 || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoclean -y > /dev/null 2>&1 || true",
 
 		// 도커 제거 확인
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>&1 || echo 'DOCKER_REMOVED'", password),
+		"sudo docker --version 2>&1 || echo 'DOCKER_REMOVED'",
 	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands, 300000) // 5분 타임아웃
 
 	// 도커가 성공적으로 제거되었는지 확인
 	dockerRemoved := false
@@ -2581,6 +2555,7 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	}
@@ -2600,6 +2575,11 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 		}
 	}
 
+	requestedID, _ := request.Parameters["container_id"].(string)
+	if !validateShellValues(c, shellValue{"container_id", command.FormatContainerID, requestedID}) {
+		return
+	}
+
 	// SSH 클라이언트 직접 생성하여 로그 추출
 	client, err := h.createSSHClient(hops)
 	if err != nil {
@@ -2611,12 +2591,12 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 	}
 	defer client.Close()
 
-	// 마지막 Hop의 패스워드 가져오기
-	password := hops[0].Password
+	// NOPASSWD 호스트에서는 sudo 비밀번호를 stdin으로 보내지 않도록 sudo 방식을 먼저 확인
+	sudoHop := client.ProbeSudo(hops[len(hops)-1])
 
 	// 1. 컨테이너 상태 확인 - 파이프 처리를 위해 명령을 분리
-	statusCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"id=%s\" --format \"{{.ID}}|{{.Names}}|{{.Status}}\"",
-		password, request.Parameters["container_id"])
+	statusCmd := command.Render("sudo docker ps -a --filter %s --format '{{.ID}}|{{.Names}}|{{.Status}}'",
+		"id="+requestedID)
 
 	session, err := client.NewSession()
 	if err != nil {
@@ -2630,7 +2610,7 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 	var statusOutput bytes.Buffer
 	session.Stdout = &statusOutput
 
-	if err := session.Run(statusCmd); err != nil {
+	if err := session.Run(ssh.SudoCommand(session, sudoHop, statusCmd)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("컨테이너 상태 확인 명령 실행 실패: %v", err),
@@ -2667,8 +2647,8 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 	tmpFileName := fmt.Sprintf("/tmp/docker_logs_%s_%d.txt", containerID, time.Now().Unix())
 
 	// 로그를 임시 파일로 저장 - 오류 출력 포함하여 전체 정보 보존
-	logCmd := fmt.Sprintf("echo '%s' | sudo -S docker logs --tail %d %s > %s",
-		password, linesInt, containerID, tmpFileName)
+	logCmd := command.Render("sudo docker logs --tail %d %s > %s",
+		linesInt, containerID, tmpFileName)
 
 	session, err = client.NewSession()
 	if err != nil {
@@ -2679,7 +2659,7 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 		return
 	}
 
-	if err := session.Run(logCmd); err != nil {
+	if err := session.Run(ssh.SudoCommand(session, sudoHop, logCmd)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("로그 명령 실행 실패: %v", err),
@@ -2689,7 +2669,7 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 	session.Close()
 
 	// 3. 임시 파일에서 로그 내용 읽기
-	catCmd := fmt.Sprintf("cat %s", tmpFileName)
+	catCmd := command.Render("cat %s", tmpFileName)
 	session, err = client.NewSession()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -2712,7 +2692,7 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 	session.Close()
 
 	// 4. 임시 파일 삭제
-	cleanupCmd := fmt.Sprintf("rm -f %s", tmpFileName)
+	cleanupCmd := command.Render("rm -f %s", tmpFileName)
 	session, err = client.NewSession()
 	if err != nil {
 		// 파일 삭제 실패해도 계속 진행
@@ -2735,24 +2715,13 @@ func (h *DockerHandler) handleGetDockerLogs(c *gin.Context, request DockerComman
 }
 
 // SSH 클라이언트 생성을 위한 헬퍼 함수
-func (h *DockerHandler) createSSHClient(hops []ssh.HopConfig) (*gossh.Client, error) {
+// 공유 연결 풀에서 hops 체인 연결을 가져오며, Close하면 풀에 반납됩니다
+func (h *DockerHandler) createSSHClient(hops []ssh.HopConfig) (*ssh.Client, error) {
 	if len(hops) == 0 {
 		return nil, fmt.Errorf("SSH 연결 정보가 없습니다")
 	}
 
-	hop := hops[0]
-
-	sshConfig := &gossh.ClientConfig{
-		User: hop.Username,
-		Auth: []gossh.AuthMethod{
-			gossh.Password(hop.Password),
-		},
-		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
-		Timeout:         time.Duration(60) * time.Second,
-	}
-
-	hostPort := fmt.Sprintf("%s:%d", hop.Host, hop.Port)
-	client, err := gossh.Dial("tcp", hostPort, sshConfig)
+	client, err := ssh.NewSSHService().Acquire(hops, time.Duration(60)*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("SSH 연결 실패: %v", err)
 	}
@@ -2789,6 +2758,7 @@ func (h *DockerHandler) handleImportDockerInfra(c *gin.Context, request DockerCo
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -2801,21 +2771,15 @@ func (h *DockerHandler) handleImportDockerInfra(c *gin.Context, request DockerCo
 
 	name := request.Parameters["name"].(string)
 
-	// 마지막 hop의 패스워드 가져오기
-	lastHopPassword := ""
-	if len(hops) > 0 {
-		lastHopPassword = hops[len(hops)-1].Password
-	}
-
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
-	// 단계 1: docker 명령어 가능한지 확인 (sudo -S 사용)
-	dockerCheckCmd := fmt.Sprintf("echo '%s' | sudo -S docker version || echo 'DOCKER_NOT_FOUND'", lastHopPassword)
-	dockerResults, err := sshUtils.ExecuteCommands(hops, []string{dockerCheckCmd}, 30000)
+	// 단계 1: docker 명령어 가능한지 확인 (sudo 사용)
+	dockerCheckCmd := "sudo docker version || echo 'DOCKER_NOT_FOUND'"
+	dockerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{dockerCheckCmd}, 30000)
 
 	if err != nil || len(dockerResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "SSH 연결 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2831,11 +2795,11 @@ func (h *DockerHandler) handleImportDockerInfra(c *gin.Context, request DockerCo
 	}
 
 	// 단계 2: 컨테이너 정보 수집
-	containerCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --format '{{.ID}}|{{.Names}}|{{.Image}}|{{.Networks}}'", lastHopPassword)
-	containerResults, err := sshUtils.ExecuteCommands(hops, []string{containerCmd}, 30000)
+	containerCmd := "sudo docker ps -a --format '{{.ID}}|{{.Names}}|{{.Image}}|{{.Networks}}'"
+	containerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{containerCmd}, 30000)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "도커 컨테이너 정보 수집 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2963,3 +2927,8 @@ func (h *DockerHandler) handleImportDockerInfra(c *gin.Context, request DockerCo
 		"service_groups":      serviceGroups,
 	})
 }
+
+// handleDryRun은 도커 액션의 실행 계획을 반환합니다
+func (h *DockerHandler) handleDryRun(c *gin.Context, request DockerCommandRequest) {
+	respondActionPlan(c, h.repos.Servers, h.cmdManager, request.Action, request.Parameters)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// respondActionPlan은 액션을 실행하지 않고 실행 계획(대상 서버와 렌더링된 명령어)을 응답합니다
+// 파라미터 검증과 대상 조회는 실제 실행과 같게 수행하며 SSH 연결은 열지 않습니다
+func respondActionPlan(c *gin.Context, servers db.ServerRepository, cm *command.CommandManager, action string, params map[string]interface{}) {
+	if !Features.DryRun {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "드라이런이 비활성화되어 있습니다"})
+		return
+	}
+	if !cm.HasCommandTemplate(action) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "드라이런을 지원하지 않는 액션입니다: " + action})
+		return
+	}
+
+	target, params, err := resolveActionTarget(servers, params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	plan, err := cm.PlanAction(action, params, target)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "실행 계획 생성 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"dry_run": true,
+		"plan":    plan,
+	})
+}
+
+// resolveActionTarget은 요청 파라미터로 액션의 실행 대상을 찾습니다
+// hops 파라미터가 있으면 사용하고, 없으면 server_id로 DB에 저장된 hops를 사용합니다
+// <이름>_hops 파라미터(예: lb_hops)는 같은 이름의 추가 대상으로 등록합니다 (lb_hops → "lb")
+// DB에서 찾은 서버 이름과 main_id 서버의 join 명령어처럼 핸들러가 채우는 값도 비어 있으면 채웁니다
+func resolveActionTarget(servers db.ServerRepository, params map[string]interface{}) (*command.CommandTarget, map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		resolved[k] = v
+	}
+
+	target := &command.CommandTarget{Hops: parseHopsParameter(params["hops"])}
+	if value, ok := params["server_id"]; ok && (len(target.Hops) == 0 || resolved["server_name"] == nil) {
+		serverID, err := getIntParameter(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("유효하지 않은 server_id입니다: %v", value)
+		}
+		serverInfo, err := servers.GetInfo(serverID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(target.Hops) == 0 {
+			if err := loadServerHops(serverInfo.Hops, &target.Hops); err != nil {
+				return nil, nil, fmt.Errorf("hops 파싱 중 오류가 발생했습니다: %w", err)
+			}
+		}
+		if resolved["server_name"] == nil {
+			resolved["server_name"] = serverInfo.ServerName
+		}
+	}
+	if len(target.Hops) == 0 {
+		return nil, nil, fmt.Errorf("대상 서버 정보가 없습니다 (hops 또는 server_id가 필요합니다)")
+	}
+	password, _ := params["password"].(string)
+	setSudoPassword(target.Hops, password)
+
+	for key, value := range params {
+		name := strings.TrimSuffix(key, "_hops")
+		if key == "hops" || name == key {
+			continue
+		}
+		if hops := parseHopsParameter(value); len(hops) > 0 {
+			if target.Targets == nil {
+				target.Targets = make(map[string][]ssh.HopConfig)
+			}
+			password, _ := params[name+"_password"].(string)
+			setSudoPassword(hops, password)
+			target.Targets[name] = hops
+		}
+	}
+
+	if value, ok := params["main_id"]; ok && resolved["join_command"] == nil {
+		mainID, err := getIntParameter(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("유효하지 않은 main_id입니다: %v", value)
+		}
+		mainInfo, err := servers.GetInfo(mainID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("메인 마스터 노드 정보를 가져오는 중 오류가 발생했습니다: %w", err)
+		}
+		resolved["join_command"] = mainInfo.JoinCommand
+		resolved["certificate_key"] = mainInfo.CertificateKey
+	}
+
+	return target, resolved, nil
+}
+
+// parseHopsParameter는 요청 파라미터의 hops 배열을 []ssh.HopConfig로 변환합니다
+func parseHopsParameter(value interface{}) []ssh.HopConfig {
+	hopsData, ok := value.([]interface{})
+	if !ok || len(hopsData) == 0 {
+		return nil
+	}
+
+	hops := make([]ssh.HopConfig, 0, len(hopsData))
+	for _, hop := range hopsData {
+		hopMap, ok := hop.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		host, _ := hopMap["host"].(string)
+		username, _ := hopMap["username"].(string)
+		password, _ := hopMap["password"].(string)
+		port := 22 // 기본값
+		if portVal, ok := hopMap["port"].(float64); ok {
+			port = int(portVal)
+		} else if portStr, ok := hopMap["port"].(string); ok {
+			if portInt, err := strconv.Atoi(portStr); err == nil {
+				port = portInt
+			}
+		}
+		config := ssh.HopConfig{
+			Host:     host,
+			Port:     port,
+			Username: username,
+			Password: password,
+		}
+		applyHopAuthFields(&config, hopMap)
+		hops = append(hops, config)
+	}
+	return hops
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+func TestHandleRequestDryRun(t *testing.T) {
+	h := NewKubernetesHandler(nil, &db.Repositories{})
+	hops := []gin.H{{"host": "10.0.0.5", "port": 22, "username": "ubuntu", "password": "hop-pass"}}
+
+	code, resp := performJSON(t, h.HandleRequest, gin.H{
+		"action":     "getNodeStatus",
+		"dry_run":    true,
+		"parameters": gin.H{"hops": hops, "server_name": "master-1", "type": "master"},
+	})
+	if code != http.StatusOK || resp["dry_run"] != true {
+		t.Fatalf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+	plan := resp["plan"].(map[string]interface{})
+	targets := plan["targets"].([]interface{})
+	if target := targets[0].(map[string]interface{}); target["host"] != "10.0.0.5" || target["route"].([]interface{})[0] != "10.0.0.5:22" {
+		t.Errorf("대상 = %v", target)
+	}
+	steps := plan["steps"].([]interface{})
+	if commands := steps[0].(map[string]interface{})["commands"].([]interface{}); len(commands) == 0 {
+		t.Errorf("렌더링된 명령어가 없습니다: %v", steps)
+	}
+	if data, _ := json.Marshal(resp); strings.Contains(string(data), "hop-pass") {
+		t.Error("hop 비밀번호가 응답에 노출됨")
+	}
+
+	tests := []struct {
+		name string
+		body gin.H
+	}{
+		{"템플릿 없는 액션", gin.H{"action": ActionGetServers, "dry_run": true}},
+		{"대상 없음", gin.H{"action": "getNodeStatus", "dry_run": true, "parameters": gin.H{}}},
+	}
+	for _, tt := range tests {
+		if code, resp := performJSON(t, h.HandleRequest, tt.body); code != http.StatusBadRequest {
+			t.Errorf("%s: 상태 코드 = %d, 응답: %v", tt.name, code, resp)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"bytes"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,24 +12,31 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
 	"github.com/k8scontrol/backend/internal/db"
 	"github.com/k8scontrol/backend/internal/utils"
 	"github.com/k8scontrol/backend/pkg/ssh"
-	gossh "golang.org/x/crypto/ssh"
 )
 
 // InfraHandler 인프라 관련 API 핸들러
 type InfraDockerHandler struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Repos *db.Repositories
 }
 
 // NewInfraDockerHandler 새 InfraDockerHandler 생성
-func NewInfraDockerHandler(db *sql.DB) *InfraDockerHandler {
-	return &InfraDockerHandler{DB: db}
+func NewInfraDockerHandler(database *sql.DB, repos *db.Repositories) *InfraDockerHandler {
+	return &InfraDockerHandler{DB: database, Repos: repos}
 }
 
 // InstallDocker는 원격 서버에 도커를 설치합니다.
 func (h *InfraDockerHandler) InstallDocker(c *gin.Context) {
+	// ?async=true이면 작업으로 실행하고 작업 ID를 즉시 반환
+	if asyncRequested(c, nil) {
+		startHandlerJob(c, "installDocker", "", h.InstallDocker)
+		return
+	}
+
 	var requestBody struct {
 		ID   int             `json:"id"`
 		Hops []ssh.HopConfig `json:"hops"`
@@ -43,7 +49,7 @@ func (h *InfraDockerHandler) InstallDocker(c *gin.Context) {
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, requestBody.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(requestBody.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -54,7 +60,7 @@ func (h *InfraDockerHandler) InstallDocker(c *gin.Context) {
 	if len(requestBody.Hops) > 0 {
 		hops = requestBody.Hops
 	} else {
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "hops 파싱 중 오류가 발생했습니다."})
 			return
 		}
@@ -65,19 +71,13 @@ func (h *InfraDockerHandler) InstallDocker(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	// Ubuntu 버전 확인 명령어
 	checkUbuntuVersionCmd := "lsb_release -rs || cat /etc/os-release | grep VERSION_ID | cut -d'\"' -f2"
 
 	sshUtils := utils.NewSSHUtils()
-	versionResults, err := sshUtils.ExecuteCommands(hops, []string{checkUbuntuVersionCmd}, 30000)
+	versionResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkUbuntuVersionCmd}, 30000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Ubuntu 버전 확인 중 오류가 발생했습니다: " + err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "Ubuntu 버전 확인 중 오류가 발생했습니다: " + err.Error()})
 		return
 	}
 
@@ -91,11 +91,11 @@ func (h *InfraDockerHandler) InstallDocker(c *gin.Context) {
 
 	// Docker가 이미 설치되어 있는지 확인
 	checkDockerExistsCmd := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-		fmt.Sprintf("echo '%s' | sudo -S docker ps -a 2>/dev/null | grep -v CONTAINER | wc -l || echo '0'", password), // 실행 중인 컨테이너 수 확인
+		"sudo docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+		"sudo docker ps -a 2>/dev/null | grep -v CONTAINER | wc -l || echo '0'", // 실행 중인 컨테이너 수 확인
 	}
 
-	existResults, _ := sshUtils.ExecuteCommands(hops, checkDockerExistsCmd, 30000)
+	existResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, checkDockerExistsCmd, 30000)
 	dockerExists := false
 	containerCount := 0
 
@@ -138,72 +138,72 @@ func (h *InfraDockerHandler) InstallDocker(c *gin.Context) {
 	// 공통 설치 준비 명령어
 	prepCommands := []string{
 		// 패키지 시스템 초기화 및 손상된 패키지 수정
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update > /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y ca-certificates curl gnupg software-properties-common apt-transport-https >> /tmp/docker_install.log 2>&1", password),
+		"sudo apt-get update > /tmp/docker_install.log 2>&1",
+		"sudo apt-get install -y ca-certificates curl gnupg software-properties-common apt-transport-https >> /tmp/docker_install.log 2>&1",
 		// APT 패키지 상태 복구 명령
-		fmt.Sprintf("echo '%s' | sudo -S apt-get -f install >> /tmp/docker_install.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S dpkg --configure -a >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get -f install >> /tmp/docker_install.log 2>&1 || true",
+		"sudo dpkg --configure -a >> /tmp/docker_install.log 2>&1 || true",
 	}
 	installDockerCommands = append(installDockerCommands, prepCommands...)
 
 	// Docker 공식 설치 스크립트 사용 (가장 안정적인 방법)
 	dockerScriptCommands := []string{
 		// 기존 설치 파일 및 디렉토리 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.gpg /etc/apt/keyrings/docker.asc /tmp/docker.gpg >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.gpg /etc/apt/keyrings/docker.asc /tmp/docker.gpg >> /tmp/docker_install.log 2>&1 || true",
 		// 기존 도커 관련 패키지 제거
-		fmt.Sprintf("echo '%s' | sudo -S apt-get remove -y docker docker-engine docker.io containerd runc >> /tmp/docker_install.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get autoremove -y >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get remove -y docker docker-engine docker.io containerd runc >> /tmp/docker_install.log 2>&1 || true",
+		"sudo apt-get autoremove -y >> /tmp/docker_install.log 2>&1 || true",
 		// APT 업데이트
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get update >> /tmp/docker_install.log 2>&1 || true",
 		// Docker 공식 설치 스크립트 다운로드 및 실행
-		fmt.Sprintf("echo '%s' | sudo -S curl -fsSL https://get.docker.com -o /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S sh /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1", password),
+		"sudo curl -fsSL https://get.docker.com -o /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1",
+		"sudo sh /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1",
 		// Docker 서비스 시작 및 활성화
-		fmt.Sprintf("echo '%s' | sudo -S systemctl start docker >> /tmp/docker_install.log 2>&1 || echo '%s' | sudo -S service docker start >> /tmp/docker_install.log 2>&1 || true", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable docker >> /tmp/docker_install.log 2>&1 || echo '%s' | sudo -S service docker enable >> /tmp/docker_install.log 2>&1 || true", password, password),
+		"sudo systemctl start docker >> /tmp/docker_install.log 2>&1 || sudo service docker start >> /tmp/docker_install.log 2>&1 || true",
+		"sudo systemctl enable docker >> /tmp/docker_install.log 2>&1 || sudo service docker enable >> /tmp/docker_install.log 2>&1 || true",
 		// 현재 사용자를 도커 그룹에 추가
-		fmt.Sprintf("echo '%s' | sudo -S groupadd -f docker >> /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S usermod -aG docker $(whoami) >> /tmp/docker_install.log 2>&1", password),
+		"sudo groupadd -f docker >> /tmp/docker_install.log 2>&1",
+		"sudo usermod -aG docker $(whoami) >> /tmp/docker_install.log 2>&1",
 		// 설치 확인
 		"echo '도커 설치 시도 완료' >> /tmp/docker_install.log",
-		fmt.Sprintf("echo '%s' | sudo -S docker --version >> /tmp/docker_install.log 2>&1 || echo '도커 명령어 실행 실패' >> /tmp/docker_install.log", password),
+		"sudo docker --version >> /tmp/docker_install.log 2>&1 || echo '도커 명령어 실행 실패' >> /tmp/docker_install.log",
 	}
 
 	installDockerCommands = append(installDockerCommands, dockerScriptCommands...)
 
 	// 도커 설치 실행
-	_, err = sshUtils.ExecuteCommands(hops, installDockerCommands, 300000) // 5분 타임아웃
+	_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, installDockerCommands, 300000) // 5분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "도커 설치 중 오류가 발생했습니다: " + err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "도커 설치 중 오류가 발생했습니다: " + err.Error()})
 		return
 	}
 
 	// 모든 패키지 업데이트 및 재시도
 	retryDockerCommands := []string{
 		// 패키지 업데이트 및 업그레이드
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update >> /tmp/docker_install_retry.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get upgrade -y >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo apt-get update >> /tmp/docker_install_retry.log 2>&1 || true",
+		"sudo apt-get upgrade -y >> /tmp/docker_install_retry.log 2>&1 || true",
 		// 도커 패키지 직접 설치 (표준 방식)
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y docker-ce docker-ce-cli containerd.io docker-compose-plugin >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo apt-get install -y docker-ce docker-ce-cli containerd.io docker-compose-plugin >> /tmp/docker_install_retry.log 2>&1 || true",
 		// 서비스 시작
-		fmt.Sprintf("echo '%s' | sudo -S systemctl start docker >> /tmp/docker_install_retry.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable docker >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo systemctl start docker >> /tmp/docker_install_retry.log 2>&1 || true",
+		"sudo systemctl enable docker >> /tmp/docker_install_retry.log 2>&1 || true",
 	}
 
-	_, retryErr := sshUtils.ExecuteCommands(hops, retryDockerCommands, 300000)
+	_, retryErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, retryDockerCommands, 300000)
 	if retryErr != nil {
 		log.Println("도커 재설치 중 오류 발생:", retryErr)
 	}
 
 	// 설치 성공 여부 확인
 	checkDockerCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install.log || echo '로그 파일을 읽을 수 없습니다.'", password),
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install_retry.log 2>/dev/null || echo '재시도 로그 없음'", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl status docker 2>/dev/null || echo '%s' | sudo -S service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'", password, password),
+		"sudo docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+		"sudo cat /tmp/docker_install.log || echo '로그 파일을 읽을 수 없습니다.'",
+		"sudo cat /tmp/docker_install_retry.log 2>/dev/null || echo '재시도 로그 없음'",
+		"sudo systemctl status docker 2>/dev/null || sudo service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'",
 	}
 
-	checkResults, checkErr := sshUtils.ExecuteCommands(hops, checkDockerCommands, 30000)
+	checkResults, checkErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, checkDockerCommands, 30000)
 
 	dockerInstalled := false
 	logContent := "도커 설치 로그를 가져올 수 없습니다."
@@ -257,21 +257,21 @@ func (h *InfraDockerHandler) InstallDocker(c *gin.Context) {
 
 		snapInstallCommands := []string{
 			// 스냅으로 도커 설치 시도
-			fmt.Sprintf("echo '%s' | sudo -S snap install docker >> /tmp/docker_install_snap.log 2>&1", password),
+			"sudo snap install docker >> /tmp/docker_install_snap.log 2>&1",
 			// 버전 확인
-			fmt.Sprintf("echo '%s' | sudo -S docker --version >> /tmp/docker_install_snap.log 2>&1 || echo '스냅 도커 명령어 실행 실패' >> /tmp/docker_install_snap.log", password),
+			"sudo docker --version >> /tmp/docker_install_snap.log 2>&1 || echo '스냅 도커 명령어 실행 실패' >> /tmp/docker_install_snap.log",
 		}
 
-		_, snapErr := sshUtils.ExecuteCommands(hops, snapInstallCommands, 180000) // 3분 타임아웃
+		_, snapErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, snapInstallCommands, 180000) // 3분 타임아웃
 
 		// 스냅 설치 결과 확인
 		if snapErr == nil {
 			snapCheckCommands := []string{
-				fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-				fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install_snap.log || echo '스냅 로그 파일을 읽을 수 없습니다.'", password),
+				"sudo docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+				"sudo cat /tmp/docker_install_snap.log || echo '스냅 로그 파일을 읽을 수 없습니다.'",
 			}
 
-			snapResults, _ := sshUtils.ExecuteCommands(hops, snapCheckCommands, 30000)
+			snapResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, snapCheckCommands, 30000)
 
 			if len(snapResults) >= 2 {
 				dockerInstalled = strings.Contains(snapResults[0].Output, "Docker version") ||
@@ -344,7 +344,7 @@ func (h *InfraDockerHandler) CreateDockerContainer(c *gin.Context) {
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, request.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(request.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -355,7 +355,7 @@ func (h *InfraDockerHandler) CreateDockerContainer(c *gin.Context) {
 	if len(request.Hops) > 0 {
 		hops = request.Hops
 	} else {
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "hops 파싱 중 오류가 발생했습니다."})
 			return
 		}
@@ -366,17 +366,21 @@ func (h *InfraDockerHandler) CreateDockerContainer(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	// 기본값 설정
 	if request.Branch == "" {
 		request.Branch = "main"
 	}
 
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"repo_url", command.FormatGitURL, request.RepoURL},
+		shellValue{"branch", command.FormatGitRef, request.Branch}) {
+		return
+	}
+	if request.ComposeProject != "" && !validateShellValues(c, shellValue{"compose_project", command.FormatContainerID, request.ComposeProject}) {
+		return
+	}
+
 	// docker-compose.yml 경로 설정 (기본: 저장소 루트의 docker-compose.yml)
 	if request.ComposePath == "" {
 		request.ComposePath = "docker-compose.yml" // ./ 제거
@@ -404,48 +408,30 @@ func (h *InfraDockerHandler) CreateDockerContainer(c *gin.Context) {
 
 	// 1. 작업 디렉토리 생성 및 이전 빌드 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S mkdir -p %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S chown $(whoami):$(whoami) %s", password, workDir)) // 디렉토리 권한 변경
+		command.Render("sudo rm -rf %s", workDir),
+		command.Render("sudo mkdir -p %s", workDir),
+		command.Render("sudo chown $(whoami):$(whoami) %s", workDir)) // 디렉토리 권한 변경
 
 	// 2. 필요한 도구 설치 확인 (Git)
 	commands = append(commands,
-		fmt.Sprintf("which git || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y git)", password, password))
+		"which git || (sudo apt-get update && sudo apt-get install -y git)")
 
 	// 3. Docker Compose 경로 확인 및 설치
 	commands = append(commands,
 		"which docker-compose || echo 'DOCKER_COMPOSE_NOT_FOUND'",
 		"which /snap/bin/docker-compose || echo 'SNAP_DOCKER_COMPOSE_NOT_FOUND'",
-		fmt.Sprintf("which docker-compose || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y docker-compose)", password, password))
+		"which docker-compose || (sudo apt-get update && sudo apt-get install -y docker-compose)")
 
 	// 4. 저장소 클론 (Git 인증 오류 로깅 개선)
-	gitCmd := ""
-	if request.UsernameRepo != "" && request.PasswordRepo != "" {
-		// URL에서 프로토콜 부분 제거
-		repoNoProtocol := strings.TrimPrefix(request.RepoURL, "https://")
-		repoNoProtocol = strings.TrimPrefix(repoNoProtocol, "http://")
-
-		// @ 문자가 포함된 사용자명 처리 (이메일 주소 등)
-		encodedUsername := request.UsernameRepo
-		if strings.Contains(encodedUsername, "@") {
-			// 사용자명이 이메일인 경우 URL 인코딩 적용
-			encodedUsername = strings.ReplaceAll(encodedUsername, "@", "%40")
-		}
-
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s https://%s:%s@%s . 2>&1 || echo 'Git 클론 실패'",
-			workDir,
-			request.Branch,
-			encodedUsername,
-			request.PasswordRepo,
-			repoNoProtocol)
-	} else {
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s %s . 2>&1 || echo 'Git 클론 실패'",
-			workDir, request.Branch, request.RepoURL)
+	gitCmd, err := gitCloneCommand(workDir, request.RepoURL, request.Branch, request.UsernameRepo, request.PasswordRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
 	}
 	commands = append(commands, gitCmd)
 
 	// 5. 클론 성공 확인 및 디렉토리 내용 확인
-	commands = append(commands, fmt.Sprintf("cd %s && ls -la", workDir))
+	commands = append(commands, command.Render("cd %s && ls -la", workDir))
 
 	// 6. docker-compose.yml 파일 존재 확인 (절대 경로 사용)
 	checkComposePath := request.ComposePath
@@ -456,30 +442,30 @@ func (h *InfraDockerHandler) CreateDockerContainer(c *gin.Context) {
 	yamlPath := fmt.Sprintf("%s/docker-compose.yaml", workDir)
 
 	// 둘 중 하나라도 있으면 그 파일 사용
-	commands = append(commands, fmt.Sprintf("if [ -f %s ]; then echo 'YML_EXISTS'; elif [ -f %s ]; then echo 'YAML_EXISTS'; else echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다!'; fi", ymlPath, yamlPath))
+	commands = append(commands, command.Render("if [ -f %s ]; then echo 'YML_EXISTS'; elif [ -f %s ]; then echo 'YAML_EXISTS'; else echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다!'; fi", ymlPath, yamlPath))
 
 	// 7. 기존 컨테이너 정리 (강제 재생성 옵션이 켜져있는 경우)
 	if request.ForceRecreate {
-		downCommand := fmt.Sprintf("cd %s && echo '%s' | sudo -S docker-compose -p %s down",
-			workDir, password, request.ComposeProject)
+		downCommand := command.Render("cd %s && sudo docker-compose -p %s down",
+			workDir, request.ComposeProject)
 		commands = append(commands, downCommand)
 	}
 
 	// 8.5 Docker 레지스트리 로그인 (필요한 경우)
 	if request.DockerRegistry != "" && request.DockerUsername != "" && request.DockerPassword != "" {
 		// Docker 로그인 명령 추가
-		loginCmd := fmt.Sprintf("echo '%s' | docker login %s -u %s --password-stdin",
+		loginCmd := command.Render("printf '%%s' %s | docker login %s -u %s --password-stdin",
 			request.DockerPassword, request.DockerRegistry, request.DockerUsername)
 		commands = append(commands, loginCmd)
 	}
 
 	// 9. Docker Compose로 빌드 및 실행
-	commands = append(commands, fmt.Sprintf("cd %s && echo '%s' | sudo -S docker-compose -p %s up -d --build",
-		workDir, password, request.ComposeProject))
+	commands = append(commands, command.Render("cd %s && sudo docker-compose -p %s up -d --build",
+		workDir, request.ComposeProject))
 
 	// 10. 컨테이너 상태 확인
-	commands = append(commands, fmt.Sprintf("cd %s && echo '%s' | sudo -S docker-compose -p %s ps",
-		workDir, password, request.ComposeProject))
+	commands = append(commands, command.Render("cd %s && sudo docker-compose -p %s ps",
+		workDir, request.ComposeProject))
 
 	// 11. 도커 이미지 및 컨테이너 목록 확인 (디버깅용)
 	commands = append(commands,
@@ -488,12 +474,12 @@ func (h *InfraDockerHandler) CreateDockerContainer(c *gin.Context) {
 
 	// 12. 임시 빌드 폴더 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir))
+		command.Render("sudo rm -rf %s", workDir))
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 600000) // 10분 타임아웃
+	results, err := sshUtils.ExecuteCommandsStream(c.Request.Context(), hops, commands, 600000, logOutputHandler("컨테이너 생성")) // 10분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "명령 실행 중 오류가 발생했습니다: " + err.Error(),
 			"logs":    formatResults(results),
@@ -871,7 +857,7 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, request.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(request.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -882,7 +868,7 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 	if len(request.Hops) > 0 {
 		hops = request.Hops
 	} else {
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "hops 파싱 중 오류가 발생했습니다."})
 			return
 		}
@@ -893,17 +879,21 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	// 기본값 설정
 	if request.Branch == "" {
 		request.Branch = "main"
 	}
 
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"repo_url", command.FormatGitURL, request.RepoURL},
+		shellValue{"branch", command.FormatGitRef, request.Branch}) {
+		return
+	}
+	if request.ComposeProject != "" && !validateShellValues(c, shellValue{"compose_project", command.FormatContainerID, request.ComposeProject}) {
+		return
+	}
+
 	// 저장소 이름 추출 (URL의 마지막 부분)
 	repoName := extractRepoName(request.RepoURL)
 
@@ -914,8 +904,8 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 	sshUtils := utils.NewSSHUtils()
 
 	// 현재 실행 중인 컨테이너 상태 확인 (작업 전)
-	initialContainerCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
-	initialContainerResults, _ := sshUtils.ExecuteCommands(hops, []string{initialContainerCmd}, 30000)
+	initialContainerCmd := "sudo docker ps -a"
+	initialContainerResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{initialContainerCmd}, 30000)
 	var initialContainers string
 	if len(initialContainerResults) > 0 {
 		initialContainers = initialContainerResults[0].Output
@@ -926,51 +916,33 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 
 	// 1. 작업 디렉토리 생성 및 이전 빌드 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S mkdir -p %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S chown $(whoami):$(whoami) %s", password, workDir))
+		command.Render("sudo rm -rf %s", workDir),
+		command.Render("sudo mkdir -p %s", workDir),
+		command.Render("sudo chown $(whoami):$(whoami) %s", workDir))
 
 	// 2. 필요한 도구 설치 확인 (Git)
 	commands = append(commands,
-		fmt.Sprintf("which git || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y git)", password, password))
+		"which git || (sudo apt-get update && sudo apt-get install -y git)")
 
 	// 3. 저장소 클론 (Git 인증 오류 로깅 개선)
-	gitCmd := ""
-	if request.UsernameRepo != "" && request.PasswordRepo != "" {
-		// URL에서 프로토콜 부분 제거
-		repoNoProtocol := strings.TrimPrefix(request.RepoURL, "https://")
-		repoNoProtocol = strings.TrimPrefix(repoNoProtocol, "http://")
-
-		// @ 문자가 포함된 사용자명 처리 (이메일 주소 등)
-		encodedUsername := request.UsernameRepo
-		if strings.Contains(encodedUsername, "@") {
-			// 사용자명이 이메일인 경우 URL 인코딩 적용
-			encodedUsername = strings.ReplaceAll(encodedUsername, "@", "%40")
-		}
-
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s https://%s:%s@%s . 2>&1 || echo 'Git 클론 실패'",
-			workDir,
-			request.Branch,
-			encodedUsername,
-			request.PasswordRepo,
-			repoNoProtocol)
-	} else {
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s %s . 2>&1 || echo 'Git 클론 실패'",
-			workDir, request.Branch, request.RepoURL)
+	gitCmd, err := gitCloneCommand(workDir, request.RepoURL, request.Branch, request.UsernameRepo, request.PasswordRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
 	}
 	commands = append(commands, gitCmd)
 
 	// 4. 클론 성공 확인 및 디렉토리 내용 확인
-	commands = append(commands, fmt.Sprintf("cd %s && ls -la", workDir))
+	commands = append(commands, command.Render("cd %s && ls -la", workDir))
 
 	// 5. docker-compose 파일 존재하는지 확인하고 내용 확인
-	composeCheckCmd := fmt.Sprintf("cd %s && ([ -f docker-compose.yml ] && echo 'FOUND_YML' && cat docker-compose.yml) || ([ -f docker-compose.yaml ] && echo 'FOUND_YAML' && cat docker-compose.yaml) || echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다.'", workDir)
+	composeCheckCmd := command.Render("cd %s && ([ -f docker-compose.yml ] && echo 'FOUND_YML' && cat docker-compose.yml) || ([ -f docker-compose.yaml ] && echo 'FOUND_YAML' && cat docker-compose.yaml) || echo 'ERROR: docker-compose.yml 또는 docker-compose.yaml 파일을 찾을 수 없습니다.'", workDir)
 	commands = append(commands, composeCheckCmd)
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands, 300000) // 5분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "명령 실행 중 오류가 발생했습니다: " + err.Error(),
 			"logs":    formatResults(results),
@@ -1054,13 +1026,13 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 	// 6.1. 첫 번째 전략: 일반적인 docker-compose down 명령 (많은 경우 작동)
 	// 두 파일 확장자 모두 시도
 	// YML 파일로 시도
-	composeDownCmdYml := fmt.Sprintf("cd %s && [ -f docker-compose.yml ] && echo '%s' | sudo -S docker-compose -p %s -f docker-compose.yml down -v --remove-orphans 2>&1 || echo 'YML_FILE_NOT_FOUND'",
-		workDir, password, request.ComposeProject)
+	composeDownCmdYml := command.Render("cd %s && [ -f docker-compose.yml ] && sudo docker-compose -p %s -f docker-compose.yml down -v --remove-orphans 2>&1 || echo 'YML_FILE_NOT_FOUND'",
+		workDir, request.ComposeProject)
 	downerCommands = append(downerCommands, composeDownCmdYml)
 
 	// YAML 파일로 시도
-	composeDownCmdYaml := fmt.Sprintf("cd %s && [ -f docker-compose.yaml ] && echo '%s' | sudo -S docker-compose -p %s -f docker-compose.yaml down -v --remove-orphans 2>&1 || echo 'YAML_FILE_NOT_FOUND'",
-		workDir, password, request.ComposeProject)
+	composeDownCmdYaml := command.Render("cd %s && [ -f docker-compose.yaml ] && sudo docker-compose -p %s -f docker-compose.yaml down -v --remove-orphans 2>&1 || echo 'YAML_FILE_NOT_FOUND'",
+		workDir, request.ComposeProject)
 	downerCommands = append(downerCommands, composeDownCmdYaml)
 
 	// 6.2. 두 번째 전략: container-name으로 컨테이너 직접 제거
@@ -1069,10 +1041,10 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 	if len(containerNames) > 0 {
 		// 추출된 컨테이너 이름으로 직접 제거 명령 추가
 		for _, containerName := range containerNames {
-			stopCmd := fmt.Sprintf("echo '%s' | sudo -S docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
-			rmCmd := fmt.Sprintf("echo '%s' | sudo -S docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
+			stopCmd := command.Render("sudo docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
+			rmCmd := command.Render("sudo docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
 			downerCommands = append(downerCommands, stopCmd, rmCmd)
 		}
 	}
@@ -1083,23 +1055,23 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 	if len(containersInPS) > 0 {
 		for _, containerName := range containersInPS {
 			// 앞의 명령에서 이미 제거되었을 수 있으므로 오류 무시
-			stopCmd := fmt.Sprintf("echo '%s' | sudo -S docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
-			rmCmd := fmt.Sprintf("echo '%s' | sudo -S docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
-				password, containerName)
+			stopCmd := command.Render("sudo docker stop %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
+			rmCmd := command.Render("sudo docker rm -f %s 2>&1 || echo 'COMMAND_FAILED'",
+				containerName)
 			downerCommands = append(downerCommands, stopCmd, rmCmd)
 		}
 	}
 
 	// 6.4. 전략 간 중간 확인을 위한 컨테이너 상태 명령
-	checkContainerCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
+	checkContainerCmd := "sudo docker ps -a"
 	downerCommands = append(downerCommands, checkContainerCmd)
 
 	// 7. 임시 폴더 정리
-	downerCommands = append(downerCommands, fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir))
+	downerCommands = append(downerCommands, command.Render("sudo rm -rf %s", workDir))
 
 	// 명령 실행
-	downResults, err := sshUtils.ExecuteCommands(hops, downerCommands, 180000) // 3분 타임아웃
+	downResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, downerCommands, 180000) // 3분 타임아웃
 
 	// 최종 컨테이너 상태와 각 전략의 결과 분석
 	var finalContainerStatus string
@@ -1164,8 +1136,8 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 		// 두 번째 전략 (container_name) 결과 분석
 		if containerNames != nil && len(containerNames) > 0 {
 			for _, name := range containerNames {
-				if strings.Contains(result.Command, fmt.Sprintf("docker stop %s", name)) ||
-					strings.Contains(result.Command, fmt.Sprintf("docker rm -f %s", name)) {
+				if strings.Contains(result.Command, command.Render("docker stop %s", name)) ||
+					strings.Contains(result.Command, command.Render("docker rm -f %s", name)) {
 					if !strings.Contains(result.Output, "COMMAND_FAILED") {
 						removeStrategy.ContainerNameSuccess = true
 						if removeStrategy.RemovedByStrategy == "" {
@@ -1173,17 +1145,17 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 						}
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.SuccessCommands = append(removeStrategy.SuccessCommands, cmd)
 					} else {
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.FailedCommands = append(removeStrategy.FailedCommands, cmd)
 					}
@@ -1194,8 +1166,8 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 		// 세 번째 전략 (PS 매치) 결과 분석
 		if containersInPS != nil && len(containersInPS) > 0 {
 			for _, name := range containersInPS {
-				if strings.Contains(result.Command, fmt.Sprintf("docker stop %s", name)) ||
-					strings.Contains(result.Command, fmt.Sprintf("docker rm -f %s", name)) {
+				if strings.Contains(result.Command, command.Render("docker stop %s", name)) ||
+					strings.Contains(result.Command, command.Render("docker rm -f %s", name)) {
 					if !strings.Contains(result.Output, "COMMAND_FAILED") {
 						removeStrategy.PSMatchSuccess = true
 						if removeStrategy.RemovedByStrategy == "" {
@@ -1203,17 +1175,17 @@ func (h *InfraDockerHandler) DeleteDockerContainer(c *gin.Context) {
 						}
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.SuccessCommands = append(removeStrategy.SuccessCommands, cmd)
 					} else {
 						cmd := ""
 						if strings.Contains(result.Command, "docker stop") {
-							cmd = fmt.Sprintf("docker stop %s", name)
+							cmd = command.Render("docker stop %s", name)
 						} else {
-							cmd = fmt.Sprintf("docker rm -f %s", name)
+							cmd = command.Render("docker rm -f %s", name)
 						}
 						removeStrategy.FailedCommands = append(removeStrategy.FailedCommands, cmd)
 					}
@@ -1403,11 +1375,8 @@ func (h *InfraDockerHandler) GetContainerStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "SSH 연결 정보(hops)가 필요합니다."})
 		return
 	}
-
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(request.Hops) > 0 {
-		password = request.Hops[len(request.Hops)-1].Password
+	if request.ComposeProject != "" && !validateShellValues(c, shellValue{"compose_project", command.FormatContainerID, request.ComposeProject}) {
+		return
 	}
 
 	// SSH 유틸리티 생성
@@ -1417,17 +1386,16 @@ func (h *InfraDockerHandler) GetContainerStatus(c *gin.Context) {
 	var containerCmd string
 	if request.ComposeProject != "" {
 		// 프로젝트 라벨을 사용하여 필터링
-		containerCmd = fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"label=com.docker.compose.project=%s\" --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'",
-			password, request.ComposeProject)
+		containerCmd = command.Render("sudo docker ps -a --filter %s --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'",
+			"label=com.docker.compose.project="+request.ComposeProject)
 	} else {
 		// 모든 컨테이너 조회
-		containerCmd = fmt.Sprintf("echo '%s' | sudo -S docker ps -a --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'",
-			password)
+		containerCmd = "sudo docker ps -a --format '{{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.Ports}}\t{{.Size}}\t{{.CreatedAt}}'"
 	}
-	containerResults, err := sshUtils.ExecuteCommands(request.Hops, []string{containerCmd}, 30000)
+	containerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{containerCmd}, 30000)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "컨테이너 상태 정보를 가져오는 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -1458,8 +1426,8 @@ func (h *InfraDockerHandler) GetContainerStatus(c *gin.Context) {
 	}
 
 	// 이미지 정보 가져오기 (프로젝트 관련 컨테이너의 이미지만 필터링)
-	imageCmd := fmt.Sprintf("echo '%s' | sudo -S docker images --format '{{.Repository}}\t{{.Tag}}\t{{.Size}}\t{{.CreatedSince}}'", password)
-	imageResults, err := sshUtils.ExecuteCommands(request.Hops, []string{imageCmd}, 30000)
+	imageCmd := "sudo docker images --format '{{.Repository}}\t{{.Tag}}\t{{.Size}}\t{{.CreatedSince}}'"
+	imageResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{imageCmd}, 30000)
 
 	var images []map[string]interface{}
 	if err == nil {
@@ -1497,14 +1465,13 @@ func (h *InfraDockerHandler) GetContainerStatus(c *gin.Context) {
 	var networkCmd string
 	if request.ComposeProject != "" {
 		// 프로젝트 이름으로 네트워크 필터링
-		networkCmd = fmt.Sprintf("echo '%s' | sudo -S docker network ls --filter \"name=%s\" --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'",
-			password, request.ComposeProject)
+		networkCmd = command.Render("sudo docker network ls --filter %s --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'",
+			"name="+request.ComposeProject)
 	} else {
 		// 모든 네트워크 조회
-		networkCmd = fmt.Sprintf("echo '%s' | sudo -S docker network ls --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'",
-			password)
+		networkCmd = "sudo docker network ls --format '{{.ID}}\t{{.Name}}\t{{.Driver}}\t{{.Scope}}'"
 	}
-	networkResults, err := sshUtils.ExecuteCommands(request.Hops, []string{networkCmd}, 30000)
+	networkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{networkCmd}, 30000)
 
 	var networks []map[string]interface{}
 	if err == nil {
@@ -1531,14 +1498,13 @@ func (h *InfraDockerHandler) GetContainerStatus(c *gin.Context) {
 	var volumeCmd string
 	if request.ComposeProject != "" {
 		// 프로젝트 이름으로 볼륨 필터링
-		volumeCmd = fmt.Sprintf("echo '%s' | sudo -S docker volume ls --filter \"name=%s\" --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'",
-			password, request.ComposeProject)
+		volumeCmd = command.Render("sudo docker volume ls --filter %s --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'",
+			"name="+request.ComposeProject)
 	} else {
 		// 모든 볼륨 조회
-		volumeCmd = fmt.Sprintf("echo '%s' | sudo -S docker volume ls --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'",
-			password)
+		volumeCmd = "sudo docker volume ls --format '{{.Name}}\t{{.Driver}}\t{{.Size}}'"
 	}
-	volumeResults, err := sshUtils.ExecuteCommands(request.Hops, []string{volumeCmd}, 30000)
+	volumeResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{volumeCmd}, 30000)
 
 	var volumes []map[string]interface{}
 	if err == nil {
@@ -1615,6 +1581,9 @@ func (h *InfraDockerHandler) GetDockerLogs(c *gin.Context) {
 		})
 		return
 	}
+	if !validateShellValues(c, shellValue{"container_id", command.FormatContainerID, request.ContainerID}) {
+		return
+	}
 
 	// SSH 클라이언트 직접 생성하여 로그 추출
 	client, err := h.createSSHClient(request.Hops)
@@ -1627,12 +1596,12 @@ func (h *InfraDockerHandler) GetDockerLogs(c *gin.Context) {
 	}
 	defer client.Close()
 
-	// 마지막 Hop의 패스워드 가져오기
-	password := request.Hops[0].Password
+	// NOPASSWD 호스트에서는 sudo 비밀번호를 stdin으로 보내지 않도록 sudo 방식을 먼저 확인
+	sudoHop := client.ProbeSudo(request.Hops[len(request.Hops)-1])
 
 	// 1. 컨테이너 상태 확인 - 파이프 처리를 위해 명령을 분리
-	statusCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"id=%s\" --format \"{{.ID}}|{{.Names}}|{{.Status}}\"",
-		password, request.ContainerID)
+	statusCmd := command.Render("sudo docker ps -a --filter %s --format %s",
+		"id="+request.ContainerID, "{{.ID}}|{{.Names}}|{{.Status}}")
 
 	session, err := client.NewSession()
 	if err != nil {
@@ -1646,7 +1615,7 @@ func (h *InfraDockerHandler) GetDockerLogs(c *gin.Context) {
 	var statusOutput bytes.Buffer
 	session.Stdout = &statusOutput
 
-	if err := session.Run(statusCmd); err != nil {
+	if err := session.Run(ssh.SudoCommand(session, sudoHop, statusCmd)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("컨테이너 상태 확인 명령 실행 실패: %v", err),
@@ -1683,8 +1652,8 @@ func (h *InfraDockerHandler) GetDockerLogs(c *gin.Context) {
 	tmpFileName := fmt.Sprintf("/tmp/docker_logs_%s_%d.txt", containerID, time.Now().Unix())
 
 	// 로그를 임시 파일로 저장 - 오류 출력 포함하여 전체 정보 보존
-	logCmd := fmt.Sprintf("echo '%s' | sudo -S docker logs --tail %d %s > %s",
-		password, request.Lines, containerID, tmpFileName)
+	logCmd := command.Render("sudo docker logs --tail %d %s > %s",
+		request.Lines, containerID, tmpFileName)
 
 	session, err = client.NewSession()
 	if err != nil {
@@ -1695,7 +1664,7 @@ func (h *InfraDockerHandler) GetDockerLogs(c *gin.Context) {
 		return
 	}
 
-	if err := session.Run(logCmd); err != nil {
+	if err := session.Run(ssh.SudoCommand(session, sudoHop, logCmd)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("로그 명령 실행 실패: %v", err),
@@ -1705,7 +1674,7 @@ func (h *InfraDockerHandler) GetDockerLogs(c *gin.Context) {
 	session.Close()
 
 	// 3. 임시 파일에서 로그 내용 읽기
-	catCmd := fmt.Sprintf("cat %s", tmpFileName)
+	catCmd := command.Render("cat %s", tmpFileName)
 	session, err = client.NewSession()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1728,7 +1697,7 @@ func (h *InfraDockerHandler) GetDockerLogs(c *gin.Context) {
 	session.Close()
 
 	// 4. 임시 파일 삭제
-	cleanupCmd := fmt.Sprintf("rm -f %s", tmpFileName)
+	cleanupCmd := command.Render("rm -f %s", tmpFileName)
 	session, err = client.NewSession()
 	if err != nil {
 		// 파일 삭제 실패해도 계속 진행
@@ -1766,24 +1735,13 @@ func cleanupLogs(logs string) string {
 }
 
 // SSH 클라이언트 생성을 위한 헬퍼 함수
-func (h *InfraDockerHandler) createSSHClient(hops []ssh.HopConfig) (*gossh.Client, error) {
+// 공유 연결 풀에서 hops 체인 연결을 가져오며, Close하면 풀에 반납됩니다
+func (h *InfraDockerHandler) createSSHClient(hops []ssh.HopConfig) (*ssh.Client, error) {
 	if len(hops) == 0 {
 		return nil, fmt.Errorf("SSH 연결 정보가 없습니다")
 	}
 
-	hop := hops[0]
-
-	sshConfig := &gossh.ClientConfig{
-		User: hop.Username,
-		Auth: []gossh.AuthMethod{
-			gossh.Password(hop.Password),
-		},
-		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
-		Timeout:         time.Duration(60) * time.Second,
-	}
-
-	hostPort := fmt.Sprintf("%s:%d", hop.Host, hop.Port)
-	client, err := gossh.Dial("tcp", hostPort, sshConfig)
+	client, err := ssh.NewSSHService().Acquire(hops, time.Duration(60)*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("SSH 연결 실패: %v", err)
 	}
@@ -1808,60 +1766,57 @@ func (h *InfraDockerHandler) UninstallDocker(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := request.Hops[len(request.Hops)-1].Password
-
 	// 도커 제거 명령어 목록
 	commands := []string{
 		// 환경 설정
 		"export DEBIAN_FRONTEND=noninteractive",
-		fmt.Sprintf("echo 'DOCKER_OPTS=\"--dns 8.8.8.8 --dns 8.8.4.4\"' | sudo -S DEBIAN_FRONTEND=noninteractive tee /etc/default/docker > /dev/null 2>&1"),
+		fmt.Sprintf("echo 'DOCKER_OPTS=\"--dns 8.8.8.8 --dns 8.8.4.4\"' | sudo DEBIAN_FRONTEND=noninteractive tee /etc/default/docker > /dev/null 2>&1"),
 
 		// 모든 컨테이너 중지 및 삭제
-		fmt.Sprintf("echo '%s' | sudo -S docker container stop $(echo '%s' | sudo -S docker container ls -aq) > /dev/null 2>&1 || true", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S docker container rm -f $(echo '%s' | sudo -S docker container ls -aq) > /dev/null 2>&1 || true", password, password),
+		"sudo docker container stop $(sudo docker container ls -aq) > /dev/null 2>&1 || true",
+		"sudo docker container rm -f $(sudo docker container ls -aq) > /dev/null 2>&1 || true",
 
 		// 모든 이미지 삭제
-		fmt.Sprintf("echo '%s' | sudo -S docker image rm -f $(echo '%s' | sudo -S docker image ls -aq) > /dev/null 2>&1 || true", password, password),
+		"sudo docker image rm -f $(sudo docker image ls -aq) > /dev/null 2>&1 || true",
 
 		// 모든 볼륨 삭제
-		fmt.Sprintf("echo '%s' | sudo -S docker volume rm $(echo '%s' | sudo -S docker volume ls -q) > /dev/null 2>&1 || true", password, password),
+		"sudo docker volume rm $(sudo docker volume ls -q) > /dev/null 2>&1 || true",
 
 		// 모든 네트워크 삭제 (bridge, host, none 제외)
-		fmt.Sprintf("echo '%s' | sudo -S docker network rm $(echo '%s' | sudo -S docker network ls | awk '/bridge|host|none/ {next} {print $1}') > /dev/null 2>&1 || true", password, password),
+		"sudo docker network rm $(sudo docker network ls | awk '/bridge|host|none/ {next} {print $1}') > /dev/null 2>&1 || true",
 
 		// 도커 서비스 중지 및 제거
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl daemon-reload > /dev/null 2>&1 || true", password),
+		"sudo systemctl stop docker > /dev/null 2>&1 || true",
+		"sudo systemctl disable docker > /dev/null 2>&1 || true",
+		"sudo systemctl daemon-reload > /dev/null 2>&1 || true",
 
 		// 도커 패키지 및 관련 파일 제거
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get remove --purge -y docker-ce docker-ce-cli containerd.io docker-buildx-plugin docker-compose-plugin > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/docker /var/lib/containerd /etc/docker ~/.docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.asc > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/systemd/system/docker.service /etc/systemd/system/docker.socket > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S groupdel docker > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -f $(which docker 2>/dev/null) /usr/local/bin/docker /usr/sbin/docker > /dev/null 2>&1 || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get remove --purge -y docker-ce docker-ce-cli containerd.io docker-buildx-plugin docker-compose-plugin > /dev/null 2>&1 || true",
+		"sudo rm -rf /var/lib/docker /var/lib/containerd /etc/docker ~/.docker > /dev/null 2>&1 || true",
+		"sudo rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.asc > /dev/null 2>&1 || true",
+		"sudo rm -rf /etc/systemd/system/docker.service /etc/systemd/system/docker.socket > /dev/null 2>&1 || true",
+		"sudo groupdel docker > /dev/null 2>&1 || true",
+		"sudo rm -f $(which docker 2>/dev/null) /usr/local/bin/docker /usr/sbin/docker > /dev/null 2>&1 || true",
 
 		// snap으로 설치된 도커 제거
-		fmt.Sprintf("echo '%s' | sudo -S snap remove docker > /dev/null 2>&1 || true", password),
+		"sudo snap remove docker > /dev/null 2>&1 || true",
 
 		// dpkg로 설치된 도커 관련 패키지 제거
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive dpkg --purge $(dpkg -l | awk '/docker/{print $2}') > /dev/null 2>&1 || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive dpkg --purge $(dpkg -l | awk '/docker/{print $2}') > /dev/null 2>&1 || true",
 
 		// 시스템 정리
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoremove -y > /dev/null 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoclean -y > /dev/null 2>&1 || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoremove -y > /dev/null 2>&1 || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoclean -y > /dev/null 2>&1 || true",
 
 		// 도커 제거 확인
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>&1 || echo 'DOCKER_REMOVED'", password),
+		"sudo docker --version 2>&1 || echo 'DOCKER_REMOVED'",
 	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(request.Hops, commands, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, commands, 300000) // 5분 타임아웃
 
 	// 도커가 성공적으로 제거되었는지 확인
 	dockerRemoved := false
@@ -1923,34 +1878,35 @@ func (h *InfraDockerHandler) DeleteOneDockerContainer(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := request.Hops[len(request.Hops)-1].Password
+	if !validateShellValues(c, shellValue{"container_id", command.FormatContainerID, request.ContainerID}) {
+		return
+	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 먼저 모든 컨테이너 목록 확인 (디버깅 용도)
-	listAllCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
-	listResults, _ := sshUtils.ExecuteCommands(request.Hops, []string{listAllCmd}, 30000)
+	listAllCmd := "sudo docker ps -a"
+	listResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{listAllCmd}, 30000)
 	allContainers := ""
 	if len(listResults) > 0 {
 		allContainers = listResults[0].Output
 	}
 
 	// 1. 컨테이너 존재 여부 확인 (ID로 검색)
-	checkContainerByIDCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"id=%s\" --format \"{{.ID}}|{{.Names}}|{{.Status}}\"",
-		password, request.ContainerID)
+	checkContainerByIDCmd := command.Render("sudo docker ps -a --filter %s --format %s",
+		"id="+request.ContainerID, "{{.ID}}|{{.Names}}|{{.Status}}")
 
 	// 2. 컨테이너 존재 여부 확인 (이름으로 검색)
-	checkContainerByNameCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"name=%s\" --format \"{{.ID}}|{{.Names}}|{{.Status}}\"",
-		password, request.ContainerID)
+	checkContainerByNameCmd := command.Render("sudo docker ps -a --filter %s --format %s",
+		"name="+request.ContainerID, "{{.ID}}|{{.Names}}|{{.Status}}")
 
 	// 두 명령 실행
 	checkCommands := []string{checkContainerByIDCmd, checkContainerByNameCmd}
-	checkResults, err := sshUtils.ExecuteCommands(request.Hops, checkCommands, 30000)
+	checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, checkCommands, 30000)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success":              false,
 			"error":                "컨테이너 확인 중 오류가 발생했습니다: " + err.Error(),
 			"debug_all_containers": allContainers,
@@ -2018,27 +1974,27 @@ func (h *InfraDockerHandler) DeleteOneDockerContainer(c *gin.Context) {
 	var commands []string
 
 	// 중지 명령 (이미 중지된 경우에도 오류 무시)
-	stopCmd := fmt.Sprintf("echo '%s' | sudo -S docker stop %s 2>&1 || echo 'CONTAINER_ALREADY_STOPPED'",
-		password, containerID)
+	stopCmd := command.Render("sudo docker stop %s 2>&1 || echo 'CONTAINER_ALREADY_STOPPED'",
+		containerID)
 	commands = append(commands, stopCmd)
 
 	// 삭제 명령 (항상 강제 삭제)
-	rmCmd := fmt.Sprintf("echo '%s' | sudo -S docker rm -f %s 2>&1",
-		password, containerID)
+	rmCmd := command.Render("sudo docker rm -f %s 2>&1",
+		containerID)
 	commands = append(commands, rmCmd)
 
 	// 삭제 확인 명령 - 단순한 방식으로 변경: 해당 ID의 컨테이너가 있는지 직접 확인
 	// -q 옵션은 컨테이너 ID만 반환, wc -l로 개수 카운트
-	checkRemovedCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter \"id=%s\" -q | wc -l",
-		password, containerID)
+	checkRemovedCmd := command.Render("sudo docker ps -a --filter %s -q | wc -l",
+		"id="+containerID)
 	commands = append(commands, checkRemovedCmd)
 
 	// 삭제 후 컨테이너 목록 확인 (디버깅 용도)
-	listAfterCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a", password)
+	listAfterCmd := "sudo docker ps -a"
 	commands = append(commands, listAfterCmd)
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(request.Hops, commands, 60000) // 1분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, commands, 60000) // 1분 타임아웃
 
 	// 결과 분석
 	var stopOutput, rmOutput, checkOutput, listAfterOutput string
@@ -2138,21 +2094,19 @@ func (h *InfraDockerHandler) ControlDockerContainer(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(request.Hops) > 0 {
-		password = request.Hops[len(request.Hops)-1].Password
+	if !validateShellValues(c, shellValue{"container_id", command.FormatContainerID, request.ContainerID}) {
+		return
 	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 컨테이너 존재 여부 확인
-	checkCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --filter id=%s --format '{{.ID}}'", password, request.ContainerID)
-	checkResults, err := sshUtils.ExecuteCommands(request.Hops, []string{checkCmd}, 30000)
+	checkCmd := command.Render("sudo docker ps -a --filter %s --format '{{.ID}}'", "id="+request.ContainerID)
+	checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{checkCmd}, 30000)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "컨테이너 확인 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2178,12 +2132,12 @@ func (h *InfraDockerHandler) ControlDockerContainer(c *gin.Context) {
 	// 액션 실행
 	var actionCmd string
 	if request.ActionType == "stop" {
-		actionCmd = fmt.Sprintf("echo '%s' | sudo -S docker stop %s", password, request.ContainerID)
+		actionCmd = command.Render("sudo docker stop %s", request.ContainerID)
 	} else {
-		actionCmd = fmt.Sprintf("echo '%s' | sudo -S docker restart %s", password, request.ContainerID)
+		actionCmd = command.Render("sudo docker restart %s", request.ContainerID)
 	}
 
-	results, err := sshUtils.ExecuteCommands(request.Hops, []string{actionCmd}, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{actionCmd}, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var formattedResults []map[string]interface{}
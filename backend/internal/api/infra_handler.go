@@ -12,14 +12,17 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
 	"github.com/k8scontrol/backend/internal/db"
 	"github.com/k8scontrol/backend/internal/utils"
+	"github.com/k8scontrol/backend/pkg/redact"
 	"github.com/k8scontrol/backend/pkg/ssh"
 )
 
 // InfraHandler 인프라 관련 API 핸들러
 type InfraHandler struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Repos *db.Repositories
 }
 type Hop struct {
 	Host     string `json:"host"`
@@ -29,8 +32,8 @@ type Hop struct {
 }
 
 // NewInfraHandler 새 InfraHandler 생성
-func NewInfraHandler(db *sql.DB) *InfraHandler {
-	return &InfraHandler{DB: db}
+func NewInfraHandler(database *sql.DB, repos *db.Repositories) *InfraHandler {
+	return &InfraHandler{DB: database, Repos: repos}
 }
 
 // 모든 명령어가 성공했는지 확인하는 함수
@@ -57,7 +60,7 @@ func (h *InfraHandler) InstallLoadBalancer(c *gin.Context) {
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, requestBody.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(requestBody.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -68,7 +71,7 @@ func (h *InfraHandler) InstallLoadBalancer(c *gin.Context) {
 	if len(requestBody.Hops) > 0 {
 		hops = requestBody.Hops
 	} else {
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "hops 파싱 중 오류가 발생했습니다."})
 			return
 		}
@@ -81,12 +84,6 @@ func (h *InfraHandler) InstallLoadBalancer(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	if ha == "Y" {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "이 서버에는 이미 HAProxy가 설치되어 있습니다."})
 		return
@@ -126,39 +123,39 @@ backend kubernetes-backend
 
 	sshUtils := utils.NewSSHUtils()
 	finalCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update > /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y haproxy >> /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S touch /etc/haproxy/haproxy.cfg >> /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak >> /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S bash -c 'echo \"%s\" > /etc/haproxy/haproxy.cfg' >> /tmp/haproxy_install.log 2>&1", password, haproxyConfig),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl restart haproxy || echo '%s' | sudo -S service haproxy restart >> /tmp/haproxy_install.log 2>&1", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable haproxy || echo '%s' | sudo -S service haproxy enable >> /tmp/haproxy_install.log 2>&1", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl status haproxy || echo '%s' | sudo -S service haproxy status >> /tmp/haproxy_install.log 2>&1", password, password),
+		"sudo apt-get update > /tmp/haproxy_install.log 2>&1",
+		"sudo apt-get install -y haproxy >> /tmp/haproxy_install.log 2>&1",
+		"sudo touch /etc/haproxy/haproxy.cfg >> /tmp/haproxy_install.log 2>&1",
+		"sudo cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak >> /tmp/haproxy_install.log 2>&1",
+		fmt.Sprintf("sudo bash -c 'echo \"%s\" > /etc/haproxy/haproxy.cfg' >> /tmp/haproxy_install.log 2>&1", haproxyConfig),
+		"sudo systemctl restart haproxy || sudo service haproxy restart >> /tmp/haproxy_install.log 2>&1",
+		"sudo systemctl enable haproxy || sudo service haproxy enable >> /tmp/haproxy_install.log 2>&1",
+		"sudo systemctl status haproxy || sudo service haproxy status >> /tmp/haproxy_install.log 2>&1",
 		"echo '로드 밸런서 설치 완료' >> /tmp/haproxy_install.log",
 		"local_ip=$(hostname -I | awk '{print $1}') && echo \"로드 밸런서 IP: $local_ip\" >> /tmp/haproxy_install.log",
-		fmt.Sprintf("echo '%s' | sudo -S bash -c 'local_ip=$(hostname -I | cut -d\" \" -f1); echo \"LOAD_BALANCER_IP=$local_ip\" > /tmp/load_balancer_info'", password),
+		"sudo bash -c 'local_ip=$(hostname -I | cut -d\" \" -f1); echo \"LOAD_BALANCER_IP=$local_ip\" > /tmp/load_balancer_info'",
 	}
 
-	results, err := sshUtils.ExecuteCommands(hops, finalCommands, 60000)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, finalCommands, 60000)
 	fmt.Print(results)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다."})
 		return
 	}
 
 	// 로그 파일 확인 명령어 - 파일 권한 문제 해결을 위해 sudo 사용
 	checkLogCommands := []string{
 		// 로그 파일이 존재하는지 확인 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S ls -la /tmp/haproxy_install.log 2>/dev/null && echo 'LOG_EXISTS=true' || echo 'LOG_EXISTS=false'", password),
+		"sudo ls -la /tmp/haproxy_install.log 2>/dev/null && echo 'LOG_EXISTS=true' || echo 'LOG_EXISTS=false'",
 		// 로그 파일에 '로드 밸런서 설치 완료' 문자열이 있는지 확인 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S grep -q '로드 밸런서 설치 완료' /tmp/haproxy_install.log 2>/dev/null && echo 'INSTALL_COMPLETE=true' || echo 'INSTALL_COMPLETE=false'", password),
+		"sudo grep -q '로드 밸런서 설치 완료' /tmp/haproxy_install.log 2>/dev/null && echo 'INSTALL_COMPLETE=true' || echo 'INSTALL_COMPLETE=false'",
 		// 로그 파일에 오류 메시지가 있는지 확인 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S grep -i 'error\\|failed\\|실패' /tmp/haproxy_install.log 2>/dev/null && echo 'HAS_ERRORS=true' || echo 'HAS_ERRORS=false'", password),
+		"sudo grep -i 'error\\|failed\\|실패' /tmp/haproxy_install.log 2>/dev/null && echo 'HAS_ERRORS=true' || echo 'HAS_ERRORS=false'",
 		// 로그 파일 내용 가져오기 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/haproxy_install.log 2>/dev/null || echo '로그 파일을 읽을 수 없습니다.'", password),
+		"sudo cat /tmp/haproxy_install.log 2>/dev/null || echo '로그 파일을 읽을 수 없습니다.'",
 	}
 
-	logResults, logErr := sshUtils.ExecuteCommands(hops, checkLogCommands, 30000)
+	logResults, logErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, checkLogCommands, 30000)
 
 	// 로그 확인 결과 초기화
 	logExists := false
@@ -184,7 +181,7 @@ backend kubernetes-backend
 
 	if installSuccess {
 		// HA 상태를 Y로 업데이트
-		err := db.UpdateServerHAStatus(h.DB, requestBody.ID)
+		err := h.Repos.Servers.SetHA(requestBody.ID)
 		if err != nil {
 			log.Printf("HA 상태 업데이트 중 오류 발생: %v", err)
 			c.JSON(http.StatusOK, gin.H{
@@ -227,6 +224,12 @@ backend kubernetes-backend
 
 // InstallMaster 쿠버네티스 마스터 노드 설치
 func (h *InfraHandler) InstallFirstMaster(c *gin.Context) {
+	// ?async=true이면 작업으로 실행하고 작업 ID를 즉시 반환
+	if asyncRequested(c, nil) {
+		startHandlerJob(c, "installFirstMaster", "", h.InstallFirstMaster)
+		return
+	}
+
 	var requestBody struct {
 		Password   string          `json:"password"`
 		ID         int             `json:"id"`
@@ -241,8 +244,12 @@ func (h *InfraHandler) InstallFirstMaster(c *gin.Context) {
 		return
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	setSudoPassword(requestBody.Hops, requestBody.Password)
+	setSudoPassword(requestBody.LBHops, requestBody.LBPassword)
+
 	// 쿠버네티스 마스터 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, requestBody.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(requestBody.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -255,9 +262,9 @@ func (h *InfraHandler) InstallFirstMaster(c *gin.Context) {
 	// 마스터 노드 IP 주소 가져오기
 	sshUtils := utils.NewSSHUtils()
 	ipCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	ipResults, err := sshUtils.ExecuteCommands(requestBody.Hops, ipCmd, 30000)
+	ipResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, ipCmd, 30000)
 	if err != nil || len(ipResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 	masterIP := strings.TrimSpace(ipResults[0].Output)
@@ -275,9 +282,9 @@ func (h *InfraHandler) InstallFirstMaster(c *gin.Context) {
 
 	// 로드 밸런서 IP 주소 가져오기
 	lbIpCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	lbIpResults, err := sshUtils.ExecuteCommands(requestBody.LBHops, lbIpCmd, 30000)
+	lbIpResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.LBHops, lbIpCmd, 30000)
 	if err != nil || len(lbIpResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 	lbIP = strings.TrimSpace(lbIpResults[0].Output)
@@ -334,15 +341,15 @@ EOF`, serverName, masterIP, port, serverName, masterIP, port),
 		"chmod +x /tmp/update_haproxy.sh",
 
 		// 3. sudo로 스크립트 실행
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/update_haproxy.sh", requestBody.LBPassword),
+		"sudo bash /tmp/update_haproxy.sh",
 
 		// 4. 임시 스크립트 파일 삭제
 		"rm -f /tmp/update_haproxy.sh",
 	}
 
-	lbResults, err := sshUtils.ExecuteCommands(requestBody.LBHops, haproxyUpdateCmd, 60000) // 타임아웃 60초로 증가
+	lbResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.LBHops, haproxyUpdateCmd, 60000) // 타임아웃 60초로 증가
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
@@ -620,7 +627,7 @@ echo "설치 완료"
 		// 2. 실행 권한 부여
 		"chmod +x /tmp/install_k8s.sh",
 		// 3. 로그 파일에 출력 저장하면서 스크립트 실행 (sudo 권한으로)
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/install_k8s.sh > /tmp/k8s_install.log 2>&1 & echo $! > /tmp/k8s_install.pid", requestBody.Password),
+		"sudo bash /tmp/install_k8s.sh > /tmp/k8s_install.log 2>&1 & echo $! > /tmp/k8s_install.pid",
 		// 4. 설치 시작 확인
 		"echo '쿠버네티스 설치가 백그라운드에서 시작되었습니다. 로그 파일: /tmp/k8s_install.log, PID: '$(cat /tmp/k8s_install.pid)",
 		// 5. 백그라운드에서 로그 모니터링 및 join 명령어 추출 스크립트 실행
@@ -742,9 +749,9 @@ echo "설치 완료"
 		" > /tmp/extract_join_cmd.log 2>&1 &`),
 	}
 
-	results, err := sshUtils.ExecuteCommands(requestBody.Hops, finalCommands, 30000) // 30초 (스크립트 시작만 확인)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, finalCommands, 30000) // 30초 (스크립트 시작만 확인)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
@@ -846,8 +853,8 @@ echo "설치 완료"
 				log.Printf("유효한 join 명령어를 찾았습니다. DB 업데이트를 시도합니다.")
 
 				// DB에 join 명령어와 인증서 키 저장
-				log.Printf("저장할 join_command: %s, certificate_key: %s", joinCommand, certificateKey)
-				err := db.UpdateServerJoinCommand(h.DB, requestBody.ID, joinCommand, certificateKey)
+				log.Printf("저장할 join_command: %s, certificate_key: %s", redact.String(joinCommand), redact.Mask)
+				err := h.Repos.Servers.UpdateJoinCommand(requestBody.ID, joinCommand, certificateKey)
 				if err != nil {
 					log.Printf("join 명령어 DB 업데이트 중 오류 발생: %v", err)
 					return
@@ -888,6 +895,12 @@ echo "설치 완료"
 }
 
 func (h *InfraHandler) JoinMaster(c *gin.Context) {
+	// ?async=true이면 작업으로 실행하고 작업 ID를 즉시 반환
+	if asyncRequested(c, nil) {
+		startHandlerJob(c, "joinMaster", "", h.JoinMaster)
+		return
+	}
+
 	var requestBody struct {
 		Password   string          `json:"password"`
 		ID         int             `json:"id"`          // 현재 마스터 노드 ID
@@ -903,8 +916,12 @@ func (h *InfraHandler) JoinMaster(c *gin.Context) {
 		return
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	setSudoPassword(requestBody.Hops, requestBody.Password)
+	setSudoPassword(requestBody.LBHops, requestBody.LBPassword)
+
 	// 쿠버네티스 마스터 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, requestBody.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(requestBody.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -917,9 +934,9 @@ func (h *InfraHandler) JoinMaster(c *gin.Context) {
 	// 마스터 노드 IP 주소 가져오기
 	sshUtils := utils.NewSSHUtils()
 	ipCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	ipResults, err := sshUtils.ExecuteCommands(requestBody.Hops, ipCmd, 30000)
+	ipResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, ipCmd, 30000)
 	if err != nil || len(ipResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 	masterIP := strings.TrimSpace(ipResults[0].Output)
@@ -937,9 +954,9 @@ func (h *InfraHandler) JoinMaster(c *gin.Context) {
 
 	// 로드 밸런서 IP 주소 가져오기
 	lbIpCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	lbIpResults, err := sshUtils.ExecuteCommands(requestBody.LBHops, lbIpCmd, 30000)
+	lbIpResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.LBHops, lbIpCmd, 30000)
 	if err != nil || len(lbIpResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 	lbIP = strings.TrimSpace(lbIpResults[0].Output)
@@ -996,15 +1013,15 @@ EOF`, serverName, masterIP, port, serverName, masterIP, port),
 		"chmod +x /tmp/update_haproxy.sh",
 
 		// 3. sudo로 스크립트 실행
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/update_haproxy.sh", requestBody.LBPassword),
+		"sudo bash /tmp/update_haproxy.sh",
 
 		// 4. 임시 스크립트 파일 삭제
 		"rm -f /tmp/update_haproxy.sh",
 	}
 
-	lbResults, err := sshUtils.ExecuteCommands(requestBody.LBHops, haproxyUpdateCmd, 60000) // 타임아웃 60초로 증가
+	lbResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.LBHops, haproxyUpdateCmd, 60000) // 타임아웃 60초로 증가
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
@@ -1016,7 +1033,7 @@ EOF`, serverName, masterIP, port, serverName, masterIP, port),
 	log.Println("로드 밸런서 HAProxy 설정이 성공적으로 업데이트되었습니다.")
 
 	// 메인 마스터 노드의 join_command와 certificate_key 가져오기
-	mainMasterInfo, err := db.GetServerInfo(h.DB, requestBody.MainID)
+	mainMasterInfo, err := h.Repos.Servers.GetInfo(requestBody.MainID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "메인 마스터 노드 정보를 가져오는 중 오류가 발생했습니다: " + err.Error()})
 		return
@@ -1312,14 +1329,14 @@ echo "설치 완료"
 		// 2. 실행 권한 부여
 		"chmod +x /tmp/join_k8s.sh",
 		// 3. 로그 파일에 출력 저장하면서 스크립트 실행 (sudo 권한으로)
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid", requestBody.Password),
+		"sudo bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid",
 		// 4. 설치 시작 확인
 		"echo '쿠버네티스 마스터 노드 조인이 백그라운드에서 시작되었습니다. 로그 파일: /tmp/k8s_join.log, PID: '$(cat /tmp/k8s_join.pid)",
 	}
 
-	results, err := sshUtils.ExecuteCommands(requestBody.Hops, finalCommands, 30000) // 30초 (스크립트 시작만 확인)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, finalCommands, 30000) // 30초 (스크립트 시작만 확인)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
@@ -1386,8 +1403,11 @@ func (h *InfraHandler) JoinWorker(c *gin.Context) {
 		return
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	setSudoPassword(requestBody.Hops, requestBody.Password)
+
 	// 쿠버네티스 워커 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, requestBody.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(requestBody.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -1398,7 +1418,7 @@ func (h *InfraHandler) JoinWorker(c *gin.Context) {
 	log.Printf("DB에서 가져온 서버 이름: %s를 사용합니다.", serverName)
 
 	// 메인 마스터 노드의 join_command 가져오기
-	mainMasterInfo, err := db.GetServerInfo(h.DB, requestBody.MainID)
+	mainMasterInfo, err := h.Repos.Servers.GetInfo(requestBody.MainID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "메인 마스터 노드 정보를 가져오는 중 오류가 발생했습니다: " + err.Error()})
 		return
@@ -1625,15 +1645,15 @@ echo "워커 노드 조인 완료"
 		// 2. 실행 권한 부여
 		"chmod +x /tmp/join_k8s.sh",
 		// 3. 로그 파일에 출력 저장하면서 스크립트 실행 (sudo 권한으로)
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid", requestBody.Password),
+		"sudo bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid",
 		// 4. 설치 시작 확인
 		"echo '쿠버네티스 워커 노드 조인이 백그라운드에서 시작되었습니다. 로그 파일: /tmp/k8s_join.log, PID: '$(cat /tmp/k8s_join.pid)",
 	}
 
 	sshUtils := utils.NewSSHUtils()
-	results, err := sshUtils.ExecuteCommands(requestBody.Hops, finalCommands, 30000) // 30초 (스크립트 시작만 확인)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, finalCommands, 30000) // 30초 (스크립트 시작만 확인)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
@@ -1702,11 +1722,15 @@ func (h *InfraHandler) DeleteWorker(c *gin.Context) {
 		return
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	setSudoPassword(requestBody.Hops, requestBody.Password)
+	setSudoPassword(requestBody.MainHops, requestBody.MainPassword)
+
 	// 디버깅을 위한 로그 추가
 	log.Printf("요청 받은 워커 노드 ID: %d, 메인 노드 ID: %d", requestBody.ID, requestBody.MainID)
 
 	// 워커 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, requestBody.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(requestBody.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -1723,66 +1747,66 @@ func (h *InfraHandler) DeleteWorker(c *gin.Context) {
 	// 마스터 노드에서 실행할 명령어 (cordon, drain, delete)
 	masterCommands := []string{
 		// 1. 노드 cordon (새로운 파드 스케줄링 방지)
-		fmt.Sprintf("echo '%s' | sudo -S kubectl cordon %s", requestBody.MainPassword, serverName),
+		command.Render("sudo kubectl cordon %s", serverName),
 
 		// 2. 노드 drain (기존 파드 안전하게 제거)
-		fmt.Sprintf("echo '%s' | sudo -S kubectl drain %s --ignore-daemonsets --delete-emptydir-data --force", requestBody.MainPassword, serverName),
+		command.Render("sudo kubectl drain %s --ignore-daemonsets --delete-emptydir-data --force", serverName),
 
 		// 3. 노드 삭제
-		fmt.Sprintf("echo '%s' | sudo -S kubectl delete node %s", requestBody.MainPassword, serverName),
+		command.Render("sudo kubectl delete node %s", serverName),
 	}
 
 	// 워커 노드에서 실행할 명령어 (쿠버네티스 관련 패키지 제거)
 	workerCommands := []string{
 		// 1. kubeadm reset 실행
-		fmt.Sprintf("echo '%s' | sudo -S kubeadm reset -f", requestBody.Password),
+		"sudo kubeadm reset -f",
 
 		// 2. CNI 설정 파일 제거
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/cni/net.d/*", requestBody.Password),
+		"sudo rm -rf /etc/cni/net.d/*",
 
 		// 3. iptables 규칙 정리
-		fmt.Sprintf("echo '%s' | sudo -S iptables -F", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t nat -F", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t mangle -F", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -X", requestBody.Password),
+		"sudo iptables -F",
+		"sudo iptables -t nat -F",
+		"sudo iptables -t mangle -F",
+		"sudo iptables -X",
 
 		// 4. IPVS 테이블 정리 (클러스터가 IPVS를 사용한 경우)
-		fmt.Sprintf("echo '%s' | sudo -S ipvsadm --clear 2>/dev/null || true", requestBody.Password),
+		"sudo ipvsadm --clear 2>/dev/null || true",
 
 		// 5. kubeconfig 파일 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /root/.kube", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/admin.conf", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/kubelet.conf", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf ~/.kube", requestBody.Password),
+		"sudo rm -rf /root/.kube",
+		"sudo rm -rf /etc/kubernetes/admin.conf",
+		"sudo rm -rf /etc/kubernetes/kubelet.conf",
+		"sudo rm -rf ~/.kube",
 
 		// 6. 서비스 중지
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop kubelet", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop containerd", requestBody.Password),
+		"sudo systemctl stop kubelet",
+		"sudo systemctl stop containerd",
 
 		// 7. 프로세스 강제 종료
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-apiserver 2>/dev/null || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-scheduler 2>/dev/null || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-controller-manager 2>/dev/null || true", requestBody.Password),
+		"sudo pkill -9 kube-apiserver 2>/dev/null || true",
+		"sudo pkill -9 kube-scheduler 2>/dev/null || true",
+		"sudo pkill -9 kube-controller-manager 2>/dev/null || true",
 
 		// 8. 마운트 해제 및 파드 디렉토리 정리
-		fmt.Sprintf("echo '%s' | sudo -S umount -l /var/lib/kubelet/pods/* 2>/dev/null || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet/pods/*", requestBody.Password),
+		"sudo umount -l /var/lib/kubelet/pods/* 2>/dev/null || true",
+		"sudo rm -rf /var/lib/kubelet/pods/*",
 
 		// 9. 쿠버네티스 관련 디렉토리 강제 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/etcd", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes", requestBody.Password),
+		"sudo rm -rf /var/lib/kubelet",
+		"sudo rm -rf /var/lib/etcd",
+		"sudo rm -rf /etc/kubernetes",
 
 		// 10. systemd 서비스 비활성화
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable kubelet", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable containerd", requestBody.Password),
+		"sudo systemctl disable kubelet",
+		"sudo systemctl disable containerd",
 
 		// 11. 패키지 제거 (대화형 프롬프트 비활성화)
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get clean || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable containerd || true", requestBody.Password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get clean || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true",
+		"sudo systemctl disable containerd || true",
 	}
 
 	// SSH 유틸리티 초기화
@@ -1790,15 +1814,15 @@ func (h *InfraHandler) DeleteWorker(c *gin.Context) {
 
 	// 1. 마스터 노드에서 cordon, drain, delete 실행
 	log.Printf("마스터 노드에서 노드 %s의 cordon, drain, delete 작업 실행 중...", serverName)
-	masterResults, err := sshUtils.ExecuteCommands(masterHops, masterCommands, 300000) // 5분 타임아웃
+	masterResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), masterHops, masterCommands, 300000) // 5분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "마스터 노드에서 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "마스터 노드에서 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
 	// 2. 워커 노드에서 쿠버네티스 관련 패키지 제거
 	log.Printf("워커 노드 %s에서 쿠버네티스 관련 패키지 제거 중...", serverName)
-	workerResults, err := sshUtils.ExecuteCommands(requestBody.Hops, workerCommands, 300000) // 5분 타임아웃
+	workerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, workerCommands, 300000) // 5분 타임아웃
 
 	// 워커 노드 접속 실패는 무시 (이미 종료되었을 수 있음)
 	if err != nil {
@@ -1806,7 +1830,7 @@ func (h *InfraHandler) DeleteWorker(c *gin.Context) {
 	}
 
 	// 3. DB에서 워커 노드 삭제
-	err = db.DeleteWorker(h.DB, requestBody.ID)
+	err = h.Repos.Servers.Delete(requestBody.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "DB에서 워커 노드 삭제 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
@@ -1853,12 +1877,17 @@ func (h *InfraHandler) DeleteMaster(c *gin.Context) {
 		return
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	setSudoPassword(requestBody.Hops, requestBody.Password)
+	setSudoPassword(requestBody.MainMasterHops, requestBody.MainMasterPassword)
+	setSudoPassword(requestBody.LBHops, requestBody.LBPassword)
+
 	// 디버깅을 위한 로그 추가
 	log.Printf("요청 받은 마스터 노드 ID: %d", requestBody.ID)
-	log.Printf("SSH 연결 정보: %+v", requestBody.Hops)
+	log.Printf("SSH 연결 정보: %s", (&command.CommandTarget{Hops: requestBody.Hops}).GetDescription())
 
 	// 삭제할 마스터 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, requestBody.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(requestBody.ID)
 	if err != nil {
 		log.Printf("서버 정보 가져오기 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
@@ -1868,32 +1897,24 @@ func (h *InfraHandler) DeleteMaster(c *gin.Context) {
 	// 서버 이름 변수 설정 (DB에서 가져온 값 사용)
 	serverName := serverInfo.ServerName
 	log.Printf("DB에서 가져온 서버 이름: %s를 삭제합니다.", serverName)
-	log.Printf("서버 정보: %+v", serverInfo)
+	log.Printf("서버 정보: 이름=%s, HA=%s", serverInfo.ServerName, serverInfo.HA)
 
 	// 메인 마스터 노드 여부 확인
 	isMainMaster := serverInfo.JoinCommand != "" && serverInfo.CertificateKey != ""
 	log.Printf("마스터 노드 %s는 메인 마스터 노드입니까? %v", serverName, isMainMaster)
 
 	// 서버의 infra_id 가져오기
-	var infraID int
-	err = h.DB.QueryRow("SELECT infra_id FROM servers WHERE id = ?", requestBody.ID).Scan(&infraID)
+	server, err := h.Repos.Servers.GetByID(requestBody.ID)
 	if err != nil {
 		log.Printf("infra_id 가져오기 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "서버의 infra_id를 가져오는 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
+	infraID := server.InfraID
 	log.Printf("서버의 infra_id: %d", infraID)
 
 	// 같은 infra_id에 다른 마스터 노드가 있는지 확인
-	var otherMasterCount int
-	query := `
-		SELECT COUNT(*) 
-		FROM servers 
-		WHERE infra_id = ? 
-		AND id != ? 
-		AND type LIKE '%master%'
-	`
-	err = h.DB.QueryRow(query, infraID, requestBody.ID).Scan(&otherMasterCount)
+	otherMasterCount, err := h.Repos.Servers.CountMasters(infraID, requestBody.ID)
 	if err != nil {
 		log.Printf("다른 마스터 노드 확인 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "다른 마스터 노드 확인 중 오류가 발생했습니다.", "errorDetails": err.Error()})
@@ -1954,13 +1975,13 @@ EOF`, serverName, serverName),
 			"chmod +x /tmp/remove_server.sh",
 
 			// sudo로 스크립트 실행
-			fmt.Sprintf("echo '%s' | sudo -S bash /tmp/remove_server.sh", requestBody.LBPassword),
+			"sudo bash /tmp/remove_server.sh",
 
 			// 임시 스크립트 파일 삭제
 			"rm -f /tmp/remove_server.sh",
 		}
 
-		_, err = sshUtils.ExecuteCommands(requestBody.LBHops, haproxyUpdateCmd, 60000)
+		_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.LBHops, haproxyUpdateCmd, 60000)
 		if err != nil {
 			log.Printf("로드 밸런서 HAProxy 설정 업데이트 실패: %v", err)
 			// 치명적이지 않으므로 계속 진행
@@ -1971,12 +1992,12 @@ EOF`, serverName, serverName),
 
 	// 로그 파일 설정 명령
 	logSetupCommands := []string{
-		fmt.Sprintf("echo '===== 마스터 노드 %s 삭제 작업 시작 =====' > /tmp/master_delete.log", serverName),
-		fmt.Sprintf("echo '시작 시간: %s' >> /tmp/master_delete.log", time.Now().Format(time.RFC3339)),
+		command.Render("echo %s > /tmp/master_delete.log", "===== 마스터 노드 "+serverName+" 삭제 작업 시작 ====="),
+		command.Render("echo %s >> /tmp/master_delete.log", "시작 시간: "+time.Now().Format(time.RFC3339)),
 	}
 
 	// 로그 파일 설정 실행
-	_, err = sshUtils.ExecuteCommands(requestBody.Hops, logSetupCommands, 10000)
+	_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, logSetupCommands, 10000)
 	if err != nil {
 		log.Printf("로그 파일 설정 실패: %v", err)
 		// 치명적이지 않으므로 계속 진행
@@ -1993,16 +2014,16 @@ EOF`, serverName, serverName),
 		// 1단계: 메인 마스터에서 노드 제거
 		mainNodeCommands := []string{
 			// 1.1. 노드 드레인 (파드 제거)
-			fmt.Sprintf("echo '%s' | sudo -S kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets", requestBody.MainMasterPassword, serverName),
+			command.Render("sudo kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets", serverName),
 
 			// 1.2. 노드 삭제
-			fmt.Sprintf("echo '%s' | sudo -S kubectl delete node %s", requestBody.MainMasterPassword, serverName),
+			command.Render("sudo kubectl delete node %s", serverName),
 		}
 
-		mainNodeResults, err := sshUtils.ExecuteCommands(requestBody.MainMasterHops, mainNodeCommands, 60000)
+		mainNodeResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.MainMasterHops, mainNodeCommands, 60000)
 		if err != nil {
 			log.Printf("메인 마스터에서 노드 제거 실패: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "메인 마스터에서 노드 제거 실패", "errorDetails": err.Error()})
+			c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "메인 마스터에서 노드 제거 실패", "errorDetails": err.Error()})
 			return
 		}
 
@@ -2012,28 +2033,28 @@ EOF`, serverName, serverName),
 
 		// 2단계: etcd 멤버 리스트 확인 및 제거
 		etcdListCmd := []string{
-			fmt.Sprintf("echo '%s' | sudo -S ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list", requestBody.MainMasterPassword),
+			"sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list",
 		}
 
-		etcdListResult, err := sshUtils.ExecuteCommands(requestBody.MainMasterHops, etcdListCmd, 30000)
+		etcdListResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.MainMasterHops, etcdListCmd, 30000)
 		if err == nil {
 			mainMasterCleanupOutput += etcdListResult[0].Output + "\n"
 
 			// etcd 멤버 ID 추출
 			etcdFindCmd := []string{
-				fmt.Sprintf("echo '%s' | sudo -S bash -c \"ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list | grep %s | cut -d',' -f1\"", requestBody.MainMasterPassword, serverName),
+				command.Render("sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list | grep -F -- %s | cut -d',' -f1", serverName),
 			}
 
-			etcdFindResult, err := sshUtils.ExecuteCommands(requestBody.MainMasterHops, etcdFindCmd, 30000)
+			etcdFindResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.MainMasterHops, etcdFindCmd, 30000)
 			if err == nil {
 				etcdMemberID := strings.TrimSpace(etcdFindResult[0].Output)
 				if etcdMemberID != "" {
 					// etcd 멤버 제거
 					etcdRemoveCmd := []string{
-						fmt.Sprintf("echo '%s' | sudo -S ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member remove %s", requestBody.MainMasterPassword, etcdMemberID),
+						command.Render("sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member remove %s", etcdMemberID),
 					}
 
-					etcdRemoveResult, err := sshUtils.ExecuteCommands(requestBody.MainMasterHops, etcdRemoveCmd, 30000)
+					etcdRemoveResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.MainMasterHops, etcdRemoveCmd, 30000)
 					if err == nil {
 						mainMasterCleanupOutput += etcdRemoveResult[0].Output + "\n"
 					}
@@ -2044,10 +2065,10 @@ EOF`, serverName, serverName),
 		// etcd 최종 확인
 		time.Sleep(5 * time.Second)
 		nodeListCmd := []string{
-			fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes", requestBody.MainMasterPassword),
+			"sudo kubectl get nodes",
 		}
 
-		nodeListResult, err := sshUtils.ExecuteCommands(requestBody.MainMasterHops, nodeListCmd, 30000)
+		nodeListResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.MainMasterHops, nodeListCmd, 30000)
 		if err == nil {
 			mainMasterCleanupOutput += nodeListResult[0].Output + "\n"
 		}
@@ -2061,46 +2082,46 @@ EOF`, serverName, serverName),
 
 	// 대상 노드 서비스 중지
 	stopServicesCmd := []string{
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop kubelet || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop etcd || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 etcd || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-apiserver || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-scheduler || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-controller-manager || true", requestBody.Password),
+		"sudo systemctl stop kubelet || true",
+		"sudo systemctl stop etcd || true",
+		"sudo pkill -9 etcd || true",
+		"sudo pkill -9 kube-apiserver || true",
+		"sudo pkill -9 kube-scheduler || true",
+		"sudo pkill -9 kube-controller-manager || true",
 	}
 
-	_, _ = sshUtils.ExecuteCommands(requestBody.Hops, stopServicesCmd, 60000)
+	_, _ = sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, stopServicesCmd, 60000)
 
 	// kubeadm reset 및 정리
 	cleanupCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubeadm reset -f", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/cni/net.d/*", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -F", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t nat -F", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t mangle -F", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -X", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S ipvsadm --clear", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /root/.kube", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/admin.conf", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/kubelet.conf", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop kubelet", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop containerd", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-apiserver", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-scheduler", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-controller-manager", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S umount -l /var/lib/kubelet/pods/* || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet/pods/*", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/etcd", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable kubelet", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /opt/cni", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubectl", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubeadm", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubelet", requestBody.Password),
-	}
-
-	cleanupResults, err := sshUtils.ExecuteCommands(requestBody.Hops, cleanupCommands, 120000)
+		"sudo kubeadm reset -f",
+		"sudo rm -rf /etc/cni/net.d/*",
+		"sudo iptables -F",
+		"sudo iptables -t nat -F",
+		"sudo iptables -t mangle -F",
+		"sudo iptables -X",
+		"sudo ipvsadm --clear",
+		"sudo rm -rf /root/.kube",
+		"sudo rm -rf /etc/kubernetes/admin.conf",
+		"sudo rm -rf /etc/kubernetes/kubelet.conf",
+		"sudo systemctl stop kubelet",
+		"sudo systemctl stop containerd",
+		"sudo pkill -9 kube-apiserver",
+		"sudo pkill -9 kube-scheduler",
+		"sudo pkill -9 kube-controller-manager",
+		"sudo umount -l /var/lib/kubelet/pods/* || true",
+		"sudo rm -rf /var/lib/kubelet/pods/*",
+		"sudo rm -rf /var/lib/kubelet",
+		"sudo rm -rf /var/lib/etcd",
+		"sudo rm -rf /etc/kubernetes",
+		"sudo systemctl disable kubelet",
+		"sudo rm -rf /opt/cni",
+		"sudo rm -rf /usr/bin/kubectl",
+		"sudo rm -rf /usr/bin/kubeadm",
+		"sudo rm -rf /usr/bin/kubelet",
+	}
+
+	cleanupResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, cleanupCommands, 120000)
 	if err == nil {
 		for _, result := range cleanupResults {
 			masterCleanupOutput += result.Output + "\n"
@@ -2109,17 +2130,17 @@ EOF`, serverName, serverName),
 
 	// 패키지 제거
 	removeCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get clean || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true", requestBody.Password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable containerd || true", requestBody.Password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get clean || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true",
+		"sudo systemctl disable containerd || true",
 	}
 
-	_, _ = sshUtils.ExecuteCommands(requestBody.Hops, removeCommands, 180000)
+	_, _ = sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, removeCommands, 180000)
 
 	// DB에서 마스터 노드 삭제
-	err = db.DeleteMaster(h.DB, requestBody.ID)
+	err = h.Repos.Servers.Delete(requestBody.ID)
 	if err != nil {
 		log.Printf("DB에서 마스터 노드 삭제 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "DB에서 마스터 노드 삭제 실패", "errorDetails": err.Error()})
@@ -2166,13 +2187,13 @@ EOF`, serverName, serverName),
 			"chmod +x /tmp/remove_server.sh",
 
 			// sudo로 스크립트 실행
-			fmt.Sprintf("echo '%s' | sudo -S bash /tmp/remove_server.sh", requestBody.LBPassword),
+			"sudo bash /tmp/remove_server.sh",
 
 			// 임시 스크립트 파일 삭제
 			"rm -f /tmp/remove_server.sh",
 		}
 
-		_, err = sshUtils.ExecuteCommands(requestBody.LBHops, haproxyUpdateCmd, 60000)
+		_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.LBHops, haproxyUpdateCmd, 60000)
 		if err != nil {
 			log.Printf("로드 밸런서 HAProxy 설정 업데이트 실패: %v", err)
 			// 마스터 노드 삭제는 이미 성공했으므로 경고 로그만 남기고 계속 진행
@@ -2183,11 +2204,11 @@ EOF`, serverName, serverName),
 
 	// 로그 파일에 완료 메시지 추가
 	logFinishCommands := []string{
-		fmt.Sprintf("echo '===== 마스터 노드 %s 삭제 작업 완료 =====' >> /tmp/master_delete.log", serverName),
-		fmt.Sprintf("echo '완료 시간: %s' >> /tmp/master_delete.log", time.Now().Format(time.RFC3339)),
+		command.Render("echo %s >> /tmp/master_delete.log", "===== 마스터 노드 "+serverName+" 삭제 작업 완료 ====="),
+		command.Render("echo %s >> /tmp/master_delete.log", "완료 시간: "+time.Now().Format(time.RFC3339)),
 	}
 
-	_, _ = sshUtils.ExecuteCommands(requestBody.Hops, logFinishCommands, 10000)
+	_, _ = sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, logFinishCommands, 10000)
 
 	// 결과 반환
 	var warningMessage string
@@ -2219,7 +2240,7 @@ EOF`, serverName, serverName),
 	})
 }
 func (h *InfraHandler) GetInfras(c *gin.Context) {
-	infras, err := db.GetAllInfras(h.DB)
+	infras, err := h.Repos.Infras.GetAll()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2234,7 +2255,7 @@ func (h *InfraHandler) GetInfraById(c *gin.Context) {
 		return
 	}
 
-	infra, err := db.GetInfraById(h.DB, id)
+	infra, err := h.Repos.Infras.GetByID(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2249,7 +2270,7 @@ func (h *InfraHandler) CreateInfra(c *gin.Context) {
 		return
 	}
 
-	id, err := db.CreateInfra(h.DB, infra)
+	id, err := h.Repos.Infras.Create(infra)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2273,7 +2294,7 @@ func (h *InfraHandler) UpdateInfra(c *gin.Context) {
 	}
 
 	infra.Id = id
-	err = db.UpdateInfra(h.DB, infra)
+	err = h.Repos.Infras.Update(infra)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2288,7 +2309,7 @@ func (h *InfraHandler) DeleteInfra(c *gin.Context) {
 		return
 	}
 
-	err = db.DeleteInfra(h.DB, id)
+	err = h.Repos.Infras.Delete(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2324,18 +2345,12 @@ func (h *InfraHandler) ImportKubernetesInfra(c *gin.Context) {
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
-	// 마지막 hop의 패스워드 가져오기
-	lastHopPassword := ""
-	if len(request.Hops) > 0 {
-		lastHopPassword = request.Hops[len(request.Hops)-1].Password
-	}
-
-	// 단계 1: kubectl 명령어 가능한지 확인 (sudo -S 사용)
-	kubectlCheckCmd := fmt.Sprintf("echo '%s' | sudo -S which kubectl || echo 'KUBECTL_NOT_FOUND'", lastHopPassword)
-	kubectlResults, err := sshUtils.ExecuteCommands(request.Hops, []string{kubectlCheckCmd}, 30000)
+	// 단계 1: kubectl 명령어 가능한지 확인 (sudo 사용)
+	kubectlCheckCmd := "sudo which kubectl || echo 'KUBECTL_NOT_FOUND'"
+	kubectlResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{kubectlCheckCmd}, 30000)
 
 	if err != nil || len(kubectlResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "SSH 연결 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2353,16 +2368,16 @@ func (h *InfraHandler) ImportKubernetesInfra(c *gin.Context) {
 	// 단계 2: 클러스터 정보 수집
 	commands := []string{
 		// 클러스터 정보
-		fmt.Sprintf("echo '%s' | sudo -S kubectl cluster-info", lastHopPassword),
+		"sudo kubectl cluster-info",
 		// 노드 정보
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes -o wide", lastHopPassword),
+		"sudo kubectl get nodes -o wide",
 		// 네임스페이스 목록
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get namespaces", lastHopPassword),
+		"sudo kubectl get namespaces",
 	}
 
-	results, err := sshUtils.ExecuteCommands(request.Hops, commands, 60000)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, commands, 60000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "쿠버네티스 정보 수집 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2538,21 +2553,15 @@ func (h *InfraHandler) ImportDockerInfra(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 가져오기
-	lastHopPassword := ""
-	if len(request.Hops) > 0 {
-		lastHopPassword = request.Hops[len(request.Hops)-1].Password
-	}
-
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
-	// 단계 1: docker 명령어 가능한지 확인 (sudo -S 사용)
-	dockerCheckCmd := fmt.Sprintf("echo '%s' | sudo -S docker version || echo 'DOCKER_NOT_FOUND'", lastHopPassword)
-	dockerResults, err := sshUtils.ExecuteCommands(request.Hops, []string{dockerCheckCmd}, 30000)
+	// 단계 1: docker 명령어 가능한지 확인 (sudo 사용)
+	dockerCheckCmd := "sudo docker version || echo 'DOCKER_NOT_FOUND'"
+	dockerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{dockerCheckCmd}, 30000)
 
 	if err != nil || len(dockerResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "SSH 연결 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2568,11 +2577,11 @@ func (h *InfraHandler) ImportDockerInfra(c *gin.Context) {
 	}
 
 	// 단계 2: 컨테이너 정보 수집
-	containerCmd := fmt.Sprintf("echo '%s' | sudo -S docker ps -a --format '{{.ID}}|{{.Names}}|{{.Image}}|{{.Networks}}'", lastHopPassword)
-	containerResults, err := sshUtils.ExecuteCommands(request.Hops, []string{containerCmd}, 30000)
+	containerCmd := "sudo docker ps -a --format '{{.ID}}|{{.Names}}|{{.Image}}|{{.Networks}}'"
+	containerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{containerCmd}, 30000)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "도커 컨테이너 정보 수집 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2715,12 +2724,6 @@ func contains(arr []string, str string) bool {
 func collectResources(requestBody struct {
 	Hops []ssh.HopConfig `json:"hops"`
 }) (map[string]string, error) {
-	// 마지막 hop의 비밀번호 추출
-	lastHopPassword := ""
-	if len(requestBody.Hops) > 0 {
-		lastHopPassword = requestBody.Hops[len(requestBody.Hops)-1].Password
-	}
-
 	// 리소스 정보 수집 명령어
 	commands := []string{
 		// CPU 정보
@@ -2735,13 +2738,13 @@ func collectResources(requestBody struct {
 		"free | grep Mem | awk '{printf \"%.2f\", $3/$2 * 100}'",
 
 		// 디스크 정보 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $2}'", lastHopPassword),
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $3}'", lastHopPassword),
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $4}'", lastHopPassword),
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $5}'", lastHopPassword),
+		"sudo df -h / | tail -1 | awk '{print $2}'",
+		"sudo df -h / | tail -1 | awk '{print $3}'",
+		"sudo df -h / | tail -1 | awk '{print $4}'",
+		"sudo df -h / | tail -1 | awk '{print $5}'",
 
 		// 네트워크 정보 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S ip -4 addr show | grep inet | awk '{print $NF, $2}' | grep -v '127.0.0.1'", lastHopPassword),
+		"sudo ip -4 addr show | grep inet | awk '{print $NF, $2}' | grep -v '127.0.0.1'",
 
 		// OS 정보
 		"hostname",
@@ -2879,24 +2882,18 @@ func (h *InfraHandler) CalculateNodes(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 비밀번호 추출
-	lastHopPassword := ""
-	if len(requestBody.Hops) > 0 {
-		lastHopPassword = requestBody.Hops[len(requestBody.Hops)-1].Password
-	}
-
 	// SSH 유틸리티 인스턴스 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 쿠버네티스 노드 정보 수집 명령어 (sudo 권한 필요)
 	commands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes -o wide", lastHopPassword),
+		"sudo kubectl get nodes -o wide",
 	}
 
 	// 명령어 실행 (60초 타임아웃)
-	results, err := sshUtils.ExecuteCommands(requestBody.Hops, commands, 60000)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, commands, 60000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "쿠버네티스 노드 정보 수집 중 오류가 발생했습니다.",
 			"detail":  err.Error(),
@@ -2943,8 +2940,8 @@ func (h *InfraHandler) CalculateNodes(c *gin.Context) {
 
 		// 역할 확인 (master 또는 worker)
 		// 노드 라벨을 확인하는 추가 명령 실행
-		roleCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get node %s --show-labels", lastHopPassword, nodeName)
-		roleResults, err := sshUtils.ExecuteCommands(requestBody.Hops, []string{roleCmd}, 30000)
+		roleCmd := command.Render("sudo kubectl get node %s --show-labels", nodeName)
+		roleResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, []string{roleCmd}, 30000)
 
 		if err == nil && len(roleResults) > 0 && roleResults[0].ExitCode == 0 {
 			roleOutput := roleResults[0].Output
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+// testAdmin은 핸들러를 직접 호출하는 테스트에서 사용하는 전역 관리자입니다
+var testAdmin = AuthUser{ID: 1, Username: "admin", Roles: []db.RoleBinding{{UserID: 1, Role: db.RoleAdmin, ScopeType: db.ScopeGlobal}}}
+
+// performJSON은 body를 JSON으로 보내 handler를 실행하고 응답 본문을 디코딩합니다
+func performJSON(t *testing.T, handler gin.HandlerFunc, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	return performJSONAs(t, testAdmin, handler, body)
+}
+
+// performJSONAs는 user로 인증된 요청으로 performJSON과 같이 handler를 실행합니다
+func performJSONAs(t *testing.T, user AuthUser, handler gin.HandlerFunc, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(authUserKey, user)
+
+	handler(c)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("응답 디코딩 실패: %v (%s)", err, w.Body.String())
+	}
+	return w.Code, resp
+}
+
+func TestCalculateResources(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	responses := map[string]string{
+		"cat /proc/cpuinfo | grep 'model name'":     "Intel(R) Xeon(R) CPU E5-2680 v4\n",
+		"nproc --all":                               "8\n",
+		"top -bn1":                                  "12.5\n",
+		"free -m | grep Mem | awk '{print $2}'":     "16000\n",
+		"free -m | grep Mem | awk '{print $3}'":     "4000\n",
+		"free -m | grep Mem | awk '{print $4}'":     "12000\n",
+		"free | grep Mem":                           "25.00",
+		"sudo df -h / | tail -1 | awk '{print $2}'": "100G\n",
+		"sudo df -h / | tail -1 | awk '{print $3}'": "40G\n",
+		"sudo df -h / | tail -1 | awk '{print $4}'": "60G\n",
+		"sudo df -h / | tail -1 | awk '{print $5}'": "40%\n",
+		"sudo ip -4 addr show":                      "eth0 192.168.0.10/24\n",
+		"hostname":                                  "master-1\n",
+		"cat /etc/os-release":                       "Ubuntu 22.04.3 LTS\n",
+		"uname -r":                                  "5.15.0-91-generic\n",
+	}
+	server.HandleFunc(func(cmd string) (sshtest.Response, bool) {
+		for prefix, output := range responses {
+			if strings.HasPrefix(cmd, prefix) {
+				return sshtest.Response{Stdout: output}, true
+			}
+		}
+		return sshtest.Response{}, false
+	})
+
+	h := NewInfraHandler(nil, &db.Repositories{})
+	code, resp := performJSON(t, h.CalculateResources, gin.H{"hops": []ssh.HopConfig{server.Hop()}})
+	if code != http.StatusOK {
+		t.Fatalf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+
+	cpu := resp["cpu"].(map[string]interface{})
+	if cpu["cores"] != "8" || cpu["model"] != "Intel(R) Xeon(R) CPU E5-2680 v4" {
+		t.Errorf("cpu = %v", cpu)
+	}
+	memory := resp["memory"].(map[string]interface{})
+	if memory["total_mb"] != "16000" || memory["usage_percent"] != "25.00" {
+		t.Errorf("memory = %v", memory)
+	}
+	disk := resp["disk"].(map[string]interface{})
+	if disk["root_total"] != "100G" || disk["root_usage_percent"] != "40%" {
+		t.Errorf("disk = %v", disk)
+	}
+	hostInfo := resp["host_info"].(map[string]interface{})
+	if hostInfo["hostname"] != "master-1" || hostInfo["os"] != "Ubuntu 22.04.3 LTS" {
+		t.Errorf("host_info = %v", hostInfo)
+	}
+}
+
+func TestCalculateNodes(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.Handle("sudo kubectl get nodes -o wide", sshtest.Response{Stdout: "" +
+		"NAME       STATUS     ROLES           AGE   VERSION\n" +
+		"master-1   Ready      control-plane   10d   v1.29.0\n" +
+		"worker-1   Ready      <none>          10d   v1.29.0\n" +
+		"worker-2   NotReady   <none>          2d    v1.29.0\n"})
+	server.Handle("sudo kubectl get node master-1 --show-labels", sshtest.Response{
+		Stdout: "master-1 Ready control-plane 10d v1.29.0 node-role.kubernetes.io/control-plane=\n",
+	})
+	server.Handle("sudo kubectl get node worker-1 --show-labels", sshtest.Response{
+		Stdout: "worker-1 Ready <none> 10d v1.29.0 kubernetes.io/hostname=worker-1\n",
+	})
+	// worker-2는 라벨 조회에 실패하여 이름으로 역할을 추측합니다
+	server.Handle("sudo kubectl get node worker-2 --show-labels", sshtest.Response{ExitCode: 1})
+
+	h := NewInfraHandler(nil, &db.Repositories{})
+	code, resp := performJSON(t, h.CalculateNodes, gin.H{"hops": []ssh.HopConfig{server.Hop()}})
+	if code != http.StatusOK {
+		t.Fatalf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+
+	nodes := resp["nodes"].(map[string]interface{})
+	if nodes["total"] != float64(3) || nodes["master"] != float64(1) || nodes["worker"] != float64(2) {
+		t.Errorf("nodes = %v", nodes)
+	}
+	list := nodes["list"].([]interface{})
+	worker2 := list[2].(map[string]interface{})
+	if worker2["name"] != "worker-2" || worker2["status"] != "NotReady" || worker2["role"] != "worker" {
+		t.Errorf("worker-2 = %v", worker2)
+	}
+}
+
+func TestCalculateNodesCommandFailure(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.Handle("sudo kubectl get nodes -o wide", sshtest.Response{Stderr: "connection refused\n", ExitCode: 1})
+
+	h := NewInfraHandler(nil, &db.Repositories{})
+	code, resp := performJSON(t, h.CalculateNodes, gin.H{"hops": []ssh.HopConfig{server.Hop()}})
+	if code != http.StatusInternalServerError || resp["success"] != false {
+		t.Errorf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+}
+
+func TestCalculateResourcesRequiresHops(t *testing.T) {
+	h := NewInfraHandler(nil, &db.Repositories{})
+	code, resp := performJSON(t, h.CalculateResources, gin.H{"hops": []ssh.HopConfig{}})
+	if code != http.StatusBadRequest || resp["success"] != false {
+		t.Errorf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+}
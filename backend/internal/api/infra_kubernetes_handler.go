@@ -3,7 +3,6 @@ package api
 import (
 	"bytes"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,24 +11,31 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
 	"github.com/k8scontrol/backend/internal/db"
 	"github.com/k8scontrol/backend/internal/utils"
 	"github.com/k8scontrol/backend/pkg/ssh"
-	gossh "golang.org/x/crypto/ssh"
 )
 
 // InfraKubernetesHandler 쿠버네티스 관련 API 핸들러
 type InfraKubernetesHandler struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Repos *db.Repositories
 }
 
 // NewInfraKubernetesHandler 새 InfraKubernetesHandler 생성
-func NewInfraKubernetesHandler(db *sql.DB) *InfraKubernetesHandler {
-	return &InfraKubernetesHandler{DB: db}
+func NewInfraKubernetesHandler(database *sql.DB, repos *db.Repositories) *InfraKubernetesHandler {
+	return &InfraKubernetesHandler{DB: database, Repos: repos}
 }
 
 // DeployKubernetes 쿠버네티스 배포를 처리합니다.
 func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
+	// ?async=true이면 작업으로 실행하고 작업 ID를 즉시 반환
+	if asyncRequested(c, nil) {
+		startHandlerJob(c, "deployKubernetes", "", h.DeployKubernetes)
+		return
+	}
+
 	var request struct {
 		ID           int             `json:"id"`            // 서버 ID
 		Hops         []ssh.HopConfig `json:"hops"`          // SSH 연결 정보
@@ -59,7 +65,7 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.DB, request.ID)
+	serverInfo, err := h.Repos.Servers.GetInfo(request.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -70,7 +76,7 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	if len(request.Hops) > 0 {
 		hops = request.Hops
 	} else {
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "hops 파싱 중 오류가 발생했습니다."})
 			return
 		}
@@ -81,17 +87,19 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	// 기본값 설정
 	if request.Branch == "" {
 		request.Branch = "main"
 	}
 
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"namespace", command.FormatDNSLabel, request.Namespace},
+		shellValue{"repo_url", command.FormatGitURL, request.RepoURL},
+		shellValue{"branch", command.FormatGitRef, request.Branch}) {
+		return
+	}
+
 	// 저장소 이름 추출 (URL의 마지막 부분)
 	repoURL := request.RepoURL
 	repoURL = strings.TrimPrefix(repoURL, "https://")
@@ -114,45 +122,27 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 
 	// 1. 작업 디렉토리 생성 및 이전 빌드 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S mkdir -p %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S chown $(whoami):$(whoami) %s", password, workDir))
+		command.Render("sudo rm -rf %s", workDir),
+		command.Render("sudo mkdir -p %s", workDir),
+		command.Render("sudo chown $(whoami):$(whoami) %s", workDir))
 
 	// 2. 필요한 도구 설치 확인 (Git)
 	commands = append(commands,
-		fmt.Sprintf("which git || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y git)", password, password))
+		"which git || (sudo apt-get update && sudo apt-get install -y git)")
 
 	// 3. 저장소 클론 (Git 인증 오류 로깅 개선)
-	gitCmd := ""
-	if request.UsernameRepo != "" && request.PasswordRepo != "" {
-		// URL에서 프로토콜 부분 제거
-		repoNoProtocol := strings.TrimPrefix(request.RepoURL, "https://")
-		repoNoProtocol = strings.TrimPrefix(repoNoProtocol, "http://")
-
-		// @ 문자가 포함된 사용자명 처리 (이메일 주소 등)
-		encodedUsername := request.UsernameRepo
-		if strings.Contains(encodedUsername, "@") {
-			// 사용자명이 이메일인 경우 URL 인코딩 적용
-			encodedUsername = strings.ReplaceAll(encodedUsername, "@", "%40")
-		}
-
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s https://%s:%s@%s . 2>&1 || echo 'Git 클론 실패'",
-			workDir,
-			request.Branch,
-			encodedUsername,
-			request.PasswordRepo,
-			repoNoProtocol)
-	} else {
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s %s . 2>&1 || echo 'Git 클론 실패'",
-			workDir, request.Branch, request.RepoURL)
+	gitCmd, err := gitCloneCommand(workDir, request.RepoURL, request.Branch, request.UsernameRepo, request.PasswordRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
 	}
 	commands = append(commands, gitCmd)
 
 	// 4. 클론 성공 확인 및 디렉토리 내용 확인
-	commands = append(commands, fmt.Sprintf("cd %s && ls -la", workDir))
+	commands = append(commands, command.Render("cd %s && ls -la", workDir))
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 600000) // 10분 타임아웃
+	results, err := sshUtils.ExecuteCommandsStream(c.Request.Context(), hops, commands, 600000, logOutputHandler("쿠버네티스 배포")) // 10분 타임아웃
 	if err != nil {
 		var formattedResults []map[string]interface{}
 		for _, result := range results {
@@ -163,7 +153,7 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 				"exitCode": result.ExitCode,
 			})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "명령 실행 중 오류가 발생했습니다: " + err.Error(),
 			"logs":    formattedResults,
@@ -200,8 +190,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 
 	// k8s 디렉토리 확인
 	k8sDir := fmt.Sprintf("%s/k8s", workDir)
-	checkK8sDirCmd := fmt.Sprintf("ls -la %s", k8sDir)
-	k8sResults, err := sshUtils.ExecuteCommands(hops, []string{checkK8sDirCmd}, 60000)
+	checkK8sDirCmd := command.Render("ls -la %s", k8sDir)
+	k8sResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkK8sDirCmd}, 60000)
 	if err != nil {
 		var formattedResults []map[string]interface{}
 		for _, result := range results {
@@ -212,7 +202,7 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 				"exitCode": result.ExitCode,
 			})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "k8s 디렉토리 확인 실패: " + err.Error(),
 			"logs":    formattedResults,
@@ -251,8 +241,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 
 	// 방법 2: ls *.yaml 명령어 시도
 	if len(yamlFiles) == 0 {
-		lsYamlCmd := fmt.Sprintf("cd %s && ls *.yaml *.yml 2>/dev/null || echo ''", k8sDir)
-		lsResults, err := sshUtils.ExecuteCommands(hops, []string{lsYamlCmd}, 60000)
+		lsYamlCmd := command.Render("cd %s && ls *.yaml *.yml 2>/dev/null || echo ''", k8sDir)
+		lsResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{lsYamlCmd}, 60000)
 		if err == nil {
 			for _, result := range lsResults {
 				if result.Output != "" && !strings.Contains(result.Output, "No such file or directory") {
@@ -270,8 +260,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 
 	// 방법 3: find 명령어로 찾기
 	if len(yamlFiles) == 0 {
-		findYamlCmd := fmt.Sprintf("find %s -maxdepth 1 -name '*.yaml' -o -name '*.yml'", k8sDir)
-		findResults, err := sshUtils.ExecuteCommands(hops, []string{findYamlCmd}, 60000)
+		findYamlCmd := command.Render("find %s -maxdepth 1 -name '*.yaml' -o -name '*.yml'", k8sDir)
+		findResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{findYamlCmd}, 60000)
 		if err == nil {
 			for _, result := range findResults {
 				if strings.Contains(result.Command, "find") && result.Output != "" {
@@ -297,8 +287,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	log.Printf("YAML 파일 목록: %v", yamlFiles)
 
 	// 직접 디렉토리 내용 확인
-	listDirCmd := fmt.Sprintf("cd %s && find . -type f | sort", k8sDir)
-	listResults, _ := sshUtils.ExecuteCommands(hops, []string{listDirCmd}, 60000)
+	listDirCmd := command.Render("cd %s && find . -type f | sort", k8sDir)
+	listResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{listDirCmd}, 60000)
 	for _, result := range listResults {
 		if strings.Contains(result.Command, "find") {
 			log.Printf("k8s 디렉토리 파일 목록 (find):\n%s", result.Output)
@@ -316,11 +306,11 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 
 	// 네임스페이스 확인 및 생성
 	namespaceCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get namespace %s || echo '%s' | sudo -S kubectl create namespace %s",
-			password, request.Namespace, password, request.Namespace),
+		command.Render("sudo kubectl get namespace %s || sudo kubectl create namespace %s",
+			request.Namespace, request.Namespace),
 	}
 
-	_, err = sshUtils.ExecuteCommands(hops, namespaceCommands, 60000)
+	_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, namespaceCommands, 60000)
 	if err != nil {
 		log.Printf("네임스페이스 확인/생성 실패: %v", err)
 	}
@@ -328,8 +318,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	// YAML 파일 처리 - namespace 설정 제거
 	modifiedYamlDir := fmt.Sprintf("%s/k8s_modified", workDir)
 	// 수정된 YAML을 저장할 디렉토리 생성
-	createModifiedDirCmd := fmt.Sprintf("mkdir -p %s", modifiedYamlDir)
-	_, err = sshUtils.ExecuteCommands(hops, []string{createModifiedDirCmd}, 60000)
+	createModifiedDirCmd := command.Render("mkdir -p %s", modifiedYamlDir)
+	_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{createModifiedDirCmd}, 60000)
 	if err != nil {
 		log.Printf("수정된 YAML 디렉토리 생성 실패: %v", err)
 	}
@@ -337,8 +327,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	// 각 YAML 파일에서 namespace 제거
 	for _, yamlFile := range yamlFiles {
 		// YAML 파일 내용 읽기
-		readYamlCmd := fmt.Sprintf("cat %s/%s", k8sDir, yamlFile)
-		yamlResults, err := sshUtils.ExecuteCommands(hops, []string{readYamlCmd}, 60000)
+		readYamlCmd := command.Render("cat %s", k8sDir+"/"+yamlFile)
+		yamlResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{readYamlCmd}, 60000)
 		if err != nil {
 			log.Printf("YAML 파일 %s 읽기 실패: %v", yamlFile, err)
 			continue
@@ -356,17 +346,17 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 		modifiedYamlPath := fmt.Sprintf("%s/%s", modifiedYamlDir, yamlFile)
 
 		// sed를 사용하여 "namespace: xxx" 행만 제거 (띄어쓰기 다양성 고려)
-		removeNamespaceCmd := fmt.Sprintf("cat %s/%s | sed '/^[[:space:]]*namespace:/d' > %s",
-			k8sDir, yamlFile, modifiedYamlPath)
-		_, err = sshUtils.ExecuteCommands(hops, []string{removeNamespaceCmd}, 60000)
+		removeNamespaceCmd := command.Render("cat %s | sed '/^[[:space:]]*namespace:/d' > %s",
+			k8sDir+"/"+yamlFile, modifiedYamlPath)
+		_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{removeNamespaceCmd}, 60000)
 		if err != nil {
 			log.Printf("YAML 파일 %s에서 namespace 제거 실패: %v", yamlFile, err)
 			continue
 		}
 
 		// 수정된 파일 내용 확인 (디버깅용)
-		checkModifiedCmd := fmt.Sprintf("cat %s", modifiedYamlPath)
-		checkResults, err := sshUtils.ExecuteCommands(hops, []string{checkModifiedCmd}, 60000)
+		checkModifiedCmd := command.Render("cat %s", modifiedYamlPath)
+		checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkModifiedCmd}, 60000)
 		if err != nil {
 			log.Printf("수정된 YAML 파일 %s 확인 실패: %v", yamlFile, err)
 		} else {
@@ -386,7 +376,7 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 
 	// kubectl 설치 확인
 	applyCommands = append(applyCommands,
-		fmt.Sprintf("which kubectl || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y kubectl)", password, password))
+		"which kubectl || (sudo apt-get update && sudo apt-get install -y kubectl)")
 
 	// 파일 적용 순서 조정: 시크릿 파일을 먼저 적용
 	var secretFiles []string
@@ -394,8 +384,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 
 	for _, yamlFile := range yamlFiles {
 		// 파일 내용을 읽어서 시크릿인지 확인
-		checkFileCmd := fmt.Sprintf("cat %s/%s | grep -i 'kind:[[:space:]]*Secret'", modifiedYamlDir, yamlFile)
-		checkResults, err := sshUtils.ExecuteCommands(hops, []string{checkFileCmd}, 60000)
+		checkFileCmd := command.Render("cat %s | grep -i 'kind:[[:space:]]*Secret'", modifiedYamlDir+"/"+yamlFile)
+		checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkFileCmd}, 60000)
 
 		isSecret := false
 		if err == nil {
@@ -418,8 +408,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	log.Printf("시크릿 파일 적용 (%d개): %v", len(secretFiles), secretFiles)
 	for _, yamlFile := range secretFiles {
 		modifiedYamlPath := fmt.Sprintf("%s/%s", modifiedYamlDir, yamlFile)
-		applyCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl apply -f %s -n %s",
-			password, modifiedYamlPath, request.Namespace)
+		applyCmd := command.Render("sudo kubectl apply -f %s -n %s",
+			modifiedYamlPath, request.Namespace)
 		applyCommands = append(applyCommands, applyCmd)
 	}
 
@@ -432,13 +422,13 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	log.Printf("일반 파일 적용 (%d개): %v", len(otherFiles), otherFiles)
 	for _, yamlFile := range otherFiles {
 		modifiedYamlPath := fmt.Sprintf("%s/%s", modifiedYamlDir, yamlFile)
-		applyCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl apply -f %s -n %s",
-			password, modifiedYamlPath, request.Namespace)
+		applyCmd := command.Render("sudo kubectl apply -f %s -n %s",
+			modifiedYamlPath, request.Namespace)
 		applyCommands = append(applyCommands, applyCmd)
 	}
 
 	// 명령 실행
-	applyResults, err := sshUtils.ExecuteCommands(hops, applyCommands, 300000) // 5분 타임아웃
+	applyResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, applyCommands, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var applyOutputs []map[string]interface{}
@@ -462,8 +452,8 @@ func (h *InfraKubernetesHandler) DeployKubernetes(c *gin.Context) {
 	}
 
 	// 작업 완료 후 클론한 폴더 제거
-	cleanupCmd := fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir)
-	_, cleanupErr := sshUtils.ExecuteCommands(hops, []string{cleanupCmd}, 60000)
+	cleanupCmd := command.Render("sudo rm -rf %s", workDir)
+	_, cleanupErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{cleanupCmd}, 60000)
 	if cleanupErr != nil {
 		log.Printf("작업 디렉토리 정리 실패: %v", cleanupErr)
 	} else {
@@ -505,18 +495,16 @@ func (h *InfraKubernetesHandler) DeleteNamespace(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(request.Hops) > 0 {
-		password = request.Hops[len(request.Hops)-1].Password
+	if !validateShellValues(c, shellValue{"namespace", command.FormatDNSLabel, request.Namespace}) {
+		return
 	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 네임스페이스 삭제 명령
-	deleteCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl delete namespace %s", password, request.Namespace)
-	results, err := sshUtils.ExecuteCommands(request.Hops, []string{deleteCmd}, 300000) // 5분 타임아웃
+	deleteCmd := command.Render("sudo kubectl delete namespace %s", request.Namespace)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{deleteCmd}, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var formattedResults []map[string]interface{}
@@ -560,9 +548,9 @@ func (h *InfraKubernetesHandler) DeleteNamespace(c *gin.Context) {
 
 		for i := 0; i < maxWaitTime; i += interval {
 			// 네임스페이스 존재 여부 확인
-			checkCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
-				password, request.Namespace)
-			checkResults, err := sshUtils.ExecuteCommands(request.Hops, []string{checkCmd}, 30000)
+			checkCmd := command.Render("sudo kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
+				request.Namespace)
+			checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{checkCmd}, 30000)
 
 			namespaceExists := true
 			if err == nil {
@@ -582,14 +570,14 @@ func (h *InfraKubernetesHandler) DeleteNamespace(c *gin.Context) {
 			// 5초 대기 후 다시 확인
 			if i+interval < maxWaitTime {
 				sleepCmd := fmt.Sprintf("sleep %d", interval)
-				sshUtils.ExecuteCommands(request.Hops, []string{sleepCmd}, 30000)
+				sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{sleepCmd}, 30000)
 			}
 		}
 
 		// 최종 확인
-		finalCheckCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
-			password, request.Namespace)
-		finalResults, _ := sshUtils.ExecuteCommands(request.Hops, []string{finalCheckCmd}, 30000)
+		finalCheckCmd := command.Render("sudo kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
+			request.Namespace)
+		finalResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{finalCheckCmd}, 30000)
 
 		namespaceStillExists := false
 		for _, result := range finalResults {
@@ -639,19 +627,17 @@ func (h *InfraKubernetesHandler) GetNamespaceAndPodStatus(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(request.Hops) > 0 {
-		password = request.Hops[len(request.Hops)-1].Password
+	if !validateShellValues(c, shellValue{"namespace", command.FormatDNSLabel, request.Namespace}) {
+		return
 	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 네임스페이스 존재 여부 확인
-	namespaceCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
-		password, request.Namespace)
-	namespaceResults, err := sshUtils.ExecuteCommands(request.Hops, []string{namespaceCmd}, 30000)
+	namespaceCmd := command.Render("sudo kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
+		request.Namespace)
+	namespaceResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{namespaceCmd}, 30000)
 
 	namespaceExists := false
 	if err == nil {
@@ -667,9 +653,9 @@ func (h *InfraKubernetesHandler) GetNamespaceAndPodStatus(c *gin.Context) {
 	var pods []map[string]interface{}
 	if namespaceExists {
 		// 파드 정보 가져오기 (이름, 상태, 재시작 횟수)
-		podCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get pods -n %s -o custom-columns=NAME:.metadata.name,STATUS:.status.phase,RESTARTS:.status.containerStatuses[0].restartCount 2>/dev/null",
-			password, request.Namespace)
-		podResults, err := sshUtils.ExecuteCommands(request.Hops, []string{podCmd}, 30000)
+		podCmd := command.Render("sudo kubectl get pods -n %s -o custom-columns=NAME:.metadata.name,STATUS:.status.phase,RESTARTS:.status.containerStatuses[0].restartCount 2>/dev/null",
+			request.Namespace)
+		podResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{podCmd}, 30000)
 
 		if err == nil {
 			for _, result := range podResults {
@@ -742,6 +728,12 @@ func (h *InfraKubernetesHandler) GetPodLogs(c *gin.Context) {
 		return
 	}
 
+	if !validateShellValues(c,
+		shellValue{"namespace", command.FormatDNSLabel, request.Namespace},
+		shellValue{"pod_name", command.FormatDNSSubdomain, request.PodName}) {
+		return
+	}
+
 	// 기본값 설정
 	if request.Lines <= 0 {
 		request.Lines = 100 // 기본값: 100줄
@@ -758,12 +750,12 @@ func (h *InfraKubernetesHandler) GetPodLogs(c *gin.Context) {
 	}
 	defer client.Close()
 
-	// 마지막 Hop의 패스워드 가져오기
-	password := request.Hops[0].Password
+	// NOPASSWD 호스트에서는 sudo 비밀번호를 stdin으로 보내지 않도록 sudo 방식을 먼저 확인
+	sudoHop := client.ProbeSudo(request.Hops[len(request.Hops)-1])
 
 	// 1. 파드 존재 여부 확인
-	podCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get pod %s -n %s -o name || echo 'not found'",
-		password, request.PodName, request.Namespace)
+	podCmd := command.Render("sudo kubectl get pod %s -n %s -o name || echo 'not found'",
+		request.PodName, request.Namespace)
 
 	session, err := client.NewSession()
 	if err != nil {
@@ -777,7 +769,7 @@ func (h *InfraKubernetesHandler) GetPodLogs(c *gin.Context) {
 	var podOutput bytes.Buffer
 	session.Stdout = &podOutput
 
-	if err := session.Run(podCmd); err != nil {
+	if err := session.Run(ssh.SudoCommand(session, sudoHop, podCmd)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("파드 확인 명령 실행 실패: %v", err),
@@ -801,8 +793,8 @@ func (h *InfraKubernetesHandler) GetPodLogs(c *gin.Context) {
 	tmpFileName := fmt.Sprintf("/tmp/pod_logs_%s_%s_%d.txt", request.Namespace, request.PodName, time.Now().Unix())
 
 	// 로그를 임시 파일로 저장 - 오류 출력 포함하여 전체 정보 보존
-	logCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl logs --tail=%d %s -n %s > %s",
-		password, request.Lines, request.PodName, request.Namespace, tmpFileName)
+	logCmd := command.Render("sudo kubectl logs --tail=%d %s -n %s > %s",
+		request.Lines, request.PodName, request.Namespace, tmpFileName)
 
 	session, err = client.NewSession()
 	if err != nil {
@@ -813,7 +805,7 @@ func (h *InfraKubernetesHandler) GetPodLogs(c *gin.Context) {
 		return
 	}
 
-	if err := session.Run(logCmd); err != nil {
+	if err := session.Run(ssh.SudoCommand(session, sudoHop, logCmd)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("로그 명령 실행 실패: %v", err),
@@ -823,7 +815,7 @@ func (h *InfraKubernetesHandler) GetPodLogs(c *gin.Context) {
 	session.Close()
 
 	// 3. 임시 파일에서 로그 내용 읽기
-	catCmd := fmt.Sprintf("cat %s", tmpFileName)
+	catCmd := command.Render("cat %s", tmpFileName)
 	session, err = client.NewSession()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -846,7 +838,7 @@ func (h *InfraKubernetesHandler) GetPodLogs(c *gin.Context) {
 	session.Close()
 
 	// 4. 임시 파일 삭제
-	cleanupCmd := fmt.Sprintf("rm -f %s", tmpFileName)
+	cleanupCmd := command.Render("rm -f %s", tmpFileName)
 	session, err = client.NewSession()
 	if err != nil {
 		// 파일 삭제 실패해도 계속 진행
@@ -883,24 +875,13 @@ func cleanupKubernetesLogs(logs string) string {
 }
 
 // SSH 클라이언트 생성을 위한 헬퍼 함수
-func (h *InfraKubernetesHandler) createSSHClient(hops []ssh.HopConfig) (*gossh.Client, error) {
+// 공유 연결 풀에서 hops 체인 연결을 가져오며, Close하면 풀에 반납됩니다
+func (h *InfraKubernetesHandler) createSSHClient(hops []ssh.HopConfig) (*ssh.Client, error) {
 	if len(hops) == 0 {
 		return nil, fmt.Errorf("SSH 연결 정보가 없습니다")
 	}
 
-	hop := hops[0]
-
-	sshConfig := &gossh.ClientConfig{
-		User: hop.Username,
-		Auth: []gossh.AuthMethod{
-			gossh.Password(hop.Password),
-		},
-		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
-		Timeout:         time.Duration(60) * time.Second,
-	}
-
-	hostPort := fmt.Sprintf("%s:%d", hop.Host, hop.Port)
-	client, err := gossh.Dial("tcp", hostPort, sshConfig)
+	client, err := ssh.NewSSHService().Acquire(hops, time.Duration(60)*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("SSH 연결 실패: %v", err)
 	}
@@ -939,20 +920,20 @@ func (h *InfraKubernetesHandler) RestartPod(c *gin.Context) {
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(request.Hops) > 0 {
-		password = request.Hops[len(request.Hops)-1].Password
+	if !validateShellValues(c,
+		shellValue{"namespace", command.FormatDNSLabel, request.Namespace},
+		shellValue{"pod_name", command.FormatDNSSubdomain, request.PodName}) {
+		return
 	}
 
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 파드 삭제 명령 실행
-	deleteCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl delete pod %s -n %s",
-		password, request.PodName, request.Namespace)
+	deleteCmd := command.Render("sudo kubectl delete pod %s -n %s",
+		request.PodName, request.Namespace)
 
-	results, err := sshUtils.ExecuteCommands(request.Hops, []string{deleteCmd}, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), request.Hops, []string{deleteCmd}, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var formattedResults []map[string]interface{}
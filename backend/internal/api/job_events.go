@@ -0,0 +1,179 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/k8scontrol/backend/internal/command"
+)
+
+// jobEventKeepAlive는 이벤트가 없을 때 연결 유지를 위해 보내는 주기입니다
+const jobEventKeepAlive = 15 * time.Second
+
+// JobHandler는 비동기 작업의 진행 상황 스트리밍을 처리합니다
+type JobHandler struct {
+	DB *sql.DB
+}
+
+// NewJobHandler 새 JobHandler 생성
+func NewJobHandler(db *sql.DB) *JobHandler {
+	return &JobHandler{DB: db}
+}
+
+// jobEventUpgrader는 작업 이벤트 WebSocket 연결을 수립합니다
+// 브라우저 WebSocket은 CORS를 따르지 않으므로 Origin을 AllowedOrigins로 직접 확인합니다
+var jobEventUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, allowed := range AllowedOrigins {
+			if origin == allowed {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+// StreamJobEvents는 작업의 진행 이벤트(단계 시작/종료, 출력, 상태)를 실시간으로 전달합니다
+// 기본은 Server-Sent Events이며, WebSocket 업그레이드 요청이면 WebSocket으로 JSON 이벤트를 보냅니다
+// 새로 연결하면 보관된 이벤트를 먼저 재생하므로 새로고침한 브라우저도 진행 중인 작업에 다시 붙을 수 있습니다
+// 재연결 시 Last-Event-ID 헤더 또는 after 쿼리 파라미터로 마지막으로 받은 seq를 보내면 그 이후 이벤트만 받습니다
+// 작업이 끝나면 최종 state 이벤트를 보낸 뒤 연결을 닫습니다 (SSE 클라이언트는 이때 EventSource를 닫아야 합니다)
+func (h *JobHandler) StreamJobEvents(c *gin.Context) {
+	jobID := c.Param("job_id")
+	afterSeq := lastEventSeq(c)
+
+	replay, events, unsubscribe, err := command.DefaultJobManager().Subscribe(jobID, afterSeq)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		streamJobEventsWebSocket(c, replay, events)
+		return
+	}
+	streamJobEventsSSE(c, replay, events)
+}
+
+// lastEventSeq는 클라이언트가 마지막으로 받은 이벤트 순번을 반환합니다 (없으면 0)
+func lastEventSeq(c *gin.Context) int64 {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("after")
+	}
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seq < 0 {
+		return 0
+	}
+	return seq
+}
+
+// streamJobEventsSSE는 이벤트를 text/event-stream 형식으로 전달합니다
+func streamJobEventsSSE(c *gin.Context, replay []command.JobEvent, events <-chan command.JobEvent) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 프록시 버퍼링 비활성화
+	c.Status(http.StatusOK)
+
+	for _, event := range replay {
+		if err := writeSSEEvent(c, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(jobEventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(c, event); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeSSEEvent는 이벤트 하나를 SSE 형식으로 씁니다. id에는 seq를 사용합니다
+func writeSSEEvent(c *gin.Context, event command.JobEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
+
+// streamJobEventsWebSocket은 이벤트를 WebSocket 텍스트 메시지(JSON)로 전달합니다
+func streamJobEventsWebSocket(c *gin.Context, replay []command.JobEvent, events <-chan command.JobEvent) {
+	conn, err := jobEventUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("[작업 이벤트] WebSocket 업그레이드 실패: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// 클라이언트가 연결을 닫으면 읽기가 실패하므로 이를 종료 신호로 사용
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, event := range replay {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(jobEventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
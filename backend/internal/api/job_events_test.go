@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+// startBlockedJob은 release가 닫힐 때까지 끝나지 않는 인프라 infraID의 작업을 시작합니다
+func startBlockedJob(t *testing.T, infraID int) (string, chan struct{}) {
+	t.Helper()
+	command.SetJobStore(command.NewMemoryJobStore())
+	release := make(chan struct{})
+	job, err := command.DefaultJobManager().Start("blocked", "", infraID, func(ctx context.Context) (interface{}, error) {
+		<-release
+		return gin.H{"done": true}, nil
+	})
+	if err != nil {
+		t.Fatalf("작업 시작 실패: %v", err)
+	}
+	return job.ID, release
+}
+
+// newJobEventServer는 user로 인증된 요청을 받는 작업 이벤트 경로만 등록한 테스트 서버를 띄웁니다
+func newJobEventServer(t *testing.T, user AuthUser) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(authUserKey, user) })
+	JobRoutes(router, nil)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStreamJobEventsSSE(t *testing.T) {
+	server := newJobEventServer(t, testAdmin)
+	jobID, release := startBlockedJob(t, 7)
+
+	resp, err := http.Get(server.URL + "/api/v1/jobs/" + jobID + "/events")
+	if err != nil {
+		t.Fatalf("SSE 연결 실패: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("응답 = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// 재생된 상태 이벤트를 받은 뒤 작업을 끝내고 최종 상태까지 읽습니다
+	var states []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var event command.JobEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			t.Fatalf("이벤트 디코딩 실패: %v (%s)", err, line)
+		}
+		states = append(states, string(event.State))
+		if event.State == command.JobRunning {
+			close(release)
+		}
+	}
+	if got := strings.Join(states, ","); got != "queued,running,succeeded" {
+		t.Errorf("상태 이벤트 = %s", got)
+	}
+
+	resp, err = http.Get(server.URL + "/api/v1/jobs/missing/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("없는 작업 상태 코드 = %d", resp.StatusCode)
+	}
+}
+
+func TestStreamJobEventsWebSocket(t *testing.T) {
+	server := newJobEventServer(t, testAdmin)
+	jobID, release := startBlockedJob(t, 7)
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := command.DefaultJobManager().Wait(ctx, jobID); err != nil {
+		t.Fatal(err)
+	}
+
+	// after=1 이므로 queued 이벤트는 다시 받지 않습니다
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/jobs/" + jobID + "/events?after=1"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("WebSocket 연결 실패: %v", err)
+	}
+	defer conn.Close()
+
+	var states []string
+	for {
+		var event command.JobEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				t.Fatalf("이벤트 읽기 실패: %v", err)
+			}
+			break
+		}
+		states = append(states, string(event.State))
+	}
+	if got := strings.Join(states, ","); got != "running,succeeded" {
+		t.Errorf("상태 이벤트 = %s", got)
+	}
+}
+
+func TestStreamJobEventsAuthorization(t *testing.T) {
+	viewer := AuthUser{ID: 2, Username: "viewer", Roles: []db.RoleBinding{{Role: db.RoleViewer, ScopeType: db.ScopeInfra, ScopeID: 7}}}
+	server := newJobEventServer(t, viewer)
+
+	// 다른 인프라의 작업 이벤트는 구독할 수 없음
+	jobID, release := startBlockedJob(t, 8)
+	defer close(release)
+	resp, err := http.Get(server.URL + "/api/v1/jobs/" + jobID + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("다른 인프라 작업 구독 상태 코드 = %d, want 403", resp.StatusCode)
+	}
+}
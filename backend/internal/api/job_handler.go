@@ -0,0 +1,257 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/pkg/redact"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// jobContextKey는 핸들러가 작업 안에서 실행 중임을 gin 컨텍스트에 표시하는 키입니다
+const jobContextKey = "k8scontrol.in_job"
+
+// asyncRequested는 요청이 비동기 작업 실행을 원하는지 확인합니다
+// 쿼리 파라미터 async=true 또는 액션 파라미터 async가 true이면 비동기로 실행하며,
+// 이미 작업 안에서 실행 중인 핸들러는 다시 작업을 만들지 않으며, 비동기 작업 기능이 꺼져 있으면 동기로 실행합니다
+func asyncRequested(c *gin.Context, params map[string]interface{}) bool {
+	if !Features.AsyncJobs {
+		return false
+	}
+	if _, inJob := c.Get(jobContextKey); inJob {
+		return false
+	}
+	if async, ok := params["async"].(bool); ok {
+		return async
+	}
+	async, _ := strconv.ParseBool(c.Query("async"))
+	return async
+}
+
+// startHandlerJob은 handler를 백그라운드 작업으로 실행하고 작업 ID를 202 응답으로 반환합니다
+// handler는 같은 요청 본문을 가진 별도의 컨텍스트에서 실행되며, handler의 JSON 응답이 작업 결과로 저장됩니다
+// 응답 상태 코드가 400 이상이면 응답의 error 필드를 오류로 하여 작업을 실패로 기록합니다
+// 작업 대상은 요청 본문의 hops로 정하며, 본문에 hops가 없으면 target을 사용합니다
+func startHandlerJob(c *gin.Context, action, target string, handler gin.HandlerFunc) {
+	body, err := requestBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "요청 본문을 읽을 수 없습니다: " + err.Error()})
+		return
+	}
+	req := c.Request.Clone(context.Background())
+	if hopTarget := hopsTarget(body); hopTarget != "" {
+		target = hopTarget
+	}
+	audit := currentAuditRecord(c)
+
+	job, err := command.DefaultJobManager().Start(action, target, currentActionScope(c).InfraID, func(ctx context.Context) (interface{}, error) {
+		// 요청이 끝난 뒤에도 작업이 실행되는 동안 요청 본문의 비밀값을 계속 가림
+		defer redact.Track(bodySecrets(body)...)()
+
+		w := &jobResponseWriter{header: make(http.Header), status: http.StatusOK}
+
+		// 요청 감사 기록과 별도로 작업의 실행 결과와 명령어 수를 감사 기록으로 남김
+		start := time.Now()
+		var commands int64
+		if audit != nil {
+			defer func() {
+				audit.finish(command.JobIDFromContext(ctx), w.status, w.body.Bytes(), atomic.LoadInt64(&commands), time.Since(start))
+			}()
+		}
+		jc, _ := gin.CreateTestContext(w)
+		jc.Request = req.WithContext(ssh.WithOutputHandler(ctx, countCommands(&commands)))
+		jc.Request.Body = io.NopCloser(bytes.NewReader(body))
+		jc.Set(jobContextKey, true)
+		if user, ok := CurrentUser(c); ok {
+			jc.Set(authUserKey, user)
+		}
+
+		handler(jc)
+
+		var result interface{}
+		if w.body.Len() > 0 {
+			if err := json.Unmarshal(w.body.Bytes(), &result); err != nil {
+				result = w.body.String()
+			}
+		}
+		if w.status >= http.StatusBadRequest {
+			return result, handlerJobError(w.status, result)
+		}
+		return result, nil
+	})
+	if err != nil {
+		log.Printf("[작업 생성 오류] %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "작업이 백그라운드에서 시작되었습니다.",
+		"job_id":  job.ID,
+		"job":     job,
+	})
+}
+
+// requestBody는 요청 본문을 반환합니다
+// ShouldBindBodyWith로 이미 읽은 본문은 gin 컨텍스트에 보관된 값을 사용합니다
+func requestBody(c *gin.Context) ([]byte, error) {
+	if cached, ok := c.Get(gin.BodyBytesKey); ok {
+		if body, ok := cached.([]byte); ok {
+			return body, nil
+		}
+	}
+	return c.GetRawData()
+}
+
+// hopsTarget은 요청 본문의 hops로 작업 대상 설명을 만듭니다
+// 액션 디스패처 요청처럼 hops가 parameters 안에 있는 본문도 처리합니다
+func hopsTarget(body []byte) string {
+	var req struct {
+		Hops       []ssh.HopConfig `json:"hops"`
+		Parameters struct {
+			Hops []ssh.HopConfig `json:"hops"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	hops := req.Hops
+	if len(hops) == 0 {
+		hops = req.Parameters.Hops
+	}
+	if len(hops) == 0 {
+		return ""
+	}
+	return (&command.CommandTarget{Hops: hops}).GetDescription()
+}
+
+// handlerJobError는 실패한 핸들러 응답에서 작업 오류를 만듭니다
+func handlerJobError(status int, result interface{}) error {
+	if resp, ok := result.(map[string]interface{}); ok {
+		if msg, ok := resp["error"].(string); ok && msg != "" {
+			return errors.New(msg)
+		}
+	}
+	return fmt.Errorf("요청 처리 실패 (HTTP %d)", status)
+}
+
+// jobResponseWriter는 작업으로 실행한 핸들러의 응답을 메모리에 기록합니다
+type jobResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+// Header는 http.ResponseWriter를 구현합니다
+func (w *jobResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write는 http.ResponseWriter를 구현합니다
+func (w *jobResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// WriteHeader는 http.ResponseWriter를 구현합니다
+func (w *jobResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// handleGetJob은 작업 상태와 단계별 결과를 조회합니다
+func (h *KubernetesHandler) handleGetJob(c *gin.Context, request CommandRequest) {
+	jobID, _ := request.Parameters["job_id"].(string)
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "job_id 파라미터가 필요합니다"})
+		return
+	}
+
+	job, err := command.DefaultJobManager().Get(jobID)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "job": job})
+}
+
+// handleListJobs는 작업 목록을 최근 생성 순으로 조회합니다 (state, action, limit으로 필터링)
+// 사용자가 역할을 가진 인프라의 작업만 반환합니다
+func (h *KubernetesHandler) handleListJobs(c *gin.Context, request CommandRequest) {
+	state, _ := request.Parameters["state"].(string)
+	jobState, err := command.ParseJobState(state)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	filter := command.JobFilter{State: jobState}
+	filter.Action, _ = request.Parameters["action"].(string)
+	if limit, err := getIntParameter(request.Parameters["limit"]); err == nil {
+		filter.Limit = limit
+	}
+	if user, ok := CurrentUser(c); ok {
+		filter.InfraIDs, err = jobInfraFilter(h.db, user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "권한 조회 실패: " + err.Error()})
+			return
+		}
+	}
+
+	jobs, err := command.DefaultJobManager().List(filter)
+	if err != nil {
+		log.Printf("[작업 목록 조회 오류] %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "작업 목록 조회 실패: " + err.Error()})
+		return
+	}
+	if jobs == nil {
+		jobs = []command.Job{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "jobs": jobs})
+}
+
+// handleCancelJob은 실행 중인 작업을 취소하고 취소된 작업 상태를 반환합니다
+func (h *KubernetesHandler) handleCancelJob(c *gin.Context, request CommandRequest) {
+	jobID, _ := request.Parameters["job_id"].(string)
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "job_id 파라미터가 필요합니다"})
+		return
+	}
+
+	if err := command.DefaultJobManager().Cancel(jobID); err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	job, err := command.DefaultJobManager().Get(jobID)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "작업이 취소되었습니다.", "job": job})
+}
+
+// jobErrorStatus는 작업 조회/취소 오류를 HTTP 상태 코드로 변환합니다
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, command.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, command.ErrJobFinished), errors.Is(err, command.ErrJobNotLocal):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
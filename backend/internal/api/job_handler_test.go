@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+func TestStartHandlerJob(t *testing.T) {
+	command.SetJobStore(command.NewMemoryJobStore())
+
+	// 작업 안에서는 같은 요청 본문을 다시 읽을 수 있고, async가 다시 적용되지 않아야 합니다
+	inner := func(c *gin.Context) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "name이 필요합니다"})
+			return
+		}
+		if asyncRequested(c, map[string]interface{}{"async": true}) {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "작업 안에서 다시 비동기 실행됨"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "hello": body.Name})
+	}
+	handler := func(c *gin.Context) {
+		startHandlerJob(c, "greet", "", inner)
+	}
+
+	tests := []struct {
+		body      gin.H
+		wantState command.JobState
+		wantError string
+	}{
+		{gin.H{"name": "k8s"}, command.JobSucceeded, ""},
+		{gin.H{}, command.JobFailed, "name이 필요합니다"},
+	}
+
+	for _, tt := range tests {
+		code, resp := performJSON(t, handler, tt.body)
+		if code != http.StatusAccepted || resp["job_id"] == "" {
+			t.Fatalf("상태 코드 = %d, 응답: %v", code, resp)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		job, err := command.DefaultJobManager().Wait(ctx, resp["job_id"].(string))
+		cancel()
+		if err != nil {
+			t.Fatalf("작업 대기 실패: %v", err)
+		}
+		if job.State != tt.wantState || job.Error != tt.wantError {
+			t.Errorf("작업 = %s (%s), want %s (%s)", job.State, job.Error, tt.wantState, tt.wantError)
+		}
+		if tt.wantState == command.JobSucceeded && string(job.Result) != `{"hello":"k8s","success":true}` {
+			t.Errorf("작업 결과 = %s", job.Result)
+		}
+	}
+}
+
+func TestDispatcherAsyncJobMasksSecrets(t *testing.T) {
+	command.SetJobStore(command.NewMemoryJobStore())
+	sshtest.UseMemoryHostKeys(t)
+	const password = "registry-pass-5678"
+	server := sshtest.NewServer(t)
+	// 원격 도구가 요청 본문의 비밀번호를 출력에 그대로 남기는 경우
+	server.HandleFunc(func(cmd string) (sshtest.Response, bool) {
+		return sshtest.Response{Stdout: "registry login " + password + "\n", ExitCode: 1}, true
+	})
+
+	database, err := db.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	repos := db.NewRepositories(database)
+	infraID, err := repos.Infras.Create(db.Infra{Name: "lab", Type: "kubernetes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverID, err := repos.Servers.Create(db.ServerInput{ServerName: "master-1", Hops: `[{"host":"10.0.0.2","port":22,"username":"u"}]`, Type: "master", InfraID: infraID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewKubernetesHandler(database, repos)
+	code, resp := performJSON(t, h.HandleRequest, gin.H{
+		"action":     ActionInstallFirstMaster,
+		"parameters": gin.H{"async": true, "server_id": serverID, "hops": []gin.H{{"host": server.Host, "port": server.Port, "username": server.User, "password": server.Password}}, "registry_password": password},
+	})
+	if code != http.StatusAccepted {
+		t.Fatalf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	job, err := command.DefaultJobManager().Wait(ctx, resp["job_id"].(string))
+	if err != nil {
+		t.Fatalf("작업 대기 실패: %v", err)
+	}
+	if !strings.Contains(job.Target, server.Host) {
+		t.Errorf("작업 대상 = %q, hops의 호스트가 없습니다", job.Target)
+	}
+	if len(job.Steps) == 0 {
+		t.Fatalf("기록된 단계가 없습니다: %+v", job)
+	}
+	if data, _ := json.Marshal(job); strings.Contains(string(data), password) {
+		t.Errorf("작업 이벤트에 비밀번호가 노출됨: %s", data)
+	}
+	if !strings.Contains(job.Steps[0].Output, "registry login "+command.SecretMask) {
+		t.Errorf("단계 출력 = %q", job.Steps[0].Output)
+	}
+}
+
+func TestGetJobAction(t *testing.T) {
+	command.SetJobStore(command.NewMemoryJobStore())
+	h := NewKubernetesHandler(nil, &db.Repositories{})
+
+	code, resp := performJSON(t, h.HandleRequest, gin.H{"action": ActionGetJob, "parameters": gin.H{"job_id": "missing"}})
+	if code != http.StatusNotFound || resp["success"] != false {
+		t.Errorf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+
+	code, resp = performJSON(t, h.HandleRequest, gin.H{"action": ActionListJobs, "parameters": gin.H{"state": "bogus"}})
+	if code != http.StatusBadRequest {
+		t.Errorf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+}
+
+func TestJobAccessByInfra(t *testing.T) {
+	command.SetJobStore(command.NewMemoryJobStore())
+	h := NewKubernetesHandler(nil, &db.Repositories{})
+
+	// 인프라 7, 8의 작업과 인프라가 없는 작업
+	jobIDs := map[int]string{}
+	for _, infraID := range []int{7, 8, 0} {
+		job, err := command.DefaultJobManager().Start("test", "", infraID, func(ctx context.Context) (interface{}, error) {
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobIDs[infraID] = job.ID
+	}
+
+	viewer := AuthUser{ID: 2, Username: "viewer", Roles: []db.RoleBinding{{Role: db.RoleViewer, ScopeType: db.ScopeInfra, ScopeID: 7}}}
+	tests := []struct {
+		name  string
+		user  AuthUser
+		infra int
+		want  int
+	}{
+		{"자기 인프라의 작업", viewer, 7, http.StatusOK},
+		{"다른 인프라의 작업", viewer, 8, http.StatusForbidden},
+		{"인프라가 없는 작업", viewer, 0, http.StatusForbidden},
+		{"전역 관리자", testAdmin, 8, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := performJSONAs(t, tt.user, h.HandleRequest, gin.H{"action": ActionGetJob, "parameters": gin.H{"job_id": jobIDs[tt.infra]}})
+			if code != tt.want {
+				t.Errorf("상태 코드 = %d, want %d (%v)", code, tt.want, resp)
+			}
+		})
+	}
+
+	// 목록에는 역할이 있는 인프라의 작업만 포함
+	code, resp := performJSONAs(t, viewer, h.HandleRequest, gin.H{"action": ActionListJobs, "parameters": gin.H{}})
+	if code != http.StatusOK {
+		t.Fatalf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+	jobs, _ := resp["jobs"].([]interface{})
+	if len(jobs) != 1 || jobs[0].(map[string]interface{})["id"] != jobIDs[7] {
+		t.Errorf("작업 목록 = %v", jobs)
+	}
+	if _, resp := performJSONAs(t, testAdmin, h.HandleRequest, gin.H{"action": ActionListJobs, "parameters": gin.H{}}); len(resp["jobs"].([]interface{})) != 3 {
+		t.Errorf("관리자 작업 목록 = %v", resp["jobs"])
+	}
+}
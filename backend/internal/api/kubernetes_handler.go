@@ -2,8 +2,10 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,23 +15,27 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/k8scontrol/backend/internal/command"
 	"github.com/k8scontrol/backend/internal/db"
 	"github.com/k8scontrol/backend/internal/utils"
+	"github.com/k8scontrol/backend/pkg/redact"
 	"github.com/k8scontrol/backend/pkg/ssh"
-	gossh "golang.org/x/crypto/ssh"
 )
 
 // KubernetesHandler는 쿠버네티스 관련 액션을 처리합니다
 type KubernetesHandler struct {
 	cmdManager *command.CommandManager
 	db         *sql.DB
+	repos      *db.Repositories
 }
 
 // CommandRequest는 명령어 API 요청 구조를 정의합니다
 type CommandRequest struct {
 	Action     string                 `json:"action"`
 	Parameters map[string]interface{} `json:"parameters"`
+	// DryRun이 true이면 명령어를 실행하지 않고 대상 서버와 렌더링된 명령어(비밀값은 가려짐)만 반환합니다
+	DryRun bool `json:"dry_run"`
 }
 
 // 액션 타입 상수
@@ -45,6 +51,7 @@ const (
 	ActionJoinMaster            = "joinMaster"
 	ActionJoinWorker            = "joinWorker"
 	ActionImportKubernetesInfra = "importKubernetesInfra"
+	ActionRunActionOnInfra      = "runActionOnInfra"
 
 	// 서버 관련 액션
 	ActionGetServers    = "getServers"
@@ -56,6 +63,16 @@ const (
 	ActionStartServer   = "startServer"
 	ActionStopServer    = "stopServer"
 
+	// 비동기 작업 관련 액션
+	ActionGetJob    = "getJob"
+	ActionListJobs  = "listJobs"
+	ActionCancelJob = "cancelJob"
+
+	// SSH 호스트 키 관련 액션
+	ActionListHostKeys   = "listHostKeys"
+	ActionApproveHostKey = "approveHostKey"
+	ActionRotateHostKey  = "rotateHostKey"
+
 	// 노드 관련 액션
 	ActionGetNodeStatus            = "getNodeStatus"
 	ActionRemoveNode               = "removeNode"
@@ -71,20 +88,22 @@ const (
 )
 
 // NewKubernetesHandler는 새로운 KubernetesHandler 인스턴스를 생성합니다
-func NewKubernetesHandler(db *sql.DB) *KubernetesHandler {
+func NewKubernetesHandler(database *sql.DB, repos *db.Repositories) *KubernetesHandler {
 	manager := command.NewCommandManager()
 	command.RegisterKubernetesCommands(manager)
 
 	return &KubernetesHandler{
 		cmdManager: manager,
-		db:         db,
+		db:         database,
+		repos:      repos,
 	}
 }
 
 // HandleRequest는 쿠버네티스 관련 모든 요청을 처리합니다
 func (h *KubernetesHandler) HandleRequest(c *gin.Context) {
+	// 비동기 작업이 같은 요청 본문을 다시 읽을 수 있도록 본문을 컨텍스트에 보관하며 바인딩
 	var request CommandRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if err := c.ShouldBindBodyWith(&request, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   "잘못된 요청 형식: " + err.Error(),
@@ -93,7 +112,7 @@ func (h *KubernetesHandler) HandleRequest(c *gin.Context) {
 	}
 
 	// 액션 요청 로그 기록
-	log.Printf("[API 요청] 액션: %s, 파라미터: %+v", request.Action, request.Parameters)
+	log.Printf("[API 요청] 액션: %s, 파라미터: %+v", request.Action, redact.Params(request.Parameters))
 
 	// 필수 파라미터 검증
 	if request.Action == "" {
@@ -104,6 +123,17 @@ func (h *KubernetesHandler) HandleRequest(c *gin.Context) {
 		return
 	}
 
+	// 사용자의 인프라별 역할로 액션 실행 권한 확인
+	if !authorizeAction(c, h.db, request.Action, request.Parameters) {
+		return
+	}
+
+	// 드라이런: 명령어를 실행하지 않고 실행 계획만 반환
+	if request.DryRun {
+		h.handleDryRun(c, request)
+		return
+	}
+
 	// 액션 처리
 	switch request.Action {
 	// 인프라 관련 액션
@@ -119,6 +149,8 @@ func (h *KubernetesHandler) HandleRequest(c *gin.Context) {
 		h.handleDeleteInfra(c, request)
 	case ActionImportKubernetesInfra:
 		h.handleImportKubernetesInfra(c, request)
+	case ActionRunActionOnInfra:
+		h.handleRunActionOnInfra(c, request)
 
 	// 서버 관련 액션
 	case ActionGetServers:
@@ -132,12 +164,40 @@ func (h *KubernetesHandler) HandleRequest(c *gin.Context) {
 	case ActionDeleteServer:
 		h.handleDeleteServer(c, request)
 
+	// 비동기 작업 관련 액션
+	case ActionGetJob:
+		h.handleGetJob(c, request)
+	case ActionListJobs:
+		h.handleListJobs(c, request)
+	case ActionCancelJob:
+		h.handleCancelJob(c, request)
+
+	// SSH 호스트 키 관련 액션
+	case ActionListHostKeys:
+		h.handleListHostKeys(c, request)
+	case ActionApproveHostKey:
+		h.handleApproveHostKey(c, request)
+	case ActionRotateHostKey:
+		h.handleRotateHostKey(c, request)
+
 	// 쿠버네티스 관련 액션
 	case ActionInstallLoadBalancer:
 		h.handleInstallLoadBalancer(c, request)
 	case ActionInstallFirstMaster:
+		if asyncRequested(c, request.Parameters) {
+			startHandlerJob(c, request.Action, fmt.Sprintf("server_id=%v", request.Parameters["server_id"]), func(jc *gin.Context) {
+				h.handleInstallFirstMaster(jc, request)
+			})
+			return
+		}
 		h.handleInstallFirstMaster(c, request)
 	case ActionJoinMaster:
+		if asyncRequested(c, request.Parameters) {
+			startHandlerJob(c, request.Action, fmt.Sprintf("server_id=%v", request.Parameters["server_id"]), func(jc *gin.Context) {
+				h.handleJoinMaster(jc, request)
+			})
+			return
+		}
 		h.handleJoinMaster(c, request)
 	case ActionJoinWorker:
 		h.handleJoinWorker(c, request)
@@ -179,7 +239,7 @@ func (h *KubernetesHandler) handleInstallLoadBalancer(c *gin.Context, request Co
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetInfo(serverID)
 	if err != nil {
 		log.Printf("[로드밸런서 설치 오류] 서버 정보 조회 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "서버 정보를 가져올 수 없습니다"})
@@ -210,11 +270,12 @@ func (h *KubernetesHandler) handleInstallLoadBalancer(c *gin.Context, request Co
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
@@ -258,16 +319,34 @@ func (h *KubernetesHandler) handleInstallLoadBalancer(c *gin.Context, request Co
 		return
 	}
 
-	// 2. 명령어 실행을 위한 대상 설정
+	// 2. HAProxy 설정 파일 업로드 (SFTP)
+	files, err := h.cmdManager.PrepareFiles(command.ActionInstallLoadBalancer, commandParams)
+	if err != nil {
+		log.Printf("[로드밸런서 설치 오류] 파일 준비 실패: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "설정 파일 준비 중 오류가 발생했습니다: " + err.Error(),
+		})
+		return
+	}
+
+	sshUtils := utils.NewSSHUtils()
+	if _, err := sshUtils.UploadFiles(c.Request.Context(), hops, files, 60000); err != nil {
+		log.Printf("[로드밸런서 설치 오류] 설정 파일 업로드 실패: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "HAProxy 설정 파일 업로드 중 오류가 발생했습니다: " + err.Error(),
+		})
+		return
+	}
 
 	// 3. 설치 명령어 실행 (처음 11개 명령어는 설치 명령어)
 	// 설치 시간이 길 수 있으므로 더 긴 타임아웃 사용 (5분)
-	sshUtils := utils.NewSSHUtils()
-	installResults, err := sshUtils.ExecuteCommands(hops, commands[:11], 300000) // 5분 타임아웃
+	installResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands[:11], 300000) // 5분 타임아웃
 
 	if err != nil {
 		log.Printf("[로드밸런서 설치 오류] 설치 명령어 실행 실패: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "HAProxy 설치 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -277,17 +356,17 @@ func (h *KubernetesHandler) handleInstallLoadBalancer(c *gin.Context, request Co
 	log.Printf("[로드밸런서 설치] 설치 명령어 실행 완료: %s", utils.TruncateString(installResults[len(installResults)-1].Output, 100))
 
 	// 4. 로그 확인 명령어 실행 (마지막 4개 명령어는 로그 확인 명령어)
-	logResults, err := sshUtils.ExecuteCommands(hops, commands[11:], 30000)
+	logResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands[11:], 30000)
 	if err != nil {
 		log.Printf("[로드밸런서 설치 오류] 로그 확인 명령어 실행 실패: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "설치 로그 확인 중 오류가 발생했습니다: " + err.Error(),
 		})
 		return
 	}
 
-	log.Printf("==========: %s, %s", logResults, err)
+	log.Printf("==========: %+v, %v", logResults, err)
 
 	// 로그 확인 결과 초기화
 	logExists := false
@@ -313,7 +392,7 @@ func (h *KubernetesHandler) handleInstallLoadBalancer(c *gin.Context, request Co
 
 	if installSuccess {
 		// HA 상태를 Y로 업데이트
-		err := db.UpdateServerHAStatus(h.db, serverID)
+		err := h.repos.Servers.SetHA(serverID)
 		if err != nil {
 			log.Printf("HA 상태 업데이트 중 오류 발생: %v", err)
 			c.JSON(http.StatusOK, gin.H{
@@ -367,7 +446,7 @@ func (h *KubernetesHandler) handleGetNodeStatus(c *gin.Context, request CommandR
 	}
 
 	// 2. 서버 정보 가져오기
-	serverInfo, err := db.GetServerByID(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetByID(serverID)
 	if err != nil {
 		log.Printf("[노드 상태 확인 오류] 서버 정보 조회 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -400,11 +479,12 @@ func (h *KubernetesHandler) handleGetNodeStatus(c *gin.Context, request CommandR
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
@@ -457,18 +537,18 @@ func (h *KubernetesHandler) handleGetNodeStatus(c *gin.Context, request CommandR
 		log.Printf("[노드 상태 확인] 시도 %d/10", attempt)
 		startTime := time.Now()
 
-		results, err := sshUtils.ExecuteCommands(hops, commands, 20000)
+		results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands, 20000)
 		executionTime := time.Since(startTime)
 
 		if err != nil {
 			// 오류 타입에 따라 더 상세한 로그 추가
-			if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "timed out") {
+			if errors.Is(err, ssh.ErrConnectionTimeout) {
 				log.Printf("[노드 상태 확인 오류] 시도 %d 타임아웃 발생 (소요시간: %v): %v",
 					attempt, executionTime, err)
-			} else if strings.Contains(err.Error(), "connection") || strings.Contains(err.Error(), "connect") {
+			} else if errors.Is(err, ssh.ErrConnectionRefused) || errors.Is(err, ssh.ErrHostNotFound) || errors.Is(err, ssh.ErrTunnelingFailed) {
 				log.Printf("[노드 상태 확인 오류] 시도 %d 연결 실패 (소요시간: %v): %v",
 					attempt, executionTime, err)
-			} else if strings.Contains(err.Error(), "authentication") || strings.Contains(err.Error(), "auth") {
+			} else if errors.Is(err, ssh.ErrAuthenticationFailed) {
 				log.Printf("[노드 상태 확인 오류] 시도 %d 인증 실패 (소요시간: %v): %v",
 					attempt, executionTime, err)
 			} else {
@@ -562,7 +642,7 @@ func (h *KubernetesHandler) handleGetNodeStatus(c *gin.Context, request CommandR
 	}
 
 	// 8. 마지막 확인 시간 업데이트
-	err = db.UpdateServerLastChecked(h.db, serverID, currentTime)
+	err = h.repos.Servers.UpdateLastChecked(serverID, currentTime)
 	if err != nil {
 		// 로그만 기록하고 계속 진행
 		log.Printf("[노드 상태 확인 경고] 마지막 확인 시간 업데이트 실패: %v", err)
@@ -573,7 +653,7 @@ func (h *KubernetesHandler) handleGetNodeStatus(c *gin.Context, request CommandR
 	// HA 노드가 설치되고 실행 중이면 Ha 컬럼을 'Y'로 업데이트
 	if nodeType == "ha" && installed && running {
 		// Ha 필드 업데이트 함수 호출
-		if err := db.UpdateServerHaStatus(h.db, serverID, "Y"); err != nil {
+		if err := h.repos.Servers.UpdateHAStatus(serverID, "Y"); err != nil {
 			log.Printf("[노드 상태 확인 경고] HA 상태 업데이트 실패: %v", err)
 		} else {
 			log.Printf("[노드 상태 확인] HA 노드 ID: %d의 Ha 필드가 'Y'로 업데이트되었습니다.", serverID)
@@ -615,7 +695,7 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 	}
 
 	// 2. 서버 정보 가져오기
-	serverInfo, err := db.GetServerByID(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetByID(serverID)
 	if err != nil {
 		log.Printf("[마스터 노드 설치 오류] 서버 정보 조회 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -653,11 +733,12 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
@@ -695,6 +776,7 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&lb_hops[i], hopMap)
 			}
 		}
 	}
@@ -705,12 +787,17 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 		password = pass
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	lbPassword, _ := request.Parameters["lb_password"].(string)
+	setSudoPassword(hops, password)
+	setSudoPassword(lb_hops, lbPassword)
+
 	// 마스터 노드 IP 주소 가져오기
 	sshUtils := utils.NewSSHUtils()
 	ipCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	ipResults, err := sshUtils.ExecuteCommands(hops, ipCmd, 30000)
+	ipResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, ipCmd, 30000)
 	if err != nil || len(ipResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 
@@ -724,9 +811,9 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 
 	// 로드 밸런서 IP 주소 가져오기
 	lbIpCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	lbIpResults, err := sshUtils.ExecuteCommands(lb_hops, lbIpCmd, 30000)
+	lbIpResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), lb_hops, lbIpCmd, 30000)
 	if err != nil || len(lbIpResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 
@@ -754,9 +841,9 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 		commandSets["haproxyUpdate"] = haproxyUpdateCmd
 
 		// HAProxy 설정 업데이트 실행
-		_, err = h.cmdManager.ExecuteCustomCommands(lbTarget, commandSets["haproxyUpdate"])
+		_, err = h.cmdManager.ExecuteCustomCommands(c.Request.Context(), lbTarget, commandSets["haproxyUpdate"])
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다."})
+			c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다."})
 			return
 		}
 
@@ -780,10 +867,10 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 
 	// 7. 설치 명령어 실행 (첫 5개 명령어)
 	// 설치는 백그라운드에서 실행되므로 빠르게 완료됨
-	results, err := sshUtils.ExecuteCommands(hops, commands[:5], 30000)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands[:5], 30000)
 	if err != nil {
 		log.Printf("[마스터 노드 설치 오류] 설치 명령어 실행 실패: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "쿠버네티스 마스터 노드 설치 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -888,8 +975,8 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 				log.Printf("유효한 join 명령어를 찾았습니다. DB 업데이트를 시도합니다.")
 
 				// DB에 join 명령어와 인증서 키 저장
-				log.Printf("저장할 join_command: %s, certificate_key: %s", joinCommand, certificateKey)
-				err := db.UpdateServerJoinCommand(h.db, serverID, joinCommand, certificateKey)
+				log.Printf("저장할 join_command: %s, certificate_key: %s", redact.String(joinCommand), redact.Mask)
+				err := h.repos.Servers.UpdateJoinCommand(serverID, joinCommand, certificateKey)
 				if err != nil {
 					log.Printf("join 명령어 DB 업데이트 중 오류 발생: %v", err)
 					return
@@ -1014,8 +1101,8 @@ func (h *KubernetesHandler) handleInstallFirstMaster(c *gin.Context, request Com
 					log.Printf("유효한 join 명령어를 찾았습니다. DB 업데이트를 시도합니다.")
 
 					// DB에 join 명령어와 인증서 키 저장
-					log.Printf("저장할 join_command: %s, certificate_key: %s", joinCommand, certificateKey)
-					err := db.UpdateServerJoinCommand(h.db, serverID, joinCommand, certificateKey)
+					log.Printf("저장할 join_command: %s, certificate_key: %s", redact.String(joinCommand), redact.Mask)
+					err := h.repos.Servers.UpdateJoinCommand(serverID, joinCommand, certificateKey)
 					if err != nil {
 						log.Printf("join 명령어 DB 업데이트 중 오류 발생: %v", err)
 						return
@@ -1072,7 +1159,7 @@ func (h *KubernetesHandler) handleJoinMaster(c *gin.Context, request CommandRequ
 	}
 
 	// 쿠버네티스 마스터 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetInfo(serverID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -1105,11 +1192,12 @@ func (h *KubernetesHandler) handleJoinMaster(c *gin.Context, request CommandRequ
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
@@ -1141,19 +1229,26 @@ func (h *KubernetesHandler) handleJoinMaster(c *gin.Context, request CommandRequ
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&lb_hops[i], hopMap)
 			}
 		}
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	masterPassword, _ := request.Parameters["password"].(string)
+	lbPassword, _ := request.Parameters["lb_password"].(string)
+	setSudoPassword(hops, masterPassword)
+	setSudoPassword(lb_hops, lbPassword)
+
 	// 마스터 노드 IP 주소 가져오기
 	target := &command.CommandTarget{
 		Hops: hops,
 	}
 
 	ipCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	ipResults, err := h.cmdManager.ExecuteCustomCommands(target, ipCmd)
+	ipResults, err := h.cmdManager.ExecuteCustomCommands(c.Request.Context(), target, ipCmd)
 	if err != nil || len(ipResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "마스터 노드 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 	masterIP := strings.TrimSpace(ipResults[0].Output)
@@ -1175,9 +1270,9 @@ func (h *KubernetesHandler) handleJoinMaster(c *gin.Context, request CommandRequ
 	}
 
 	lbIpCmd := []string{"ip -4 addr show | awk '/inet / && $2 ~ /^192/ {print $2}' | cut -d/ -f1 | head -n 1"}
-	lbIpResults, err := h.cmdManager.ExecuteCustomCommands(lbTarget, lbIpCmd)
+	lbIpResults, err := h.cmdManager.ExecuteCustomCommands(c.Request.Context(), lbTarget, lbIpCmd)
 	if err != nil || len(lbIpResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "로드 밸런서 IP 주소를 가져오는 중 오류가 발생했습니다."})
 		return
 	}
 	lbIP = strings.TrimSpace(lbIpResults[0].Output)
@@ -1190,7 +1285,7 @@ func (h *KubernetesHandler) handleJoinMaster(c *gin.Context, request CommandRequ
 		return
 	}
 
-	mainMasterInfo, err := db.GetServerInfo(h.db, mainID)
+	mainMasterInfo, err := h.repos.Servers.GetInfo(mainID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "메인 마스터 노드 정보를 가져오는 중 오류가 발생했습니다: " + err.Error()})
 		return
@@ -1219,78 +1314,33 @@ func (h *KubernetesHandler) handleJoinMaster(c *gin.Context, request CommandRequ
 		"lb_ip":           lbIP,
 	}
 
-	commandSets, err := command.PrepareJoinMasterCommands(commandParams)
+	steps, err := command.JoinMasterSteps(commandParams)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "명령어 준비 중 오류가 발생했습니다: " + err.Error()})
 		return
 	}
+	target.Targets = map[string][]ssh.HopConfig{command.JoinMasterLBTarget: lb_hops}
 
-	// 1. HAProxy 설정 업데이트
-	lbResults, err := h.cmdManager.ExecuteCustomCommands(lbTarget, commandSets["haproxyUpdate"])
+	// HAProxy 백엔드 등록 → 조인 시작 → 조인 완료 대기 (실패하면 실행된 단계를 역순으로 롤백)
+	// 조인 완료까지 기다리므로 오래 걸릴 수 있으며, async 파라미터로 작업으로 실행할 수 있습니다
+	reports, err := h.cmdManager.RunSteps(c.Request.Context(), target, steps, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다.", "errorDetails": err.Error()})
-		return
-	}
-
-	// 로드 밸런서 설정 결과 확인
-	if !allCommandsSuccessful(lbResults) {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "로드 밸런서 HAProxy 설정 업데이트 중 오류가 발생했습니다.", "errorDetails": lbResults[len(lbResults)-2].Error}) // 스크립트 실행 결과 오류 반환
-		return
-	}
-	log.Println("로드 밸런서 HAProxy 설정이 성공적으로 업데이트되었습니다.")
-
-	// 2. 마스터 노드 조인 스크립트 실행
-	results, err := h.cmdManager.ExecuteCustomCommands(target, commandSets["joinScript"])
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{
+			"success":      false,
+			"error":        "쿠버네티스 마스터 노드 조인 중 오류가 발생했습니다.",
+			"errorDetails": err.Error(),
+			"steps":        reports,
+		})
 		return
 	}
 
-	// 결과 처리
-	if allCommandsSuccessful(results) {
-		output := results[len(results)-1].Output // 마지막 명령어의 출력 (설치 시작 확인)
-
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "쿠버네티스 마스터 노드 조인이 백그라운드에서 시작되었습니다.",
-			"details": output,
-			"logFile": "/tmp/k8s_join.log",
-			"note":    "조인 진행 상황을 확인하려면 로그 파일을 확인하세요.",
-		})
-
-		// 백그라운드에서 조인 완료 확인
-		go func() {
-			// 조인 완료 메시지를 확인하는 명령어
-			checkJoinCompleteCmd := commandSets["checkJoin"]
-
-			// 조인 완료될 때까지 대기
-			_, err := h.cmdManager.ExecuteCustomCommands(target, checkJoinCompleteCmd)
-			if err != nil {
-				log.Printf("조인 완료 확인 중 오류 발생: %v", err)
-				return
-			}
-
-			log.Printf("마스터 노드 조인이 완료되었습니다.")
-		}()
-	} else {
-		// 실패한 명령어와 그 결과를 반환
-		failedCommands := []gin.H{}
-		for _, result := range results {
-			if result.ExitCode != 0 {
-				failedCommands = append(failedCommands, gin.H{
-					"command":  result.Command,
-					"output":   result.Output,
-					"error":    result.Error,
-					"exitCode": result.ExitCode,
-				})
-			}
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":        false,
-			"error":          "쿠버네티스 마스터 노드 조인 시작 중 오류가 발생했습니다.",
-			"failedCommands": failedCommands,
-		})
-	}
+	log.Printf("마스터 노드 조인이 완료되었습니다.")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "쿠버네티스 마스터 노드 조인이 완료되었습니다.",
+		"logFile": "/tmp/k8s_join.log",
+		"steps":   reports,
+	})
 }
 
 // handleJoinWorker 함수는 워커 노드 조인을 처리합니다
@@ -1307,7 +1357,7 @@ func (h *KubernetesHandler) handleJoinWorker(c *gin.Context, request CommandRequ
 	}
 
 	// 쿠버네티스 워커 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetInfo(serverID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -1324,7 +1374,7 @@ func (h *KubernetesHandler) handleJoinWorker(c *gin.Context, request CommandRequ
 		return
 	}
 
-	mainMasterInfo, err := db.GetServerInfo(h.db, mainID)
+	mainMasterInfo, err := h.repos.Servers.GetInfo(mainID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "메인 마스터 노드 정보를 가져오는 중 오류가 발생했습니다: " + err.Error()})
 		return
@@ -1364,11 +1414,12 @@ func (h *KubernetesHandler) handleJoinWorker(c *gin.Context, request CommandRequ
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
@@ -1377,6 +1428,10 @@ func (h *KubernetesHandler) handleJoinWorker(c *gin.Context, request CommandRequ
 		}
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	workerPassword, _ := request.Parameters["password"].(string)
+	setSudoPassword(hops, workerPassword)
+
 	// 명령 실행을 위한 target 생성
 	target := command.CommandTarget{
 		Hops: hops,
@@ -1397,9 +1452,9 @@ func (h *KubernetesHandler) handleJoinWorker(c *gin.Context, request CommandRequ
 	}
 
 	// CommandManager를 통한 명령 실행
-	results, err := h.cmdManager.ExecuteCustomCommands(&target, finalCommands)
+	results, err := h.cmdManager.ExecuteCustomCommands(c.Request.Context(), &target, finalCommands)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "SSH 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
@@ -1428,7 +1483,7 @@ func (h *KubernetesHandler) handleJoinWorker(c *gin.Context, request CommandRequ
 			}
 
 			// 조인 완료될 때까지 대기
-			_, err := h.cmdManager.ExecuteCustomCommands(&bgTarget, checkJoinCompleteCmd)
+			_, err := h.cmdManager.ExecuteCustomCommands(context.Background(), &bgTarget, checkJoinCompleteCmd)
 			if err != nil {
 				log.Printf("조인 완료 확인 중 오류 발생: %v", err)
 				return
@@ -1470,7 +1525,7 @@ func (h *KubernetesHandler) handleDeleteWorker(c *gin.Context, request CommandRe
 	}
 
 	// 쿠버네티스 워커 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetInfo(serverID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -1503,11 +1558,12 @@ func (h *KubernetesHandler) handleDeleteWorker(c *gin.Context, request CommandRe
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
@@ -1539,10 +1595,17 @@ func (h *KubernetesHandler) handleDeleteWorker(c *gin.Context, request CommandRe
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&masterHops[i], hopMap)
 			}
 		}
 	}
 
+	// 요청의 비밀번호는 sudo 비밀번호로 세션 stdin을 통해서만 전달
+	workerPassword, _ := request.Parameters["password"].(string)
+	mainPassword, _ := request.Parameters["main_password"].(string)
+	setSudoPassword(hops, workerPassword)
+	setSudoPassword(masterHops, mainPassword)
+
 	// 명령어 실행을 위한 파라미터 준비
 	params := map[string]interface{}{
 		"server_name":   serverName,
@@ -1570,15 +1633,15 @@ func (h *KubernetesHandler) handleDeleteWorker(c *gin.Context, request CommandRe
 
 	// 1. 마스터 노드에서 cordon, drain, delete 실행
 	log.Printf("마스터 노드에서 노드 %s의 cordon, drain, delete 작업 실행 중...", serverName)
-	masterResults, err := sshUtils.ExecuteCommands(masterHops, masterCommands, 300000) // 5분 타임아웃
+	masterResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), masterHops, masterCommands, 300000) // 5분 타임아웃
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "마스터 노드에서 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
+		c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "마스터 노드에서 명령어 실행 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
 
 	// 2. 워커 노드에서 쿠버네티스 관련 패키지 제거
 	log.Printf("워커 노드 %s에서 쿠버네티스 관련 패키지 제거 중...", serverName)
-	workerResults, err := sshUtils.ExecuteCommands(hops, workerCommands, 300000) // 5분 타임아웃
+	workerResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, workerCommands, 300000) // 5분 타임아웃
 
 	// 워커 노드 접속 실패는 무시 (이미 종료되었을 수 있음)
 	if err != nil {
@@ -1586,7 +1649,7 @@ func (h *KubernetesHandler) handleDeleteWorker(c *gin.Context, request CommandRe
 	}
 
 	// 3. DB에서 워커 노드 삭제
-	err = db.DeleteWorker(h.db, serverID)
+	err = h.repos.Servers.Delete(serverID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "DB에서 워커 노드 삭제 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
@@ -1628,7 +1691,7 @@ func (h *KubernetesHandler) handleDeleteMaster(c *gin.Context, request CommandRe
 	}
 
 	// 쿠버네티스 마스터 노드 서버 정보 가져오기
-	serverInfo, err := db.GetServerInfo(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetInfo(serverID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -1661,11 +1724,12 @@ func (h *KubernetesHandler) handleDeleteMaster(c *gin.Context, request CommandRe
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
@@ -1697,6 +1761,7 @@ func (h *KubernetesHandler) handleDeleteMaster(c *gin.Context, request CommandRe
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&lbHops[i], hopMap)
 			}
 		}
 	}
@@ -1724,38 +1789,36 @@ func (h *KubernetesHandler) handleDeleteMaster(c *gin.Context, request CommandRe
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&masterHops[i], hopMap)
 			}
 		}
 	}
 
-	password := request.Parameters["password"]
-	mainPassword := request.Parameters["main_password"]
+	// 요청으로 받은 비밀번호는 각 노드의 sudo 비밀번호로 사용 (명령어에는 포함하지 않음)
+	password, _ := request.Parameters["password"].(string)
+	mainPassword, _ := request.Parameters["main_password"].(string)
 	lbPassword := request.Parameters["lb_password"]
+	lbSudoPassword, _ := lbPassword.(string)
+	setSudoPassword(hops, password)
+	setSudoPassword(masterHops, mainPassword)
+	setSudoPassword(lbHops, lbSudoPassword)
 
 	// 메인 마스터 노드 여부 확인
 	isMainMaster := serverInfo.JoinCommand != "" && serverInfo.CertificateKey != ""
 	log.Printf("마스터 노드 %s는 메인 마스터 노드입니까? %v", serverName, isMainMaster)
 
 	// 서버의 infra_id 가져오기
-	var infraID int
-	err = h.db.QueryRow("SELECT infra_id FROM servers WHERE id = ?", serverID).Scan(&infraID)
+	server, err := h.repos.Servers.GetByID(serverID)
 	if err != nil {
 		log.Printf("infra_id 가져오기 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "서버의 infra_id를 가져오는 중 오류가 발생했습니다.", "errorDetails": err.Error()})
 		return
 	}
+	infraID := server.InfraID
 	log.Printf("서버의 infra_id: %d", infraID)
 
 	// 같은 infra_id에 다른 마스터 노드가 있는지 확인
-	var otherMasterCount int
-	query := `
-		SELECT COUNT(*) 
-		FROM servers 
-		WHERE infra_id = ? 
-		AND id != ? 
-		AND type LIKE '%master%'
-	`
-	err = h.db.QueryRow(query, infraID, serverID).Scan(&otherMasterCount)
+	otherMasterCount, err := h.repos.Servers.CountMasters(infraID, serverID)
 	if err != nil {
 		log.Printf("다른 마스터 노드 확인 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "다른 마스터 노드 확인 중 오류가 발생했습니다.", "errorDetails": err.Error()})
@@ -1816,13 +1879,13 @@ EOF`, serverName, serverName),
 			"chmod +x /tmp/remove_server.sh",
 
 			// sudo로 스크립트 실행
-			fmt.Sprintf("echo '%s' | sudo -S bash /tmp/remove_server.sh", lbPassword),
+			"sudo bash /tmp/remove_server.sh",
 
 			// 임시 스크립트 파일 삭제
 			"rm -f /tmp/remove_server.sh",
 		}
 
-		_, err = sshUtils.ExecuteCommands(lbHops, haproxyUpdateCmd, 60000)
+		_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), lbHops, haproxyUpdateCmd, 60000)
 		if err != nil {
 			log.Printf("로드 밸런서 HAProxy 설정 업데이트 실패: %v", err)
 			// 치명적이지 않으므로 계속 진행
@@ -1833,12 +1896,12 @@ EOF`, serverName, serverName),
 
 	// 로그 파일 설정 명령
 	logSetupCommands := []string{
-		fmt.Sprintf("echo '===== 마스터 노드 %s 삭제 작업 시작 =====' > /tmp/master_delete.log", serverName),
-		fmt.Sprintf("echo '시작 시간: %s' >> /tmp/master_delete.log", time.Now().Format(time.RFC3339)),
+		command.Render("echo %s > /tmp/master_delete.log", "===== 마스터 노드 "+serverName+" 삭제 작업 시작 ====="),
+		command.Render("echo %s >> /tmp/master_delete.log", "시작 시간: "+time.Now().Format(time.RFC3339)),
 	}
 
 	// 로그 파일 설정 실행
-	_, err = sshUtils.ExecuteCommands(hops, logSetupCommands, 10000)
+	_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, logSetupCommands, 10000)
 	if err != nil {
 		log.Printf("로그 파일 설정 실패: %v", err)
 		// 치명적이지 않으므로 계속 진행
@@ -1855,16 +1918,16 @@ EOF`, serverName, serverName),
 		// 1단계: 메인 마스터에서 노드 제거
 		mainNodeCommands := []string{
 			// 1.1. 노드 드레인 (파드 제거)
-			fmt.Sprintf("echo '%s' | sudo -S kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets", mainPassword, serverName),
+			command.Render("sudo kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets", serverName),
 
 			// 1.2. 노드 삭제
-			fmt.Sprintf("echo '%s' | sudo -S kubectl delete node %s", mainPassword, serverName),
+			command.Render("sudo kubectl delete node %s", serverName),
 		}
 
-		mainNodeResults, err := sshUtils.ExecuteCommands(masterHops, mainNodeCommands, 60000)
+		mainNodeResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), masterHops, mainNodeCommands, 60000)
 		if err != nil {
 			log.Printf("메인 마스터에서 노드 제거 실패: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "메인 마스터에서 노드 제거 실패", "errorDetails": err.Error()})
+			c.JSON(sshErrorStatus(err), gin.H{"success": false, "error": "메인 마스터에서 노드 제거 실패", "errorDetails": err.Error()})
 			return
 		}
 
@@ -1874,28 +1937,28 @@ EOF`, serverName, serverName),
 
 		// 2단계: etcd 멤버 리스트 확인 및 제거
 		etcdListCmd := []string{
-			fmt.Sprintf("echo '%s' | sudo -S ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list", mainPassword),
+			"sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list",
 		}
 
-		etcdListResult, err := sshUtils.ExecuteCommands(masterHops, etcdListCmd, 30000)
+		etcdListResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), masterHops, etcdListCmd, 30000)
 		if err == nil {
 			mainMasterCleanupOutput += etcdListResult[0].Output + "\n"
 
 			// etcd 멤버 ID 추출
 			etcdFindCmd := []string{
-				fmt.Sprintf("echo '%s' | sudo -S bash -c \"ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list | grep %s | cut -d',' -f1\"", mainPassword, serverName),
+				command.Render("sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list | grep -F -- %s | cut -d',' -f1", serverName),
 			}
 
-			etcdFindResult, err := sshUtils.ExecuteCommands(masterHops, etcdFindCmd, 30000)
+			etcdFindResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), masterHops, etcdFindCmd, 30000)
 			if err == nil {
 				etcdMemberID := strings.TrimSpace(etcdFindResult[0].Output)
 				if etcdMemberID != "" {
 					// etcd 멤버 제거
 					etcdRemoveCmd := []string{
-						fmt.Sprintf("echo '%s' | sudo -S ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member remove %s", mainPassword, etcdMemberID),
+						command.Render("sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member remove %s", etcdMemberID),
 					}
 
-					etcdRemoveResult, err := sshUtils.ExecuteCommands(masterHops, etcdRemoveCmd, 30000)
+					etcdRemoveResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), masterHops, etcdRemoveCmd, 30000)
 					if err == nil {
 						mainMasterCleanupOutput += etcdRemoveResult[0].Output + "\n"
 					}
@@ -1906,10 +1969,10 @@ EOF`, serverName, serverName),
 		// etcd 최종 확인
 		time.Sleep(5 * time.Second)
 		nodeListCmd := []string{
-			fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes", mainPassword),
+			"sudo kubectl get nodes",
 		}
 
-		nodeListResult, err := sshUtils.ExecuteCommands(masterHops, nodeListCmd, 30000)
+		nodeListResult, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), masterHops, nodeListCmd, 30000)
 		if err == nil {
 			mainMasterCleanupOutput += nodeListResult[0].Output + "\n"
 		}
@@ -1923,46 +1986,46 @@ EOF`, serverName, serverName),
 
 	// 대상 노드 서비스 중지
 	stopServicesCmd := []string{
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop kubelet || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop etcd || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 etcd || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-apiserver || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-scheduler || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-controller-manager || true", password),
+		"sudo systemctl stop kubelet || true",
+		"sudo systemctl stop etcd || true",
+		"sudo pkill -9 etcd || true",
+		"sudo pkill -9 kube-apiserver || true",
+		"sudo pkill -9 kube-scheduler || true",
+		"sudo pkill -9 kube-controller-manager || true",
 	}
 
-	_, _ = sshUtils.ExecuteCommands(hops, stopServicesCmd, 60000)
+	_, _ = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, stopServicesCmd, 60000)
 
 	// kubeadm reset 및 정리
 	cleanupCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubeadm reset -f", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/cni/net.d/*", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t nat -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t mangle -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -X", password),
-		fmt.Sprintf("echo '%s' | sudo -S ipvsadm --clear", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /root/.kube", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/admin.conf", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/kubelet.conf", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop kubelet", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop containerd", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-apiserver", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-scheduler", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-controller-manager", password),
-		fmt.Sprintf("echo '%s' | sudo -S umount -l /var/lib/kubelet/pods/* || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet/pods/*", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/etcd", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable kubelet", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /opt/cni", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubectl", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubeadm", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubelet", password),
-	}
-
-	cleanupResults, err := sshUtils.ExecuteCommands(hops, cleanupCommands, 120000)
+		"sudo kubeadm reset -f",
+		"sudo rm -rf /etc/cni/net.d/*",
+		"sudo iptables -F",
+		"sudo iptables -t nat -F",
+		"sudo iptables -t mangle -F",
+		"sudo iptables -X",
+		"sudo ipvsadm --clear",
+		"sudo rm -rf /root/.kube",
+		"sudo rm -rf /etc/kubernetes/admin.conf",
+		"sudo rm -rf /etc/kubernetes/kubelet.conf",
+		"sudo systemctl stop kubelet",
+		"sudo systemctl stop containerd",
+		"sudo pkill -9 kube-apiserver",
+		"sudo pkill -9 kube-scheduler",
+		"sudo pkill -9 kube-controller-manager",
+		"sudo umount -l /var/lib/kubelet/pods/* || true",
+		"sudo rm -rf /var/lib/kubelet/pods/*",
+		"sudo rm -rf /var/lib/kubelet",
+		"sudo rm -rf /var/lib/etcd",
+		"sudo rm -rf /etc/kubernetes",
+		"sudo systemctl disable kubelet",
+		"sudo rm -rf /opt/cni",
+		"sudo rm -rf /usr/bin/kubectl",
+		"sudo rm -rf /usr/bin/kubeadm",
+		"sudo rm -rf /usr/bin/kubelet",
+	}
+
+	cleanupResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, cleanupCommands, 120000)
 	if err == nil {
 		for _, result := range cleanupResults {
 			masterCleanupOutput += result.Output + "\n"
@@ -1971,17 +2034,17 @@ EOF`, serverName, serverName),
 
 	// 패키지 제거
 	removeCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get clean || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable containerd || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get clean || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true",
+		"sudo systemctl disable containerd || true",
 	}
 
-	_, _ = sshUtils.ExecuteCommands(hops, removeCommands, 180000)
+	_, _ = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, removeCommands, 180000)
 
 	// DB에서 마스터 노드 삭제
-	err = db.DeleteMaster(h.db, serverID)
+	err = h.repos.Servers.Delete(serverID)
 	if err != nil {
 		log.Printf("DB에서 마스터 노드 삭제 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "DB에서 마스터 노드 삭제 실패", "errorDetails": err.Error()})
@@ -2028,13 +2091,13 @@ EOF`, serverName, serverName),
 			"chmod +x /tmp/remove_server.sh",
 
 			// sudo로 스크립트 실행
-			fmt.Sprintf("echo '%s' | sudo -S bash /tmp/remove_server.sh", lbPassword),
+			"sudo bash /tmp/remove_server.sh",
 
 			// 임시 스크립트 파일 삭제
 			"rm -f /tmp/remove_server.sh",
 		}
 
-		_, err = sshUtils.ExecuteCommands(lbHops, haproxyUpdateCmd, 60000)
+		_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), lbHops, haproxyUpdateCmd, 60000)
 		if err != nil {
 			log.Printf("로드 밸런서 HAProxy 설정 업데이트 실패: %v", err)
 			// 마스터 노드 삭제는 이미 성공했으므로 경고 로그만 남기고 계속 진행
@@ -2045,11 +2108,11 @@ EOF`, serverName, serverName),
 
 	// 로그 파일에 완료 메시지 추가
 	logFinishCommands := []string{
-		fmt.Sprintf("echo '===== 마스터 노드 %s 삭제 작업 완료 =====' >> /tmp/master_delete.log", serverName),
-		fmt.Sprintf("echo '완료 시간: %s' >> /tmp/master_delete.log", time.Now().Format(time.RFC3339)),
+		command.Render("echo %s >> /tmp/master_delete.log", "===== 마스터 노드 "+serverName+" 삭제 작업 완료 ====="),
+		command.Render("echo %s >> /tmp/master_delete.log", "완료 시간: "+time.Now().Format(time.RFC3339)),
 	}
 
-	_, _ = sshUtils.ExecuteCommands(hops, logFinishCommands, 10000)
+	_, _ = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, logFinishCommands, 10000)
 
 	// 결과 반환
 	var warningMessage string
@@ -2094,6 +2157,20 @@ func installOutput(results []ssh.CommandResult) []map[string]string {
 	return output
 }
 
+// logOutputHandler는 장시간 실행되는 명령어의 진행 상황을 서버 로그로 남기는 OutputHandler를 반환합니다
+func logOutputHandler(label string) ssh.OutputHandler {
+	return func(event ssh.OutputEvent) {
+		switch event.Type {
+		case ssh.EventCommandStart:
+			log.Printf("[%s] 명령어 #%d 시작", label, event.CommandIndex+1)
+		case ssh.EventOutput:
+			log.Printf("[%s] #%d %s: %s", label, event.CommandIndex+1, event.Stream, event.Line)
+		case ssh.EventCommandExit:
+			log.Printf("[%s] 명령어 #%d 종료 (exit code: %d)", label, event.CommandIndex+1, event.ExitCode)
+		}
+	}
+}
+
 // 유틸리티 함수
 func GetFloat64(val interface{}) float64 {
 	switch v := val.(type) {
@@ -2121,10 +2198,10 @@ func (h *KubernetesHandler) handleOtherAction(c *gin.Context, request CommandReq
 	}
 
 	// 명령 실행
-	results, err := h.cmdManager.ExecuteAction(request.Action, request.Parameters, nil)
+	results, err := h.cmdManager.ExecuteAction(c.Request.Context(), request.Action, request.Parameters, nil)
 	if err != nil {
 		log.Printf("[API 오류] 액션: %s, 오류: %v", request.Action, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "명령 실행 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2143,6 +2220,168 @@ func (h *KubernetesHandler) handleOtherAction(c *gin.Context, request CommandReq
 	})
 }
 
+// handleRunActionOnInfra는 인프라에 속한 여러 서버에서 하나의 액션을 병렬로 실행합니다
+// 서버별 결과를 개별적으로 반환하며, 일부 서버가 실패해도 전체 요청은 성공으로 응답합니다
+func (h *KubernetesHandler) handleRunActionOnInfra(c *gin.Context, request CommandRequest) {
+	infraID, err := getIntParameter(request.Parameters["infra_id"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "유효한 infra_id가 필요합니다"})
+		return
+	}
+
+	targetAction, _ := request.Parameters["target_action"].(string)
+	if !h.cmdManager.HasCommandTemplate(targetAction) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "지원하지 않는 target_action입니다: " + targetAction})
+		return
+	}
+
+	// 동시 실행 수와 서버별 타임아웃 (밀리초)
+	var opts command.FanOutOptions
+	if value, ok := request.Parameters["concurrency"]; ok {
+		concurrency, err := getIntParameter(value)
+		if err != nil || concurrency <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "concurrency는 1 이상의 숫자여야 합니다"})
+			return
+		}
+		opts.Concurrency = concurrency
+	}
+	if value, ok := request.Parameters["host_timeout"]; ok {
+		hostTimeout, err := getIntParameter(value)
+		if err != nil || hostTimeout <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "host_timeout은 1 이상의 밀리초 값이어야 합니다"})
+			return
+		}
+		opts.HostTimeout = time.Duration(hostTimeout) * time.Millisecond
+	}
+
+	params, _ := request.Parameters["parameters"].(map[string]interface{})
+
+	servers, err := h.repos.Servers.GetByInfraID(infraID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "서버 목록을 가져오는데 실패했습니다: " + err.Error()})
+		return
+	}
+
+	// server_ids가 지정되면 해당 서버만 실행
+	var serverIDs map[int]bool
+	if ids, ok := request.Parameters["server_ids"].([]interface{}); ok && len(ids) > 0 {
+		serverIDs = make(map[int]bool, len(ids))
+		for _, value := range ids {
+			id, err := getIntParameter(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "유효하지 않은 server_ids 값입니다"})
+				return
+			}
+			serverIDs[id] = true
+		}
+	}
+
+	targets := make([]command.FanOutTarget, 0, len(servers))
+	for _, server := range servers {
+		if serverIDs != nil && !serverIDs[server.ID] {
+			continue
+		}
+		var hops []ssh.HopConfig
+		if err := loadServerHops(server.Hops, &hops); err != nil {
+			log.Printf("[병렬 실행] 서버 %s의 hops 파싱 실패: %v", server.ServerName, err)
+		}
+		targets = append(targets, command.FanOutTarget{
+			Name:   server.ServerName,
+			Target: &command.CommandTarget{Hops: hops},
+			Params: map[string]interface{}{
+				"server_name": server.ServerName,
+				"type":        server.Type,
+			},
+		})
+	}
+
+	if len(targets) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "실행할 서버가 없습니다"})
+		return
+	}
+
+	results := h.cmdManager.ExecuteActionFanOut(c.Request.Context(), targetAction, params, targets, opts)
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"dry_run": command.IsDryRun(c.Request.Context()),
+		"result": gin.H{
+			"action":    targetAction,
+			"total":     len(results),
+			"succeeded": len(results) - failed,
+			"failed":    failed,
+			"hosts":     results,
+		},
+	})
+}
+
+// applyHopAuthFields는 요청 파라미터의 hop 맵에서 비밀번호 외 인증 정보(개인키, 인증서)를 채웁니다
+// ssh-agent 소켓은 서버 설정으로만 지정하므로 요청의 agent_socket은 무시하며,
+// credential_id는 저장된 서버의 hops에서만 사용하므로 읽지 않습니다 (요청은 authorizeAction에서 거부)
+func applyHopAuthFields(hop *ssh.HopConfig, hopMap map[string]interface{}) {
+	hop.PrivateKey, _ = hopMap["private_key"].(string)
+	hop.Passphrase, _ = hopMap["passphrase"].(string)
+	hop.Certificate, _ = hopMap["certificate"].(string)
+	if mode, ok := hopMap["sudo_mode"].(string); ok {
+		hop.SudoMode = ssh.SudoMode(mode)
+	}
+	hop.SudoPassword, _ = hopMap["sudo_password"].(string)
+}
+
+// errRequestCredential은 요청에서 받은 hop에 credential_id가 있을 때의 오류입니다
+// 요청자가 정한 호스트로 저장된 비밀번호, 개인키가 전송되지 않도록 credential_id는 DB에 저장된 서버의 hops에서만 사용합니다
+var errRequestCredential = errors.New("요청의 hops에는 credential_id를 지정할 수 없습니다. 저장된 서버의 인증 정보는 서버 ID로 사용하세요")
+
+// hasRequestCredential은 요청 파라미터(중첩된 맵, 배열 포함)에 credential_id가 있는지 확인합니다
+func hasRequestCredential(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if key == "credential_id" || hasRequestCredential(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasRequestCredential(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadServerHops는 DB에 저장된 hops JSON을 해석하고 credential_id로 참조한 인증 정보를 채웁니다
+// 비밀번호 등은 자격 증명 저장소에 암호화되어 있으므로 hop의 비밀번호를 직접 사용하는 핸들러는 이 함수로 hops를 읽어야 합니다
+func loadServerHops(raw string, hops *[]ssh.HopConfig) error {
+	var parsed []ssh.HopConfig
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return err
+	}
+	resolved, err := ssh.ResolveHops(parsed)
+	if err != nil {
+		return err
+	}
+	*hops = resolved
+	return nil
+}
+
+// setSudoPassword는 요청에서 별도로 받은 비밀번호를 최종 hop의 sudo 비밀번호로 설정합니다
+// hop에 sudo_password가 이미 지정되어 있거나 비밀번호가 비어 있으면 변경하지 않습니다
+func setSudoPassword(hops []ssh.HopConfig, password string) {
+	if len(hops) == 0 || password == "" || hops[len(hops)-1].SudoPassword != "" {
+		return
+	}
+	hops[len(hops)-1].SudoPassword = password
+}
+
 // 유틸리티 함수들
 func getIntParameter(value interface{}) (int, error) {
 	switch v := value.(type) {
@@ -2162,7 +2401,7 @@ func getIntParameter(value interface{}) (int, error) {
 
 // 인프라 CRUD 핸들러
 func (h *KubernetesHandler) handleGetInfras(c *gin.Context) {
-	infras, err := db.GetAllInfras(h.db)
+	infras, err := h.repos.Infras.GetAll()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -2196,7 +2435,7 @@ func (h *KubernetesHandler) handleGetInfraById(c *gin.Context, request CommandRe
 		return
 	}
 
-	infra, err := db.GetInfraById(h.db, id)
+	infra, err := h.repos.Infras.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -2239,7 +2478,7 @@ func (h *KubernetesHandler) handleCreateInfra(c *gin.Context, request CommandReq
 		return
 	}
 
-	id, err := db.CreateInfra(h.db, infra)
+	id, err := h.repos.Infras.Create(infra)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -2249,7 +2488,7 @@ func (h *KubernetesHandler) handleCreateInfra(c *gin.Context, request CommandReq
 	}
 
 	// 새로 생성된 인프라 조회
-	infra, err = db.GetInfraById(h.db, id)
+	infra, err = h.repos.Infras.GetByID(id)
 	if err != nil {
 		c.JSON(http.StatusCreated, gin.H{
 			"success": true,
@@ -2285,7 +2524,7 @@ func (h *KubernetesHandler) handleUpdateInfra(c *gin.Context, request CommandReq
 	}
 
 	// 존재하는지 확인
-	existingInfra, err := db.GetInfraById(h.db, id)
+	existingInfra, err := h.repos.Infras.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -2313,7 +2552,7 @@ func (h *KubernetesHandler) handleUpdateInfra(c *gin.Context, request CommandReq
 		existingInfra.Type = typeVal
 	}
 
-	if err := db.UpdateInfra(h.db, existingInfra); err != nil {
+	if err := h.repos.Infras.Update(existingInfra); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -2322,7 +2561,7 @@ func (h *KubernetesHandler) handleUpdateInfra(c *gin.Context, request CommandReq
 	}
 
 	// 업데이트된 인프라 조회
-	updatedInfra, err := db.GetInfraById(h.db, id)
+	updatedInfra, err := h.repos.Infras.GetByID(id)
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
@@ -2357,7 +2596,7 @@ func (h *KubernetesHandler) handleDeleteInfra(c *gin.Context, request CommandReq
 		return
 	}
 
-	if err := db.DeleteInfra(h.db, id); err != nil {
+	if err := h.repos.Infras.Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -2411,6 +2650,7 @@ func (h *KubernetesHandler) handleImportKubernetesInfra(c *gin.Context, request
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -2430,18 +2670,12 @@ func (h *KubernetesHandler) handleImportKubernetesInfra(c *gin.Context, request
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
-	// 마지막 hop의 패스워드 가져오기
-	lastHopPassword := ""
-	if len(hops) > 0 {
-		lastHopPassword = hops[len(hops)-1].Password
-	}
-
-	// 단계 1: kubectl 명령어 가능한지 확인 (sudo -S 사용)
-	kubectlCheckCmd := fmt.Sprintf("echo '%s' | sudo -S which kubectl || echo 'KUBECTL_NOT_FOUND'", lastHopPassword)
-	kubectlResults, err := sshUtils.ExecuteCommands(hops, []string{kubectlCheckCmd}, 30000)
+	// 단계 1: kubectl 명령어 가능한지 확인 (sudo 사용)
+	kubectlCheckCmd := "sudo which kubectl || echo 'KUBECTL_NOT_FOUND'"
+	kubectlResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{kubectlCheckCmd}, 30000)
 
 	if err != nil || len(kubectlResults) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "SSH 연결 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2459,16 +2693,16 @@ func (h *KubernetesHandler) handleImportKubernetesInfra(c *gin.Context, request
 	// 단계 2: 클러스터 정보 수집
 	commands := []string{
 		// 클러스터 정보
-		fmt.Sprintf("echo '%s' | sudo -S kubectl cluster-info", lastHopPassword),
+		"sudo kubectl cluster-info",
 		// 노드 정보
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes -o wide", lastHopPassword),
+		"sudo kubectl get nodes -o wide",
 		// 네임스페이스 목록
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get namespaces", lastHopPassword),
+		"sudo kubectl get namespaces",
 	}
 
-	results, err := sshUtils.ExecuteCommands(hops, commands, 60000)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands, 60000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "쿠버네티스 정보 수집 중 오류가 발생했습니다: " + err.Error(),
 		})
@@ -2653,7 +2887,7 @@ func (h *KubernetesHandler) handleGetServers(c *gin.Context, request CommandRequ
 	}
 
 	// 인프라 ID로 서버 조회
-	servers, err = db.GetServersByInfraID(h.db, infraID)
+	servers, err = h.repos.Servers.GetByInfraID(infraID)
 	if err != nil {
 		log.Printf("[서버 조회 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -2690,7 +2924,7 @@ func (h *KubernetesHandler) handleGetServerById(c *gin.Context, request CommandR
 		return
 	}
 
-	server, err := db.GetServerByID(h.db, id)
+	server, err := h.repos.Servers.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[서버 조회 오류] ID %d에 해당하는 서버 없음", id)
@@ -2745,8 +2979,12 @@ func (h *KubernetesHandler) handleCreateServer(c *gin.Context, request CommandRe
 	if certKey, ok := request.Parameters["certificate_key"].(string); ok {
 		serverInput.CertificateKey = certKey
 	}
+	// 서버 이름은 노드 이름으로 kubectl 명령어에 들어가므로 형식 검증
+	if serverInput.ServerName != "" && !validateShellValues(c, shellValue{"name", command.FormatDNSSubdomain, serverInput.ServerName}) {
+		return
+	}
 
-	id, err := db.CreateServer(h.db, serverInput)
+	id, err := h.repos.Servers.Create(serverInput)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -2756,7 +2994,7 @@ func (h *KubernetesHandler) handleCreateServer(c *gin.Context, request CommandRe
 	}
 
 	// 생성된 서버 정보 조회
-	server, err := db.GetServerByID(h.db, id)
+	server, err := h.repos.Servers.GetByID(id)
 	if err != nil {
 		// 서버는 생성되었지만 조회 실패
 		c.JSON(http.StatusCreated, gin.H{
@@ -2793,7 +3031,7 @@ func (h *KubernetesHandler) handleUpdateServer(c *gin.Context, request CommandRe
 	}
 
 	// 존재하는지 확인
-	existingServer, err := db.GetServerByID(h.db, id)
+	existingServer, err := h.repos.Servers.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -2846,8 +3084,12 @@ func (h *KubernetesHandler) handleUpdateServer(c *gin.Context, request CommandRe
 	if certKey, ok := request.Parameters["certificate_key"].(string); ok {
 		serverInput.CertificateKey = certKey
 	}
+	// 서버 이름은 노드 이름으로 kubectl 명령어에 들어가므로 바꾸는 이름은 형식 검증
+	if serverInput.ServerName != existingServer.ServerName && !validateShellValues(c, shellValue{"server_name", command.FormatDNSSubdomain, serverInput.ServerName}) {
+		return
+	}
 
-	if err := db.UpdateServer(h.db, id, serverInput); err != nil {
+	if err := h.repos.Servers.Update(id, serverInput); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -2856,7 +3098,7 @@ func (h *KubernetesHandler) handleUpdateServer(c *gin.Context, request CommandRe
 	}
 
 	// 업데이트된 서버 정보 조회
-	server, err := db.GetServerByID(h.db, id)
+	server, err := h.repos.Servers.GetByID(id)
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
@@ -2891,7 +3133,7 @@ func (h *KubernetesHandler) handleDeleteServer(c *gin.Context, request CommandRe
 		return
 	}
 
-	if err := db.DeleteServer(h.db, id); err != nil {
+	if err := h.repos.Servers.Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -2905,6 +3147,189 @@ func (h *KubernetesHandler) handleDeleteServer(c *gin.Context, request CommandRe
 	})
 }
 
+// handleListHostKeys는 고정된 SSH 호스트 키 목록을 반환합니다
+// server_id가 주어지면 해당 서버의 hops에 포함된 호스트만 반환합니다
+func (h *KubernetesHandler) handleListHostKeys(c *gin.Context, request CommandRequest) {
+	keys, err := db.GetHostKeys(h.db)
+	if err != nil {
+		log.Printf("[호스트 키 조회 오류] %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if serverIDVal, exists := request.Parameters["server_id"]; exists {
+		serverID, err := getIntParameter(serverIDVal)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "유효하지 않은 server_id 형식입니다: " + err.Error(),
+			})
+			return
+		}
+
+		server, err := h.repos.Servers.GetByID(serverID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "서버를 찾을 수 없습니다",
+			})
+			return
+		}
+
+		var hops []ssh.HopConfig
+		if err := loadServerHops(server.Hops, &hops); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "hops 파싱 중 오류가 발생했습니다: " + err.Error(),
+			})
+			return
+		}
+
+		// 서버의 각 홉에 대한 키 상태 (미등록 홉은 pinned=false)
+		hopKeys := make([]gin.H, 0, len(hops))
+		for i, hop := range hops {
+			port := hop.Port
+			if port == 0 {
+				port = 22
+			}
+			via := ssh.HopRoute(hops[:i])
+			entry := gin.H{"via": via, "host": hop.Host, "port": port, "pinned": false}
+			for _, key := range keys {
+				if key.Via == via && key.Host == hop.Host && key.Port == port {
+					entry["pinned"] = true
+					entry["key"] = key
+					break
+				}
+			}
+			hopKeys = append(hopKeys, entry)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":   true,
+			"server_id": serverID,
+			"host_keys": hopKeys,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"host_keys": keys,
+	})
+}
+
+// getHostKeyTarget은 요청 파라미터에서 홉 경로(via), host, port를 추출합니다
+// via는 점프 호스트를 거쳐 접속하는 호스트의 경로이며 listHostKeys 응답의 via 값을 그대로 사용합니다
+func getHostKeyTarget(params map[string]interface{}) (string, string, int, error) {
+	via, _ := params["via"].(string)
+	host, _ := params["host"].(string)
+	if host == "" {
+		return "", "", 0, fmt.Errorf("host 파라미터가 필요합니다")
+	}
+
+	port := 22
+	if portVal, exists := params["port"]; exists {
+		p, err := getIntParameter(portVal)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("유효하지 않은 port 형식입니다: %v", err)
+		}
+		port = p
+	}
+
+	return via, host, port, nil
+}
+
+// handleApproveHostKey는 키 불일치로 거부된 새 호스트 키를 승인하여 고정 키로 교체합니다
+func (h *KubernetesHandler) handleApproveHostKey(c *gin.Context, request CommandRequest) {
+	via, host, port, err := getHostKeyTarget(request.Parameters)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := db.ApproveHostKey(h.db, via, host, port); err != nil {
+		log.Printf("[호스트 키 승인 오류] %s:%d: %v", host, port, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	key, err := db.GetHostKey(h.db, via, host, port)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	log.Printf("[호스트 키 승인] %s:%d -> %s", host, port, key.Fingerprint)
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"message":  "호스트 키가 승인되었습니다",
+		"host_key": key,
+	})
+}
+
+// handleRotateHostKey는 고정된 호스트 키를 교체합니다
+// public_key가 없으면 고정 정보를 삭제하여 다음 접속 시 새 키를 고정합니다
+func (h *KubernetesHandler) handleRotateHostKey(c *gin.Context, request CommandRequest) {
+	via, host, port, err := getHostKeyTarget(request.Parameters)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	var newKey *ssh.HostKey
+	if publicKey, _ := request.Parameters["public_key"].(string); publicKey != "" {
+		parsed, err := ssh.ParseHostKey(via, host, port, publicKey)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		newKey = &parsed
+	}
+
+	if err := db.RotateHostKey(h.db, via, host, port, newKey); err != nil {
+		log.Printf("[호스트 키 교체 오류] %s:%d: %v", host, port, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if newKey == nil {
+		log.Printf("[호스트 키 교체] %s:%d 고정 해제", host, port)
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "호스트 키 고정이 해제되었습니다. 다음 접속 시 새 키가 고정됩니다",
+		})
+		return
+	}
+
+	log.Printf("[호스트 키 교체] %s:%d -> %s", host, port, newKey.Fingerprint)
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"message":  "호스트 키가 교체되었습니다",
+		"host_key": newKey,
+	})
+}
+
 // handleGetNamespaceAndPodStatus 함수는 네임스페이스와 파드 상태 확인 요청을 처리합니다
 func (h *KubernetesHandler) handleGetNamespaceAndPodStatus(c *gin.Context, request CommandRequest) {
 	// 1. 필수 파라미터 확인 (namespace)
@@ -2939,6 +3364,7 @@ func (h *KubernetesHandler) handleGetNamespaceAndPodStatus(c *gin.Context, reque
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -2991,7 +3417,7 @@ func (h *KubernetesHandler) handleGetNamespaceAndPodStatus(c *gin.Context, reque
 
 	// 5. 네임스페이스 확인 명령어 실행
 	target := &command.CommandTarget{Hops: hops}
-	namespaceResults, err := h.cmdManager.ExecuteCustomCommands(target, nsCheckCmd)
+	namespaceResults, err := h.cmdManager.ExecuteCustomCommands(c.Request.Context(), target, nsCheckCmd)
 
 	namespaceExists := false
 	if err == nil {
@@ -3007,7 +3433,7 @@ func (h *KubernetesHandler) handleGetNamespaceAndPodStatus(c *gin.Context, reque
 	var pods []map[string]interface{}
 	if namespaceExists {
 		log.Printf("[상태 확인 정보] 네임스페이스 '%s' 존재 확인, 파드 상태 확인 시작", namespace)
-		podResults, err := h.cmdManager.ExecuteCustomCommands(target, podStatusCmd)
+		podResults, err := h.cmdManager.ExecuteCustomCommands(c.Request.Context(), target, podStatusCmd)
 
 		if err == nil {
 			for _, result := range podResults {
@@ -3078,7 +3504,7 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 	}
 
 	// 서버 정보 가져오기
-	serverInfo, err := db.GetServerByID(h.db, serverID)
+	serverInfo, err := h.repos.Servers.GetByID(serverID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
 		return
@@ -3108,11 +3534,12 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	} else {
 		// 요청에 hops가 없는 경우 DB에서 가져오기
-		if err := json.Unmarshal([]byte(serverInfo.Hops), &hops); err != nil {
+		if err := loadServerHops(serverInfo.Hops, &hops); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "hops 파싱 중 오류가 발생했습니다."})
 			return
 		}
@@ -3123,12 +3550,6 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
 	// 선택적 파라미터 추출
 	branch, _ := request.Parameters["branch"].(string)
 	if branch == "" {
@@ -3138,12 +3559,20 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 	usernameRepo, _ := request.Parameters["username_repo"].(string)
 	passwordRepo, _ := request.Parameters["password_repo"].(string)
 
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"namespace", command.FormatDNSLabel, namespace},
+		shellValue{"repo_url", command.FormatGitURL, repoURL},
+		shellValue{"branch", command.FormatGitRef, branch}) {
+		return
+	}
+
 	// 저장소 이름 추출 (URL의 마지막 부분)
 	repoURLFormatted := repoURL
-	repoURLFormatted = strings.TrimPrefix(repoURL, "https://")
-	repoURLFormatted = strings.TrimPrefix(repoURL, "http://")
-	repoURLFormatted = strings.TrimSuffix(repoURL, ".git")
-	parts := strings.Split(repoURL, "/")
+	repoURLFormatted = strings.TrimPrefix(repoURLFormatted, "https://")
+	repoURLFormatted = strings.TrimPrefix(repoURLFormatted, "http://")
+	repoURLFormatted = strings.TrimSuffix(repoURLFormatted, ".git")
+	parts := strings.Split(repoURLFormatted, "/")
 	repoName := repoURLFormatted
 	if len(parts) > 0 {
 		repoName = parts[len(parts)-1]
@@ -3160,45 +3589,27 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 
 	// 1. 작업 디렉토리 생성 및 이전 빌드 정리
 	commands = append(commands,
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S mkdir -p %s", password, workDir),
-		fmt.Sprintf("echo '%s' | sudo -S chown $(whoami):$(whoami) %s", password, workDir))
+		command.Render("sudo rm -rf %s", workDir),
+		command.Render("sudo mkdir -p %s", workDir),
+		command.Render("sudo chown $(whoami):$(whoami) %s", workDir))
 
 	// 2. 필요한 도구 설치 확인 (Git)
 	commands = append(commands,
-		fmt.Sprintf("which git || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y git)", password, password))
+		"which git || (sudo apt-get update && sudo apt-get install -y git)")
 
 	// 3. 저장소 클론 (Git 인증 오류 로깅 개선)
-	gitCmd := ""
-	if usernameRepo != "" && passwordRepo != "" {
-		// URL에서 프로토콜 부분 제거
-		repoNoProtocol := strings.TrimPrefix(repoURL, "https://")
-		repoNoProtocol = strings.TrimPrefix(repoNoProtocol, "http://")
-
-		// @ 문자가 포함된 사용자명 처리 (이메일 주소 등)
-		encodedUsername := usernameRepo
-		if strings.Contains(encodedUsername, "@") {
-			// 사용자명이 이메일인 경우 URL 인코딩 적용
-			encodedUsername = strings.ReplaceAll(encodedUsername, "@", "%40")
-		}
-
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s https://%s:%s@%s . 2>&1 || echo 'Git 클론 실패'",
-			workDir,
-			branch,
-			encodedUsername,
-			passwordRepo,
-			repoNoProtocol)
-	} else {
-		gitCmd = fmt.Sprintf("cd %s && git clone -b %s %s . 2>&1 || echo 'Git 클론 실패'",
-			workDir, branch, repoURL)
+	gitCmd, err := gitCloneCommand(workDir, repoURL, branch, usernameRepo, passwordRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
 	}
 	commands = append(commands, gitCmd)
 
 	// 4. 클론 성공 확인 및 디렉토리 내용 확인
-	commands = append(commands, fmt.Sprintf("cd %s && ls -la", workDir))
+	commands = append(commands, command.Render("cd %s && ls -la", workDir))
 
 	// 명령 실행
-	results, err := sshUtils.ExecuteCommands(hops, commands, 600000) // 10분 타임아웃
+	results, err := sshUtils.ExecuteCommandsStream(c.Request.Context(), hops, commands, 600000, logOutputHandler("쿠버네티스 배포")) // 10분 타임아웃
 	if err != nil {
 		var formattedResults []map[string]interface{}
 		for _, result := range results {
@@ -3209,7 +3620,7 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 				"exitCode": result.ExitCode,
 			})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "명령 실행 중 오류가 발생했습니다: " + err.Error(),
 			"logs":    formattedResults,
@@ -3246,8 +3657,8 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 
 	// k8s 디렉토리 확인
 	k8sDir := fmt.Sprintf("%s/k8s", workDir)
-	checkK8sDirCmd := fmt.Sprintf("ls -la %s", k8sDir)
-	k8sResults, err := sshUtils.ExecuteCommands(hops, []string{checkK8sDirCmd}, 60000)
+	checkK8sDirCmd := command.Render("ls -la %s", k8sDir)
+	k8sResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkK8sDirCmd}, 60000)
 	if err != nil {
 		var formattedResults []map[string]interface{}
 		for _, result := range results {
@@ -3258,7 +3669,7 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 				"exitCode": result.ExitCode,
 			})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "k8s 디렉토리 확인 실패: " + err.Error(),
 			"logs":    formattedResults,
@@ -3297,8 +3708,8 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 
 	// 방법 2: ls *.yaml 명령어 시도
 	if len(yamlFiles) == 0 {
-		lsYamlCmd := fmt.Sprintf("cd %s && ls *.yaml *.yml 2>/dev/null || echo ''", k8sDir)
-		lsResults, err := sshUtils.ExecuteCommands(hops, []string{lsYamlCmd}, 60000)
+		lsYamlCmd := command.Render("cd %s && ls *.yaml *.yml 2>/dev/null || echo ''", k8sDir)
+		lsResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{lsYamlCmd}, 60000)
 		if err == nil {
 			for _, result := range lsResults {
 				if result.Output != "" && !strings.Contains(result.Output, "No such file or directory") {
@@ -3316,8 +3727,8 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 
 	// 방법 3: find 명령어로 찾기
 	if len(yamlFiles) == 0 {
-		findYamlCmd := fmt.Sprintf("find %s -maxdepth 1 -name '*.yaml' -o -name '*.yml'", k8sDir)
-		findResults, err := sshUtils.ExecuteCommands(hops, []string{findYamlCmd}, 60000)
+		findYamlCmd := command.Render("find %s -maxdepth 1 -name '*.yaml' -o -name '*.yml'", k8sDir)
+		findResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{findYamlCmd}, 60000)
 		if err == nil {
 			for _, result := range findResults {
 				if strings.Contains(result.Command, "find") && result.Output != "" {
@@ -3343,8 +3754,8 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 	log.Printf("YAML 파일 목록: %v", yamlFiles)
 
 	// 직접 디렉토리 내용 확인
-	listDirCmd := fmt.Sprintf("cd %s && find . -type f | sort", k8sDir)
-	listResults, _ := sshUtils.ExecuteCommands(hops, []string{listDirCmd}, 60000)
+	listDirCmd := command.Render("cd %s && find . -type f | sort", k8sDir)
+	listResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{listDirCmd}, 60000)
 	for _, result := range listResults {
 		if strings.Contains(result.Command, "find") {
 			log.Printf("k8s 디렉토리 파일 목록 (find):\n%s", result.Output)
@@ -3362,68 +3773,48 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 
 	// 네임스페이스 확인 및 생성
 	namespaceCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get namespace %s || echo '%s' | sudo -S kubectl create namespace %s",
-			password, namespace, password, namespace),
+		command.Render("sudo kubectl get namespace %s || sudo kubectl create namespace %s",
+			namespace, namespace),
 	}
 
-	_, err = sshUtils.ExecuteCommands(hops, namespaceCommands, 60000)
+	_, err = sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, namespaceCommands, 60000)
 	if err != nil {
 		log.Printf("네임스페이스 확인/생성 실패: %v", err)
 	}
 
 	// YAML 파일 처리 - namespace 설정 제거
+	// 원본 YAML은 SFTP로 내려받아 수정한 뒤, 수정된 파일을 별도 디렉토리에 업로드합니다
 	modifiedYamlDir := fmt.Sprintf("%s/k8s_modified", workDir)
-	// 수정된 YAML을 저장할 디렉토리 생성
-	createModifiedDirCmd := fmt.Sprintf("mkdir -p %s", modifiedYamlDir)
-	_, err = sshUtils.ExecuteCommands(hops, []string{createModifiedDirCmd}, 60000)
-	if err != nil {
-		log.Printf("수정된 YAML 디렉토리 생성 실패: %v", err)
-	}
+	namespaceLine := regexp.MustCompile(`(?m)^[ \t]*namespace:.*(\r?\n|$)`)
+	secretKind := regexp.MustCompile(`(?i)kind:\s*Secret`)
+
+	// 파일 적용 순서 조정: 시크릿 파일을 먼저 적용
+	var secretFiles []string
+	var otherFiles []string
 
-	// 각 YAML 파일에서 namespace 제거
 	for _, yamlFile := range yamlFiles {
 		// YAML 파일 내용 읽기
-		readYamlCmd := fmt.Sprintf("cat %s/%s", k8sDir, yamlFile)
-		yamlResults, err := sshUtils.ExecuteCommands(hops, []string{readYamlCmd}, 60000)
+		content, err := sshUtils.ReadFile(c.Request.Context(), hops, fmt.Sprintf("%s/%s", k8sDir, yamlFile), 60000)
 		if err != nil {
 			log.Printf("YAML 파일 %s 읽기 실패: %v", yamlFile, err)
 			continue
 		}
+		log.Printf("원본 YAML 파일 %s의 크기: %d bytes", yamlFile, len(content))
 
-		// 이 부분은 로깅과 디버깅을 위한 용도로만 사용
-		for _, result := range yamlResults {
-			if strings.Contains(result.Command, "cat") {
-				log.Printf("원본 YAML 파일 %s의 크기: %d bytes", yamlFile, len(result.Output))
-				break
-			}
-		}
-
-		// YAML 파일에서 namespace 제거
+		// "namespace: xxx" 행만 제거 (띄어쓰기 다양성 고려)
+		modifiedContent := namespaceLine.ReplaceAll(content, nil)
 		modifiedYamlPath := fmt.Sprintf("%s/%s", modifiedYamlDir, yamlFile)
-
-		// sed를 사용하여 "namespace: xxx" 행만 제거 (띄어쓰기 다양성 고려)
-		removeNamespaceCmd := fmt.Sprintf("cat %s/%s | sed '/^[[:space:]]*namespace:/d' > %s",
-			k8sDir, yamlFile, modifiedYamlPath)
-		_, err = sshUtils.ExecuteCommands(hops, []string{removeNamespaceCmd}, 60000)
-		if err != nil {
+		if _, err := sshUtils.WriteFile(c.Request.Context(), hops, modifiedYamlPath, modifiedContent, 0644, 60000); err != nil {
 			log.Printf("YAML 파일 %s에서 namespace 제거 실패: %v", yamlFile, err)
 			continue
 		}
+		log.Printf("수정된 YAML 파일 %s 내용:\n%s", yamlFile, modifiedContent)
 
-		// 수정된 파일 내용 확인 (디버깅용)
-		checkModifiedCmd := fmt.Sprintf("cat %s", modifiedYamlPath)
-		checkResults, err := sshUtils.ExecuteCommands(hops, []string{checkModifiedCmd}, 60000)
-		if err != nil {
-			log.Printf("수정된 YAML 파일 %s 확인 실패: %v", yamlFile, err)
+		// 시크릿 여부 확인
+		if secretKind.Match(modifiedContent) || strings.Contains(strings.ToLower(yamlFile), "secret") {
+			secretFiles = append(secretFiles, yamlFile)
 		} else {
-			var modifiedContent string
-			for _, result := range checkResults {
-				if strings.Contains(result.Command, "cat") {
-					modifiedContent = result.Output
-					break
-				}
-			}
-			log.Printf("수정된 YAML 파일 %s 내용:\n%s", yamlFile, modifiedContent)
+			otherFiles = append(otherFiles, yamlFile)
 		}
 	}
 
@@ -3432,40 +3823,14 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 
 	// kubectl 설치 확인
 	applyCommands = append(applyCommands,
-		fmt.Sprintf("which kubectl || (echo '%s' | sudo -S apt-get update && echo '%s' | sudo -S apt-get install -y kubectl)", password, password))
-
-	// 파일 적용 순서 조정: 시크릿 파일을 먼저 적용
-	var secretFiles []string
-	var otherFiles []string
-
-	for _, yamlFile := range yamlFiles {
-		// 파일 내용을 읽어서 시크릿인지 확인
-		checkFileCmd := fmt.Sprintf("cat %s/%s | grep -i 'kind:[[:space:]]*Secret'", modifiedYamlDir, yamlFile)
-		checkResults, err := sshUtils.ExecuteCommands(hops, []string{checkFileCmd}, 60000)
-
-		isSecret := false
-		if err == nil {
-			for _, result := range checkResults {
-				if strings.Contains(result.Command, "grep") && result.Output != "" {
-					isSecret = true
-					break
-				}
-			}
-		}
-
-		if isSecret || strings.Contains(strings.ToLower(yamlFile), "secret") {
-			secretFiles = append(secretFiles, yamlFile)
-		} else {
-			otherFiles = append(otherFiles, yamlFile)
-		}
-	}
+		"which kubectl || (sudo apt-get update && sudo apt-get install -y kubectl)")
 
 	// 1단계: 시크릿 파일 먼저 적용
 	log.Printf("시크릿 파일 적용 (%d개): %v", len(secretFiles), secretFiles)
 	for _, yamlFile := range secretFiles {
 		modifiedYamlPath := fmt.Sprintf("%s/%s", modifiedYamlDir, yamlFile)
-		applyCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl apply -f %s -n %s",
-			password, modifiedYamlPath, namespace)
+		applyCmd := command.Render("sudo kubectl apply -f %s -n %s",
+			modifiedYamlPath, namespace)
 		applyCommands = append(applyCommands, applyCmd)
 	}
 
@@ -3478,13 +3843,13 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 	log.Printf("일반 파일 적용 (%d개): %v", len(otherFiles), otherFiles)
 	for _, yamlFile := range otherFiles {
 		modifiedYamlPath := fmt.Sprintf("%s/%s", modifiedYamlDir, yamlFile)
-		applyCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl apply -f %s -n %s",
-			password, modifiedYamlPath, namespace)
+		applyCmd := command.Render("sudo kubectl apply -f %s -n %s",
+			modifiedYamlPath, namespace)
 		applyCommands = append(applyCommands, applyCmd)
 	}
 
 	// 명령 실행
-	applyResults, err := sshUtils.ExecuteCommands(hops, applyCommands, 300000) // 5분 타임아웃
+	applyResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, applyCommands, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var applyOutputs []map[string]interface{}
@@ -3508,8 +3873,8 @@ func (h *KubernetesHandler) handleDeployKubernetes(c *gin.Context, request Comma
 	}
 
 	// 작업 완료 후 클론한 폴더 제거
-	cleanupCmd := fmt.Sprintf("echo '%s' | sudo -S rm -rf %s", password, workDir)
-	_, cleanupErr := sshUtils.ExecuteCommands(hops, []string{cleanupCmd}, 60000)
+	cleanupCmd := command.Render("sudo rm -rf %s", workDir)
+	_, cleanupErr := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{cleanupCmd}, 60000)
 	if cleanupErr != nil {
 		log.Printf("작업 디렉토리 정리 실패: %v", cleanupErr)
 	} else {
@@ -3538,6 +3903,9 @@ func (h *KubernetesHandler) handleDeleteNamespace(c *gin.Context, request Comman
 		})
 		return
 	}
+	if !validateShellValues(c, shellValue{"namespace", command.FormatDNSLabel, namespace}) {
+		return
+	}
 	// 2. hops 정보 파싱
 	var hops []ssh.HopConfig
 	if hopsData, ok := request.Parameters["hops"].([]interface{}); ok && len(hopsData) > 0 {
@@ -3560,6 +3928,7 @@ func (h *KubernetesHandler) handleDeleteNamespace(c *gin.Context, request Comman
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -3576,17 +3945,12 @@ func (h *KubernetesHandler) handleDeleteNamespace(c *gin.Context, request Comman
 		return
 	}
 
-	// 3. 마지막 hop의 비밀번호 추출 (sudo 실행용)
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 네임스페이스 삭제 명령
-	deleteCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl delete namespace %s", password, namespace)
-	results, err := sshUtils.ExecuteCommands(hops, []string{deleteCmd}, 300000) // 5분 타임아웃
+	deleteCmd := command.Render("sudo kubectl delete namespace %s", namespace)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{deleteCmd}, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var formattedResults []map[string]interface{}
@@ -3630,9 +3994,9 @@ func (h *KubernetesHandler) handleDeleteNamespace(c *gin.Context, request Comman
 
 		for i := 0; i < maxWaitTime; i += interval {
 			// 네임스페이스 존재 여부 확인
-			checkCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
-				password, namespace)
-			checkResults, err := sshUtils.ExecuteCommands(hops, []string{checkCmd}, 30000)
+			checkCmd := command.Render("sudo kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
+				namespace)
+			checkResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{checkCmd}, 30000)
 
 			namespaceExists := true
 			if err == nil {
@@ -3652,14 +4016,14 @@ func (h *KubernetesHandler) handleDeleteNamespace(c *gin.Context, request Comman
 			// 5초 대기 후 다시 확인
 			if i+interval < maxWaitTime {
 				sleepCmd := fmt.Sprintf("sleep %d", interval)
-				sshUtils.ExecuteCommands(hops, []string{sleepCmd}, 30000)
+				sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{sleepCmd}, 30000)
 			}
 		}
 
 		// 최종 확인
-		finalCheckCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
-			password, namespace)
-		finalResults, _ := sshUtils.ExecuteCommands(hops, []string{finalCheckCmd}, 30000)
+		finalCheckCmd := command.Render("sudo kubectl get namespace %s -o name 2>/dev/null || echo 'not found'",
+			namespace)
+		finalResults, _ := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{finalCheckCmd}, 30000)
 
 		namespaceStillExists := false
 		for _, result := range finalResults {
@@ -3686,16 +4050,25 @@ func (h *KubernetesHandler) handleDeleteNamespace(c *gin.Context, request Comman
 
 func (h *KubernetesHandler) handleRestartPod(c *gin.Context, request CommandRequest) {
 	// 필수 필드 검증
-	if request.Parameters["namespace"] == "" {
+	namespace, ok := request.Parameters["namespace"].(string)
+	if !ok || namespace == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "네임스페이스는 필수 항목입니다."})
 		return
 	}
 
-	if request.Parameters["namespace"] == "" {
+	podName, ok := request.Parameters["pod_name"].(string)
+	if !ok || podName == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "파드 이름은 필수 항목입니다."})
 		return
 	}
 
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"namespace", command.FormatDNSLabel, namespace},
+		shellValue{"pod_name", command.FormatDNSSubdomain, podName}) {
+		return
+	}
+
 	var hops []ssh.HopConfig
 	if hopsData, ok := request.Parameters["hops"].([]interface{}); ok && len(hopsData) > 0 {
 		hops = make([]ssh.HopConfig, len(hopsData))
@@ -3717,6 +4090,7 @@ func (h *KubernetesHandler) handleRestartPod(c *gin.Context, request CommandRequ
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			} else {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"success": false,
@@ -3733,23 +4107,14 @@ func (h *KubernetesHandler) handleRestartPod(c *gin.Context, request CommandRequ
 		return
 	}
 
-	// 마지막 hop의 패스워드 사용
-	password := ""
-	if len(hops) > 0 {
-		password = hops[len(hops)-1].Password
-	}
-
-	podName := request.Parameters["pod_name"]
-	namespace := request.Parameters["namespace"]
-
 	// SSH 유틸리티 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 파드 삭제 명령 실행
-	deleteCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl delete pod %s -n %s",
-		password, podName, namespace)
+	deleteCmd := command.Render("sudo kubectl delete pod %s -n %s",
+		podName, namespace)
 
-	results, err := sshUtils.ExecuteCommands(hops, []string{deleteCmd}, 300000) // 5분 타임아웃
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{deleteCmd}, 300000) // 5분 타임아웃
 
 	// 실행 결과 수집
 	var formattedResults []map[string]interface{}
@@ -3810,6 +4175,13 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 		return
 	}
 
+	// 원격 셸 명령어에 들어가는 값 검증
+	if !validateShellValues(c,
+		shellValue{"namespace", command.FormatDNSLabel, namespace},
+		shellValue{"pod_name", command.FormatDNSSubdomain, podName}) {
+		return
+	}
+
 	// 요청 본문에서 hops가 제공되었는지 확인하고, 그렇지 않으면 DB에서 가져옴
 	var hops []ssh.HopConfig
 	if hopsData, ok := request.Parameters["hops"].([]interface{}); ok && len(hopsData) > 0 {
@@ -3834,6 +4206,7 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	}
@@ -3864,12 +4237,12 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 	}
 	defer client.Close()
 
-	// 마지막 Hop의 패스워드 가져오기
-	password := hops[0].Password
+	// NOPASSWD 호스트에서는 sudo 비밀번호를 stdin으로 보내지 않도록 sudo 방식을 먼저 확인
+	sudoHop := client.ProbeSudo(hops[len(hops)-1])
 
 	// 1. 파드 존재 여부 확인
-	podCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get pod %s -n %s -o name || echo 'not found'",
-		password, podName, namespace)
+	podCmd := command.Render("sudo kubectl get pod %s -n %s -o name || echo 'not found'",
+		podName, namespace)
 
 	podSession, err := client.NewSession()
 	if err != nil {
@@ -3883,7 +4256,7 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 	var podOutput bytes.Buffer
 	podSession.Stdout = &podOutput
 
-	if err := podSession.Run(podCmd); err != nil {
+	if err := podSession.Run(ssh.SudoCommand(podSession, sudoHop, podCmd)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("파드 확인 명령 실행 실패: %v", err),
@@ -3907,8 +4280,8 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 	tmpFileName := fmt.Sprintf("/tmp/pod_logs_%s_%s_%d.txt", namespace, podName, time.Now().Unix())
 
 	// 로그를 임시 파일로 저장 - 오류 출력 포함하여 전체 정보 보존
-	logCmd := fmt.Sprintf("echo '%s' | sudo -S /usr/bin/kubectl logs --tail=%d %s -n %s > %s 2>/tmp/kubectl_err_%d.log",
-		password, linesInt, podName, namespace, tmpFileName, time.Now().Unix())
+	logCmd := command.Render("sudo /usr/bin/kubectl logs --tail=%d %s -n %s > %s 2>/tmp/kubectl_err_%d.log",
+		linesInt, podName, namespace, tmpFileName, time.Now().Unix())
 
 	logSession, err := client.NewSession()
 	if err != nil {
@@ -3922,7 +4295,7 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 	var stderr bytes.Buffer
 	logSession.Stderr = &stderr
 
-	if err := logSession.Run(logCmd); err != nil {
+	if err := logSession.Run(ssh.SudoCommand(logSession, sudoHop, logCmd)); err != nil {
 		errMsg := stderr.String()
 
 		// 에러 로그 파일 확인 시도
@@ -3943,7 +4316,7 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 	logSession.Close()
 
 	// 3. 임시 파일에서 로그 내용 읽기
-	catCmd := fmt.Sprintf("cat %s", tmpFileName)
+	catCmd := command.Render("cat %s", tmpFileName)
 	catSession, err := client.NewSession()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -3967,7 +4340,7 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 	catSession.Close()
 
 	// 4. 임시 파일 삭제
-	cleanupCmd := fmt.Sprintf("rm -f %s /tmp/kubectl_err_*.log", tmpFileName)
+	cleanupCmd := command.Render("rm -f %s /tmp/kubectl_err_*.log", tmpFileName)
 	cleanupSession, err := client.NewSession()
 	if err != nil {
 		// 파일 삭제 실패해도 계속 진행
@@ -3989,24 +4362,13 @@ func (h *KubernetesHandler) handleGetPodLogs(c *gin.Context, request CommandRequ
 }
 
 // SSH 클라이언트 생성을 위한 헬퍼 함수
-func (h *KubernetesHandler) createSSHClient(hops []ssh.HopConfig) (*gossh.Client, error) {
+// 공유 연결 풀에서 hops 체인 연결을 가져오며, Close하면 풀에 반납됩니다
+func (h *KubernetesHandler) createSSHClient(hops []ssh.HopConfig) (*ssh.Client, error) {
 	if len(hops) == 0 {
 		return nil, fmt.Errorf("SSH 연결 정보가 없습니다")
 	}
 
-	hop := hops[0]
-
-	sshConfig := &gossh.ClientConfig{
-		User: hop.Username,
-		Auth: []gossh.AuthMethod{
-			gossh.Password(hop.Password),
-		},
-		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
-		Timeout:         time.Duration(60) * time.Second,
-	}
-
-	hostPort := fmt.Sprintf("%s:%d", hop.Host, hop.Port)
-	client, err := gossh.Dial("tcp", hostPort, sshConfig)
+	client, err := ssh.NewSSHService().Acquire(hops, time.Duration(60)*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("SSH 연결 실패: %v", err)
 	}
@@ -4016,12 +4378,6 @@ func (h *KubernetesHandler) createSSHClient(hops []ssh.HopConfig) (*gossh.Client
 
 // collectResources 시스템 리소스 정보를 수집합니다
 func collectResource(hops []ssh.HopConfig) (map[string]string, error) {
-	// 마지막 hop의 비밀번호 추출
-	lastHopPassword := ""
-	if len(hops) > 0 {
-		lastHopPassword = hops[len(hops)-1].Password
-	}
-
 	// 리소스 정보 수집 명령어
 	commands := []string{
 		// CPU 정보
@@ -4036,13 +4392,13 @@ func collectResource(hops []ssh.HopConfig) (map[string]string, error) {
 		"free | grep Mem | awk '{printf \"%.2f\", $3/$2 * 100}'",
 
 		// 디스크 정보 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $2}'", lastHopPassword),
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $3}'", lastHopPassword),
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $4}'", lastHopPassword),
-		fmt.Sprintf("echo '%s' | sudo -S df -h / | tail -1 | awk '{print $5}'", lastHopPassword),
+		"sudo df -h / | tail -1 | awk '{print $2}'",
+		"sudo df -h / | tail -1 | awk '{print $3}'",
+		"sudo df -h / | tail -1 | awk '{print $4}'",
+		"sudo df -h / | tail -1 | awk '{print $5}'",
 
 		// 네트워크 정보 (sudo 사용)
-		fmt.Sprintf("echo '%s' | sudo -S ip -4 addr show | grep inet | awk '{print $NF, $2}' | grep -v '127.0.0.1'", lastHopPassword),
+		"sudo ip -4 addr show | grep inet | awk '{print $NF, $2}' | grep -v '127.0.0.1'",
 
 		// OS 정보
 		"hostname",
@@ -4116,6 +4472,7 @@ func (h *KubernetesHandler) handleCalculateResources(c *gin.Context, request Com
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	}
@@ -4201,28 +4558,23 @@ func (h *KubernetesHandler) handleCalculateNodes(c *gin.Context, request Command
 					Username: username,
 					Password: password,
 				}
+				applyHopAuthFields(&hops[i], hopMap)
 			}
 		}
 	}
 
-	// 마지막 hop의 비밀번호 추출
-	lastHopPassword := ""
-	if len(hops) > 0 {
-		lastHopPassword = hops[len(hops)-1].Password
-	}
-
 	// SSH 유틸리티 인스턴스 생성
 	sshUtils := utils.NewSSHUtils()
 
 	// 쿠버네티스 노드 정보 수집 명령어 (sudo 권한 필요)
 	commands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes -o wide", lastHopPassword),
+		"sudo kubectl get nodes -o wide",
 	}
 
 	// 명령어 실행 (60초 타임아웃)
-	results, err := sshUtils.ExecuteCommands(hops, commands, 60000)
+	results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, commands, 60000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sshErrorStatus(err), gin.H{
 			"success": false,
 			"error":   "쿠버네티스 노드 정보 수집 중 오류가 발생했습니다.",
 			"detail":  err.Error(),
@@ -4269,8 +4621,8 @@ func (h *KubernetesHandler) handleCalculateNodes(c *gin.Context, request Command
 
 		// 역할 확인 (master 또는 worker)
 		// 노드 라벨을 확인하는 추가 명령 실행
-		roleCmd := fmt.Sprintf("echo '%s' | sudo -S kubectl get node %s --show-labels", lastHopPassword, nodeName)
-		roleResults, err := sshUtils.ExecuteCommands(hops, []string{roleCmd}, 30000)
+		roleCmd := command.Render("sudo kubectl get node %s --show-labels", nodeName)
+		roleResults, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), hops, []string{roleCmd}, 30000)
 
 		if err == nil && len(roleResults) > 0 && roleResults[0].ExitCode == 0 {
 			roleOutput := roleResults[0].Output
@@ -4314,3 +4666,14 @@ func (h *KubernetesHandler) handleCalculateNodes(c *gin.Context, request Command
 		},
 	})
 }
+
+// handleDryRun은 액션의 실행 계획을 반환합니다
+// runActionOnInfra는 드라이런 컨텍스트로 실행하여 서버별로 실행될 명령어를 반환합니다
+func (h *KubernetesHandler) handleDryRun(c *gin.Context, request CommandRequest) {
+	if request.Action == ActionRunActionOnInfra {
+		c.Request = c.Request.WithContext(command.WithDryRun(c.Request.Context()))
+		h.handleRunActionOnInfra(c, request)
+		return
+	}
+	respondActionPlan(c, h.repos.Servers, h.cmdManager, request.Action, request.Parameters)
+}
@@ -0,0 +1,395 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+// viewerActions는 viewer 역할로 호출할 수 있는 조회 액션입니다
+var viewerActions = map[string]bool{
+	ActionGetNamespaceAndPodStatus: true,
+	ActionGetPodLogs:               true,
+	ActionGetNodeStatus:            true,
+	ActionGetInfras:                true,
+	ActionGetInfraById:             true,
+	ActionGetServers:               true,
+	ActionGetServerById:            true,
+	ActionGetJob:                   true,
+	ActionListJobs:                 true,
+	ActionListHostKeys:             true,
+	ActionCalculateResources:       true,
+	ActionCalculateNodes:           true,
+	ActionGetDockerServer:          true,
+	ActionCheckServerStatus:        true,
+	ActionGetDockerInfo:            true,
+	ActionGetContainers:            true,
+	ActionGetDockerLogs:            true,
+	ActionGetImages:                true,
+	ActionGetServiceStatus:         true,
+	ActionGetDockerFiles:           true,
+	"getServices":                  true,
+	"getServiceById":               true,
+	"getContainerStatus":           true,
+	"getServerStatus":              true,
+}
+
+// adminActions는 admin 역할이 필요한 파괴적 액션입니다. 나머지 액션은 operator 역할이 필요합니다
+var adminActions = map[string]bool{
+	ActionDeleteInfra:           true,
+	ActionDeleteMaster:          true,
+	ActionhandleUninstallDocker: true,
+	ActionApproveHostKey:        true,
+	ActionRotateHostKey:         true,
+}
+
+// infraIDActions는 id 파라미터가 인프라 ID인 액션입니다
+var infraIDActions = map[string]bool{
+	ActionGetInfraById: true,
+	ActionUpdateInfra:  true,
+	ActionDeleteInfra:  true,
+}
+
+// serverIDActions는 id 파라미터가 서버 ID인 액션입니다
+var serverIDActions = map[string]bool{
+	ActionGetServerById:    true,
+	ActionUpdateServer:     true,
+	ActionDeleteServer:     true,
+	ActionDeployKubernetes: true,
+}
+
+// serviceIDActions는 id 파라미터가 서비스 ID인 액션입니다
+var serviceIDActions = map[string]bool{
+	"getServiceById":        true,
+	"updateService":         true,
+	"deleteService":         true,
+	ActionGetServiceStatus:  true,
+	ActionDeployService:     true,
+	ActionRestartService:    true,
+	ActionStopService:       true,
+	ActionRemoveService:     true,
+	ActionGetDockerFiles:    true,
+	ActionSaveDockerfile:    true,
+	ActionSaveDockerCompose: true,
+}
+
+// jobIDActions는 job_id 파라미터로 작업을 지정하는 액션입니다. 작업을 실행한 인프라를 범위로 사용합니다
+var jobIDActions = map[string]bool{
+	ActionGetJob:    true,
+	ActionCancelJob: true,
+}
+
+// roleLevels는 역할의 권한 크기입니다
+var roleLevels = map[string]int{db.RoleViewer: 1, db.RoleOperator: 2, db.RoleAdmin: 3}
+
+// requiredRole은 액션을 실행하는 데 필요한 최소 역할을 반환합니다
+func requiredRole(action string) string {
+	switch {
+	case adminActions[action]:
+		return db.RoleAdmin
+	case viewerActions[action]:
+		return db.RoleViewer
+	default:
+		return db.RoleOperator
+	}
+}
+
+// accessScope는 액션이 대상으로 하는 인프라와 서비스, 서버입니다. 대상이 없으면 0입니다
+// 권한 확인에는 InfraID와 ServiceID만 사용하며, ServerID는 감사 기록에 남기기 위한 값입니다
+type accessScope struct {
+	InfraID   int
+	ServiceID int
+	ServerID  int
+}
+
+// errScopeMismatch는 요청의 infra_id가 대상 서버, 서비스가 속한 인프라와 다를 때 반환됩니다
+var errScopeMismatch = errors.New("infra_id가 대상 서버 또는 서비스의 인프라와 일치하지 않습니다")
+
+// resolveActionScope는 액션 파라미터에서 대상 인프라와 서비스를 찾습니다
+// 서버, 서비스 ID로 지정한 경우 요청의 infra_id가 아니라 DB에 저장된 소속 인프라를 범위로 사용하며,
+// 요청의 infra_id가 그 인프라와 다르면 errScopeMismatch를 반환합니다
+// 존재하지 않는 대상은 무시합니다 (핸들러가 404로 응답)
+func resolveActionScope(database *sql.DB, action string, params map[string]interface{}) (accessScope, error) {
+	var scope accessScope
+	requestedInfraID, _ := getIntParameter(params["infra_id"])
+	scope.InfraID = requestedInfraID
+	if id, err := getIntParameter(params["id"]); err == nil {
+		switch {
+		case infraIDActions[action]:
+			if requestedInfraID != 0 && requestedInfraID != id {
+				return scope, errScopeMismatch
+			}
+			scope.InfraID = id
+		case serviceIDActions[action]:
+			scope.ServiceID = id
+		}
+	}
+	serverID, err := getIntParameter(params["server_id"])
+	if err != nil && serverIDActions[action] {
+		serverID, err = getIntParameter(params["id"])
+	}
+	if err != nil {
+		serverID, err = getIntParameter(params["main_id"])
+	}
+	if err == nil {
+		scope.ServerID = serverID
+	}
+	// 작업은 작업을 실행한 인프라를 범위로 사용 (인프라가 없는 작업은 전역 범위)
+	if jobID, _ := params["job_id"].(string); jobID != "" && jobIDActions[action] {
+		if job, err := command.DefaultJobManager().Get(jobID); err == nil {
+			if requestedInfraID != 0 && requestedInfraID != job.InfraID {
+				return scope, errScopeMismatch
+			}
+			scope.InfraID = job.InfraID
+		}
+	}
+	if database == nil {
+		return scope, nil
+	}
+
+	// 대상 서비스, 서버가 속한 인프라를 범위로 사용 (요청의 infra_id로 다른 인프라의 대상을 지정할 수 없음)
+	owners := map[int]bool{}
+	if scope.ServiceID != 0 {
+		if infraID, err := db.GetServiceInfraID(database, scope.ServiceID); err == nil {
+			owners[infraID] = true
+			scope.InfraID = infraID
+		}
+	}
+	if scope.ServerID != 0 {
+		if server, err := db.GetServerByID(database, scope.ServerID); err == nil {
+			owners[server.InfraID] = true
+			scope.InfraID = server.InfraID
+		}
+	}
+	if len(owners) > 1 || (requestedInfraID != 0 && len(owners) == 1 && !owners[requestedInfraID]) {
+		return scope, errScopeMismatch
+	}
+	return scope, nil
+}
+
+// effectiveRole은 scope에 적용되는 가장 큰 역할을 반환합니다
+// 인프라 역할은 그 인프라의 서버와 서비스에도 적용됩니다. anyScope가 true이면 범위와 관계없이 모든 역할을 고려합니다
+func effectiveRole(bindings []db.RoleBinding, scope accessScope, anyScope bool) string {
+	best := ""
+	for _, b := range bindings {
+		applies := anyScope || b.ScopeType == db.ScopeGlobal ||
+			(b.ScopeType == db.ScopeInfra && scope.InfraID != 0 && b.ScopeID == scope.InfraID) ||
+			(b.ScopeType == db.ScopeService && scope.ServiceID != 0 && b.ScopeID == scope.ServiceID)
+		if applies && roleLevels[b.Role] > roleLevels[best] {
+			best = b.Role
+		}
+	}
+	return best
+}
+
+// userRoleBindings는 사용자의 역할 목록을 반환합니다. 컨텍스트에 미리 담긴 역할이 있으면 그대로 사용합니다
+func userRoleBindings(database *sql.DB, user AuthUser) ([]db.RoleBinding, error) {
+	if user.Roles != nil || database == nil {
+		return user.Roles, nil
+	}
+	return db.GetUserRoleBindings(database, user.ID)
+}
+
+// authorizeAction은 요청한 사용자가 action을 실행할 수 있는지 확인하고, 권한이 없으면 401/403 응답을 보냅니다
+// 대상 인프라, 서비스가 없는 조회 액션(목록 조회 등)은 어느 범위든 viewer 이상의 역할이 있으면 허용하고,
+// 대상이 없는 그 외 액션은 전역 역할이 필요합니다
+func authorizeAction(c *gin.Context, database *sql.DB, action string, params map[string]interface{}) bool {
+	user, ok := CurrentUser(c)
+	if !ok {
+		abortUnauthorized(c, "인증이 필요합니다")
+		return false
+	}
+	if hasRequestCredential(params) {
+		log.Printf("[권한 거부] 사용자: %s, 액션: %s, 요청 hops의 credential_id", user.Username, action)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"success": false, "error": errRequestCredential.Error()})
+		return false
+	}
+	bindings, err := userRoleBindings(database, user)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"success": false, "error": "권한 조회 실패: " + err.Error()})
+		return false
+	}
+
+	required := requiredRole(action)
+	// 다른 액션을 대신 실행하는 액션은 대상 액션의 권한도 필요
+	if action == ActionRunActionOnInfra {
+		if target, _ := params["target_action"].(string); roleLevels[requiredRole(target)] > roleLevels[required] {
+			required = requiredRole(target)
+		}
+	}
+
+	scope, err := resolveActionScope(database, action, params)
+	if err != nil {
+		log.Printf("[권한 거부] 사용자: %s, 액션: %s, %v", user.Username, action, err)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"success": false, "error": err.Error()})
+		return false
+	}
+	// API 토큰은 토큰에 허용된 액션, 인프라만 호출 가능
+	if user.Token != nil && !tokenAllows(user.Token, action, params, scope) {
+		log.Printf("[권한 거부] 사용자: %s, API 토큰: %d, 액션: %s, 인프라: %d", user.Username, user.Token.ID, action, scope.InfraID)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("API 토큰으로 %s 액션을 실행할 수 없습니다", action),
+		})
+		return false
+	}
+	// 인프라가 없는 작업은 목록 조회와 달리 전역 역할이 있어야 조회 가능
+	unscoped := scope.InfraID == 0 && scope.ServiceID == 0 && !jobIDActions[action]
+	role := effectiveRole(bindings, scope, unscoped && required == db.RoleViewer)
+	if roleLevels[role] >= roleLevels[required] {
+		// 비동기 작업으로 실행하면 작업에 대상 인프라를 기록
+		c.Set(actionScopeKey, scope)
+		return true
+	}
+
+	log.Printf("[권한 거부] 사용자: %s, 액션: %s, 필요 역할: %s, 인프라: %d, 서비스: %d", user.Username, action, required, scope.InfraID, scope.ServiceID)
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"success": false,
+		"error":   fmt.Sprintf("%s 액션을 실행할 권한이 없습니다 (필요 역할: %s)", action, required),
+	})
+	return false
+}
+
+// tokenAllows는 API 토큰으로 액션을 실행할 수 있는지 확인합니다. 다른 액션을 대신 실행하는 액션은 대상 액션도 허용되어야 합니다
+func tokenAllows(token *TokenScope, action string, params map[string]interface{}, scope accessScope) bool {
+	if !token.allows(action, scope) {
+		return false
+	}
+	if action == ActionRunActionOnInfra {
+		target, _ := params["target_action"].(string)
+		return token.allows(target, scope)
+	}
+	return true
+}
+
+// actionScopeKey는 권한 검사를 통과한 액션의 대상 범위를 gin 컨텍스트에 저장하는 키입니다
+const actionScopeKey = "k8scontrol.action_scope"
+
+// currentActionScope는 권한 검사에서 찾은 액션의 대상 범위를 반환합니다
+func currentActionScope(c *gin.Context) accessScope {
+	scope, _ := c.Get(actionScopeKey)
+	s, _ := scope.(accessScope)
+	return s
+}
+
+// RequireAction은 액션 이름별 경로(/infra/deleteMaster 등)에 권한 검사를 적용하는 미들웨어입니다
+// action이 빈 문자열이면 경로의 마지막 부분을 액션 이름으로 사용하며, 대상 범위는 JSON 요청 본문의 파라미터와
+// 경로 파라미터(/jobs/:job_id/events의 job_id 등)에서 찾습니다
+func RequireAction(database *sql.DB, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := action
+		if name == "" {
+			name = path.Base(c.FullPath())
+		}
+		params := requestParams(c)
+		for _, p := range c.Params {
+			if params == nil {
+				params = make(map[string]interface{})
+			}
+			if _, exists := params[p.Key]; !exists {
+				params[p.Key] = p.Value
+			}
+		}
+		if !authorizeAction(c, database, name, params) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestParams는 JSON 요청 본문을 파라미터 맵으로 읽습니다. 본문은 핸들러가 다시 읽을 수 있도록 되돌려 놓습니다
+func requestParams(c *gin.Context) map[string]interface{} {
+	if c.Request.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(body, &params); err != nil {
+		return nil
+	}
+	return params
+}
+
+// RequireAdmin은 전역 admin 역할이 있는 사용자만 허용하는 미들웨어입니다 (사용자, 권한, 자격 증명 관리 등)
+func RequireAdmin(database *sql.DB) gin.HandlerFunc {
+	return requireGlobalRole(database, db.RoleAdmin)
+}
+
+// jobInfraFilter는 사용자가 작업 목록에서 볼 수 있는 인프라를 반환합니다
+// 전역 역할이 있고 인프라를 제한하지 않은 API 토큰이면 nil(전체)을 반환하며, 그 외에는 viewer 이상의 역할이 있는 인프라만 반환합니다
+func jobInfraFilter(database *sql.DB, user AuthUser) ([]int, error) {
+	bindings, err := userRoleBindings(database, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed []int
+	if roleLevels[effectiveRole(bindings, accessScope{}, false)] >= roleLevels[db.RoleViewer] {
+		if user.Token == nil || len(user.Token.InfraIDs) == 0 {
+			return nil, nil
+		}
+		allowed = append(allowed, user.Token.InfraIDs...)
+	} else {
+		allowed = []int{}
+		for _, b := range bindings {
+			if b.ScopeType == db.ScopeInfra && roleLevels[b.Role] >= roleLevels[db.RoleViewer] {
+				allowed = append(allowed, b.ScopeID)
+			}
+		}
+	}
+
+	// 인프라를 제한한 API 토큰은 토큰의 인프라만 조회
+	if user.Token != nil && len(user.Token.InfraIDs) > 0 {
+		filtered := []int{}
+		for _, id := range allowed {
+			for _, tokenInfra := range user.Token.InfraIDs {
+				if id == tokenInfra {
+					filtered = append(filtered, id)
+					break
+				}
+			}
+		}
+		allowed = filtered
+	}
+	return allowed, nil
+}
+
+// requireGlobalRole은 전역 범위에서 role 이상의 역할이 있는 사용자만 허용하는 미들웨어입니다
+func requireGlobalRole(database *sql.DB, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			abortUnauthorized(c, "인증이 필요합니다")
+			return
+		}
+		bindings, err := userRoleBindings(database, user)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"success": false, "error": "권한 조회 실패: " + err.Error()})
+			return
+		}
+		// 관리 작업은 API 토큰으로 할 수 없음
+		if user.Token != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"success": false, "error": "API 토큰으로는 호출할 수 없습니다"})
+			return
+		}
+		if roleLevels[effectiveRole(bindings, accessScope{}, false)] < roleLevels[role] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"success": false, "error": fmt.Sprintf("전역 %s 역할이 필요합니다", role)})
+			return
+		}
+		c.Next()
+	}
+}
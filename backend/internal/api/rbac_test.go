@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+)
+
+func TestRequiredRole(t *testing.T) {
+	tests := map[string]string{
+		ActionGetNamespaceAndPodStatus: db.RoleViewer,
+		ActionGetPodLogs:               db.RoleViewer,
+		ActionDeleteMaster:             db.RoleAdmin,
+		"uninstallDocker":              db.RoleAdmin,
+		ActionDeleteInfra:              db.RoleAdmin,
+		ActionInstallFirstMaster:       db.RoleOperator,
+		ActionDeployService:            db.RoleOperator,
+		"unknownAction":                db.RoleOperator,
+	}
+	for action, want := range tests {
+		if got := requiredRole(action); got != want {
+			t.Errorf("requiredRole(%s) = %s, want %s", action, got, want)
+		}
+	}
+}
+
+func TestAuthorizeAction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	infraViewer := AuthUser{ID: 2, Username: "viewer", Roles: []db.RoleBinding{{Role: db.RoleViewer, ScopeType: db.ScopeInfra, ScopeID: 7}}}
+	infraOperator := AuthUser{ID: 3, Username: "operator", Roles: []db.RoleBinding{{Role: db.RoleOperator, ScopeType: db.ScopeInfra, ScopeID: 7}}}
+	serviceAdmin := AuthUser{ID: 4, Username: "svc", Roles: []db.RoleBinding{{Role: db.RoleAdmin, ScopeType: db.ScopeService, ScopeID: 9}}}
+	// 전역 admin 사용자의 API 토큰이라도 토큰에 허용된 액션, 인프라만 호출 가능
+	ciToken := testAdmin
+	ciToken.Token = &TokenScope{ID: 1, Actions: []string{ActionDeployService, ActionRunActionOnInfra, ActionGetPodLogs}, InfraIDs: []int{7}}
+	anyInfraToken := testAdmin
+	anyInfraToken.Token = &TokenScope{ID: 2, Actions: []string{ActionGetInfras}}
+
+	tests := []struct {
+		name   string
+		user   AuthUser
+		action string
+		params map[string]interface{}
+		want   int
+	}{
+		{"viewer 조회", infraViewer, ActionGetPodLogs, map[string]interface{}{"infra_id": 7.0}, http.StatusOK},
+		{"viewer 다른 인프라", infraViewer, ActionGetPodLogs, map[string]interface{}{"infra_id": 8.0}, http.StatusForbidden},
+		{"viewer 배포 불가", infraViewer, ActionDeployKubernetes, map[string]interface{}{"infra_id": 7.0}, http.StatusForbidden},
+		{"viewer 목록 조회", infraViewer, ActionGetInfras, nil, http.StatusOK},
+		{"operator 설치", infraOperator, ActionInstallFirstMaster, map[string]interface{}{"infra_id": 7.0}, http.StatusOK},
+		{"operator 인프라 삭제 불가", infraOperator, ActionDeleteInfra, map[string]interface{}{"id": 7.0}, http.StatusForbidden},
+		{"operator 전역 액션 불가", infraOperator, ActionCreateInfra, nil, http.StatusForbidden},
+		{"대상 액션 권한 확인", infraOperator, ActionRunActionOnInfra, map[string]interface{}{"infra_id": 7.0, "target_action": ActionDeleteMaster}, http.StatusForbidden},
+		{"서비스 범위", serviceAdmin, ActionRemoveService, map[string]interface{}{"id": 9.0}, http.StatusOK},
+		{"서비스 범위 밖", serviceAdmin, ActionRemoveService, map[string]interface{}{"id": 10.0}, http.StatusForbidden},
+		{"관리자", testAdmin, ActionDeleteMaster, map[string]interface{}{"server_id": 1.0}, http.StatusOK},
+		{"토큰 허용 액션", ciToken, ActionGetPodLogs, map[string]interface{}{"infra_id": 7.0}, http.StatusOK},
+		{"토큰 허용 밖 액션", ciToken, ActionDeleteMaster, map[string]interface{}{"infra_id": 7.0}, http.StatusForbidden},
+		{"토큰 허용 밖 인프라", ciToken, ActionGetPodLogs, map[string]interface{}{"infra_id": 8.0}, http.StatusForbidden},
+		{"토큰 인프라 제한 시 목록 불가", ciToken, ActionGetInfras, nil, http.StatusForbidden},
+		{"토큰 대상 액션 확인", ciToken, ActionRunActionOnInfra, map[string]interface{}{"infra_id": 7.0, "target_action": ActionDeleteMaster}, http.StatusForbidden},
+		{"토큰 대상 액션 허용", ciToken, ActionRunActionOnInfra, map[string]interface{}{"infra_id": 7.0, "target_action": ActionGetPodLogs}, http.StatusOK},
+		{"인프라 제한 없는 토큰", anyInfraToken, ActionGetInfras, nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set(authUserKey, tt.user)
+
+			ok := authorizeAction(c, nil, tt.action, tt.params)
+			if got := map[bool]int{true: http.StatusOK, false: w.Code}[ok]; got != tt.want {
+				t.Errorf("status = %d, want %d (%s)", got, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	// 인증되지 않은 요청
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if authorizeAction(c, nil, ActionGetPodLogs, nil) || w.Code != http.StatusUnauthorized {
+		t.Errorf("인증 없음: status = %d, want 401", w.Code)
+	}
+}
+
+func TestAuthorizeActionScopeFromTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	repos := db.NewRepositories(database)
+	infraA, _ := repos.Infras.Create(db.Infra{Name: "a", Type: "kubernetes"})
+	infraB, _ := repos.Infras.Create(db.Infra{Name: "b", Type: "kubernetes"})
+	masterA, err := repos.Servers.Create(db.ServerInput{ServerName: "master-a", Hops: `[{"host":"10.0.0.1","port":22,"username":"u"}]`, Type: "master", InfraID: infraA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterB, err := repos.Servers.Create(db.ServerInput{ServerName: "master-b", Hops: `[{"host":"10.0.1.1","port":22,"username":"u"}]`, Type: "master", InfraID: infraB})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 인프라 A의 admin은 infra_id를 A로 보내더라도 인프라 B의 서버를 대상으로 할 수 없음
+	adminA := AuthUser{ID: 5, Username: "admin-a", Roles: []db.RoleBinding{{Role: db.RoleAdmin, ScopeType: db.ScopeInfra, ScopeID: infraA}}}
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   int
+	}{
+		{"자기 인프라의 서버", map[string]interface{}{"infra_id": float64(infraA), "server_id": float64(masterA)}, http.StatusOK},
+		{"infra_id 없이 자기 인프라의 서버", map[string]interface{}{"server_id": float64(masterA)}, http.StatusOK},
+		{"infra_id와 서버의 인프라 불일치", map[string]interface{}{"infra_id": float64(infraA), "server_id": float64(masterB)}, http.StatusForbidden},
+		{"다른 인프라의 서버", map[string]interface{}{"server_id": float64(masterB)}, http.StatusForbidden},
+		{"main_id 불일치", map[string]interface{}{"infra_id": float64(infraA), "main_id": float64(masterB)}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set(authUserKey, adminA)
+
+			ok := authorizeAction(c, database, ActionDeleteMaster, tt.params)
+			if got := map[bool]int{true: http.StatusOK, false: w.Code}[ok]; got != tt.want {
+				t.Errorf("status = %d, want %d (%s)", got, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/pkg/redact"
+)
+
+// RedactSecrets는 요청 본문의 비밀값이 로그와 응답으로 나가지 않도록 하는 미들웨어입니다
+// JSON 요청 본문에서 비밀값 키(password, token 등)의 값을 모아 요청이 끝날 때까지 로그에서 가리고,
+// JSON, 텍스트 응답의 문자열에서도 같은 값과 비밀값 형태(kubeadm 토큰, URL 비밀번호 등)를 가립니다
+func RedactSecrets() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secrets := requestSecrets(c)
+		defer redact.Track(secrets...)()
+
+		c.Writer = &redactWriter{ResponseWriter: c.Writer, masker: redact.NewMasker(secrets...)}
+		c.Next()
+	}
+}
+
+// requestSecrets는 JSON 요청 본문에서 비밀값을 모읍니다. 본문은 핸들러가 다시 읽을 수 있도록 되돌려 놓습니다
+func requestSecrets(c *gin.Context) []string {
+	if c.Request.Body == nil || !strings.Contains(c.ContentType(), "json") {
+		return nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return bodySecrets(body)
+}
+
+// bodySecrets는 JSON 본문에서 비밀값 키의 값을 모읍니다
+func bodySecrets(body []byte) []string {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+	return redact.Secrets(doc)
+}
+
+// redactWriter는 응답 본문의 비밀값을 가리는 gin.ResponseWriter입니다
+// JSON은 문자열 값만 가려 문서 구조를 유지하고, 파일 등 텍스트가 아닌 응답은 그대로 전달합니다
+type redactWriter struct {
+	gin.ResponseWriter
+	masker *redact.Masker
+}
+
+func (w *redactWriter) Write(data []byte) (int, error) {
+	contentType := w.Header().Get("Content-Type")
+	masked := data
+	switch {
+	case strings.Contains(contentType, "json"):
+		masked = w.masker.JSON(data)
+	case strings.HasPrefix(contentType, "text/"):
+		masked = []byte(w.masker.String(string(data)))
+	}
+	if _, err := w.ResponseWriter.Write(masked); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *redactWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRedactSecretsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RedactSecrets())
+
+	var received string
+	router.POST("/run", func(c *gin.Context) {
+		var req struct {
+			Hops []struct {
+				Password string `json:"password"`
+			} `json:"hops"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || len(req.Hops) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "잘못된 요청"})
+			return
+		}
+		received = req.Hops[0].Password
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"results": []gin.H{{"command": "echo " + received, "exitCode": 0}},
+		})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(`{"hops":[{"host":"10.0.0.1","password":"hop-secret"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if received != "hop-secret" {
+		t.Fatalf("핸들러는 원래 요청 본문을 읽어야 합니다: %q (%d %s)", received, w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "hop-secret") || !strings.Contains(w.Body.String(), `"command":"echo ****"`) {
+		t.Errorf("응답의 비밀값이 가려지지 않았습니다: %s", w.Body.String())
+	}
+}
@@ -2,22 +2,32 @@ package api
 
 import (
 	"database/sql"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/auth"
+	"github.com/k8scontrol/backend/internal/config"
+	"github.com/k8scontrol/backend/internal/db"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func InfraRoutes(router *gin.Engine, db *sql.DB) {
-	// API 버전 그룹
-	v1 := router.Group("/api/v1")
+// AllowedOrigins는 API와 WebSocket 연결을 허용하는 프론트엔드 Origin 목록입니다
+var AllowedOrigins = config.Default().Server.CORSOrigins
+
+// Features는 기능 플래그입니다. 서버 시작 시 설정 파일과 환경 변수의 값으로 바꿉니다
+var Features = config.Default().Features
+
+func InfraRoutes(router *gin.Engine, database *sql.DB, repos *db.Repositories) {
+	// API 버전 그룹 (경로 이름(/infra/deleteMaster 등)을 액션 이름으로 하여 감사 기록, 권한 확인)
+	v1 := router.Group("/api/v1", Audit(database), RequireAction(database, ""))
 
 	// 이제 인프라 핸들러는 사용하지 않음
-	// infraHandler := NewInfraHandler(db)
+	// infraHandler := NewInfraHandler(database, repos)
 	// v1.POST("/infra/deleteWorker", infraHandler.DeleteWorker)
 
 	// 쿠버네티스 핸들러 초기화 (새로운 API 구조)
-	infraHandler := NewInfraHandler(db)
+	infraHandler := NewInfraHandler(database, repos)
 
 	v1.POST("/infra/installLoadBalancer", infraHandler.InstallLoadBalancer)     // 0
 	v1.POST("/infra/installFirstMaster", infraHandler.InstallFirstMaster)       // 0
@@ -30,16 +40,16 @@ func InfraRoutes(router *gin.Engine, db *sql.DB) {
 	v1.POST("/infra/calculateResources", infraHandler.CalculateResources)
 	v1.POST("/infra/calculateNodes", infraHandler.CalculateNodes)
 }
-func InfraDockerRoutes(router *gin.Engine, db *sql.DB) {
-	// API 버전 그룹
-	v1 := router.Group("/api/v1")
+func InfraDockerRoutes(router *gin.Engine, database *sql.DB, repos *db.Repositories) {
+	// API 버전 그룹 (경로 이름(/infra/deleteMaster 등)을 액션 이름으로 하여 감사 기록, 권한 확인)
+	v1 := router.Group("/api/v1", Audit(database), RequireAction(database, ""))
 
 	// 이제 인프라 핸들러는 사용하지 않음
-	// infraHandler := NewInfraHandler(db)
+	// infraHandler := NewInfraHandler(database, repos)
 	// v1.POST("/infra/deleteWorker", infraHandler.DeleteWorker)
 
 	// 쿠버네티스 핸들러 초기화 (새로운 API 구조)
-	infrDockerHandler := NewInfraDockerHandler(db)
+	infrDockerHandler := NewInfraDockerHandler(database, repos)
 
 	v1.POST("/docker/installDocker", infrDockerHandler.InstallDocker)                       // 0
 	v1.POST("/docker/createDockerContainer", infrDockerHandler.CreateDockerContainer)       // 0
@@ -50,16 +60,16 @@ func InfraDockerRoutes(router *gin.Engine, db *sql.DB) {
 	v1.POST("/docker/deleteOneDockerContainer", infrDockerHandler.DeleteOneDockerContainer) // 0
 	v1.POST("/docker/controlDockerContainer", infrDockerHandler.ControlDockerContainer)     // 0
 }
-func InfraKubernetesRoutes(router *gin.Engine, db *sql.DB) {
-	// API 버전 그룹
-	v1 := router.Group("/api/v1")
+func InfraKubernetesRoutes(router *gin.Engine, database *sql.DB, repos *db.Repositories) {
+	// API 버전 그룹 (경로 이름(/infra/deleteMaster 등)을 액션 이름으로 하여 감사 기록, 권한 확인)
+	v1 := router.Group("/api/v1", Audit(database), RequireAction(database, ""))
 
 	// 이제 인프라 핸들러는 사용하지 않음
-	// infraHandler := NewInfraHandler(db)
+	// infraHandler := NewInfraHandler(database, repos)
 	// v1.POST("/infra/deleteWorker", infraHandler.DeleteWorker)
 
 	// 쿠버네티스 핸들러 초기화 (새로운 API 구조)
-	infrKubernetesHandler := NewInfraKubernetesHandler(db)
+	infrKubernetesHandler := NewInfraKubernetesHandler(database, repos)
 
 	v1.POST("/kubernetes/deployKubernetes", infrKubernetesHandler.DeployKubernetes)                 // 0
 	v1.POST("/kubernetes/deleteNamespace", infrKubernetesHandler.DeleteNamespace)                   // 0
@@ -67,48 +77,132 @@ func InfraKubernetesRoutes(router *gin.Engine, db *sql.DB) {
 	v1.POST("/kubernetes/getPodLogs", infrKubernetesHandler.GetPodLogs)                             //0
 	v1.POST("/kubernetes/restartPod", infrKubernetesHandler.RestartPod)
 }
-func ServerRoutes(router *gin.Engine, db *sql.DB) {
+func ServerRoutes(router *gin.Engine, database *sql.DB, repos *db.Repositories) {
 	// API 버전 그룹
 	v1 := router.Group("/api/v1")
 
 	// 이제 인프라 핸들러는 사용하지 않음
-	// infraHandler := NewInfraHandler(db)
+	// infraHandler := NewInfraHandler(database, repos)
 	// v1.POST("/infra/deleteWorker", infraHandler.DeleteWorker)
 
 	// 쿠버네티스 핸들러 초기화 (새로운 API 구조)
-	serverHandler := NewServerHandler(db)
+	serverHandler := NewServerHandler(database, repos)
+
+	v1.POST("/server/status", RequireAction(database, "getServerStatus"), serverHandler.GetServerStatus) // 0
+}
+
+// AuthRoutes는 로그인, 토큰 갱신, API 토큰, 사용자 관리 경로를 등록합니다
+func AuthRoutes(router *gin.Engine, db *sql.DB, tokens *auth.Manager) {
+	v1 := router.Group("/api/v1")
+
+	authHandler := NewAuthHandler(db, tokens)
+
+	v1.POST("/auth/login", authHandler.Login)
+	v1.POST("/auth/refresh", authHandler.Refresh)
+	v1.GET("/auth/me", authHandler.Me)
+
+	// 로그아웃, 비밀번호 변경, API 토큰 관리는 로그인 세션으로만 가능
+	session := v1.Group("", RequireSession())
+	session.POST("/auth/logout", authHandler.Logout)
+	session.PUT("/auth/password", authHandler.ChangePassword)
+
+	apiTokenHandler := NewAPITokenHandler(db)
+	session.GET("/tokens", apiTokenHandler.GetAPITokens)
+	session.POST("/tokens", apiTokenHandler.CreateAPIToken)
+	session.DELETE("/tokens/:id", apiTokenHandler.RevokeAPIToken)
+
+	// 사용자, 역할 관리는 전역 관리자만 가능
+	admin := v1.Group("", RequireAdmin(db))
+	admin.GET("/users", authHandler.GetUsers)
+	admin.POST("/users", authHandler.CreateUser)
+	admin.DELETE("/users/:id", authHandler.DeleteUser)
+	admin.GET("/roles", authHandler.GetRoles)
+	admin.POST("/roles", authHandler.GrantRole)
+	admin.DELETE("/roles/:id", authHandler.RevokeRole)
+}
+
+// CredentialRoutes는 암호화된 자격 증명 관리 경로를 등록합니다 (전역 관리자만 가능)
+func CredentialRoutes(router *gin.Engine, db *sql.DB) {
+	v1 := router.Group("/api/v1", RequireAdmin(db))
+
+	credentialHandler := NewCredentialHandler(db)
+
+	v1.GET("/credentials", credentialHandler.GetCredentials)
+	v1.POST("/credentials", credentialHandler.CreateCredential)
+	v1.PUT("/credentials/:id", credentialHandler.UpdateCredential)
+	v1.DELETE("/credentials/:id", credentialHandler.DeleteCredential)
+	v1.POST("/credentials/rotate", credentialHandler.RotateCredentialKeys)
+}
+
+// AuditRoutes는 감사 기록 조회, 내보내기 경로를 등록합니다 (전역 관리자만 가능)
+func AuditRoutes(router *gin.Engine, db *sql.DB) {
+	v1 := router.Group("/api/v1", RequireAdmin(db))
+
+	auditHandler := NewAuditHandler(db)
+
+	v1.GET("/audit", auditHandler.GetAuditLog)
+	v1.GET("/audit/export", auditHandler.ExportAuditLog)
+}
+
+// ConfigRoutes는 비밀값을 가린 현재 설정 조회 경로를 등록합니다 (관리자 전용)
+func ConfigRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
+	v1 := router.Group("/api/v1", RequireAdmin(db))
+
+	v1.GET("/config", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"success": true, "config": cfg.Redacted()})
+	})
+}
+
+// JobRoutes는 비동기 작업 진행 상황 스트리밍 경로를 등록합니다
+func JobRoutes(router *gin.Engine, db *sql.DB) {
+	v1 := router.Group("/api/v1")
+
+	jobHandler := NewJobHandler(db)
+
+	// SSE (기본) 또는 WebSocket 업그레이드로 작업 이벤트 구독 (getJob과 같은 권한으로 작업의 인프라를 확인)
+	v1.GET("/jobs/:job_id/events", RequireAction(db, ActionGetJob), jobHandler.StreamJobEvents)
+}
+
+// ActionRoutes는 액션 목록과 파라미터 스키마를 조회하는 경로를 등록합니다
+func ActionRoutes(router *gin.Engine, db *sql.DB) {
+	v1 := router.Group("/api/v1")
+
+	actionHandler := NewActionHandler(db)
 
-	v1.POST("/server/status", serverHandler.GetServerStatus) // 0
+	v1.GET("/actions", actionHandler.ListActions)
+	v1.GET("/actions/:category/:name", actionHandler.GetAction)
 }
 
-func SetupRoutes(router *gin.Engine, db *sql.DB) {
+func SetupRoutes(router *gin.Engine, database *sql.DB, repos *db.Repositories) {
 	// API 버전 그룹
 	v1 := router.Group("/api/v1")
 
 	// 이제 인프라 핸들러는 사용하지 않음
-	// infraHandler := NewInfraHandler(db)
+	// infraHandler := NewInfraHandler(database, repos)
 	// v1.POST("/infra/deleteWorker", infraHandler.DeleteWorker)
 
 	// 쿠버네티스 핸들러 초기화 (새로운 API 구조)
-	kubernetesHandler := NewKubernetesHandler(db)
+	kubernetesHandler := NewKubernetesHandler(database, repos)
 
 	// 쿠버네티스 엔드포인트 (단일 엔드포인트로 모든 액션 처리)
-	v1.POST("/kubernetes", kubernetesHandler.HandleRequest)
+	v1.POST("/kubernetes", AuditCommands(database), kubernetesHandler.HandleRequest)
 
 	// 도커 핸들러 초기화 (새로운 API 구조)
-	dockerHandler := NewDockerHandler(db)
+	dockerHandler := NewDockerHandler(database, repos)
 
 	// 도커 엔드포인트 (단일 엔드포인트로 모든 액션 처리)
-	v1.POST("/docker", dockerHandler.HandleRequest)
+	v1.POST("/docker", AuditCommands(database), dockerHandler.HandleRequest)
 
 	// 서비스 핸들러 초기화 (새로운 API 구조)
-	serviceHandler := NewServiceHandler(db)
+	serviceHandler := NewServiceHandler(database, repos)
 
 	// 서비스 엔드포인트 (단일 엔드포인트로 모든 액션 처리)
-	v1.POST("/service", serviceHandler.HandleRequest)
+	v1.POST("/service", AuditCommands(database), serviceHandler.HandleRequest)
 
 	// Swagger 문서 설정
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if Features.Swagger {
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
 }
 
 // RegisterInfraDockerRoutes는 인프라 Docker 관련 경로를 등록합니다.
@@ -2,6 +2,7 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
 	"github.com/k8scontrol/backend/internal/db"
 	"github.com/k8scontrol/backend/internal/utils"
 	"github.com/k8scontrol/backend/pkg/ssh"
@@ -16,12 +18,13 @@ import (
 
 // ServerHandler 서버 관련 API 핸들러
 type ServerHandler struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Repos *db.Repositories
 }
 
 // NewServerHandler 새 ServerHandler 생성
-func NewServerHandler(db *sql.DB) *ServerHandler {
-	return &ServerHandler{DB: db}
+func NewServerHandler(database *sql.DB, repos *db.Repositories) *ServerHandler {
+	return &ServerHandler{DB: database, Repos: repos}
 }
 
 // GetServers 모든 서버 조회
@@ -38,7 +41,7 @@ func (h *ServerHandler) GetServers(c *gin.Context) {
 			return
 		}
 
-		servers, err := db.GetServersByInfraID(h.DB, infraID)
+		servers, err := h.Repos.Servers.GetByInfraID(infraID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -50,7 +53,7 @@ func (h *ServerHandler) GetServers(c *gin.Context) {
 
 	// 서버 타입 필터링이 있는 경우
 	if typeParam != "" {
-		servers, err := db.GetServersByType(h.DB, typeParam)
+		servers, err := h.Repos.Servers.GetByType(typeParam)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -61,7 +64,7 @@ func (h *ServerHandler) GetServers(c *gin.Context) {
 	}
 
 	// 모든 서버 조회
-	servers, err := db.GetAllServers(h.DB)
+	servers, err := h.Repos.Servers.GetAll()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -81,7 +84,7 @@ func (h *ServerHandler) GetServerById(c *gin.Context) {
 		return
 	}
 
-	server, err := db.GetServerByID(h.DB, id)
+	server, err := h.Repos.Servers.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "서버를 찾을 수 없습니다"})
@@ -101,15 +104,19 @@ func (h *ServerHandler) CreateServer(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// 서버 이름은 노드 이름으로 kubectl 명령어에 들어가므로 형식 검증
+	if input.ServerName != "" && !validateShellValues(c, shellValue{"server_name", command.FormatDNSSubdomain, input.ServerName}) {
+		return
+	}
 
-	id, err := db.CreateServer(h.DB, input)
+	id, err := h.Repos.Servers.Create(input)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 생성된 서버 정보 조회
-	server, err := db.GetServerByID(h.DB, id)
+	server, err := h.Repos.Servers.GetByID(id)
 	if err != nil {
 		// 서버는 생성되었지만 조회 실패
 		c.JSON(http.StatusCreated, gin.H{
@@ -132,7 +139,7 @@ func (h *ServerHandler) UpdateServer(c *gin.Context) {
 	}
 
 	// 해당 ID의 서버가 존재하는지 확인
-	_, err = db.GetServerByID(h.DB, id)
+	_, err = h.Repos.Servers.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[서버 업데이트 오류] ID %d에 해당하는 서버 없음", id)
@@ -150,15 +157,18 @@ func (h *ServerHandler) UpdateServer(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if input.ServerName != "" && !validateShellValues(c, shellValue{"server_name", command.FormatDNSSubdomain, input.ServerName}) {
+		return
+	}
 
-	if err := db.UpdateServer(h.DB, id, input); err != nil {
+	if err := h.Repos.Servers.Update(id, input); err != nil {
 		log.Printf("[서버 업데이트 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 업데이트된 서버 정보 조회
-	server, err := db.GetServerByID(h.DB, id)
+	server, err := h.Repos.Servers.GetByID(id)
 	if err != nil {
 		log.Printf("[서버 업데이트 경고] 서버 업데이트 성공했으나 조회 실패: %v", err)
 		c.JSON(http.StatusOK, gin.H{
@@ -181,7 +191,7 @@ func (h *ServerHandler) DeleteServer(c *gin.Context) {
 	}
 
 	// 해당 ID의 서버가 존재하는지 확인
-	server, err := db.GetServerByID(h.DB, id)
+	server, err := h.Repos.Servers.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[서버 삭제 오류] ID %d에 해당하는 서버 없음", id)
@@ -196,7 +206,7 @@ func (h *ServerHandler) DeleteServer(c *gin.Context) {
 	log.Printf("[서버 삭제] ID %d 서버 삭제 중 - 이름: %s, 타입: %s",
 		id, server.ServerName, server.Type)
 
-	if err := db.DeleteServer(h.DB, id); err != nil {
+	if err := h.Repos.Servers.Delete(id); err != nil {
 		log.Printf("[서버 삭제 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -227,6 +237,13 @@ func (h *ServerHandler) GetServerStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "SSH 연결 정보(hops)가 필요합니다."})
 		return
 	}
+	for _, hop := range requestBody.Hops {
+		if hop.CredentialID != 0 {
+			log.Printf("[서버 상태 확인 오류] 요청 hops의 credential_id: %d", hop.CredentialID)
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": errRequestCredential.Error()})
+			return
+		}
+	}
 
 	log.Printf("[서버 상태 확인] 서버 ID: %d, 타입: %s, Hop 수: %d",
 		requestBody.ID, requestBody.Type, len(requestBody.Hops))
@@ -238,7 +255,7 @@ func (h *ServerHandler) GetServerStatus(c *gin.Context) {
 
 	// 서버 ID가 제공된 경우 서버 정보 조회
 	if requestBody.ID > 0 {
-		server, err := db.GetServerByID(h.DB, requestBody.ID)
+		server, err := h.Repos.Servers.GetByID(requestBody.ID)
 		if err != nil {
 			log.Printf("[서버 상태 확인 경고] 서버 정보 조회 실패: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "서버 정보를 조회할 수 없습니다."})
@@ -265,7 +282,7 @@ func (h *ServerHandler) GetServerStatus(c *gin.Context) {
 	serverType := strings.ToLower(requestBody.Type)
 
 	// 서버 정보 조회
-	server, err := db.GetServerByID(h.DB, requestBody.ID)
+	server, err := h.Repos.Servers.GetByID(requestBody.ID)
 	if err != nil {
 		log.Printf("[서버 상태 확인 오류] 서버 정보 조회 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "서버 정보를 조회할 수 없습니다."})
@@ -280,31 +297,33 @@ func (h *ServerHandler) GetServerStatus(c *gin.Context) {
 			"echo '===END==='"
 		log.Printf("[서버 상태 확인] HA 노드 상태 확인 명령어 준비 완료")
 	case "master":
-		cmd = "echo '===START==='; " +
-			"if command -v kubectl >/dev/null 2>&1 && command -v kubelet >/dev/null 2>&1; then echo 'INSTALLED=true'; else echo 'INSTALLED=false'; fi; " +
-			"if systemctl status kubelet 2>/dev/null | grep -q 'Active: active (running)'; then echo 'KUBELET_RUNNING=true'; else echo 'KUBELET_RUNNING=false'; fi; " +
-			"echo \"hostname=" + server.ServerName + "\"; " +
-			"if echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S kubectl get nodes --no-headers 2>/dev/null | grep -E \"" + server.ServerName + ".*control-plane|" + server.ServerName + ".*master\"; then echo 'IS_MASTER=true'; else echo 'IS_MASTER=false'; fi; " +
-			"if echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S kubectl get nodes --no-headers 2>/dev/null | grep \"" + server.ServerName + "\" | grep -vE \"control-plane|master\"; then echo 'IS_WORKER=true'; else echo 'IS_WORKER=false'; fi; " +
-			"if echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S kubectl get nodes --no-headers 2>/dev/null | grep -q \"" + server.ServerName + "\"; then echo 'NODE_REGISTERED=true'; else echo 'NODE_REGISTERED=false'; fi; " +
-			"echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S kubectl get nodes -o wide 2>/dev/null | grep \"" + server.ServerName + "\" || echo 'NODE_STATUS=NotFound'; " +
-			"echo '===END==='"
+		// 서버 이름은 사용자가 지정한 값이므로 따옴표로 감싸고 정규식이 아닌 고정 문자열로 찾음
+		name := server.ServerName
+		cmd = command.Render("echo '===START==='; "+
+			"if command -v kubectl >/dev/null 2>&1 && command -v kubelet >/dev/null 2>&1; then echo 'INSTALLED=true'; else echo 'INSTALLED=false'; fi; "+
+			"if systemctl status kubelet 2>/dev/null | grep -q 'Active: active (running)'; then echo 'KUBELET_RUNNING=true'; else echo 'KUBELET_RUNNING=false'; fi; "+
+			"echo hostname=%s; "+
+			"if sudo kubectl get nodes --no-headers 2>/dev/null | grep -F -- %s | grep -E 'control-plane|master'; then echo 'IS_MASTER=true'; else echo 'IS_MASTER=false'; fi; "+
+			"if sudo kubectl get nodes --no-headers 2>/dev/null | grep -F -- %s | grep -vE 'control-plane|master'; then echo 'IS_WORKER=true'; else echo 'IS_WORKER=false'; fi; "+
+			"if sudo kubectl get nodes --no-headers 2>/dev/null | grep -qF -- %s; then echo 'NODE_REGISTERED=true'; else echo 'NODE_REGISTERED=false'; fi; "+
+			"sudo kubectl get nodes -o wide 2>/dev/null | grep -F -- %s || echo 'NODE_STATUS=NotFound'; "+
+			"echo '===END==='", name, name, name, name, name)
 		log.Printf("[서버 상태 확인] 마스터 노드 상태 확인 명령어 준비 완료")
 	case "worker":
-		cmd = "echo '===START==='; " +
-			"if command -v kubectl >/dev/null 2>&1 && command -v kubelet >/dev/null 2>&1; then echo 'INSTALLED=true'; else echo 'INSTALLED=false'; fi; " +
-			"if systemctl status kubelet 2>/dev/null | grep -q 'Active: active (running)'; then echo 'KUBELET_RUNNING=true'; else echo 'KUBELET_RUNNING=false'; fi; " +
-			"echo \"hostname=" + server.ServerName + "\"; " +
-			"echo '===END==='"
+		cmd = command.Render("echo '===START==='; "+
+			"if command -v kubectl >/dev/null 2>&1 && command -v kubelet >/dev/null 2>&1; then echo 'INSTALLED=true'; else echo 'INSTALLED=false'; fi; "+
+			"if systemctl status kubelet 2>/dev/null | grep -q 'Active: active (running)'; then echo 'KUBELET_RUNNING=true'; else echo 'KUBELET_RUNNING=false'; fi; "+
+			"echo hostname=%s; "+
+			"echo '===END==='", server.ServerName)
 		log.Printf("[서버 상태 확인] 워커 노드 상태 확인 명령어 준비 완료")
 	case "docker":
 		cmd = "echo '===START==='; " +
 			"if command -v docker >/dev/null; then echo 'DOCKER_INSTALLED=true'; else echo 'DOCKER_INSTALLED=false'; fi; " +
 			"if systemctl status docker | grep -q 'Active: active (running)'; then echo 'DOCKER_RUNNING=true'; else echo 'DOCKER_RUNNING=false'; fi; " +
-			"echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S docker info | grep 'Server Version' || echo 'DOCKER_VERSION=NotFound'; " +
-			"echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S docker ps --format '{{.Names}}' || echo 'DOCKER_CONTAINERS=NotFound'; " +
-			"echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S docker system df || echo 'DOCKER_DISK_USAGE=NotFound'; " +
-			"echo '" + requestBody.Hops[len(requestBody.Hops)-1].Password + "' | sudo -S docker network ls --format '{{.Name}}' || echo 'DOCKER_NETWORKS=NotFound'; " +
+			"sudo docker info | grep 'Server Version' || echo 'DOCKER_VERSION=NotFound'; " +
+			"sudo docker ps --format '{{.Names}}' || echo 'DOCKER_CONTAINERS=NotFound'; " +
+			"sudo docker system df || echo 'DOCKER_DISK_USAGE=NotFound'; " +
+			"sudo docker network ls --format '{{.Name}}' || echo 'DOCKER_NETWORKS=NotFound'; " +
 			"echo '===END==='"
 		log.Printf("[서버 상태 확인] 도커 노드 상태 확인 명령어 준비 완료")
 	default:
@@ -322,18 +341,18 @@ func (h *ServerHandler) GetServerStatus(c *gin.Context) {
 		log.Printf("[서버 상태 확인] 시도 %d/10", attempt)
 		startTime := time.Now()
 
-		results, err := sshUtils.ExecuteCommands(requestBody.Hops, []string{cmd}, 20000)
+		results, err := sshUtils.ExecuteCommandsContext(c.Request.Context(), requestBody.Hops, []string{cmd}, 20000)
 		executionTime := time.Since(startTime)
 
 		if err != nil {
 			// 오류 타입에 따라 더 상세한 로그 추가
-			if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "timed out") {
+			if errors.Is(err, ssh.ErrConnectionTimeout) {
 				log.Printf("[서버 상태 확인 오류] 시도 %d 타임아웃 발생 (소요시간: %v): %v",
 					attempt, executionTime, err)
-			} else if strings.Contains(err.Error(), "connection") || strings.Contains(err.Error(), "connect") {
+			} else if errors.Is(err, ssh.ErrConnectionRefused) || errors.Is(err, ssh.ErrHostNotFound) || errors.Is(err, ssh.ErrTunnelingFailed) {
 				log.Printf("[서버 상태 확인 오류] 시도 %d 연결 실패 (소요시간: %v): %v",
 					attempt, executionTime, err)
-			} else if strings.Contains(err.Error(), "authentication") || strings.Contains(err.Error(), "auth") {
+			} else if errors.Is(err, ssh.ErrAuthenticationFailed) {
 				log.Printf("[서버 상태 확인 오류] 시도 %d 인증 실패 (소요시간: %v): %v",
 					attempt, executionTime, err)
 			} else {
@@ -443,7 +462,7 @@ func (h *ServerHandler) GetServerStatus(c *gin.Context) {
 
 	// 서버 ID가 제공된 경우 DB에 마지막 확인 시간 업데이트
 	if requestBody.ID > 0 {
-		err := db.UpdateServerLastChecked(h.DB, requestBody.ID, now)
+		err := h.Repos.Servers.UpdateLastChecked(requestBody.ID, now)
 		if err != nil {
 			log.Printf("[서버 상태 확인 경고] 마지막 확인 시간 업데이트 실패: %v", err)
 			// 업데이트 실패해도 계속 진행
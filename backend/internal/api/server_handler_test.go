@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+// newSQLiteServerHandler는 메모리 SQLite DB를 사용하는 ServerHandler를 생성합니다
+func newSQLiteServerHandler(t *testing.T) *ServerHandler {
+	t.Helper()
+	database, err := db.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return NewServerHandler(database, db.NewRepositories(database))
+}
+
+func TestServerHandlerCRUD(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newSQLiteServerHandler(t)
+	router := gin.New()
+	router.GET("/servers", h.GetServers)
+	router.GET("/servers/:id", h.GetServerById)
+	router.POST("/servers", h.CreateServer)
+	router.DELETE("/servers/:id", h.DeleteServer)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	infraID, err := h.Repos.Infras.Create(db.Infra{Name: "lab", Type: "kubernetes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := do(http.MethodPost, "/servers", `{"server_name": "worker-1", "hops": "[{\"host\":\"10.0.0.2\",\"port\":22,\"username\":\"ubuntu\"}]", "type": "worker", "infra_id": 1}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("생성 상태 = %d (%s)", w.Code, w.Body.String())
+	}
+	var created db.Server
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == 0 || created.InfraID != infraID || created.ServerName != "worker-1" {
+		t.Errorf("created = %+v", created)
+	}
+
+	w = do(http.MethodGet, "/servers?infra_id=1", "")
+	var servers []db.Server
+	if err := json.Unmarshal(w.Body.Bytes(), &servers); err != nil || len(servers) != 1 {
+		t.Errorf("인프라별 조회 = %s", w.Body.String())
+	}
+	if w = do(http.MethodGet, "/servers?type=master", ""); strings.TrimSpace(w.Body.String()) != "null" {
+		t.Errorf("타입별 조회 = %s", w.Body.String())
+	}
+
+	if w = do(http.MethodDelete, "/servers/1", ""); w.Code != http.StatusOK {
+		t.Errorf("삭제 상태 = %d (%s)", w.Code, w.Body.String())
+	}
+	if w = do(http.MethodGet, "/servers/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("삭제 후 조회 상태 = %d", w.Code)
+	}
+	if w = do(http.MethodPost, "/servers", `{"server_name": "x"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("필수 항목 없는 생성 상태 = %d", w.Code)
+	}
+}
+
+// stubServerRepository는 GetAll만 구현한 db.ServerRepository입니다 (다른 메서드는 호출하면 패닉)
+type stubServerRepository struct {
+	db.ServerRepository
+	servers []db.Server
+}
+
+func (r stubServerRepository) GetAll() ([]db.Server, error) { return r.servers, nil }
+
+func TestServerHandlerInjectedRepository(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repos := &db.Repositories{Servers: stubServerRepository{servers: []db.Server{{ID: 7, ServerName: "stub"}}}}
+	h := NewServerHandler(nil, repos)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/servers", nil)
+	h.GetServers(c)
+
+	var servers []db.Server
+	if err := json.Unmarshal(w.Body.Bytes(), &servers); err != nil || len(servers) != 1 || servers[0].ServerName != "stub" {
+		t.Errorf("주입한 저장소를 사용하지 않았습니다: %s", w.Body.String())
+	}
+}
+
+func TestGetServerStatusQuotesServerName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.HandleFunc(func(cmd string) (sshtest.Response, bool) {
+		return sshtest.Response{Stdout: "===START===\nINSTALLED=true\nKUBELET_RUNNING=true\n===END===\n"}, true
+	})
+
+	h := newSQLiteServerHandler(t)
+	infraID, err := h.Repos.Infras.Create(db.Infra{Name: "lab", Type: "kubernetes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := `w1"; touch /tmp/pwned; echo "`
+	id, err := h.Repos.Servers.Create(db.ServerInput{ServerName: name, Hops: `[{"host":"10.0.0.2","port":22,"username":"u"}]`, Type: "worker", InfraID: infraID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, resp := performJSON(t, h.GetServerStatus, gin.H{"hops": []ssh.HopConfig{server.Hop()}, "type": "worker", "id": id})
+	if code != http.StatusOK {
+		t.Fatalf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+
+	commands := server.Commands()
+	if len(commands) != 1 || !strings.Contains(commands[0], "echo hostname="+ssh.ShellQuote(name)+";") {
+		t.Errorf("서버 이름이 따옴표로 감싸지지 않음: %q", commands)
+	}
+}
+
+// countingResolver는 자격 증명 조회 횟수를 기록하는 ssh.CredentialResolver입니다
+type countingResolver struct{ calls int }
+
+func (r *countingResolver) ResolveHopCredential(id int) (ssh.HopCredential, error) {
+	r.calls++
+	return ssh.HopCredential{Password: "stored-secret"}, nil
+}
+
+func TestGetServerStatusRejectsRequestCredential(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sshtest.UseMemoryHostKeys(t)
+	attacker := sshtest.NewServer(t)
+	resolver := &countingResolver{}
+	ssh.SetCredentialResolver(resolver)
+	t.Cleanup(func() { ssh.SetCredentialResolver(nil) })
+
+	h := newSQLiteServerHandler(t)
+	infraID, err := h.Repos.Infras.Create(db.Infra{Name: "lab", Type: "kubernetes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	viewer := AuthUser{ID: 2, Username: "viewer", Roles: []db.RoleBinding{{Role: db.RoleViewer, ScopeType: db.ScopeInfra, ScopeID: infraID}}}
+
+	hop := attacker.Hop()
+	hop.Password = ""
+	hop.CredentialID = 1
+	body := gin.H{"hops": []ssh.HopConfig{hop}, "type": "worker", "infra_id": infraID}
+
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+	}{
+		{"핸들러", h.GetServerStatus},
+		{"권한 검사 미들웨어", func(c *gin.Context) {
+			RequireAction(h.DB, "getServerStatus")(c)
+			if !c.IsAborted() {
+				h.GetServerStatus(c)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := performJSONAs(t, viewer, tt.handler, body)
+			if code != http.StatusBadRequest {
+				t.Errorf("상태 코드 = %d, want %d (%v)", code, http.StatusBadRequest, resp)
+			}
+		})
+	}
+
+	if resolver.calls != 0 {
+		t.Errorf("요청 hops의 credential_id로 자격 증명을 %d번 조회했습니다", resolver.calls)
+	}
+	if commands := attacker.Commands(); len(commands) != 0 {
+		t.Errorf("요청한 호스트에 접속했습니다: %q", commands)
+	}
+}
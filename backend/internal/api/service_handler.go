@@ -16,6 +16,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/redact"
 )
 
 // 서비스 관련 액션 상수
@@ -44,7 +45,8 @@ const (
 
 // ServiceHandler 서비스 관련 API 핸들러
 type ServiceHandler struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Repos *db.Repositories
 }
 
 // ActionRequest는 액션 기반 API 요청을 표현합니다
@@ -54,8 +56,8 @@ type ServiceActionRequest struct {
 }
 
 // NewServiceHandler 새 ServiceHandler 생성
-func NewServiceHandler(db *sql.DB) *ServiceHandler {
-	return &ServiceHandler{DB: db}
+func NewServiceHandler(database *sql.DB, repos *db.Repositories) *ServiceHandler {
+	return &ServiceHandler{DB: database, Repos: repos}
 }
 
 // HandleRequest는 모든 서비스 관련 요청을 처리하는 통합 핸들러입니다
@@ -70,7 +72,12 @@ func (h *ServiceHandler) HandleRequest(c *gin.Context) {
 	}
 
 	// 액션 요청 로그 기록
-	log.Printf("[Service API 요청] 액션: %s, 파라미터: %+v", request.Action, request.Parameters)
+	log.Printf("[Service API 요청] 액션: %s, 파라미터: %+v", request.Action, redact.Params(request.Parameters))
+
+	// 사용자의 서비스, 인프라별 역할로 액션 실행 권한 확인
+	if !authorizeAction(c, h.DB, request.Action, request.Parameters) {
+		return
+	}
 
 	// 액션에 따라 적절한 핸들러 호출
 	switch request.Action {
@@ -121,7 +128,7 @@ func (h *ServiceHandler) HandleRequest(c *gin.Context) {
 // 모든 서비스 조회 핸들러
 func (h *ServiceHandler) handleGetServices(c *gin.Context, params map[string]interface{}) {
 	log.Printf("[서비스 조회] 모든 서비스 조회 요청")
-	services, err := db.GetAllServices(h.DB)
+	services, err := h.Repos.Services.GetAll()
 	if err != nil {
 		log.Printf("[서비스 조회 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -177,7 +184,7 @@ func (h *ServiceHandler) handleGetServiceById(c *gin.Context, params map[string]
 	}
 
 	log.Printf("[서비스 조회] ID %d로 서비스 조회 중", id)
-	service, err := db.GetServiceByID(h.DB, id)
+	service, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[서비스 조회 오류] ID %d에 해당하는 서비스 없음", id)
@@ -286,6 +293,10 @@ func (h *ServiceHandler) handleCreateService(c *gin.Context, params map[string]i
 	} else {
 		service.UserID = 0
 	}
+	// 로그인한 사용자를 서비스 소유자로 기록
+	if user, ok := CurrentUser(c); ok {
+		service.UserID = int64(user.ID)
+	}
 
 	// InfraID 처리
 	if infraIDRaw, ok := params["infra_id"]; ok && infraIDRaw != nil {
@@ -311,7 +322,7 @@ func (h *ServiceHandler) handleCreateService(c *gin.Context, params map[string]i
 		service.Name, service.Domain.String, service.Namespace.String, service.GitlabURL.String, service.InfraID.Int64)
 
 	// 서비스 생성
-	id, err := db.CreateService(h.DB, service)
+	id, err := h.Repos.Services.Create(service)
 	if err != nil {
 		log.Printf("[서비스 생성 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -322,7 +333,7 @@ func (h *ServiceHandler) handleCreateService(c *gin.Context, params map[string]i
 	}
 
 	// 생성된 서비스 조회
-	newService, err := db.GetServiceByID(h.DB, id)
+	newService, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		log.Printf("[서비스 생성 후 조회 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -379,7 +390,7 @@ func (h *ServiceHandler) handleUpdateService(c *gin.Context, params map[string]i
 	}
 
 	// 기존 서비스 조회
-	service, err := db.GetServiceByID(h.DB, id)
+	service, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[서비스 업데이트 오류] ID %d에 해당하는 서비스 없음", id)
@@ -465,7 +476,7 @@ func (h *ServiceHandler) handleUpdateService(c *gin.Context, params map[string]i
 	}
 
 	// 서비스 업데이트
-	if err := db.UpdateService(h.DB, service); err != nil {
+	if err := h.Repos.Services.Update(service); err != nil {
 		log.Printf("[서비스 업데이트 오류] 업데이트 실패: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -475,7 +486,7 @@ func (h *ServiceHandler) handleUpdateService(c *gin.Context, params map[string]i
 	}
 
 	// 업데이트된 서비스 조회
-	updatedService, err := db.GetServiceByID(h.DB, id)
+	updatedService, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		log.Printf("[서비스 업데이트 후 조회 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -533,7 +544,7 @@ func (h *ServiceHandler) handleDeleteService(c *gin.Context, params map[string]i
 
 	// 서비스 삭제
 	log.Printf("[서비스 삭제] ID %d 서비스 삭제 중", id)
-	if err := db.DeleteService(h.DB, id); err != nil {
+	if err := h.Repos.Services.Delete(id); err != nil {
 		log.Printf("[서비스 삭제 오류] %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -589,7 +600,7 @@ func (h *ServiceHandler) handleGetServiceStatus(c *gin.Context, params map[strin
 	}
 
 	// 서비스 조회
-	service, err := db.GetServiceByID(h.DB, id)
+	service, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -679,7 +690,7 @@ func (h *ServiceHandler) handleDeployService(c *gin.Context, params map[string]i
 	}
 
 	// 서비스 조회 (존재 여부 확인)
-	_, err := db.GetServiceByID(h.DB, id)
+	_, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -743,7 +754,7 @@ func (h *ServiceHandler) handleRestartService(c *gin.Context, params map[string]
 	}
 
 	// 서비스 조회 (존재 여부 확인)
-	_, err := db.GetServiceByID(h.DB, id)
+	_, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -807,7 +818,7 @@ func (h *ServiceHandler) handleStopService(c *gin.Context, params map[string]int
 	}
 
 	// 서비스 조회 (존재 여부 확인)
-	_, err := db.GetServiceByID(h.DB, id)
+	_, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -871,7 +882,7 @@ func (h *ServiceHandler) handleRemoveService(c *gin.Context, params map[string]i
 	}
 
 	// 서비스 조회 (존재 여부 확인)
-	_, err := db.GetServiceByID(h.DB, id)
+	_, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -890,7 +901,7 @@ func (h *ServiceHandler) handleRemoveService(c *gin.Context, params map[string]i
 	// TODO: 실제 쿠버네티스에서 서비스 제거 로직 구현
 	// 그 다음에 DB에서 제거
 
-	if err := db.DeleteService(h.DB, id); err != nil {
+	if err := h.Repos.Services.Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -947,7 +958,7 @@ func (h *ServiceHandler) handleGetDockerFiles(c *gin.Context, params map[string]
 	}
 
 	// 서비스 조회 (존재 여부 확인)
-	service, err := db.GetServiceByID(h.DB, id)
+	service, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[도커 파일 조회 오류] ID %d에 해당하는 서비스 없음", id)
@@ -1383,7 +1394,7 @@ func (h *ServiceHandler) handleSaveDockerfile(c *gin.Context, params map[string]
 	}
 
 	// 서비스 조회 (존재 여부 확인)
-	service, err := db.GetServiceByID(h.DB, id)
+	service, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[Dockerfile 저장 오류] ID %d에 해당하는 서비스 없음", id)
@@ -1613,7 +1624,7 @@ func (h *ServiceHandler) handleSaveDockerCompose(c *gin.Context, params map[stri
 	}
 
 	// 서비스 조회 (존재 여부 확인)
-	service, err := db.GetServiceByID(h.DB, id)
+	service, err := h.Repos.Services.GetByID(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("[Docker Compose 저장 오류] ID %d에 해당하는 서비스 없음", id)
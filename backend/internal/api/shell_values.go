@@ -0,0 +1,45 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/command"
+)
+
+// shellValue는 원격 셸 명령어에 들어가는 요청 값과 따라야 하는 형식입니다
+type shellValue struct {
+	name   string
+	format command.ParamFormat
+	value  string
+}
+
+// validateShellValues는 요청 값이 형식에 맞는지 확인합니다
+// 맞지 않으면 400 응답을 보내고 false를 반환합니다. 값은 명령어에 넣을 때 command.Render로 감싸야 합니다
+func validateShellValues(c *gin.Context, values ...shellValue) bool {
+	for _, v := range values {
+		if err := command.ValidateFormat(v.format, v.value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": v.name + ": " + err.Error()})
+			return false
+		}
+	}
+	return true
+}
+
+// gitCloneCommand는 workDir에 저장소를 클론하는 명령어를 만듭니다
+// 사용자명과 비밀번호가 있으면 URL 인코딩하여 https URL에 넣습니다 (특수 문자가 포함된 이메일, 토큰 등)
+func gitCloneCommand(workDir, repoURL, branch, username, password string) (string, error) {
+	cloneURL := repoURL
+	if username != "" && password != "" {
+		parsedURL, err := url.Parse(repoURL)
+		if err != nil || parsedURL.Host == "" {
+			return "", fmt.Errorf("저장소 인증 정보는 https 저장소 URL에만 사용할 수 있습니다")
+		}
+		parsedURL.Scheme = "https"
+		parsedURL.User = url.UserPassword(username, password)
+		cloneURL = parsedURL.String()
+	}
+	return command.Render("cd %s && git clone -b %s -- %s . 2>&1 || echo 'Git 클론 실패'", workDir, branch, cloneURL), nil
+}
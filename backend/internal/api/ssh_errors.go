@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// statusClientClosedRequest는 클라이언트가 응답 전에 요청을 취소했음을 나타냅니다 (nginx 관례)
+const statusClientClosedRequest = 499
+
+// sshErrorStatus는 SSH 실행 오류를 HTTP 상태 코드로 변환합니다
+//   - 잘못된 hop 설정, 액션 파라미터 스키마 위반: 400
+//   - 원격 서버 인증 실패: 422 (API 자체의 인증 실패인 401과 구분)
+//   - 호스트 키 불일치: 409
+//   - 원격 서버 연결/터널링/실행 실패: 502
+//   - 타임아웃: 504
+//   - 요청 취소: 499
+//
+// SSHError가 아니거나 err가 nil이면 500을 반환합니다
+func sshErrorStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusInternalServerError
+	case errors.Is(err, ssh.ErrValidation), errors.Is(err, command.ErrInvalidParams):
+		return http.StatusBadRequest
+	case errors.Is(err, ssh.ErrAuthenticationFailed):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ssh.ErrHostKeyMismatch):
+		return http.StatusConflict
+	case errors.Is(err, ssh.ErrConnectionTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ssh.ErrCommandCanceled):
+		return statusClientClosedRequest
+	case errors.Is(err, ssh.ErrHostNotFound),
+		errors.Is(err, ssh.ErrConnectionRefused),
+		errors.Is(err, ssh.ErrTunnelingFailed),
+		errors.Is(err, ssh.ErrCommandExecutionFailed),
+		errors.Is(err, ssh.ErrRemoteCommandFailed),
+		errors.Is(err, ssh.ErrTransferFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
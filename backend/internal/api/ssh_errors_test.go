@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k8scontrol/backend/internal/db"
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+func TestSSHErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusInternalServerError},
+		{errors.New("plain"), http.StatusInternalServerError},
+		{ssh.SSHError{Type: ssh.ValidationError}, http.StatusBadRequest},
+		{ssh.SSHError{Type: ssh.AuthenticationFailed}, http.StatusUnprocessableEntity},
+		{ssh.SSHError{Type: ssh.HostKeyMismatch}, http.StatusConflict},
+		{ssh.SSHError{Type: ssh.HostNotFound}, http.StatusBadGateway},
+		{ssh.SSHError{Type: ssh.ConnectionRefused}, http.StatusBadGateway},
+		{ssh.SSHError{Type: ssh.TunnelingFailed}, http.StatusBadGateway},
+		{ssh.SSHError{Type: ssh.RemoteCommandFailed}, http.StatusBadGateway},
+		{ssh.SSHError{Type: ssh.ConnectionTimeout}, http.StatusGatewayTimeout},
+		{ssh.SSHError{Type: ssh.CommandCanceled, Err: context.Canceled}, statusClientClosedRequest},
+		{ssh.SSHError{Type: ssh.UnknownError}, http.StatusInternalServerError},
+		// 감싸진 오류도 유형대로 분류합니다
+		{fmt.Errorf("설치 실패: %w", ssh.SSHError{Type: ssh.ConnectionTimeout}), http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		if got := sshErrorStatus(tt.err); got != tt.want {
+			t.Errorf("sshErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateNodesAuthFailure(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t, sshtest.RejectAuth())
+
+	h := NewInfraHandler(nil, &db.Repositories{})
+	code, resp := performJSON(t, h.CalculateNodes, gin.H{"hops": []ssh.HopConfig{server.Hop()}})
+	if code != http.StatusUnprocessableEntity || resp["success"] != false {
+		t.Errorf("상태 코드 = %d, 응답: %v", code, resp)
+	}
+}
@@ -0,0 +1,181 @@
+// Package auth는 사용자 비밀번호 해시와 JWT 액세스/리프레시 토큰, API 토큰 발급, 검증을 담당합니다
+//
+// 액세스 토큰은 수명이 짧고 매 요청의 Authorization 헤더로 전달합니다.
+// 리프레시 토큰은 새 액세스 토큰을 받을 때 한 번만 사용할 수 있으며, 사용한 토큰은 DB에서 폐기됩니다.
+// API 토큰은 CI 등 자동화 호출용 장기 토큰으로, 해시만 DB에 저장합니다
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// DefaultAccessTTL은 액세스 토큰의 기본 유효 기간입니다
+	DefaultAccessTTL = 15 * time.Minute
+	// DefaultRefreshTTL은 리프레시 토큰의 기본 유효 기간입니다
+	DefaultRefreshTTL = 7 * 24 * time.Hour
+
+	// MinPasswordLength는 사용자 비밀번호의 최소 길이입니다
+	MinPasswordLength = 8
+)
+
+// 토큰 종류
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+var (
+	// ErrInvalidToken은 서명, 형식, 종류가 올바르지 않은 토큰입니다
+	ErrInvalidToken = errors.New("유효하지 않은 토큰입니다")
+	// ErrExpiredToken은 유효 기간이 지난 토큰입니다
+	ErrExpiredToken = errors.New("만료된 토큰입니다")
+	// ErrWeakPassword는 정책에 맞지 않는 비밀번호입니다
+	ErrWeakPassword = fmt.Errorf("비밀번호는 %d자 이상이어야 합니다", MinPasswordLength)
+)
+
+// Claims는 토큰에 담기는 사용자 정보입니다
+type Claims struct {
+	UserID   int    `json:"uid"`
+	Username string `json:"username"`
+	Type     string `json:"typ"`
+	jwt.StandardClaims
+}
+
+// Manager는 토큰을 발급하고 검증합니다
+type Manager struct {
+	secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// NewManager는 secret으로 서명하는 Manager를 생성합니다
+func NewManager(secret []byte) *Manager {
+	return &Manager{secret: secret, AccessTTL: DefaultAccessTTL, RefreshTTL: DefaultRefreshTTL}
+}
+
+// LoadSecret은 설정의 JWT 서명 키(auth.jwt_secret, JWT_SECRET)를 반환합니다
+// 설정되지 않았으면 무작위 키를 생성하고 generated를 true로 반환합니다. 이 경우 서버를 재시작하면 기존 토큰은 무효가 됩니다
+func LoadSecret(configured string) (secret []byte, generated bool, err error) {
+	if configured != "" {
+		return []byte(configured), false, nil
+	}
+	secret = make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, false, err
+	}
+	return secret, true, nil
+}
+
+// Issue는 사용자에게 typ 종류의 토큰을 발급합니다. 반환한 Claims의 Id(jti)로 리프레시 토큰을 추적합니다
+func (m *Manager) Issue(userID int, username, typ string) (string, Claims, error) {
+	ttl := m.AccessTTL
+	if typ == TokenRefresh {
+		ttl = m.RefreshTTL
+	}
+	id, err := newTokenID()
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	now := time.Now()
+	claims := Claims{
+		UserID:   userID,
+		Username: username,
+		Type:     typ,
+		StandardClaims: jwt.StandardClaims{
+			Id:        id,
+			Subject:   fmt.Sprint(userID),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	return token, claims, nil
+}
+
+// Parse는 토큰의 서명과 유효 기간, 종류를 확인하고 Claims를 반환합니다
+func (m *Manager) Parse(token, typ string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("지원하지 않는 서명 방식입니다: %v", t.Header["alg"])
+		}
+		return m.secret, nil
+	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+	if claims.Type != typ || claims.UserID == 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// HashPassword는 비밀번호 정책을 확인하고 bcrypt 해시를 반환합니다
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword는 비밀번호가 bcrypt 해시와 일치하는지 확인합니다
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// APITokenPrefix는 API 토큰의 접두사입니다. JWT와 구분하고, 유출된 토큰을 찾기 쉽게 합니다
+const APITokenPrefix = "kct_"
+
+// NewAPIToken은 새 API 토큰과 저장용 해시, 목록 표시용 앞부분을 생성합니다
+// 토큰 원문은 생성 시 한 번만 사용자에게 보여 주고 저장하지 않습니다
+func NewAPIToken() (token, hash, display string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashAPIToken(token), token[:len(APITokenPrefix)+6], nil
+}
+
+// HashAPIToken은 API 토큰의 SHA-256 해시입니다
+// 토큰은 충분히 긴 무작위 값이므로 bcrypt 대신 조회 가능한 해시를 사용합니다
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken은 값이 API 토큰 형식인지 확인합니다
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
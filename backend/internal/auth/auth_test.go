@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIssueParse(t *testing.T) {
+	m := NewManager([]byte("test-secret"))
+
+	token, claims, err := m.Issue(3, "alice", TokenAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Id == "" {
+		t.Error("토큰 ID가 비어 있습니다")
+	}
+
+	parsed, err := m.Parse(token, TokenAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.UserID != 3 || parsed.Username != "alice" || parsed.Id != claims.Id {
+		t.Errorf("Parse = %+v", parsed)
+	}
+
+	// 액세스 토큰을 리프레시 토큰으로 사용할 수 없음
+	if _, err := m.Parse(token, TokenRefresh); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("종류가 다른 토큰: err = %v, want ErrInvalidToken", err)
+	}
+	// 다른 키로 서명한 토큰은 거부
+	if _, err := NewManager([]byte("other-secret")).Parse(token, TokenAccess); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("다른 키: err = %v, want ErrInvalidToken", err)
+	}
+	if _, err := m.Parse("not-a-token", TokenAccess); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("잘못된 형식: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestParseExpired(t *testing.T) {
+	m := NewManager([]byte("test-secret"))
+	m.AccessTTL = -time.Minute
+
+	token, _, err := m.Issue(1, "bob", TokenAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Parse(token, TokenAccess); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("err = %v, want ErrExpiredToken", err)
+	}
+}
+
+func TestPassword(t *testing.T) {
+	if _, err := HashPassword("short"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("짧은 비밀번호: err = %v, want ErrWeakPassword", err)
+	}
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Error("올바른 비밀번호가 거부되었습니다")
+	}
+	if CheckPassword(hash, "wrong horse") {
+		t.Error("틀린 비밀번호가 허용되었습니다")
+	}
+}
+
+func TestNewAPIToken(t *testing.T) {
+	token, hash, display, err := NewAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPIToken(token) || IsAPIToken("eyJhbGciOiJIUzI1NiJ9.x.y") {
+		t.Errorf("IsAPIToken 판별 오류: %s", token)
+	}
+	if hash != HashAPIToken(token) || len(hash) != 64 {
+		t.Errorf("hash = %s", hash)
+	}
+	if len(display) >= len(token) || token[:len(display)] != display {
+		t.Errorf("display = %s, token = %s", display, token)
+	}
+
+	other, _, _, _ := NewAPIToken()
+	if other == token {
+		t.Error("같은 토큰이 생성되었습니다")
+	}
+}
@@ -1,12 +1,15 @@
 package command
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/k8scontrol/backend/internal/utils"
+	"github.com/k8scontrol/backend/pkg/redact"
 	"github.com/k8scontrol/backend/pkg/ssh"
 )
 
@@ -15,11 +18,26 @@ type CommandTemplate struct {
 	Commands     []string
 	PrepareFunc  func(params map[string]interface{}) ([]string, error)
 	ValidateFunc func(params map[string]interface{}) error
+	// RunAsRoot가 true이면 모든 명령어를 관리자 권한으로 실행합니다
+	// 명령어에 sudo를 직접 붙이지 않으며, sudo 비밀번호는 실행 시 stdin으로 전달됩니다
+	RunAsRoot bool
+	// FilesFunc는 명령어 실행 전에 SFTP로 대상 서버에 업로드할 파일을 준비합니다
+	FilesFunc func(params map[string]interface{}) ([]ssh.FileUpload, error)
+	// StepsFunc가 있으면 Commands/PrepareFunc 대신 선언적 단계로 액션을 실행합니다 (RunSteps 참고)
+	StepsFunc func(params map[string]interface{}) ([]Step, error)
+	// Params는 액션이 받는 파라미터 정의입니다. ValidateFunc보다 먼저 검증하며 기본값과 타입 변환을 적용합니다
+	Params ParamSchema
+	// Description은 액션 탐색 API에 표시되는 설명입니다
+	Description string
 }
 
 // CommandTarget은 명령어 실행 대상을 정의합니다
 type CommandTarget struct {
 	Hops []ssh.HopConfig
+	// Targets는 단계 템플릿에서 Step.Target 이름으로 참조하는 추가 실행 대상입니다 (예: 로드 밸런서)
+	Targets map[string][]ssh.HopConfig
+	// InfraID는 대상 서버가 속한 인프라입니다 (EnqueueAction으로 만든 작업의 조회 권한 확인에 사용)
+	InfraID int
 }
 
 // GetDescription은 CommandTarget의 간략한 설명을 반환합니다
@@ -41,7 +59,18 @@ func (ct *CommandTarget) GetDescription() string {
 type CommandManager struct {
 	commandMap     map[string]CommandTemplate
 	sshUtils       utils.SSHUtils
-	commandTimeout int // 명령어 실행 타임아웃 (밀리초)
+	commandTimeout int         // 명령어 실행 타임아웃 (밀리초)
+	jobs           *JobManager // 비동기 작업 실행기 (nil이면 DefaultJobManager)
+}
+
+// defaultCommandTimeout은 새 CommandManager의 명령어 실행 타임아웃입니다 (밀리초, 기본 30초)
+var defaultCommandTimeout int64 = 30000
+
+// SetDefaultCommandTimeout은 이후 생성하는 CommandManager의 명령어 실행 타임아웃을 설정합니다
+func SetDefaultCommandTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		atomic.StoreInt64(&defaultCommandTimeout, timeout.Milliseconds())
+	}
 }
 
 // NewCommandManager는 새 CommandManager 인스턴스를 생성합니다
@@ -49,12 +78,16 @@ func NewCommandManager() *CommandManager {
 	return &CommandManager{
 		commandMap:     make(map[string]CommandTemplate),
 		sshUtils:       *utils.NewSSHUtils(),
-		commandTimeout: 30000, // 기본 타임아웃 30초
+		commandTimeout: int(atomic.LoadInt64(&defaultCommandTimeout)),
 	}
 }
 
 // RegisterCommand는 새로운 명령어 템플릿을 등록합니다
+// 파라미터 스키마 정의가 잘못되었으면 등록 시점에 panic합니다
 func (cm *CommandManager) RegisterCommand(action string, template CommandTemplate) {
+	if err := template.Params.Validate(); err != nil {
+		panic(fmt.Sprintf("액션 %s의 파라미터 스키마 오류: %v", action, err))
+	}
 	cm.commandMap[action] = template
 }
 
@@ -65,9 +98,42 @@ func (cm *CommandManager) HasCommandTemplate(action string) bool {
 }
 
 // ExecuteAction은 지정된 액션을 실행하고 결과를 반환합니다
-func (cm *CommandManager) ExecuteAction(action string, params map[string]interface{}, target *CommandTarget) ([]ssh.CommandResult, error) {
+// ctx가 취소되면 실행 중인 원격 명령어를 종료하고 취소 오류를 반환합니다
+// WithDryRun 컨텍스트이면 SSH 연결 없이 실행될 명령어(비밀값은 가려짐)만 결과로 반환합니다
+func (cm *CommandManager) ExecuteAction(ctx context.Context, action string, params map[string]interface{}, target *CommandTarget) ([]ssh.CommandResult, error) {
+	return cm.ExecuteActionStream(ctx, action, params, target, nil)
+}
+
+// ExecuteActionStream은 ExecuteAction과 같이 액션을 실행하면서 명령어 출력을 handler로 전달합니다
+// 실행 결과와 오류 메시지의 비밀값은 가려서 반환합니다
+func (cm *CommandManager) ExecuteActionStream(ctx context.Context, action string, params map[string]interface{}, target *CommandTarget, handler ssh.OutputHandler) ([]ssh.CommandResult, error) {
+	// 실행하는 동안 액션의 비밀값이 로그와 작업 기록에 남지 않도록 등록
+	masker := newSecretMasker(params, target, cm.commandMap[action].Params)
+	defer redact.Track(masker.Secrets()...)()
+
 	// 액션 로깅
-	log.Printf("[CommandManager] 액션 실행: %s, 파라미터: %+v", action, params)
+	log.Printf("[CommandManager] 액션 실행: %s, 파라미터: %+v", action, redact.Params(params))
+
+	results, err := cm.executeAction(ctx, action, params, target, handler)
+	return maskResults(masker, results), masker.Error(err)
+}
+
+// executeAction은 ExecuteActionStream의 실제 실행 부분입니다
+func (cm *CommandManager) executeAction(ctx context.Context, action string, params map[string]interface{}, target *CommandTarget, handler ssh.OutputHandler) ([]ssh.CommandResult, error) {
+
+	// 드라이런은 실행 계획만 만들고 반환
+	if IsDryRun(ctx) {
+		plan, err := cm.PlanAction(action, params, target)
+		if err != nil {
+			return nil, err
+		}
+		return plan.CommandResults(), nil
+	}
+
+	// 단계 템플릿은 단계 실행기로 처리
+	if template, exists := cm.commandMap[action]; exists && template.StepsFunc != nil {
+		return cm.executeStepsAction(ctx, action, params, target, handler)
+	}
 
 	// 액션에 대한 명령어 템플릿 가져오기
 	commands, err := cm.PrepareAction(action, params)
@@ -82,9 +148,22 @@ func (cm *CommandManager) ExecuteAction(action string, params map[string]interfa
 		target = &CommandTarget{}
 	}
 
+	// 명령어에서 사용할 파일 업로드
+	files, err := cm.PrepareFiles(action, params)
+	if err != nil {
+		log.Printf("[CommandManager] 액션 %s 파일 준비 실패: %v", action, err)
+		return nil, err
+	}
+	if len(files) > 0 {
+		if err := cm.UploadFiles(ctx, target, files); err != nil {
+			log.Printf("[CommandManager] 액션 %s 파일 업로드 실패: %v", action, err)
+			return nil, err
+		}
+	}
+
 	// 명령어 실행
 	startTime := time.Now()
-	results, err := cm.ExecuteCustomCommands(target, commands)
+	results, err := cm.ExecuteCustomCommandsStream(ctx, target, commands, handler)
 	executionTime := time.Since(startTime)
 
 	if err != nil {
@@ -97,7 +176,12 @@ func (cm *CommandManager) ExecuteAction(action string, params map[string]interfa
 }
 
 // ExecuteCustomCommands는 주어진 명령어를 대상 서버에서 실행합니다
-func (cm *CommandManager) ExecuteCustomCommands(target *CommandTarget, commands []string) ([]ssh.CommandResult, error) {
+func (cm *CommandManager) ExecuteCustomCommands(ctx context.Context, target *CommandTarget, commands []string) ([]ssh.CommandResult, error) {
+	return cm.ExecuteCustomCommandsStream(ctx, target, commands, nil)
+}
+
+// ExecuteCustomCommandsStream은 주어진 명령어를 대상 서버에서 실행하면서 출력을 handler로 전달합니다
+func (cm *CommandManager) ExecuteCustomCommandsStream(ctx context.Context, target *CommandTarget, commands []string, handler ssh.OutputHandler) ([]ssh.CommandResult, error) {
 	// 로그 기록
 	if target == nil || len(target.Hops) == 0 {
 		log.Printf("[CommandManager] 오류: 대상 서버 정보가 없습니다")
@@ -105,31 +189,50 @@ func (cm *CommandManager) ExecuteCustomCommands(target *CommandTarget, commands
 	}
 	log.Printf("[CommandManager] 커스텀 명령어 실행: %d개의 명령어, 대상: %s", len(commands), target.GetDescription())
 
-	// SSH 유틸리티 초기화
-	sshUtils := utils.NewSSHUtils()
+	// 드라이런에서는 SSH 연결을 열지 않고 명령어만 반환
+	masker := newSecretMasker(nil, target, nil)
+	if IsDryRun(ctx) {
+		results := make([]ssh.CommandResult, len(commands))
+		for i, cmd := range commands {
+			results[i] = ssh.CommandResult{Command: masker.String(cmd)}
+		}
+		return results, nil
+	}
 
-	// 명령어 실행
+	// 명령어 실행 (공유 연결 풀 사용)
 	startTime := time.Now()
-	results, err := sshUtils.ExecuteCommands(target.Hops, commands, cm.commandTimeout)
+	results, err := cm.sshUtils.ExecuteCommandsStream(ctx, target.Hops, commands, cm.commandTimeout, handler)
 	executionTime := time.Since(startTime)
 
 	// 실행 결과 로깅
 	if err != nil {
 		// 오류 타입에 따라 더 상세한 로그 추가
-		if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "timed out") {
+		if errors.Is(err, ssh.ErrCommandCanceled) {
+			log.Printf("[CommandManager] 커스텀 명령어 실행 취소됨 (소요시간: %v): %v", executionTime, err)
+		} else if errors.Is(err, ssh.ErrConnectionTimeout) {
 			log.Printf("[CommandManager] 오류: 커스텀 명령어 실행 중 타임아웃 발생 (소요시간: %v): %v", executionTime, err)
-		} else if strings.Contains(err.Error(), "connection") || strings.Contains(err.Error(), "connect") {
+		} else if errors.Is(err, ssh.ErrConnectionRefused) || errors.Is(err, ssh.ErrHostNotFound) || errors.Is(err, ssh.ErrTunnelingFailed) {
 			log.Printf("[CommandManager] 오류: 커스텀 명령어 실행 중 연결 실패 (소요시간: %v): %v", executionTime, err)
-		} else if strings.Contains(err.Error(), "authentication") || strings.Contains(err.Error(), "auth") {
+		} else if errors.Is(err, ssh.ErrAuthenticationFailed) {
 			log.Printf("[CommandManager] 오류: 커스텀 명령어 실행 중 인증 실패 (소요시간: %v): %v", executionTime, err)
 		} else {
 			log.Printf("[CommandManager] 오류: 커스텀 명령어 실행 중 에러 발생 (소요시간: %v): %v", executionTime, err)
 		}
-		return nil, err
+		return nil, masker.Error(err)
 	}
 
 	log.Printf("[CommandManager] 명령어 실행 성공 (소요시간: %v): %d개의 결과", executionTime, len(results))
-	return results, nil
+	return maskResults(masker, results), nil
+}
+
+// maskResults는 실행 결과의 명령어와 출력에서 비밀값을 가립니다
+func maskResults(m *redact.Masker, results []ssh.CommandResult) []ssh.CommandResult {
+	for i := range results {
+		results[i].Command = m.String(results[i].Command)
+		results[i].Output = m.String(results[i].Output)
+		results[i].Error = m.String(results[i].Error)
+	}
+	return results
 }
 
 // PrepareAction은 액션에 맞는 명령어를 준비합니다
@@ -142,17 +245,21 @@ func (cm *CommandManager) PrepareAction(action string, params map[string]interfa
 	}
 
 	// 2. 파라미터 검증
-	if template.ValidateFunc != nil {
-		if err := template.ValidateFunc(params); err != nil {
-			log.Printf("[CommandManager] 오류: 파라미터 검증 실패: %v", err)
-			return nil, fmt.Errorf("파라미터 검증 실패: %w", err)
-		}
+	params, err := validateParams(template, params)
+	if err != nil {
+		return nil, err
 	}
 
-	// 3. 명령어 준비
+	// 3. 명령어 준비 (단계 템플릿은 단계의 실행 명령어를 순서대로 반환)
+	if template.StepsFunc != nil {
+		steps, err := cm.buildSteps(template, params)
+		if err != nil {
+			return nil, err
+		}
+		return StepCommands(steps), nil
+	}
 	commands := template.Commands
 	if template.PrepareFunc != nil {
-		var err error
 		commands, err = template.PrepareFunc(params)
 		if err != nil {
 			log.Printf("[CommandManager] 오류: 명령어 준비 실패: %v", err)
@@ -160,9 +267,70 @@ func (cm *CommandManager) PrepareAction(action string, params map[string]interfa
 		}
 	}
 
+	// 4. 관리자 권한 실행
+	if template.RunAsRoot {
+		rootCommands := make([]string, len(commands))
+		for i, cmd := range commands {
+			rootCommands[i] = ssh.AsRoot(cmd)
+		}
+		commands = rootCommands
+	}
+
 	return commands, nil
 }
 
+// validateParams는 템플릿의 파라미터 스키마와 ValidateFunc로 파라미터를 검증하고 스키마를 적용한 파라미터를 반환합니다
+func validateParams(template CommandTemplate, params map[string]interface{}) (map[string]interface{}, error) {
+	params, err := template.Params.Apply(params)
+	if err == nil && template.ValidateFunc != nil {
+		err = template.ValidateFunc(params)
+	}
+	if err != nil {
+		log.Printf("[CommandManager] 오류: 파라미터 검증 실패: %v", err)
+		return nil, fmt.Errorf("파라미터 검증 실패: %w", err)
+	}
+	return params, nil
+}
+
+// PrepareFiles는 액션에서 사용할 업로드 파일을 준비합니다
+// 파라미터 검증은 PrepareAction에서 수행하므로 PrepareAction 이후에 호출해야 합니다
+func (cm *CommandManager) PrepareFiles(action string, params map[string]interface{}) ([]ssh.FileUpload, error) {
+	template, exists := cm.commandMap[action]
+	if !exists {
+		return nil, fmt.Errorf("지원하지 않는 액션입니다: %s", action)
+	}
+	if template.FilesFunc == nil {
+		return nil, nil
+	}
+
+	params, err := template.Params.Apply(params)
+	if err != nil {
+		return nil, fmt.Errorf("파라미터 검증 실패: %w", err)
+	}
+	files, err := template.FilesFunc(params)
+	if err != nil {
+		log.Printf("[CommandManager] 오류: 파일 준비 실패: %v", err)
+		return nil, fmt.Errorf("파일 준비 실패: %w", err)
+	}
+	return files, nil
+}
+
+// UploadFiles는 준비된 파일을 대상 서버에 업로드합니다
+func (cm *CommandManager) UploadFiles(ctx context.Context, target *CommandTarget, files []ssh.FileUpload) error {
+	if target == nil || len(target.Hops) == 0 {
+		return fmt.Errorf("대상 서버 정보가 없습니다")
+	}
+
+	results, err := cm.sshUtils.UploadFiles(ctx, target.Hops, files, cm.commandTimeout)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		log.Printf("[CommandManager] 파일 업로드 완료: %s (%d bytes, sha256 %s)", result.RemotePath, result.Size, result.Checksum)
+	}
+	return nil
+}
+
 // SetCommandTimeout은 명령어 실행 타임아웃을 설정합니다
 func (cm *CommandManager) SetCommandTimeout(timeout int) {
 	cm.commandTimeout = timeout
@@ -0,0 +1,150 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+func TestPrepareActionRunAsRoot(t *testing.T) {
+	cm := NewCommandManager()
+	RegisterDockerCommands(cm)
+
+	commands, err := cm.PrepareAction(ActionGetDockerVersion, map[string]interface{}{"password": "pw"})
+	if err != nil {
+		t.Fatalf("PrepareAction 실패: %v", err)
+	}
+	want := `sudo sh -c 'docker --version 2>/dev/null || echo '\''DOCKER_NOT_FOUND'\'''`
+	if commands[0] != want {
+		t.Errorf("commands[0] = %s, want %s", commands[0], want)
+	}
+	for _, cmd := range commands {
+		if strings.Contains(cmd, "pw") {
+			t.Errorf("명령어에 비밀번호가 포함됨: %s", cmd)
+		}
+	}
+
+	// 검증 실패
+	if _, err := cm.PrepareAction(ActionGetDockerVersion, map[string]interface{}{}); err == nil {
+		t.Error("password가 없으면 검증에 실패해야 합니다")
+	}
+	if _, err := cm.PrepareAction("unknownAction", nil); err == nil {
+		t.Error("등록되지 않은 액션은 실패해야 합니다")
+	}
+}
+
+func TestExecuteActionOnFakeServer(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.HandleFunc(func(cmd string) (sshtest.Response, bool) {
+		if strings.HasPrefix(cmd, "sudo sh -c 'docker --version") {
+			return sshtest.Response{Stdout: "Docker version 24.0.7, build afdd53b\n"}, true
+		}
+		return sshtest.Response{}, false
+	})
+
+	cm := NewCommandManager()
+	RegisterDockerCommands(cm)
+
+	results, err := cm.ExecuteAction(context.Background(), ActionGetDockerVersion, map[string]interface{}{"password": ""}, &CommandTarget{Hops: []ssh.HopConfig{server.Hop()}})
+	if err != nil {
+		t.Fatalf("ExecuteAction 실패: %v", err)
+	}
+	if !strings.Contains(results[0].Output, "Docker version 24.0.7") {
+		t.Errorf("출력 = %q", results[0].Output)
+	}
+	if execs := server.Execs(); execs[0].SudoPassword != sshtest.DefaultPassword {
+		t.Errorf("sudo 비밀번호가 stdin으로 전달되지 않음: %q", execs[0].SudoPassword)
+	}
+}
+
+func TestExecuteActionFanOut(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+
+	healthy1 := sshtest.NewServer(t)
+	healthy2 := sshtest.NewServer(t)
+	rejecting := sshtest.NewServer(t, sshtest.RejectAuth())
+	slow := sshtest.NewServer(t)
+	for _, s := range []*sshtest.Server{healthy1, healthy2, slow} {
+		s.HandleFunc(func(cmd string) (sshtest.Response, bool) {
+			if name, ok := strings.CutPrefix(cmd, "echo "); ok {
+				return sshtest.Response{Stdout: name + "\n"}, true
+			}
+			return sshtest.Response{}, false
+		})
+	}
+	slow.Handle("echo node-4", sshtest.Response{Delay: time.Minute})
+
+	cm := NewCommandManager()
+	cm.RegisterCommand("echoName", CommandTemplate{
+		PrepareFunc: func(params map[string]interface{}) ([]string, error) {
+			return []string{fmt.Sprintf("echo %s", params["server_name"])}, nil
+		},
+	})
+
+	var targets []FanOutTarget
+	for i, s := range []*sshtest.Server{healthy1, healthy2, rejecting, slow} {
+		name := fmt.Sprintf("node-%d", i+1)
+		targets = append(targets, FanOutTarget{
+			Name:   name,
+			Target: &CommandTarget{Hops: []ssh.HopConfig{s.Hop()}},
+			Params: map[string]interface{}{"server_name": name},
+		})
+	}
+
+	results := cm.ExecuteActionFanOut(context.Background(), "echoName", nil, targets, FanOutOptions{
+		Concurrency: 2,
+		HostTimeout: 500 * time.Millisecond,
+	})
+	if len(results) != len(targets) {
+		t.Fatalf("결과 개수 = %d, want %d", len(results), len(targets))
+	}
+
+	for i, name := range []string{"node-1", "node-2"} {
+		if !results[i].Success || results[i].Results[0].Output != name+"\n" {
+			t.Errorf("%s 결과 = %+v", name, results[i])
+		}
+	}
+	if results[2].Success || results[2].ErrorType != string(ssh.AuthenticationFailed) {
+		t.Errorf("인증 실패 대상 결과 = %+v", results[2])
+	}
+	if results[3].Success || results[3].ErrorType != string(ssh.ConnectionTimeout) {
+		t.Errorf("타임아웃 대상 결과 = %+v", results[3])
+	}
+}
+
+func TestFanOutConcurrencyLimit(t *testing.T) {
+	cm := NewCommandManager()
+	targets := make([]FanOutTarget, 8)
+
+	var mu sync.Mutex
+	current, maxRunning := 0, 0
+	results := cm.fanOut(context.Background(), targets, FanOutOptions{Concurrency: 3}, func(ctx context.Context, target FanOutTarget) ([]ssh.CommandResult, error) {
+		mu.Lock()
+		current++
+		if current > maxRunning {
+			maxRunning = current
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		current--
+		mu.Unlock()
+		return nil, nil
+	})
+
+	if len(results) != len(targets) {
+		t.Fatalf("결과 개수 = %d, want %d", len(results), len(targets))
+	}
+	if maxRunning > 3 {
+		t.Errorf("동시 실행 수 = %d, want <= 3", maxRunning)
+	}
+}
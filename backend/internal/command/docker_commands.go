@@ -54,116 +54,126 @@ const (
 func RegisterDockerCommands(manager *CommandManager) {
 	// 도커 설치 명령어 등록
 	manager.RegisterCommand(ActionInstallDocker, CommandTemplate{
-		ValidateFunc: validateInstallDockerParams,
+		Description:  "도커를 설치합니다",
+		Params:       dockerServerParams,
+		ValidateFunc: validateDockerServerParams,
 		PrepareFunc:  prepareInstallDockerCommands,
 	})
 
 	// 도커 상태 확인 명령어 등록
 	manager.RegisterCommand(ActionCheckDockerStatus, CommandTemplate{
+		Description:  "도커 설치 및 서비스 상태를 확인합니다",
+		Params:       dockerServerParams,
 		ValidateFunc: validateDockerServerParams,
 		PrepareFunc:  prepareCheckDockerStatusCommands,
+		RunAsRoot:    true,
 	})
 
 	// 도커 버전 확인 명령어 등록
 	manager.RegisterCommand(ActionGetDockerVersion, CommandTemplate{
+		Description:  "도커 버전을 조회합니다",
+		Params:       dockerServerParams,
 		ValidateFunc: validateDockerServerParams,
 		PrepareFunc:  prepareGetDockerVersionCommands,
+		RunAsRoot:    true,
 	})
 
 	// 컨테이너 목록 조회 명령어 등록
 	manager.RegisterCommand(ActionListContainers, CommandTemplate{
+		Description:  "컨테이너 목록을 조회합니다",
+		Params:       dockerServerParams,
 		ValidateFunc: validateDockerServerParams,
 		PrepareFunc:  prepareListContainersCommands,
+		RunAsRoot:    true,
 	})
 
 	// 이미지 목록 조회 명령어 등록
 	manager.RegisterCommand(ActionListImages, CommandTemplate{
+		Description:  "이미지 목록을 조회합니다",
+		Params:       dockerServerParams,
 		ValidateFunc: validateDockerServerParams,
 		PrepareFunc:  prepareListImagesCommands,
+		RunAsRoot:    true,
 	})
 
 	// 도커 서비스 재시작 명령어 등록
 	manager.RegisterCommand(ActionRestartDockerService, CommandTemplate{
+		Description:  "도커 서비스를 재시작합니다",
+		Params:       dockerServerParams,
 		ValidateFunc: validateDockerServerParams,
 		PrepareFunc:  prepareRestartDockerServiceCommands,
+		RunAsRoot:    true,
 	})
 }
 
-// 공통 파라미터 검증 함수
+// dockerServerParams는 도커 액션의 공통 파라미터입니다
+var dockerServerParams = ParamSchema{
+	{Name: "password", Type: ParamString, Secret: true, Description: "sudo 비밀번호 (비어 있으면 대상 hop의 비밀번호 사용)"},
+}
+
+// validateDockerServerParams는 password 파라미터가 전달되었는지 확인합니다 (빈 값 허용)
 func validateDockerServerParams(params map[string]interface{}) error {
-	// 패스워드는 필수 파라미터
 	if _, exists := params["password"]; !exists {
 		return fmt.Errorf("password 파라미터가 필요합니다")
 	}
 	return nil
 }
 
-// 도커 설치 관련 함수
-func validateInstallDockerParams(params map[string]interface{}) error {
-	// 기본 파라미터 검증
-	if err := validateDockerServerParams(params); err != nil {
-		return err
-	}
-	return nil
-}
-
 // prepareInstallDockerCommands는 도커 설치 명령어를 준비합니다
 func prepareInstallDockerCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
-
 	// 공통 설치 준비 명령어
 	prepCommands := []string{
 		// 패키지 시스템 초기화 및 손상된 패키지 수정
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update > /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y ca-certificates curl gnupg software-properties-common apt-transport-https >> /tmp/docker_install.log 2>&1", password),
+		"sudo apt-get update > /tmp/docker_install.log 2>&1",
+		"sudo apt-get install -y ca-certificates curl gnupg software-properties-common apt-transport-https >> /tmp/docker_install.log 2>&1",
 		// APT 패키지 상태 복구 명령
-		fmt.Sprintf("echo '%s' | sudo -S apt-get -f install >> /tmp/docker_install.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S dpkg --configure -a >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get -f install >> /tmp/docker_install.log 2>&1 || true",
+		"sudo dpkg --configure -a >> /tmp/docker_install.log 2>&1 || true",
 	}
 
 	// Docker 공식 설치 스크립트 사용 (가장 안정적인 방법)
 	dockerScriptCommands := []string{
 		// 기존 설치 파일 및 디렉토리 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.gpg /etc/apt/keyrings/docker.asc /tmp/docker.gpg >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo rm -f /etc/apt/sources.list.d/docker.list /etc/apt/keyrings/docker.gpg /etc/apt/keyrings/docker.asc /tmp/docker.gpg >> /tmp/docker_install.log 2>&1 || true",
 		// 기존 도커 관련 패키지 제거
-		fmt.Sprintf("echo '%s' | sudo -S apt-get remove -y docker docker-engine docker.io containerd runc >> /tmp/docker_install.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get autoremove -y >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get remove -y docker docker-engine docker.io containerd runc >> /tmp/docker_install.log 2>&1 || true",
+		"sudo apt-get autoremove -y >> /tmp/docker_install.log 2>&1 || true",
 		// APT 업데이트
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update >> /tmp/docker_install.log 2>&1 || true", password),
+		"sudo apt-get update >> /tmp/docker_install.log 2>&1 || true",
 		// Docker 공식 설치 스크립트 다운로드 및 실행
-		fmt.Sprintf("echo '%s' | sudo -S curl -fsSL https://get.docker.com -o /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S sh /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1", password),
+		"sudo curl -fsSL https://get.docker.com -o /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1",
+		"sudo sh /tmp/get-docker.sh >> /tmp/docker_install.log 2>&1",
 		// Docker 서비스 시작 및 활성화
-		fmt.Sprintf("echo '%s' | sudo -S systemctl start docker >> /tmp/docker_install.log 2>&1 || echo '%s' | sudo -S service docker start >> /tmp/docker_install.log 2>&1 || true", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable docker >> /tmp/docker_install.log 2>&1 || echo '%s' | sudo -S service docker enable >> /tmp/docker_install.log 2>&1 || true", password, password),
+		"sudo systemctl start docker >> /tmp/docker_install.log 2>&1 || sudo service docker start >> /tmp/docker_install.log 2>&1 || true",
+		"sudo systemctl enable docker >> /tmp/docker_install.log 2>&1 || sudo service docker enable >> /tmp/docker_install.log 2>&1 || true",
 		// 현재 사용자를 도커 그룹에 추가
-		fmt.Sprintf("echo '%s' | sudo -S groupadd -f docker >> /tmp/docker_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S usermod -aG docker $(whoami) >> /tmp/docker_install.log 2>&1", password),
+		"sudo groupadd -f docker >> /tmp/docker_install.log 2>&1",
+		"sudo usermod -aG docker $(whoami) >> /tmp/docker_install.log 2>&1",
 		// 설치 확인
 		"echo '도커 설치 시도 완료' >> /tmp/docker_install.log",
-		fmt.Sprintf("echo '%s' | sudo -S docker --version >> /tmp/docker_install.log 2>&1 || echo '도커 명령어 실행 실패' >> /tmp/docker_install.log", password),
+		"sudo docker --version >> /tmp/docker_install.log 2>&1 || echo '도커 명령어 실행 실패' >> /tmp/docker_install.log",
 	}
 
 	// 모든 패키지 업데이트 및 재시도
 	retryDockerCommands := []string{
 		// 패키지 업데이트 및 업그레이드
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update >> /tmp/docker_install_retry.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get upgrade -y >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo apt-get update >> /tmp/docker_install_retry.log 2>&1 || true",
+		"sudo apt-get upgrade -y >> /tmp/docker_install_retry.log 2>&1 || true",
 		// 도커 패키지 직접 설치 (표준 방식)
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y docker-ce docker-ce-cli containerd.io docker-compose-plugin >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo apt-get install -y docker-ce docker-ce-cli containerd.io docker-compose-plugin >> /tmp/docker_install_retry.log 2>&1 || true",
 		// 서비스 시작
-		fmt.Sprintf("echo '%s' | sudo -S systemctl start docker >> /tmp/docker_install_retry.log 2>&1 || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable docker >> /tmp/docker_install_retry.log 2>&1 || true", password),
+		"sudo systemctl start docker >> /tmp/docker_install_retry.log 2>&1 || true",
+		"sudo systemctl enable docker >> /tmp/docker_install_retry.log 2>&1 || true",
 	}
 
 	// 스냅 패키지 관련 명령어는 handler에서 필요할 때 직접 실행하므로 여기서는 정의하지 않음
 
 	// 설치 확인 명령어
 	checkDockerCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install.log || echo '로그 파일을 읽을 수 없습니다.'", password),
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/docker_install_retry.log 2>/dev/null || echo '재시도 로그 없음'", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl status docker 2>/dev/null || echo '%s' | sudo -S service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'", password, password),
+		"sudo docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+		"sudo cat /tmp/docker_install.log || echo '로그 파일을 읽을 수 없습니다.'",
+		"sudo cat /tmp/docker_install_retry.log 2>/dev/null || echo '재시도 로그 없음'",
+		"sudo systemctl status docker 2>/dev/null || sudo service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'",
 	}
 
 	// 모든 명령어를 하나의 슬라이스로 통합
@@ -178,14 +188,12 @@ func prepareInstallDockerCommands(params map[string]interface{}) ([]string, erro
 
 // 도커 상태 확인 관련 함수
 func prepareCheckDockerStatusCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
-
 	// 도커 상태 확인 명령어
 	statusCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl status docker 2>/dev/null || echo '%s' | sudo -S service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S docker info 2>/dev/null || echo 'DOCKER_INFO_FAILED'", password),
-		fmt.Sprintf("echo '%s' | sudo -S docker ps -a 2>/dev/null | grep -v CONTAINER | wc -l || echo '0'", password),
+		"docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+		"systemctl status docker 2>/dev/null || service docker status 2>/dev/null || echo 'docker 서비스 상태를 확인할 수 없습니다.'",
+		"docker info 2>/dev/null || echo 'DOCKER_INFO_FAILED'",
+		"docker ps -a 2>/dev/null | grep -v CONTAINER | wc -l || echo '0'",
 	}
 
 	return statusCommands, nil
@@ -193,12 +201,10 @@ func prepareCheckDockerStatusCommands(params map[string]interface{}) ([]string,
 
 // 도커 버전 확인 관련 함수
 func prepareGetDockerVersionCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
-
 	// 도커 버전 확인 명령어
 	versionCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'", password),
-		fmt.Sprintf("echo '%s' | sudo -S docker version 2>/dev/null || echo 'DOCKER_VERSION_FAILED'", password),
+		"docker --version 2>/dev/null || echo 'DOCKER_NOT_FOUND'",
+		"docker version 2>/dev/null || echo 'DOCKER_VERSION_FAILED'",
 	}
 
 	return versionCommands, nil
@@ -206,11 +212,9 @@ func prepareGetDockerVersionCommands(params map[string]interface{}) ([]string, e
 
 // 컨테이너 목록 조회 관련 함수
 func prepareListContainersCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
-
 	// 컨테이너 목록 조회 명령어 (JSON 형식)
 	listContainersCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker ps -a --format '{{json .}}' 2>/dev/null || echo 'DOCKER_PS_FAILED'", password),
+		"docker ps -a --format '{{json .}}' 2>/dev/null || echo 'DOCKER_PS_FAILED'",
 	}
 
 	return listContainersCommands, nil
@@ -218,11 +222,9 @@ func prepareListContainersCommands(params map[string]interface{}) ([]string, err
 
 // 이미지 목록 조회 관련 함수
 func prepareListImagesCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
-
 	// 이미지 목록 조회 명령어 (JSON 형식)
 	listImagesCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S docker images --format '{{json .}}' 2>/dev/null || echo 'DOCKER_IMAGES_FAILED'", password),
+		"docker images --format '{{json .}}' 2>/dev/null || echo 'DOCKER_IMAGES_FAILED'",
 	}
 
 	return listImagesCommands, nil
@@ -230,12 +232,10 @@ func prepareListImagesCommands(params map[string]interface{}) ([]string, error)
 
 // 도커 서비스 재시작 관련 함수
 func prepareRestartDockerServiceCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
-
 	// 도커 서비스 재시작 명령어
 	restartCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S systemctl restart docker >> /tmp/docker_restart.log 2>&1 || echo '%s' | sudo -S service docker restart >> /tmp/docker_restart.log 2>&1", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S docker info 2>/dev/null || echo 'DOCKER_INFO_FAILED'", password),
+		"systemctl restart docker >> /tmp/docker_restart.log 2>&1 || service docker restart >> /tmp/docker_restart.log 2>&1",
+		"docker info 2>/dev/null || echo 'DOCKER_INFO_FAILED'",
 	}
 
 	return restartCommands, nil
@@ -0,0 +1,164 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// DefaultFanOutConcurrency는 동시 실행 수가 지정되지 않았을 때 사용하는 기본값입니다
+const DefaultFanOutConcurrency = 10
+
+// fanOutLimits는 SetFanOutLimits로 설정한 기본 동시 실행 수와 최대 동시 실행 수입니다 (최대 0은 제한 없음)
+var fanOutLimits = struct {
+	sync.RWMutex
+	def, max int
+}{def: DefaultFanOutConcurrency}
+
+// SetFanOutLimits는 동시 실행 수를 지정하지 않았을 때의 기본값과 요청할 수 있는 최대값을 설정합니다
+// max가 0이면 최대값을 제한하지 않습니다
+func SetFanOutLimits(def, max int) {
+	fanOutLimits.Lock()
+	defer fanOutLimits.Unlock()
+	if def > 0 {
+		fanOutLimits.def = def
+	}
+	fanOutLimits.max = max
+}
+
+// FanOutOptions는 여러 대상에 대한 병렬 실행 옵션을 정의합니다
+type FanOutOptions struct {
+	Concurrency int           // 동시에 실행할 최대 대상 수 (0 이하이면 SetFanOutLimits의 기본값)
+	HostTimeout time.Duration // 대상별 전체 실행 타임아웃 (0이면 요청 컨텍스트만 따름)
+}
+
+// FanOutTarget은 병렬 실행의 개별 대상입니다
+type FanOutTarget struct {
+	Name   string                 // 결과에 표시할 대상 이름 (예: 서버 이름)
+	Target *CommandTarget         // 명령어 실행 대상
+	Params map[string]interface{} // 대상별로 공통 파라미터를 덮어쓸 값 (예: server_name)
+}
+
+// HostResult는 한 대상에 대한 실행 결과입니다
+// 한 대상의 실패는 Error에만 기록되며 다른 대상의 실행에는 영향을 주지 않습니다
+type HostResult struct {
+	Name       string              `json:"name"`
+	Host       string              `json:"host"`
+	Success    bool                `json:"success"`
+	Results    []ssh.CommandResult `json:"results,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	ErrorType  string              `json:"error_type,omitempty"`
+	DurationMs int64               `json:"duration_ms"`
+	Err        error               `json:"-"`
+}
+
+// ExecuteActionFanOut은 하나의 액션을 여러 대상에서 병렬로 실행하고 대상별 결과를 반환합니다
+// 결과는 targets와 같은 순서로 반환되며, 일부 대상이 실패해도 나머지 대상의 실행은 계속됩니다
+func (cm *CommandManager) ExecuteActionFanOut(ctx context.Context, action string, params map[string]interface{}, targets []FanOutTarget, opts FanOutOptions) []HostResult {
+	log.Printf("[CommandManager] 액션 병렬 실행: %s, 대상 %d개, 동시 실행 %d", action, len(targets), opts.concurrency())
+
+	return cm.fanOut(ctx, targets, opts, func(ctx context.Context, target FanOutTarget) ([]ssh.CommandResult, error) {
+		return cm.ExecuteAction(ctx, action, mergeParams(params, target.Params), target.Target)
+	})
+}
+
+// ExecuteCustomCommandsFanOut은 주어진 명령어를 여러 대상에서 병렬로 실행하고 대상별 결과를 반환합니다
+func (cm *CommandManager) ExecuteCustomCommandsFanOut(ctx context.Context, targets []FanOutTarget, commands []string, opts FanOutOptions) []HostResult {
+	log.Printf("[CommandManager] 커스텀 명령어 병렬 실행: %d개의 명령어, 대상 %d개, 동시 실행 %d", len(commands), len(targets), opts.concurrency())
+
+	return cm.fanOut(ctx, targets, opts, func(ctx context.Context, target FanOutTarget) ([]ssh.CommandResult, error) {
+		return cm.ExecuteCustomCommands(ctx, target.Target, commands)
+	})
+}
+
+// fanOut은 동시 실행 수를 제한하면서 각 대상에 대해 run을 실행합니다
+func (cm *CommandManager) fanOut(ctx context.Context, targets []FanOutTarget, opts FanOutOptions, run func(ctx context.Context, target FanOutTarget) ([]ssh.CommandResult, error)) []HostResult {
+	results := make([]HostResult, len(targets))
+	sem := make(chan struct{}, opts.concurrency())
+	var wg sync.WaitGroup
+
+	startTime := time.Now()
+	for i, target := range targets {
+		results[i] = HostResult{Name: target.Name, Host: target.Target.GetDescription()}
+
+		// 요청이 취소되면 아직 시작하지 않은 대상은 실행하지 않음
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].setError(fmt.Errorf("실행 전에 요청이 취소되었습니다: %w", ctx.Err()))
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, target FanOutTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			hostCtx := ctx
+			if opts.HostTimeout > 0 {
+				var cancel context.CancelFunc
+				hostCtx, cancel = context.WithTimeout(ctx, opts.HostTimeout)
+				defer cancel()
+			}
+
+			hostStart := time.Now()
+			commandResults, err := run(hostCtx, target)
+			results[i].DurationMs = time.Since(hostStart).Milliseconds()
+			if err != nil {
+				log.Printf("[CommandManager] 대상 %s(%s) 실행 실패: %v", target.Name, results[i].Host, err)
+				results[i].setError(err)
+				return
+			}
+			results[i].Success = true
+			results[i].Results = commandResults
+		}(i, target)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	log.Printf("[CommandManager] 병렬 실행 완료 (소요시간: %v): 성공 %d개, 실패 %d개", time.Since(startTime), len(results)-failed, failed)
+	return results
+}
+
+// setError는 실행 오류를 결과에 기록합니다
+func (r *HostResult) setError(err error) {
+	r.Success = false
+	r.Err = err
+	r.Error = err.Error()
+	r.ErrorType = string(ssh.TypeOf(err))
+}
+
+// concurrency는 유효한 동시 실행 수를 반환합니다. 최대값을 넘으면 최대값으로 줄입니다
+func (o FanOutOptions) concurrency() int {
+	fanOutLimits.RLock()
+	defer fanOutLimits.RUnlock()
+	n := o.Concurrency
+	if n <= 0 {
+		n = fanOutLimits.def
+	}
+	if fanOutLimits.max > 0 && n > fanOutLimits.max {
+		n = fanOutLimits.max
+	}
+	return n
+}
+
+// mergeParams는 공통 파라미터에 대상별 파라미터를 덮어쓴 새 맵을 반환합니다
+func mergeParams(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,647 @@
+package command
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/redact"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// JobState는 비동기 작업의 상태입니다
+type JobState string
+
+const (
+	JobQueued      JobState = "queued"      // 생성되어 실행을 기다리는 중
+	JobRunning     JobState = "running"     // 실행 중
+	JobSucceeded   JobState = "succeeded"   // 성공
+	JobFailed      JobState = "failed"      // 실패
+	JobCanceled    JobState = "canceled"    // 사용자 요청으로 취소됨
+	JobInterrupted JobState = "interrupted" // 실행하던 백엔드 인스턴스가 종료되어 중단됨
+)
+
+// Finished는 더 이상 상태가 바뀌지 않는 종료 상태인지 확인합니다
+func (s JobState) Finished() bool {
+	switch s {
+	case JobSucceeded, JobFailed, JobCanceled, JobInterrupted:
+		return true
+	}
+	return false
+}
+
+// 작업 조회/취소 오류
+var (
+	ErrJobNotFound = errors.New("작업을 찾을 수 없습니다")
+	ErrJobFinished = errors.New("이미 종료된 작업입니다")
+	ErrJobNotLocal = errors.New("다른 백엔드 인스턴스에서 실행 중인 작업이므로 해당 인스턴스에서만 취소할 수 있습니다")
+)
+
+const (
+	// JobHeartbeatInterval은 실행 중인 작업의 heartbeat를 갱신하는 주기입니다
+	JobHeartbeatInterval = 10 * time.Second
+	// JobStaleAfter는 heartbeat가 이 시간 동안 갱신되지 않은 작업을 실행하던 인스턴스가 종료된 것으로 보는 기준입니다
+	JobStaleAfter = 3 * JobHeartbeatInterval
+)
+
+// JobStep은 작업 중 실행된 원격 명령어 하나의 결과입니다
+type JobStep struct {
+	Index      int        `json:"index"`
+	Host       string     `json:"host,omitempty"`
+	Command    string     `json:"command"`
+	State      JobState   `json:"state"` // running, succeeded, failed
+	Output     string     `json:"output"`
+	Error      string     `json:"error"`
+	ExitCode   int        `json:"exit_code"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Job은 백그라운드에서 실행되는 장시간 작업입니다
+// 요청 파라미터는 비밀번호를 포함할 수 있으므로 저장하지 않습니다
+type Job struct {
+	ID        string          `json:"id"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target,omitempty"`
+	InfraID   int             `json:"infra_id,omitempty"` // 작업 대상 인프라 (권한 확인에 사용하며, 0이면 전역 역할이 있어야 조회 가능)
+	State     JobState        `json:"state"`
+	Steps     []JobStep       `json:"steps"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorType string          `json:"error_type,omitempty"`
+	// Owner는 작업을 실행하는 백엔드 인스턴스 ID이며, HeartbeatAt은 그 인스턴스가 작업이 살아 있음을 마지막으로 기록한 시각입니다
+	Owner       string     `json:"owner,omitempty"`
+	HeartbeatAt time.Time  `json:"heartbeat_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// clone은 steps 슬라이스까지 복사한 Job을 반환합니다
+func (j Job) clone() Job {
+	j.Steps = append([]JobStep(nil), j.Steps...)
+	return j
+}
+
+// JobFilter는 작업 목록 조회 조건입니다
+type JobFilter struct {
+	State  JobState // 비어 있으면 전체
+	Action string   // 비어 있으면 전체
+	Limit  int      // 0 이하이면 DefaultJobListLimit
+	// InfraIDs가 nil이 아니면 해당 인프라의 작업만 조회합니다 (빈 슬라이스이면 조회 결과 없음)
+	InfraIDs []int
+}
+
+// matchesInfra는 작업이 인프라 조건에 맞는지 확인합니다
+func (f JobFilter) matchesInfra(infraID int) bool {
+	if f.InfraIDs == nil {
+		return true
+	}
+	for _, id := range f.InfraIDs {
+		if id == infraID {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultJobListLimit는 작업 목록 조회 시 기본 최대 개수입니다
+const DefaultJobListLimit = 50
+
+// limit은 유효한 조회 개수를 반환합니다
+func (f JobFilter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultJobListLimit
+	}
+	return f.Limit
+}
+
+// JobStore는 작업 상태를 저장하는 저장소입니다
+type JobStore interface {
+	// SaveJob은 작업을 새로 저장하거나 같은 ID의 작업을 갱신합니다
+	SaveJob(job Job) error
+	// GetJob은 작업을 조회합니다. 없으면 ErrJobNotFound를 반환합니다
+	GetJob(id string) (Job, error)
+	// ListJobs는 조건에 맞는 작업을 최근 생성 순으로 반환합니다
+	ListJobs(filter JobFilter) ([]Job, error)
+	// TouchJobs는 owner 인스턴스의 종료되지 않은 작업의 heartbeat를 at으로 갱신합니다
+	TouchJobs(owner string, at time.Time) error
+	// MarkInterruptedJobs는 대기 또는 실행 중으로 남아 있지만 heartbeat가 staleBefore보다 오래된 작업을
+	// 중단 상태로 바꾸고 바뀐 개수를 반환합니다
+	MarkInterruptedJobs(staleBefore time.Time) (int64, error)
+}
+
+// MemoryJobStore는 메모리에 작업을 저장하는 JobStore 구현체입니다 (테스트 및 DB 미사용 환경용)
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// NewMemoryJobStore는 빈 MemoryJobStore를 생성합니다
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]Job)}
+}
+
+// SaveJob은 작업을 저장합니다
+func (s *MemoryJobStore) SaveJob(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job.clone()
+	return nil
+}
+
+// GetJob은 작업을 조회합니다
+func (s *MemoryJobStore) GetJob(id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	return job.clone(), nil
+}
+
+// ListJobs는 조건에 맞는 작업 목록을 반환합니다
+func (s *MemoryJobStore) ListJobs(filter JobFilter) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []Job
+	for _, job := range s.jobs {
+		if (filter.State == "" || job.State == filter.State) && (filter.Action == "" || job.Action == filter.Action) && filter.matchesInfra(job.InfraID) {
+			jobs = append(jobs, job.clone())
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	if len(jobs) > filter.limit() {
+		jobs = jobs[:filter.limit()]
+	}
+	return jobs, nil
+}
+
+// TouchJobs는 owner의 종료되지 않은 작업의 heartbeat를 갱신합니다
+func (s *MemoryJobStore) TouchJobs(owner string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, job := range s.jobs {
+		if job.Owner == owner && !job.State.Finished() {
+			job.HeartbeatAt = at
+			s.jobs[id] = job
+		}
+	}
+	return nil
+}
+
+// MarkInterruptedJobs는 heartbeat가 staleBefore보다 오래된 종료되지 않은 작업을 중단 상태로 바꿉니다
+func (s *MemoryJobStore) MarkInterruptedJobs(staleBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	now := time.Now()
+	for id, job := range s.jobs {
+		if !job.State.Finished() && job.HeartbeatAt.Before(staleBefore) {
+			job.State = JobInterrupted
+			job.Error = JobInterruptedMessage
+			job.FinishedAt = &now
+			job.UpdatedAt = now
+			s.jobs[id] = job
+			count++
+		}
+	}
+	return count, nil
+}
+
+// JobInterruptedMessage는 실행하던 인스턴스가 종료되어 중단된 작업에 기록하는 오류 메시지입니다
+const JobInterruptedMessage = "작업을 실행하던 백엔드 인스턴스가 종료되어 작업이 중단되었습니다"
+
+// JobFunc는 작업으로 실행할 함수입니다
+// ctx는 작업이 취소되면 취소되며, 반환한 값은 JSON으로 변환되어 작업 결과로 저장됩니다
+// ctx로 실행한 원격 명령어는 자동으로 작업 단계로 기록됩니다
+type JobFunc func(ctx context.Context) (interface{}, error)
+
+// JobManager는 작업을 백그라운드에서 실행하고 상태를 저장소에 기록합니다
+// 여러 백엔드 인스턴스가 저장소를 공유하므로 작업에는 실행하는 인스턴스 ID(Owner)와 heartbeat를 기록합니다
+type JobManager struct {
+	mu       sync.Mutex
+	store    JobStore
+	instance string
+	running  map[string]*runningJob
+	streams  map[string]*jobStream
+}
+
+// runningJob은 실행 중인 작업의 취소 함수와 종료 알림 채널입니다
+type runningJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewJobManager는 store를 사용하는 JobManager를 생성합니다
+func NewJobManager(store JobStore) *JobManager {
+	return &JobManager{
+		store:    store,
+		instance: newInstanceID(),
+		running:  make(map[string]*runningJob),
+		streams:  make(map[string]*jobStream),
+	}
+}
+
+// InstanceID는 이 JobManager가 실행하는 작업의 Owner로 기록하는 인스턴스 ID입니다
+func (m *JobManager) InstanceID() string {
+	return m.instance
+}
+
+var defaultJobManager = NewJobManager(NewMemoryJobStore())
+
+// DefaultJobManager는 애플리케이션 전체에서 공유하는 JobManager를 반환합니다
+func DefaultJobManager() *JobManager {
+	return defaultJobManager
+}
+
+// SetJobStore는 공유 JobManager가 사용할 저장소를 설정합니다
+// 작업을 실행하기 전, 애플리케이션 시작 시 호출해야 합니다
+func SetJobStore(store JobStore) {
+	defaultJobManager.mu.Lock()
+	defer defaultJobManager.mu.Unlock()
+	defaultJobManager.store = store
+}
+
+// getStore는 현재 저장소를 반환합니다
+func (m *JobManager) getStore() JobStore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store
+}
+
+// Start는 run을 백그라운드 작업으로 실행하고 생성된 작업을 즉시 반환합니다
+// target은 작업 목록에 표시할 대상 설명이며, infraID는 작업 조회 권한을 확인할 대상 인프라입니다 (없으면 0)
+func (m *JobManager) Start(action, target string, infraID int, run JobFunc) (Job, error) {
+	now := time.Now()
+	job := Job{
+		ID:          newJobID(),
+		Action:      action,
+		Target:      target,
+		InfraID:     infraID,
+		State:       JobQueued,
+		Steps:       []JobStep{},
+		Owner:       m.instance,
+		HeartbeatAt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := m.getStore().SaveJob(job); err != nil {
+		return Job{}, fmt.Errorf("작업 저장 실패: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rj := &runningJob{cancel: cancel, done: make(chan struct{})}
+	m.mu.Lock()
+	m.running[job.ID] = rj
+	m.mu.Unlock()
+
+	stream := m.openStream(job.ID)
+	stream.publish(JobEvent{Type: JobEventState, State: JobQueued, Time: now})
+
+	log.Printf("[JobManager] 작업 생성: %s (액션: %s, 대상: %s)", job.ID, action, target)
+	go m.run(ctx, rj, stream, job, run)
+	return job, nil
+}
+
+// run은 작업을 실행하고 단계별 진행 상황과 최종 결과를 저장합니다
+func (m *JobManager) run(ctx context.Context, rj *runningJob, stream *jobStream, job Job, run JobFunc) {
+	defer close(rj.done)
+	defer func() {
+		m.mu.Lock()
+		delete(m.running, job.ID)
+		m.mu.Unlock()
+		rj.cancel()
+		m.closeStream(job.ID, stream)
+	}()
+
+	rec := &jobRecorder{manager: m, stream: stream, job: job}
+	rec.update(func(j *Job) {
+		now := time.Now()
+		j.State = JobRunning
+		j.StartedAt = &now
+	})
+	stream.publish(JobEvent{Type: JobEventState, State: JobRunning})
+
+	result, err := runJobFunc(ssh.WithOutputHandler(context.WithValue(ctx, jobIDKey{}, job.ID), rec.handle), run)
+
+	rec.update(func(j *Job) {
+		now := time.Now()
+		j.FinishedAt = &now
+		if result != nil {
+			if data, marshalErr := json.Marshal(result); marshalErr == nil {
+				j.Result = (*redact.Masker)(nil).JSON(data)
+			}
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			j.State = JobCanceled
+			j.Error = "작업이 취소되었습니다"
+			j.ErrorType = string(ssh.CommandCanceled)
+		case err != nil:
+			j.State = JobFailed
+			j.Error = redact.String(err.Error())
+			j.ErrorType = string(ssh.TypeOf(err))
+		default:
+			j.State = JobSucceeded
+		}
+	})
+
+	final := rec.snapshot()
+	stream.publish(JobEvent{Type: JobEventState, State: final.State, Error: final.Error})
+	log.Printf("[JobManager] 작업 종료: %s (상태: %s, 단계 %d개)", final.ID, final.State, len(final.Steps))
+}
+
+// runJobFunc는 run을 실행하며, panic이 발생하면 작업 실패로 처리합니다
+func runJobFunc(ctx context.Context, run JobFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("작업 실행 중 panic 발생: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// Get은 작업을 조회합니다
+func (m *JobManager) Get(id string) (Job, error) {
+	return m.getStore().GetJob(id)
+}
+
+// List는 조건에 맞는 작업 목록을 조회합니다
+func (m *JobManager) List(filter JobFilter) ([]Job, error) {
+	return m.getStore().ListJobs(filter)
+}
+
+// Cancel은 실행 중인 작업을 취소합니다
+// 실행 중인 원격 명령어는 프로세스 그룹째 종료되며, 작업 상태는 canceled로 기록됩니다
+func (m *JobManager) Cancel(id string) error {
+	m.mu.Lock()
+	rj, ok := m.running[id]
+	m.mu.Unlock()
+
+	if !ok {
+		job, err := m.Get(id)
+		if err != nil {
+			return err
+		}
+		if job.State.Finished() {
+			return ErrJobFinished
+		}
+		// 저장소에는 실행 중으로 남아 있지만 다른 인스턴스가 실행 중인 작업
+		return fmt.Errorf("%w (인스턴스: %s)", ErrJobNotLocal, job.Owner)
+	}
+
+	log.Printf("[JobManager] 작업 취소 요청: %s", id)
+	rj.cancel()
+	<-rj.done
+	return nil
+}
+
+// Wait는 작업이 끝날 때까지 기다린 뒤 최종 상태를 반환합니다
+// ctx가 먼저 끝나면 현재 상태와 ctx 오류를 반환합니다
+func (m *JobManager) Wait(ctx context.Context, id string) (Job, error) {
+	m.mu.Lock()
+	rj, ok := m.running[id]
+	m.mu.Unlock()
+
+	if ok {
+		select {
+		case <-rj.done:
+		case <-ctx.Done():
+			job, _ := m.Get(id)
+			return job, ctx.Err()
+		}
+	}
+	return m.Get(id)
+}
+
+// RecoverInterrupted는 heartbeat가 JobStaleAfter 이상 갱신되지 않은 작업을 중단 상태로 표시합니다
+// 다른 인스턴스에서 실행 중인 작업은 heartbeat가 계속 갱신되므로 건드리지 않습니다
+func (m *JobManager) RecoverInterrupted() (int64, error) {
+	count, err := m.getStore().MarkInterruptedJobs(time.Now().Add(-JobStaleAfter))
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		log.Printf("[JobManager] 실행하던 인스턴스가 종료되어 중단된 작업 %d개를 interrupted로 표시했습니다", count)
+	}
+	return count, nil
+}
+
+// RunHeartbeat는 ctx가 끝날 때까지 JobHeartbeatInterval마다 이 인스턴스가 실행 중인 작업의 heartbeat를 갱신하고,
+// 종료된 다른 인스턴스가 남긴 작업을 중단 상태로 표시합니다
+func (m *JobManager) RunHeartbeat(ctx context.Context) {
+	ticker := time.NewTicker(JobHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := m.getStore().TouchJobs(m.instance, time.Now()); err != nil {
+			log.Printf("[JobManager] 작업 heartbeat 갱신 실패: %v", err)
+		}
+		if _, err := m.RecoverInterrupted(); err != nil {
+			log.Printf("[JobManager] 중단된 작업 표시 실패: %v", err)
+		}
+	}
+}
+
+// jobRecorder는 작업의 원격 명령어 실행 이벤트를 단계로 기록합니다
+// 출력은 메모리에 누적하고, 명령어 시작/종료 시점에만 저장소에 반영합니다
+type jobRecorder struct {
+	manager *JobManager
+	stream  *jobStream
+	mu      sync.Mutex
+	job     Job
+	open    map[string]int // host/명령어 순번별 진행 중인 단계 위치
+}
+
+// handle은 ssh.OutputHandler로 사용되는 이벤트 처리 함수입니다
+// 단계 기록과 함께 구독자에게 진행 이벤트를 전달하며, 명령어와 출력의 비밀값은 기록 전에 가립니다
+func (r *jobRecorder) handle(event ssh.OutputEvent) {
+	event.Command = redact.String(event.Command)
+	event.Line = redact.String(event.Line)
+	key := fmt.Sprintf("%s/%d", event.Host, event.CommandIndex)
+
+	switch event.Type {
+	case ssh.EventCommandStart:
+		var index int
+		r.update(func(j *Job) {
+			if r.open == nil {
+				r.open = make(map[string]int)
+			}
+			index = len(j.Steps)
+			r.open[key] = index
+			j.Steps = append(j.Steps, JobStep{
+				Index:     index,
+				Host:      event.Host,
+				Command:   event.Command,
+				State:     JobRunning,
+				StartedAt: event.Time,
+			})
+		})
+		r.stream.publish(JobEvent{Type: JobEventStepStart, Step: index, Host: event.Host, Command: event.Command, Time: event.Time})
+	case ssh.EventOutput:
+		r.mu.Lock()
+		i, ok := r.open[key]
+		if ok {
+			step := &r.job.Steps[i]
+			if event.Stream == ssh.StreamStderr {
+				step.Error += event.Line + "\n"
+			} else {
+				step.Output += event.Line + "\n"
+			}
+		}
+		r.mu.Unlock()
+		if ok {
+			r.stream.publish(JobEvent{Type: JobEventOutput, Step: i, Host: event.Host, Command: event.Command, Stream: event.Stream, Line: event.Line, Time: event.Time})
+		}
+	case ssh.EventCommandExit:
+		var step JobStep
+		found := false
+		r.update(func(j *Job) {
+			i, ok := r.open[key]
+			if !ok {
+				return
+			}
+			delete(r.open, key)
+			finishedAt := event.Time
+			j.Steps[i].ExitCode = event.ExitCode
+			j.Steps[i].FinishedAt = &finishedAt
+			j.Steps[i].State = JobSucceeded
+			if event.ExitCode != 0 {
+				j.Steps[i].State = JobFailed
+			}
+			step, found = j.Steps[i], true
+		})
+		if found {
+			r.stream.publish(JobEvent{Type: JobEventStepFinish, Step: step.Index, Host: step.Host, Command: step.Command, State: step.State, ExitCode: step.ExitCode, Time: event.Time})
+		}
+	}
+}
+
+// update는 작업을 변경하고 저장소에 저장합니다
+func (r *jobRecorder) update(fn func(j *Job)) {
+	r.mu.Lock()
+	fn(&r.job)
+	r.job.UpdatedAt = time.Now()
+	r.job.HeartbeatAt = r.job.UpdatedAt
+
+	// 작업이 끝났는데 종료 이벤트를 받지 못한 단계는 취소 또는 실패로 닫습니다
+	if r.job.State.Finished() {
+		for i := range r.job.Steps {
+			if r.job.Steps[i].State == JobRunning {
+				r.job.Steps[i].State = r.job.State
+				r.job.Steps[i].FinishedAt = r.job.FinishedAt
+			}
+		}
+	}
+	job := r.job.clone()
+	r.mu.Unlock()
+
+	if err := r.manager.getStore().SaveJob(job); err != nil {
+		log.Printf("[JobManager] 작업 %s 상태 저장 실패: %v", job.ID, err)
+	}
+}
+
+// snapshot은 현재 작업 상태의 복사본을 반환합니다
+func (r *jobRecorder) snapshot() Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.job.clone()
+}
+
+// jobIDKey는 작업 함수의 컨텍스트에 작업 ID를 저장할 때 사용하는 키입니다
+type jobIDKey struct{}
+
+// JobIDFromContext는 ctx로 실행 중인 작업의 ID를 반환합니다. 작업 컨텍스트가 아니면 빈 문자열입니다
+func JobIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(jobIDKey{}).(string)
+	return id
+}
+
+// newInstanceID는 호스트 이름과 무작위 값으로 백엔드 인스턴스 ID를 생성합니다
+// 같은 호스트에서 재시작한 프로세스도 이전 프로세스와 구별됩니다
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "k8scontrol"
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
+
+// newJobID는 무작위 작업 ID를 생성합니다
+func newJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// EnqueueAction은 액션을 백그라운드 작업으로 실행하고 작업 정보를 즉시 반환합니다
+// 명령어 준비와 파라미터 검증은 호출 시점에 수행하므로 잘못된 요청은 작업을 만들지 않고 오류를 반환합니다
+// 원격 명령어 중 하나라도 0이 아닌 종료 코드로 끝나면 작업은 실패로 기록됩니다
+func (cm *CommandManager) EnqueueAction(action string, params map[string]interface{}, target *CommandTarget) (Job, error) {
+	if _, err := cm.PrepareAction(action, params); err != nil {
+		return Job{}, err
+	}
+	if target == nil || len(target.Hops) == 0 {
+		return Job{}, fmt.Errorf("대상 서버 정보가 없습니다")
+	}
+
+	return cm.jobManager().Start(action, target.GetDescription(), target.InfraID, func(ctx context.Context) (interface{}, error) {
+		results, err := cm.ExecuteAction(ctx, action, params, target)
+		if err != nil {
+			return results, err
+		}
+		// 단계 템플릿은 단계별 성공 조건을 이미 확인했으므로 종료 코드를 다시 검사하지 않습니다
+		if cm.commandMap[action].StepsFunc != nil {
+			return results, nil
+		}
+		return results, ssh.CheckResults(target.Hops[len(target.Hops)-1].Host, results)
+	})
+}
+
+// SetJobManager는 EnqueueAction이 사용할 JobManager를 지정합니다. nil이면 DefaultJobManager를 사용합니다
+func (cm *CommandManager) SetJobManager(m *JobManager) {
+	cm.jobs = m
+}
+
+// jobManager는 CommandManager가 사용할 JobManager를 반환합니다
+func (cm *CommandManager) jobManager() *JobManager {
+	if cm.jobs == nil {
+		return DefaultJobManager()
+	}
+	return cm.jobs
+}
+
+// ParseJobState는 문자열을 JobState로 변환합니다. 알 수 없는 값이면 오류를 반환합니다
+func ParseJobState(s string) (JobState, error) {
+	state := JobState(strings.ToLower(strings.TrimSpace(s)))
+	switch state {
+	case "", JobQueued, JobRunning, JobSucceeded, JobFailed, JobCanceled, JobInterrupted:
+		return state, nil
+	}
+	return "", fmt.Errorf("알 수 없는 작업 상태입니다: %s", s)
+}
@@ -0,0 +1,217 @@
+package command
+
+import (
+	"sync"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// JobEventType은 작업 진행 이벤트 종류입니다
+type JobEventType string
+
+const (
+	JobEventState      JobEventType = "state"       // 작업 상태 변경 (queued, running, 종료 상태)
+	JobEventStepStart  JobEventType = "step_start"  // 원격 명령어 실행 시작
+	JobEventOutput     JobEventType = "output"      // stdout/stderr 한 줄 출력
+	JobEventStepFinish JobEventType = "step_finish" // 원격 명령어 종료 (ExitCode 포함)
+)
+
+// JobEvent는 작업 진행 상황을 구독자에게 전달하는 이벤트입니다
+// Seq는 작업 안에서 1부터 증가하며, 재연결 시 마지막으로 받은 Seq 이후의 이벤트만 다시 받을 수 있습니다
+type JobEvent struct {
+	Seq      int64        `json:"seq"`
+	JobID    string       `json:"job_id"`
+	Type     JobEventType `json:"type"`
+	State    JobState     `json:"state,omitempty"` // state 이벤트는 작업 상태, step_finish 이벤트는 단계 상태
+	Step     int          `json:"step"`            // 단계 이벤트의 단계 순번
+	Host     string       `json:"host,omitempty"`
+	Command  string       `json:"command,omitempty"`
+	Stream   string       `json:"stream,omitempty"`
+	Line     string       `json:"line,omitempty"`
+	ExitCode int          `json:"exit_code"`
+	Error    string       `json:"error,omitempty"`
+	Time     time.Time    `json:"time"`
+}
+
+const (
+	// JobReplayBufferSize는 늦게 구독한 클라이언트에게 다시 보내기 위해 보관하는 최근 이벤트 수입니다
+	JobReplayBufferSize = 5000
+	// JobEventRetention은 작업이 끝난 뒤 재생 버퍼를 보관하는 시간입니다
+	// 이후에는 저장소의 작업 정보로 단계 이벤트를 다시 만들어 전달합니다
+	JobEventRetention = 10 * time.Minute
+	// jobSubscriberBuffer는 구독자별 채널 크기입니다. 가득 차면 구독이 끊어지므로 클라이언트는 재연결해야 합니다
+	jobSubscriberBuffer = 256
+)
+
+// jobStream은 한 작업의 이벤트 재생 버퍼와 구독자 목록입니다
+type jobStream struct {
+	mu      sync.Mutex
+	jobID   string
+	seq     int64
+	events  []JobEvent
+	subs    map[chan JobEvent]struct{}
+	closed  bool
+	maxSize int
+}
+
+// newJobStream은 빈 이벤트 스트림을 생성합니다
+func newJobStream(jobID string) *jobStream {
+	return &jobStream{
+		jobID:   jobID,
+		subs:    make(map[chan JobEvent]struct{}),
+		maxSize: JobReplayBufferSize,
+	}
+}
+
+// publish는 이벤트에 순번을 붙여 재생 버퍼에 저장하고 구독자에게 전달합니다
+// 이벤트를 제때 받지 못하는 구독자는 채널을 닫아 연결을 끊습니다
+func (s *jobStream) publish(event JobEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	s.seq++
+	event.Seq = s.seq
+	event.JobID = s.jobID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	s.events = append(s.events, event)
+	if len(s.events) > s.maxSize {
+		s.events = append(s.events[:0:0], s.events[len(s.events)-s.maxSize:]...)
+	}
+
+	for ch := range s.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// close는 스트림을 종료하고 모든 구독자 채널을 닫습니다
+func (s *jobStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for ch := range s.subs {
+		close(ch)
+	}
+	s.subs = nil
+}
+
+// subscribe는 afterSeq 이후의 재생 이벤트와 이후 이벤트를 받을 채널을 반환합니다
+// 스트림이 이미 닫혔으면 닫힌 채널을 반환합니다
+func (s *jobStream) subscribe(afterSeq int64) ([]JobEvent, <-chan JobEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var replay []JobEvent
+	for _, event := range s.events {
+		if event.Seq > afterSeq {
+			replay = append(replay, event)
+		}
+	}
+
+	ch := make(chan JobEvent, jobSubscriberBuffer)
+	if s.closed {
+		close(ch)
+		return replay, ch, func() {}
+	}
+
+	s.subs[ch] = struct{}{}
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[ch]; ok {
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+	return replay, ch, unsubscribe
+}
+
+// Subscribe는 작업 이벤트를 구독합니다
+// afterSeq보다 큰 순번의 보관된 이벤트를 replay로 먼저 반환하고, 이후 이벤트는 events 채널로 전달합니다
+// 작업이 끝나거나 구독자가 이벤트를 제때 받지 못하면 events 채널이 닫히며, 구독을 마치면 unsubscribe를 호출해야 합니다
+// 재생 버퍼가 없는 작업(보관 시간이 지났거나 다른 프로세스에서 실행된 작업)은 저장된 작업 정보로 이벤트를 만들어 반환합니다
+func (m *JobManager) Subscribe(id string, afterSeq int64) (replay []JobEvent, events <-chan JobEvent, unsubscribe func(), err error) {
+	m.mu.Lock()
+	stream, ok := m.streams[id]
+	m.mu.Unlock()
+	if ok {
+		replay, events, unsubscribe = stream.subscribe(afterSeq)
+		return replay, events, unsubscribe, nil
+	}
+
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, event := range jobEventsFromJob(job) {
+		// 재생 버퍼와 순번이 다를 수 있으므로 최종 상태 이벤트는 항상 전달합니다
+		if event.Seq > afterSeq || event.Type == JobEventState {
+			replay = append(replay, event)
+		}
+	}
+	closed := make(chan JobEvent)
+	close(closed)
+	return replay, closed, func() {}, nil
+}
+
+// openStream은 작업의 이벤트 스트림을 생성합니다
+func (m *JobManager) openStream(id string) *jobStream {
+	stream := newJobStream(id)
+	m.mu.Lock()
+	m.streams[id] = stream
+	m.mu.Unlock()
+	return stream
+}
+
+// closeStream은 작업의 이벤트 스트림을 닫고 보관 시간이 지나면 재생 버퍼를 삭제합니다
+func (m *JobManager) closeStream(id string, stream *jobStream) {
+	stream.close()
+	time.AfterFunc(JobEventRetention, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.streams[id] == stream {
+			delete(m.streams, id)
+		}
+	})
+}
+
+// jobEventsFromJob은 저장된 작업 정보로 단계 시작/종료와 최종 상태 이벤트를 만듭니다
+// 출력은 단계별로 누적된 내용을 한 번에 담은 output 이벤트로 전달합니다
+func jobEventsFromJob(job Job) []JobEvent {
+	var events []JobEvent
+	add := func(event JobEvent) {
+		event.Seq = int64(len(events) + 1)
+		event.JobID = job.ID
+		events = append(events, event)
+	}
+
+	for _, step := range job.Steps {
+		add(JobEvent{Type: JobEventStepStart, Step: step.Index, Host: step.Host, Command: step.Command, Time: step.StartedAt})
+		if step.Output != "" {
+			add(JobEvent{Type: JobEventOutput, Step: step.Index, Host: step.Host, Command: step.Command, Stream: ssh.StreamStdout, Line: step.Output, Time: step.StartedAt})
+		}
+		if step.Error != "" {
+			add(JobEvent{Type: JobEventOutput, Step: step.Index, Host: step.Host, Command: step.Command, Stream: ssh.StreamStderr, Line: step.Error, Time: step.StartedAt})
+		}
+		if step.FinishedAt != nil {
+			add(JobEvent{Type: JobEventStepFinish, Step: step.Index, Host: step.Host, Command: step.Command, State: step.State, ExitCode: step.ExitCode, Time: *step.FinishedAt})
+		}
+	}
+
+	add(JobEvent{Type: JobEventState, State: job.State, Error: job.Error, Time: job.UpdatedAt})
+	return events
+}
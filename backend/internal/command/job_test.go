@@ -0,0 +1,230 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+// newJobTestManager는 독립된 메모리 저장소를 사용하는 CommandManager를 생성합니다
+func newJobTestManager(t *testing.T, commands ...string) (*CommandManager, *JobManager) {
+	t.Helper()
+	cm := NewCommandManager()
+	cm.RegisterCommand("testAction", CommandTemplate{Commands: commands})
+	jobs := NewJobManager(NewMemoryJobStore())
+	cm.SetJobManager(jobs)
+	return cm, jobs
+}
+
+// waitJob은 작업이 끝날 때까지 기다립니다
+func waitJob(t *testing.T, jobs *JobManager, id string) Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	job, err := jobs.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("작업 대기 실패: %v", err)
+	}
+	return job
+}
+
+func TestEnqueueActionRecordsSteps(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.Handle("echo one", sshtest.Response{Stdout: "one\n"})
+	server.Handle("echo two", sshtest.Response{Stdout: "two\n", Stderr: "warn\n"})
+
+	cm, jobs := newJobTestManager(t, "echo one", "echo two")
+	job, err := cm.EnqueueAction("testAction", nil, &CommandTarget{Hops: []ssh.HopConfig{server.Hop()}})
+	if err != nil {
+		t.Fatalf("EnqueueAction 실패: %v", err)
+	}
+	if job.ID == "" || job.State != JobQueued {
+		t.Fatalf("생성된 작업 = %+v", job)
+	}
+
+	job = waitJob(t, jobs, job.ID)
+	if job.State != JobSucceeded || job.StartedAt == nil || job.FinishedAt == nil {
+		t.Fatalf("작업 상태 = %+v", job)
+	}
+	if len(job.Steps) != 2 {
+		t.Fatalf("단계 개수 = %d, want 2", len(job.Steps))
+	}
+	step := job.Steps[1]
+	if step.Command != "echo two" || step.Output != "two\n" || step.Error != "warn\n" || step.State != JobSucceeded || step.Host != server.Host {
+		t.Errorf("두 번째 단계 = %+v", step)
+	}
+	if !strings.Contains(string(job.Result), `"output":"one\n"`) {
+		t.Errorf("작업 결과 = %s", job.Result)
+	}
+}
+
+func TestEnqueueActionFailsOnNonZeroExit(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.Handle("install", sshtest.Response{Stderr: "no space left\n", ExitCode: 2})
+
+	cm, jobs := newJobTestManager(t, "install", "never-run")
+	job, err := cm.EnqueueAction("testAction", nil, &CommandTarget{Hops: []ssh.HopConfig{server.Hop()}})
+	if err != nil {
+		t.Fatalf("EnqueueAction 실패: %v", err)
+	}
+
+	job = waitJob(t, jobs, job.ID)
+	if job.State != JobFailed || job.ErrorType != string(ssh.RemoteCommandFailed) {
+		t.Fatalf("작업 상태 = %s, 오류 = %s (%s)", job.State, job.Error, job.ErrorType)
+	}
+	if job.Steps[0].State != JobFailed || job.Steps[0].ExitCode != 2 {
+		t.Errorf("실패한 단계 = %+v", job.Steps[0])
+	}
+}
+
+func TestEnqueueActionValidation(t *testing.T) {
+	cm, jobs := newJobTestManager(t, "true")
+
+	if _, err := cm.EnqueueAction("unknownAction", nil, &CommandTarget{Hops: []ssh.HopConfig{{Host: "h"}}}); err == nil {
+		t.Error("등록되지 않은 액션은 작업을 만들지 않아야 합니다")
+	}
+	if _, err := cm.EnqueueAction("testAction", nil, nil); err == nil {
+		t.Error("대상이 없으면 작업을 만들지 않아야 합니다")
+	}
+	if list, _ := jobs.List(JobFilter{}); len(list) != 0 {
+		t.Errorf("작업 개수 = %d, want 0", len(list))
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.Handle("long-task", sshtest.Response{Delay: time.Minute})
+
+	cm, jobs := newJobTestManager(t, "long-task")
+	job, err := cm.EnqueueAction("testAction", nil, &CommandTarget{Hops: []ssh.HopConfig{server.Hop()}})
+	if err != nil {
+		t.Fatalf("EnqueueAction 실패: %v", err)
+	}
+
+	// 명령어가 시작될 때까지 대기
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, _ := jobs.Get(job.ID)
+		if len(current.Steps) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("명령어가 시작되지 않음")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := jobs.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel 실패: %v", err)
+	}
+	job, _ = jobs.Get(job.ID)
+	if job.State != JobCanceled || job.Steps[0].State != JobCanceled {
+		t.Errorf("취소된 작업 = %+v", job)
+	}
+	if err := jobs.Cancel(job.ID); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("종료된 작업 취소 오류 = %v, want ErrJobFinished", err)
+	}
+	if err := jobs.Cancel("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("없는 작업 취소 오류 = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestRecoverInterrupted(t *testing.T) {
+	store := NewMemoryJobStore()
+	now := time.Now()
+	stale := now.Add(-2 * JobStaleAfter)
+	store.SaveJob(Job{ID: "running", State: JobRunning, Owner: "gone", HeartbeatAt: stale, CreatedAt: now})
+	store.SaveJob(Job{ID: "queued", State: JobQueued, Owner: "gone", HeartbeatAt: stale, CreatedAt: now.Add(time.Second)})
+	store.SaveJob(Job{ID: "done", State: JobSucceeded, Owner: "gone", HeartbeatAt: stale, CreatedAt: now.Add(2 * time.Second)})
+	// 다른 인스턴스에서 실행 중이며 heartbeat가 최근인 작업은 중단하지 않음
+	store.SaveJob(Job{ID: "alive", State: JobRunning, Owner: "other", HeartbeatAt: now, CreatedAt: now.Add(3 * time.Second)})
+
+	jobs := NewJobManager(store)
+	count, err := jobs.RecoverInterrupted()
+	if err != nil || count != 2 {
+		t.Fatalf("RecoverInterrupted = %d, %v", count, err)
+	}
+
+	interrupted, _ := jobs.List(JobFilter{State: JobInterrupted})
+	if len(interrupted) != 2 || interrupted[0].ID != "queued" || interrupted[0].Error != JobInterruptedMessage {
+		t.Errorf("중단된 작업 = %+v", interrupted)
+	}
+	if done, _ := jobs.Get("done"); done.State != JobSucceeded {
+		t.Errorf("완료된 작업 상태가 바뀜: %s", done.State)
+	}
+	if alive, _ := jobs.Get("alive"); alive.State != JobRunning {
+		t.Errorf("다른 인스턴스의 작업 상태가 바뀜: %s", alive.State)
+	}
+
+	// heartbeat가 끊기면 중단으로 표시
+	store.TouchJobs("other", stale)
+	if count, _ := jobs.RecoverInterrupted(); count != 1 {
+		t.Errorf("heartbeat가 끊긴 작업 중단 수 = %d, want 1", count)
+	}
+
+	// 다른 인스턴스의 작업은 이 인스턴스에서 취소할 수 없음을 알림
+	store.SaveJob(Job{ID: "remote", State: JobRunning, Owner: "other", HeartbeatAt: now, CreatedAt: now})
+	if err := jobs.Cancel("remote"); !errors.Is(err, ErrJobNotLocal) {
+		t.Errorf("다른 인스턴스 작업 취소 오류 = %v, want ErrJobNotLocal", err)
+	}
+}
+
+func TestSubscribeJobEvents(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.Handle("echo one", sshtest.Response{Stdout: "one\n"})
+	server.Handle("echo two", sshtest.Response{Stdout: "two\n"})
+
+	cm, jobs := newJobTestManager(t, "echo one", "echo two")
+	job, err := cm.EnqueueAction("testAction", nil, &CommandTarget{Hops: []ssh.HopConfig{server.Hop()}})
+	if err != nil {
+		t.Fatalf("EnqueueAction 실패: %v", err)
+	}
+	waitJob(t, jobs, job.ID)
+
+	// 작업이 끝난 뒤 구독해도 재생 버퍼로 전체 진행 이벤트를 받아야 합니다
+	replay, events, unsubscribe, err := jobs.Subscribe(job.ID, 0)
+	if err != nil {
+		t.Fatalf("Subscribe 실패: %v", err)
+	}
+	defer unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("종료된 작업의 이벤트 채널은 닫혀 있어야 합니다")
+	}
+
+	var types []string
+	for i, event := range replay {
+		if event.Seq != int64(i+1) || event.JobID != job.ID {
+			t.Errorf("이벤트 %d = %+v", i, event)
+		}
+		types = append(types, string(event.Type))
+		if event.Type == JobEventOutput && event.Line != "one" && event.Line != "two" {
+			t.Errorf("출력 이벤트 = %+v", event)
+		}
+	}
+	want := "state,state,step_start,output,step_finish,step_start,output,step_finish,state"
+	if got := strings.Join(types, ","); got != want {
+		t.Fatalf("이벤트 순서 = %s, want %s", got, want)
+	}
+	if last := replay[len(replay)-1]; last.State != JobSucceeded {
+		t.Errorf("최종 상태 이벤트 = %+v", last)
+	}
+
+	// 재연결 시 마지막으로 받은 순번 이후만 전달
+	replay, _, _, _ = jobs.Subscribe(job.ID, int64(len(replay)-1))
+	if len(replay) != 1 || replay[0].Type != JobEventState {
+		t.Errorf("afterSeq 이후 이벤트 = %+v", replay)
+	}
+
+	if _, _, _, err := jobs.Subscribe("missing", 0); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("없는 작업 구독 오류 = %v, want ErrJobNotFound", err)
+	}
+}
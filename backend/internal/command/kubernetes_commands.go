@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
 )
 
 // 쿠버네티스 관련 액션 상수 정의
@@ -41,65 +43,114 @@ const (
 func RegisterKubernetesCommands(manager *CommandManager) {
 	// LoadBalancer 설치 명령어
 	manager.RegisterCommand(ActionInstallLoadBalancer, CommandTemplate{
-		ValidateFunc: validateLoadBalancerParams,
-		PrepareFunc:  prepareLoadBalancerCommands,
+		Description: "HAProxy 로드 밸런서를 설치합니다",
+		Params: ParamSchema{
+			{Name: "server_id", Type: ParamInt, Required: true, Description: "로드 밸런서 서버 ID"},
+			{Name: "password", Type: ParamString, Secret: true, Description: "sudo 비밀번호"},
+		},
+		PrepareFunc: prepareLoadBalancerCommands,
+		FilesFunc:   prepareLoadBalancerFiles,
+		RunAsRoot:   true,
 	})
 
 	// 노드 상태 확인 명령어
 	manager.RegisterCommand(ActionGetNodeStatus, CommandTemplate{
+		Description: "노드 종류별 설치 및 실행 상태를 확인합니다",
+		Params: ParamSchema{
+			{Name: "type", Type: ParamString, Required: true, Enum: []string{"ha", "master", "worker"}, Description: "노드 종류"},
+			{Name: "server_name", Type: ParamString, Format: FormatDNSSubdomain, Description: "노드 이름 (없으면 호스트 이름)"},
+			{Name: "password", Type: ParamString, Secret: true, Description: "sudo 비밀번호"},
+		},
 		PrepareFunc: prepareNodeStatusCommands,
 	})
 
 	// 첫번째 마스터 노드 설치 명령어
 	manager.RegisterCommand(ActionInstallFirstMaster, CommandTemplate{
+		Description: "첫번째 마스터 노드에 쿠버네티스 컨트롤 플레인을 설치합니다",
+		Params: ParamSchema{
+			{Name: "password", Type: ParamString, Secret: true, Description: "sudo 비밀번호"},
+			{Name: "server_name", Type: ParamString, Format: FormatDNSSubdomain, Description: "노드 이름"},
+			{Name: "lb_ip", Type: ParamString, Format: FormatHost, Description: "컨트롤 플레인 엔드포인트로 사용할 로드 밸런서 IP"},
+			{Name: "pod_network_cidr", Type: ParamString, Default: "10.10.0.0/16", Pattern: `^[0-9.]+/[0-9]{1,2}$`, Description: "파드 네트워크 CIDR"},
+		},
 		ValidateFunc: validateFirstMasterParams,
 		PrepareFunc:  prepareFirstMasterCommands,
 	})
 
 	// 마스터 노드 조인 명령어
 	manager.RegisterCommand(ActionJoinMaster, CommandTemplate{
+		Description: "마스터 노드를 기존 컨트롤 플레인에 조인하고 로드 밸런서 백엔드에 등록합니다",
+		Params: ParamSchema{
+			{Name: "server_id", Type: ParamInt, Required: true, Description: "조인할 서버 ID"},
+			{Name: "main_id", Type: ParamInt, Required: true, Description: "메인 마스터 서버 ID"},
+			{Name: "password", Type: ParamString, Secret: true, Description: "조인할 서버의 sudo 비밀번호"},
+			{Name: "lb_password", Type: ParamString, Secret: true, Description: "로드 밸런서 서버의 sudo 비밀번호"},
+			{Name: "server_name", Type: ParamString, Format: FormatDNSSubdomain, Description: "노드 이름"},
+			{Name: "master_ip", Type: ParamString, Format: FormatHost, Description: "조인할 서버 IP"},
+			{Name: "port", Type: ParamString, Default: "6443", Pattern: `^[0-9]{1,5}$`, Description: "API 서버 포트"},
+			{Name: "join_command", Type: ParamString, Format: FormatKubeadmJoin, Secret: true, Description: "kubeadm join 명령어 (없으면 메인 마스터 정보 사용)"},
+			{Name: "certificate_key", Type: ParamString, Pattern: `^--control-plane --certificate-key [A-Za-z0-9]+$`, Secret: true, Description: "컨트롤 플레인 인증서 키"},
+		},
 		ValidateFunc: validateJoinMasterParams,
-		PrepareFunc:  prepareJoinMasterCommandsWrapper,
+		StepsFunc:    JoinMasterSteps,
 	})
 
 	// 워커 노드 조인 명령어
 	manager.RegisterCommand(ActionJoinWorker, CommandTemplate{
-		ValidateFunc: validateJoinWorkerParams,
-		PrepareFunc:  prepareJoinWorkerCommands,
+		Description: "워커 노드를 클러스터에 조인합니다",
+		Params: ParamSchema{
+			{Name: "server_name", Type: ParamString, Required: true, Format: FormatDNSSubdomain, Description: "노드 이름"},
+			{Name: "join_command", Type: ParamString, Required: true, Format: FormatKubeadmJoin, Secret: true, Description: "kubeadm join 명령어"},
+			{Name: "password", Type: ParamString, Required: true, Secret: true, Description: "sudo 비밀번호"},
+		},
+		PrepareFunc: prepareJoinWorkerCommands,
 	})
 
 	// 워커 노드 조인 명령어
 	manager.RegisterCommand(ActionDeleteWorker, CommandTemplate{
-		ValidateFunc: validateDeleteWorkerParams,
-		PrepareFunc:  prepareDeleteWorkerCommands,
+		Description: "워커 노드를 드레인하고 클러스터에서 삭제합니다",
+		Params: ParamSchema{
+			{Name: "server_name", Type: ParamString, Required: true, Format: FormatDNSSubdomain, Description: "삭제할 노드 이름"},
+			{Name: "main_password", Type: ParamString, Required: true, Secret: true, Description: "메인 마스터 서버의 sudo 비밀번호"},
+			{Name: "password", Type: ParamString, Required: true, Secret: true, Description: "워커 서버의 sudo 비밀번호"},
+		},
+		PrepareFunc: prepareDeleteWorkerCommands,
 	})
 
 	// 마스터 노드 삭제 명령어 등록
 	manager.RegisterCommand(ActionDeleteMaster, CommandTemplate{
-		ValidateFunc: validateDeleteMasterParams,
-		PrepareFunc:  prepareDeleteMasterCommands,
+		Description: "마스터 노드를 클러스터와 로드 밸런서에서 삭제합니다",
+		Params: ParamSchema{
+			{Name: "server_name", Type: ParamString, Required: true, Format: FormatDNSSubdomain, Description: "삭제할 노드 이름"},
+			{Name: "password", Type: ParamString, Required: true, Secret: true, Description: "마스터 서버의 sudo 비밀번호"},
+			{Name: "main_password", Type: ParamString, Secret: true, Description: "메인 마스터 서버의 sudo 비밀번호"},
+			{Name: "lb_password", Type: ParamString, Secret: true, Description: "로드 밸런서 서버의 sudo 비밀번호"},
+		},
+		PrepareFunc: prepareDeleteMasterCommands,
 	})
 
 	// HAProxy 설정 업데이트 명령어
 	manager.RegisterCommand("updateHAProxy", CommandTemplate{
+		Description: "HAProxy 백엔드에 마스터 노드를 추가합니다",
+		Params: ParamSchema{
+			{Name: "server_name", Type: ParamString, Required: true, Format: FormatDNSSubdomain, Description: "추가할 노드 이름"},
+			{Name: "master_ip", Type: ParamString, Required: true, Format: FormatHost, Description: "추가할 노드 IP"},
+			{Name: "port", Type: ParamString, Default: "6443", Pattern: `^[0-9]{1,5}$`, Description: "API 서버 포트"},
+		},
 		PrepareFunc: prepareHAProxyUpdateCommands,
 	})
 
 	// 네임스페이스 및 파드 상태 확인 명령어 등록
 	manager.RegisterCommand(ActionGetNamespaceAndPodStatus, CommandTemplate{
+		Description: "네임스페이스와 파드 상태를 조회합니다",
+		Params: ParamSchema{
+			{Name: "namespace", Type: ParamString, Required: true, Format: FormatDNSLabel, Description: "조회할 네임스페이스"},
+			{Name: "password", Type: ParamString, Secret: true, Description: "sudo 비밀번호 (있으면 sudo로 kubectl 실행)"},
+		},
 		PrepareFunc: prepareGetNamespaceAndPodStatusCommands,
 	})
 }
 
-// LoadBalancer 관련 함수들
-func validateLoadBalancerParams(params map[string]interface{}) error {
-	// 필요한 파라미터 검증
-	if _, exists := params["server_id"]; !exists {
-		return fmt.Errorf("server_id 파라미터가 필요합니다")
-	}
-	return nil
-}
-
 // getStringParameter는 인터페이스 타입의 파라미터에서 문자열 값을 추출합니다
 func getStringParameter(param interface{}) string {
 	if param == nil {
@@ -111,11 +162,12 @@ func getStringParameter(param interface{}) string {
 	return ""
 }
 
-func prepareLoadBalancerCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
+// haproxyConfigUploadPath는 HAProxy 설정 파일을 업로드할 임시 경로입니다
+// SFTP는 로그인 사용자 권한으로 동작하므로 업로드 후 관리자 권한으로 /etc/haproxy에 설치합니다
+const haproxyConfigUploadPath = "/tmp/k8scontrol/haproxy.cfg"
 
-	// HAProxy 설정
-	haproxyConfig := `
+// haproxyConfig는 로드 밸런서 설치 시 사용하는 기본 HAProxy 설정입니다
+const haproxyConfig = `
 global
     log /dev/log    local0
     log /dev/log    local1 notice
@@ -146,27 +198,35 @@ backend kubernetes-backend
     option tcp-check
 `
 
+// prepareLoadBalancerFiles는 로드 밸런서 설치 전에 업로드할 HAProxy 설정 파일을 준비합니다
+func prepareLoadBalancerFiles(params map[string]interface{}) ([]ssh.FileUpload, error) {
+	return []ssh.FileUpload{
+		{RemotePath: haproxyConfigUploadPath, Content: []byte(haproxyConfig), Mode: 0644},
+	}, nil
+}
+
+func prepareLoadBalancerCommands(params map[string]interface{}) ([]string, error) {
 	// 설치 명령어들을 개별 문자열로 분리
 	installCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update > /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y haproxy >> /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S touch /etc/haproxy/haproxy.cfg >> /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak >> /tmp/haproxy_install.log 2>&1", password),
-		fmt.Sprintf("echo '%s' | sudo -S bash -c 'echo \"%s\" > /etc/haproxy/haproxy.cfg' >> /tmp/haproxy_install.log 2>&1", password, haproxyConfig),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl restart haproxy || echo '%s' | sudo -S service haproxy restart >> /tmp/haproxy_install.log 2>&1", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl enable haproxy || echo '%s' | sudo -S service haproxy enable >> /tmp/haproxy_install.log 2>&1", password, password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl status haproxy || echo '%s' | sudo -S service haproxy status >> /tmp/haproxy_install.log 2>&1", password, password),
+		"apt-get update > /tmp/haproxy_install.log 2>&1",
+		"apt-get install -y haproxy >> /tmp/haproxy_install.log 2>&1",
+		"touch /etc/haproxy/haproxy.cfg >> /tmp/haproxy_install.log 2>&1",
+		"cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak >> /tmp/haproxy_install.log 2>&1",
+		fmt.Sprintf("install -m 644 %s /etc/haproxy/haproxy.cfg >> /tmp/haproxy_install.log 2>&1", haproxyConfigUploadPath),
+		"systemctl restart haproxy || service haproxy restart >> /tmp/haproxy_install.log 2>&1",
+		"systemctl enable haproxy || service haproxy enable >> /tmp/haproxy_install.log 2>&1",
+		"systemctl status haproxy || service haproxy status >> /tmp/haproxy_install.log 2>&1",
 		"echo '로드 밸런서 설치 완료' >> /tmp/haproxy_install.log",
 		"local_ip=$(hostname -I | awk '{print $1}') && echo \"로드 밸런서 IP: $local_ip\" >> /tmp/haproxy_install.log",
-		fmt.Sprintf("echo '%s' | sudo -S bash -c 'local_ip=$(hostname -I | cut -d\" \" -f1); echo \"LOAD_BALANCER_IP=$local_ip\" > /tmp/load_balancer_info'", password),
+		"local_ip=$(hostname -I | cut -d\" \" -f1); echo \"LOAD_BALANCER_IP=$local_ip\" > /tmp/load_balancer_info",
 	}
 
 	// 로그 확인 명령어들을 개별 문자열로 분리
 	logCheckCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S ls -la /tmp/haproxy_install.log 2>/dev/null && echo 'LOG_EXISTS=true' || echo 'LOG_EXISTS=false'", password),
-		fmt.Sprintf("echo '%s' | sudo -S grep -q '로드 밸런서 설치 완료' /tmp/haproxy_install.log 2>/dev/null && echo 'INSTALL_COMPLETE=true' || echo 'INSTALL_COMPLETE=false'", password),
-		fmt.Sprintf("echo '%s' | sudo -S grep -i 'error\\|failed\\|실패' /tmp/haproxy_install.log 2>/dev/null && echo 'HAS_ERRORS=true' || echo 'HAS_ERRORS=false'", password),
-		fmt.Sprintf("echo '%s' | sudo -S cat /tmp/haproxy_install.log 2>/dev/null || echo '로그 파일을 읽을 수 없습니다.'", password),
+		"ls -la /tmp/haproxy_install.log 2>/dev/null && echo 'LOG_EXISTS=true' || echo 'LOG_EXISTS=false'",
+		"grep -q '로드 밸런서 설치 완료' /tmp/haproxy_install.log 2>/dev/null && echo 'INSTALL_COMPLETE=true' || echo 'INSTALL_COMPLETE=false'",
+		"grep -i 'error\\|failed\\|실패' /tmp/haproxy_install.log 2>/dev/null && echo 'HAS_ERRORS=true' || echo 'HAS_ERRORS=false'",
+		"cat /tmp/haproxy_install.log 2>/dev/null || echo '로그 파일을 읽을 수 없습니다.'",
 	}
 
 	// 모든 명령어를 하나의 슬라이스로 합치기
@@ -206,50 +266,28 @@ func prepareNodeStatusCommands(params map[string]interface{}) ([]string, error)
 			"if systemctl status haproxy | grep -q 'Active: active (running)'; then echo 'RUNNING=true'; else echo 'RUNNING=false'; fi; " +
 			"echo '===END==='"
 	case "master":
-		// 서버 이름이 제공되지 않은 경우 현재 호스트 이름 사용
-		hostVar := serverName
-		if hostVar == "" {
-			hostVar = "$(hostname)"
+		kubectl := "kubectl"
+		if password != "" {
+			kubectl = "sudo kubectl"
 		}
 
 		cmd = "echo '===START==='; " +
 			"if command -v kubectl >/dev/null 2>&1 && command -v kubelet >/dev/null 2>&1; then echo 'INSTALLED=true'; else echo 'INSTALLED=false'; fi; " +
 			"if systemctl status kubelet 2>/dev/null | grep -q 'Active: active (running)'; then echo 'KUBELET_RUNNING=true'; else echo 'KUBELET_RUNNING=false'; fi; " +
-			fmt.Sprintf("echo \"hostname=%s\"; ", hostVar)
-
-		// 비밀번호가 제공된 경우 사용
-		if password != "" {
-			cmd += fmt.Sprintf("if echo '%s' | sudo -S kubectl get nodes --no-headers 2>/dev/null | grep -E \"%s.*control-plane|%s.*master\"; then echo 'IS_MASTER=true'; else echo 'IS_MASTER=false'; fi; ",
-				password, hostVar, hostVar) +
-				fmt.Sprintf("if echo '%s' | sudo -S kubectl get nodes --no-headers 2>/dev/null | grep \"%s\" | grep -vE \"control-plane|master\"; then echo 'IS_WORKER=true'; else echo 'IS_WORKER=false'; fi; ",
-					password, hostVar) +
-				fmt.Sprintf("if echo '%s' | sudo -S kubectl get nodes --no-headers 2>/dev/null | grep -q \"%s\"; then echo 'NODE_REGISTERED=true'; else echo 'NODE_REGISTERED=false'; fi; ",
-					password, hostVar) +
-				fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes -o wide 2>/dev/null | grep \"%s\" || echo 'NODE_STATUS=NotFound'; ",
-					password, hostVar)
-		} else {
-			cmd += fmt.Sprintf("if kubectl get nodes --no-headers 2>/dev/null | grep -E \"%s.*control-plane|%s.*master\"; then echo 'IS_MASTER=true'; else echo 'IS_MASTER=false'; fi; ",
-				hostVar, hostVar) +
-				fmt.Sprintf("if kubectl get nodes --no-headers 2>/dev/null | grep \"%s\" | grep -vE \"control-plane|master\"; then echo 'IS_WORKER=true'; else echo 'IS_WORKER=false'; fi; ",
-					hostVar) +
-				fmt.Sprintf("if kubectl get nodes --no-headers 2>/dev/null | grep -q \"%s\"; then echo 'NODE_REGISTERED=true'; else echo 'NODE_REGISTERED=false'; fi; ",
-					hostVar) +
-				fmt.Sprintf("kubectl get nodes -o wide 2>/dev/null | grep \"%s\" || echo 'NODE_STATUS=NotFound'; ",
-					hostVar)
-		}
-		cmd += "echo '===END==='"
+			nodeNameVariable(serverName) +
+			"echo \"hostname=$NODE\"; " +
+			fmt.Sprintf("if %s get nodes --no-headers 2>/dev/null | grep -E \"$NODE.*control-plane|$NODE.*master\"; then echo 'IS_MASTER=true'; else echo 'IS_MASTER=false'; fi; ", kubectl) +
+			fmt.Sprintf("if %s get nodes --no-headers 2>/dev/null | grep -F \"$NODE\" | grep -vE \"control-plane|master\"; then echo 'IS_WORKER=true'; else echo 'IS_WORKER=false'; fi; ", kubectl) +
+			fmt.Sprintf("if %s get nodes --no-headers 2>/dev/null | grep -qF \"$NODE\"; then echo 'NODE_REGISTERED=true'; else echo 'NODE_REGISTERED=false'; fi; ", kubectl) +
+			fmt.Sprintf("%s get nodes -o wide 2>/dev/null | grep -F \"$NODE\" || echo 'NODE_STATUS=NotFound'; ", kubectl) +
+			"echo '===END==='"
 
 	case "worker":
-		// 서버 이름이 제공되지 않은 경우 현재 호스트 이름 사용
-		hostVar := serverName
-		if hostVar == "" {
-			hostVar = "$(hostname)"
-		}
-
 		cmd = "echo '===START==='; " +
 			"if command -v kubectl >/dev/null 2>&1 && command -v kubelet >/dev/null 2>&1; then echo 'INSTALLED=true'; else echo 'INSTALLED=false'; fi; " +
 			"if systemctl status kubelet 2>/dev/null | grep -q 'Active: active (running)'; then echo 'KUBELET_RUNNING=true'; else echo 'KUBELET_RUNNING=false'; fi; " +
-			fmt.Sprintf("echo \"hostname=%s\"; ", hostVar) +
+			nodeNameVariable(serverName) +
+			"echo \"hostname=$NODE\"; " +
 			"echo '===END==='"
 	default:
 		return nil, fmt.Errorf("지원하지 않는 노드 타입: %s", nodeType)
@@ -258,6 +296,15 @@ func prepareNodeStatusCommands(params map[string]interface{}) ([]string, error)
 	return []string{cmd}, nil
 }
 
+// nodeNameVariable은 셸 변수 NODE에 노드 이름을 설정하는 명령어를 반환합니다
+// 서버 이름이 제공되지 않은 경우 현재 호스트 이름을 사용합니다
+func nodeNameVariable(serverName string) string {
+	if serverName == "" {
+		return `NODE="$(hostname)"; `
+	}
+	return Render("NODE=%s; ", serverName)
+}
+
 // 첫번째 마스터 노드 설치 관련 함수
 func validateFirstMasterParams(params map[string]interface{}) error {
 	// 필요한 파라미터 검증
@@ -268,7 +315,6 @@ func validateFirstMasterParams(params map[string]interface{}) error {
 }
 
 func prepareFirstMasterCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["password"])
 	lbIP := getStringParameter(params["lb_ip"])
 	serverName := getStringParameter(params["server_name"])
 
@@ -282,12 +328,12 @@ func prepareFirstMasterCommands(params map[string]interface{}) ([]string, error)
 	}
 
 	// 2. 쿠버네티스 마스터 노드 설치 스크립트
-	installScript := fmt.Sprintf(`#!/bin/bash
+	installScript := Render(`#!/bin/bash
 
 set -euxo pipefail
 
 # 포트 설정
-PORT="%s"
+PORT=%s
 echo "사용할 포트: $PORT"
 
 # 현재 IP 주소 감지
@@ -302,7 +348,7 @@ if [ -z "$local_ip" ]; then
 fi
 
 # 로드 밸런서 IP 설정
-LB_IP="%s"
+LB_IP=%s
 if [ -z "$LB_IP" ]; then
   LB_IP=$local_ip
   echo "로드 밸런서 IP가 제공되지 않아 로컬 IP를 사용합니다."
@@ -310,7 +356,7 @@ fi
 echo "로드 밸런서 IP: $LB_IP"
 
 # 서버 이름 설정 (DB에서 가져온 값)
-SERVER_NAME="%s"
+SERVER_NAME=%s
 echo "DB에서 가져온 서버 이름: $SERVER_NAME"
 
 # 현재 사용자 확인
@@ -548,7 +594,7 @@ echo "설치 완료"
 		// 2. 실행 권한 부여
 		"chmod +x /tmp/install_k8s.sh",
 		// 3. 로그 파일에 출력 저장하면서 스크립트 실행 (sudo 권한으로)
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/install_k8s.sh > /tmp/k8s_install.log 2>&1 & echo $! > /tmp/k8s_install.pid", password),
+		"sudo bash /tmp/install_k8s.sh > /tmp/k8s_install.log 2>&1 & echo $! > /tmp/k8s_install.pid",
 		// 4. 설치 시작 확인
 		"echo '쿠버네티스 설치가 백그라운드에서 시작되었습니다. 로그 파일: /tmp/k8s_install.log, PID: '$(cat /tmp/k8s_install.pid)",
 		// 5. 백그라운드에서 로그 모니터링 및 join 명령어 추출 스크립트 실행
@@ -675,13 +721,7 @@ echo "설치 완료"
 
 // 마스터 노드 조인 관련 함수
 func validateJoinMasterParams(params map[string]interface{}) error {
-	// 필요한 파라미터 검증
-	if _, exists := params["server_id"]; !exists {
-		return fmt.Errorf("server_id 파라미터가 필요합니다")
-	}
-	if _, exists := params["main_id"]; !exists {
-		return fmt.Errorf("main_id 파라미터가 필요합니다")
-	}
+	// 필요한 파라미터 검증 (server_id, main_id는 파라미터 스키마에서 검증)
 	if _, exists := params["password"]; !exists {
 		return fmt.Errorf("password 파라미터가 필요합니다")
 	}
@@ -704,8 +744,6 @@ func PrepareJoinMasterCommands(params map[string]interface{}) (map[string][]stri
 	masterIP := getStringParameter(params["master_ip"])
 	joinCommand := getStringParameter(params["join_command"])
 	certificateKey := getStringParameter(params["certificate_key"])
-	password := getStringParameter(params["password"])
-	lbPassword := getStringParameter(params["lb_password"])
 
 	// 포트 기본값 설정 (기본값: 6443)
 	port := getStringParameter(params["port"])
@@ -719,10 +757,12 @@ func PrepareJoinMasterCommands(params map[string]interface{}) (map[string][]stri
 	// HAProxy 설정 업데이트 - 스크립트 파일 사용
 	haproxyUpdateCmd := []string{
 		// 1. 스크립트 파일 생성
-		fmt.Sprintf(`cat > /tmp/update_haproxy.sh << 'EOF'
+		Render(`cat > /tmp/update_haproxy.sh << 'EOF'
 #!/bin/bash
 set -e
 
+SERVER_LINE=%s
+
 # 백업 생성
 cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak_$(date +%%Y%%m%%d%%H%%M%%S)
 
@@ -730,14 +770,14 @@ cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak_$(date +%%Y%%m%%d%%H%%M
 sed -i '/^frontend/,/^backend/ {/server/d}' /etc/haproxy/haproxy.cfg
 
 # backend 섹션에 서버가 이미 있는지 확인
-if grep -q 'server %s %s:%s check' /etc/haproxy/haproxy.cfg; then
+if grep -qF "$SERVER_LINE" /etc/haproxy/haproxy.cfg; then
     echo '서버 라인이 이미 존재합니다.'
 else
     # backend 섹션 찾기
     backend_line=$(grep -n '^backend kubernetes-backend' /etc/haproxy/haproxy.cfg | cut -d: -f1)
     if [ -n "$backend_line" ]; then
         # backend 섹션 마지막에 서버 추가
-        sed -i "${backend_line}a\\    server %s %s:%s check" /etc/haproxy/haproxy.cfg
+        sed -i "${backend_line}a\\    $SERVER_LINE" /etc/haproxy/haproxy.cfg
     else
         echo "backend 섹션을 찾을 수 없습니다."
         exit 1
@@ -761,13 +801,13 @@ if ! systemctl restart haproxy && ! service haproxy restart; then
 fi
 
 echo "HAProxy 설정이 성공적으로 업데이트되었습니다."
-EOF`, serverName, masterIP, port, serverName, masterIP, port),
+EOF`, haproxyServerLine(serverName, masterIP, port)),
 
 		// 2. 스크립트 실행 권한 부여
 		"chmod +x /tmp/update_haproxy.sh",
 
 		// 3. sudo로 스크립트 실행
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/update_haproxy.sh", lbPassword),
+		"sudo bash /tmp/update_haproxy.sh",
 
 		// 4. 임시 스크립트 파일 삭제
 		"rm -f /tmp/update_haproxy.sh",
@@ -776,7 +816,7 @@ EOF`, serverName, masterIP, port, serverName, masterIP, port),
 	// 조인 스크립트 생성 및 실행을 위한 명령어
 	joinScriptCommands := []string{
 		// 1. 마스터 노드 조인 스크립트 생성
-		fmt.Sprintf(`cat > /tmp/join_k8s.sh << 'EOL'
+		Render(`cat > /tmp/join_k8s.sh << 'EOL'
 #!/bin/bash
 
 set -euxo pipefail
@@ -797,7 +837,7 @@ if [ -z "$local_ip" ]; then
 fi
 
 # 로드 밸런서 IP 설정
-LB_IP="%s"
+LB_IP=%s
 if [ -z "$LB_IP" ]; then
   LB_IP=$local_ip
   echo "로드 밸런서 IP가 제공되지 않아 로컬 IP를 사용합니다."
@@ -805,7 +845,7 @@ fi
 echo "로드 밸런서 IP: $LB_IP"
 
 # 서버 이름 설정 (DB에서 가져온 값)
-SERVER_NAME="%s"
+SERVER_NAME=%s
 echo "DB에서 가져온 서버 이름: $SERVER_NAME"
 
 # 현재 사용자 확인
@@ -983,8 +1023,8 @@ echo "Preflight Check Passed: Downloaded All Required Images"
 
 # DB에서 가져온 join 명령어와 인증서 키로 마스터 노드 조인
 echo "Joining kubernetes control plane with the following command:"
-JOIN_CMD="%s"
-CERT_KEY="%s"
+JOIN_CMD=%s
+CERT_KEY=%s
 
 JOIN_CMD="$JOIN_CMD $CERT_KEY"
 
@@ -1046,13 +1086,14 @@ echo ""
 echo "또는 다음 명령을 ~/.bashrc 파일에 추가하세요:"
 echo "echo 'export KUBECONFIG=$USER_HOME/.kube/config' >> $USER_HOME/.bashrc"
 echo "설치 완료"
+echo "마스터 노드 조인 완료"
 EOL`, port, lbIP, serverName, joinCommand, certificateKey),
 
 		// 2. 스크립트 실행 권한 부여
 		"chmod +x /tmp/join_k8s.sh",
 
 		// 3. 백그라운드로 스크립트 실행 (sudo 권한 포함)
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid", password),
+		"sudo bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid",
 
 		// 4. 설치 시작 확인
 		"echo '쿠버네티스 마스터 노드 조인이 백그라운드에서 시작되었습니다. 로그 파일: /tmp/k8s_join.log, PID: '$(cat /tmp/k8s_join.pid)",
@@ -1071,19 +1112,73 @@ EOL`, port, lbIP, serverName, joinCommand, certificateKey),
 	}, nil
 }
 
-// prepareJoinMasterCommandsWrapper는 CommandTemplate 인터페이스와 호환되도록 하는 래퍼 함수입니다
-func prepareJoinMasterCommandsWrapper(params map[string]interface{}) ([]string, error) {
-	// 하프록시 업데이트 명령만 반환 (joinScript, checkJoin은 API 핸들러에서 직접 사용)
+// JoinMasterLBTarget은 마스터 노드 조인 단계에서 로드 밸런서를 가리키는 대상 이름입니다 (CommandTarget.Targets 키)
+const JoinMasterLBTarget = "lb"
+
+// joinMasterWaitStatus는 조인 스크립트가 아직 실행 중이면 종료 코드 75를 반환하는 확인 명령어입니다
+const joinMasterWaitStatus = `if grep -q '마스터 노드 조인 완료' /tmp/k8s_join.log 2>/dev/null; then
+  echo '조인이 완료되었습니다.'
+elif [ -f /tmp/k8s_join.pid ] && kill -0 "$(cat /tmp/k8s_join.pid)" 2>/dev/null; then
+  exit 75
+else
+  echo '조인 스크립트가 완료 전에 종료되었습니다.' >&2
+  tail -n 20 /tmp/k8s_join.log >&2 2>/dev/null
+  exit 1
+fi`
+
+// JoinMasterSteps는 마스터 노드 조인을 롤백 가능한 단계로 준비합니다
+// 로드 밸런서 등록 → 조인 시작 → 조인 완료 대기 순서로 실행하며,
+// 조인이 실패하면 kubeadm reset으로 etcd 멤버와 노드를 정리하고 HAProxy 백엔드에서 서버를 제거합니다
+func JoinMasterSteps(params map[string]interface{}) ([]Step, error) {
 	commandSets, err := PrepareJoinMasterCommands(params)
 	if err != nil {
 		return nil, err
 	}
-	return commandSets["haproxyUpdate"], nil
+
+	serverName := getStringParameter(params["server_name"])
+	masterIP := getStringParameter(params["master_ip"])
+	port := getStringParameter(params["port"])
+	if port == "" {
+		port = "6443"
+	}
+	serverLine := haproxyServerLine(serverName, masterIP, port)
+
+	return []Step{
+		{
+			Name:     "haproxy-backend",
+			Target:   JoinMasterLBTarget,
+			Check:    []string{Render("grep -qF %s /etc/haproxy/haproxy.cfg", serverLine)},
+			Commands: commandSets["haproxyUpdate"],
+			Rollback: []string{
+				Render("sudo sed -i %s /etc/haproxy/haproxy.cfg", `/^[[:space:]]*`+strings.ReplaceAll(serverLine, ".", `\.`)+`$/d`),
+				"sudo haproxy -c -f /etc/haproxy/haproxy.cfg",
+				"sudo systemctl restart haproxy || sudo service haproxy restart",
+			},
+		},
+		{
+			Name: "join-control-plane",
+			// 컨트롤 플레인으로 조인된 노드에는 etcd 정적 파드 매니페스트가 있습니다
+			Check:    []string{"sudo test -f /etc/kubernetes/manifests/etcd.yaml"},
+			Commands: commandSets["joinScript"],
+			Rollback: []string{
+				`if [ -f /tmp/k8s_join.pid ]; then sudo kill "$(cat /tmp/k8s_join.pid)" 2>/dev/null; fi; true`,
+				// kubeadm reset은 클러스터에 연결할 수 있으면 이 노드의 etcd 멤버를 먼저 제거합니다
+				Render("sudo kubectl --kubeconfig=/etc/kubernetes/admin.conf delete node %s --ignore-not-found 2>/dev/null; true", serverName),
+				"sudo kubeadm reset -f",
+				"sudo rm -rf /etc/cni/net.d $HOME/.kube/config",
+			},
+		},
+		{
+			Name:     "wait-join",
+			Commands: []string{joinMasterWaitStatus},
+			// 10초 간격으로 최대 30분 대기
+			Retry: RetryPolicy{Attempts: 180, Delay: 10 * time.Second, ExitCodes: []int{stepRetryExitCode}},
+		},
+	}, nil
 }
 
 // prepareHAProxyUpdateCommands HAProxy 백엔드 설정을 업데이트하는 명령어를 준비합니다.
 func prepareHAProxyUpdateCommands(params map[string]interface{}) ([]string, error) {
-	password := getStringParameter(params["lb_password"])
 	masterIP := getStringParameter(params["master_ip"])
 	serverName := getStringParameter(params["server_name"])
 
@@ -1094,9 +1189,11 @@ func prepareHAProxyUpdateCommands(params map[string]interface{}) ([]string, erro
 	}
 
 	// HAProxy 업데이트 스크립트 생성
-	scriptContent := fmt.Sprintf(`#!/bin/bash
+	scriptContent := Render(`#!/bin/bash
 set -euo pipefail
 
+SERVER_LINE=%s
+
 # 백업 생성
 cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak_$(date +%%Y%%m%%d%%H%%M%%S)
 
@@ -1104,14 +1201,14 @@ cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak_$(date +%%Y%%m%%d%%H%%M
 sed -i '/^frontend/,/^backend/ {/server/d}' /etc/haproxy/haproxy.cfg
 
 # backend 섹션에 서버가 이미 있는지 확인
-if grep -q 'server %s %s:%s check' /etc/haproxy/haproxy.cfg; then
+if grep -qF "$SERVER_LINE" /etc/haproxy/haproxy.cfg; then
     echo '서버 라인이 이미 존재합니다.'
 else
     # backend 섹션 찾기
     backend_line=$(grep -n '^backend kubernetes-backend' /etc/haproxy/haproxy.cfg | cut -d: -f1)
     if [ -n "$backend_line" ]; then
         # backend 섹션 마지막에 서버 추가
-        sed -i "${backend_line}a\\    server %s %s:%s check" /etc/haproxy/haproxy.cfg
+        sed -i "${backend_line}a\\    $SERVER_LINE" /etc/haproxy/haproxy.cfg
     else
         echo "backend 섹션을 찾을 수 없습니다."
         exit 1
@@ -1135,7 +1232,7 @@ if ! systemctl restart haproxy && ! service haproxy restart; then
 fi
 
 echo "HAProxy 설정이 성공적으로 업데이트되었습니다."
-EOF`, serverName, masterIP, port, serverName, masterIP, port)
+EOF`, haproxyServerLine(serverName, masterIP, port))
 
 	// 명령어 배열 생성
 	commands := []string{
@@ -1144,7 +1241,7 @@ EOF`, serverName, masterIP, port, serverName, masterIP, port)
 		// 2. 실행 권한 부여
 		"chmod +x /tmp/update_haproxy.sh",
 		// 3. sudo로 스크립트 실행
-		fmt.Sprintf("echo '%s' | sudo -S /tmp/update_haproxy.sh", password),
+		"sudo /tmp/update_haproxy.sh",
 		// 4. 임시 스크립트 파일 삭제
 		"rm -f /tmp/update_haproxy.sh",
 	}
@@ -1153,32 +1250,20 @@ EOF`, serverName, masterIP, port, serverName, masterIP, port)
 }
 
 // 워커 노드 조인 관련 함수
-func validateJoinWorkerParams(params map[string]interface{}) error {
-	// 필수 매개변수 확인
-	requiredParams := []string{"server_name", "join_command", "password"}
-	for _, param := range requiredParams {
-		if _, ok := params[param]; !ok || params[param] == "" {
-			return fmt.Errorf("'%s' 매개변수가 필요합니다", param)
-		}
-	}
-	return nil
-}
-
 func prepareJoinWorkerCommands(params map[string]interface{}) ([]string, error) {
 	// 파라미터 추출
 	serverName := getStringParameter(params["server_name"])
 	joinCommand := getStringParameter(params["join_command"])
-	password := getStringParameter(params["password"])
 
 	// 워커 노드 설치 스크립트 생성
-	installScript := fmt.Sprintf(`#!/bin/bash
+	installScript := Render(`#!/bin/bash
 
 set -euxo pipefail
 
 # 현재 사용자 홈 디렉토리
 USER_HOME=$HOME
 # 서버 이름 설정
-SERVER_NAME="%s"
+SERVER_NAME=%s
 echo "서버 이름: $SERVER_NAME"
 
 # 현재 사용자 확인
@@ -1354,7 +1439,7 @@ echo "Preflight Check Passed: Downloaded All Required Images"
 
 # DB에서 가져온 join 명령어로 워커 노드 조인
 echo "Joining kubernetes as worker node with the following command:"
-JOIN_CMD="%s"
+JOIN_CMD=%s
 
 # 노드 이름 추가 (이미 있는지 확인 후)
 if ! echo "$JOIN_CMD" | grep -q -- "--node-name"; then
@@ -1381,7 +1466,7 @@ echo "워커 노드 조인 완료"`, serverName, joinCommand)
 		// 2. 실행 권한 부여
 		"chmod +x /tmp/join_k8s.sh",
 		// 3. 로그 파일에 출력 저장하면서 스크립트 실행 (sudo 권한으로)
-		fmt.Sprintf("echo '%s' | sudo -S bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid", password),
+		"sudo bash /tmp/join_k8s.sh > /tmp/k8s_join.log 2>&1 & echo $! > /tmp/k8s_join.pid",
 		// 4. 설치 시작 확인
 		"echo '쿠버네티스 워커 노드 조인이 백그라운드에서 시작되었습니다. 로그 파일: /tmp/k8s_join.log, PID: '$(cat /tmp/k8s_join.pid)",
 	}
@@ -1390,93 +1475,80 @@ echo "워커 노드 조인 완료"`, serverName, joinCommand)
 }
 
 // 워커 노드 조인 관련 함수
-func validateDeleteWorkerParams(params map[string]interface{}) error {
-	// 필수 매개변수 확인
-	requiredParams := []string{"server_name", "main_password", "password"}
-	for _, param := range requiredParams {
-		if _, ok := params[param]; !ok || params[param] == "" {
-			return fmt.Errorf("'%s' 매개변수가 필요합니다", param)
-		}
-	}
-	return nil
-}
-
 func prepareDeleteWorkerCommands(params map[string]interface{}) ([]string, error) {
 	// 파라미터 추출
 	serverName := getStringParameter(params["server_name"])
-	mainPassword := getStringParameter(params["main_password"])
-	password := getStringParameter(params["password"])
 
 	// 마스터 노드에서 실행할 명령어 (cordon, drain, delete)
 	masterCommands := []string{
 		// 1. 노드 cordon (새로운 파드 스케줄링 방지)
-		fmt.Sprintf("echo '%s' | sudo -S kubectl cordon %s", mainPassword, serverName),
+		Render("sudo kubectl cordon %s", serverName),
 
 		// 2. 노드 drain (기존 파드 안전하게 제거)
-		fmt.Sprintf("echo '%s' | sudo -S kubectl drain %s --ignore-daemonsets --delete-emptydir-data --force", mainPassword, serverName),
+		Render("sudo kubectl drain %s --ignore-daemonsets --delete-emptydir-data --force", serverName),
 
 		// 3. 노드 삭제
-		fmt.Sprintf("echo '%s' | sudo -S kubectl delete node %s", mainPassword, serverName),
+		Render("sudo kubectl delete node %s", serverName),
 	}
 
 	// 워커 노드에서 실행할 명령어 (쿠버네티스 관련 패키지 제거)
 	workerCommands := []string{
 		// 1. kubeadm reset 실행
-		fmt.Sprintf("echo '%s' | sudo -S kubeadm reset -f", password),
+		"sudo kubeadm reset -f",
 
 		// 2. CNI 설정 파일 제거
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/cni/net.d/*", password),
+		"sudo rm -rf /etc/cni/net.d/*",
 
 		// 3. iptables 규칙 정리
-		fmt.Sprintf("echo '%s' | sudo -S iptables -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t nat -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t mangle -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -X", password),
+		"sudo iptables -F",
+		"sudo iptables -t nat -F",
+		"sudo iptables -t mangle -F",
+		"sudo iptables -X",
 
 		// 4. IPVS 테이블 정리 (클러스터가 IPVS를 사용한 경우)
-		fmt.Sprintf("echo '%s' | sudo -S ipvsadm --clear 2>/dev/null || true", password),
+		"sudo ipvsadm --clear 2>/dev/null || true",
 
 		// 5. kubeconfig 파일 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /root/.kube", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/admin.conf", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes/kubelet.conf", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf ~/.kube", password),
+		"sudo rm -rf /root/.kube",
+		"sudo rm -rf /etc/kubernetes/admin.conf",
+		"sudo rm -rf /etc/kubernetes/kubelet.conf",
+		"sudo rm -rf ~/.kube",
 
 		// 6. 서비스 중지
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop kubelet", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop containerd", password),
+		"sudo systemctl stop kubelet",
+		"sudo systemctl stop containerd",
 
 		// 7. 프로세스 강제 종료
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-apiserver 2>/dev/null || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-scheduler 2>/dev/null || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-controller-manager 2>/dev/null || true", password),
+		"sudo pkill -9 kube-apiserver 2>/dev/null || true",
+		"sudo pkill -9 kube-scheduler 2>/dev/null || true",
+		"sudo pkill -9 kube-controller-manager 2>/dev/null || true",
 
 		// 8. 마운트 해제 및 파드 디렉토리 정리
-		fmt.Sprintf("echo '%s' | sudo -S umount -l /var/lib/kubelet/pods/* 2>/dev/null || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet/pods/*", password),
+		"sudo umount -l /var/lib/kubelet/pods/* 2>/dev/null || true",
+		"sudo rm -rf /var/lib/kubelet/pods/*",
 
 		// 9. 쿠버네티스 관련 디렉토리 강제 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/etcd", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes", password),
+		"sudo rm -rf /var/lib/kubelet",
+		"sudo rm -rf /var/lib/etcd",
+		"sudo rm -rf /etc/kubernetes",
 
 		// 10. systemd 서비스 비활성화
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable kubelet", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable containerd", password),
+		"sudo systemctl disable kubelet",
+		"sudo systemctl disable containerd",
 
 		// 11. 패키지 제거 (대화형 프롬프트 비활성화)
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni", password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni",
 
 		// 12. 관련 디렉토리 정리
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /opt/cni", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubectl", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubeadm", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /usr/bin/kubelet", password),
+		"sudo rm -rf /opt/cni",
+		"sudo rm -rf /usr/bin/kubectl",
+		"sudo rm -rf /usr/bin/kubeadm",
+		"sudo rm -rf /usr/bin/kubelet",
 
 		// 13. 패키지 캐시 정리
-		fmt.Sprintf("echo '%s' | sudo -S apt-get clean", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoremove -y", password),
+		"sudo apt-get clean",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoremove -y",
 
 		// 14. 완료 메시지
 		"echo '쿠버네티스 노드 정리 완료'",
@@ -1511,7 +1583,6 @@ func prepareDeleteMasterCommands(params map[string]interface{}) ([]string, error
 	}
 
 	serverName := getStringParameter(params["server_name"])
-	password := getStringParameter(params["password"])
 	mainPassword := getStringParameter(params["main_password"])
 	lbPassword := getStringParameter(params["lb_password"])
 
@@ -1520,7 +1591,7 @@ func prepareDeleteMasterCommands(params map[string]interface{}) ([]string, error
 
 	// 1. 로그 파일 시작 설정 명령어
 	logStartCommands := []string{
-		fmt.Sprintf("echo '===== 마스터 노드 %s 삭제 작업 시작 =====' > /tmp/master_delete.log", serverName),
+		Render("echo %s > /tmp/master_delete.log", "===== 마스터 노드 "+serverName+" 삭제 작업 시작 ====="),
 		fmt.Sprintf("echo '시작 시간: %s' >> /tmp/master_delete.log", time.Now().Format(time.RFC3339)),
 	}
 	allCommands = append(allCommands, logStartCommands...)
@@ -1529,15 +1600,17 @@ func prepareDeleteMasterCommands(params map[string]interface{}) ([]string, error
 	if lbPassword != "" {
 		haproxyUpdateCmd := []string{
 			// 스크립트 파일 생성
-			fmt.Sprintf(`cat > /tmp/remove_server.sh << 'EOF'
+			Render(`cat > /tmp/remove_server.sh << 'EOF'
 #!/bin/bash
 set -e
 
+SERVER_NAME=%s
+
 # 백업 생성
 cp /etc/haproxy/haproxy.cfg /etc/haproxy/haproxy.cfg.bak.$(date +%%Y%%m%%d%%H%%M%%S)
 
 # 서버 이름에 해당하는 라인 제거
-sed -i '/server %s /d' /etc/haproxy/haproxy.cfg
+sed -i "/server $SERVER_NAME /d" /etc/haproxy/haproxy.cfg
 
 # 설정 파일 문법 검사
 if ! haproxy -c -f /etc/haproxy/haproxy.cfg; then
@@ -1555,14 +1628,14 @@ if ! systemctl restart haproxy && ! service haproxy restart; then
     exit 1
 fi
 
-echo "HAProxy 설정에서 서버 %s가 성공적으로 제거되었습니다."
-EOF`, serverName, serverName),
+echo "HAProxy 설정에서 서버 $SERVER_NAME가 성공적으로 제거되었습니다."
+EOF`, serverName),
 
 			// 스크립트 실행 권한 부여
 			"chmod +x /tmp/remove_server.sh",
 
 			// sudo로 스크립트 실행
-			fmt.Sprintf("echo '%s' | sudo -S bash /tmp/remove_server.sh", lbPassword),
+			"sudo bash /tmp/remove_server.sh",
 
 			// 임시 스크립트 파일 삭제
 			"rm -f /tmp/remove_server.sh",
@@ -1575,88 +1648,88 @@ EOF`, serverName, serverName),
 		// 3.1 노드 드레인 및 삭제
 		mainNodeCommands := []string{
 			// 노드 드레인 (파드 제거)
-			fmt.Sprintf("echo '%s' | sudo -S kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets", mainPassword, serverName),
+			Render("sudo kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets", serverName),
 
 			// 노드 삭제
-			fmt.Sprintf("echo '%s' | sudo -S kubectl delete node %s", mainPassword, serverName),
+			Render("sudo kubectl delete node %s", serverName),
 		}
 		allCommands = append(allCommands, mainNodeCommands...)
 
 		// 3.2 etcd 멤버 제거
 		etcdCommands := []string{
 			// etcd 멤버 리스트 조회
-			fmt.Sprintf("echo '%s' | sudo -S ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list", mainPassword),
+			"sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list",
 
 			// etcd 멤버 ID 추출 및 제거
-			fmt.Sprintf("echo '%s' | sudo -S bash -c \"ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list | grep %s | cut -d',' -f1 | xargs -I {} sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member remove {}\"", mainPassword, serverName),
+			Render("sudo bash -c %s", etcdMemberRemoveCommand(serverName)),
 
 			// 노드 목록 확인
-			fmt.Sprintf("echo '%s' | sudo -S kubectl get nodes", mainPassword),
+			"sudo kubectl get nodes",
 		}
 		allCommands = append(allCommands, etcdCommands...)
 	}
 
 	// 4. 대상 노드 서비스 중지 명령어
 	stopServicesCmd := []string{
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop kubelet || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl stop etcd || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 etcd || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-apiserver || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-scheduler || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S pkill -9 kube-controller-manager || true", password),
+		"sudo systemctl stop kubelet || true",
+		"sudo systemctl stop etcd || true",
+		"sudo pkill -9 etcd || true",
+		"sudo pkill -9 kube-apiserver || true",
+		"sudo pkill -9 kube-scheduler || true",
+		"sudo pkill -9 kube-controller-manager || true",
 	}
 	allCommands = append(allCommands, stopServicesCmd...)
 
 	// 5. 노드 cordon 및 drain 명령어
 	nodeCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubectl cordon %s || true", password, serverName),
-		fmt.Sprintf("echo '%s' | sudo -S kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets || true", password, serverName),
+		Render("sudo kubectl cordon %s || true", serverName),
+		Render("sudo kubectl drain %s --delete-emptydir-data --force --ignore-daemonsets || true", serverName),
 	}
 	allCommands = append(allCommands, nodeCommands...)
 
 	// 6. etcd 멤버 제거 명령어 (로컬 노드에서)
 	etcdLocalRemoveCmd := []string{
-		fmt.Sprintf("echo '%s' | sudo -S bash -c \"ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member list | grep %s | cut -d',' -f1 | xargs -I {} sudo ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key member remove {}\" || true", password, serverName),
+		Render("sudo bash -c %s || true", etcdMemberRemoveCommand(serverName)),
 	}
 	allCommands = append(allCommands, etcdLocalRemoveCmd...)
 
 	// 7. kubeadm reset 및 정리 명령어
 	cleanupCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S kubeadm reset -f", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/cni/net.d/*", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/etcd", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /var/lib/kubelet", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf /etc/kubernetes", password),
-		fmt.Sprintf("echo '%s' | sudo -S rm -rf $HOME/.kube", password),
+		"sudo kubeadm reset -f",
+		"sudo rm -rf /etc/cni/net.d/*",
+		"sudo rm -rf /var/lib/etcd",
+		"sudo rm -rf /var/lib/kubelet",
+		"sudo rm -rf /etc/kubernetes",
+		"sudo rm -rf $HOME/.kube",
 	}
 	allCommands = append(allCommands, cleanupCommands...)
 
 	// 8. iptables 정리 명령어
 	iptablesCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S iptables -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t nat -F", password),
-		fmt.Sprintf("echo '%s' | sudo -S iptables -t mangle -F", password),
+		"sudo iptables -F",
+		"sudo iptables -t nat -F",
+		"sudo iptables -t mangle -F",
 	}
 	allCommands = append(allCommands, iptablesCommands...)
 
 	// 9. 서비스 비활성화 명령어
 	disableCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable kubelet || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S systemctl disable etcd || true", password),
+		"sudo systemctl disable kubelet || true",
+		"sudo systemctl disable etcd || true",
 	}
 	allCommands = append(allCommands, disableCommands...)
 
 	// 10. 패키지 제거 명령어
 	removeCommands := []string{
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true", password),
-		fmt.Sprintf("echo '%s' | sudo -S DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true", password),
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get remove --allow-change-held-packages -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get purge -y kubeadm kubectl kubelet kubernetes-cni || true",
+		"sudo DEBIAN_FRONTEND=noninteractive apt-get autoremove -y || true",
 	}
 	allCommands = append(allCommands, removeCommands...)
 
 	// 11. 로그 파일 완료 메시지 추가
 	logFinishCommands := []string{
-		fmt.Sprintf("echo '===== 마스터 노드 %s 삭제 작업 완료 =====' >> /tmp/master_delete.log", serverName),
+		Render("echo %s >> /tmp/master_delete.log", "===== 마스터 노드 "+serverName+" 삭제 작업 완료 ====="),
 		fmt.Sprintf("echo '완료 시간: %s' >> /tmp/master_delete.log", time.Now().Format(time.RFC3339)),
 	}
 	allCommands = append(allCommands, logFinishCommands...)
@@ -1676,19 +1749,32 @@ func prepareGetNamespaceAndPodStatusCommands(params map[string]interface{}) ([]s
 	var commands []string
 	sudoPrefix := ""
 	if password != "" {
-		sudoPrefix = fmt.Sprintf("echo '%s' | sudo -S ", password)
+		sudoPrefix = "sudo "
 	}
 
 	// 1. 네임스페이스 존재 확인 명령어
 	//    -o name 옵션으로 존재하면 "namespace/[name]" 출력, 없으면 에러.
 	//    에러 시 stderr를 무시하고 'NAMESPACE_NOT_FOUND' 출력
-	nsCheckCmd := fmt.Sprintf("%skubectl get namespace %s -o name 2>/dev/null || echo 'not found'", sudoPrefix, namespace)
+	nsCheckCmd := Render("%skubectl get namespace %s -o name 2>/dev/null || echo 'not found'", Raw(sudoPrefix), namespace)
 	commands = append(commands, nsCheckCmd)
 
 	// 2. 파드 상태 확인 명령어 (핸들러에서 네임스페이스 존재 여부 확인 후 조건부 실행 또는 결과 파싱 시 사용)
 	//    --no-headers 옵션으로 헤더 제거
-	podStatusCmd := fmt.Sprintf("%skubectl get pods -n %s -o custom-columns=NAME:.metadata.name,STATUS:.status.phase,RESTARTS:.status.containerStatuses[0].restartCount 2>/dev/null", sudoPrefix, namespace)
+	podStatusCmd := Render("%skubectl get pods -n %s -o custom-columns=NAME:.metadata.name,STATUS:.status.phase,RESTARTS:.status.containerStatuses[0].restartCount 2>/dev/null", Raw(sudoPrefix), namespace)
 	commands = append(commands, podStatusCmd)
 
 	return commands, nil
 }
+
+// haproxyServerLine은 HAProxy backend에 추가하는 마스터 노드 server 라인입니다
+func haproxyServerLine(serverName, masterIP, port string) string {
+	return fmt.Sprintf("server %s %s:%s check", serverName, masterIP, port)
+}
+
+// etcdctlCommand는 컨트롤 플레인 노드의 etcd 인증서로 etcdctl을 실행하는 명령어입니다
+const etcdctlCommand = "ETCDCTL_API=3 etcdctl --endpoints=localhost:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key"
+
+// etcdMemberRemoveCommand는 이름이 serverName인 etcd 멤버를 찾아 제거하는 명령어입니다
+func etcdMemberRemoveCommand(serverName string) string {
+	return Render("%s member list | grep -F %s | cut -d',' -f1 | xargs -I {} sudo %s member remove {}", Raw(etcdctlCommand), serverName, Raw(etcdctlCommand))
+}
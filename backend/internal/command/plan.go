@@ -0,0 +1,177 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/k8scontrol/backend/pkg/redact"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// SecretMask는 드라이런 결과에서 비밀값을 대신하는 문자열입니다
+const SecretMask = redact.Mask
+
+// dryRunKey는 컨텍스트에 드라이런 여부를 저장할 때 사용하는 키입니다
+type dryRunKey struct{}
+
+// WithDryRun은 ctx로 실행되는 액션을 드라이런으로 처리하는 컨텍스트를 반환합니다
+// 드라이런에서는 파라미터 검증과 명령어 준비만 수행하며 SSH 연결을 열지 않습니다
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun은 ctx가 드라이런 컨텍스트인지 확인합니다
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// ActionPlan은 액션을 실행했을 때 어떤 대상에서 어떤 명령어가 실행되는지를 나타냅니다
+// 명령어와 대상 정보의 비밀값은 SecretMask로 가려져 있습니다
+type ActionPlan struct {
+	Action  string        `json:"action"`
+	Targets []PlanTarget  `json:"targets"`
+	Steps   []PlannedStep `json:"steps"`
+	Files   []PlannedFile `json:"files,omitempty"`
+}
+
+// PlanTarget은 명령어가 실행될 대상 서버입니다
+type PlanTarget struct {
+	Name     string   `json:"name"` // 단계의 Target 이름 (기본 대상은 빈 문자열)
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Route    []string `json:"route"` // 접속 경로 (host:port 순서, 마지막이 최종 대상)
+}
+
+// PlannedStep은 실행될 단계와 렌더링된 명령어입니다
+// 단계 템플릿이 아닌 액션은 액션 이름의 단계 하나로 표시됩니다
+type PlannedStep struct {
+	Name     string   `json:"name"`
+	Target   string   `json:"target,omitempty"`
+	Host     string   `json:"host"`
+	Check    []string `json:"check,omitempty"`
+	Commands []string `json:"commands"`
+	Rollback []string `json:"rollback,omitempty"`
+	Attempts int      `json:"attempts,omitempty"`
+}
+
+// PlannedFile은 명령어 실행 전에 업로드될 파일입니다 (내용은 포함하지 않습니다)
+type PlannedFile struct {
+	RemotePath string `json:"remote_path"`
+	Size       int    `json:"size"`
+	Mode       string `json:"mode"`
+}
+
+// PlanAction은 SSH 연결 없이 액션의 실행 계획을 만듭니다
+// 파라미터 검증, 명령어 렌더링, 대상 확인을 실제 실행과 같은 방식으로 수행하고 비밀값을 가립니다
+func (cm *CommandManager) PlanAction(action string, params map[string]interface{}, target *CommandTarget) (*ActionPlan, error) {
+	template, exists := cm.commandMap[action]
+	if !exists {
+		return nil, fmt.Errorf("지원하지 않는 액션입니다: %s", action)
+	}
+
+	var steps []Step
+	if template.StepsFunc != nil {
+		var err error
+		if steps, err = cm.PrepareSteps(action, params); err != nil {
+			return nil, err
+		}
+	} else {
+		commands, err := cm.PrepareAction(action, params)
+		if err != nil {
+			return nil, err
+		}
+		steps = []Step{{Name: action, Commands: commands}}
+	}
+
+	files, err := cm.PrepareFiles(action, params)
+	if err != nil {
+		return nil, err
+	}
+
+	masker := newSecretMasker(params, target, template.Params)
+	plan := &ActionPlan{Action: action}
+
+	seen := make(map[string]bool)
+	for _, step := range steps {
+		hops, err := target.stepHops(step.Target)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[step.Target] {
+			seen[step.Target] = true
+			plan.Targets = append(plan.Targets, planTarget(step.Target, hops))
+		}
+
+		attempts := 0
+		if step.Retry.Attempts > 1 {
+			attempts = step.Retry.Attempts
+		}
+		plan.Steps = append(plan.Steps, PlannedStep{
+			Name:     step.Name,
+			Target:   step.Target,
+			Host:     hops[len(hops)-1].Host,
+			Check:    masker.Strings(step.commands(step.Check)),
+			Commands: masker.Strings(step.commands(step.Commands)),
+			Rollback: masker.Strings(step.commands(step.Rollback)),
+			Attempts: attempts,
+		})
+	}
+
+	for _, file := range files {
+		mode := file.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		plan.Files = append(plan.Files, PlannedFile{RemotePath: file.RemotePath, Size: len(file.Content), Mode: fmt.Sprintf("%04o", mode)})
+	}
+
+	log.Printf("[CommandManager] 액션 %s 드라이런: 단계 %d개, 대상 %d개", action, len(plan.Steps), len(plan.Targets))
+	return plan, nil
+}
+
+// CommandResults는 실행 계획의 명령어를 실행 결과 형식으로 반환합니다 (Output, ExitCode는 비어 있습니다)
+func (p *ActionPlan) CommandResults() []ssh.CommandResult {
+	var results []ssh.CommandResult
+	for _, step := range p.Steps {
+		for _, cmd := range step.Commands {
+			results = append(results, ssh.CommandResult{Command: cmd})
+		}
+	}
+	return results
+}
+
+// planTarget은 hop 목록을 비밀값 없는 대상 정보로 변환합니다
+func planTarget(name string, hops []ssh.HopConfig) PlanTarget {
+	last := hops[len(hops)-1]
+	target := PlanTarget{Name: name, Host: last.Host, Port: last.Port, Username: last.Username}
+	for _, hop := range hops {
+		target.Route = append(target.Route, fmt.Sprintf("%s:%d", hop.Host, hop.Port))
+	}
+	return target
+}
+
+// newSecretMasker는 파라미터(중첩 맵 포함)와 대상 hop의 인증 정보, 스키마에서 Secret으로 정의한 파라미터의 값을 가리는 Masker를 만듭니다
+func newSecretMasker(params map[string]interface{}, target *CommandTarget, schema ParamSchema) *redact.Masker {
+	m := redact.NewMasker(redact.Secrets(params)...)
+	for _, spec := range schema {
+		if value, ok := params[spec.Name].(string); ok && spec.Secret {
+			m.Add(value)
+		}
+	}
+	if target != nil {
+		addHopSecrets(m, target.Hops)
+		for _, hops := range target.Targets {
+			addHopSecrets(m, hops)
+		}
+	}
+	return m
+}
+
+func addHopSecrets(m *redact.Masker, hops []ssh.HopConfig) {
+	for _, hop := range hops {
+		m.Add(hop.Password, hop.SudoPassword, hop.Passphrase, hop.PrivateKey)
+	}
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+func TestPlanActionMasksSecrets(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+
+	cm := NewCommandManager()
+	cm.RegisterCommand("joinTest", CommandTemplate{
+		ValidateFunc: func(params map[string]interface{}) error {
+			if params["db_password"] == nil {
+				return fmt.Errorf("db_password 파라미터가 필요합니다")
+			}
+			return nil
+		},
+		PrepareFunc: func(params map[string]interface{}) ([]string, error) {
+			return []string{
+				"mysql -p" + params["db_password"].(string) + " -e 'select 1'",
+				params["join_command"].(string),
+			}, nil
+		},
+		RunAsRoot: true,
+	})
+
+	hop := server.Hop()
+	hop.Password = "hop-secret"
+	target := &CommandTarget{Hops: []ssh.HopConfig{hop}}
+	params := map[string]interface{}{
+		"db_password":  "s3cr3t-pw",
+		"join_command": "kubeadm join 10.0.0.1:6443 --token abcdef.0123456789abcdef --discovery-token-ca-cert-hash sha256:1234",
+	}
+
+	plan, err := cm.PlanAction("joinTest", params, target)
+	if err != nil {
+		t.Fatalf("PlanAction 실패: %v", err)
+	}
+	if len(plan.Targets) != 1 || plan.Targets[0].Host != hop.Host || len(plan.Steps) != 1 {
+		t.Fatalf("실행 계획 = %+v", plan)
+	}
+	rendered := strings.Join(plan.Steps[0].Commands, "\n")
+	for _, secret := range []string{"s3cr3t-pw", "abcdef.0123456789abcdef"} {
+		if strings.Contains(rendered, secret) {
+			t.Errorf("비밀값 %q가 노출됨:\n%s", secret, rendered)
+		}
+	}
+	if !strings.Contains(rendered, "sudo sh -c") || !strings.Contains(rendered, "--token "+SecretMask) || !strings.Contains(rendered, "sha256:1234") {
+		t.Errorf("렌더링된 명령어 =\n%s", rendered)
+	}
+
+	// 드라이런 컨텍스트의 ExecuteAction은 SSH 연결 없이 명령어만 반환
+	results, err := cm.ExecuteAction(WithDryRun(context.Background()), "joinTest", params, target)
+	if err != nil || len(results) != 2 || results[1].Output != "" {
+		t.Fatalf("드라이런 결과 = %+v, %v", results, err)
+	}
+	if len(server.Commands()) != 0 {
+		t.Errorf("드라이런에서 명령어가 실행됨: %v", server.Commands())
+	}
+
+	if _, err := cm.PlanAction("joinTest", map[string]interface{}{}, target); err == nil {
+		t.Error("파라미터 검증 오류가 발생해야 합니다")
+	}
+	if _, err := cm.PlanAction("joinTest", params, nil); err == nil {
+		t.Error("대상이 없으면 오류가 발생해야 합니다")
+	}
+}
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// Raw는 Render에서 따옴표 없이 그대로 넣는 신뢰된 명령어 조각입니다
+// 코드의 상수나 이미 Render로 만든 명령어에만 사용하고 요청 값에는 사용하지 않습니다
+type Raw string
+
+// Render는 fmt.Sprintf와 같이 명령어를 만들되 문자열 인자를 모두 ssh.ShellQuote로 감쌉니다
+// 숫자와 bool, Raw는 그대로 넣습니다. 형식 문자열에서 인자를 따옴표로 감싸지 않아야 합니다 ("%s"가 아닌 %s)
+func Render(format string, args ...interface{}) string {
+	quoted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case Raw:
+			quoted[i] = string(v)
+		case string:
+			quoted[i] = ssh.ShellQuote(v)
+		case int, int64, uint, uint64, float64, bool:
+			quoted[i] = v
+		default:
+			quoted[i] = ssh.ShellQuote(fmt.Sprint(v))
+		}
+	}
+	return fmt.Sprintf(format, quoted...)
+}
+
+// ParamFormat은 문자열 파라미터가 따라야 하는 식별자 형식입니다
+// 따옴표로 감싸도 명령어 옵션으로 해석되거나(-로 시작) 원격 도구가 다르게 해석할 수 있는 값을 막습니다
+type ParamFormat string
+
+const (
+	FormatDNSLabel     ParamFormat = "dns1123-label"     // 네임스페이스 등 (소문자, 숫자, '-', 최대 63자)
+	FormatDNSSubdomain ParamFormat = "dns1123-subdomain" // 노드 이름 등 ('.'으로 구분한 DNS-1123 레이블, 최대 253자)
+	FormatContainerID  ParamFormat = "container-id"      // 도커 컨테이너 ID 또는 이름
+	FormatGitRef       ParamFormat = "git-ref"           // git 브랜치, 태그 이름
+	FormatGitURL       ParamFormat = "git-url"           // git 저장소 URL (https, http, ssh, git@host:path)
+	FormatHost         ParamFormat = "host"              // IP 주소 또는 호스트 이름
+	FormatKubeadmJoin  ParamFormat = "kubeadm-join"      // kubeadm token create --print-join-command 출력
+)
+
+var (
+	dnsLabelPattern    = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	containerIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	hostnamePattern    = regexp.MustCompile(`^(?i)[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	gitURLPattern      = regexp.MustCompile(`^(https?://|ssh://|git@)[A-Za-z0-9._~:/?#@!$&()*+,;=%-]+$`)
+	joinArgPattern     = regexp.MustCompile(`^[A-Za-z0-9._:/=\[\]-]+$`)
+)
+
+// ValidateFormat은 값이 식별자 형식에 맞는지 확인합니다
+func ValidateFormat(format ParamFormat, value string) error {
+	switch format {
+	case FormatDNSLabel:
+		if len(value) > 63 || !dnsLabelPattern.MatchString(value) {
+			return fmt.Errorf("소문자, 숫자, '-'로 이루어진 63자 이하의 이름이어야 합니다")
+		}
+	case FormatDNSSubdomain:
+		if len(value) > 253 {
+			return fmt.Errorf("253자 이하여야 합니다")
+		}
+		for _, label := range strings.Split(value, ".") {
+			if len(label) > 63 || !dnsLabelPattern.MatchString(label) {
+				return fmt.Errorf("소문자, 숫자, '-', '.'로 이루어진 DNS 이름이어야 합니다")
+			}
+		}
+	case FormatContainerID:
+		if len(value) > 128 || !containerIDPattern.MatchString(value) {
+			return fmt.Errorf("컨테이너 ID 또는 이름이 올바르지 않습니다")
+		}
+	case FormatGitRef:
+		return validateGitRef(value)
+	case FormatGitURL:
+		if !gitURLPattern.MatchString(value) {
+			return fmt.Errorf("https://, ssh:// 또는 git@으로 시작하는 저장소 주소여야 합니다")
+		}
+	case FormatHost:
+		if net.ParseIP(value) == nil && (len(value) > 253 || !hostnamePattern.MatchString(value)) {
+			return fmt.Errorf("IP 주소 또는 호스트 이름이어야 합니다")
+		}
+	case FormatKubeadmJoin:
+		fields := strings.Fields(value)
+		if len(fields) < 3 || fields[0] != "kubeadm" || fields[1] != "join" {
+			return fmt.Errorf("kubeadm join 명령어여야 합니다")
+		}
+		for _, arg := range fields[2:] {
+			// kubeadm init 출력에서 복사한 줄 바꿈 기호(\)는 스크립트에서 제거합니다
+			if arg != `\` && !joinArgPattern.MatchString(arg) {
+				return fmt.Errorf("kubeadm join 명령어에 허용되지 않는 문자가 있습니다: %q", arg)
+			}
+		}
+	default:
+		return fmt.Errorf("알 수 없는 형식입니다: %s", format)
+	}
+	return nil
+}
+
+// validateGitRef는 git check-ref-format 규칙 중 브랜치, 태그 이름에 필요한 부분을 확인합니다
+func validateGitRef(ref string) error {
+	if ref == "" || ref == "@" || len(ref) > 255 {
+		return fmt.Errorf("브랜치 또는 태그 이름이 올바르지 않습니다")
+	}
+	if strings.HasPrefix(ref, "-") || strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") ||
+		strings.HasSuffix(ref, ".") || strings.HasSuffix(ref, ".lock") ||
+		strings.Contains(ref, "..") || strings.Contains(ref, "//") || strings.Contains(ref, "@{") {
+		return fmt.Errorf("브랜치 또는 태그 이름이 올바르지 않습니다: %s", ref)
+	}
+	for _, r := range ref {
+		if r <= ' ' || r == 0x7f || strings.ContainsRune(`~^:?*[\`, r) {
+			return fmt.Errorf("브랜치 또는 태그 이름에 허용되지 않는 문자가 있습니다: %q", r)
+		}
+	}
+	for _, component := range strings.Split(ref, "/") {
+		if strings.HasPrefix(component, ".") {
+			return fmt.Errorf("브랜치 또는 태그 이름의 각 부분은 '.'으로 시작할 수 없습니다: %s", ref)
+		}
+	}
+	return nil
+}
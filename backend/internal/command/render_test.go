@@ -0,0 +1,77 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderQuotesArguments(t *testing.T) {
+	got := Render("kubectl get pods -n %s --tail=%d %s", "x; rm -rf /", 100, Raw("| head"))
+	want := `kubectl get pods -n 'x; rm -rf /' --tail=100 | head`
+	if got != want {
+		t.Errorf("Render 결과가 올바르지 않습니다\n got: %s\nwant: %s", got, want)
+	}
+
+	got = Render("echo %s", "it's")
+	if got != `echo 'it'\''s'` {
+		t.Errorf("작은따옴표가 이스케이프되지 않았습니다: %s", got)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	cases := []struct {
+		format ParamFormat
+		value  string
+		valid  bool
+	}{
+		{FormatDNSLabel, "kube-system", true},
+		{FormatDNSLabel, "x; rm -rf /", false},
+		{FormatDNSLabel, "-n", false},
+		{FormatDNSSubdomain, "worker-1.example.com", true},
+		{FormatDNSSubdomain, "worker_1", false},
+		{FormatContainerID, "3f2a9c1b7d0e", true},
+		{FormatContainerID, "--rm", false},
+		{FormatGitRef, "feature/login", true},
+		{FormatGitRef, "-b", false},
+		{FormatGitRef, "main..dev", false},
+		{FormatGitRef, "main$(id)", true}, // 셸 문자는 Render에서 따옴표로 처리합니다
+		{FormatGitURL, "https://github.com/org/repo.git", true},
+		{FormatGitURL, "--upload-pack=touch /tmp/x", false},
+		{FormatHost, "10.0.0.1", true},
+		{FormatHost, "lb.example.com", true},
+		{FormatHost, "10.0.0.1 && reboot", false},
+		{FormatKubeadmJoin, `kubeadm join 10.0.0.1:6443 --token abcdef.0123456789abcdef \ --discovery-token-ca-cert-hash sha256:0a1b`, true},
+		{FormatKubeadmJoin, "kubeadm join 10.0.0.1:6443; reboot", false},
+		{FormatKubeadmJoin, "kubeadm reset -f", false},
+	}
+
+	for _, tc := range cases {
+		err := ValidateFormat(tc.format, tc.value)
+		if (err == nil) != tc.valid {
+			t.Errorf("ValidateFormat(%s, %q) = %v, 기대 유효 여부 %v", tc.format, tc.value, err, tc.valid)
+		}
+	}
+}
+
+func TestNamespaceCommandsRejectInjection(t *testing.T) {
+	cm := NewCommandManager()
+	RegisterKubernetesCommands(cm)
+	_, err := cm.PrepareAction("getNamespaceAndPodStatus", map[string]interface{}{
+		"namespace": "default; rm -rf /",
+		"password":  "secret",
+	})
+	if err == nil {
+		t.Fatal("잘못된 네임스페이스가 거부되어야 합니다")
+	}
+
+	commands, err := cm.PrepareAction("getNamespaceAndPodStatus", map[string]interface{}{
+		"namespace": "default",
+		"password":  "secret",
+	})
+	if err != nil {
+		t.Fatalf("PrepareAction 실패: %v", err)
+	}
+	if !strings.Contains(strings.Join(commands, "\n"), "'default'") {
+		t.Errorf("네임스페이스가 따옴표로 감싸져야 합니다: %v", commands)
+	}
+}
@@ -0,0 +1,273 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamType은 액션 파라미터의 값 종류입니다
+type ParamType string
+
+const (
+	ParamString ParamType = "string" // 문자열 (정수 숫자는 문자열로 변환)
+	ParamInt    ParamType = "int"    // 정수 (JSON 숫자와 숫자 문자열 허용)
+	ParamNumber ParamType = "number" // 실수
+	ParamBool   ParamType = "bool"   // true/false ("true", "false" 문자열 허용)
+	ParamObject ParamType = "object" // JSON 객체
+	ParamArray  ParamType = "array"  // JSON 배열
+	ParamHops   ParamType = "hops"   // SSH hop 배열 (host 필드가 있는 객체 배열)
+)
+
+// ErrInvalidParams는 파라미터가 스키마와 맞지 않을 때 errors.Is로 확인할 수 있는 오류입니다
+var ErrInvalidParams = errors.New("잘못된 파라미터")
+
+// ParamSpec은 액션 파라미터 하나의 정의입니다
+type ParamSpec struct {
+	Name        string      `json:"name"`
+	Type        ParamType   `json:"type"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`    // 허용 값 목록 (대소문자 구분 없이 비교, 목록의 값으로 정규화)
+	Pattern     string      `json:"pattern,omitempty"` // 문자열이 일치해야 하는 정규식
+	Format      ParamFormat `json:"format,omitempty"`  // 문자열이 따라야 하는 식별자 형식 (ValidateFormat 참고)
+	Secret      bool        `json:"secret,omitempty"`  // 비밀번호 등 화면과 로그에 표시하지 않아야 하는 값
+	Description string      `json:"description,omitempty"`
+}
+
+// ParamSchema는 액션이 받는 파라미터 정의 목록입니다
+// 정의되지 않은 파라미터(hops, async 등 핸들러가 사용하는 값)는 검사하지 않고 그대로 전달합니다
+type ParamSchema []ParamSpec
+
+// ParamError는 파라미터 하나의 검증 오류입니다
+type ParamError struct {
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// ParamErrors는 파라미터 검증 오류 목록입니다
+type ParamErrors []ParamError
+
+func (e ParamErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Param + ": " + err.Message
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Is는 errors.Is(err, ErrInvalidParams)가 참이 되도록 합니다
+func (e ParamErrors) Is(target error) bool {
+	return target == ErrInvalidParams
+}
+
+// Validate는 스키마 정의 자체가 올바른지 확인합니다 (이름 중복, 알 수 없는 타입, 잘못된 정규식)
+func (s ParamSchema) Validate() error {
+	names := make(map[string]bool, len(s))
+	for _, spec := range s {
+		if spec.Name == "" || names[spec.Name] {
+			return fmt.Errorf("파라미터 이름이 없거나 중복되었습니다: %q", spec.Name)
+		}
+		names[spec.Name] = true
+		switch spec.Type {
+		case ParamString, ParamInt, ParamNumber, ParamBool, ParamObject, ParamArray, ParamHops:
+		default:
+			return fmt.Errorf("%s 파라미터의 타입이 올바르지 않습니다: %s", spec.Name, spec.Type)
+		}
+		if spec.Pattern != "" {
+			if _, err := regexp.Compile(spec.Pattern); err != nil {
+				return fmt.Errorf("%s 파라미터의 pattern이 올바르지 않습니다: %w", spec.Name, err)
+			}
+		}
+		switch spec.Format {
+		case "", FormatDNSLabel, FormatDNSSubdomain, FormatContainerID, FormatGitRef, FormatGitURL, FormatHost, FormatKubeadmJoin:
+		default:
+			return fmt.Errorf("%s 파라미터의 format이 올바르지 않습니다: %s", spec.Name, spec.Format)
+		}
+	}
+	return nil
+}
+
+// Apply는 파라미터를 스키마로 검증하고 기본값과 타입 변환을 적용한 새 맵을 반환합니다
+// 모든 파라미터의 오류를 모아 ParamErrors로 반환합니다
+func (s ParamSchema) Apply(params map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(params)+len(s))
+	for k, v := range params {
+		result[k] = v
+	}
+
+	var errs ParamErrors
+	for _, spec := range s {
+		value, exists := result[spec.Name]
+		if !exists || value == nil || value == "" {
+			if spec.Default != nil {
+				result[spec.Name] = spec.Default
+			} else if spec.Required {
+				errs = append(errs, ParamError{Param: spec.Name, Message: "필수 파라미터입니다"})
+			}
+			continue
+		}
+
+		converted, err := spec.convert(value)
+		if err != nil {
+			errs = append(errs, ParamError{Param: spec.Name, Message: err.Error()})
+			continue
+		}
+		result[spec.Name] = converted
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return result, nil
+}
+
+// convert는 값을 파라미터 타입으로 변환하고 enum, pattern 조건을 확인합니다
+func (p ParamSpec) convert(value interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParamString:
+		var str string
+		switch v := value.(type) {
+		case string:
+			str = v
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("문자열이어야 합니다")
+			}
+			str = strconv.FormatInt(int64(v), 10)
+		case int:
+			str = strconv.Itoa(v)
+		default:
+			return nil, fmt.Errorf("문자열이어야 합니다")
+		}
+		return p.checkString(str)
+	case ParamInt:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("정수여야 합니다")
+	case ParamNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("숫자여야 합니다")
+	case ParamBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("true 또는 false여야 합니다")
+	case ParamObject:
+		if v, ok := value.(map[string]interface{}); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("객체여야 합니다")
+	case ParamArray:
+		if v, ok := value.([]interface{}); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("배열이어야 합니다")
+	case ParamHops:
+		hops, ok := value.([]interface{})
+		if !ok || len(hops) == 0 {
+			return nil, fmt.Errorf("hop 배열이어야 합니다")
+		}
+		for i, hop := range hops {
+			hopMap, ok := hop.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%d번째 hop이 객체가 아닙니다", i+1)
+			}
+			if host, _ := hopMap["host"].(string); host == "" {
+				return nil, fmt.Errorf("%d번째 hop에 host가 없습니다", i+1)
+			}
+		}
+		return hops, nil
+	}
+	return nil, fmt.Errorf("알 수 없는 파라미터 타입입니다: %s", p.Type)
+}
+
+// checkString은 문자열 값의 enum, pattern, format 조건을 확인합니다
+func (p ParamSpec) checkString(value string) (interface{}, error) {
+	if len(p.Enum) > 0 {
+		for _, allowed := range p.Enum {
+			if strings.EqualFold(value, allowed) {
+				return allowed, nil
+			}
+		}
+		return nil, fmt.Errorf("다음 중 하나여야 합니다: %s", strings.Join(p.Enum, ", "))
+	}
+	if p.Pattern != "" && !regexp.MustCompile(p.Pattern).MatchString(value) {
+		return nil, fmt.Errorf("형식이 올바르지 않습니다 (%s)", p.Pattern)
+	}
+	if p.Format != "" {
+		if err := ValidateFormat(p.Format, value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// ActionInfo는 액션 탐색 API에서 반환하는 액션 정보입니다
+type ActionInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Params      ParamSchema `json:"params"`
+	Steps       bool        `json:"steps,omitempty"`       // 단계 템플릿 여부 (롤백 지원)
+	RunAsRoot   bool        `json:"run_as_root,omitempty"` // 관리자 권한 실행 여부
+}
+
+// DescribeActions는 등록된 모든 액션의 정보를 이름순으로 반환합니다
+func (cm *CommandManager) DescribeActions() []ActionInfo {
+	actions := cm.GetRegisteredActions()
+	sort.Strings(actions)
+
+	infos := make([]ActionInfo, 0, len(actions))
+	for _, action := range actions {
+		info, _ := cm.DescribeAction(action)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// DescribeAction은 액션 하나의 정보를 반환합니다. 등록되지 않은 액션이면 false를 반환합니다
+func (cm *CommandManager) DescribeAction(action string) (ActionInfo, bool) {
+	template, exists := cm.commandMap[action]
+	if !exists {
+		return ActionInfo{}, false
+	}
+	params := template.Params
+	if params == nil {
+		params = ParamSchema{}
+	}
+	return ActionInfo{
+		Name:        action,
+		Description: template.Description,
+		Params:      params,
+		Steps:       template.StepsFunc != nil,
+		RunAsRoot:   template.RunAsRoot,
+	}, true
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParamSchemaApply(t *testing.T) {
+	schema := ParamSchema{
+		{Name: "server_id", Type: ParamInt, Required: true},
+		{Name: "type", Type: ParamString, Enum: []string{"ha", "master", "worker"}},
+		{Name: "port", Type: ParamString, Default: "6443", Pattern: `^[0-9]{1,5}$`},
+		{Name: "force", Type: ParamBool},
+	}
+
+	params, err := schema.Apply(map[string]interface{}{
+		"server_id": float64(3), // JSON 숫자
+		"type":      "Master",
+		"force":     "true",
+		"hops":      []interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("Apply 실패: %v", err)
+	}
+	if params["server_id"] != 3 || params["type"] != "master" || params["port"] != "6443" || params["force"] != true {
+		t.Errorf("변환 결과가 올바르지 않습니다: %+v", params)
+	}
+	if _, ok := params["hops"]; !ok {
+		t.Errorf("스키마에 없는 파라미터는 그대로 전달되어야 합니다")
+	}
+
+	_, err = schema.Apply(map[string]interface{}{
+		"type": "etcd",
+		"port": "64a3",
+	})
+	var paramErrs ParamErrors
+	if !errors.As(err, &paramErrs) || !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("ParamErrors가 필요합니다: %v", err)
+	}
+	if len(paramErrs) != 3 {
+		t.Errorf("server_id, type, port 오류 3개가 필요합니다: %v", paramErrs)
+	}
+}
+
+func TestPrepareActionValidatesSchema(t *testing.T) {
+	cm := NewCommandManager()
+	RegisterKubernetesCommands(cm)
+
+	_, err := cm.PrepareAction(ActionJoinWorker, map[string]interface{}{"server_name": "worker-1"})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("필수 파라미터 누락은 ErrInvalidParams여야 합니다: %v", err)
+	}
+
+	info, ok := cm.DescribeAction(ActionGetNodeStatus)
+	if !ok || len(info.Params) == 0 || info.Params[0].Name != "type" {
+		t.Errorf("getNodeStatus 스키마가 필요합니다: %+v", info)
+	}
+}
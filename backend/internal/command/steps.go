@@ -0,0 +1,415 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// Step은 이름이 있는 선언적 실행 단계입니다
+// 단계는 순서대로 실행되며, 실패하면 이미 실행된 단계의 Rollback을 역순으로 실행합니다
+type Step struct {
+	Name string
+	// Target은 단계를 실행할 대상 이름입니다. 비어 있으면 CommandTarget.Hops, 아니면 CommandTarget.Targets[Target]에서 실행합니다
+	Target   string
+	Commands []string
+	// Check는 멱등성 확인 명령어입니다. 모두 종료 코드 0이면 이미 적용된 것으로 보고 단계를 건너뜁니다
+	Check   []string
+	Success StepSuccess
+	Retry   RetryPolicy
+	// Timeout은 시도 한 번의 제한 시간입니다. 0이면 CommandManager의 명령어 타임아웃을 사용합니다
+	Timeout time.Duration
+	// RunAsRoot가 true이면 Check, Commands, Rollback을 모두 관리자 권한으로 실행합니다
+	RunAsRoot bool
+	// Rollback은 이 단계 또는 이후 단계가 실패했을 때 실행하는 보상 명령어입니다
+	// 단계가 일부만 적용된 상태에서 실패할 수 있으므로 실패한 단계 자신의 Rollback도 실행되며, 여러 번 실행해도 안전해야 합니다
+	Rollback []string
+}
+
+// StepSuccess는 단계의 성공 조건입니다
+type StepSuccess struct {
+	// ExitCodes는 성공으로 보는 종료 코드 목록입니다. 비어 있으면 0만 성공입니다
+	ExitCodes []int
+	// OutputPattern은 단계 표준 출력 전체가 일치해야 하는 정규식입니다. 비어 있으면 검사하지 않습니다
+	OutputPattern string
+}
+
+// RetryPolicy는 단계 실패 시 재시도 정책입니다
+type RetryPolicy struct {
+	// Attempts는 최대 시도 횟수입니다. 0 또는 1이면 재시도하지 않습니다
+	Attempts int
+	// Delay는 다음 시도까지 대기 시간입니다
+	Delay time.Duration
+	// ExitCodes가 있으면 해당 종료 코드로 실패한 경우에만 재시도합니다. 비어 있으면 모든 실패에서 재시도합니다
+	ExitCodes []int
+}
+
+// StepStatus는 단계 실행 결과 상태입니다
+type StepStatus string
+
+const (
+	StepSucceeded      StepStatus = "succeeded"
+	StepSkipped        StepStatus = "skipped" // Check로 이미 적용된 것이 확인되어 건너뜀
+	StepFailed         StepStatus = "failed"
+	StepNotRun         StepStatus = "not_run"         // 앞 단계가 실패하여 실행하지 않음
+	StepRolledBack     StepStatus = "rolled_back"     // 실행 후 롤백됨
+	StepRollbackFailed StepStatus = "rollback_failed" // 롤백 명령어가 실패함
+)
+
+// StepResult는 단계 하나의 실행 결과입니다
+type StepResult struct {
+	Name     string              `json:"name"`
+	Target   string              `json:"target,omitempty"`
+	Status   StepStatus          `json:"status"`
+	Attempts int                 `json:"attempts"`
+	Results  []ssh.CommandResult `json:"results,omitempty"`  // 마지막 시도의 명령어 결과
+	Rollback []ssh.CommandResult `json:"rollback,omitempty"` // 롤백 명령어 결과
+	Error    string              `json:"error,omitempty"`
+}
+
+// StepError는 단계 실행 실패 오류입니다. 원인 오류를 감싸므로 errors.Is로 ssh 오류 종류를 확인할 수 있습니다
+type StepError struct {
+	Step        string
+	Err         error
+	RollbackErr error // 롤백 중 발생한 오류 (없으면 nil)
+}
+
+func (e *StepError) Error() string {
+	msg := fmt.Sprintf("%s 단계 실패: %v", e.Step, e.Err)
+	if e.RollbackErr != nil {
+		msg += fmt.Sprintf(" (롤백 실패: %v)", e.RollbackErr)
+	}
+	return msg
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// stepRetryExitCode는 retry 정책에서 "아직 진행 중"을 나타낼 때 관례적으로 사용하는 종료 코드입니다 (EX_TEMPFAIL)
+const stepRetryExitCode = 75
+
+// ValidateSteps는 단계 정의가 올바른지 확인합니다
+func ValidateSteps(steps []Step) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("실행할 단계가 없습니다")
+	}
+	names := make(map[string]bool, len(steps))
+	for i, step := range steps {
+		if step.Name == "" {
+			return fmt.Errorf("%d번째 단계의 이름이 없습니다", i+1)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("단계 이름이 중복되었습니다: %s", step.Name)
+		}
+		names[step.Name] = true
+		if len(step.Commands) == 0 {
+			return fmt.Errorf("%s 단계에 명령어가 없습니다", step.Name)
+		}
+		if step.Success.OutputPattern != "" {
+			if _, err := regexp.Compile(step.Success.OutputPattern); err != nil {
+				return fmt.Errorf("%s 단계의 출력 조건이 올바르지 않습니다: %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// StepCommands는 단계의 실행 명령어를 순서대로 펼쳐 반환합니다 (Check, Rollback 제외)
+func StepCommands(steps []Step) []string {
+	var commands []string
+	for _, step := range steps {
+		commands = append(commands, step.commands(step.Commands)...)
+	}
+	return commands
+}
+
+// commands는 RunAsRoot 설정을 적용한 명령어 목록을 반환합니다
+func (s Step) commands(commands []string) []string {
+	if !s.RunAsRoot {
+		return commands
+	}
+	rootCommands := make([]string, len(commands))
+	for i, cmd := range commands {
+		rootCommands[i] = ssh.AsRoot(cmd)
+	}
+	return rootCommands
+}
+
+// PrepareSteps는 단계 템플릿으로 등록된 액션의 파라미터를 검증하고 실행할 단계를 준비합니다
+func (cm *CommandManager) PrepareSteps(action string, params map[string]interface{}) ([]Step, error) {
+	template, exists := cm.commandMap[action]
+	if !exists {
+		return nil, fmt.Errorf("지원하지 않는 액션입니다: %s", action)
+	}
+	if template.StepsFunc == nil {
+		return nil, fmt.Errorf("단계 템플릿이 아닌 액션입니다: %s", action)
+	}
+	params, err := validateParams(template, params)
+	if err != nil {
+		return nil, err
+	}
+	return cm.buildSteps(template, params)
+}
+
+// buildSteps는 템플릿의 StepsFunc로 단계를 만들고 정의를 검증합니다
+func (cm *CommandManager) buildSteps(template CommandTemplate, params map[string]interface{}) ([]Step, error) {
+	steps, err := template.StepsFunc(params)
+	if err != nil {
+		log.Printf("[CommandManager] 오류: 단계 준비 실패: %v", err)
+		return nil, fmt.Errorf("명령어 준비 실패: %w", err)
+	}
+	if err := ValidateSteps(steps); err != nil {
+		return nil, fmt.Errorf("단계 정의 오류: %w", err)
+	}
+	return steps, nil
+}
+
+// executeStepsAction은 단계 템플릿 액션을 실행하고 각 단계 마지막 시도의 명령어 결과를 순서대로 반환합니다
+func (cm *CommandManager) executeStepsAction(ctx context.Context, action string, params map[string]interface{}, target *CommandTarget, handler ssh.OutputHandler) ([]ssh.CommandResult, error) {
+	steps, err := cm.PrepareSteps(action, params)
+	if err != nil {
+		log.Printf("[CommandManager] 액션 %s 단계 준비 실패: %v", action, err)
+		return nil, err
+	}
+
+	files, err := cm.PrepareFiles(action, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		if err := cm.UploadFiles(ctx, target, files); err != nil {
+			log.Printf("[CommandManager] 액션 %s 파일 업로드 실패: %v", action, err)
+			return nil, err
+		}
+	}
+
+	startTime := time.Now()
+	reports, err := cm.RunSteps(ctx, target, steps, handler)
+	if err != nil {
+		log.Printf("[CommandManager] 액션 %s 실행 실패 (소요시간: %v): %v", action, time.Since(startTime), err)
+		return nil, err
+	}
+
+	var results []ssh.CommandResult
+	for _, report := range reports {
+		results = append(results, report.Results...)
+	}
+	log.Printf("[CommandManager] 액션 %s 실행 성공 (소요시간: %v): %d개 단계", action, time.Since(startTime), len(reports))
+	return results, nil
+}
+
+// RunSteps는 단계를 순서대로 실행합니다
+// 단계가 실패하면 실패한 단계와 이미 실행된 단계의 Rollback을 역순으로 실행하고 StepError를 반환합니다
+// Check로 건너뛰었거나 명령어를 실행하기 전에 실패한 단계는 이번 실행에서 적용한 것이 없으므로 롤백하지 않습니다
+// 롤백은 ctx가 취소되어도 끝까지 실행됩니다
+func (cm *CommandManager) RunSteps(ctx context.Context, target *CommandTarget, steps []Step, handler ssh.OutputHandler) ([]StepResult, error) {
+	if err := ValidateSteps(steps); err != nil {
+		return nil, fmt.Errorf("단계 정의 오류: %w", err)
+	}
+
+	reports := make([]StepResult, len(steps))
+	for i, step := range steps {
+		reports[i] = StepResult{Name: step.Name, Target: step.Target, Status: StepNotRun}
+	}
+
+	for i, step := range steps {
+		hops, err := target.stepHops(step.Target)
+		if err == nil {
+			log.Printf("[CommandManager] 단계 실행: %s (%d/%d)", step.Name, i+1, len(steps))
+			err = cm.runStep(ctx, hops, step, &reports[i], handler)
+		}
+		if err == nil {
+			continue
+		}
+
+		log.Printf("[CommandManager] 단계 %s 실패: %v", step.Name, err)
+		reports[i].Status = StepFailed
+		reports[i].Error = err.Error()
+		rollbackErr := cm.rollbackSteps(context.WithoutCancel(ctx), target, steps[:i+1], reports[:i+1], handler)
+		return reports, &StepError{Step: step.Name, Err: err, RollbackErr: rollbackErr}
+	}
+	return reports, nil
+}
+
+// stepHops는 단계 대상 이름에 해당하는 hop 목록을 반환합니다
+func (ct *CommandTarget) stepHops(name string) ([]ssh.HopConfig, error) {
+	if ct == nil {
+		return nil, fmt.Errorf("대상 서버 정보가 없습니다")
+	}
+	hops := ct.Hops
+	if name != "" {
+		hops = ct.Targets[name]
+	}
+	if len(hops) == 0 {
+		if name == "" {
+			return nil, fmt.Errorf("대상 서버 정보가 없습니다")
+		}
+		return nil, fmt.Errorf("%s 대상 서버 정보가 없습니다", name)
+	}
+	return hops, nil
+}
+
+// runStep은 단계 하나를 Check, 재시도 정책과 함께 실행합니다
+func (cm *CommandManager) runStep(ctx context.Context, hops []ssh.HopConfig, step Step, report *StepResult, handler ssh.OutputHandler) error {
+	if len(step.Check) > 0 {
+		results, err := cm.runStepCommands(ctx, hops, step.commands(step.Check), step.Timeout, StepSuccess{}, handler)
+		if err != nil {
+			return err
+		}
+		if len(results) == len(step.Check) && results[len(results)-1].ExitCode == 0 {
+			log.Printf("[CommandManager] 단계 %s는 이미 적용되어 건너뜁니다", step.Name)
+			report.Status = StepSkipped
+			return nil
+		}
+	}
+
+	attempts := step.Retry.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		report.Attempts = attempt
+		attemptCtx := ctx
+		cancel := func() {}
+		if step.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, step.Timeout)
+		}
+		results, err := cm.runStepCommands(attemptCtx, hops, step.commands(step.Commands), step.Timeout, step.Success, handler)
+		cancel()
+		report.Results = results
+		if err == nil {
+			err = step.Success.check(hops[len(hops)-1].Host, results)
+		}
+		if err == nil {
+			report.Status = StepSucceeded
+			return nil
+		}
+
+		if attempt >= attempts || !step.Retry.retryable(err) || ctx.Err() != nil {
+			return err
+		}
+		log.Printf("[CommandManager] 단계 %s 재시도 (%d/%d): %v", step.Name, attempt+1, attempts, err)
+		select {
+		case <-time.After(step.Retry.Delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// runStepCommands는 명령어를 하나씩 실행하고, success에서 허용하지 않는 종료 코드가 나오면 나머지 명령어를 실행하지 않습니다
+func (cm *CommandManager) runStepCommands(ctx context.Context, hops []ssh.HopConfig, commands []string, timeout time.Duration, success StepSuccess, handler ssh.OutputHandler) ([]ssh.CommandResult, error) {
+	timeoutMs := cm.commandTimeout
+	if timeout > 0 {
+		timeoutMs = int(timeout.Milliseconds())
+	}
+
+	var results []ssh.CommandResult
+	for _, cmd := range commands {
+		result, err := cm.sshUtils.ExecuteCommandsStream(ctx, hops, []string{cmd}, timeoutMs, handler)
+		results = append(results, result...)
+		if err != nil {
+			return results, err
+		}
+		if len(result) > 0 && !success.exitCodeAllowed(result[0].ExitCode) {
+			break
+		}
+	}
+	return results, nil
+}
+
+// rollbackSteps는 실행된 단계의 Rollback을 역순으로 실행합니다
+// 롤백 중 하나가 실패해도 나머지 롤백은 계속 실행하며, 발생한 오류를 모아 반환합니다
+func (cm *CommandManager) rollbackSteps(ctx context.Context, target *CommandTarget, steps []Step, reports []StepResult, handler ssh.OutputHandler) error {
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		report := &reports[i]
+		if len(step.Rollback) == 0 || report.Attempts == 0 || (report.Status != StepSucceeded && report.Status != StepFailed) {
+			continue
+		}
+
+		log.Printf("[CommandManager] 단계 롤백: %s", step.Name)
+		hops, err := target.stepHops(step.Target)
+		if err == nil {
+			report.Rollback, err = cm.runStepCommands(ctx, hops, step.commands(step.Rollback), step.Timeout, StepSuccess{}, handler)
+		}
+		if err == nil {
+			err = ssh.CheckResults(hops[len(hops)-1].Host, report.Rollback)
+		}
+		if err != nil {
+			log.Printf("[CommandManager] 단계 %s 롤백 실패: %v", step.Name, err)
+			if report.Status != StepFailed {
+				report.Status = StepRollbackFailed
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, err))
+			continue
+		}
+		if report.Status == StepSucceeded {
+			report.Status = StepRolledBack
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check는 명령어 결과가 성공 조건을 만족하는지 확인합니다
+func (s StepSuccess) check(host string, results []ssh.CommandResult) error {
+	var stdout strings.Builder
+	for _, result := range results {
+		if !s.exitCodeAllowed(result.ExitCode) {
+			return ssh.ExitError(host, result)
+		}
+		stdout.WriteString(result.Output)
+	}
+
+	if s.OutputPattern != "" && !regexp.MustCompile(s.OutputPattern).MatchString(stdout.String()) {
+		return ssh.SSHError{
+			Type:    ssh.RemoteCommandFailed,
+			Message: fmt.Sprintf("명령어 출력이 성공 조건(%s)과 일치하지 않습니다", s.OutputPattern),
+			Host:    host,
+		}
+	}
+	return nil
+}
+
+// exitCodeAllowed는 종료 코드가 성공으로 허용되는지 확인합니다
+func (s StepSuccess) exitCodeAllowed(code int) bool {
+	if len(s.ExitCodes) == 0 {
+		return code == 0
+	}
+	for _, allowed := range s.ExitCodes {
+		if code == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable은 실패한 시도를 다시 실행할지 결정합니다
+// SSH 연결 오류처럼 종료 코드가 없는 실패는 ExitCodes가 비어 있을 때만 재시도합니다
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, ssh.ErrValidation) || errors.Is(err, ssh.ErrCommandCanceled) || errors.Is(err, ssh.ErrAuthenticationFailed) || errors.Is(err, ssh.ErrHostKeyMismatch) {
+		return false
+	}
+	if len(p.ExitCodes) == 0 {
+		return true
+	}
+
+	var sshErr ssh.SSHError
+	if !errors.As(err, &sshErr) || !errors.Is(err, ssh.ErrRemoteCommandFailed) {
+		return false
+	}
+	for _, code := range p.ExitCodes {
+		if sshErr.ExitCode == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+func TestRunStepsRetryAndSkip(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	server.Handle("already-done", sshtest.Response{})
+	server.Handle("apply", sshtest.Response{Stdout: "applied\n"})
+	var polls int32
+	server.HandleFunc(func(cmd string) (sshtest.Response, bool) {
+		if cmd != "poll" {
+			return sshtest.Response{}, false
+		}
+		if atomic.AddInt32(&polls, 1) < 3 {
+			return sshtest.Response{ExitCode: stepRetryExitCode}, true
+		}
+		return sshtest.Response{Stdout: "ready\n"}, true
+	})
+
+	steps := []Step{
+		{Name: "skip", Check: []string{"already-done"}, Commands: []string{"never-run"}},
+		{Name: "apply", Check: []string{"missing-check"}, Commands: []string{"apply"}, Success: StepSuccess{OutputPattern: `^applied`}},
+		{Name: "poll", Commands: []string{"poll"}, Retry: RetryPolicy{Attempts: 5, Delay: time.Millisecond, ExitCodes: []int{stepRetryExitCode}}},
+	}
+
+	cm := NewCommandManager()
+	reports, err := cm.RunSteps(context.Background(), &CommandTarget{Hops: []ssh.HopConfig{server.Hop()}}, steps, nil)
+	if err != nil {
+		t.Fatalf("RunSteps 실패: %v", err)
+	}
+
+	want := []struct {
+		status   StepStatus
+		attempts int
+	}{{StepSkipped, 0}, {StepSucceeded, 1}, {StepSucceeded, 3}}
+	for i, w := range want {
+		if reports[i].Status != w.status || reports[i].Attempts != w.attempts {
+			t.Errorf("%s 단계 = %s (%d회), want %s (%d회)", reports[i].Name, reports[i].Status, reports[i].Attempts, w.status, w.attempts)
+		}
+	}
+	for _, cmd := range server.Commands() {
+		if cmd == "never-run" {
+			t.Error("Check를 통과한 단계의 명령어가 실행됨")
+		}
+	}
+}
+
+func TestRunStepsRollbackInReverse(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	server := sshtest.NewServer(t)
+	lb := sshtest.NewServer(t)
+	for _, cmd := range []string{"create-a", "undo-a", "undo-b", "undo-c", "b-done", "c-part"} {
+		server.Handle(cmd, sshtest.Response{})
+	}
+	lb.Handle("register", sshtest.Response{})
+	lb.Handle("deregister", sshtest.Response{})
+	server.Handle("c-fail", sshtest.Response{Stderr: "etcd join failed\n", ExitCode: 1})
+
+	steps := []Step{
+		{Name: "lb", Target: "lb", Commands: []string{"register"}, Rollback: []string{"deregister"}},
+		{Name: "a", Commands: []string{"create-a"}, Rollback: []string{"undo-a"}},
+		{Name: "b", Check: []string{"b-done"}, Commands: []string{"create-b"}, Rollback: []string{"undo-b"}},
+		{Name: "c", Commands: []string{"c-part", "c-fail", "c-never"}, Rollback: []string{"undo-c"}},
+		{Name: "d", Commands: []string{"create-d"}, Rollback: []string{"undo-d"}},
+	}
+
+	cm := NewCommandManager()
+	target := &CommandTarget{
+		Hops:    []ssh.HopConfig{server.Hop()},
+		Targets: map[string][]ssh.HopConfig{"lb": {lb.Hop()}},
+	}
+	reports, err := cm.RunSteps(context.Background(), target, steps, nil)
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "c" || stepErr.RollbackErr != nil {
+		t.Fatalf("오류 = %v, want c 단계 StepError", err)
+	}
+	if !errors.Is(err, ssh.ErrRemoteCommandFailed) {
+		t.Errorf("원격 명령어 실패로 분류되어야 합니다: %v", err)
+	}
+
+	// 실패한 단계부터 역순으로 롤백, 건너뛴 단계와 실행하지 않은 단계는 롤백하지 않음
+	got := strings.Join(server.Commands(), ",")
+	if want := "create-a,b-done,c-part,c-fail,undo-c,undo-a"; got != want {
+		t.Errorf("실행된 명령어 = %s, want %s", got, want)
+	}
+	if got := strings.Join(lb.Commands(), ","); got != "register,deregister" {
+		t.Errorf("로드 밸런서 명령어 = %s", got)
+	}
+
+	statuses := make([]string, len(reports))
+	for i, report := range reports {
+		statuses[i] = string(report.Status)
+	}
+	if got, want := strings.Join(statuses, ","), "rolled_back,rolled_back,skipped,failed,not_run"; got != want {
+		t.Errorf("단계 상태 = %s, want %s", got, want)
+	}
+}
+
+func TestValidateSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Step
+	}{
+		{"빈 단계", nil},
+		{"이름 없음", []Step{{Commands: []string{"true"}}}},
+		{"중복 이름", []Step{{Name: "a", Commands: []string{"true"}}, {Name: "a", Commands: []string{"true"}}}},
+		{"명령어 없음", []Step{{Name: "a"}}},
+		{"잘못된 정규식", []Step{{Name: "a", Commands: []string{"true"}, Success: StepSuccess{OutputPattern: "("}}}},
+	}
+	for _, tt := range tests {
+		if err := ValidateSteps(tt.steps); err == nil {
+			t.Errorf("%s: 오류가 발생해야 합니다", tt.name)
+		}
+	}
+
+	steps, err := JoinMasterSteps(map[string]interface{}{"server_name": "m2", "master_ip": "192.168.0.12"})
+	if err != nil || ValidateSteps(steps) != nil || len(steps) != 3 || steps[0].Target != JoinMasterLBTarget {
+		t.Errorf("JoinMasterSteps = %+v, %v", steps, err)
+	}
+}
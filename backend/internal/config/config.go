@@ -0,0 +1,416 @@
+// Package config는 서버 설정을 환경 변수와 YAML 파일에서 읽어 검증합니다
+//
+// 설정 값의 우선순위는 환경 변수 > YAML 파일(CONFIG_FILE) > 기본값입니다.
+// 비밀값(DB 비밀번호 등)은 코드에 두지 않으며, 환경 변수나 파일로만 지정합니다
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k8scontrol/backend/internal/auth"
+	"github.com/k8scontrol/backend/internal/vault"
+	"github.com/k8scontrol/backend/pkg/redact"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFile은 YAML 설정 파일 경로를 지정하는 환경 변수입니다
+const EnvFile = "CONFIG_FILE"
+
+// Config는 서버 전체 설정입니다
+type Config struct {
+	Env         string            `yaml:"env" json:"env"` // development 또는 production (prod, dev도 허용)
+	Server      ServerConfig      `yaml:"server" json:"server"`
+	Database    DatabaseConfig    `yaml:"database" json:"database"`
+	Auth        AuthConfig        `yaml:"auth" json:"auth"`
+	Credentials CredentialsConfig `yaml:"credentials" json:"credentials"`
+	SSH         SSHConfig         `yaml:"ssh" json:"ssh"`
+	Concurrency ConcurrencyConfig `yaml:"concurrency" json:"concurrency"`
+	Features    Features          `yaml:"features" json:"features"`
+}
+
+// ServerConfig는 HTTP 서버 설정입니다
+type ServerConfig struct {
+	ListenAddr  string    `yaml:"listen_addr" json:"listen_addr"`
+	CORSOrigins []string  `yaml:"cors_origins" json:"cors_origins"` // API와 WebSocket 연결을 허용하는 프론트엔드 Origin
+	TLS         TLSConfig `yaml:"tls" json:"tls"`
+}
+
+// TLSConfig는 HTTPS 인증서 설정입니다. 둘 다 비어 있으면 HTTP로 실행합니다
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+}
+
+// Enabled는 TLS 인증서가 설정되었는지 확인합니다
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// DatabaseConfig는 DB 연결 설정입니다
+// Driver가 mysql이면 MariaDB에 연결하며, DSN을 지정하면 Host, Port, User, Password, Name 대신 DSN을 그대로 사용합니다
+// Driver가 sqlite이면 외부 DB 없이 Path의 SQLite 파일을 사용합니다
+type DatabaseConfig struct {
+	Driver          string   `yaml:"driver" json:"driver"` // mysql 또는 sqlite
+	Path            string   `yaml:"path" json:"path,omitempty"`
+	DSN             string   `yaml:"dsn" json:"dsn,omitempty"`
+	Host            string   `yaml:"host" json:"host"`
+	Port            int      `yaml:"port" json:"port"`
+	User            string   `yaml:"user" json:"user"`
+	Password        string   `yaml:"password" json:"password"`
+	Name            string   `yaml:"name" json:"name"`
+	MaxOpenConns    int      `yaml:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" json:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
+	AutoMigrate     bool     `yaml:"auto_migrate" json:"auto_migrate"` // 서버 시작 시 스키마 마이그레이션 적용 (끄면 migrate 명령어로 적용)
+}
+
+// database.driver 값
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// AuthConfig는 로그인 토큰과 첫 관리자 계정 설정입니다
+type AuthConfig struct {
+	JWTSecret     string `yaml:"jwt_secret" json:"jwt_secret"`         // 토큰 서명 키 (production은 필수, development에서 비어 있으면 시작할 때마다 무작위 키 사용)
+	AdminUsername string `yaml:"admin_username" json:"admin_username"` // 등록된 사용자가 없을 때 만들 첫 관리자
+	AdminPassword string `yaml:"admin_password" json:"admin_password"`
+}
+
+// CredentialsConfig는 자격 증명 저장소의 마스터 키 설정입니다
+// production은 둘 중 하나가 필수이며, development에서 둘 다 비어 있으면 자격 증명 저장소를 사용하지 않습니다
+type CredentialsConfig struct {
+	MasterKey     string `yaml:"master_key" json:"master_key"`           // base64 키, 쉼표로 구분하면 첫 키로 암호화하고 나머지는 복호화에만 사용
+	MasterKeyFile string `yaml:"master_key_file" json:"master_key_file"` // 한 줄에 키 하나인 파일
+}
+
+// minJWTSecretLength는 production에서 지정해야 하는 JWT 서명 키의 최소 길이입니다 (HS256 키 길이)
+// 레플리카마다 무작위 키를 쓰면 다른 레플리카가 발급한 토큰을 거부하므로 production에서는 키를 반드시 지정합니다
+const minJWTSecretLength = 32
+
+// SSHConfig는 원격 명령어 실행과 SSH 연결 풀 설정입니다
+type SSHConfig struct {
+	CommandTimeout       Duration `yaml:"command_timeout" json:"command_timeout"` // 액션 명령어 하나의 실행 타임아웃
+	ConnectTimeout       Duration `yaml:"connect_timeout" json:"connect_timeout"` // 타임아웃을 지정하지 않은 연결, 실행의 기본값
+	PoolIdleTimeout      Duration `yaml:"pool_idle_timeout" json:"pool_idle_timeout"`
+	KeepAliveInterval    Duration `yaml:"keepalive_interval" json:"keepalive_interval"`
+	MaxSessionsPerClient int      `yaml:"max_sessions_per_client" json:"max_sessions_per_client"`
+	AgentSocket          string   `yaml:"agent_socket" json:"agent_socket"` // 홉 인증에 사용할 ssh-agent 소켓 ("env"이면 SSH_AUTH_SOCK, 비어 있으면 사용 안 함)
+}
+
+// ConcurrencyConfig는 여러 서버에 대한 병렬 실행 제한입니다
+type ConcurrencyConfig struct {
+	FanOutDefault int `yaml:"fanout_default" json:"fanout_default"` // 요청에 concurrency가 없을 때의 동시 실행 수
+	FanOutMax     int `yaml:"fanout_max" json:"fanout_max"`         // 요청할 수 있는 최대 동시 실행 수
+}
+
+// Features는 기능 플래그입니다
+type Features struct {
+	DryRun    bool `yaml:"dry_run" json:"dry_run"`       // dry_run 요청 허용
+	AsyncJobs bool `yaml:"async_jobs" json:"async_jobs"` // async 요청을 백그라운드 작업으로 실행 (끄면 동기 실행)
+	AuditLog  bool `yaml:"audit_log" json:"audit_log"`   // 인프라 작업 감사 기록
+	Swagger   bool `yaml:"swagger" json:"swagger"`       // /swagger 문서 제공
+}
+
+// Default는 기본 설정을 반환합니다. DB 계정은 기본값이 없으므로 반드시 지정해야 합니다
+func Default() *Config {
+	return &Config{
+		Env: "development",
+		Server: ServerConfig{
+			ListenAddr:  ":8080",
+			CORSOrigins: []string{"http://localhost:3000", "https://kc.mipllab.com", "http://kc.mipllab.com"},
+		},
+		Database: DatabaseConfig{
+			Driver:          DriverMySQL,
+			Host:            "localhost",
+			Port:            3306,
+			Name:            "k8scontrol",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration{5 * time.Minute},
+			AutoMigrate:     true,
+		},
+		SSH: SSHConfig{
+			CommandTimeout:       Duration{30 * time.Second},
+			ConnectTimeout:       Duration{120 * time.Second},
+			PoolIdleTimeout:      Duration{5 * time.Minute},
+			KeepAliveInterval:    Duration{30 * time.Second},
+			MaxSessionsPerClient: 8,
+		},
+		Concurrency: ConcurrencyConfig{
+			FanOutDefault: 10,
+			FanOutMax:     50,
+		},
+		Features: Features{
+			DryRun:    true,
+			AsyncJobs: true,
+			AuditLog:  true,
+			Swagger:   true,
+		},
+	}
+}
+
+// Load는 기본값에 YAML 파일(path가 비어 있으면 CONFIG_FILE)과 환경 변수를 차례로 적용하고 검증한 설정을 반환합니다
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		path = os.Getenv(EnvFile)
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("설정 파일을 읽을 수 없습니다: %w", err)
+		}
+		// 오타로 설정이 무시되지 않도록 알 수 없는 항목은 오류로 처리
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("설정 파일 형식 오류 (%s): %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv는 설정된 환경 변수로 값을 덮어씁니다. lookup은 os.LookupEnv와 같습니다
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	str := func(key string, dest *string) {
+		if v, ok := lookup(key); ok && v != "" {
+			*dest = v
+		}
+	}
+	num := func(key string, dest *int) {
+		if v, ok := lookup(key); ok && v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: 숫자가 아닙니다: %q", key, v))
+				return
+			}
+			*dest = n
+		}
+	}
+	duration := func(key string, dest *Duration) {
+		if v, ok := lookup(key); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: 시간 형식(30s, 5m 등)이 아닙니다: %q", key, v))
+				return
+			}
+			dest.Duration = d
+		}
+	}
+	flag := func(key string, dest *bool) {
+		if v, ok := lookup(key); ok && v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: true 또는 false여야 합니다: %q", key, v))
+				return
+			}
+			*dest = b
+		}
+	}
+
+	str("APP_ENV", &c.Env)
+	// 이전 버전은 APP_ENV=prod도 production으로 처리했으므로 같은 이름으로 맞춤
+	switch c.Env = strings.ToLower(c.Env); c.Env {
+	case "prod":
+		c.Env = "production"
+	case "dev":
+		c.Env = "development"
+	}
+
+	// PORT는 이전 버전과의 호환을 위해 지원 (LISTEN_ADDR이 우선)
+	if port, ok := lookup("PORT"); ok && port != "" {
+		c.Server.ListenAddr = ":" + port
+	}
+	str("LISTEN_ADDR", &c.Server.ListenAddr)
+	if v, ok := lookup("CORS_ORIGINS"); ok && v != "" {
+		c.Server.CORSOrigins = splitList(v)
+	}
+	str("TLS_CERT_FILE", &c.Server.TLS.CertFile)
+	str("TLS_KEY_FILE", &c.Server.TLS.KeyFile)
+
+	str("DB_DRIVER", &c.Database.Driver)
+	str("DB_PATH", &c.Database.Path)
+	str("DB_DSN", &c.Database.DSN)
+	str("DB_HOST", &c.Database.Host)
+	num("DB_PORT", &c.Database.Port)
+	str("DB_USER", &c.Database.User)
+	str("DB_PASSWORD", &c.Database.Password)
+	str("DB_NAME", &c.Database.Name)
+	num("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	num("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	flag("DB_AUTO_MIGRATE", &c.Database.AutoMigrate)
+
+	str("JWT_SECRET", &c.Auth.JWTSecret)
+	str("ADMIN_USERNAME", &c.Auth.AdminUsername)
+	str("ADMIN_PASSWORD", &c.Auth.AdminPassword)
+
+	str(vault.EnvMasterKey, &c.Credentials.MasterKey)
+	str(vault.EnvMasterKeyFile, &c.Credentials.MasterKeyFile)
+
+	duration("SSH_COMMAND_TIMEOUT", &c.SSH.CommandTimeout)
+	duration("SSH_CONNECT_TIMEOUT", &c.SSH.ConnectTimeout)
+	duration("SSH_POOL_IDLE_TIMEOUT", &c.SSH.PoolIdleTimeout)
+	duration("SSH_KEEPALIVE_INTERVAL", &c.SSH.KeepAliveInterval)
+	num("SSH_MAX_SESSIONS_PER_CLIENT", &c.SSH.MaxSessionsPerClient)
+	str("SSH_AGENT_SOCKET", &c.SSH.AgentSocket)
+
+	num("FANOUT_CONCURRENCY", &c.Concurrency.FanOutDefault)
+	num("FANOUT_MAX_CONCURRENCY", &c.Concurrency.FanOutMax)
+
+	flag("FEATURE_DRY_RUN", &c.Features.DryRun)
+	flag("FEATURE_ASYNC_JOBS", &c.Features.AsyncJobs)
+	flag("FEATURE_AUDIT_LOG", &c.Features.AuditLog)
+	flag("FEATURE_SWAGGER", &c.Features.Swagger)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("설정 오류: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Validate는 설정 값이 올바른지 확인하고 잘못된 항목을 모두 모아 반환합니다
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Env == "development" || c.Env == "production", "env는 development 또는 production이어야 합니다: %q", c.Env)
+	check(c.Server.ListenAddr != "", "server.listen_addr이 비어 있습니다")
+	check(len(c.Server.CORSOrigins) > 0, "server.cors_origins가 비어 있습니다")
+	for _, origin := range c.Server.CORSOrigins {
+		check(strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"), "server.cors_origins는 http:// 또는 https://로 시작해야 합니다: %q", origin)
+	}
+	if c.Server.TLS.Enabled() {
+		check(c.Server.TLS.CertFile != "" && c.Server.TLS.KeyFile != "", "server.tls는 cert_file과 key_file을 함께 지정해야 합니다")
+		for _, file := range []string{c.Server.TLS.CertFile, c.Server.TLS.KeyFile} {
+			if file != "" {
+				_, err := os.Stat(file)
+				check(err == nil, "server.tls 파일을 읽을 수 없습니다: %s", file)
+			}
+		}
+	}
+
+	check(c.Database.Driver == DriverMySQL || c.Database.Driver == DriverSQLite, "database.driver는 %s 또는 %s이어야 합니다: %q", DriverMySQL, DriverSQLite, c.Database.Driver)
+	if c.Database.Driver == DriverSQLite {
+		check(c.Database.Path != "", "database.driver가 sqlite이면 database.path(DB_PATH)를 지정해야 합니다")
+	} else if c.Database.DSN == "" {
+		check(c.Database.User != "", "database.user(DB_USER) 또는 database.dsn(DB_DSN)을 지정해야 합니다")
+		check(c.Database.Host != "", "database.host가 비어 있습니다")
+		check(c.Database.Port > 0 && c.Database.Port < 65536, "database.port가 올바르지 않습니다: %d", c.Database.Port)
+		check(c.Database.Name != "", "database.name이 비어 있습니다")
+		check(c.Env != "production" || c.Database.Password != "", "production에서는 database.password(DB_PASSWORD)를 지정해야 합니다")
+	}
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns는 0 이상이어야 합니다")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns는 0 이상이어야 합니다")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns는 max_open_conns 이하여야 합니다")
+	check(c.Database.ConnMaxLifetime.Duration >= 0, "database.conn_max_lifetime은 0 이상이어야 합니다")
+
+	check(c.Env != "production" || len(c.Auth.JWTSecret) >= minJWTSecretLength,
+		"production에서는 auth.jwt_secret(JWT_SECRET)을 %d자 이상으로 지정해야 합니다", minJWTSecretLength)
+	check((c.Auth.AdminUsername == "") == (c.Auth.AdminPassword == ""), "auth.admin_username(ADMIN_USERNAME)과 auth.admin_password(ADMIN_PASSWORD)는 함께 지정해야 합니다")
+	check(c.Auth.AdminPassword == "" || len(c.Auth.AdminPassword) >= auth.MinPasswordLength,
+		"auth.admin_password(ADMIN_PASSWORD)는 %d자 이상이어야 합니다", auth.MinPasswordLength)
+
+	check(c.Credentials.MasterKey == "" || c.Credentials.MasterKeyFile == "", "credentials.master_key와 master_key_file은 하나만 지정해야 합니다")
+	// 저장된 hop과 서비스의 비밀값은 자격 증명 저장소에만 저장하므로 키가 없으면 서버, 서비스 저장이 실패함
+	check(c.Env != "production" || c.Credentials.MasterKey != "" || c.Credentials.MasterKeyFile != "",
+		"production에서는 credentials.master_key(%s) 또는 master_key_file(%s)을 지정해야 합니다", vault.EnvMasterKey, vault.EnvMasterKeyFile)
+	if c.Credentials.MasterKey != "" || c.Credentials.MasterKeyFile != "" {
+		_, err := vault.LoadKeyring(c.Credentials.MasterKey, c.Credentials.MasterKeyFile)
+		check(err == nil, "credentials 마스터 키가 올바르지 않습니다: %v", err)
+	}
+
+	check(c.SSH.CommandTimeout.Duration > 0, "ssh.command_timeout은 0보다 커야 합니다")
+	check(c.SSH.ConnectTimeout.Duration > 0, "ssh.connect_timeout은 0보다 커야 합니다")
+	check(c.SSH.PoolIdleTimeout.Duration > 0, "ssh.pool_idle_timeout은 0보다 커야 합니다")
+	check(c.SSH.KeepAliveInterval.Duration > 0, "ssh.keepalive_interval은 0보다 커야 합니다")
+	check(c.SSH.MaxSessionsPerClient > 0, "ssh.max_sessions_per_client는 0보다 커야 합니다")
+
+	check(c.Concurrency.FanOutDefault > 0, "concurrency.fanout_default는 0보다 커야 합니다")
+	check(c.Concurrency.FanOutMax >= c.Concurrency.FanOutDefault, "concurrency.fanout_max는 fanout_default 이상이어야 합니다")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("설정 오류: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted는 비밀값을 가린 설정 복사본을 반환합니다 (관리자 조회, 로그용)
+func (c *Config) Redacted() Config {
+	redacted := *c
+	redacted.Server.CORSOrigins = append([]string(nil), c.Server.CORSOrigins...)
+	if redacted.Database.Password != "" {
+		redacted.Database.Password = redact.Mask
+	}
+	redacted.Database.DSN = redactDSN(c.Database.DSN)
+	for _, secret := range []*string{&redacted.Auth.JWTSecret, &redacted.Auth.AdminPassword, &redacted.Credentials.MasterKey} {
+		if *secret != "" {
+			*secret = redact.Mask
+		}
+	}
+	return redacted
+}
+
+// redactDSN은 DSN(user:password@tcp(host:port)/db)의 비밀번호를 가립니다
+func redactDSN(dsn string) string {
+	at := strings.LastIndex(dsn, "@")
+	if at < 0 {
+		return dsn
+	}
+	user, _, hasPassword := strings.Cut(dsn[:at], ":")
+	if !hasPassword {
+		return dsn
+	}
+	return user + ":" + redact.Mask + dsn[at:]
+}
+
+// Duration은 YAML과 JSON에서 "30s", "5m" 같은 문자열로 쓰는 시간 값입니다
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalYAML은 time.ParseDuration 형식의 문자열을 읽습니다
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("%d번째 줄: 시간 형식(30s, 5m 등)이 아닙니다: %q", node.Line, node.Value)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalJSON은 시간 값을 "30s" 형식의 문자열로 씁니다
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// splitList는 쉼표로 구분한 목록을 공백을 제거해 나눕니다
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
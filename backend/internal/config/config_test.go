@@ -0,0 +1,241 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k8scontrol/backend/internal/vault"
+	"github.com/k8scontrol/backend/pkg/redact"
+)
+
+// lookupMap은 map을 os.LookupEnv처럼 조회하는 함수를 반환합니다
+func lookupMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFileAndEnv(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+env: production
+server:
+  listen_addr: ":9090"
+database:
+  user: k8s
+  password: from-file
+  conn_max_lifetime: 10m
+ssh:
+  command_timeout: 45s
+features:
+  swagger: false
+`)
+	t.Setenv(EnvFile, path)
+	t.Setenv("DB_PASSWORD", "from-env")
+	t.Setenv("JWT_SECRET", strings.Repeat("j", minJWTSecretLength))
+	t.Setenv(vault.EnvMasterKey, base64.StdEncoding.EncodeToString(make([]byte, vault.KeySize)))
+	t.Setenv("CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Env != "production" || cfg.Server.ListenAddr != ":9090" || cfg.Database.User != "k8s" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	// 환경 변수가 파일보다 우선
+	if cfg.Database.Password != "from-env" {
+		t.Errorf("Password = %q, want from-env", cfg.Database.Password)
+	}
+	if len(cfg.Server.CORSOrigins) != 2 || cfg.Server.CORSOrigins[1] != "https://b.example.com" {
+		t.Errorf("CORSOrigins = %v", cfg.Server.CORSOrigins)
+	}
+	if cfg.Database.ConnMaxLifetime.Duration != 10*time.Minute || cfg.SSH.CommandTimeout.Duration != 45*time.Second {
+		t.Errorf("ConnMaxLifetime, CommandTimeout = %v, %v", cfg.Database.ConnMaxLifetime, cfg.SSH.CommandTimeout)
+	}
+	// 파일에 없는 값은 기본값 유지
+	if cfg.Features.Swagger || !cfg.Features.DryRun || cfg.Database.Port != 3306 {
+		t.Errorf("Features, Port = %+v, %d", cfg.Features, cfg.Database.Port)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"알 수 없는 항목", "database:\n  usr: k8s\n", "usr"},
+		{"시간 형식", "ssh:\n  command_timeout: 30\n", "시간 형식"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFile(t, "config.yaml", tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q 포함", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg := Default()
+	err := cfg.applyEnv(lookupMap(map[string]string{
+		"PORT":                   "9000",
+		"DB_USER":                "k8s",
+		"SSH_CONNECT_TIMEOUT":    "1m",
+		"FANOUT_MAX_CONCURRENCY": "20",
+		"FEATURE_ASYNC_JOBS":     "false",
+		"APP_ENV":                "prod",
+		"JWT_SECRET":             "jwt-secret",
+		"ADMIN_USERNAME":         "admin",
+		"CREDENTIAL_MASTER_KEY":  "bWFzdGVy",
+		"DB_DRIVER":              "sqlite",
+		"DB_PATH":                "/data/k8scontrol.db",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.ListenAddr != ":9000" || cfg.Database.User != "k8s" || cfg.SSH.ConnectTimeout.Duration != time.Minute {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if cfg.Concurrency.FanOutMax != 20 || cfg.Features.AsyncJobs {
+		t.Errorf("FanOutMax, AsyncJobs = %d, %v", cfg.Concurrency.FanOutMax, cfg.Features.AsyncJobs)
+	}
+	// 이전 버전과 같이 APP_ENV=prod는 production
+	if cfg.Env != "production" {
+		t.Errorf("Env = %q, want production", cfg.Env)
+	}
+	if cfg.Auth.JWTSecret != "jwt-secret" || cfg.Auth.AdminUsername != "admin" || cfg.Credentials.MasterKey != "bWFzdGVy" {
+		t.Errorf("Auth, Credentials = %+v, %+v", cfg.Auth, cfg.Credentials)
+	}
+	if cfg.Database.Driver != DriverSQLite || cfg.Database.Path != "/data/k8scontrol.db" {
+		t.Errorf("Driver, Path = %q, %q", cfg.Database.Driver, cfg.Database.Path)
+	}
+
+	// 잘못된 값은 모두 모아 반환
+	err = Default().applyEnv(lookupMap(map[string]string{
+		"DB_PORT":             "abc",
+		"SSH_COMMAND_TIMEOUT": "soon",
+		"FEATURE_SWAGGER":     "maybe",
+	}))
+	if err == nil {
+		t.Fatal("오류가 없습니다")
+	}
+	for _, key := range []string{"DB_PORT", "SSH_COMMAND_TIMEOUT", "FEATURE_SWAGGER"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("오류에 %s가 없습니다: %v", key, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	masterKey := base64.StdEncoding.EncodeToString(make([]byte, vault.KeySize))
+	valid := func() *Config {
+		cfg := Default()
+		cfg.Database.User = "k8s"
+		return cfg
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("기본 설정 검증 실패: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"DB 계정 없음", func(c *Config) { c.Database.User = "" }, "DB_USER"},
+		{"production 비밀번호 없음", func(c *Config) { c.Env = "production" }, "DB_PASSWORD"},
+		{"알 수 없는 환경", func(c *Config) { c.Env = "staging" }, "env"},
+		{"CORS 형식", func(c *Config) { c.Server.CORSOrigins = []string{"example.com"} }, "cors_origins"},
+		{"TLS 키 없음", func(c *Config) { c.Server.TLS.CertFile = "cert.pem" }, "key_file"},
+		{"알 수 없는 DB 드라이버", func(c *Config) { c.Database.Driver = "postgres" }, "database.driver"},
+		{"SQLite 경로 없음", func(c *Config) { c.Database.Driver = DriverSQLite }, "DB_PATH"},
+		{"유휴 연결 수", func(c *Config) { c.Database.MaxIdleConns = 100 }, "max_idle_conns"},
+		{"SSH 타임아웃", func(c *Config) { c.SSH.CommandTimeout = Duration{} }, "command_timeout"},
+		{"동시 실행 수", func(c *Config) { c.Concurrency.FanOutMax = 5 }, "fanout_max"},
+		{"production JWT 키 없음", func(c *Config) { c.Env, c.Database.Password = "production", "pw" }, "JWT_SECRET"},
+		{"production JWT 키 길이", func(c *Config) { c.Env, c.Database.Password, c.Auth.JWTSecret = "production", "pw", "short" }, "jwt_secret"},
+		{"관리자 비밀번호 없음", func(c *Config) { c.Auth.AdminUsername = "admin" }, "ADMIN_PASSWORD"},
+		{"관리자 비밀번호 길이", func(c *Config) { c.Auth.AdminUsername, c.Auth.AdminPassword = "admin", "short" }, "admin_password"},
+		{"마스터 키 형식", func(c *Config) { c.Credentials.MasterKey = "not-a-key" }, "마스터 키"},
+		{"production 마스터 키 없음", func(c *Config) { c.Env, c.Database.Password = "production", "pw" }, vault.EnvMasterKey},
+		{"마스터 키 중복 지정", func(c *Config) { c.Credentials.MasterKey, c.Credentials.MasterKeyFile = masterKey, "master.key" }, "하나만"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q 포함", err, tt.want)
+			}
+		})
+	}
+
+	// DSN을 지정하면 계정 설정은 필요 없음
+	cfg := valid()
+	cfg.Database.User = ""
+	cfg.Database.DSN = "k8s:pw@tcp(db:3306)/k8scontrol"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("DSN 설정 검증 실패: %v", err)
+	}
+
+	// SQLite는 MariaDB 계정 설정이 필요 없음
+	cfg = valid()
+	cfg.Env, cfg.Database.User = "production", ""
+	cfg.Database.Driver, cfg.Database.Path = DriverSQLite, "k8scontrol.db"
+	cfg.Auth.JWTSecret = strings.Repeat("s", minJWTSecretLength)
+	cfg.Credentials.MasterKey = masterKey
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("SQLite 설정 검증 실패: %v", err)
+	}
+
+	cfg = valid()
+	cfg.Auth = AuthConfig{JWTSecret: strings.Repeat("s", minJWTSecretLength), AdminUsername: "admin", AdminPassword: "password1"}
+	cfg.Credentials.MasterKey = masterKey
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("인증, 자격 증명 설정 검증 실패: %v", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "s3cret"
+	cfg.Database.DSN = "k8s:s3cret@tcp(db:3306)/k8scontrol?parseTime=true"
+	cfg.Auth = AuthConfig{JWTSecret: "s3cret-jwt", AdminUsername: "admin", AdminPassword: "s3cret-admin"}
+	cfg.Credentials.MasterKey = "s3cret-key"
+
+	data, err := json.Marshal(cfg.Redacted())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("비밀값이 포함되어 있습니다: %s", data)
+	}
+	if want := "k8s:" + redact.Mask + "@tcp(db:3306)/k8scontrol?parseTime=true"; cfg.Redacted().Database.DSN != want {
+		t.Errorf("DSN = %q, want %q", cfg.Redacted().Database.DSN, want)
+	}
+	if !strings.Contains(string(data), `"command_timeout":"30s"`) {
+		t.Errorf("시간 값 형식: %s", data)
+	}
+	// 원본은 바뀌지 않음
+	if cfg.Database.Password != "s3cret" {
+		t.Errorf("원본 Password = %q", cfg.Database.Password)
+	}
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIToken 자동화 호출용 API 토큰 (토큰 원문은 저장하지 않고 해시만 저장)
+type APIToken struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Username   string     `json:"username"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`    // 목록에서 토큰을 구분하기 위한 앞부분
+	Actions    []string   `json:"actions"`   // 호출할 수 있는 액션 이름
+	InfraIDs   []int      `json:"infra_ids"` // 접근할 수 있는 인프라 (비어 있으면 사용자 역할 범위 전체)
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+const apiTokenColumns = `t.id, t.user_id, u.username, t.name, t.prefix, t.actions, t.infra_ids,
+	t.expires_at, t.last_used_at, t.revoked_at, t.created_at`
+
+func scanAPIToken(row interface{ Scan(...interface{}) error }) (APIToken, error) {
+	var token APIToken
+	var actions, infraIDs string
+	err := row.Scan(&token.ID, &token.UserID, &token.Username, &token.Name, &token.Prefix, &actions, &infraIDs,
+		&token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt, &token.CreatedAt)
+	token.Actions = splitFields(actions)
+	token.InfraIDs = []int{}
+	for _, field := range splitFields(infraIDs) {
+		if id, err := strconv.Atoi(field); err == nil {
+			token.InfraIDs = append(token.InfraIDs, id)
+		}
+	}
+	return token, err
+}
+
+// CreateAPIToken API 토큰 생성 (hash는 토큰 원문의 해시)
+func CreateAPIToken(db *sql.DB, token APIToken, hash string) (int, error) {
+	infraIDs := make([]string, len(token.InfraIDs))
+	for i, id := range token.InfraIDs {
+		infraIDs[i] = strconv.Itoa(id)
+	}
+
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, prefix, actions, infra_ids, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.Exec(query,
+		token.UserID,
+		token.Name,
+		hash,
+		token.Prefix,
+		strings.Join(token.Actions, ","),
+		strings.Join(infraIDs, ","),
+		token.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// GetAPITokens API 토큰 목록 조회. userID가 0이면 모든 사용자의 토큰을 조회합니다
+func GetAPITokens(db *sql.DB, userID int) ([]APIToken, error) {
+	query := "SELECT " + apiTokenColumns + " FROM api_tokens t JOIN users u ON u.id = t.user_id"
+	var args []interface{}
+	if userID != 0 {
+		query += " WHERE t.user_id = ?"
+		args = append(args, userID)
+	}
+	query += " ORDER BY t.id"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// GetAPITokenByHash 해시로 API 토큰 조회 (폐기, 만료 여부는 호출한 쪽에서 확인)
+func GetAPITokenByHash(db *sql.DB, hash string) (APIToken, error) {
+	query := "SELECT " + apiTokenColumns + " FROM api_tokens t JOIN users u ON u.id = t.user_id WHERE t.token_hash = ?"
+	return scanAPIToken(db.QueryRow(query, hash))
+}
+
+// TouchAPIToken API 토큰의 마지막 사용 시간 갱신
+func TouchAPIToken(db *sql.DB, id int) error {
+	_, err := db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", time.Now().UTC(), id)
+	return err
+}
+
+// RevokeAPIToken API 토큰 폐기. userID가 0이 아니면 그 사용자의 토큰만 폐기합니다
+func RevokeAPIToken(db *sql.DB, id, userID int) error {
+	query := "UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL"
+	args := []interface{}{time.Now().UTC(), id}
+	if userID != 0 {
+		query += " AND user_id = ?"
+		args = append(args, userID)
+	}
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
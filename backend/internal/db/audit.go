@@ -0,0 +1,186 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// 감사 기록의 결과 상태
+const (
+	AuditSuccess  = "success"  // 요청 성공
+	AuditFailure  = "failure"  // 요청 실패 (잘못된 요청, 명령어 실행 오류 등)
+	AuditDenied   = "denied"   // 권한이 없어 거부됨
+	AuditAccepted = "accepted" // 비동기 작업으로 시작됨 (작업 결과는 job_id가 같은 기록에 따로 남음)
+	AuditDryRun   = "dry_run"  // 드라이런으로 실행 계획만 반환함
+)
+
+// DefaultAuditLimit은 감사 기록 조회 시 limit을 지정하지 않았을 때의 최대 개수입니다
+const DefaultAuditLimit = 100
+
+// AuditEntry 인프라 작업 요청 하나의 감사 기록
+// 감사 기록은 추가만 할 수 있으며, 수정하거나 삭제하는 함수는 두지 않습니다
+type AuditEntry struct {
+	ID           int64           `json:"id"`
+	UserID       int             `json:"user_id"`
+	Username     string          `json:"username"`
+	TokenID      int             `json:"token_id,omitempty"` // API 토큰으로 호출한 경우 토큰 ID
+	Route        string          `json:"route"`
+	Action       string          `json:"action"`
+	InfraID      int             `json:"infra_id,omitempty"`
+	ServerID     int             `json:"server_id,omitempty"`
+	ServiceID    int             `json:"service_id,omitempty"`
+	Params       json.RawMessage `json:"params,omitempty"` // 비밀값을 가린 요청 파라미터
+	CommandCount int             `json:"command_count"`    // 원격 서버에서 실행한 명령어 수
+	Status       string          `json:"status"`
+	HTTPStatus   int             `json:"http_status"`
+	Error        string          `json:"error,omitempty"`
+	JobID        string          `json:"job_id,omitempty"`
+	DurationMs   int64           `json:"duration_ms"`
+	ClientIP     string          `json:"client_ip"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// AuditFilter 감사 기록 조회 조건 (값이 비어 있는 조건은 적용하지 않음)
+type AuditFilter struct {
+	Username string
+	Action   string
+	InfraID  int
+	ServerID int
+	Status   string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// InsertAuditEntry 감사 기록 추가
+func InsertAuditEntry(db *sql.DB, entry AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (user_id, username, token_id, route, action, infra_id, server_id, service_id,
+			params, command_count, status, http_status, error, job_id, duration_ms, client_ip)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.Exec(query,
+		entry.UserID,
+		entry.Username,
+		nullInt64FromInt(entry.TokenID),
+		entry.Route,
+		entry.Action,
+		nullInt64FromInt(entry.InfraID),
+		nullInt64FromInt(entry.ServerID),
+		nullInt64FromInt(entry.ServiceID),
+		nullStringFromBytes(entry.Params),
+		entry.CommandCount,
+		entry.Status,
+		entry.HTTPStatus,
+		nullStringFromString(entry.Error),
+		nullStringFromString(entry.JobID),
+		entry.DurationMs,
+		entry.ClientIP,
+	)
+	return err
+}
+
+// GetAuditEntries 조건에 맞는 감사 기록을 최근 순으로 조회
+func GetAuditEntries(db *sql.DB, filter AuditFilter) ([]AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Username != "" {
+		conditions = append(conditions, "username = ?")
+		args = append(args, filter.Username)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.InfraID != 0 {
+		conditions = append(conditions, "infra_id = ?")
+		args = append(args, filter.InfraID)
+	}
+	if filter.ServerID != 0 {
+		conditions = append(conditions, "server_id = ?")
+		args = append(args, filter.ServerID)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+
+	query := `
+		SELECT id, user_id, username, token_id, route, action, infra_id, server_id, service_id,
+			params, command_count, status, http_status, error, job_id, duration_ms, client_ip, created_at
+		FROM audit_log
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	args = append(args, limit, filter.Offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		var tokenID, infraID, serverID, serviceID sql.NullInt64
+		var params, errMsg, jobID sql.NullString
+		err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Username,
+			&tokenID,
+			&entry.Route,
+			&entry.Action,
+			&infraID,
+			&serverID,
+			&serviceID,
+			&params,
+			&entry.CommandCount,
+			&entry.Status,
+			&entry.HTTPStatus,
+			&errMsg,
+			&jobID,
+			&entry.DurationMs,
+			&entry.ClientIP,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.TokenID = int(nullInt64ToInt64(tokenID))
+		entry.InfraID = int(nullInt64ToInt64(infraID))
+		entry.ServerID = int(nullInt64ToInt64(serverID))
+		entry.ServiceID = int(nullInt64ToInt64(serviceID))
+		if params.Valid {
+			entry.Params = json.RawMessage(params.String)
+		}
+		entry.Error = stringFromNullString(errMsg)
+		entry.JobID = stringFromNullString(jobID)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// nullInt64FromInt는 0을 NULL로 저장하기 위한 sql.NullInt64를 반환합니다
+func nullInt64FromInt(n int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n), Valid: n != 0}
+}
@@ -0,0 +1,486 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k8scontrol/backend/internal/vault"
+	"github.com/k8scontrol/backend/pkg/redact"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// 자격 증명 종류
+const (
+	CredentialSSH = "ssh" // SSH hop 인증 정보 (password, private_key, passphrase, sudo_password)
+	CredentialGit = "git" // Git 저장소 인증 정보 (password, token)
+)
+
+// Credential 암호화된 자격 증명
+// 비밀값은 Secrets에만 담기며 JSON으로 내보내지 않습니다. 응답에는 저장된 비밀값의 이름(Fields)만 포함됩니다
+type Credential struct {
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Kind      string            `json:"kind"`
+	Username  string            `json:"username"`
+	Fields    []string          `json:"fields"`
+	KeyID     string            `json:"key_id"` // 데이터 키를 암호화한 마스터 키 ID
+	Secrets   map[string]string `json:"-"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// sealSecrets는 비밀값을 현재 마스터 키로 봉투 암호화합니다
+func sealSecrets(secrets map[string]string) (vault.Envelope, error) {
+	keyring, err := vault.Default()
+	if err != nil {
+		return vault.Envelope{}, err
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return vault.Envelope{}, err
+	}
+	return keyring.Seal(plaintext)
+}
+
+// secretFields는 값이 있는 비밀값 이름 목록을 정렬해 반환합니다
+func secretFields(secrets map[string]string) []string {
+	fields := make([]string, 0, len(secrets))
+	for name, value := range secrets {
+		if value != "" {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// CreateCredential 자격 증명 생성 (비밀값은 암호화하여 저장)
+func CreateCredential(db *sql.DB, cred Credential) (int, error) {
+	envelope, err := sealSecrets(cred.Secrets)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO credentials (name, kind, username, fields, key_id, wrapped_key, ciphertext)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := db.Exec(query,
+		cred.Name,
+		cred.Kind,
+		cred.Username,
+		strings.Join(secretFields(cred.Secrets), ","),
+		envelope.KeyID,
+		envelope.WrappedKey,
+		envelope.Ciphertext,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// UpdateCredential 자격 증명 수정
+// Secrets가 nil이면 이름과 사용자 이름만 바꾸고, 아니면 비밀값 전체를 새 데이터 키로 다시 암호화합니다
+func UpdateCredential(db *sql.DB, id int, cred Credential) error {
+	var result sql.Result
+	var err error
+	if cred.Secrets == nil {
+		result, err = db.Exec("UPDATE credentials SET name = ?, username = ? WHERE id = ?", cred.Name, cred.Username, id)
+	} else {
+		envelope, sealErr := sealSecrets(cred.Secrets)
+		if sealErr != nil {
+			return sealErr
+		}
+		query := `
+			UPDATE credentials
+			SET name = ?, username = ?, fields = ?, key_id = ?, wrapped_key = ?, ciphertext = ?
+			WHERE id = ?
+		`
+		result, err = db.Exec(query,
+			cred.Name,
+			cred.Username,
+			strings.Join(secretFields(cred.Secrets), ","),
+			envelope.KeyID,
+			envelope.WrappedKey,
+			envelope.Ciphertext,
+			id,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		// 값이 같아 변경되지 않은 경우와 구분하기 위해 존재 여부 확인
+		if _, err := GetCredential(db, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCredential ID로 자격 증명 메타데이터 조회 (비밀값은 복호화하지 않음)
+func GetCredential(db *sql.DB, id int) (Credential, error) {
+	cred, _, err := getCredentialRow(db, id)
+	return cred, err
+}
+
+// OpenCredential ID로 자격 증명을 조회하고 비밀값을 복호화
+func OpenCredential(db *sql.DB, id int) (Credential, error) {
+	cred, envelope, err := getCredentialRow(db, id)
+	if err != nil {
+		return cred, err
+	}
+
+	keyring, err := vault.Default()
+	if err != nil {
+		return cred, err
+	}
+	plaintext, err := keyring.Open(envelope)
+	if err != nil {
+		return cred, fmt.Errorf("자격 증명 %d: %w", id, err)
+	}
+	if err := json.Unmarshal(plaintext, &cred.Secrets); err != nil {
+		return cred, fmt.Errorf("자격 증명 %d 형식 오류: %w", id, err)
+	}
+	return cred, nil
+}
+
+func getCredentialRow(db *sql.DB, id int) (Credential, vault.Envelope, error) {
+	query := `
+		SELECT id, name, kind, username, fields, key_id, wrapped_key, ciphertext, created_at, updated_at
+		FROM credentials
+		WHERE id = ?
+	`
+
+	var cred Credential
+	var fields string
+	var envelope vault.Envelope
+	err := db.QueryRow(query, id).Scan(
+		&cred.ID,
+		&cred.Name,
+		&cred.Kind,
+		&cred.Username,
+		&fields,
+		&envelope.KeyID,
+		&envelope.WrappedKey,
+		&envelope.Ciphertext,
+		&cred.CreatedAt,
+		&cred.UpdatedAt,
+	)
+	cred.Fields = splitFields(fields)
+	cred.KeyID = envelope.KeyID
+	return cred, envelope, err
+}
+
+// GetCredentials 자격 증명 메타데이터 목록 조회
+func GetCredentials(db *sql.DB) ([]Credential, error) {
+	query := `
+		SELECT id, name, kind, username, fields, key_id, created_at, updated_at
+		FROM credentials
+		ORDER BY id
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []Credential{}
+	for rows.Next() {
+		var cred Credential
+		var fields string
+		if err := rows.Scan(&cred.ID, &cred.Name, &cred.Kind, &cred.Username, &fields, &cred.KeyID, &cred.CreatedAt, &cred.UpdatedAt); err != nil {
+			return nil, err
+		}
+		cred.Fields = splitFields(fields)
+		credentials = append(credentials, cred)
+	}
+	return credentials, rows.Err()
+}
+
+// DeleteCredential 자격 증명 삭제
+func DeleteCredential(db *sql.DB, id int) error {
+	_, err := db.Exec("DELETE FROM credentials WHERE id = ?", id)
+	return err
+}
+
+// RotateCredentialKeys 현재 마스터 키가 아닌 키로 암호화된 데이터 키를 현재 키로 다시 암호화
+// 마스터 키를 교체할 때 새 키를 목록 맨 앞에 추가하고 호출한 뒤, 모든 자격 증명이 새 키를 사용하면 이전 키를 제거합니다
+func RotateCredentialKeys(db *sql.DB) (int, error) {
+	keyring, err := vault.Default()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.Query("SELECT id, key_id, wrapped_key FROM credentials WHERE key_id <> ?", keyring.PrimaryID())
+	if err != nil {
+		return 0, err
+	}
+	type pending struct {
+		id       int
+		envelope vault.Envelope
+	}
+	var stale []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.envelope.KeyID, &p.envelope.WrappedKey); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		stale = append(stale, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, p := range stale {
+		envelope, changed, err := keyring.Rewrap(p.envelope)
+		if err != nil {
+			return rotated, fmt.Errorf("자격 증명 %d: %w", p.id, err)
+		}
+		if !changed {
+			continue
+		}
+		if _, err := db.Exec("UPDATE credentials SET key_id = ?, wrapped_key = ? WHERE id = ?", envelope.KeyID, envelope.WrappedKey, p.id); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+func splitFields(fields string) []string {
+	if fields == "" {
+		return []string{}
+	}
+	return strings.Split(fields, ",")
+}
+
+// CredentialStore는 credentials 테이블을 사용하는 ssh.CredentialResolver 구현체입니다
+// 조회한 비밀값은 로그와 응답에서 가려지도록 redact에 등록합니다
+type CredentialStore struct {
+	DB *sql.DB
+
+	mu      sync.Mutex
+	tracked map[int]func()
+}
+
+// NewCredentialStore 새 CredentialStore 생성
+func NewCredentialStore(db *sql.DB) *CredentialStore {
+	return &CredentialStore{DB: db, tracked: make(map[int]func())}
+}
+
+// ResolveHopCredential 자격 증명을 복호화하여 hop 인증 정보로 반환
+func (s *CredentialStore) ResolveHopCredential(id int) (ssh.HopCredential, error) {
+	cred, err := OpenCredential(s.DB, id)
+	if err != nil {
+		return ssh.HopCredential{}, err
+	}
+
+	s.mu.Lock()
+	if release, ok := s.tracked[id]; ok {
+		release()
+	}
+	secrets := make([]string, 0, len(cred.Secrets))
+	for _, value := range cred.Secrets {
+		secrets = append(secrets, value)
+	}
+	s.tracked[id] = redact.Track(secrets...)
+	s.mu.Unlock()
+
+	return ssh.HopCredential{
+		Username:     cred.Username,
+		Password:     cred.Secrets["password"],
+		PrivateKey:   cred.Secrets["private_key"],
+		Passphrase:   cred.Secrets["passphrase"],
+		SudoPassword: cred.Secrets["sudo_password"],
+	}, nil
+}
+
+// hopSecretFields는 hops JSON에서 자격 증명 저장소로 옮기는 필드입니다
+var hopSecretFields = []string{"password", "private_key", "passphrase", "sudo_password"}
+
+// SealHops는 hops JSON의 비밀값을 자격 증명 저장소로 옮기고 credential_id로 바꾼 JSON을 반환합니다
+// previous는 기존에 저장된 hops JSON입니다. 같은 위치에 같은 경로(host, port, username)의 hop이 있으면
+// 기존 자격 증명을 갱신하거나(비밀값이 있을 때) 그대로 연결합니다(비밀값이 없을 때)
+// hopsJSON의 credential_id는 요청에서 온 값일 수 있으므로 무시하고 previous의 값만 이어받습니다
+func SealHops(db *sql.DB, hopsJSON, previous string) (string, error) {
+	if strings.TrimSpace(hopsJSON) == "" {
+		return hopsJSON, nil
+	}
+	var hops []map[string]interface{}
+	if err := json.Unmarshal([]byte(hopsJSON), &hops); err != nil {
+		return "", fmt.Errorf("hops 형식이 올바르지 않습니다: %w", err)
+	}
+	var prevHops []map[string]interface{}
+	if previous != "" {
+		json.Unmarshal([]byte(previous), &prevHops)
+	}
+
+	for i, hop := range hops {
+		secrets := make(map[string]string)
+		for _, field := range hopSecretFields {
+			if value, ok := hop[field].(string); ok && value != "" {
+				secrets[field] = value
+			}
+			delete(hop, field)
+		}
+
+		delete(hop, "credential_id")
+		credentialID := 0
+		if i < len(prevHops) && hopRoute(prevHops[i]) == hopRoute(hop) {
+			credentialID = hopCredentialID(prevHops[i])
+		}
+
+		if len(secrets) > 0 {
+			username, _ := hop["username"].(string)
+			cred := Credential{Name: hopRoute(hop), Kind: CredentialSSH, Username: username, Secrets: secrets}
+			if credentialID != 0 {
+				if err := UpdateCredential(db, credentialID, cred); err != nil {
+					return "", err
+				}
+			} else {
+				id, err := CreateCredential(db, cred)
+				if err != nil {
+					return "", err
+				}
+				credentialID = id
+			}
+		}
+		if credentialID != 0 {
+			hop["credential_id"] = credentialID
+		}
+	}
+
+	sealed, err := json.Marshal(hops)
+	if err != nil {
+		return "", err
+	}
+	return string(sealed), nil
+}
+
+// HopsHaveSecrets는 hops JSON에 평문 비밀값이 남아 있는지 확인합니다
+func HopsHaveSecrets(hopsJSON string) bool {
+	var hops []map[string]interface{}
+	if err := json.Unmarshal([]byte(hopsJSON), &hops); err != nil {
+		return false
+	}
+	for _, hop := range hops {
+		for _, field := range hopSecretFields {
+			if value, ok := hop[field].(string); ok && value != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hopCredentialID는 hop의 credential_id를 반환합니다 (없으면 0)
+func hopCredentialID(hop map[string]interface{}) int {
+	switch v := hop["credential_id"].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// hopRoute는 hop의 접속 경로(username@host:port)입니다. 비밀값이 달라도 같은 hop인지 비교할 때 사용합니다
+func hopRoute(hop map[string]interface{}) string {
+	host, _ := hop["host"].(string)
+	username, _ := hop["username"].(string)
+	port := 22
+	switch v := hop["port"].(type) {
+	case float64:
+		port = int(v)
+	case string:
+		fmt.Sscanf(v, "%d", &port)
+	}
+	return fmt.Sprintf("%s@%s:%d", username, host, port)
+}
+
+// hopsRoute는 hops JSON 전체의 접속 경로입니다
+func hopsRoute(hopsJSON string) string {
+	var hops []map[string]interface{}
+	if err := json.Unmarshal([]byte(hopsJSON), &hops); err != nil {
+		return hopsJSON
+	}
+	routes := make([]string, len(hops))
+	for i, hop := range hops {
+		routes[i] = hopRoute(hop)
+	}
+	return strings.Join(routes, ",")
+}
+
+// MigratePlaintextCredentials는 서버 hops와 서비스의 평문 비밀값을 자격 증명 저장소로 옮깁니다
+// 서버 시작 시 마스터 키가 설정되어 있으면 호출합니다. 옮긴 행의 수를 반환합니다
+func MigratePlaintextCredentials(db *sql.DB) (int, error) {
+	migrated := 0
+
+	servers, err := GetAllServers(db)
+	if err != nil {
+		return 0, err
+	}
+	for _, server := range servers {
+		if !HopsHaveSecrets(server.Hops) {
+			continue
+		}
+		sealed, err := SealHops(db, server.Hops, server.Hops)
+		if err != nil {
+			return migrated, fmt.Errorf("서버 %d: %w", server.ID, err)
+		}
+		if _, err := db.Exec("UPDATE servers SET hops = ? WHERE id = ?", sealed, server.ID); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	rows, err := db.Query(`
+		SELECT id FROM services
+		WHERE (gitlab_password IS NOT NULL AND gitlab_password <> '') OR (gitlab_token IS NOT NULL AND gitlab_token <> '')
+	`)
+	if err != nil {
+		return migrated, err
+	}
+	var serviceIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return migrated, err
+		}
+		serviceIDs = append(serviceIDs, id)
+	}
+	rows.Close()
+
+	for _, id := range serviceIDs {
+		service, err := GetServiceByID(db, id)
+		if err != nil {
+			return migrated, err
+		}
+		// UpdateService가 비밀값을 자격 증명으로 옮기고 평문 컬럼을 비움
+		if err := UpdateService(db, service); err != nil {
+			return migrated, fmt.Errorf("서비스 %d: %w", id, err)
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("[자격 증명] 평문 비밀값 %d건을 암호화된 자격 증명으로 옮겼습니다", migrated)
+	}
+	return migrated, nil
+}
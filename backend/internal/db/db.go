@@ -3,61 +3,50 @@ package db
 import (
 	"database/sql"
 	"fmt"
-	"os"
-	"strings"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/k8scontrol/backend/internal/config"
 )
 
-// 환경 확인 함수
-func isProduction() bool {
-	env := strings.ToLower(os.Getenv("APP_ENV"))
-	return env == "production" || env == "prod"
-}
-
 // Connect establishes a connection to the MariaDB database
-func Connect() (*sql.DB, error) {
-	var dbUser, dbPassword, dbHost, dbPort, dbName string
-
-	// 프로덕션 환경인지 확인
-	if isProduction() {
-		// 프로덕션 환경에서의 하드코딩된 값
-		dbUser = "lw"
-		dbPassword = "line9876"
-		dbHost = "k8scontrol-db"
-		dbPort = "3306"
-		dbName = "k8scontrol"
-	} else {
-		// 개발 환경에서의 하드코딩된 값
-		dbUser = "lw"
-		dbPassword = "line9876"
-		dbHost = "lineworldap.iptime.org"
-		dbPort = "4445"
-		dbName = "k8scontrol"
+// cfg.Driver가 sqlite이면 cfg.Path의 SQLite 파일을 엽니다. 스키마는 두 경우 모두 Migrate로 적용합니다
+func Connect(cfg config.DatabaseConfig) (*sql.DB, error) {
+	if cfg.Driver == config.DriverSQLite {
+		return openSQLite(cfg.Path)
 	}
 
-	// 데이터베이스 연결 문자열 생성
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", dbUser, dbPassword, dbHost, dbPort, dbName)
-
 	// 데이터베이스 연결
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", dsn(cfg))
 	if err != nil {
 		return nil, err
 	}
 
+	// 연결 풀 설정
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration)
+
 	// 연결 테스트
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
 
-// 환경 변수 가져오기 (기본값 지원)
-func getEnvWithDefault(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// dsn은 설정의 DSN을 반환합니다. DSN이 없으면 호스트, 계정 설정으로 만듭니다
+// 비밀번호의 특수문자가 DSN 구분자로 해석되지 않도록 mysql.Config로 만듭니다
+func dsn(cfg config.DatabaseConfig) string {
+	if cfg.DSN != "" {
+		return cfg.DSN
 	}
-	return value
+	c := mysql.NewConfig()
+	c.User = cfg.User
+	c.Passwd = cfg.Password
+	c.Net = "tcp"
+	c.Addr = fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	c.DBName = cfg.Name
+	c.ParseTime = true
+	return c.FormatDSN()
 }
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// Dialect는 연결한 DB의 SQL 문법 종류입니다
+type Dialect int
+
+const (
+	// MySQL은 MariaDB, MySQL 문법입니다 (운영 환경 기본값)
+	MySQL Dialect = iota
+	// SQLite는 외부 DB 없이 실행할 때 사용하는 SQLite 문법입니다
+	SQLite
+)
+
+// dialectOf는 연결의 드라이버로 SQL 문법 종류를 판단합니다
+func dialectOf(db *sql.DB) Dialect {
+	if _, ok := db.Driver().(*sqlite.Driver); ok {
+		return SQLite
+	}
+	return MySQL
+}
+
+// upsert는 INSERT 문 뒤에 붙여 키가 겹치는 행이 있으면 갱신하는 절을 반환합니다
+// conflict는 겹침을 판단하는 기본 키 또는 유니크 키의 컬럼(SQLite에서만 사용), columns는 새 값으로 바꿀 컬럼이며
+// extra는 그대로 덧붙일 할당식(pending_key = NULL 등)입니다
+func (d Dialect) upsert(conflict, columns []string, extra ...string) string {
+	assignments := make([]string, 0, len(columns)+len(extra))
+	for _, column := range columns {
+		if d == SQLite {
+			assignments = append(assignments, column+" = excluded."+column)
+		} else {
+			assignments = append(assignments, column+" = VALUES("+column+")")
+		}
+	}
+	assignments = append(assignments, extra...)
+
+	if d == SQLite {
+		return "ON CONFLICT (" + strings.Join(conflict, ", ") + ") DO UPDATE SET " + strings.Join(assignments, ", ")
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// PinnedHostKey 고정된 SSH 호스트 키와 승인 대기 중인 키 정보
+type PinnedHostKey struct {
+	ID                 int       `json:"id"`
+	Via                string    `json:"via"` // 점프 호스트 경로 (직접 연결이면 빈 값)
+	Host               string    `json:"host"`
+	Port               int       `json:"port"`
+	KeyType            string    `json:"key_type"`
+	Fingerprint        string    `json:"fingerprint"`
+	PublicKey          string    `json:"public_key"`
+	PendingKeyType     string    `json:"pending_key_type,omitempty"`
+	PendingFingerprint string    `json:"pending_fingerprint,omitempty"`
+	PendingPublicKey   string    `json:"pending_public_key,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// HostKeyStore는 ssh_host_keys 테이블을 사용하는 ssh.HostKeyStore 구현체입니다
+type HostKeyStore struct {
+	DB *sql.DB
+}
+
+// NewHostKeyStore 새 HostKeyStore 생성
+func NewHostKeyStore(db *sql.DB) *HostKeyStore {
+	return &HostKeyStore{DB: db}
+}
+
+// LookupHostKey via 경로로 접속한 호스트에 고정된 키 조회 (없으면 nil)
+func (s *HostKeyStore) LookupHostKey(via, host string, port int) (*ssh.HostKey, error) {
+	query := "SELECT key_type, fingerprint, public_key FROM ssh_host_keys WHERE via = ? AND host = ? AND port = ?"
+
+	key := ssh.HostKey{Via: via, Host: host, Port: port}
+	err := s.DB.QueryRow(query, via, host, port).Scan(&key.KeyType, &key.Fingerprint, &key.PublicKey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// PinHostKey 처음 접속한 호스트의 키를 저장
+// 동시에 처음 접속한 다른 연결이 먼저 저장하여 고유 키가 충돌하면 기존 키를 유지하고 성공으로 처리
+// (호출한 쪽은 다시 조회하여 제시된 키와 비교합니다)
+func (s *HostKeyStore) PinHostKey(key ssh.HostKey) error {
+	query := `
+		INSERT INTO ssh_host_keys (via, host, port, key_type, fingerprint, public_key)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := s.DB.Exec(query, key.Via, key.Host, key.Port, key.KeyType, key.Fingerprint, key.PublicKey)
+	if err == nil {
+		return nil
+	}
+	if pinned, lookupErr := s.LookupHostKey(key.Via, key.Host, key.Port); lookupErr == nil && pinned != nil {
+		return nil
+	}
+	return err
+}
+
+// RecordHostKeyMismatch 변경된 호스트 키를 승인 대기 상태로 기록
+func (s *HostKeyStore) RecordHostKeyMismatch(key ssh.HostKey) error {
+	query := `
+		UPDATE ssh_host_keys
+		SET pending_key_type = ?, pending_fingerprint = ?, pending_public_key = ?
+		WHERE via = ? AND host = ? AND port = ?
+	`
+
+	_, err := s.DB.Exec(query, key.KeyType, key.Fingerprint, key.PublicKey, key.Via, key.Host, key.Port)
+	return err
+}
+
+// GetHostKeys 고정된 호스트 키 목록 조회
+func GetHostKeys(db *sql.DB) ([]PinnedHostKey, error) {
+	query := `
+		SELECT id, via, host, port, key_type, fingerprint, public_key,
+			pending_key_type, pending_fingerprint, pending_public_key, created_at, updated_at
+		FROM ssh_host_keys
+		ORDER BY host, port, via
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []PinnedHostKey
+	for rows.Next() {
+		key, err := scanPinnedHostKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetHostKey 홉 경로, host, port로 고정된 호스트 키 조회
+func GetHostKey(db *sql.DB, via, host string, port int) (PinnedHostKey, error) {
+	query := `
+		SELECT id, via, host, port, key_type, fingerprint, public_key,
+			pending_key_type, pending_fingerprint, pending_public_key, created_at, updated_at
+		FROM ssh_host_keys
+		WHERE via = ? AND host = ? AND port = ?
+	`
+
+	return scanPinnedHostKey(db.QueryRow(query, via, host, port))
+}
+
+// rowScanner는 *sql.Row와 *sql.Rows의 공통 Scan 메서드입니다
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPinnedHostKey는 조회 결과 한 행을 PinnedHostKey로 변환합니다
+func scanPinnedHostKey(row rowScanner) (PinnedHostKey, error) {
+	var key PinnedHostKey
+	var pendingKeyType, pendingFingerprint, pendingPublicKey sql.NullString
+
+	err := row.Scan(
+		&key.ID,
+		&key.Via,
+		&key.Host,
+		&key.Port,
+		&key.KeyType,
+		&key.Fingerprint,
+		&key.PublicKey,
+		&pendingKeyType,
+		&pendingFingerprint,
+		&pendingPublicKey,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	if err != nil {
+		return key, err
+	}
+
+	key.PendingKeyType = stringFromNullString(pendingKeyType)
+	key.PendingFingerprint = stringFromNullString(pendingFingerprint)
+	key.PendingPublicKey = stringFromNullString(pendingPublicKey)
+
+	return key, nil
+}
+
+// ApproveHostKey 승인 대기 중인 키를 고정 키로 승격
+func ApproveHostKey(db *sql.DB, via, host string, port int) error {
+	query := `
+		UPDATE ssh_host_keys
+		SET key_type = pending_key_type, fingerprint = pending_fingerprint, public_key = pending_public_key,
+			pending_key_type = NULL, pending_fingerprint = NULL, pending_public_key = NULL
+		WHERE via = ? AND host = ? AND port = ? AND pending_fingerprint IS NOT NULL
+	`
+
+	result, err := db.Exec(query, via, host, port)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("승인 대기 중인 호스트 키가 없습니다")
+	}
+
+	return nil
+}
+
+// RotateHostKey 고정된 키를 지정한 키로 교체
+// key가 nil이면 고정 정보를 삭제하여 다음 접속 시 새로 고정(TOFU)되도록 합니다
+func RotateHostKey(db *sql.DB, via, host string, port int, key *ssh.HostKey) error {
+	if key == nil {
+		_, err := db.Exec("DELETE FROM ssh_host_keys WHERE via = ? AND host = ? AND port = ?", via, host, port)
+		return err
+	}
+
+	query := `
+		INSERT INTO ssh_host_keys (via, host, port, key_type, fingerprint, public_key)
+		VALUES (?, ?, ?, ?, ?, ?)
+	` + dialectOf(db).upsert([]string{"via", "host", "port"}, []string{"key_type", "fingerprint", "public_key"},
+		"pending_key_type = NULL", "pending_fingerprint = NULL", "pending_public_key = NULL")
+
+	_, err := db.Exec(query, via, host, port, key.KeyType, key.Fingerprint, key.PublicKey)
+	return err
+}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/k8scontrol/backend/internal/command"
+)
+
+// JobStore는 jobs 테이블을 사용하는 command.JobStore 구현체입니다
+type JobStore struct {
+	DB *sql.DB
+}
+
+// NewJobStore 새 JobStore 생성
+func NewJobStore(db *sql.DB) *JobStore {
+	return &JobStore{DB: db}
+}
+
+// SaveJob 작업 저장 (같은 ID가 있으면 갱신)
+func (s *JobStore) SaveJob(job command.Job) error {
+	steps, err := json.Marshal(job.Steps)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO jobs (id, action, target, infra_id, state, steps, result, error, error_type, owner, heartbeat_at, created_at, started_at, finished_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	` + dialectOf(s.DB).upsert([]string{"id"}, []string{
+		"state", "steps", "result", "error", "error_type", "heartbeat_at", "started_at", "finished_at", "updated_at",
+	})
+
+	_, err = s.DB.Exec(query,
+		job.ID,
+		job.Action,
+		job.Target,
+		job.InfraID,
+		job.State,
+		string(steps),
+		nullStringFromBytes(job.Result),
+		nullStringFromString(job.Error),
+		nullStringFromString(job.ErrorType),
+		job.Owner,
+		job.HeartbeatAt.UTC(),
+		job.CreatedAt.UTC(),
+		nullTimeUTC(job.StartedAt),
+		nullTimeUTC(job.FinishedAt),
+		job.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// GetJob ID로 작업 조회
+func (s *JobStore) GetJob(id string) (command.Job, error) {
+	query := `
+		SELECT id, action, target, infra_id, state, steps, result, error, error_type, owner, heartbeat_at, created_at, started_at, finished_at, updated_at
+		FROM jobs
+		WHERE id = ?
+	`
+
+	job, err := scanJob(s.DB.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return job, command.ErrJobNotFound
+	}
+	return job, err
+}
+
+// ListJobs 조건에 맞는 작업 목록을 최근 생성 순으로 조회
+// 목록에서는 단계별 출력이 클 수 있으므로 steps를 포함하지 않습니다
+func (s *JobStore) ListJobs(filter command.JobFilter) ([]command.Job, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.State != "" {
+		conditions = append(conditions, "state = ?")
+		args = append(args, filter.State)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.InfraIDs != nil {
+		if len(filter.InfraIDs) == 0 {
+			return nil, nil
+		}
+		placeholders := make([]string, len(filter.InfraIDs))
+		for i, id := range filter.InfraIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		conditions = append(conditions, "infra_id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	query := `
+		SELECT id, action, target, infra_id, state, '[]', result, error, error_type, owner, heartbeat_at, created_at, started_at, finished_at, updated_at
+		FROM jobs
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = command.DefaultJobListLimit
+	}
+	args = append(args, limit)
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []command.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// TouchJobs owner 인스턴스의 대기 또는 실행 중인 작업의 heartbeat 갱신
+func (s *JobStore) TouchJobs(owner string, at time.Time) error {
+	query := `
+		UPDATE jobs
+		SET heartbeat_at = ?
+		WHERE owner = ? AND state IN (?, ?)
+	`
+
+	_, err := s.DB.Exec(query, at.UTC(), owner, command.JobQueued, command.JobRunning)
+	return err
+}
+
+// MarkInterruptedJobs 대기 또는 실행 중으로 남아 있고 heartbeat가 staleBefore보다 오래된 작업을 중단 상태로 변경
+// heartbeat가 없는 작업은 owner 기록 이전에 만들어진 작업이므로 함께 중단으로 표시
+func (s *JobStore) MarkInterruptedJobs(staleBefore time.Time) (int64, error) {
+	query := `
+		UPDATE jobs
+		SET state = ?, error = ?, finished_at = ?, updated_at = ?
+		WHERE state IN (?, ?) AND (heartbeat_at IS NULL OR heartbeat_at < ?)
+	`
+
+	now := time.Now().UTC()
+	result, err := s.DB.Exec(query,
+		command.JobInterrupted, command.JobInterruptedMessage, now, now,
+		command.JobQueued, command.JobRunning, staleBefore.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanJob은 조회 결과 한 행을 command.Job으로 변환합니다
+func scanJob(row rowScanner) (command.Job, error) {
+	var job command.Job
+	var steps string
+	var result, errMsg, errType sql.NullString
+	var heartbeatAt, startedAt, finishedAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID,
+		&job.Action,
+		&job.Target,
+		&job.InfraID,
+		&job.State,
+		&steps,
+		&result,
+		&errMsg,
+		&errType,
+		&job.Owner,
+		&heartbeatAt,
+		&job.CreatedAt,
+		&startedAt,
+		&finishedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return job, err
+	}
+
+	if err := json.Unmarshal([]byte(steps), &job.Steps); err != nil {
+		return job, err
+	}
+	if result.Valid {
+		job.Result = json.RawMessage(result.String)
+	}
+	job.Error = stringFromNullString(errMsg)
+	job.ErrorType = stringFromNullString(errType)
+	if heartbeatAt.Valid {
+		job.HeartbeatAt = heartbeatAt.Time
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return job, nil
+}
+
+// nullTimeUTC는 nil이면 NULL로, 아니면 UTC 시간으로 저장하기 위한 sql.NullTime을 반환합니다
+// SQLite는 시간을 문자열로 비교하므로 저장하는 시간의 시간대를 UTC로 맞춥니다
+func nullTimeUTC(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// nullStringFromString은 빈 문자열을 NULL로 저장하기 위한 sql.NullString을 반환합니다
+func nullStringFromString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullStringFromBytes는 비어 있는 바이트 슬라이스를 NULL로 저장하기 위한 sql.NullString을 반환합니다
+func nullStringFromBytes(b []byte) sql.NullString {
+	return sql.NullString{String: string(b), Valid: len(b) > 0}
+}
@@ -0,0 +1,328 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles는 바이너리에 포함한 스키마 마이그레이션입니다
+// 파일 이름은 <버전>_<이름>.up.sql, <버전>_<이름>.down.sql 형식이며 버전 순서대로 적용합니다
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	// migrationLockName은 여러 서버(레플리카)가 동시에 마이그레이션하지 않도록 잡는 DB 잠금 이름입니다
+	migrationLockName = "k8scontrol.schema_migration"
+	// migrationLockTimeout은 다른 서버의 마이그레이션이 끝나기를 기다리는 최대 시간입니다
+	migrationLockTimeout = 5 * time.Minute
+)
+
+// migrationFileName은 마이그레이션 파일 이름 형식입니다
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration은 버전 하나의 스키마 변경입니다
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+// MigrationStatus는 적용한 스키마 버전과 적용하지 않은 마이그레이션 목록입니다
+type MigrationStatus struct {
+	Version int         `json:"version"`
+	Latest  int         `json:"latest"`
+	Pending []Migration `json:"pending"`
+}
+
+// Migrations는 바이너리에 포함한 마이그레이션을 버전 순으로 반환합니다
+func Migrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles, "migrations")
+}
+
+// loadMigrations는 dir의 마이그레이션 파일을 읽어 버전 순으로 반환합니다
+// 버전마다 up 파일이 있어야 하며, 같은 버전에 다른 이름을 쓰면 오류입니다
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		m := migrationFileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("마이그레이션 파일 이름 형식이 아닙니다 (<버전>_<이름>.up.sql): %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("마이그레이션 버전은 1 이상이어야 합니다: %s", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("마이그레이션 버전 %d의 이름이 다릅니다: %s, %s", version, migration.Name, m[2])
+		}
+		if m[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("마이그레이션 버전 %d(%s)의 up 파일이 없습니다", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate는 적용하지 않은 마이그레이션을 모두 적용하고 적용한 목록을 반환합니다
+// 다른 서버가 마이그레이션 중이면 끝날 때까지 기다린 뒤 남은 마이그레이션만 적용합니다
+func Migrate(db *sql.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	dialect := dialectOf(db)
+	var applied []Migration
+	err = withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		current, err := schemaVersion(ctx, conn, dialect)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			if migration.Version <= current {
+				continue
+			}
+			err := execMigration(ctx, conn, dialect, migration.Up,
+				"INSERT INTO schema_version (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("마이그레이션 %04d_%s 적용 실패: %w", migration.Version, migration.Name, err)
+			}
+			log.Printf("[마이그레이션] %04d_%s 적용", migration.Version, migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown은 적용한 마이그레이션을 최근 것부터 되돌려 스키마 버전을 target으로 만들고 되돌린 목록을 반환합니다
+// 되돌리는 마이그레이션의 테이블, 컬럼 데이터는 삭제됩니다
+func MigrateDown(db *sql.DB, target int) ([]Migration, error) {
+	if target < 0 {
+		return nil, fmt.Errorf("대상 버전은 0 이상이어야 합니다: %d", target)
+	}
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	dialect := dialectOf(db)
+	var reverted []Migration
+	err = withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		current, err := schemaVersion(ctx, conn, dialect)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0; i-- {
+			migration := migrations[i]
+			if migration.Version > current || migration.Version <= target {
+				continue
+			}
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("마이그레이션 %04d_%s는 되돌릴 수 없습니다 (down 파일 없음)", migration.Version, migration.Name)
+			}
+			err := execMigration(ctx, conn, dialect, migration.Down,
+				"DELETE FROM schema_version WHERE version = ?", migration.Version)
+			if err != nil {
+				return fmt.Errorf("마이그레이션 %04d_%s 되돌리기 실패: %w", migration.Version, migration.Name, err)
+			}
+			log.Printf("[마이그레이션] %04d_%s 되돌림", migration.Version, migration.Name)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// GetMigrationStatus는 현재 스키마 버전과 적용하지 않은 마이그레이션을 조회합니다
+func GetMigrationStatus(db *sql.DB) (*MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	current, err := schemaVersion(ctx, conn, dialectOf(db))
+	if err != nil {
+		return nil, err
+	}
+	status := &MigrationStatus{Version: current}
+	for _, migration := range migrations {
+		status.Latest = migration.Version
+		if migration.Version > current {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// withMigrationLock은 DB 잠금(GET_LOCK)을 잡은 연결로 fn을 실행합니다
+// GET_LOCK은 연결 단위 잠금이므로 잠금과 마이그레이션을 같은 연결에서 실행합니다
+// SQLite는 GET_LOCK이 없으며, 마이그레이션마다 쓰기 트랜잭션으로 실행하므로 잠금 없이 실행합니다
+func withMigrationLock(db *sql.DB, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if dialectOf(db) == SQLite {
+		if err := createSchemaVersionTable(ctx, conn); err != nil {
+			return err
+		}
+		return fn(ctx, conn)
+	}
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(migrationLockTimeout.Seconds())).Scan(&acquired); err != nil {
+		return fmt.Errorf("마이그레이션 잠금 실패: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("마이그레이션 잠금을 %s 안에 얻지 못했습니다 (다른 서버가 마이그레이션 중)", migrationLockTimeout)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName); err != nil {
+			log.Printf("[마이그레이션] 잠금 해제 실패: %v", err)
+		}
+	}()
+
+	if err := createSchemaVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(ctx, conn)
+}
+
+// createSchemaVersionTable은 적용한 마이그레이션 버전을 기록하는 테이블을 만듭니다
+func createSchemaVersionTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_version (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("schema_version 테이블 생성 실패: %w", err)
+	}
+	return nil
+}
+
+// schemaVersion은 적용한 가장 높은 마이그레이션 버전을 반환합니다. schema_version 테이블이 없으면 0입니다
+func schemaVersion(ctx context.Context, conn *sql.Conn, dialect Dialect) (int, error) {
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_version'"
+	if dialect == SQLite {
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'"
+	}
+	var exists int
+	err := conn.QueryRowContext(ctx, query).Scan(&exists)
+	if err != nil || exists == 0 {
+		return 0, err
+	}
+	var version sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("스키마 버전 조회 실패: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// execMigration은 마이그레이션 SQL의 문장을 차례로 실행한 뒤 스키마 버전을 기록하는 record 문장을 실행합니다
+// MariaDB의 DDL은 트랜잭션으로 묶이지 않으므로, 중간에 실패해도 다시 실행할 수 있게 IF NOT EXISTS 등으로 작성해야 합니다
+// SQLite는 문장을 sqliteStatements로 바꾸고, 버전 기록까지 한 트랜잭션으로 실행합니다
+func execMigration(ctx context.Context, conn *sql.Conn, dialect Dialect, script, record string, args ...interface{}) error {
+	statements := splitStatements(script)
+	if dialect != SQLite {
+		for _, stmt := range statements {
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		if _, err := conn.ExecContext(ctx, record, args...); err != nil {
+			return fmt.Errorf("스키마 버전 기록 실패: %w", err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range statements {
+		for _, translated := range sqliteStatements(stmt) {
+			if _, err := tx.ExecContext(ctx, translated); err != nil {
+				return fmt.Errorf("%w (%s)", err, translated)
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("스키마 버전 기록 실패: %w", err)
+	}
+	return tx.Commit()
+}
+
+// splitStatements는 SQL 스크립트를 줄 끝의 세미콜론 기준으로 문장별로 나눕니다. "--" 주석 줄은 제외합니다
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		if strings.HasSuffix(trimmed, ";") {
+			current.WriteString(strings.TrimSuffix(strings.TrimRight(line, " \t\r"), ";"))
+			flush()
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("마이그레이션이 없습니다")
+	}
+	for i, m := range migrations {
+		// 버전은 1부터 빠짐없이 이어져야 함
+		if m.Version != i+1 {
+			t.Errorf("%d번째 마이그레이션 버전 = %d, want %d", i, m.Version, i+1)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("%04d_%s: down 파일이 없습니다", m.Version, m.Name)
+		}
+		if len(splitStatements(m.Up)) == 0 {
+			t.Errorf("%04d_%s: up 문장이 없습니다", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"m/0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"m/README.md":            {Data: []byte("무시")},
+		"m/0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+	}
+	migrations, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "first" || migrations[1].Version != 2 || migrations[0].Down != "DROP TABLE a;" {
+		t.Errorf("migrations = %+v", migrations)
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"이름 형식":  {"m/first.up.sql": {Data: []byte("SELECT 1;")}},
+		"up 없음":  {"m/0001_first.down.sql": {Data: []byte("SELECT 1;")}},
+		"이름 불일치": {"m/0001_a.up.sql": {Data: []byte("SELECT 1;")}, "m/0001_b.down.sql": {Data: []byte("SELECT 1;")}},
+	}
+	for name, fsys := range invalid {
+		if _, err := loadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: 오류가 없습니다", name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- 주석
+CREATE TABLE a (
+	id INT,
+	note VARCHAR(16) DEFAULT 'a;b'
+);
+
+ALTER TABLE a ADD COLUMN b INT;
+ALTER TABLE a ADD COLUMN c INT`
+	want := []string{
+		"CREATE TABLE a (\n\tid INT,\n\tnote VARCHAR(16) DEFAULT 'a;b'\n)",
+		"ALTER TABLE a ADD COLUMN b INT",
+		"ALTER TABLE a ADD COLUMN c INT",
+	}
+	if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements = %q, want %q", got, want)
+	}
+}
+
+func TestSQLiteMigrateUpDown(t *testing.T) {
+	database, err := openSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrations[len(migrations)-1].Version
+
+	// 마이그레이션을 모두 적용하고, 모두 되돌린 뒤 다시 적용
+	if applied, err := Migrate(database); err != nil || len(applied) != len(migrations) {
+		t.Fatalf("Migrate = %d개, %v", len(applied), err)
+	}
+	if applied, err := Migrate(database); err != nil || len(applied) != 0 {
+		t.Errorf("두 번째 Migrate = %d개, %v, want 0개", len(applied), err)
+	}
+	if reverted, err := MigrateDown(database, 0); err != nil || len(reverted) != len(migrations) {
+		t.Fatalf("MigrateDown = %d개, %v", len(reverted), err)
+	}
+	if status, err := GetMigrationStatus(database); err != nil || status.Version != 0 || len(status.Pending) != len(migrations) {
+		t.Errorf("되돌린 뒤 상태 = %+v, %v", status, err)
+	}
+	if _, err := Migrate(database); err != nil {
+		t.Fatalf("다시 Migrate: %v", err)
+	}
+	if status, err := GetMigrationStatus(database); err != nil || status.Version != latest || len(status.Pending) != 0 {
+		t.Errorf("다시 적용한 뒤 상태 = %+v, %v", status, err)
+	}
+}
+
+func TestSQLiteStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want []string
+	}{
+		{
+			"CREATE TABLE",
+			`CREATE TABLE IF NOT EXISTS jobs (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	steps MEDIUMTEXT NOT NULL,
+	created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	UNIQUE KEY uk_jobs_name (name, owner),
+	KEY idx_jobs_state (state)
+)`,
+			[]string{
+				"CREATE TABLE IF NOT EXISTS jobs (\n\tid INTEGER PRIMARY KEY AUTOINCREMENT,\n\tsteps TEXT NOT NULL,\n\tcreated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,\n\tupdated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP\n)",
+				"CREATE UNIQUE INDEX IF NOT EXISTS uk_jobs_name ON jobs (name, owner)",
+				"CREATE INDEX IF NOT EXISTS idx_jobs_state ON jobs (state)",
+				"CREATE TRIGGER IF NOT EXISTS trg_jobs_updated_at AFTER UPDATE ON jobs\nBEGIN\n\tUPDATE jobs SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;\nEND",
+			},
+		},
+		{
+			"여러 변경을 묶은 ALTER TABLE",
+			"ALTER TABLE jobs ADD COLUMN IF NOT EXISTS owner VARCHAR(64) NULL, ADD KEY idx_jobs_owner (owner, state), DROP COLUMN IF EXISTS target",
+			[]string{
+				"ALTER TABLE jobs ADD COLUMN owner VARCHAR(64) NULL",
+				"CREATE INDEX IF NOT EXISTS idx_jobs_owner ON jobs (owner, state)",
+				"ALTER TABLE jobs DROP COLUMN target",
+			},
+		},
+		{"DROP INDEX ... ON", "DROP INDEX IF EXISTS idx_jobs_owner ON jobs", []string{"DROP INDEX IF EXISTS idx_jobs_owner"}},
+		{"변환하지 않는 문장", "DROP TABLE IF EXISTS jobs", []string{"DROP TABLE IF EXISTS jobs"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqliteStatements(tt.stmt); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sqliteStatements =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
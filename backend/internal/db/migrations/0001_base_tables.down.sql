@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS services;
+DROP TABLE IF EXISTS servers;
+DROP TABLE IF EXISTS infras;
@@ -0,0 +1,42 @@
+-- 인프라, 서버, 서비스 기본 테이블 (이전 DB 초기화 스크립트의 테이블)
+-- 초기화 스크립트로 이미 만든 DB에도 적용할 수 있도록 IF NOT EXISTS로 생성합니다
+CREATE TABLE IF NOT EXISTS infras (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	type VARCHAR(64) NOT NULL,
+	info TEXT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS servers (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	server_name VARCHAR(255) NULL,
+	hops MEDIUMTEXT NOT NULL,
+	join_command TEXT NULL,
+	certificate_key VARCHAR(255) NULL,
+	type VARCHAR(64) NOT NULL,
+	infra_id INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	KEY idx_servers_infra_id (infra_id),
+	CONSTRAINT fk_servers_infra FOREIGN KEY (infra_id) REFERENCES infras (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS services (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	domain VARCHAR(255) NULL,
+	namespace VARCHAR(255) NULL,
+	gitlab_url VARCHAR(512) NULL,
+	gitlab_id VARCHAR(255) NULL,
+	gitlab_password VARCHAR(255) NULL,
+	gitlab_token VARCHAR(255) NULL,
+	gitlab_branch VARCHAR(255) NULL,
+	user_id INT NOT NULL DEFAULT 0,
+	infra_id INT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	KEY idx_services_infra_id (infra_id),
+	CONSTRAINT fk_services_infra FOREIGN KEY (infra_id) REFERENCES infras (id) ON DELETE SET NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE servers DROP COLUMN IF EXISTS last_checked;
+ALTER TABLE servers DROP COLUMN IF EXISTS ha;
@@ -0,0 +1,3 @@
+-- HA 프록시 설정 여부와 마지막 상태 확인 시간 (초기화 스크립트로 만든 이전 DB에는 없음)
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS ha CHAR(1) NOT NULL DEFAULT 'N';
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS last_checked TIMESTAMP NULL;
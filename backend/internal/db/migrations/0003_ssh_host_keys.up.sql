@@ -0,0 +1,15 @@
+-- 서버별로 고정한 SSH 호스트 키 (pending_* 는 승인 대기 중인 새 키)
+CREATE TABLE IF NOT EXISTS ssh_host_keys (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	host VARCHAR(255) NOT NULL,
+	port INT NOT NULL DEFAULT 22,
+	key_type VARCHAR(64) NOT NULL,
+	fingerprint VARCHAR(128) NOT NULL,
+	public_key TEXT NOT NULL,
+	pending_key_type VARCHAR(64) NULL,
+	pending_fingerprint VARCHAR(128) NULL,
+	pending_public_key TEXT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_ssh_host_keys_host_port (host, port)
+);
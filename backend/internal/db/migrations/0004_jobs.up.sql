@@ -0,0 +1,17 @@
+-- 비동기 작업 상태
+CREATE TABLE IF NOT EXISTS jobs (
+	id VARCHAR(64) PRIMARY KEY,
+	action VARCHAR(128) NOT NULL,
+	target VARCHAR(255) NOT NULL DEFAULT '',
+	state VARCHAR(32) NOT NULL,
+	steps MEDIUMTEXT NOT NULL,
+	result MEDIUMTEXT NULL,
+	error TEXT NULL,
+	error_type VARCHAR(64) NULL,
+	created_at TIMESTAMP(3) NOT NULL,
+	started_at TIMESTAMP(3) NULL,
+	finished_at TIMESTAMP(3) NULL,
+	updated_at TIMESTAMP(3) NOT NULL,
+	KEY idx_jobs_state (state),
+	KEY idx_jobs_created_at (created_at)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE services DROP COLUMN IF EXISTS gitlab_credential_id;
+DROP TABLE IF EXISTS credentials;
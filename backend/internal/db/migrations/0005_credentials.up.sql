@@ -0,0 +1,17 @@
+-- 암호화한 자격 증명 (서버 홉 비밀번호, GitLab 비밀번호/토큰)
+CREATE TABLE IF NOT EXISTS credentials (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	kind VARCHAR(32) NOT NULL,
+	username VARCHAR(255) NOT NULL DEFAULT '',
+	fields VARCHAR(255) NOT NULL DEFAULT '',
+	key_id VARCHAR(32) NOT NULL,
+	wrapped_key VARBINARY(255) NOT NULL,
+	ciphertext MEDIUMBLOB NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	KEY idx_credentials_key_id (key_id)
+);
+
+-- 서비스의 GitLab 비밀번호, 토큰은 credentials 테이블에 저장하고 ID로 참조
+ALTER TABLE services ADD COLUMN IF NOT EXISTS gitlab_credential_id INT NULL;
@@ -0,0 +1,19 @@
+-- 사용자 계정과 리프레시 토큰
+CREATE TABLE IF NOT EXISTS users (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	username VARCHAR(64) NOT NULL,
+	password_hash VARCHAR(255) NOT NULL,
+	last_login_at TIMESTAMP NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_users_username (username)
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id INT NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	revoked_at TIMESTAMP NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_refresh_tokens_user_id (user_id)
+);
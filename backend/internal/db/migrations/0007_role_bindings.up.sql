@@ -0,0 +1,10 @@
+-- 사용자별 역할 (scope_type: global, infra, service)
+CREATE TABLE IF NOT EXISTS role_bindings (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	role VARCHAR(32) NOT NULL,
+	scope_type VARCHAR(32) NOT NULL,
+	scope_id INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_role_bindings_scope (user_id, scope_type, scope_id)
+);
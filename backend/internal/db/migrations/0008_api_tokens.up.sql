@@ -0,0 +1,16 @@
+-- 자동화용 API 토큰 (원문은 저장하지 않고 SHA-256 해시만 저장)
+CREATE TABLE IF NOT EXISTS api_tokens (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	prefix VARCHAR(16) NOT NULL,
+	actions TEXT NOT NULL,
+	infra_ids TEXT NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	last_used_at TIMESTAMP NULL,
+	revoked_at TIMESTAMP NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_api_tokens_hash (token_hash),
+	KEY idx_api_tokens_user_id (user_id)
+);
@@ -0,0 +1,26 @@
+-- 인프라 작업 감사 기록 (추가만 하며, 사용자가 삭제되어도 username으로 남음)
+CREATE TABLE IF NOT EXISTS audit_log (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	username VARCHAR(64) NOT NULL,
+	token_id INT NULL,
+	route VARCHAR(255) NOT NULL,
+	action VARCHAR(128) NOT NULL,
+	infra_id INT NULL,
+	server_id INT NULL,
+	service_id INT NULL,
+	params MEDIUMTEXT NULL,
+	command_count INT NOT NULL DEFAULT 0,
+	status VARCHAR(16) NOT NULL,
+	http_status INT NOT NULL,
+	error TEXT NULL,
+	job_id VARCHAR(64) NULL,
+	duration_ms BIGINT NOT NULL,
+	client_ip VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	KEY idx_audit_log_created_at (created_at),
+	KEY idx_audit_log_username (username),
+	KEY idx_audit_log_action (action),
+	KEY idx_audit_log_infra_id (infra_id),
+	KEY idx_audit_log_server_id (server_id)
+);
@@ -0,0 +1,4 @@
+-- 경로별로 고정한 키는 host, port 단위로 합칠 수 없으므로 직접 연결 경로의 키만 남김
+DELETE FROM ssh_host_keys WHERE via <> '';
+ALTER TABLE ssh_host_keys DROP INDEX uq_ssh_host_keys_route, ADD UNIQUE KEY uq_ssh_host_keys_host_port (host, port);
+ALTER TABLE ssh_host_keys DROP COLUMN IF EXISTS via;
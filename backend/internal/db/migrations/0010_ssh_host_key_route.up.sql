@@ -0,0 +1,3 @@
+-- 같은 사설 IP라도 거쳐 가는 점프 호스트가 다르면 다른 호스트이므로 홉 경로(via)별로 키를 고정
+ALTER TABLE ssh_host_keys ADD COLUMN IF NOT EXISTS via VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE ssh_host_keys DROP INDEX uq_ssh_host_keys_host_port, ADD UNIQUE KEY uq_ssh_host_keys_route (via, host, port);
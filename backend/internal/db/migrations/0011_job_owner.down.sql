@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_owner_state ON jobs;
+ALTER TABLE jobs DROP COLUMN IF EXISTS heartbeat_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS owner;
@@ -0,0 +1,4 @@
+-- 작업을 실행하는 백엔드 인스턴스와 마지막 heartbeat (heartbeat가 끊긴 작업만 중단으로 표시)
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS owner VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP(3) NULL;
+CREATE INDEX IF NOT EXISTS idx_jobs_owner_state ON jobs (owner, state);
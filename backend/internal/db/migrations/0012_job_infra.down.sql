@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_infra_id ON jobs;
+ALTER TABLE jobs DROP COLUMN IF EXISTS infra_id;
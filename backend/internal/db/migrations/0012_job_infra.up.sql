@@ -0,0 +1,3 @@
+-- 작업 대상 인프라 (작업 조회, 취소, 이벤트 구독 권한을 인프라별로 확인)
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS infra_id INT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_jobs_infra_id ON jobs (infra_id);
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// InfraRepository는 인프라 저장소입니다
+type InfraRepository interface {
+	GetAll() ([]Infra, error)
+	GetByID(id int) (Infra, error)
+	Create(infra Infra) (int, error)
+	Update(infra Infra) error
+	Delete(id int) error
+}
+
+// ServerRepository는 서버 저장소입니다
+// hops의 비밀번호, 개인키는 Create, Update에서 자격 증명 저장소에 암호화하여 저장합니다
+type ServerRepository interface {
+	GetAll() ([]Server, error)
+	GetByInfraID(infraID int) ([]Server, error)
+	GetByType(serverType string) ([]Server, error)
+	GetByID(id int) (Server, error)
+	// GetInfo는 SSH 접속과 조인에 필요한 서버 정보를 조회합니다
+	GetInfo(id int) (*ServerInfo, error)
+	// GetMasterInfo는 조인 명령어가 있는 메인 마스터 노드 정보를 조회합니다 (excludeServerID는 제외)
+	GetMasterInfo(infraID, excludeServerID int) (*ServerInfo, error)
+	// CountMasters는 인프라에서 excludeServerID를 제외한 마스터 노드 수를 반환합니다
+	CountMasters(infraID, excludeServerID int) (int, error)
+	// Create는 같은 인프라에 접속 경로가 같은 서버가 있으면 그 서버를 수정하고 ID를 반환합니다
+	Create(input ServerInput) (int, error)
+	Update(id int, input ServerInput) error
+	Delete(id int) error
+	UpdateJoinCommand(id int, joinCommand, certificateKey string) error
+	UpdateLastChecked(id int, lastChecked time.Time) error
+	// SetHA는 HA 상태를 Y로 바꾸며, 서버가 없으면 오류를 반환합니다
+	SetHA(id int) error
+	UpdateHAStatus(id int, haStatus string) error
+}
+
+// ServiceRepository는 서비스 저장소입니다
+// GitLab 비밀번호, 토큰은 Create, Update에서 자격 증명 저장소에 암호화하여 저장하고 GetByID에서 복호화합니다
+type ServiceRepository interface {
+	GetAll() ([]Service, error)
+	GetByID(id int) (Service, error)
+	Create(service Service) (int, error)
+	Update(service Service) error
+	Delete(id int) error
+}
+
+// Repositories는 핸들러가 사용하는 저장소 모음입니다
+type Repositories struct {
+	Infras   InfraRepository
+	Servers  ServerRepository
+	Services ServiceRepository
+}
+
+// NewRepositories는 db 연결을 사용하는 저장소를 생성합니다
+// 쿼리는 MariaDB와 SQLite에서 모두 동작하도록 작성되어 있으므로 Connect와 OpenSQLite로 연 연결 모두에 사용할 수 있습니다
+func NewRepositories(db *sql.DB) *Repositories {
+	return &Repositories{
+		Infras:   sqlInfraRepository{db},
+		Servers:  sqlServerRepository{db},
+		Services: sqlServiceRepository{db},
+	}
+}
+
+// sqlInfraRepository는 SQL DB의 infras 테이블을 사용하는 InfraRepository입니다
+type sqlInfraRepository struct {
+	db *sql.DB
+}
+
+func (r sqlInfraRepository) GetAll() ([]Infra, error)        { return GetAllInfras(r.db) }
+func (r sqlInfraRepository) GetByID(id int) (Infra, error)   { return GetInfraById(r.db, id) }
+func (r sqlInfraRepository) Create(infra Infra) (int, error) { return CreateInfra(r.db, infra) }
+func (r sqlInfraRepository) Update(infra Infra) error        { return UpdateInfra(r.db, infra) }
+func (r sqlInfraRepository) Delete(id int) error             { return DeleteInfra(r.db, id) }
+
+// sqlServerRepository는 SQL DB의 servers 테이블을 사용하는 ServerRepository입니다
+type sqlServerRepository struct {
+	db *sql.DB
+}
+
+func (r sqlServerRepository) GetAll() ([]Server, error) { return GetAllServers(r.db) }
+func (r sqlServerRepository) GetByInfraID(infraID int) ([]Server, error) {
+	return GetServersByInfraID(r.db, infraID)
+}
+func (r sqlServerRepository) GetByType(serverType string) ([]Server, error) {
+	return GetServersByType(r.db, serverType)
+}
+func (r sqlServerRepository) GetByID(id int) (Server, error)        { return GetServerByID(r.db, id) }
+func (r sqlServerRepository) GetInfo(id int) (*ServerInfo, error)   { return GetServerInfo(r.db, id) }
+func (r sqlServerRepository) Create(input ServerInput) (int, error) { return CreateServer(r.db, input) }
+func (r sqlServerRepository) Update(id int, input ServerInput) error {
+	return UpdateServer(r.db, id, input)
+}
+func (r sqlServerRepository) Delete(id int) error { return DeleteServer(r.db, id) }
+func (r sqlServerRepository) GetMasterInfo(infraID, excludeServerID int) (*ServerInfo, error) {
+	return GetMasterInfo(r.db, infraID, excludeServerID)
+}
+func (r sqlServerRepository) CountMasters(infraID, excludeServerID int) (int, error) {
+	return CountOtherMasters(r.db, infraID, excludeServerID)
+}
+func (r sqlServerRepository) UpdateJoinCommand(id int, joinCommand, certificateKey string) error {
+	return UpdateServerJoinCommand(r.db, id, joinCommand, certificateKey)
+}
+func (r sqlServerRepository) UpdateLastChecked(id int, lastChecked time.Time) error {
+	return UpdateServerLastChecked(r.db, id, lastChecked)
+}
+func (r sqlServerRepository) SetHA(id int) error { return UpdateServerHAStatus(r.db, id) }
+func (r sqlServerRepository) UpdateHAStatus(id int, haStatus string) error {
+	return UpdateServerHaStatus(r.db, id, haStatus)
+}
+
+// sqlServiceRepository는 SQL DB의 services 테이블을 사용하는 ServiceRepository입니다
+type sqlServiceRepository struct {
+	db *sql.DB
+}
+
+func (r sqlServiceRepository) GetAll() ([]Service, error)      { return GetAllServices(r.db) }
+func (r sqlServiceRepository) GetByID(id int) (Service, error) { return GetServiceByID(r.db, id) }
+func (r sqlServiceRepository) Create(service Service) (int, error) {
+	return CreateService(r.db, service)
+}
+func (r sqlServiceRepository) Update(service Service) error { return UpdateService(r.db, service) }
+func (r sqlServiceRepository) Delete(id int) error          { return DeleteService(r.db, id) }
@@ -0,0 +1,279 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k8scontrol/backend/internal/command"
+	"github.com/k8scontrol/backend/internal/vault"
+	"github.com/k8scontrol/backend/pkg/ssh"
+)
+
+// newTestRepositories는 메모리 SQLite DB와 임시 마스터 키로 저장소를 생성합니다
+func newTestRepositories(t *testing.T) *Repositories {
+	t.Helper()
+	database, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	key := make([]byte, vault.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := vault.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault.SetDefault(keyring)
+	t.Cleanup(func() { vault.SetDefault(nil) })
+
+	return NewRepositories(database)
+}
+
+func TestSQLiteInfraServerRepository(t *testing.T) {
+	repos := newTestRepositories(t)
+
+	infraID, err := repos.Infras.Create(Infra{Name: "lab", Type: "kubernetes", Info: "테스트"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	infra, err := repos.Infras.GetByID(infraID)
+	if err != nil || infra.Name != "lab" || infra.CreatedAt.IsZero() {
+		t.Fatalf("GetByID = %+v, %v", infra, err)
+	}
+
+	hops := `[{"host":"10.0.0.1","port":22,"username":"ubuntu","password":"s3cret"}]`
+	masterID, err := repos.Servers.Create(ServerInput{ServerName: "master-1", Hops: hops, Type: "master", InfraID: infraID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workerID, err := repos.Servers.Create(ServerInput{ServerName: "worker-1", Hops: `[{"host":"10.0.0.2","port":22,"username":"ubuntu"}]`, Type: "worker", InfraID: infraID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 접속 경로가 같은 서버는 새로 만들지 않고 수정
+	if id, err := repos.Servers.Create(ServerInput{Hops: hops, Type: "master,ha", InfraID: infraID}); err != nil || id != masterID {
+		t.Errorf("중복 Create = %d, %v, want %d", id, err, masterID)
+	}
+
+	master, err := repos.Servers.GetByID(masterID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master.Type != "master,ha" || master.ServerName != "master-1" || master.Ha != "N" {
+		t.Errorf("master = %+v", master)
+	}
+	// 비밀번호는 자격 증명으로 옮겨지고 hops에는 남지 않음
+	if strings.Contains(master.Hops, "s3cret") {
+		t.Errorf("hops에 비밀번호가 남아 있습니다: %s", master.Hops)
+	}
+
+	// 요청에서 받은 credential_id는 저장하지 않음 (다른 서버의 자격 증명을 참조하지 못하도록)
+	stolenID, err := repos.Servers.Create(ServerInput{ServerName: "stolen", Hops: `[{"host":"10.0.0.9","port":22,"username":"x","credential_id":1}]`, Type: "worker", InfraID: infraID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stolen, err := repos.Servers.GetByID(stolenID); err != nil || strings.Contains(stolen.Hops, "credential_id") {
+		t.Errorf("요청의 credential_id가 저장되었습니다: %s, %v", stolen.Hops, err)
+	}
+
+	if err := repos.Servers.UpdateJoinCommand(masterID, "kubeadm join ...", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Servers.SetHA(masterID); err != nil {
+		t.Fatal(err)
+	}
+	info, err := repos.Servers.GetMasterInfo(infraID, workerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ServerName != "master-1" || info.JoinCommand != "kubeadm join ..." || info.HA != "Y" {
+		t.Errorf("GetMasterInfo = %+v", info)
+	}
+	if n, err := repos.Servers.CountMasters(infraID, workerID); err != nil || n != 1 {
+		t.Errorf("CountMasters = %d, %v, want 1", n, err)
+	}
+	if servers, err := repos.Servers.GetByType("master"); err != nil || len(servers) != 1 {
+		t.Errorf("GetByType = %d개, %v", len(servers), err)
+	}
+	if err := repos.Servers.SetHA(9999); err == nil {
+		t.Error("없는 서버의 SetHA에 오류가 없습니다")
+	}
+
+	// 인프라를 삭제하면 서버도 삭제
+	if err := repos.Infras.Delete(infraID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Servers.GetByID(workerID); err != sql.ErrNoRows {
+		t.Errorf("삭제된 인프라의 서버 조회 오류 = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestSQLiteServiceRepository(t *testing.T) {
+	repos := newTestRepositories(t)
+
+	infraID, err := repos.Infras.Create(Infra{Name: "lab", Type: "kubernetes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := repos.Services.Create(Service{
+		Name:           "web",
+		Namespace:      sql.NullString{String: "web", Valid: true},
+		GitlabID:       sql.NullString{String: "deploy", Valid: true},
+		GitlabPassword: sql.NullString{String: "s3cret", Valid: true},
+		InfraID:        sql.NullInt64{Int64: int64(infraID), Valid: true},
+		UserID:         1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := repos.Services.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// GitLab 비밀번호는 자격 증명으로 저장되고 조회 시 복호화
+	if !service.GitlabCredentialID.Valid || service.GitlabPassword.String != "s3cret" {
+		t.Errorf("service = %+v", service)
+	}
+
+	service.Domain = sql.NullString{String: "web.example.com", Valid: true}
+	if err := repos.Services.Update(service); err != nil {
+		t.Fatal(err)
+	}
+	services, err := repos.Services.GetAll()
+	if err != nil || len(services) != 1 || services[0].Domain.String != "web.example.com" {
+		t.Errorf("GetAll = %+v, %v", services, err)
+	}
+	// 목록 조회는 비밀값을 복호화하지 않음
+	if services[0].GitlabPassword.Valid {
+		t.Errorf("목록에 GitLab 비밀번호가 포함되었습니다")
+	}
+
+	// 토큰만 바꾸는 수정은 저장된 비밀번호를 유지하고, 비밀번호만 바꾸는 수정은 토큰을 유지
+	partial := services[0]
+	partial.GitlabToken = sql.NullString{String: "glpat-token", Valid: true}
+	if err := repos.Services.Update(partial); err != nil {
+		t.Fatal(err)
+	}
+	partial.GitlabToken = sql.NullString{}
+	partial.GitlabPassword = sql.NullString{String: "n3w-secret", Valid: true}
+	if err := repos.Services.Update(partial); err != nil {
+		t.Fatal(err)
+	}
+	if service, err := repos.Services.GetByID(id); err != nil || service.GitlabPassword.String != "n3w-secret" || service.GitlabToken.String != "glpat-token" {
+		t.Errorf("부분 수정 후 서비스 = %+v, %v", service, err)
+	}
+
+	if err := repos.Services.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Services.GetByID(id); err != sql.ErrNoRows {
+		t.Errorf("삭제 후 조회 오류 = %v, want sql.ErrNoRows", err)
+	}
+}
+
+// TestSQLiteDialectQueries는 MariaDB와 문법이 다른 갱신(upsert), 시간 비교 쿼리를 SQLite에서 확인합니다
+func TestSQLiteDialectQueries(t *testing.T) {
+	database, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	userID, err := CreateUser(database, "alice", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 같은 범위에 다시 부여하면 역할만 바뀜
+	for _, role := range []string{RoleViewer, RoleOperator} {
+		if err := GrantRole(database, RoleBinding{UserID: userID, Role: role, ScopeType: ScopeGlobal}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if bindings, err := GetUserRoleBindings(database, userID); err != nil || len(bindings) != 1 || bindings[0].Role != RoleOperator {
+		t.Errorf("GetUserRoleBindings = %+v, %v", bindings, err)
+	}
+
+	// 같은 ID로 다시 저장하면 상태가 갱신됨
+	jobs := NewJobStore(database)
+	now := time.Now()
+	job := command.Job{ID: "job-1", Action: "installDocker", State: command.JobQueued, Owner: "a", HeartbeatAt: now, CreatedAt: now, UpdatedAt: now}
+	if err := jobs.SaveJob(job); err != nil {
+		t.Fatal(err)
+	}
+	job.State, job.StartedAt = command.JobRunning, &now
+	if err := jobs.SaveJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := jobs.GetJob("job-1"); err != nil || saved.State != command.JobRunning || saved.StartedAt == nil {
+		t.Errorf("GetJob = %+v, %v", saved, err)
+	}
+	// heartbeat가 staleBefore 이후이면 중단하지 않고, 이전이면 중단
+	if err := jobs.TouchJobs("a", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := jobs.MarkInterruptedJobs(now); err != nil || n != 0 {
+		t.Errorf("MarkInterruptedJobs(heartbeat 이전) = %d, %v, want 0", n, err)
+	}
+	if n, err := jobs.MarkInterruptedJobs(now.Add(2 * time.Minute)); err != nil || n != 1 {
+		t.Errorf("MarkInterruptedJobs(heartbeat 이후) = %d, %v, want 1", n, err)
+	}
+
+	// 교체하면 키가 바뀌고 승인 대기 키는 지워짐
+	hostKeys := NewHostKeyStore(database)
+	if err := hostKeys.PinHostKey(ssh.HostKey{Host: "10.0.0.1", Port: 22, KeyType: "ssh-ed25519", Fingerprint: "SHA256:old", PublicKey: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := hostKeys.RecordHostKeyMismatch(ssh.HostKey{Host: "10.0.0.1", Port: 22, KeyType: "ssh-ed25519", Fingerprint: "SHA256:new", PublicKey: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, fingerprint := range []string{"SHA256:new", "SHA256:newer"} {
+		if err := RotateHostKey(database, "", "10.0.0.1", 22, &ssh.HostKey{KeyType: "ssh-ed25519", Fingerprint: fingerprint, PublicKey: "new"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if key, err := GetHostKey(database, "", "10.0.0.1", 22); err != nil || key.Fingerprint != "SHA256:newer" || key.PendingFingerprint != "" {
+		t.Errorf("GetHostKey = %+v, %v", key, err)
+	}
+
+	// 리프레시 토큰은 한 번만 사용할 수 있고, 만료된 토큰은 사용할 수 없음
+	if err := SaveRefreshToken(database, "valid", userID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveRefreshToken(database, "expired", userID, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConsumeRefreshToken(database, "valid", userID); err != nil {
+		t.Errorf("ConsumeRefreshToken: %v", err)
+	}
+	for _, id := range []string{"valid", "expired"} {
+		if err := ConsumeRefreshToken(database, id, userID); err != ErrRefreshTokenInvalid {
+			t.Errorf("ConsumeRefreshToken(%s) = %v, want ErrRefreshTokenInvalid", id, err)
+		}
+	}
+
+	tokenID, err := CreateAPIToken(database, APIToken{UserID: userID, Name: "ci", Prefix: "kc_abc", ExpiresAt: time.Now().Add(time.Hour)}, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := TouchAPIToken(database, tokenID); err != nil {
+		t.Fatal(err)
+	}
+	if err := RevokeAPIToken(database, tokenID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := GetAPITokenByHash(database, "hash"); err != nil || token.LastUsedAt == nil || token.RevokedAt == nil {
+		t.Errorf("GetAPITokenByHash = %+v, %v", token, err)
+	}
+	if err := RevokeAPIToken(database, tokenID, userID); err != sql.ErrNoRows {
+		t.Errorf("두 번째 RevokeAPIToken = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// 역할 (권한이 큰 순서: admin > operator > viewer)
+const (
+	RoleViewer   = "viewer"   // 상태, 로그 조회
+	RoleOperator = "operator" // 설치, 배포, 재시작 등 일반 작업
+	RoleAdmin    = "admin"    // 인프라, 마스터 노드 삭제 등 파괴적 작업과 사용자, 권한 관리
+)
+
+// 역할이 적용되는 범위
+const (
+	ScopeGlobal  = "global"  // 모든 인프라와 서비스
+	ScopeInfra   = "infra"   // 인프라 하나와 그 인프라의 서버, 서비스
+	ScopeService = "service" // 서비스 하나
+)
+
+// RoleBinding 사용자에게 부여된 역할과 적용 범위
+type RoleBinding struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Role      string    `json:"role"`
+	ScopeType string    `json:"scope_type"`
+	ScopeID   int       `json:"scope_id"` // 전역 범위는 0
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidRole은 지원하는 역할인지 확인합니다
+func ValidRole(role string) bool {
+	return role == RoleViewer || role == RoleOperator || role == RoleAdmin
+}
+
+const roleBindingColumns = "id, user_id, role, scope_type, scope_id, created_at"
+
+func queryRoleBindings(db *sql.DB, query string, args ...interface{}) ([]RoleBinding, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bindings := []RoleBinding{}
+	for rows.Next() {
+		var b RoleBinding
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Role, &b.ScopeType, &b.ScopeID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, rows.Err()
+}
+
+// GetRoleBindings 모든 역할 부여 목록 조회
+func GetRoleBindings(db *sql.DB) ([]RoleBinding, error) {
+	return queryRoleBindings(db, "SELECT "+roleBindingColumns+" FROM role_bindings ORDER BY user_id, id")
+}
+
+// GetUserRoleBindings 사용자에게 부여된 역할 목록 조회
+func GetUserRoleBindings(db *sql.DB, userID int) ([]RoleBinding, error) {
+	return queryRoleBindings(db, "SELECT "+roleBindingColumns+" FROM role_bindings WHERE user_id = ? ORDER BY id", userID)
+}
+
+// GrantRole 사용자에게 범위별 역할 부여. 같은 범위에 이미 역할이 있으면 새 역할로 바꿉니다
+func GrantRole(db *sql.DB, b RoleBinding) error {
+	if b.ScopeType == ScopeGlobal {
+		b.ScopeID = 0
+	}
+	query := `
+		INSERT INTO role_bindings (user_id, role, scope_type, scope_id)
+		VALUES (?, ?, ?, ?)
+	` + dialectOf(db).upsert([]string{"user_id", "scope_type", "scope_id"}, []string{"role"})
+	_, err := db.Exec(query, b.UserID, b.Role, b.ScopeType, b.ScopeID)
+	return err
+}
+
+// RevokeRole 역할 부여 삭제
+func RevokeRole(db *sql.DB, id int) error {
+	result, err := db.Exec("DELETE FROM role_bindings WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// EnsureAdminRole 전역 관리자가 한 명도 없으면 가장 먼저 등록된 사용자에게 전역 관리자 역할 부여
+// 역할 기능 도입 전에 만든 사용자가 권한 없이 잠기지 않도록 서버 시작 시 호출합니다. 역할을 부여한 사용자를 반환합니다
+func EnsureAdminRole(db *sql.DB) (*User, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM role_bindings WHERE role = ? AND scope_type = ?"
+	if err := db.QueryRow(query, RoleAdmin, ScopeGlobal).Scan(&count); err != nil || count > 0 {
+		return nil, err
+	}
+
+	user, err := scanUser(db.QueryRow("SELECT " + userColumns + " FROM users ORDER BY id LIMIT 1"))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := GrantRole(db, RoleBinding{UserID: user.ID, Role: RoleAdmin, ScopeType: ScopeGlobal}); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetServiceInfraID 서비스가 속한 인프라 ID 조회 (비밀값을 복호화하지 않음)
+func GetServiceInfraID(db *sql.DB, serviceID int) (int, error) {
+	var infraID sql.NullInt64
+	err := db.QueryRow("SELECT infra_id FROM services WHERE id = ?", serviceID).Scan(&infraID)
+	return int(infraID.Int64), err
+}
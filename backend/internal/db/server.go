@@ -309,62 +309,20 @@ func GetServersByType(db *sql.DB, serverType string) ([]Server, error) {
 }
 
 // GetServerByHopsAndInfraID Hops와 인프라 ID로 서버 조회
+// hops의 비밀값은 자격 증명으로 저장되므로 비밀값을 제외한 접속 경로(username@host:port)로 비교합니다
 func GetServerByHopsAndInfraID(db *sql.DB, hops string, infraID int) (Server, error) {
-	query := `
-		SELECT id, server_name, hops, join_command, certificate_key, type, infra_id, ha, last_checked, created_at, updated_at 
-		FROM servers 
-		WHERE hops = ? AND infra_id = ?
-	`
-
-	var server Server
-	var serverNameNull sql.NullString
-	var joinCommandNull sql.NullString
-	var certificateKeyNull sql.NullString
-	var lastCheckedNull sql.NullTime
-
-	err := db.QueryRow(query, hops, infraID).Scan(
-		&server.ID,
-		&serverNameNull,
-		&server.Hops,
-		&joinCommandNull,
-		&certificateKeyNull,
-		&server.Type,
-		&server.InfraID,
-		&server.Ha,
-		&lastCheckedNull,
-		&server.CreatedAt,
-		&server.UpdatedAt,
-	)
-
-	if err == nil {
-		// NULL 값 처리
-		if serverNameNull.Valid {
-			server.ServerName = serverNameNull.String
-		} else {
-			server.ServerName = ""
-		}
-
-		if joinCommandNull.Valid {
-			server.JoinCommand = joinCommandNull.String
-		} else {
-			server.JoinCommand = ""
-		}
-
-		if certificateKeyNull.Valid {
-			server.CertificateKey = certificateKeyNull.String
-		} else {
-			server.CertificateKey = ""
-		}
+	servers, err := GetServersByInfraID(db, infraID)
+	if err != nil {
+		return Server{}, err
+	}
 
-		// LastChecked NULL 값 처리
-		if lastCheckedNull.Valid {
-			server.LastChecked = &lastCheckedNull.Time
-		} else {
-			server.LastChecked = nil // NULL 값이면 nil로 설정
+	route := hopsRoute(hops)
+	for _, server := range servers {
+		if hopsRoute(server.Hops) == route {
+			return server, nil
 		}
 	}
-
-	return server, err
+	return Server{}, sql.ErrNoRows
 }
 
 // CreateServer 새 서버 생성
@@ -382,6 +340,12 @@ func CreateServer(db *sql.DB, input ServerInput) (int, error) {
 		return 0, err
 	}
 
+	// hops의 비밀번호, 개인키는 자격 증명 저장소에 암호화하여 저장하고 ID만 남김
+	hops, err := SealHops(db, input.Hops, "")
+	if err != nil {
+		return 0, err
+	}
+
 	// 서버가 존재하지 않으면 새로 생성
 	// 필요한 컬럼만 명시하고 나머지는 기본값 사용
 	query := `
@@ -389,10 +353,9 @@ func CreateServer(db *sql.DB, input ServerInput) (int, error) {
 		VALUES (?, ?, ?, ?)
 	`
 
-	// 프론트엔드에서 전송한 값을 그대로 사용
 	result, err := db.Exec(query,
 		input.ServerName,
-		input.Hops,
+		hops,
 		input.Type,
 		input.InfraID,
 	)
@@ -422,6 +385,10 @@ func UpdateServer(db *sql.DB, id int, input ServerInput) error {
 	if hops == "" {
 		hops = existingServer.Hops
 	}
+	hops, err = SealHops(db, hops, existingServer.Hops)
+	if err != nil {
+		return err
+	}
 
 	typeValue := input.Type
 	if typeValue == "" {
@@ -473,6 +440,21 @@ func DeleteServer(db *sql.DB, id int) error {
 	return err
 }
 
+// CountOtherMasters 인프라에서 excludeServerID를 제외한 마스터 노드 수 조회
+func CountOtherMasters(db *sql.DB, infraID, excludeServerID int) (int, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM servers 
+		WHERE infra_id = ? 
+		AND id != ? 
+		AND type LIKE '%master%'
+	`
+
+	var count int
+	err := db.QueryRow(query, infraID, excludeServerID).Scan(&count)
+	return count, err
+}
+
 // UpdateServerLastChecked 서버의 마지막 확인 시간 업데이트
 func UpdateServerLastChecked(db *sql.DB, serverID int, lastChecked time.Time) error {
 	query := "UPDATE servers SET last_checked = ? WHERE id = ?"
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"log"
 )
 
 // Service 모델 정의
@@ -13,41 +14,45 @@ type Service struct {
 	Namespace      sql.NullString `json:"namespace"`
 	GitlabURL      sql.NullString `json:"gitlab_url"`
 	GitlabID       sql.NullString `json:"gitlab_id"`
-	GitlabPassword sql.NullString `json:"gitlab_password"`
-	GitlabToken    sql.NullString `json:"gitlab_token"`
+	GitlabPassword sql.NullString `json:"-"` // 자격 증명 저장소에서 복호화한 값 (응답에 포함하지 않음)
+	GitlabToken    sql.NullString `json:"-"` // 자격 증명 저장소에서 복호화한 값 (응답에 포함하지 않음)
 	GitlabBranch   sql.NullString `json:"gitlab_branch"`
-	UserID         int64          `json:"user_id"`
-	InfraID        sql.NullInt64  `json:"infra_id"`
-	CreatedAt      string         `json:"created_at"`
-	UpdatedAt      string         `json:"updated_at"`
+	// GitlabCredentialID는 GitLab 비밀번호, 토큰을 암호화하여 저장한 자격 증명의 ID입니다
+	GitlabCredentialID sql.NullInt64 `json:"gitlab_credential_id"`
+	UserID             int64         `json:"user_id"`
+	InfraID            sql.NullInt64 `json:"infra_id"`
+	CreatedAt          string        `json:"created_at"`
+	UpdatedAt          string        `json:"updated_at"`
 }
 
 // MarshalJSON은 Service 구조체의 JSON 직렬화를 위한 메서드입니다.
-// sql.NullString 필드를 일반 string으로 변환합니다.
+// sql.NullString 필드를 일반 string으로 변환하며, GitLab 비밀번호와 토큰은 설정 여부만 포함합니다.
 func (s Service) MarshalJSON() ([]byte, error) {
 	type Alias Service // 재귀 호출 방지를 위한 타입 별칭
 
 	// 일반 문자열로 변환된 필드를 가진 임시 구조체
 	return json.Marshal(&struct {
-		Domain         string `json:"domain"`
-		Namespace      string `json:"namespace"`
-		GitlabURL      string `json:"gitlab_url"`
-		GitlabID       string `json:"gitlab_id"`
-		GitlabPassword string `json:"gitlab_password"`
-		GitlabToken    string `json:"gitlab_token"`
-		GitlabBranch   string `json:"gitlab_branch"`
-		InfraID        int64  `json:"infra_id,omitempty"`
+		Domain             string `json:"domain"`
+		Namespace          string `json:"namespace"`
+		GitlabURL          string `json:"gitlab_url"`
+		GitlabID           string `json:"gitlab_id"`
+		GitlabPasswordSet  bool   `json:"gitlab_password_set"`
+		GitlabTokenSet     bool   `json:"gitlab_token_set"`
+		GitlabBranch       string `json:"gitlab_branch"`
+		GitlabCredentialID int64  `json:"gitlab_credential_id,omitempty"`
+		InfraID            int64  `json:"infra_id,omitempty"`
 		*Alias
 	}{
-		Domain:         stringFromNullString(s.Domain),
-		Namespace:      stringFromNullString(s.Namespace),
-		GitlabURL:      stringFromNullString(s.GitlabURL),
-		GitlabID:       stringFromNullString(s.GitlabID),
-		GitlabPassword: stringFromNullString(s.GitlabPassword),
-		GitlabToken:    stringFromNullString(s.GitlabToken),
-		GitlabBranch:   stringFromNullString(s.GitlabBranch),
-		InfraID:        nullInt64ToInt64(s.InfraID),
-		Alias:          (*Alias)(&s),
+		Domain:             stringFromNullString(s.Domain),
+		Namespace:          stringFromNullString(s.Namespace),
+		GitlabURL:          stringFromNullString(s.GitlabURL),
+		GitlabID:           stringFromNullString(s.GitlabID),
+		GitlabPasswordSet:  stringFromNullString(s.GitlabPassword) != "",
+		GitlabTokenSet:     stringFromNullString(s.GitlabToken) != "",
+		GitlabBranch:       stringFromNullString(s.GitlabBranch),
+		GitlabCredentialID: nullInt64ToInt64(s.GitlabCredentialID),
+		InfraID:            nullInt64ToInt64(s.InfraID),
+		Alias:              (*Alias)(&s),
 	})
 }
 
@@ -70,7 +75,7 @@ func nullInt64ToInt64(n sql.NullInt64) int64 {
 // GetAllServices 모든 서비스 조회
 func GetAllServices(db *sql.DB) ([]Service, error) {
 	query := `
-		SELECT id, name, domain, namespace, gitlab_url, gitlab_id, gitlab_password, gitlab_token, gitlab_branch, user_id, infra_id, created_at, updated_at 
+		SELECT id, name, domain, namespace, gitlab_url, gitlab_id, gitlab_password, gitlab_token, gitlab_branch, gitlab_credential_id, user_id, infra_id, created_at, updated_at 
 		FROM services
 	`
 
@@ -93,6 +98,7 @@ func GetAllServices(db *sql.DB) ([]Service, error) {
 			&service.GitlabPassword,
 			&service.GitlabToken,
 			&service.GitlabBranch,
+			&service.GitlabCredentialID,
 			&service.UserID,
 			&service.InfraID,
 			&service.CreatedAt,
@@ -110,7 +116,7 @@ func GetAllServices(db *sql.DB) ([]Service, error) {
 // GetServiceByID ID로 서비스 조회
 func GetServiceByID(db *sql.DB, id int) (Service, error) {
 	query := `
-		SELECT id, name, domain, namespace, gitlab_url, gitlab_id, gitlab_password, gitlab_token, gitlab_branch, user_id, infra_id, created_at, updated_at 
+		SELECT id, name, domain, namespace, gitlab_url, gitlab_id, gitlab_password, gitlab_token, gitlab_branch, gitlab_credential_id, user_id, infra_id, created_at, updated_at 
 		FROM services 
 		WHERE id = ?
 	`
@@ -126,20 +132,86 @@ func GetServiceByID(db *sql.DB, id int) (Service, error) {
 		&service.GitlabPassword,
 		&service.GitlabToken,
 		&service.GitlabBranch,
+		&service.GitlabCredentialID,
 		&service.UserID,
 		&service.InfraID,
 		&service.CreatedAt,
 		&service.UpdatedAt,
 	)
+	if err == nil {
+		loadServiceCredential(db, &service)
+	}
 
 	return service, err
 }
 
+// loadServiceCredential은 서비스의 GitLab 자격 증명을 복호화하여 GitlabPassword, GitlabToken에 채웁니다
+// 복호화할 수 없으면 로그만 남기며, 이 경우 GitLab 인증 정보가 없는 것으로 처리됩니다
+func loadServiceCredential(db *sql.DB, service *Service) {
+	if !service.GitlabCredentialID.Valid {
+		return
+	}
+	cred, err := OpenCredential(db, int(service.GitlabCredentialID.Int64))
+	if err != nil {
+		log.Printf("[자격 증명] 서비스 %d의 GitLab 자격 증명을 불러올 수 없습니다: %v", service.ID, err)
+		return
+	}
+	service.GitlabPassword = nullStringFromString(cred.Secrets["password"])
+	service.GitlabToken = nullStringFromString(cred.Secrets["token"])
+}
+
+// sealServiceCredential은 서비스의 GitLab 비밀번호, 토큰을 자격 증명 저장소에 암호화하여 저장하고 자격 증명 ID를 반환합니다
+// 비밀값이 없으면 기존 자격 증명 ID를 그대로 반환하며, 기존 자격 증명을 수정할 때 주어지지 않은 비밀값은 기존 값을 유지합니다
+func sealServiceCredential(db *sql.DB, service Service) (sql.NullInt64, error) {
+	secrets := make(map[string]string)
+	if password := stringFromNullString(service.GitlabPassword); password != "" {
+		secrets["password"] = password
+	}
+	if token := stringFromNullString(service.GitlabToken); token != "" {
+		secrets["token"] = token
+	}
+	if len(secrets) == 0 {
+		return service.GitlabCredentialID, nil
+	}
+
+	cred := Credential{
+		Name:     "service:" + service.Name,
+		Kind:     CredentialGit,
+		Username: stringFromNullString(service.GitlabID),
+		Secrets:  secrets,
+	}
+	if service.GitlabCredentialID.Valid {
+		id := int(service.GitlabCredentialID.Int64)
+		existing, err := OpenCredential(db, id)
+		if err != nil {
+			return service.GitlabCredentialID, err
+		}
+		for key, value := range existing.Secrets {
+			if _, ok := secrets[key]; !ok {
+				secrets[key] = value
+			}
+		}
+		err = UpdateCredential(db, id, cred)
+		return service.GitlabCredentialID, err
+	}
+	id, err := CreateCredential(db, cred)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	return sql.NullInt64{Int64: int64(id), Valid: true}, nil
+}
+
 // CreateService 새 서비스 생성
 func CreateService(db *sql.DB, service Service) (int, error) {
+	// GitLab 비밀번호, 토큰은 자격 증명 저장소에 저장하고 평문 컬럼은 비워 둠
+	credentialID, err := sealServiceCredential(db, service)
+	if err != nil {
+		return 0, err
+	}
+
 	query := `
-		INSERT INTO services (name, domain, namespace, gitlab_url, gitlab_id, gitlab_password, gitlab_token, gitlab_branch, user_id, infra_id) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO services (name, domain, namespace, gitlab_url, gitlab_id, gitlab_password, gitlab_token, gitlab_branch, gitlab_credential_id, user_id, infra_id) 
+		VALUES (?, ?, ?, ?, ?, NULL, NULL, ?, ?, ?, ?)
 	`
 
 	result, err := db.Exec(query,
@@ -148,9 +220,8 @@ func CreateService(db *sql.DB, service Service) (int, error) {
 		service.Namespace,
 		service.GitlabURL,
 		service.GitlabID,
-		service.GitlabPassword,
-		service.GitlabToken,
 		service.GitlabBranch,
+		credentialID,
 		service.UserID,
 		service.InfraID,
 	)
@@ -164,20 +235,25 @@ func CreateService(db *sql.DB, service Service) (int, error) {
 
 // UpdateService 서비스 업데이트
 func UpdateService(db *sql.DB, service Service) error {
+	// GitLab 비밀번호, 토큰은 자격 증명 저장소에 저장하고 평문 컬럼은 비워 둠
+	credentialID, err := sealServiceCredential(db, service)
+	if err != nil {
+		return err
+	}
+
 	query := `		UPDATE services 
-		SET name = ?, domain = ?, namespace = ?, gitlab_url = ?, gitlab_id = ?, gitlab_password = ?, gitlab_token = ?, gitlab_branch = ?, user_id = ?, infra_id = ? 
+		SET name = ?, domain = ?, namespace = ?, gitlab_url = ?, gitlab_id = ?, gitlab_password = NULL, gitlab_token = NULL, gitlab_branch = ?, gitlab_credential_id = ?, user_id = ?, infra_id = ? 
 		WHERE id = ?
 	`
 
-	_, err := db.Exec(query,
+	_, err = db.Exec(query,
 		service.Name,
 		service.Domain,
 		service.Namespace,
 		service.GitlabURL,
 		service.GitlabID,
-		service.GitlabPassword,
-		service.GitlabToken,
 		service.GitlabBranch,
+		credentialID,
 		service.UserID,
 		service.InfraID,
 		service.ID,
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite는 SQLite DB 파일을 엽니다 (database.driver가 sqlite일 때 Connect에서 사용)
+// 스키마는 만들지 않으므로 Migrate로 적용해야 합니다
+// path가 ":memory:"이면 연결을 닫을 때 사라지는 메모리 DB를 사용합니다 (테스트용)
+func openSQLite(path string) (*sql.DB, error) {
+	dsn := path
+	if !strings.HasPrefix(dsn, "file:") {
+		dsn = "file:" + dsn
+	}
+	// 외래 키(서버의 infra_id ON DELETE CASCADE 등)는 연결마다 켜야 함
+	// 시간 값은 문자열로 비교할 수 있도록 SQLite 시간 형식으로 저장
+	// 트랜잭션은 시작할 때 쓰기 잠금을 잡아, 여러 연결이 읽기 잠금을 쓰기 잠금으로 올리다 SQLITE_BUSY로 실패하지 않게 함
+	dsn += "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite&_txlock=immediate"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// 메모리 DB는 연결마다 별도의 DB이므로 연결 하나만 사용
+	if path == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenSQLite는 SQLite DB 파일을 열고 마이그레이션을 모두 적용합니다
+// 외부 MariaDB 없이 로컬 개발과 테스트에 사용하며, 순수 Go 드라이버를 사용하므로 cgo가 필요 없습니다
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("SQLite 스키마 생성 실패: %w", err)
+	}
+	return db, nil
+}
+
+var (
+	sqliteCreateTable   = regexp.MustCompile(`(?is)^CREATE TABLE IF NOT EXISTS (\w+) \((.*)\)$`)
+	sqliteAlterTable    = regexp.MustCompile(`(?is)^ALTER TABLE (\w+) (.*)$`)
+	sqliteDropIndexOn   = regexp.MustCompile(`(?i)^DROP INDEX (IF EXISTS )?(\w+) ON \w+$`)
+	sqliteTableIndex    = regexp.MustCompile(`(?i)^(UNIQUE )?(?:KEY|INDEX) (\w+) (\(.*\))$`)
+	sqliteAutoIncrement = regexp.MustCompile(`(?i)\b(?:BIG)?INT AUTO_INCREMENT PRIMARY KEY`)
+	sqliteTypes         = strings.NewReplacer(
+		"MEDIUMTEXT", "TEXT",
+		"MEDIUMBLOB", "BLOB",
+		"VARBINARY(255)", "BLOB",
+		"TIMESTAMP(3)", "TIMESTAMP",
+		"CURRENT_TIMESTAMP(3)", "CURRENT_TIMESTAMP",
+	)
+)
+
+// sqliteStatements는 마이그레이션의 MariaDB 문장 하나를 같은 스키마를 만드는 SQLite 문장으로 바꿉니다
+// 마이그레이션에서 사용하는 문법만 변환합니다
+//   - AUTO_INCREMENT 기본 키, MEDIUMTEXT 등 타입과 TIMESTAMP(3)의 정밀도
+//   - CREATE TABLE 안의 KEY, UNIQUE KEY는 CREATE INDEX로, ON UPDATE CURRENT_TIMESTAMP는 트리거로 분리
+//   - ALTER TABLE의 IF [NOT] EXISTS, 여러 변경을 쉼표로 묶은 문장, DROP INDEX ... ON, ADD [UNIQUE] KEY
+//
+// SQLite는 DDL도 트랜잭션으로 묶이므로 마이그레이션을 다시 실행할 수 있게 만드는 IF [NOT] EXISTS는 없어도 됩니다
+func sqliteStatements(stmt string) []string {
+	stmt = strings.TrimSpace(stmt)
+	if m := sqliteCreateTable.FindStringSubmatch(stmt); m != nil {
+		return sqliteCreateTableStatements(m[1], m[2])
+	}
+	if m := sqliteAlterTable.FindStringSubmatch(stmt); m != nil {
+		var statements []string
+		for _, change := range splitDefinitions(m[2]) {
+			statements = append(statements, sqliteAlterStatement(m[1], change))
+		}
+		return statements
+	}
+	if m := sqliteDropIndexOn.FindStringSubmatch(stmt); m != nil {
+		return []string{"DROP INDEX IF EXISTS " + m[2]}
+	}
+	return []string{stmt}
+}
+
+// sqliteCreateTableStatements는 CREATE TABLE의 컬럼, 키 정의를 SQLite의 CREATE TABLE, CREATE INDEX, 트리거로 나눕니다
+func sqliteCreateTableStatements(table, body string) []string {
+	var columns, indexes, triggers []string
+	for _, def := range splitDefinitions(body) {
+		if m := sqliteTableIndex.FindStringSubmatch(def); m != nil {
+			indexes = append(indexes, sqliteCreateIndex(table, m[1] != "", m[2], m[3]))
+			continue
+		}
+		def = sqliteAutoIncrement.ReplaceAllString(sqliteTypes.Replace(def), "INTEGER PRIMARY KEY AUTOINCREMENT")
+		if column, ok := strings.CutSuffix(def, " ON UPDATE CURRENT_TIMESTAMP"); ok {
+			def = column
+			name := strings.Fields(column)[0]
+			triggers = append(triggers, fmt.Sprintf(
+				"CREATE TRIGGER IF NOT EXISTS trg_%[1]s_%[2]s AFTER UPDATE ON %[1]s\nBEGIN\n\tUPDATE %[1]s SET %[2]s = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;\nEND",
+				table, name))
+		}
+		columns = append(columns, def)
+	}
+
+	statements := []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t%s\n)", table, strings.Join(columns, ",\n\t"))}
+	statements = append(statements, indexes...)
+	return append(statements, triggers...)
+}
+
+// sqliteAlterStatement는 ALTER TABLE의 변경 하나를 SQLite 문장으로 바꿉니다
+func sqliteAlterStatement(table, change string) string {
+	upper := strings.ToUpper(change)
+	switch {
+	case strings.HasPrefix(upper, "ADD COLUMN "):
+		column := strings.TrimSpace(change[len("ADD COLUMN "):])
+		column = trimPrefixFold(column, "IF NOT EXISTS ")
+		return "ALTER TABLE " + table + " ADD COLUMN " + sqliteTypes.Replace(column)
+	case strings.HasPrefix(upper, "DROP COLUMN "):
+		column := trimPrefixFold(strings.TrimSpace(change[len("DROP COLUMN "):]), "IF EXISTS ")
+		return "ALTER TABLE " + table + " DROP COLUMN " + column
+	case strings.HasPrefix(upper, "DROP INDEX "):
+		index := trimPrefixFold(strings.TrimSpace(change[len("DROP INDEX "):]), "IF EXISTS ")
+		return "DROP INDEX IF EXISTS " + index
+	case strings.HasPrefix(upper, "ADD "):
+		if m := sqliteTableIndex.FindStringSubmatch(strings.TrimSpace(change[len("ADD "):])); m != nil {
+			return sqliteCreateIndex(table, m[1] != "", m[2], m[3])
+		}
+	}
+	return "ALTER TABLE " + table + " " + change
+}
+
+// sqliteCreateIndex는 CREATE [UNIQUE] INDEX 문장을 만듭니다
+func sqliteCreateIndex(table string, unique bool, name, columns string) string {
+	kind := "INDEX"
+	if unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s %s", kind, name, table, columns)
+}
+
+// splitDefinitions는 괄호 밖의 쉼표로 정의 목록을 나누고 앞뒤 공백을 제거합니다
+func splitDefinitions(body string) []string {
+	var defs []string
+	depth, start := 0, 0
+	flush := func(end int) {
+		if def := strings.TrimSpace(body[start:end]); def != "" {
+			defs = append(defs, def)
+		}
+	}
+	for i, r := range body {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				flush(i)
+				start = i + 1
+			}
+		}
+	}
+	flush(len(body))
+	return defs
+}
+
+// trimPrefixFold는 대소문자를 구분하지 않고 prefix를 제거합니다
+func trimPrefixFold(s, prefix string) string {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):]
+	}
+	return s
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrRefreshTokenInvalid는 이미 사용했거나 폐기, 만료된 리프레시 토큰입니다
+var ErrRefreshTokenInvalid = errors.New("사용할 수 없는 리프레시 토큰입니다")
+
+// User 사용자 정보
+type User struct {
+	ID           int        `json:"id"`
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"-"`
+	LastLoginAt  *time.Time `json:"last_login_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+const userColumns = "id, username, password_hash, last_login_at, created_at, updated_at"
+
+func scanUser(row interface{ Scan(...interface{}) error }) (User, error) {
+	var user User
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt)
+	return user, err
+}
+
+// CreateUser 사용자 생성 (passwordHash는 bcrypt 해시)
+func CreateUser(db *sql.DB, username, passwordHash string) (int, error) {
+	result, err := db.Exec("INSERT INTO users (username, password_hash) VALUES (?, ?)", username, passwordHash)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// GetUserByID ID로 사용자 조회
+func GetUserByID(db *sql.DB, id int) (User, error) {
+	return scanUser(db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = ?", id))
+}
+
+// GetUserByUsername 사용자 이름으로 사용자 조회
+func GetUserByUsername(db *sql.DB, username string) (User, error) {
+	return scanUser(db.QueryRow("SELECT "+userColumns+" FROM users WHERE username = ?", username))
+}
+
+// GetUsers 사용자 목록 조회
+func GetUsers(db *sql.DB) ([]User, error) {
+	rows, err := db.Query("SELECT " + userColumns + " FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers 등록된 사용자 수 조회
+func CountUsers(db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+// UpdateUserPassword 사용자 비밀번호 변경. 발급된 리프레시 토큰은 모두 폐기합니다
+func UpdateUserPassword(db *sql.DB, id int, passwordHash string) error {
+	result, err := db.Exec("UPDATE users SET password_hash = ? WHERE id = ?", passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return RevokeUserRefreshTokens(db, id)
+}
+
+// TouchUserLogin 마지막 로그인 시간 갱신
+func TouchUserLogin(db *sql.DB, id int) error {
+	_, err := db.Exec("UPDATE users SET last_login_at = ? WHERE id = ?", time.Now().UTC(), id)
+	return err
+}
+
+// DeleteUser 사용자와 사용자의 리프레시 토큰, 역할, API 토큰 삭제
+func DeleteUser(db *sql.DB, id int) error {
+	if _, err := db.Exec("DELETE FROM refresh_tokens WHERE user_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := db.Exec("DELETE FROM role_bindings WHERE user_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := db.Exec("DELETE FROM api_tokens WHERE user_id = ?", id); err != nil {
+		return err
+	}
+	result, err := db.Exec("DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SaveRefreshToken 발급한 리프레시 토큰의 ID(jti)를 저장
+func SaveRefreshToken(db *sql.DB, tokenID string, userID int, expiresAt time.Time) error {
+	_, err := db.Exec("INSERT INTO refresh_tokens (id, user_id, expires_at) VALUES (?, ?, ?)", tokenID, userID, expiresAt.UTC())
+	return err
+}
+
+// ConsumeRefreshToken 리프레시 토큰을 사용 처리
+// 토큰은 한 번만 사용할 수 있으며, 이미 사용했거나 폐기, 만료된 토큰이면 ErrRefreshTokenInvalid를 반환합니다
+func ConsumeRefreshToken(db *sql.DB, tokenID string, userID int) error {
+	now := time.Now().UTC()
+	result, err := db.Exec(`
+		UPDATE refresh_tokens SET revoked_at = ?
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?
+	`, now, tokenID, userID, now)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRefreshTokenInvalid
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens 사용자의 리프레시 토큰을 모두 폐기하고 만료된 토큰을 정리
+func RevokeUserRefreshTokens(db *sql.DB, userID int) error {
+	now := time.Now().UTC()
+	if _, err := db.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", now, userID); err != nil {
+		return err
+	}
+	_, err := db.Exec("DELETE FROM refresh_tokens WHERE expires_at < ?", now)
+	return err
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"os"
 	"time"
 
 	"github.com/k8scontrol/backend/pkg/ssh"
@@ -20,14 +23,51 @@ func NewSSHUtils() *SSHUtils {
 
 // ExecuteCommands는 SSH를 통해 명령어를 실행합니다.
 func (u *SSHUtils) ExecuteCommands(hops []ssh.HopConfig, finalCommands []string, timeoutMs int) ([]ssh.CommandResult, error) {
-	// 타임아웃 설정 (밀리초 -> 시간 단위로 변환)
-	timeout := time.Duration(timeoutMs) * time.Millisecond
-	if timeoutMs == 0 {
-		timeout = 120 * time.Second // 기본값 120초
-	}
+	return u.ExecuteCommandsStream(context.Background(), hops, finalCommands, timeoutMs, nil)
+}
+
+// ExecuteCommandsContext는 SSH를 통해 명령어를 실행하며, ctx가 취소되면 원격 프로세스를 종료합니다.
+func (u *SSHUtils) ExecuteCommandsContext(ctx context.Context, hops []ssh.HopConfig, finalCommands []string, timeoutMs int) ([]ssh.CommandResult, error) {
+	return u.ExecuteCommandsStream(ctx, hops, finalCommands, timeoutMs, nil)
+}
 
+// ExecuteCommandsStream은 SSH를 통해 명령어를 실행하면서 출력을 줄 단위로 handler에 전달합니다.
+func (u *SSHUtils) ExecuteCommandsStream(ctx context.Context, hops []ssh.HopConfig, finalCommands []string, timeoutMs int, handler ssh.OutputHandler) ([]ssh.CommandResult, error) {
 	// SSH 명령어 실행
-	return u.ssh.ExecuteCommands(hops, finalCommands, timeout)
+	return u.ssh.ExecuteCommandsStream(ctx, hops, finalCommands, toTimeout(timeoutMs), handler)
+}
+
+// UploadFiles는 SFTP를 통해 파일들을 최종 호스트에 업로드합니다.
+func (u *SSHUtils) UploadFiles(ctx context.Context, hops []ssh.HopConfig, files []ssh.FileUpload, timeoutMs int) ([]ssh.TransferResult, error) {
+	return u.ssh.UploadFiles(ctx, hops, files, toTimeout(timeoutMs))
+}
+
+// WriteFile은 SFTP를 통해 data를 remotePath에 파일로 저장합니다.
+func (u *SSHUtils) WriteFile(ctx context.Context, hops []ssh.HopConfig, remotePath string, data []byte, mode os.FileMode, timeoutMs int) (ssh.TransferResult, error) {
+	return u.ssh.Upload(ctx, hops, remotePath, bytes.NewReader(data), mode, toTimeout(timeoutMs))
+}
+
+// ReadFile은 SFTP를 통해 원격 파일의 내용을 읽습니다.
+func (u *SSHUtils) ReadFile(ctx context.Context, hops []ssh.HopConfig, remotePath string, timeoutMs int) ([]byte, error) {
+	return u.ssh.ReadFile(ctx, hops, remotePath, toTimeout(timeoutMs))
+}
+
+// UploadDir는 SFTP를 통해 로컬 디렉토리를 원격 디렉토리로 업로드합니다.
+func (u *SSHUtils) UploadDir(ctx context.Context, hops []ssh.HopConfig, localDir, remoteDir string, timeoutMs int) ([]ssh.TransferResult, error) {
+	return u.ssh.UploadDir(ctx, hops, localDir, remoteDir, toTimeout(timeoutMs))
+}
+
+// DownloadDir는 SFTP를 통해 원격 디렉토리를 로컬 디렉토리로 내려받습니다.
+func (u *SSHUtils) DownloadDir(ctx context.Context, hops []ssh.HopConfig, remoteDir, localDir string, timeoutMs int) ([]ssh.TransferResult, error) {
+	return u.ssh.DownloadDir(ctx, hops, remoteDir, localDir, toTimeout(timeoutMs))
+}
+
+// toTimeout은 밀리초 타임아웃을 time.Duration으로 변환합니다. 0이면 ssh.DefaultTimeout을 사용합니다.
+func toTimeout(timeoutMs int) time.Duration {
+	if timeoutMs == 0 {
+		return ssh.DefaultTimeout()
+	}
+	return time.Duration(timeoutMs) * time.Millisecond
 }
 
 // ExecuteCommandsOnServer는 단일 서버에 SSH 명령어를 실행하는 간편 메서드입니다.
@@ -44,14 +84,10 @@ func (u *SSHUtils) ExecuteCommandsOnServer(host string, port int, username, pass
 
 // IsSSHError는 에러가 SSH 에러인지 확인합니다.
 func (u *SSHUtils) IsSSHError(err error) bool {
-	_, ok := err.(ssh.SSHError)
-	return ok
+	return ssh.TypeOf(err) != ""
 }
 
 // GetSSHErrorType은 SSH 에러의 타입을 반환합니다.
 func (u *SSHUtils) GetSSHErrorType(err error) string {
-	if sshErr, ok := err.(ssh.SSHError); ok {
-		return string(sshErr.Type)
-	}
-	return ""
+	return string(ssh.TypeOf(err))
 }
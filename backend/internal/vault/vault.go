@@ -0,0 +1,250 @@
+// Package vault는 자격 증명을 봉투 암호화(envelope encryption)로 보호합니다
+//
+// 자격 증명마다 무작위 데이터 키를 만들어 비밀값을 AES-256-GCM으로 암호화하고,
+// 데이터 키는 마스터 키로 다시 암호화해 함께 저장합니다. 마스터 키를 교체할 때는
+// 비밀값은 그대로 두고 데이터 키만 새 마스터 키로 다시 암호화(Rewrap)하면 됩니다
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// EnvMasterKey는 마스터 키를 직접 지정하는 환경 변수입니다 (base64, 쉼표로 구분한 여러 키)
+	EnvMasterKey = "CREDENTIAL_MASTER_KEY"
+	// EnvMasterKeyFile은 마스터 키 파일 경로를 지정하는 환경 변수입니다 (한 줄에 키 하나)
+	EnvMasterKeyFile = "CREDENTIAL_MASTER_KEY_FILE"
+
+	// KeySize는 마스터 키와 데이터 키의 길이입니다 (AES-256)
+	KeySize = 32
+)
+
+// ErrNoMasterKey는 마스터 키가 설정되지 않았을 때 반환하는 오류입니다
+var ErrNoMasterKey = errors.New("자격 증명 마스터 키가 설정되지 않았습니다 (" + EnvMasterKey + " 또는 " + EnvMasterKeyFile + ")")
+
+// ErrUnknownKey는 데이터 키를 암호화한 마스터 키가 키 목록에 없을 때 반환하는 오류입니다
+var ErrUnknownKey = errors.New("데이터 키를 암호화한 마스터 키를 찾을 수 없습니다")
+
+// Keyring은 마스터 키 목록입니다
+// 첫 번째 키로 새 데이터 키를 암호화하고, 나머지 키는 교체 전에 암호화된 데이터 키를 복호화할 때만 사용합니다
+type Keyring struct {
+	keys []masterKey
+}
+
+type masterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewKeyring은 마스터 키 목록으로 Keyring을 생성합니다. 첫 번째 키가 현재 키입니다
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoMasterKey
+	}
+	k := &Keyring{}
+	for i, key := range keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("%d번째 마스터 키의 길이가 %d바이트가 아닙니다", i+1, KeySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys = append(k.keys, masterKey{id: KeyID(key), aead: aead})
+	}
+	return k, nil
+}
+
+// KeyID는 마스터 키를 식별하는 ID입니다. 키 자체를 드러내지 않도록 SHA-256 해시의 앞부분을 사용합니다
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParseKeys는 쉼표나 줄 바꿈으로 구분한 base64 마스터 키 목록을 해석합니다. #으로 시작하는 줄은 무시합니다
+func ParseKeys(s string) ([][]byte, error) {
+	var keys [][]byte
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("마스터 키가 base64 형식이 아닙니다: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// LoadKeyring은 설정의 마스터 키로 Keyring을 생성합니다
+// keyFile이 있으면 파일(한 줄에 키 하나)을, 없으면 key(쉼표로 구분한 여러 키)를 사용하며 둘 다 없으면 ErrNoMasterKey를 반환합니다
+func LoadKeyring(key, keyFile string) (*Keyring, error) {
+	source := key
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("마스터 키 파일을 읽을 수 없습니다: %w", err)
+		}
+		source = string(data)
+	}
+
+	keys, err := ParseKeys(source)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(keys...)
+}
+
+// GenerateKey는 새 마스터 키를 base64 문자열로 생성합니다
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// PrimaryID는 새 데이터 키를 암호화하는 현재 마스터 키의 ID입니다
+func (k *Keyring) PrimaryID() string {
+	return k.keys[0].id
+}
+
+// Envelope은 암호화된 비밀값과, 마스터 키로 암호화된 데이터 키입니다
+type Envelope struct {
+	KeyID      string // 데이터 키를 암호화한 마스터 키 ID
+	WrappedKey []byte // 암호화된 데이터 키 (nonce 포함)
+	Ciphertext []byte // 데이터 키로 암호화된 비밀값 (nonce 포함)
+}
+
+// Seal은 새 데이터 키로 plaintext를 암호화하고 데이터 키를 현재 마스터 키로 암호화합니다
+func (k *Keyring) Seal(plaintext []byte) (Envelope, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return Envelope{}, fmt.Errorf("데이터 키 생성 실패: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+	ciphertext, err := seal(dataAEAD, plaintext, nil)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	primary := k.keys[0]
+	wrapped, err := seal(primary.aead, dataKey, []byte(primary.id))
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{KeyID: primary.id, WrappedKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Open은 봉투의 데이터 키를 복호화한 뒤 비밀값을 복호화합니다
+func (k *Keyring) Open(e Envelope) ([]byte, error) {
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dataAEAD, e.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("자격 증명 복호화 실패: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap은 현재 마스터 키가 아닌 키로 암호화된 데이터 키를 현재 마스터 키로 다시 암호화합니다
+// 비밀값의 암호문은 바뀌지 않으며, 이미 현재 키를 사용하고 있으면 false를 반환합니다
+func (k *Keyring) Rewrap(e Envelope) (Envelope, bool, error) {
+	if e.KeyID == k.PrimaryID() {
+		return e, false, nil
+	}
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return e, false, err
+	}
+	primary := k.keys[0]
+	wrapped, err := seal(primary.aead, dataKey, []byte(primary.id))
+	if err != nil {
+		return e, false, err
+	}
+	return Envelope{KeyID: primary.id, WrappedKey: wrapped, Ciphertext: e.Ciphertext}, true, nil
+}
+
+// unwrap은 봉투의 마스터 키 ID에 맞는 키로 데이터 키를 복호화합니다
+func (k *Keyring) unwrap(e Envelope) ([]byte, error) {
+	for _, key := range k.keys {
+		if key.id != e.KeyID {
+			continue
+		}
+		dataKey, err := open(key.aead, e.WrappedKey, []byte(key.id))
+		if err != nil {
+			return nil, fmt.Errorf("데이터 키 복호화 실패: %w", err)
+		}
+		return dataKey, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKey, e.KeyID)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal은 무작위 nonce로 암호화하고 nonce를 암호문 앞에 붙입니다
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, data, additionalData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("암호문이 너무 짧습니다")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultKeyring *Keyring
+)
+
+// SetDefault는 애플리케이션 전체에서 사용할 Keyring을 설정합니다. 서버 시작 시 호출합니다
+func SetDefault(k *Keyring) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKeyring = k
+}
+
+// Default는 설정된 Keyring을 반환합니다. 설정되지 않았으면 ErrNoMasterKey를 반환합니다
+func Default() (*Keyring, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultKeyring == nil {
+		return nil, ErrNoMasterKey
+	}
+	return defaultKeyring, nil
+}
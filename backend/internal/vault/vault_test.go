@@ -0,0 +1,139 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestSealOpen(t *testing.T) {
+	k, err := NewKeyring(newKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte(`{"password":"s3cret"}`)
+
+	e, err := k.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.KeyID != k.PrimaryID() {
+		t.Errorf("KeyID = %s, want %s", e.KeyID, k.PrimaryID())
+	}
+	if bytes.Contains(e.Ciphertext, plaintext) {
+		t.Error("암호문에 평문이 포함되어 있습니다")
+	}
+
+	got, err := k.Open(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Open = %q, want %q", got, plaintext)
+	}
+
+	// 암호문이 변조되면 복호화에 실패해야 함
+	tampered := e
+	tampered.Ciphertext = append([]byte(nil), e.Ciphertext...)
+	tampered.Ciphertext[len(tampered.Ciphertext)-1] ^= 1
+	if _, err := k.Open(tampered); err == nil {
+		t.Error("변조된 암호문이 복호화되었습니다")
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	oldKey, newKeyBytes := newKey(t), newKey(t)
+	oldRing, _ := NewKeyring(oldKey)
+	e, err := oldRing.Seal([]byte("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 새 키를 맨 앞에 추가한 키 목록으로 교체
+	ring, err := NewKeyring(newKeyBytes, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrapped, changed, err := ring.Rewrap(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || rewrapped.KeyID != KeyID(newKeyBytes) {
+		t.Fatalf("Rewrap changed=%v KeyID=%s, want new key", changed, rewrapped.KeyID)
+	}
+	if !bytes.Equal(rewrapped.Ciphertext, e.Ciphertext) {
+		t.Error("Rewrap이 비밀값 암호문을 바꿨습니다")
+	}
+	if _, changed, _ := ring.Rewrap(rewrapped); changed {
+		t.Error("이미 현재 키를 사용하는 봉투를 다시 암호화했습니다")
+	}
+
+	// 이전 키를 제거한 뒤에도 다시 암호화한 봉투는 열 수 있어야 함
+	newRing, _ := NewKeyring(newKeyBytes)
+	if got, err := newRing.Open(rewrapped); err != nil || string(got) != "token" {
+		t.Errorf("Open = %q, %v", got, err)
+	}
+	if _, err := newRing.Open(e); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("이전 키로 암호화된 봉투: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	a, b := newKey(t), newKey(t)
+	input := "# 현재 키\n" + base64.StdEncoding.EncodeToString(a) + "\n\n" + base64.StdEncoding.EncodeToString(b) + "\n"
+	keys, err := ParseKeys(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || !bytes.Equal(keys[0], a) || !bytes.Equal(keys[1], b) {
+		t.Fatalf("ParseKeys = %d keys", len(keys))
+	}
+
+	if _, err := ParseKeys("not-base64!"); err == nil {
+		t.Error("잘못된 base64에 오류가 없습니다")
+	}
+	if _, err := NewKeyring([]byte("short")); err == nil {
+		t.Error("짧은 키에 오류가 없습니다")
+	}
+	if _, err := NewKeyring(); !errors.Is(err, ErrNoMasterKey) {
+		t.Errorf("빈 키 목록: err = %v, want ErrNoMasterKey", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	key := newKey(t)
+	k, err := LoadKeyring(base64.StdEncoding.EncodeToString(key), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.PrimaryID() != KeyID(key) {
+		t.Errorf("PrimaryID = %s, want %s", k.PrimaryID(), KeyID(key))
+	}
+
+	// 키 파일이 있으면 파일의 키를 사용
+	other := newKey(t)
+	path := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(other)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if k, err := LoadKeyring("", path); err != nil || k.PrimaryID() != KeyID(other) {
+		t.Errorf("키 파일: err = %v", err)
+	}
+
+	if _, err := LoadKeyring("", ""); !errors.Is(err, ErrNoMasterKey) {
+		t.Errorf("키 없음: err = %v, want ErrNoMasterKey", err)
+	}
+}
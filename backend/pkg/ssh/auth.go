@@ -0,0 +1,192 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// AgentSocketFromEnv를 HopConfig.AgentSocket에 지정하면 SSH_AUTH_SOCK 환경 변수의 소켓을 사용합니다
+const AgentSocketFromEnv = "env"
+
+// agentSocket은 AgentSocket이 비어 있는 홉에 사용할 서버 설정의 ssh-agent 소켓 경로입니다
+var agentSocket atomic.Value
+
+// SetAgentSocket은 모든 홉의 인증에 사용할 ssh-agent 소켓 경로를 설정합니다 (비어 있으면 사용하지 않음)
+func SetAgentSocket(socket string) {
+	agentSocket.Store(socket)
+}
+
+// hopAgentSocket은 홉에 사용할 ssh-agent 소켓 경로를 반환합니다
+func hopAgentSocket(hop HopConfig) string {
+	if hop.AgentSocket != "" {
+		return hop.AgentSocket
+	}
+	socket, _ := agentSocket.Load().(string)
+	return socket
+}
+
+// hopAuth는 하나의 홉에 대한 인증 수단과 핸드셰이크 후 정리할 자원을 보관합니다
+type hopAuth struct {
+	methods   []ssh.AuthMethod
+	agentConn net.Conn
+}
+
+// Close는 인증 과정에서 사용한 ssh-agent 연결을 닫습니다
+func (a *hopAuth) Close() {
+	if a != nil && a.agentConn != nil {
+		a.agentConn.Close()
+	}
+}
+
+// newHopAuth는 HopConfig에 설정된 인증 정보로 인증 수단 목록을 생성합니다.
+//
+// 인증 시도 순서는 다음과 같습니다:
+//  1. ssh-agent에 등록된 키 (AgentSocket 또는 SetAgentSocket)
+//  2. OpenSSH 인증서가 결합된 개인키 (PrivateKey + Certificate)
+//  3. 개인키 (PrivateKey, Passphrase)
+//  4. 비밀번호 (Password)
+//  5. keyboard-interactive 방식의 비밀번호 입력 (Password)
+//
+// golang.org/x/crypto/ssh는 같은 이름의 인증 방식을 한 번만 시도하므로
+// 1~3번의 공개키 서명자는 하나의 publickey 인증 수단으로 묶어서 전달합니다.
+func newHopAuth(hop HopConfig) (*hopAuth, error) {
+	auth := &hopAuth{}
+
+	var signers []ssh.Signer
+	var agentErr error
+
+	// 1. ssh-agent
+	if socket := hopAgentSocket(hop); socket != "" {
+		agentSigners, conn, err := agentSigners(socket)
+		if err != nil {
+			// 다음 인증 수단으로 넘어가되, 사용할 수단이 없으면 이 오류를 보고합니다
+			agentErr = err
+		} else {
+			auth.agentConn = conn
+			signers = append(signers, agentSigners...)
+		}
+	}
+
+	// 2, 3. 개인키 및 인증서
+	if hop.PrivateKey != "" {
+		keySigners, err := privateKeySigners(hop)
+		if err != nil {
+			auth.Close()
+			return nil, SSHError{
+				Type:    ValidationError,
+				Message: fmt.Sprintf("Invalid private key for %s: %s", hop.Host, err.Error()),
+				Host:    hop.Host,
+				Err:     err,
+			}
+		}
+		signers = append(signers, keySigners...)
+	} else if hop.Certificate != "" {
+		auth.Close()
+		return nil, SSHError{
+			Type:    ValidationError,
+			Message: fmt.Sprintf("Certificate for %s requires a private key", hop.Host),
+			Host:    hop.Host,
+		}
+	}
+
+	if len(signers) > 0 {
+		auth.methods = append(auth.methods, ssh.PublicKeys(signers...))
+	}
+
+	// 4, 5. 비밀번호
+	if hop.Password != "" {
+		password := hop.Password
+		auth.methods = append(auth.methods,
+			ssh.Password(password),
+			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range questions {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+
+	if len(auth.methods) == 0 {
+		message := fmt.Sprintf("No authentication method configured for %s", hop.Host)
+		if agentErr != nil {
+			message = fmt.Sprintf("%s: %s", message, agentErr.Error())
+		}
+		return nil, SSHError{
+			Type:    ValidationError,
+			Message: message,
+			Host:    hop.Host,
+		}
+	}
+
+	return auth, nil
+}
+
+// agentSigners는 ssh-agent 소켓에 연결하여 등록된 서명자 목록을 가져옵니다.
+// 서명은 인증 중에 agent를 통해 이루어지므로 반환된 연결은 핸드셰이크가 끝날 때까지 열려 있어야 합니다.
+func agentSigners(socket string) ([]ssh.Signer, net.Conn, error) {
+	if socket == AgentSocketFromEnv {
+		socket = os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, errors.New("SSH_AUTH_SOCK is not set")
+		}
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to list ssh-agent keys: %w", err)
+	}
+
+	return signers, conn, nil
+}
+
+// privateKeySigners는 PEM 개인키(필요 시 암호 해제)와 선택적인 OpenSSH 인증서로 서명자를 생성합니다.
+// 인증서가 있으면 인증서 서명자를 먼저, 일반 키 서명자를 그 다음에 둡니다.
+func privateKeySigners(hop HopConfig) ([]ssh.Signer, error) {
+	var signer ssh.Signer
+	var err error
+	if hop.Passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(hop.PrivateKey), []byte(hop.Passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(hop.PrivateKey))
+	}
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, errors.New("private key is passphrase protected")
+		}
+		return nil, err
+	}
+
+	if hop.Certificate == "" {
+		return []ssh.Signer{signer}, nil
+	}
+
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hop.Certificate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, errors.New("certificate is not an OpenSSH certificate")
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ssh.Signer{certSigner, signer}, nil
+}
@@ -0,0 +1,105 @@
+package ssh
+
+import (
+	"fmt"
+	"sync"
+)
+
+// HopCredential은 자격 증명 저장소에서 가져온 hop 인증 정보입니다
+type HopCredential struct {
+	Username     string
+	Password     string
+	PrivateKey   string
+	Passphrase   string
+	SudoPassword string
+}
+
+// CredentialResolver는 HopConfig.CredentialID로 인증 정보를 조회합니다
+type CredentialResolver interface {
+	ResolveHopCredential(id int) (HopCredential, error)
+}
+
+var (
+	credentialResolverMu sync.RWMutex
+	credentialResolver   CredentialResolver
+)
+
+// SetCredentialResolver는 CredentialID가 지정된 hop의 인증 정보를 조회할 저장소를 설정합니다
+// 서버 시작 시 DB 기반 자격 증명 저장소로 설정하며, 설정하지 않으면 CredentialID를 사용할 수 없습니다
+func SetCredentialResolver(r CredentialResolver) {
+	credentialResolverMu.Lock()
+	defer credentialResolverMu.Unlock()
+	credentialResolver = r
+}
+
+func getCredentialResolver() CredentialResolver {
+	credentialResolverMu.RLock()
+	defer credentialResolverMu.RUnlock()
+	return credentialResolver
+}
+
+// ResolveHops는 CredentialID가 지정된 hop의 인증 정보를 저장소에서 채운 복사본을 반환합니다
+// hop에 직접 지정한 값이 우선하며, 인증 정보를 채운 hop의 CredentialID는 0이 됩니다
+// 저장된 인증 정보가 요청자가 지정한 호스트로 전송되지 않도록, DB에 저장된 서버의 hops에만 호출해야 합니다
+// 연결, 명령어 실행 함수는 인증 정보를 채우지 않은 hop을 거부합니다
+func ResolveHops(hops []HopConfig) ([]HopConfig, error) {
+	var resolved []HopConfig
+	for i, hop := range hops {
+		if hop.CredentialID == 0 {
+			continue
+		}
+		if resolved == nil {
+			resolved = append([]HopConfig(nil), hops...)
+		}
+
+		resolver := getCredentialResolver()
+		if resolver == nil {
+			return nil, SSHError{
+				Type:    ValidationError,
+				Message: fmt.Sprintf("Credential %d is referenced but no credential store is configured", hop.CredentialID),
+				Host:    hop.Host,
+			}
+		}
+		cred, err := resolver.ResolveHopCredential(hop.CredentialID)
+		if err != nil {
+			return nil, SSHError{
+				Type:    ValidationError,
+				Message: fmt.Sprintf("Failed to load credential %d: %v", hop.CredentialID, err),
+				Host:    hop.Host,
+				Err:     err,
+			}
+		}
+
+		fill(&resolved[i].Username, cred.Username)
+		fill(&resolved[i].Password, cred.Password)
+		fill(&resolved[i].PrivateKey, cred.PrivateKey)
+		fill(&resolved[i].Passphrase, cred.Passphrase)
+		fill(&resolved[i].SudoPassword, cred.SudoPassword)
+		resolved[i].CredentialID = 0
+	}
+	if resolved == nil {
+		return hops, nil
+	}
+	return resolved, nil
+}
+
+// checkResolved는 ResolveHops로 인증 정보를 채우지 않은 hop이 있으면 오류를 반환합니다
+func checkResolved(hops []HopConfig) error {
+	for _, hop := range hops {
+		if hop.CredentialID != 0 {
+			return SSHError{
+				Type:    ValidationError,
+				Message: fmt.Sprintf("Credential %d for %s must be resolved from a stored server before connecting", hop.CredentialID, hop.Host),
+				Host:    hop.Host,
+			}
+		}
+	}
+	return nil
+}
+
+// fill은 비어 있는 필드만 value로 채웁니다
+func fill(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
@@ -0,0 +1,63 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeResolver map[int]HopCredential
+
+func (f fakeResolver) ResolveHopCredential(id int) (HopCredential, error) {
+	cred, ok := f[id]
+	if !ok {
+		return HopCredential{}, errors.New("not found")
+	}
+	return cred, nil
+}
+
+func TestResolveHops(t *testing.T) {
+	SetCredentialResolver(fakeResolver{
+		7: {Username: "stored", Password: "pw", SudoPassword: "sudo-pw"},
+	})
+	defer SetCredentialResolver(nil)
+
+	hops := []HopConfig{
+		{Host: "bastion", Port: 22, Username: "jump", Password: "direct"},
+		{Host: "target", Port: 22, Username: "admin", CredentialID: 7},
+	}
+	resolved, err := ResolveHops(hops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := resolved[1]
+	// 직접 지정한 값이 우선하고 비어 있는 필드만 저장소 값으로 채움
+	if got.Username != "admin" || got.Password != "pw" || got.SudoPassword != "sudo-pw" || got.CredentialID != 0 {
+		t.Errorf("resolved hop = %+v", got)
+	}
+	if resolved[0] != hops[0] {
+		t.Errorf("credential_id가 없는 hop이 바뀌었습니다: %+v", resolved[0])
+	}
+	if hops[1].Password != "" || hops[1].CredentialID != 7 {
+		t.Errorf("원본 hops가 수정되었습니다: %+v", hops[1])
+	}
+
+	var sshErr SSHError
+	if _, err := ResolveHops([]HopConfig{{Host: "x", CredentialID: 8}}); !errors.As(err, &sshErr) || sshErr.Type != ValidationError {
+		t.Errorf("없는 자격 증명: err = %v, want ValidationError", err)
+	}
+}
+
+func TestConnectRejectsUnresolvedCredential(t *testing.T) {
+	SetCredentialResolver(fakeResolver{7: {Password: "pw"}})
+	defer SetCredentialResolver(nil)
+
+	// 연결 함수는 저장소의 인증 정보를 직접 채우지 않음 (요청에서 받은 hop으로 비밀값이 전송되지 않도록)
+	var sshErr SSHError
+	_, err := (&SSHService{}).ConnectContext(context.Background(), []HopConfig{{Host: "attacker", Port: 22, CredentialID: 7}}, time.Second)
+	if !errors.As(err, &sshErr) || sshErr.Type != ValidationError {
+		t.Errorf("err = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,203 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrorType은 SSH 연결 또는 명령 실행 중 발생할 수 있는 오류 유형을 정의합니다
+type ErrorType string
+
+const (
+	AuthenticationFailed   ErrorType = "AUTHENTICATION_FAILED"
+	ConnectionRefused      ErrorType = "CONNECTION_REFUSED"
+	ConnectionTimeout      ErrorType = "CONNECTION_TIMEOUT"
+	HostNotFound           ErrorType = "HOST_NOT_FOUND"
+	TunnelingFailed        ErrorType = "TUNNELING_FAILED"
+	HostKeyMismatch        ErrorType = "HOST_KEY_MISMATCH"
+	CommandExecutionFailed ErrorType = "COMMAND_EXECUTION_FAILED"
+	RemoteCommandFailed    ErrorType = "REMOTE_COMMAND_FAILED"
+	ValidationError        ErrorType = "VALIDATION_ERROR"
+	CommandCanceled        ErrorType = "COMMAND_CANCELED"
+	TransferFailed         ErrorType = "TRANSFER_FAILED"
+	UnknownError           ErrorType = "UNKNOWN_ERROR"
+)
+
+// 오류 유형별 센티널 오류입니다
+// SSHError는 자신의 유형에 해당하는 센티널을 감싸므로 errors.Is(err, ErrAuthenticationFailed)처럼 유형을 확인할 수 있습니다
+var (
+	ErrAuthenticationFailed   = errors.New("ssh: authentication failed")
+	ErrConnectionRefused      = errors.New("ssh: connection refused")
+	ErrConnectionTimeout      = errors.New("ssh: connection timeout")
+	ErrHostNotFound           = errors.New("ssh: host not found")
+	ErrTunnelingFailed        = errors.New("ssh: tunneling failed")
+	ErrHostKeyMismatch        = errors.New("ssh: host key mismatch")
+	ErrCommandExecutionFailed = errors.New("ssh: command execution failed")
+	ErrRemoteCommandFailed    = errors.New("ssh: remote command exited with non-zero status")
+	ErrValidation             = errors.New("ssh: invalid configuration")
+	ErrCommandCanceled        = errors.New("ssh: command canceled")
+	ErrTransferFailed         = errors.New("ssh: file transfer failed")
+	ErrUnknown                = errors.New("ssh: unknown error")
+)
+
+var sentinels = map[ErrorType]error{
+	AuthenticationFailed:   ErrAuthenticationFailed,
+	ConnectionRefused:      ErrConnectionRefused,
+	ConnectionTimeout:      ErrConnectionTimeout,
+	HostNotFound:           ErrHostNotFound,
+	TunnelingFailed:        ErrTunnelingFailed,
+	HostKeyMismatch:        ErrHostKeyMismatch,
+	CommandExecutionFailed: ErrCommandExecutionFailed,
+	RemoteCommandFailed:    ErrRemoteCommandFailed,
+	ValidationError:        ErrValidation,
+	CommandCanceled:        ErrCommandCanceled,
+	TransferFailed:         ErrTransferFailed,
+	UnknownError:           ErrUnknown,
+}
+
+// Sentinel은 오류 유형에 해당하는 센티널 오류를 반환합니다. 알 수 없는 유형은 ErrUnknown입니다
+func (t ErrorType) Sentinel() error {
+	if err, ok := sentinels[t]; ok {
+		return err
+	}
+	return ErrUnknown
+}
+
+// SSHError는 SSH 연결 또는 명령 실행 중 발생하는 오류를 나타냅니다
+// Err에는 원인 오류가 담기며, errors.Is/As로 센티널 오류와 원인 오류를 모두 확인할 수 있습니다
+type SSHError struct {
+	Type     ErrorType `json:"type"`
+	Message  string    `json:"message"`
+	Host     string    `json:"host,omitempty"`
+	Command  string    `json:"command,omitempty"`
+	ExitCode int       `json:"exit_code,omitempty"` // RemoteCommandFailed인 경우 원격 명령어의 종료 코드
+	Err      error     `json:"-"`
+}
+
+// Error는 error 인터페이스를 구현합니다
+func (e SSHError) Error() string {
+	return e.Message
+}
+
+// Unwrap은 오류 유형의 센티널 오류와 원인 오류를 반환합니다
+func (e SSHError) Unwrap() []error {
+	errs := []error{e.Type.Sentinel()}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// TypeOf는 err에 포함된 SSHError의 오류 유형을 반환합니다. SSHError가 아니면 빈 문자열을 반환합니다
+func TypeOf(err error) ErrorType {
+	var sshErr SSHError
+	if errors.As(err, &sshErr) {
+		return sshErr.Type
+	}
+	return ""
+}
+
+// ExitError는 원격 명령어가 0이 아닌 종료 코드로 끝난 결과를 RemoteCommandFailed 오류로 변환합니다
+func ExitError(host string, result CommandResult) SSHError {
+	message := fmt.Sprintf("Command exited with status %d", result.ExitCode)
+	if stderr := strings.TrimSpace(result.Error); stderr != "" {
+		message += ": " + stderr
+	}
+	return SSHError{
+		Type:     RemoteCommandFailed,
+		Message:  message,
+		Host:     host,
+		Command:  result.Command,
+		ExitCode: result.ExitCode,
+	}
+}
+
+// CheckResults는 종료 코드가 0이 아닌 첫 번째 결과를 RemoteCommandFailed 오류로 반환합니다
+// 모든 명령어가 성공했으면 nil을 반환합니다
+func CheckResults(host string, results []CommandResult) error {
+	for _, result := range results {
+		if result.ExitCode != 0 {
+			return ExitError(host, result)
+		}
+	}
+	return nil
+}
+
+// canceledError는 ctx가 끝난 이유에 따라 취소 또는 타임아웃 오류를 생성합니다
+func canceledError(ctx context.Context, host, cmd string) SSHError {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return SSHError{
+			Type:    ConnectionTimeout,
+			Message: "Operation timed out before completion",
+			Host:    host,
+			Command: cmd,
+			Err:     ctx.Err(),
+		}
+	}
+	return SSHError{
+		Type:    CommandCanceled,
+		Message: "Operation was canceled",
+		Host:    host,
+		Command: cmd,
+		Err:     ctx.Err(),
+	}
+}
+
+// mapSSHError는 연결 및 핸드셰이크 오류를 SSHError 타입으로 변환합니다
+// 오류 메시지 대신 오류 타입과 errno로 분류하며, 원래 오류는 Err에 보존합니다
+func mapSSHError(err error, host string) SSHError {
+	sshErr := SSHError{Host: host, Err: err}
+
+	var hostKeyErr *HostKeyError
+	var dnsErr *net.DNSError
+	var exitErr *ssh.ExitError
+	switch {
+	case errors.As(err, &hostKeyErr):
+		// 호스트 키 불일치 (고정된 키와 다른 키를 제시한 경우)
+		sshErr.Type = HostKeyMismatch
+		sshErr.Message = fmt.Sprintf("Host key mismatch for %s: expected %s, got %s", host, hostKeyErr.Expected, hostKeyErr.Presented)
+	case errors.As(err, &dnsErr) && !dnsErr.IsTimeout:
+		sshErr.Type = HostNotFound
+		sshErr.Message = fmt.Sprintf("Host not found: %s", host)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		sshErr.Type = ConnectionRefused
+		sshErr.Message = fmt.Sprintf("Connection refused to %s", host)
+	case isTimeout(err):
+		sshErr.Type = ConnectionTimeout
+		sshErr.Message = fmt.Sprintf("Connection timeout while connecting to %s", host)
+	case isAuthError(err):
+		sshErr.Type = AuthenticationFailed
+		sshErr.Message = fmt.Sprintf("Authentication failed for %s", host)
+	case errors.As(err, &exitErr):
+		sshErr.Type = RemoteCommandFailed
+		sshErr.Message = fmt.Sprintf("Command exited with status %d on %s", exitErr.ExitStatus(), host)
+		sshErr.ExitCode = exitErr.ExitStatus()
+	default:
+		sshErr.Type = UnknownError
+		sshErr.Message = fmt.Sprintf("Unknown error while connecting to %s: %s", host, err.Error())
+	}
+	return sshErr
+}
+
+// isTimeout은 err가 네트워크 타임아웃인지 확인합니다
+func isTimeout(err error) bool {
+	if errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isAuthError는 err가 SSH 클라이언트 인증 실패인지 확인합니다
+// x/crypto/ssh는 인증 실패에 대한 오류 타입을 제공하지 않으므로 라이브러리가 사용하는 고정 문구로 판단합니다
+func isAuthError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "ssh: unable to authenticate") || strings.Contains(msg, "no supported methods remain")
+}
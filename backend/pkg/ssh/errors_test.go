@@ -0,0 +1,90 @@
+package ssh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestMapSSHError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		want     ErrorType
+		sentinel error
+	}{
+		{"DNS 실패", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x.invalid", IsNotFound: true}}, HostNotFound, ErrHostNotFound},
+		{"DNS 타임아웃", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "i/o timeout", Name: "x", IsTimeout: true}}, ConnectionTimeout, ErrConnectionTimeout},
+		{"연결 거부", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, ConnectionRefused, ErrConnectionRefused},
+		{"타임아웃", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, ConnectionTimeout, ErrConnectionTimeout},
+		{"인증 실패", errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none password], no supported methods remain"), AuthenticationFailed, ErrAuthenticationFailed},
+		{"호스트 키 불일치", fmt.Errorf("ssh: handshake failed: %w", &HostKeyError{Host: "h", Port: 22, Expected: "a", Presented: "b"}), HostKeyMismatch, ErrHostKeyMismatch},
+		// 메시지에 "auth"가 포함되어도 인증 오류로 분류하지 않습니다
+		{"기타 오류", errors.New("ssh: handshake failed: author unknown"), UnknownError, ErrUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapSSHError(tt.err, "host-1")
+			if got.Type != tt.want {
+				t.Errorf("Type = %s, want %s", got.Type, tt.want)
+			}
+			if !errors.Is(got, tt.sentinel) {
+				t.Errorf("errors.Is(%v) = false", tt.sentinel)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Error("원인 오류가 보존되지 않음")
+			}
+			if got.Host != "host-1" {
+				t.Errorf("Host = %q", got.Host)
+			}
+		})
+	}
+}
+
+func TestSSHErrorUnwrap(t *testing.T) {
+	err := fmt.Errorf("작업 실패: %w", SSHError{Type: TunnelingFailed, Message: "tunnel", Err: syscall.ECONNRESET})
+
+	if !errors.Is(err, ErrTunnelingFailed) || errors.Is(err, ErrConnectionRefused) {
+		t.Error("센티널 오류가 유형과 일치하지 않음")
+	}
+	if !errors.Is(err, syscall.ECONNRESET) {
+		t.Error("원인 오류를 찾을 수 없음")
+	}
+	if got := TypeOf(err); got != TunnelingFailed {
+		t.Errorf("TypeOf = %s", got)
+	}
+	if got := TypeOf(errors.New("plain")); got != "" {
+		t.Errorf("SSHError가 아닌 오류의 TypeOf = %s", got)
+	}
+
+	// 원인 오류는 JSON 응답에 포함하지 않습니다
+	data, _ := json.Marshal(SSHError{Type: UnknownError, Message: "m", Err: errors.New("secret cause")})
+	if strings.Contains(string(data), "secret cause") {
+		t.Errorf("JSON에 원인 오류가 포함됨: %s", data)
+	}
+}
+
+func TestCheckResults(t *testing.T) {
+	if err := CheckResults("h", []CommandResult{{Command: "true"}}); err != nil {
+		t.Fatalf("성공한 결과에서 오류 발생: %v", err)
+	}
+
+	err := CheckResults("h", []CommandResult{
+		{Command: "true"},
+		{Command: "false", Error: "boom\n", ExitCode: 3},
+		{Command: "exit 4", ExitCode: 4},
+	})
+	var sshErr SSHError
+	if !errors.As(err, &sshErr) || !errors.Is(err, ErrRemoteCommandFailed) {
+		t.Fatalf("RemoteCommandFailed 오류가 아님: %v", err)
+	}
+	if sshErr.ExitCode != 3 || sshErr.Command != "false" || sshErr.Message != "Command exited with status 3: boom" {
+		t.Errorf("오류 = %+v", sshErr)
+	}
+}
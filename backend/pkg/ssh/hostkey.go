@@ -0,0 +1,206 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKey는 SSH 호스트에 고정(pinning)된 공개키 정보입니다
+// 같은 사설 IP라도 거쳐 가는 점프 호스트가 다르면 다른 호스트이므로 키는 Via, Host, Port 단위로 고정합니다
+type HostKey struct {
+	Via         string `json:"via"` // 이 호스트에 도달하기 위해 거친 홉 경로 (HopRoute, 직접 연결이면 빈 값)
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	KeyType     string `json:"key_type"`
+	Fingerprint string `json:"fingerprint"` // SHA256 지문 (예: SHA256:abc...)
+	PublicKey   string `json:"public_key"`  // authorized_keys 형식 공개키
+}
+
+// HopRoute는 hops를 차례로 거치는 경로를 "host:port>host:port" 형식으로 반환합니다
+// hops[i]의 호스트 키는 HopRoute(hops[:i]) 경로로 고정됩니다
+func HopRoute(hops []HopConfig) string {
+	parts := make([]string, len(hops))
+	for i, hop := range hops {
+		port := hop.Port
+		if port == 0 {
+			port = 22
+		}
+		parts[i] = fmt.Sprintf("%s:%d", hop.Host, port)
+	}
+	return strings.Join(parts, ">")
+}
+
+// NewHostKey는 via 경로로 접속한 호스트의 SSH 공개키로부터 HostKey를 생성합니다
+func NewHostKey(via, host string, port int, key ssh.PublicKey) HostKey {
+	return HostKey{
+		Via:         via,
+		Host:        host,
+		Port:        port,
+		KeyType:     key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(key),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+	}
+}
+
+// ParseHostKey는 authorized_keys 형식의 공개키 문자열로부터 HostKey를 생성합니다
+func ParseHostKey(via, host string, port int, authorizedKey string) (HostKey, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return HostKey{}, fmt.Errorf("failed to parse host key: %w", err)
+	}
+	return NewHostKey(via, host, port, key), nil
+}
+
+// HostKeyStore는 최초 접속 시 신뢰(TOFU) 방식으로 호스트 키를 보관하는 저장소입니다
+type HostKeyStore interface {
+	// LookupHostKey는 via 경로의 호스트에 고정된 키를 반환합니다. 등록된 키가 없으면 nil을 반환합니다
+	LookupHostKey(via, host string, port int) (*HostKey, error)
+	// PinHostKey는 처음 접속한 호스트의 키를 고정합니다
+	// 동시에 처음 접속한 다른 연결이 먼저 고정했으면 기존 키를 그대로 두고 오류 없이 반환합니다
+	PinHostKey(key HostKey) error
+	// RecordHostKeyMismatch는 고정된 키와 다른 키가 제시되었을 때 승인 대기 키로 기록합니다
+	RecordHostKeyMismatch(key HostKey) error
+}
+
+// HostKeyError는 호스트가 고정된 키와 다른 키를 제시했을 때 반환됩니다
+type HostKeyError struct {
+	Host      string
+	Port      int
+	Expected  string
+	Presented string
+}
+
+// Error는 error 인터페이스를 구현합니다
+func (e *HostKeyError) Error() string {
+	return fmt.Sprintf("host key mismatch for %s:%d: expected %s, got %s", e.Host, e.Port, e.Expected, e.Presented)
+}
+
+var (
+	hostKeyStoreMu      sync.RWMutex
+	defaultHostKeyStore HostKeyStore = NewMemoryHostKeyStore()
+)
+
+// SetHostKeyStore는 모든 SSH 연결이 사용할 호스트 키 저장소를 설정합니다
+// 서버 시작 시 DB 기반 저장소로 교체하며, 설정하지 않으면 프로세스 메모리에만 보관됩니다
+func SetHostKeyStore(store HostKeyStore) {
+	hostKeyStoreMu.Lock()
+	defer hostKeyStoreMu.Unlock()
+	defaultHostKeyStore = store
+}
+
+// getHostKeyStore는 현재 설정된 호스트 키 저장소를 반환합니다
+func getHostKeyStore() HostKeyStore {
+	hostKeyStoreMu.RLock()
+	defer hostKeyStoreMu.RUnlock()
+	return defaultHostKeyStore
+}
+
+// hostKeyCallback은 TOFU 방식으로 호스트 키를 검증하는 콜백을 생성합니다
+// 처음 보는 호스트는 키를 고정하고, 이후 키가 바뀌면 연결을 거부합니다
+func hostKeyCallback(store HostKeyStore, via, host string, port int) ssh.HostKeyCallback {
+	return func(_ string, _ net.Addr, key ssh.PublicKey) error {
+		presented := NewHostKey(via, host, port, key)
+
+		pinned, err := store.LookupHostKey(via, host, port)
+		if err != nil {
+			return fmt.Errorf("failed to look up host key for %s:%d: %w", host, port, err)
+		}
+
+		if pinned == nil {
+			if err := store.PinHostKey(presented); err != nil {
+				return fmt.Errorf("failed to pin host key for %s:%d: %w", host, port, err)
+			}
+			// 동시에 처음 접속한 연결이 다른 키를 먼저 고정했을 수 있으므로 다시 읽어 비교합니다
+			pinned, err = store.LookupHostKey(via, host, port)
+			if err != nil {
+				return fmt.Errorf("failed to look up host key for %s:%d: %w", host, port, err)
+			}
+			if pinned == nil {
+				return fmt.Errorf("host key for %s:%d was not pinned", host, port)
+			}
+		}
+
+		if pinned.Fingerprint == presented.Fingerprint {
+			return nil
+		}
+
+		// 관리자가 승인할 수 있도록 새 키를 기록하고 연결은 거부합니다
+		if err := store.RecordHostKeyMismatch(presented); err != nil {
+			log.Printf("failed to record host key mismatch for %s:%d: %v", host, port, err)
+		}
+		return &HostKeyError{
+			Host:      host,
+			Port:      port,
+			Expected:  pinned.Fingerprint,
+			Presented: presented.Fingerprint,
+		}
+	}
+}
+
+// MemoryHostKeyStore는 프로세스 메모리에 호스트 키를 보관하는 저장소입니다
+type MemoryHostKeyStore struct {
+	mu      sync.Mutex
+	pinned  map[string]HostKey
+	pending map[string]HostKey
+}
+
+// NewMemoryHostKeyStore는 새로운 MemoryHostKeyStore 인스턴스를 생성합니다
+func NewMemoryHostKeyStore() *MemoryHostKeyStore {
+	return &MemoryHostKeyStore{
+		pinned:  make(map[string]HostKey),
+		pending: make(map[string]HostKey),
+	}
+}
+
+// hostKeyID는 저장소 내부에서 사용하는 via>host:port 키를 생성합니다
+func hostKeyID(via, host string, port int) string {
+	return fmt.Sprintf("%s>%s:%d", via, host, port)
+}
+
+// LookupHostKey는 고정된 호스트 키를 반환합니다
+func (m *MemoryHostKeyStore) LookupHostKey(via, host string, port int) (*HostKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if key, ok := m.pinned[hostKeyID(via, host, port)]; ok {
+		return &key, nil
+	}
+	return nil, nil
+}
+
+// PinHostKey는 고정된 키가 없을 때만 호스트 키를 고정합니다
+func (m *MemoryHostKeyStore) PinHostKey(key HostKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := hostKeyID(key.Via, key.Host, key.Port)
+	if _, ok := m.pinned[id]; !ok {
+		m.pinned[id] = key
+	}
+	return nil
+}
+
+// RecordHostKeyMismatch는 승인 대기 키를 기록합니다
+func (m *MemoryHostKeyStore) RecordHostKeyMismatch(key HostKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[hostKeyID(key.Via, key.Host, key.Port)] = key
+	return nil
+}
+
+// ApproveHostKey는 승인 대기 중인 키를 고정 키로 교체합니다
+func (m *MemoryHostKeyStore) ApproveHostKey(via, host string, port int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := hostKeyID(via, host, port)
+	key, ok := m.pending[id]
+	if !ok {
+		return fmt.Errorf("no pending host key for %s", id)
+	}
+	m.pinned[id] = key
+	delete(m.pending, id)
+	return nil
+}
@@ -0,0 +1,334 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PoolConfig는 SSH 연결 풀의 동작을 정의합니다
+type PoolConfig struct {
+	IdleTimeout          time.Duration // 사용하지 않는 연결을 닫기까지의 시간
+	KeepAliveInterval    time.Duration // keepalive 요청 및 유휴 연결 정리 주기
+	MaxSessionsPerClient int           // 연결 하나를 동시에 빌려줄 수 있는 최대 수
+	HealthCheckTimeout   time.Duration // 유휴 연결 재사용 전 상태 확인 응답 대기 시간
+}
+
+// DefaultPoolConfig는 기본 풀 설정을 반환합니다
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		IdleTimeout:          5 * time.Minute,
+		KeepAliveInterval:    30 * time.Second,
+		MaxSessionsPerClient: 8, // OpenSSH 기본 MaxSessions(10)보다 작게 유지
+		HealthCheckTimeout:   5 * time.Second,
+	}
+}
+
+// pooledConn은 풀에 보관된 홉 체인 연결 하나입니다
+type pooledConn struct {
+	key      string
+	client   *Client
+	active   int
+	lastUsed time.Time
+	closed   bool
+}
+
+// Pool은 홉 체인별로 SSH 연결을 재사용하는 연결 풀입니다
+// 같은 홉 체인(호스트, 포트, 사용자, 인증 정보)에 대한 요청은 기존 연결을 공유합니다
+type Pool struct {
+	config PoolConfig
+	dial   func(ctx context.Context, hops []HopConfig, timeout time.Duration) (*Client, error)
+
+	mu    sync.Mutex
+	conns map[string][]*pooledConn
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var (
+	defaultPoolOnce sync.Once
+	defaultPool     *Pool
+)
+
+// ConfigureDefaultPool은 기본 연결 풀의 설정을 지정합니다
+// 기본 풀은 처음 사용할 때 생성되므로 SSHService를 만들기 전(서버 시작 시)에 호출해야 하며, 이후 호출은 무시됩니다
+func ConfigureDefaultPool(config PoolConfig) {
+	defaultPoolOnce.Do(func() {
+		defaultPool = NewPool(config)
+	})
+}
+
+// DefaultPool은 모든 SSHService가 공유하는 기본 연결 풀을 반환합니다
+func DefaultPool() *Pool {
+	defaultPoolOnce.Do(func() {
+		defaultPool = NewPool(DefaultPoolConfig())
+	})
+	return defaultPool
+}
+
+// NewPool은 새로운 연결 풀을 생성하고 keepalive 및 유휴 연결 정리를 시작합니다
+func NewPool(config PoolConfig) *Pool {
+	defaults := DefaultPoolConfig()
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaults.IdleTimeout
+	}
+	if config.KeepAliveInterval <= 0 {
+		config.KeepAliveInterval = defaults.KeepAliveInterval
+	}
+	if config.MaxSessionsPerClient <= 0 {
+		config.MaxSessionsPerClient = defaults.MaxSessionsPerClient
+	}
+	if config.HealthCheckTimeout <= 0 {
+		config.HealthCheckTimeout = defaults.HealthCheckTimeout
+	}
+
+	p := &Pool{
+		config: config,
+		dial:   (&SSHService{}).ConnectContext,
+		conns:  make(map[string][]*pooledConn),
+		done:   make(chan struct{}),
+	}
+	go p.maintain()
+	return p
+}
+
+// poolKey는 홉 체인을 식별하는 키를 생성합니다
+// 인증 정보가 다르면 다른 연결로 취급하며, 키에는 해시만 사용해 비밀 값을 보관하지 않습니다
+func poolKey(hops []HopConfig) string {
+	h := sha256.New()
+	for _, hop := range hops {
+		port := hop.Port
+		if port == 0 {
+			port = 22
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x01",
+			hop.Host, port, hop.Username, hop.Password,
+			hop.PrivateKey, hop.Passphrase, hop.Certificate, hop.AgentSocket)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Acquire는 홉 체인에 대한 연결을 풀에서 빌려옵니다
+// 반환된 Client의 Close는 연결을 닫지 않고 풀에 반납합니다
+// ctx는 새 연결을 만드는 동안에만 사용되며, 만들어진 연결은 ctx와 무관하게 풀에 남습니다
+func (p *Pool) Acquire(ctx context.Context, hops []HopConfig, timeout time.Duration) (*Client, error) {
+	if len(hops) == 0 {
+		return nil, SSHError{
+			Type:    ValidationError,
+			Message: "At least one hop configuration is required",
+		}
+	}
+
+	key := poolKey(hops)
+
+	for {
+		pc, wasIdle := p.reserve(key)
+		if pc == nil {
+			break
+		}
+
+		// 유휴 상태였던 연결은 재사용 전에 상태를 확인합니다
+		if wasIdle && !p.healthy(pc) {
+			p.release(pc, true)
+			continue
+		}
+
+		return p.lease(pc), nil
+	}
+
+	client, err := p.dial(ctx, hops, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := &pooledConn{key: key, client: client, active: 1, lastUsed: time.Now()}
+
+	p.mu.Lock()
+	select {
+	case <-p.done:
+		// 풀이 닫힌 뒤에는 연결을 보관하지 않고 바로 닫습니다
+		p.mu.Unlock()
+		client.Close()
+		return nil, SSHError{
+			Type:    ValidationError,
+			Message: "SSH connection pool is closed",
+		}
+	default:
+	}
+	p.conns[key] = append(p.conns[key], pc)
+	p.mu.Unlock()
+
+	return p.lease(pc), nil
+}
+
+// reserve는 사용 가능한 연결을 찾아 사용 중으로 표시합니다
+func (p *Pool) reserve(key string) (*pooledConn, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, pc := range p.conns[key] {
+		if pc.closed || pc.active >= p.config.MaxSessionsPerClient {
+			continue
+		}
+		wasIdle := pc.active == 0
+		pc.active++
+		pc.lastUsed = time.Now()
+		return pc, wasIdle
+	}
+	return nil, false
+}
+
+// lease는 풀 연결을 감싸 Close 시 풀에 반납하는 Client를 생성합니다
+func (p *Pool) lease(pc *pooledConn) *Client {
+	var once sync.Once
+	leased := &Client{Client: pc.client.Client}
+	leased.release = func(broken bool) {
+		once.Do(func() { p.release(pc, broken) })
+	}
+	return leased
+}
+
+// release는 빌려간 연결을 반납합니다. broken이면 연결을 풀에서 제거합니다
+func (p *Pool) release(pc *pooledConn, broken bool) {
+	p.mu.Lock()
+	pc.active--
+	pc.lastUsed = time.Now()
+	if broken {
+		p.removeLocked(pc)
+	}
+	shouldClose := pc.closed && pc.active == 0
+	p.mu.Unlock()
+
+	if shouldClose {
+		pc.client.Close()
+	}
+}
+
+// removeLocked는 연결을 풀 목록에서 제거하고 닫힘으로 표시합니다. p.mu를 잡은 상태에서 호출해야 합니다
+func (p *Pool) removeLocked(pc *pooledConn) {
+	pc.closed = true
+	conns := p.conns[pc.key]
+	for i, c := range conns {
+		if c == pc {
+			p.conns[pc.key] = append(conns[:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(p.conns[pc.key]) == 0 {
+		delete(p.conns, pc.key)
+	}
+}
+
+// healthy는 keepalive 요청으로 연결이 살아 있는지 확인합니다
+func (p *Pool) healthy(pc *pooledConn) bool {
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := pc.client.SendRequest("keepalive@openssh.com", true, nil)
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err == nil
+	case <-time.After(p.config.HealthCheckTimeout):
+		return false
+	}
+}
+
+// maintain은 주기적으로 keepalive를 보내고 유휴 시간이 지난 연결을 닫습니다
+func (p *Pool) maintain() {
+	ticker := time.NewTicker(p.config.KeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.sweep()
+		}
+	}
+}
+
+// sweep은 유휴 연결을 정리하고 나머지 연결에 keepalive를 보냅니다
+func (p *Pool) sweep() {
+	var idle, alive []*pooledConn
+
+	p.mu.Lock()
+	for _, conns := range p.conns {
+		for _, pc := range conns {
+			if pc.active == 0 && time.Since(pc.lastUsed) > p.config.IdleTimeout {
+				idle = append(idle, pc)
+			} else {
+				alive = append(alive, pc)
+			}
+		}
+	}
+	for _, pc := range idle {
+		p.removeLocked(pc)
+	}
+	p.mu.Unlock()
+
+	for _, pc := range idle {
+		pc.client.Close()
+	}
+
+	for _, pc := range alive {
+		if p.healthy(pc) {
+			continue
+		}
+		p.mu.Lock()
+		if pc.closed {
+			// 상태 확인 중 이미 제거된 연결
+			p.mu.Unlock()
+			continue
+		}
+		p.removeLocked(pc)
+		shouldClose := pc.active == 0
+		p.mu.Unlock()
+		if shouldClose {
+			pc.client.Close()
+		}
+	}
+}
+
+// Stats는 홉 체인별 열린 연결 수를 반환합니다
+func (p *Pool) Stats() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]int, len(p.conns))
+	for key, conns := range p.conns {
+		stats[key] = len(conns)
+	}
+	return stats
+}
+
+// Close는 풀의 모든 연결을 닫고 백그라운드 작업을 중지합니다
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+
+		p.mu.Lock()
+		var idle []*pooledConn
+		for _, conns := range p.conns {
+			for _, pc := range conns {
+				// 사용 중인 연결은 반납될 때 닫힙니다
+				pc.closed = true
+				if pc.active == 0 {
+					idle = append(idle, pc)
+				}
+			}
+		}
+		p.conns = make(map[string][]*pooledConn)
+		p.mu.Unlock()
+
+		for _, pc := range idle {
+			pc.client.Close()
+		}
+	})
+}
@@ -2,11 +2,10 @@ package ssh
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
 	"net"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -18,11 +17,24 @@ type Target struct {
 }
 
 // HopConfig는 SSH 연결을 위한 설정을 정의합니다
+// 인증 수단은 하나 이상 지정해야 하며, 시도 순서는 newHopAuth를 참고하세요
 type HopConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	PrivateKey  string `json:"private_key,omitempty"` // PEM 형식 개인키
+	Passphrase  string `json:"passphrase,omitempty"`  // 개인키 암호 (암호화된 키인 경우)
+	Certificate string `json:"certificate,omitempty"` // OpenSSH 인증서 (authorized_keys 형식, PrivateKey 필요)
+	// AgentSocket은 ssh-agent 소켓 경로입니다 ("env"이면 SSH_AUTH_SOCK 사용)
+	// 백엔드 호스트의 키로 인증하게 되므로 요청 JSON으로는 받지 않고 서버 설정(SetAgentSocket)으로만 지정합니다
+	AgentSocket string `json:"-"`
+	// CredentialID는 자격 증명 저장소에 보관된 인증 정보의 ID입니다 (비밀번호, 개인키를 직접 지정하지 않을 때 사용)
+	CredentialID int `json:"credential_id,omitempty"`
+
+	// 관리자 권한 실행 설정 (최종 hop에만 적용)
+	SudoMode     SudoMode `json:"sudo_mode,omitempty"`     // password, nopasswd, root (비어 있으면 자동 판단)
+	SudoPassword string   `json:"sudo_password,omitempty"` // 비어 있으면 Password 사용
 }
 
 // CommandResult는 명령어 실행 결과를 저장합니다
@@ -33,57 +45,93 @@ type CommandResult struct {
 	ExitCode int    `json:"exitCode"`
 }
 
-// ErrorType은 SSH 연결 또는 명령 실행 중 발생할 수 있는 오류 유형을 정의합니다
-type ErrorType string
-
-const (
-	AuthenticationFailed   ErrorType = "AUTHENTICATION_FAILED"
-	ConnectionRefused      ErrorType = "CONNECTION_REFUSED"
-	ConnectionTimeout      ErrorType = "CONNECTION_TIMEOUT"
-	HostNotFound           ErrorType = "HOST_NOT_FOUND"
-	TunnelingFailed        ErrorType = "TUNNELING_FAILED"
-	CommandExecutionFailed ErrorType = "COMMAND_EXECUTION_FAILED"
-	ValidationError        ErrorType = "VALIDATION_ERROR"
-	UnknownError           ErrorType = "UNKNOWN_ERROR"
-)
-
-// SSHError는 SSH 연결 또는 명령 실행 중 발생하는 오류를 나타냅니다
-type SSHError struct {
-	Type    ErrorType `json:"type"`
-	Message string    `json:"message"`
-	Host    string    `json:"host,omitempty"`
-	Command string    `json:"command,omitempty"`
+// SSHService는 SSH 연결 및 명령어 실행을 담당하는 서비스입니다
+type SSHService struct {
+	pool *Pool
 }
 
-// Error는 error 인터페이스를 구현합니다
-func (e SSHError) Error() string {
-	return e.Message
+// defaultTimeout은 타임아웃을 0으로 지정한 연결, 명령어 실행, 파일 전송의 타임아웃입니다 (나노초)
+var defaultTimeout int64 = int64(120 * time.Second)
+
+// SetDefaultTimeout은 타임아웃을 0으로 지정했을 때 사용할 기본 타임아웃을 설정합니다
+func SetDefaultTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		atomic.StoreInt64(&defaultTimeout, int64(timeout))
+	}
 }
 
-// SSHService는 SSH 연결 및 명령어 실행을 담당하는 서비스입니다
-type SSHService struct{}
+// DefaultTimeout은 타임아웃을 0으로 지정했을 때 사용하는 기본 타임아웃입니다 (기본 120초)
+func DefaultTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&defaultTimeout))
+}
 
-// NewSSHService는 새로운 SSHService 인스턴스를 생성합니다
+// NewSSHService는 기본 연결 풀을 공유하는 새로운 SSHService 인스턴스를 생성합니다
 func NewSSHService() *SSHService {
-	return &SSHService{}
+	return &SSHService{pool: DefaultPool()}
 }
 
-// getSSHClientConfig는 SSH 클라이언트 설정을 생성합니다
-func getSSHClientConfig(hop HopConfig, timeout time.Duration) *ssh.ClientConfig {
+// NewSSHServiceWithPool은 지정한 연결 풀을 사용하는 SSHService 인스턴스를 생성합니다
+// pool이 nil이면 매 실행마다 새로 연결합니다
+func NewSSHServiceWithPool(pool *Pool) *SSHService {
+	return &SSHService{pool: pool}
+}
+
+// getSSHClientConfig는 via 경로를 거쳐 접속하는 hop의 SSH 클라이언트 설정을 생성합니다
+// 반환된 hopAuth는 핸드셰이크가 끝난 뒤 Close해야 합니다
+func getSSHClientConfig(hop HopConfig, via string, timeout time.Duration) (*ssh.ClientConfig, *hopAuth, error) {
+	auth, err := newHopAuth(hop)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &ssh.ClientConfig{
-		User: hop.Username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(hop.Password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		User:            hop.Username,
+		Auth:            auth.methods,
+		HostKeyCallback: hostKeyCallback(getHostKeyStore(), via, hop.Host, hop.Port),
 		Timeout:         timeout,
+	}, auth, nil
+}
+
+// Client는 홉 체인을 거쳐 연결된 최종 호스트의 SSH 클라이언트입니다
+// Close는 최종 호스트부터 첫 번째 홉까지 모든 연결을 역순으로 종료하며,
+// 풀에서 빌려온 클라이언트는 연결을 닫지 않고 풀에 반납합니다
+type Client struct {
+	*ssh.Client
+	chain   []*ssh.Client
+	release func(broken bool)
+	broken  bool
+}
+
+// markBroken은 연결에 문제가 있어 풀에 반납하지 말아야 함을 표시합니다
+func (c *Client) markBroken() {
+	c.broken = true
+}
+
+// Close는 홉 체인의 모든 연결을 종료하거나 풀에 반납합니다
+func (c *Client) Close() error {
+	if c.release != nil {
+		c.release(c.broken)
+		return nil
 	}
+
+	var firstErr error
+	for i := len(c.chain) - 1; i >= 0; i-- {
+		if err := c.chain[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
-// ExecuteCommands는 여러 SSH 호스트를 통해 연결하고 최종 호스트에서 명령어를 실행합니다
-func (s *SSHService) ExecuteCommands(hops []HopConfig, finalCommands []string, timeout time.Duration) ([]CommandResult, error) {
+// Connect는 hops 순서대로 터널링하여 최종 호스트에 대한 SSH 클라이언트를 생성합니다
+func (s *SSHService) Connect(hops []HopConfig, timeout time.Duration) (*Client, error) {
+	return s.ConnectContext(context.Background(), hops, timeout)
+}
+
+// ConnectContext는 Connect와 같지만 ctx가 취소되면 진행 중인 연결을 중단합니다
+func (s *SSHService) ConnectContext(ctx context.Context, hops []HopConfig, timeout time.Duration) (*Client, error) {
 	if timeout == 0 {
-		timeout = 120 * time.Second // 기본 타임아웃 120초
+		timeout = DefaultTimeout()
 	}
 
 	if len(hops) == 0 {
@@ -92,24 +140,16 @@ func (s *SSHService) ExecuteCommands(hops []HopConfig, finalCommands []string, t
 			Message: "At least one hop configuration is required",
 		}
 	}
-
-	if len(finalCommands) == 0 {
-		return nil, SSHError{
-			Type:    ValidationError,
-			Message: "At least one command is required",
-		}
+	if err := checkResolved(hops); err != nil {
+		return nil, err
 	}
 
-	var currentClient *ssh.Client
 	var clients []*ssh.Client
-	var results []CommandResult
-
-	// 함수 종료 시 모든 클라이언트 연결 종료
-	defer func() {
+	closeAll := func() {
 		for i := len(clients) - 1; i >= 0; i-- {
 			clients[i].Close()
 		}
-	}()
+	}
 
 	// 첫 번째 호스트에 직접 연결
 	firstHop := hops[0]
@@ -117,10 +157,26 @@ func (s *SSHService) ExecuteCommands(hops []HopConfig, finalCommands []string, t
 		firstHop.Port = 22 // 기본 SSH 포트
 	}
 
-	config := getSSHClientConfig(firstHop, timeout)
-	var err error
-	currentClient, err = ssh.Dial("tcp", fmt.Sprintf("%s:%d", firstHop.Host, firstHop.Port), config)
+	config, auth, err := getSSHClientConfig(firstHop, "", timeout)
+	if err != nil {
+		return nil, err
+	}
+	addr := fmt.Sprintf("%s:%d", firstHop.Host, firstHop.Port)
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		auth.Close()
+		if ctx.Err() != nil {
+			return nil, canceledError(ctx, firstHop.Host, "")
+		}
+		return nil, mapSSHError(err, firstHop.Host)
+	}
+	currentClient, err := newClientConnContext(ctx, conn, addr, config)
+	auth.Close()
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, canceledError(ctx, firstHop.Host, "")
+		}
 		return nil, mapSSHError(err, firstHop.Host)
 	}
 	clients = append(clients, currentClient)
@@ -132,208 +188,322 @@ func (s *SSHService) ExecuteCommands(hops []HopConfig, finalCommands []string, t
 			hop.Port = 22
 		}
 
+		config, auth, err := getSSHClientConfig(hop, HopRoute(hops[:i]), timeout)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+
 		// 이전 호스트를 통해 터널 설정
-		conn, err := currentClient.Dial("tcp", fmt.Sprintf("%s:%d", hop.Host, hop.Port))
+		addr := fmt.Sprintf("%s:%d", hop.Host, hop.Port)
+		conn, err := currentClient.DialContext(ctx, "tcp", addr)
 		if err != nil {
+			auth.Close()
+			closeAll()
+			if ctx.Err() != nil {
+				return nil, canceledError(ctx, hop.Host, "")
+			}
 			return nil, SSHError{
 				Type:    TunnelingFailed,
 				Message: fmt.Sprintf("Tunneling failed to %s: %s", hop.Host, err.Error()),
 				Host:    hop.Host,
+				Err:     err,
 			}
 		}
 
 		// 터널을 통해 SSH 연결 설정
-		ncc, chans, reqs, err := ssh.NewClientConn(conn, fmt.Sprintf("%s:%d", hop.Host, hop.Port), getSSHClientConfig(hop, timeout))
+		client, err := newClientConnContext(ctx, conn, addr, config)
+		auth.Close()
 		if err != nil {
-			conn.Close()
+			closeAll()
+			if ctx.Err() != nil {
+				return nil, canceledError(ctx, hop.Host, "")
+			}
 			return nil, mapSSHError(err, hop.Host)
 		}
 
-		client := ssh.NewClient(ncc, chans, reqs)
 		currentClient = client
-		clients = append(clients, client)
+		clients = append(clients, currentClient)
 	}
 
-	// 최종 호스트에서 명령어 실행
-	for _, cmd := range finalCommands {
-		session, err := currentClient.NewSession()
-		if err != nil {
-			return results, SSHError{
-				Type:    CommandExecutionFailed,
-				Message: fmt.Sprintf("Failed to create session: %s", err.Error()),
-				Host:    hops[len(hops)-1].Host,
-			}
-		}
+	return &Client{Client: currentClient, chain: clients}, nil
+}
 
-		// 결과 및 오류 스트림 설정
-		var stdoutBuf, stderrBuf io.Reader
-		stdoutPipe, err := session.StdoutPipe()
-		if err != nil {
-			session.Close()
-			return results, SSHError{
-				Type:    CommandExecutionFailed,
-				Message: fmt.Sprintf("Failed to setup stdout pipe: %s", err.Error()),
-				Command: cmd,
-			}
+// newClientConnContext는 conn 위에서 SSH 핸드셰이크를 수행합니다
+// 핸드셰이크 도중 ctx가 취소되면 conn을 닫아 즉시 중단하며, 실패 시 conn은 항상 닫힙니다
+func newClientConnContext(ctx context.Context, conn net.Conn, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+
+	ncc, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if !stop() {
+		// ctx 취소로 이미 conn이 닫힌 경우
+		if err == nil {
+			ncc.Close()
 		}
-		stdoutBuf = stdoutPipe
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
 
-		stderrPipe, err := session.StderrPipe()
-		if err != nil {
-			session.Close()
-			return results, SSHError{
-				Type:    CommandExecutionFailed,
-				Message: fmt.Sprintf("Failed to setup stderr pipe: %s", err.Error()),
-				Command: cmd,
-			}
+	return ssh.NewClient(ncc, chans, reqs), nil
+}
+
+// Acquire는 연결 풀에서 홉 체인 연결을 가져옵니다. 풀이 없으면 새로 연결합니다
+func (s *SSHService) Acquire(hops []HopConfig, timeout time.Duration) (*Client, error) {
+	return s.AcquireContext(context.Background(), hops, timeout)
+}
+
+// AcquireContext는 Acquire와 같지만 ctx가 취소되면 새 연결 생성을 중단합니다
+func (s *SSHService) AcquireContext(ctx context.Context, hops []HopConfig, timeout time.Duration) (*Client, error) {
+	if err := checkResolved(hops); err != nil {
+		return nil, err
+	}
+	if s.pool == nil {
+		return s.ConnectContext(ctx, hops, timeout)
+	}
+	return s.pool.Acquire(ctx, hops, timeout)
+}
+
+// ExecuteCommands는 여러 SSH 호스트를 통해 연결하고 최종 호스트에서 명령어를 실행합니다
+func (s *SSHService) ExecuteCommands(hops []HopConfig, finalCommands []string, timeout time.Duration) ([]CommandResult, error) {
+	return s.ExecuteCommandsStream(context.Background(), hops, finalCommands, timeout, nil)
+}
+
+// ExecuteCommandsContext는 ExecuteCommands와 같지만 ctx가 취소되면 실행 중인 원격 프로세스 그룹을 종료하고 반환합니다
+func (s *SSHService) ExecuteCommandsContext(ctx context.Context, hops []HopConfig, finalCommands []string, timeout time.Duration) ([]CommandResult, error) {
+	return s.ExecuteCommandsStream(ctx, hops, finalCommands, timeout, nil)
+}
+
+// ExecuteCommandsStream은 ExecuteCommandsContext와 같이 명령어를 실행하면서
+// 명령어 시작/종료와 stdout/stderr 출력을 줄 단위로 handler에 전달합니다
+// handler가 nil이면 ExecuteCommandsContext와 동일하게 동작하며, 반환값은 항상 전체 실행 결과입니다
+func (s *SSHService) ExecuteCommandsStream(ctx context.Context, hops []HopConfig, finalCommands []string, timeout time.Duration, handler OutputHandler) ([]CommandResult, error) {
+	if timeout == 0 {
+		timeout = DefaultTimeout()
+	}
+
+	if len(hops) == 0 {
+		return nil, SSHError{
+			Type:    ValidationError,
+			Message: "At least one hop configuration is required",
 		}
-		stderrBuf = stderrPipe
+	}
 
-		// 명령어 실행 및 결과 수집
-		result := CommandResult{
-			Command: cmd,
+	if len(finalCommands) == 0 {
+		return nil, SSHError{
+			Type:    ValidationError,
+			Message: "At least one command is required",
 		}
+	}
 
-		// 컨텍스트를 사용하여 타임아웃 설정
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-
-		errCh := make(chan error, 1)
-		go func() {
-			errCh <- session.Run(cmd)
-		}()
-
-		// 실행 결과 수집
-		stdoutData := make([]byte, 1024)
-		stderrData := make([]byte, 1024)
-
-		var output, errOutput string
-
-		// 비동기적으로 stdout 읽기
-		stdoutCh := make(chan []byte, 1)
-		go func() {
-			for {
-				n, err := stdoutBuf.Read(stdoutData)
-				if n > 0 {
-					stdoutCh <- stdoutData[:n]
-				}
-				if err == io.EOF || err != nil {
-					close(stdoutCh)
-					return
-				}
-			}
-		}()
-
-		// 비동기적으로 stderr 읽기
-		stderrCh := make(chan []byte, 1)
-		go func() {
-			for {
-				n, err := stderrBuf.Read(stderrData)
-				if n > 0 {
-					stderrCh <- stderrData[:n]
-				}
-				if err == io.EOF || err != nil {
-					close(stderrCh)
-					return
-				}
-			}
-		}()
+	var results []CommandResult
 
-		// 모든 출력 채널과 명령어 완료를 기다림
-		for {
-			select {
-			case <-ctx.Done():
-				session.Close()
-				return results, SSHError{
-					Type:    ConnectionTimeout,
-					Message: fmt.Sprintf("Command execution timed out after %v", timeout),
-					Command: cmd,
-				}
-			case data, ok := <-stdoutCh:
-				if ok {
-					output += string(data)
-				}
-			case data, ok := <-stderrCh:
-				if ok {
-					errOutput += string(data)
-				}
-			case err := <-errCh:
-				result.Output = output
-				result.Error = errOutput
-
-				// 종료 코드 확인
-				exitCode := 0
-				if err != nil {
-					if exitErr, ok := err.(*ssh.ExitError); ok {
-						exitCode = exitErr.ExitStatus()
-					} else {
-						session.Close()
-						return results, SSHError{
-							Type:    CommandExecutionFailed,
-							Message: fmt.Sprintf("Command execution error: %s", err.Error()),
-							Command: cmd,
-						}
-					}
-				}
-				result.ExitCode = exitCode
-
-				results = append(results, result)
-				session.Close()
-				goto NextCommand
-			}
+	currentClient, err := s.AcquireContext(ctx, hops, timeout)
+	if err != nil {
+		return nil, err
+	}
+	// 함수 종료 시 연결 반납 (풀이 없으면 종료)
+	defer currentClient.Close()
+
+	host := hops[len(hops)-1].Host
+	emit := hostHandler(ctx, host, handler)
+	sudoHop := hops[len(hops)-1]
+	for _, cmd := range finalCommands {
+		if sudoPattern.MatchString(cmd) {
+			sudoHop = currentClient.ProbeSudo(sudoHop)
+			break
 		}
+	}
+	sudo := newSudoConfig(sudoHop)
 
-	NextCommand:
+	// 최종 호스트에서 명령어 실행
+	for i, cmd := range finalCommands {
+		if ctx.Err() != nil {
+			return results, canceledError(ctx, host, cmd)
+		}
+		result, err := runCommand(ctx, currentClient, host, sudo, i, cmd, timeout, emit)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
 	}
 
 	return results, nil
 }
 
-// mapSSHError는 SSH 오류를 SSHError 타입으로 변환합니다
-func mapSSHError(err error, host string) SSHError {
-	// 네트워크 오류 확인
-	var netErr net.Error
-	if errors.As(err, &netErr) {
-		if netErr.Timeout() {
-			return SSHError{
-				Type:    ConnectionTimeout,
-				Message: fmt.Sprintf("Connection timeout while connecting to %s", host),
-				Host:    host,
-			}
+// pgidMarker는 원격 셸이 자신의 프로세스 그룹 ID를 stderr로 알려줄 때 사용하는 접두어입니다
+// sshd는 exec 요청마다 setsid()로 새 세션을 만들므로 셸의 PID가 곧 프로세스 그룹 ID입니다
+const pgidMarker = "__K8SCONTROL_PGID__="
+
+// runCommand는 새 세션에서 명령어 하나를 실행하고 결과를 수집합니다
+// ctx가 취소되거나 timeout이 지나면 원격 프로세스 그룹을 종료하고 세션을 닫습니다
+func runCommand(ctx context.Context, client *Client, host string, sudo sudoConfig, index int, cmd string, timeout time.Duration, emit OutputHandler) (CommandResult, error) {
+	result := CommandResult{
+		Command: cmd,
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		client.markBroken()
+		return result, SSHError{
+			Type:    CommandExecutionFailed,
+			Message: fmt.Sprintf("Failed to create session: %s", err.Error()),
+			Host:    host,
+			Err:     err,
 		}
 	}
+	defer session.Close()
 
-	// 다양한 SSH 오류 유형에 따라 적절한 오류 반환
-	if errors.Is(err, &net.OpError{}) || errors.Is(err, &net.AddrError{}) {
-		// 연결 거부 또는 호스트 찾기 오류
-		if msg := err.Error(); strings.Contains(msg, "connection refused") {
-			return SSHError{
-				Type:    ConnectionRefused,
-				Message: fmt.Sprintf("Connection refused to %s", host),
-				Host:    host,
-			}
-		} else if strings.Contains(msg, "no such host") || strings.Contains(msg, "lookup") {
-			return SSHError{
-				Type:    HostNotFound,
-				Message: fmt.Sprintf("Host not found: %s", host),
-				Host:    host,
-			}
+	// 결과 및 오류 스트림 설정
+	stdoutPipe, err := session.StdoutPipe()
+	if err != nil {
+		return result, SSHError{
+			Type:    CommandExecutionFailed,
+			Message: fmt.Sprintf("Failed to setup stdout pipe: %s", err.Error()),
+			Command: cmd,
+			Err:     err,
 		}
 	}
 
-	// 인증 오류
-	if msg := err.Error(); strings.Contains(msg, "auth") || strings.Contains(msg, "authentication") {
-		return SSHError{
-			Type:    AuthenticationFailed,
-			Message: fmt.Sprintf("Authentication failed for %s", host),
+	stderrPipe, err := session.StderrPipe()
+	if err != nil {
+		return result, SSHError{
+			Type:    CommandExecutionFailed,
+			Message: fmt.Sprintf("Failed to setup stderr pipe: %s", err.Error()),
+			Command: cmd,
+			Err:     err,
+		}
+	}
+
+	// 컨텍스트를 사용하여 타임아웃 설정
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	emit(OutputEvent{Type: EventCommandStart, CommandIndex: index, Command: cmd, Time: time.Now()})
+
+	// stdout, stderr를 줄 단위로 읽으며 버퍼에 누적
+	// stderr의 첫 줄은 프로세스 그룹 ID 보고용이므로 결과에서 제외합니다
+	var output, errOutput outputBuffer
+	pgidCh := make(chan string, 1)
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		readLines(stdoutPipe, &output, func(line string) {
+			emit(OutputEvent{Type: EventOutput, CommandIndex: index, Command: cmd, Stream: StreamStdout, Line: line, Time: time.Now()})
+		})
+	}()
+	go func() {
+		defer readers.Done()
+		defer close(pgidCh)
+		readLinesAfterMarker(stderrPipe, &errOutput, pgidMarker, pgidCh, func(line string) {
+			emit(OutputEvent{Type: EventOutput, CommandIndex: index, Command: cmd, Stream: StreamStderr, Line: line, Time: time.Now()})
+		})
+	}()
+
+	// sudo 비밀번호는 명령줄 대신 stdin으로 전달
+	wrapped, stdin := sudo.wrap(cmd)
+	if stdin != nil {
+		session.Stdin = stdin
+	}
+
+	if err := session.Start(fmt.Sprintf("echo %s$$ >&2; %s", pgidMarker, wrapped)); err != nil {
+		return result, SSHError{
+			Type:    CommandExecutionFailed,
+			Message: fmt.Sprintf("Command execution error: %s", err.Error()),
+			Command: cmd,
+			Err:     err,
+		}
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- session.Wait()
+	}()
+
+	var runErr error
+	select {
+	case <-cmdCtx.Done():
+		killProcessGroup(client, session, pgidCh)
+		if ctx.Err() != nil {
+			return result, canceledError(ctx, host, cmd)
+		}
+		return result, SSHError{
+			Type:    ConnectionTimeout,
+			Message: fmt.Sprintf("Command execution timed out after %v", timeout),
 			Host:    host,
+			Command: cmd,
+			Err:     cmdCtx.Err(),
+		}
+	case runErr = <-errCh:
+	}
+
+	// 남은 출력을 모두 읽을 때까지 대기
+	readersDone := make(chan struct{})
+	go func() {
+		readers.Wait()
+		close(readersDone)
+	}()
+	select {
+	case <-readersDone:
+	case <-cmdCtx.Done():
+	}
+
+	result.Output = output.String()
+	result.Error = errOutput.String()
+
+	// 종료 코드 확인
+	if runErr != nil {
+		exitErr, ok := runErr.(*ssh.ExitError)
+		if !ok {
+			client.markBroken()
+			return result, SSHError{
+				Type:    CommandExecutionFailed,
+				Message: fmt.Sprintf("Command execution error: %s", runErr.Error()),
+				Command: cmd,
+				Err:     runErr,
+			}
 		}
+		result.ExitCode = exitErr.ExitStatus()
 	}
 
-	// 기타 오류
-	return SSHError{
-		Type:    UnknownError,
-		Message: fmt.Sprintf("Unknown error while connecting to %s: %s", host, err.Error()),
-		Host:    host,
+	emit(OutputEvent{Type: EventCommandExit, CommandIndex: index, Command: cmd, ExitCode: result.ExitCode, Time: time.Now()})
+
+	return result, nil
+}
+
+// killProcessGroup은 실행 중인 원격 명령어의 프로세스 그룹 전체를 종료하고 세션을 닫습니다
+// sudo로 실행된 자식 프로세스는 sudo가 전달받은 시그널을 대신 전달합니다
+func killProcessGroup(client *Client, session *ssh.Session, pgidCh <-chan string) {
+	session.Signal(ssh.SIGTERM)
+
+	// 실행 직후 취소된 경우를 위해 프로세스 그룹 ID 보고를 잠시 기다립니다
+	var pgid string
+	select {
+	case pgid = <-pgidCh:
+	case <-time.After(time.Second):
 	}
+
+	if pgid != "" {
+		if killSession, err := client.NewSession(); err == nil {
+			// TERM으로 정리할 기회를 준 뒤 남은 프로세스는 백그라운드에서 KILL 합니다
+			killCmd := fmt.Sprintf("kill -TERM -%[1]s 2>/dev/null; (sleep 2; kill -KILL -%[1]s) >/dev/null 2>&1 &", pgid)
+			done := make(chan struct{})
+			go func() {
+				killSession.Run(killCmd)
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+			}
+			killSession.Close()
+		}
+	}
+
+	session.Close()
 }
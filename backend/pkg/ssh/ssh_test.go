@@ -0,0 +1,419 @@
+package ssh_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/k8scontrol/backend/pkg/ssh/sshtest"
+)
+
+// newService는 테스트마다 독립된 호스트 키 저장소와 풀 없는 SSHService를 생성합니다
+func newService(t *testing.T) *ssh.SSHService {
+	t.Helper()
+	sshtest.UseMemoryHostKeys(t)
+	return ssh.NewSSHServiceWithPool(nil)
+}
+
+// sshErrorType은 err가 SSHError이면 오류 유형을 반환합니다
+func sshErrorType(t *testing.T, err error) ssh.ErrorType {
+	t.Helper()
+	var sshErr ssh.SSHError
+	if !errors.As(err, &sshErr) {
+		t.Fatalf("SSHError가 아닌 오류: %T %v", err, err)
+	}
+	return sshErr.Type
+}
+
+func TestExecuteCommands(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t)
+	server.Handle("hostname", sshtest.Response{Stdout: "node-1\n"})
+	server.Handle("false", sshtest.Response{Stderr: "failed\n", ExitCode: 1})
+
+	results, err := service.ExecuteCommands([]ssh.HopConfig{server.Hop()}, []string{"hostname", "false", "missing-cmd"}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ExecuteCommands 실패: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("결과 개수 = %d, want 3", len(results))
+	}
+
+	if results[0].Output != "node-1\n" || results[0].ExitCode != 0 || results[0].Error != "" {
+		t.Errorf("hostname 결과 = %+v", results[0])
+	}
+	if results[1].Error != "failed\n" || results[1].ExitCode != 1 {
+		t.Errorf("false 결과 = %+v", results[1])
+	}
+	if results[2].ExitCode != 127 {
+		t.Errorf("missing-cmd 종료 코드 = %d, want 127", results[2].ExitCode)
+	}
+
+	want := []string{"hostname", "false", "missing-cmd"}
+	if got := server.Commands(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("실행된 명령어 = %q, want %q", got, want)
+	}
+}
+
+func TestExecuteCommandsMultiHop(t *testing.T) {
+	service := newService(t)
+	bastion := sshtest.NewServer(t, sshtest.WithForwarding())
+	target := sshtest.NewServer(t, sshtest.WithCredentials("admin", "pw"))
+	target.Handle("uptime", sshtest.Response{Stdout: "up 3 days\n"})
+
+	results, err := service.ExecuteCommands([]ssh.HopConfig{bastion.Hop(), target.Hop()}, []string{"uptime"}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ExecuteCommands 실패: %v", err)
+	}
+	if results[0].Output != "up 3 days\n" {
+		t.Errorf("출력 = %q", results[0].Output)
+	}
+	if len(bastion.Commands()) != 0 {
+		t.Errorf("배스천에서 명령어가 실행됨: %q", bastion.Commands())
+	}
+}
+
+func TestConnectErrors(t *testing.T) {
+	service := newService(t)
+
+	// 연결을 거부하는 주소
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	rejecting := sshtest.NewServer(t, sshtest.RejectAuth())
+	noForward := sshtest.NewServer(t)
+	target := sshtest.NewServer(t)
+
+	tests := []struct {
+		name     string
+		hops     []ssh.HopConfig
+		want     ssh.ErrorType
+		sentinel error
+	}{
+		{"인증 실패", []ssh.HopConfig{rejecting.Hop()}, ssh.AuthenticationFailed, ssh.ErrAuthenticationFailed},
+		{"연결 거부", []ssh.HopConfig{{Host: "127.0.0.1", Port: closedPort, Username: "u", Password: "p"}}, ssh.ConnectionRefused, ssh.ErrConnectionRefused},
+		{"호스트 없음", []ssh.HopConfig{{Host: "no-such-host.invalid", Port: 22, Username: "u", Password: "p"}}, ssh.HostNotFound, ssh.ErrHostNotFound},
+		{"터널링 실패", []ssh.HopConfig{noForward.Hop(), target.Hop()}, ssh.TunnelingFailed, ssh.ErrTunnelingFailed},
+		{"hop 없음", nil, ssh.ValidationError, ssh.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ExecuteCommands(tt.hops, []string{"true"}, 5*time.Second)
+			if err == nil {
+				t.Fatal("오류가 발생해야 합니다")
+			}
+			if got := sshErrorType(t, err); got != tt.want {
+				t.Errorf("오류 유형 = %s, want %s (%v)", got, tt.want, err)
+			}
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("errors.Is(err, %v) = false (%v)", tt.sentinel, err)
+			}
+		})
+	}
+}
+
+func TestHopConfigIgnoresAgentSocket(t *testing.T) {
+	// 요청 JSON으로 백엔드 호스트의 ssh-agent를 지정할 수 없어야 함
+	var hop ssh.HopConfig
+	if err := json.Unmarshal([]byte(`{"host":"10.0.0.1","agent_socket":"env"}`), &hop); err != nil {
+		t.Fatal(err)
+	}
+	if hop.AgentSocket != "" {
+		t.Errorf("AgentSocket = %q, want 빈 값", hop.AgentSocket)
+	}
+
+	hop.AgentSocket = "/tmp/agent.sock"
+	data, err := json.Marshal(hop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "agent") {
+		t.Errorf("JSON에 agent 소켓이 포함되었습니다: %s", data)
+	}
+}
+
+func TestHostKeyMismatch(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t)
+	other := sshtest.NewServer(t)
+
+	// 다른 서버의 키를 이 서버의 키로 고정
+	store := sshtest.UseMemoryHostKeys(t)
+	store.PinHostKey(ssh.NewHostKey("", server.Host, server.Port, other.HostKey()))
+
+	_, err := service.ExecuteCommands([]ssh.HopConfig{server.Hop()}, []string{"true"}, 5*time.Second)
+	if got := sshErrorType(t, err); got != ssh.HostKeyMismatch {
+		t.Fatalf("오류 유형 = %s, want %s", got, ssh.HostKeyMismatch)
+	}
+	var hostKeyErr *ssh.HostKeyError
+	if !errors.Is(err, ssh.ErrHostKeyMismatch) || !errors.As(err, &hostKeyErr) {
+		t.Errorf("호스트 키 오류가 센티널과 원인을 감싸지 않음: %v", err)
+	}
+
+	// 승인하면 새 키로 접속할 수 있어야 합니다
+	if err := store.ApproveHostKey("", server.Host, server.Port); err != nil {
+		t.Fatalf("ApproveHostKey 실패: %v", err)
+	}
+	server.Handle("true", sshtest.Response{})
+	if _, err := service.ExecuteCommands([]ssh.HopConfig{server.Hop()}, []string{"true"}, 5*time.Second); err != nil {
+		t.Fatalf("승인 후 접속 실패: %v", err)
+	}
+}
+
+func TestHostKeyPinnedPerRoute(t *testing.T) {
+	service := newService(t)
+	bastion := sshtest.NewServer(t, sshtest.WithForwarding())
+	target := sshtest.NewServer(t)
+	other := sshtest.NewServer(t)
+	target.Handle("true", sshtest.Response{})
+
+	// 다른 네트워크의 같은 주소 호스트가 직접 연결 경로로 고정되어 있어도
+	// 배스천을 거치는 경로는 별도로 고정되어야 합니다
+	store := sshtest.UseMemoryHostKeys(t)
+	store.PinHostKey(ssh.NewHostKey("", target.Host, target.Port, other.HostKey()))
+
+	hops := []ssh.HopConfig{bastion.Hop(), target.Hop()}
+	if _, err := service.ExecuteCommands(hops, []string{"true"}, 5*time.Second); err != nil {
+		t.Fatalf("배스천 경유 접속 실패: %v", err)
+	}
+
+	via := ssh.HopRoute(hops[:1])
+	pinned, err := store.LookupHostKey(via, target.Host, target.Port)
+	if err != nil || pinned == nil {
+		t.Fatalf("배스천 경유 경로의 키가 고정되지 않음: %v", err)
+	}
+	if want := ssh.NewHostKey(via, target.Host, target.Port, target.HostKey()); pinned.Fingerprint != want.Fingerprint {
+		t.Errorf("고정된 키 = %s, want %s", pinned.Fingerprint, want.Fingerprint)
+	}
+
+	// 이미 고정된 키는 다시 고정해도 바뀌지 않음
+	store.PinHostKey(ssh.NewHostKey(via, target.Host, target.Port, other.HostKey()))
+	if again, _ := store.LookupHostKey(via, target.Host, target.Port); again.Fingerprint != pinned.Fingerprint {
+		t.Errorf("고정된 키가 덮어써짐: %s", again.Fingerprint)
+	}
+}
+
+func TestCommandTimeoutKillsProcessGroup(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t)
+	server.Handle("sleep 60", sshtest.Response{Delay: time.Minute})
+
+	start := time.Now()
+	_, err := service.ExecuteCommands([]ssh.HopConfig{server.Hop()}, []string{"sleep 60"}, 200*time.Millisecond)
+	if got := sshErrorType(t, err); got != ssh.ConnectionTimeout {
+		t.Fatalf("오류 유형 = %s, want %s", got, ssh.ConnectionTimeout)
+	}
+	if !errors.Is(err, ssh.ErrConnectionTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("타임아웃 오류가 센티널과 원인을 감싸지 않음: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("타임아웃 처리에 너무 오래 걸림: %v", elapsed)
+	}
+
+	waitFor(t, func() bool {
+		for _, e := range server.Execs() {
+			if e.Command == "sleep 60" && e.Killed {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func TestExecuteCommandsContextCanceled(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t)
+	server.Handle("long-task", sshtest.Response{Delay: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	_, err := service.ExecuteCommandsContext(ctx, []ssh.HopConfig{server.Hop()}, []string{"long-task", "never-run"}, 10*time.Second)
+	if got := sshErrorType(t, err); got != ssh.CommandCanceled {
+		t.Fatalf("오류 유형 = %s, want %s", got, ssh.CommandCanceled)
+	}
+	if !errors.Is(err, ssh.ErrCommandCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("취소 오류가 센티널과 원인을 감싸지 않음: %v", err)
+	}
+	for _, cmd := range server.Commands() {
+		if cmd == "never-run" {
+			t.Error("취소 후 다음 명령어가 실행됨")
+		}
+	}
+}
+
+func TestSudoPasswordOverStdin(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t, sshtest.WithCredentials("deploy", "login-pw"))
+	server.Handle("sudo systemctl restart kubelet", sshtest.Response{})
+
+	hop := server.Hop()
+	hop.SudoPassword = "sudo-pw"
+	if _, err := service.ExecuteCommands([]ssh.HopConfig{hop}, []string{"sudo systemctl restart kubelet"}, 5*time.Second); err != nil {
+		t.Fatalf("ExecuteCommands 실패: %v", err)
+	}
+
+	execs := server.Execs()
+	if len(execs) != 1 {
+		t.Fatalf("실행 기록 = %d개, want 1", len(execs))
+	}
+	if execs[0].Command != "sudo systemctl restart kubelet" {
+		t.Errorf("명령어 = %q", execs[0].Command)
+	}
+	if execs[0].SudoPassword != "sudo-pw" {
+		t.Errorf("stdin으로 받은 비밀번호 = %q, want %q", execs[0].SudoPassword, "sudo-pw")
+	}
+	if strings.Contains(execs[0].Raw, "sudo-pw") || strings.Contains(execs[0].Raw, "login-pw") {
+		t.Errorf("명령줄에 비밀번호가 포함됨: %q", execs[0].Raw)
+	}
+}
+
+func TestSudoNoPasswdHostSkipsPassword(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t, sshtest.WithCredentials("deploy", "login-pw"), sshtest.WithNoPasswdSudo())
+	server.Handle("sudo tee /etc/k8scontrol.conf", sshtest.Response{})
+
+	// 로그인 비밀번호가 있어도 NOPASSWD 호스트에서는 sudo -S로 비밀번호를 보내지 않아야 합니다
+	if _, err := service.ExecuteCommands([]ssh.HopConfig{server.Hop()}, []string{"sudo tee /etc/k8scontrol.conf"}, 5*time.Second); err != nil {
+		t.Fatalf("ExecuteCommands 실패: %v", err)
+	}
+
+	execs := server.Execs()
+	if len(execs) != 1 {
+		t.Fatalf("실행 기록 = %d개, want 1", len(execs))
+	}
+	if execs[0].SudoPassword != "" || strings.Contains(execs[0].Raw, "sudo -S") {
+		t.Errorf("NOPASSWD 호스트에 비밀번호 모드로 실행됨: %q (stdin %q)", execs[0].Raw, execs[0].SudoPassword)
+	}
+	if !strings.Contains(execs[0].Raw, "command sudo -n") {
+		t.Errorf("nopasswd 모드로 실행되지 않음: %q", execs[0].Raw)
+	}
+
+	// sudo_mode를 명시하면 확인하지 않고 그대로 사용합니다
+	hop := server.Hop()
+	hop.SudoMode = ssh.SudoPassword
+	if _, err := service.ExecuteCommands([]ssh.HopConfig{hop}, []string{"sudo tee /etc/k8scontrol.conf"}, 5*time.Second); err != nil {
+		t.Fatalf("ExecuteCommands 실패: %v", err)
+	}
+	if execs := server.Execs(); execs[len(execs)-1].SudoPassword != "login-pw" {
+		t.Errorf("명시한 password 모드에서 stdin으로 받은 비밀번호 = %q", execs[len(execs)-1].SudoPassword)
+	}
+}
+
+func TestExecuteCommandsStream(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t)
+	server.Handle("build", sshtest.Response{Stdout: "step 1\nstep 2\n", Stderr: "warn\n", ExitCode: 2})
+
+	var events []ssh.OutputEvent
+	results, err := service.ExecuteCommandsStream(context.Background(), []ssh.HopConfig{server.Hop()}, []string{"build"}, 5*time.Second, func(e ssh.OutputEvent) {
+		events = append(events, e)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteCommandsStream 실패: %v", err)
+	}
+	if results[0].ExitCode != 2 {
+		t.Errorf("종료 코드 = %d, want 2", results[0].ExitCode)
+	}
+
+	var stdout []string
+	var exitEvent *ssh.OutputEvent
+	for i, e := range events {
+		switch {
+		case e.Type == ssh.EventOutput && e.Stream == ssh.StreamStdout:
+			stdout = append(stdout, e.Line)
+		case e.Type == ssh.EventOutput && strings.Contains(e.Line, "__K8SCONTROL_PGID__"):
+			t.Errorf("프로세스 그룹 보고 줄이 출력으로 전달됨: %q", e.Line)
+		case e.Type == ssh.EventCommandExit:
+			exitEvent = &events[i]
+		}
+	}
+	if strings.Join(stdout, "|") != "step 1|step 2" {
+		t.Errorf("stdout 줄 = %q", stdout)
+	}
+	if events[0].Type != ssh.EventCommandStart || exitEvent == nil || exitEvent.ExitCode != 2 {
+		t.Errorf("시작/종료 이벤트가 올바르지 않음: %+v", events)
+	}
+}
+
+func TestPoolReusesConnection(t *testing.T) {
+	sshtest.UseMemoryHostKeys(t)
+	pool := ssh.NewPool(ssh.DefaultPoolConfig())
+	defer pool.Close()
+	service := ssh.NewSSHServiceWithPool(pool)
+
+	server := sshtest.NewServer(t)
+	server.Handle("true", sshtest.Response{})
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.ExecuteCommands([]ssh.HopConfig{server.Hop()}, []string{"true"}, 5*time.Second); err != nil {
+			t.Fatalf("%d번째 실행 실패: %v", i+1, err)
+		}
+	}
+	stats := pool.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("풀 홉 체인 수 = %d, want 1", len(stats))
+	}
+	for _, count := range stats {
+		if count != 1 {
+			t.Errorf("풀 연결 수 = %d, want 1", count)
+		}
+	}
+}
+
+func TestUploadAndReadFile(t *testing.T) {
+	service := newService(t)
+	server := sshtest.NewServer(t)
+	hops := []ssh.HopConfig{server.Hop()}
+	ctx := context.Background()
+
+	content := "global\n  maxconn 4096\n"
+	result, err := service.Upload(ctx, hops, "/haproxy.cfg", strings.NewReader(content), 0644, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Upload 실패: %v", err)
+	}
+	if result.Size != int64(len(content)) || result.Checksum == "" {
+		t.Errorf("업로드 결과 = %+v", result)
+	}
+
+	// 덮어쓰기
+	content = "global\n  maxconn 8192\n"
+	if _, err := service.Upload(ctx, hops, "/haproxy.cfg", strings.NewReader(content), 0644, 5*time.Second); err != nil {
+		t.Fatalf("덮어쓰기 실패: %v", err)
+	}
+
+	data, err := service.ReadFile(ctx, hops, "/haproxy.cfg", 5*time.Second)
+	if err != nil {
+		t.Fatalf("ReadFile 실패: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("읽은 내용 = %q, want %q", data, content)
+	}
+
+	_, err = service.ReadFile(ctx, hops, "/missing.cfg", 5*time.Second)
+	if got := sshErrorType(t, err); got != ssh.TransferFailed {
+		t.Errorf("없는 파일 오류 유형 = %s, want %s", got, ssh.TransferFailed)
+	}
+}
+
+// waitFor는 cond가 참이 될 때까지 최대 5초 동안 기다립니다
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("조건을 만족하지 못하고 시간이 초과됨")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,571 @@
+// Package sshtest는 실제 서버 없이 pkg/ssh와 이를 사용하는 코드를 테스트하기 위한
+// 가짜 SSH 서버를 제공합니다.
+//
+// 서버는 루프백 주소에서 실행되며 명령어별 응답(출력, 종료 코드, 지연)을 지정할 수 있고,
+// 포워딩을 허용하면 다중 홉 테스트를 위한 배스천 역할도 합니다. 실행된 명령어는
+// pkg/ssh가 덧붙이는 프로세스 그룹 보고 및 sudo 처리 구문을 제거한 형태로 기록됩니다.
+package sshtest
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8scontrol/backend/pkg/ssh"
+	"github.com/pkg/sftp"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// 기본 인증 정보
+const (
+	DefaultUser     = "tester"
+	DefaultPassword = "secret"
+)
+
+// pkg/ssh가 명령어 앞에 붙이는 구문 (pkg/ssh의 runCommand, sudoConfig.wrap 참고)
+const (
+	pgidPrefix     = "echo __K8SCONTROL_PGID__=$$ >&2; "
+	pgidMarker     = "__K8SCONTROL_PGID__="
+	sudoReadPrefix = "IFS= read -r "
+	sudoFuncPrefix = "sudo() {"
+	sudoFuncSuffix = "; }; "
+)
+
+// killPattern은 pkg/ssh가 취소 시 실행하는 프로세스 그룹 종료 명령어입니다
+var killPattern = regexp.MustCompile(`^kill -TERM -(\d+)`)
+
+// Response는 명령어 하나에 대한 가짜 응답입니다
+type Response struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Delay    time.Duration // 응답 전 대기 시간 (시그널이나 kill로 중단되면 종료 코드 143)
+}
+
+// HandlerFunc는 명령어에 대한 응답을 결정합니다. 처리하지 않는 명령어이면 false를 반환합니다
+type HandlerFunc func(cmd string) (Response, bool)
+
+// Exec는 서버에서 실행된 명령어 기록입니다
+type Exec struct {
+	Command      string // pkg/ssh가 덧붙인 구문을 제거한 명령어
+	Raw          string // 클라이언트가 보낸 원본 명령어
+	SudoPassword string // stdin으로 전달받은 sudo 비밀번호 (password 모드인 경우)
+	Killed       bool   // 시그널이나 kill 명령어로 중단되었는지 여부
+}
+
+// Option은 서버 설정을 변경합니다
+type Option func(*Server)
+
+// WithCredentials는 비밀번호 인증에 사용할 사용자와 비밀번호를 지정합니다
+func WithCredentials(user, password string) Option {
+	return func(s *Server) {
+		s.User = user
+		s.Password = password
+	}
+}
+
+// WithAuthorizedKey는 공개키 인증을 허용할 키를 추가합니다
+func WithAuthorizedKey(key cryptossh.PublicKey) Option {
+	return func(s *Server) {
+		s.authorizedKeys = append(s.authorizedKeys, key)
+	}
+}
+
+// WithForwarding은 direct-tcpip 포워딩을 허용하여 서버를 배스천으로 사용할 수 있게 합니다
+func WithForwarding() Option {
+	return func(s *Server) {
+		s.forwarding = true
+	}
+}
+
+// WithNoPasswdSudo는 NOPASSWD로 설정된 sudo를 가진 호스트처럼 sudo 확인 명령어에 성공을 응답합니다
+// 설정하지 않으면 sudo 확인은 비밀번호가 필요하다는 오류로 실패합니다
+func WithNoPasswdSudo() Option {
+	return func(s *Server) {
+		s.noPasswdSudo = true
+	}
+}
+
+// RejectAuth는 모든 인증 시도를 거부합니다
+func RejectAuth() Option {
+	return func(s *Server) {
+		s.rejectAuth = true
+	}
+}
+
+// Server는 테스트용 가짜 SSH 서버입니다
+type Server struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+
+	signer         cryptossh.Signer
+	authorizedKeys []cryptossh.PublicKey
+	forwarding     bool
+	rejectAuth     bool
+	noPasswdSudo   bool
+
+	listener net.Listener
+	sftp     sftp.Handlers
+
+	mu       sync.Mutex
+	exact    map[string]Response
+	handlers []HandlerFunc
+	execs    []Exec
+	running  map[int]chan struct{}
+	nextPGID int
+
+	wg     sync.WaitGroup
+	closed chan struct{}
+}
+
+// NewServer는 루프백 주소에서 가짜 SSH 서버를 시작합니다
+// 서버는 테스트가 끝나면 자동으로 종료됩니다
+func NewServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("sshtest: 호스트 키 생성 실패: %v", err)
+	}
+	signer, err := cryptossh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("sshtest: 호스트 키 서명자 생성 실패: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("sshtest: 리스너 생성 실패: %v", err)
+	}
+
+	s := &Server{
+		Host:     "127.0.0.1",
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		User:     DefaultUser,
+		Password: DefaultPassword,
+		signer:   signer,
+		listener: listener,
+		sftp:     sftp.InMemHandler(),
+		exact:    make(map[string]Response),
+		running:  make(map[int]chan struct{}),
+		nextPGID: 1000,
+		closed:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// UseMemoryHostKeys는 테스트 동안 새 메모리 호스트 키 저장소를 사용하도록 설정합니다
+// 테스트 서버의 포트가 재사용되어 이전에 고정된 키와 충돌하는 것을 막습니다
+func UseMemoryHostKeys(t testing.TB) *ssh.MemoryHostKeyStore {
+	store := ssh.NewMemoryHostKeyStore()
+	ssh.SetHostKeyStore(store)
+	t.Cleanup(func() {
+		ssh.SetHostKeyStore(ssh.NewMemoryHostKeyStore())
+	})
+	return store
+}
+
+// Addr는 서버의 host:port 주소를 반환합니다
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// HostKey는 서버의 호스트 공개키를 반환합니다
+func (s *Server) HostKey() cryptossh.PublicKey {
+	return s.signer.PublicKey()
+}
+
+// Hop은 기본 인증 정보로 서버에 접속하는 HopConfig를 반환합니다
+func (s *Server) Hop() ssh.HopConfig {
+	return ssh.HopConfig{
+		Host:     s.Host,
+		Port:     s.Port,
+		Username: s.User,
+		Password: s.Password,
+	}
+}
+
+// Handle은 cmd와 정확히 일치하는 명령어의 응답을 지정합니다
+func (s *Server) Handle(cmd string, resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exact[cmd] = resp
+}
+
+// HandleFunc는 응답 결정 함수를 추가합니다. 정확히 일치하는 응답이 없을 때 등록 순서대로 확인합니다
+func (s *Server) HandleFunc(fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, fn)
+}
+
+// Execs는 지금까지 실행된 명령어 기록을 반환합니다
+func (s *Server) Execs() []Exec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Exec(nil), s.execs...)
+}
+
+// Commands는 지금까지 실행된 명령어 목록을 반환합니다
+func (s *Server) Commands() []string {
+	execs := s.Execs()
+	commands := make([]string, len(execs))
+	for i, e := range execs {
+		commands[i] = e.Command
+	}
+	return commands
+}
+
+// Close는 서버를 종료하고 열린 연결을 모두 닫습니다
+func (s *Server) Close() {
+	select {
+	case <-s.closed:
+		return
+	default:
+	}
+	close(s.closed)
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// serve는 새 연결을 받아 처리합니다
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	config := &cryptossh.ServerConfig{
+		PasswordCallback: func(meta cryptossh.ConnMetadata, password []byte) (*cryptossh.Permissions, error) {
+			if !s.rejectAuth && meta.User() == s.User && string(password) == s.Password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected for %s", meta.User())
+		},
+		PublicKeyCallback: func(meta cryptossh.ConnMetadata, key cryptossh.PublicKey) (*cryptossh.Permissions, error) {
+			if !s.rejectAuth && meta.User() == s.User {
+				for _, authorized := range s.authorizedKeys {
+					if string(authorized.Marshal()) == string(key.Marshal()) {
+						return nil, nil
+					}
+				}
+			}
+			return nil, fmt.Errorf("public key rejected for %s", meta.User())
+		},
+	}
+	config.AddHostKey(s.signer)
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn, config)
+		}()
+	}
+}
+
+// handleConn은 연결 하나의 핸드셰이크와 채널 요청을 처리합니다
+func (s *Server) handleConn(conn net.Conn, config *cryptossh.ServerConfig) {
+	// 서버 종료 시 연결을 닫아 처리 중인 고루틴을 정리합니다
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-s.closed:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	sconn, chans, reqs, err := cryptossh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer sconn.Close()
+	go cryptossh.DiscardRequests(reqs)
+
+	var channels sync.WaitGroup
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			channels.Add(1)
+			go func() {
+				defer channels.Done()
+				s.handleSession(channel, requests)
+			}()
+		case "direct-tcpip":
+			if !s.forwarding {
+				newChannel.Reject(cryptossh.Prohibited, "port forwarding is disabled")
+				continue
+			}
+			channels.Add(1)
+			go func() {
+				defer channels.Done()
+				s.handleForward(newChannel)
+			}()
+		default:
+			newChannel.Reject(cryptossh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+	channels.Wait()
+}
+
+// handleForward는 배스천 역할로 direct-tcpip 채널을 대상 주소에 연결합니다
+func (s *Server) handleForward(newChannel cryptossh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := cryptossh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(cryptossh.ConnectionFailed, "invalid forward payload")
+		return
+	}
+
+	target, err := net.DialTimeout("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))), 5*time.Second)
+	if err != nil {
+		newChannel.Reject(cryptossh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go cryptossh.DiscardRequests(requests)
+
+	var copies sync.WaitGroup
+	copies.Add(2)
+	go func() {
+		defer copies.Done()
+		io.Copy(target, channel)
+		target.Close()
+	}()
+	go func() {
+		defer copies.Done()
+		io.Copy(channel, target)
+		channel.Close()
+	}()
+	copies.Wait()
+}
+
+// handleSession은 세션 채널의 exec, subsystem, signal 요청을 처리합니다
+func (s *Server) handleSession(channel cryptossh.Channel, requests <-chan *cryptossh.Request) {
+	defer channel.Close()
+
+	signaled := make(chan struct{})
+	var signalOnce sync.Once
+	var execDone chan struct{}
+
+	for req := range requests {
+		switch req.Type {
+		case "exec":
+			var payload struct{ Command string }
+			if err := cryptossh.Unmarshal(req.Payload, &payload); err != nil || execDone != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			execDone = make(chan struct{})
+			go func() {
+				defer close(execDone)
+				s.exec(channel, payload.Command, signaled)
+			}()
+		case "subsystem":
+			var payload struct{ Name string }
+			if err := cryptossh.Unmarshal(req.Payload, &payload); err != nil || payload.Name != "sftp" || execDone != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			execDone = make(chan struct{})
+			go func() {
+				defer close(execDone)
+				server := sftp.NewRequestServer(channel, s.sftp)
+				server.Serve()
+				server.Close()
+				channel.Close()
+			}()
+		case "signal":
+			signalOnce.Do(func() { close(signaled) })
+			if req.WantReply {
+				req.Reply(true, nil)
+			}
+		case "env", "pty-req":
+			req.Reply(true, nil)
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+
+	// 클라이언트가 채널을 닫으면 실행 중인 명령어도 중단합니다
+	signalOnce.Do(func() { close(signaled) })
+	if execDone != nil {
+		<-execDone
+	}
+}
+
+// exec는 명령어 하나를 실행하고 종료 상태를 전송합니다
+func (s *Server) exec(channel cryptossh.Channel, raw string, signaled <-chan struct{}) {
+	record := Exec{Raw: raw}
+	cmd := raw
+
+	// pkg/ssh의 sudo 방식 확인은 실행 기록에 남기지 않고 호스트의 sudo 설정대로 응답
+	if cmd == ssh.SudoProbeCommand {
+		exitCode := 1
+		if s.noPasswdSudo {
+			exitCode = 0
+		} else {
+			io.WriteString(channel.Stderr(), "sudo: a password is required\n")
+		}
+		status := make([]byte, 4)
+		binary.BigEndian.PutUint32(status, uint32(exitCode))
+		channel.SendRequest("exit-status", false, status)
+		channel.Close()
+		return
+	}
+
+	// 프로세스 그룹 보고 구문
+	var kill <-chan struct{}
+	if strings.HasPrefix(cmd, pgidPrefix) {
+		cmd = strings.TrimPrefix(cmd, pgidPrefix)
+		var pgid int
+		pgid, kill = s.startProcessGroup()
+		defer s.endProcessGroup(pgid)
+		fmt.Fprintf(channel.Stderr(), "%s%d\n", pgidMarker, pgid)
+	}
+
+	// sudo 비밀번호 읽기 구문 (비밀번호는 stdin의 첫 줄)
+	if strings.HasPrefix(cmd, sudoReadPrefix) {
+		if i := strings.Index(cmd, "; "); i >= 0 {
+			cmd = cmd[i+2:]
+		}
+		line, _ := bufio.NewReader(channel).ReadString('\n')
+		record.SudoPassword = strings.TrimSuffix(line, "\n")
+	}
+
+	// sudo 셸 함수 정의 구문
+	if strings.HasPrefix(cmd, sudoFuncPrefix) {
+		if i := strings.Index(cmd, sudoFuncSuffix); i >= 0 {
+			cmd = cmd[i+len(sudoFuncSuffix):]
+		}
+	}
+	record.Command = cmd
+
+	var resp Response
+	if m := killPattern.FindStringSubmatch(cmd); m != nil {
+		target, _ := strconv.Atoi(m[1])
+		s.killProcessGroup(target)
+	} else {
+		resp = s.lookup(cmd)
+	}
+
+	// 지연 중 시그널이나 kill을 받으면 중단
+	killed := false
+	if resp.Delay > 0 {
+		select {
+		case <-time.After(resp.Delay):
+		case <-signaled:
+			killed = true
+		case <-kill:
+			killed = true
+		case <-s.closed:
+			killed = true
+		}
+	}
+	record.Killed = killed
+
+	s.mu.Lock()
+	s.execs = append(s.execs, record)
+	s.mu.Unlock()
+
+	exitCode := resp.ExitCode
+	if killed {
+		exitCode = 143
+	} else {
+		io.WriteString(channel, resp.Stdout)
+		io.WriteString(channel.Stderr(), resp.Stderr)
+	}
+
+	status := make([]byte, 4)
+	binary.BigEndian.PutUint32(status, uint32(exitCode))
+	channel.SendRequest("exit-status", false, status)
+	channel.Close()
+}
+
+// lookup은 명령어에 대한 응답을 찾습니다. 없으면 셸의 command not found 응답을 반환합니다
+func (s *Server) lookup(cmd string) Response {
+	s.mu.Lock()
+	resp, ok := s.exact[cmd]
+	handlers := append([]HandlerFunc(nil), s.handlers...)
+	s.mu.Unlock()
+	if ok {
+		return resp
+	}
+
+	for _, handler := range handlers {
+		if resp, ok := handler(cmd); ok {
+			return resp
+		}
+	}
+
+	name := cmd
+	if fields := strings.Fields(cmd); len(fields) > 0 {
+		name = fields[0]
+	}
+	return Response{Stderr: fmt.Sprintf("sh: 1: %s: not found\n", name), ExitCode: 127}
+}
+
+// startProcessGroup은 새 가짜 프로세스 그룹 ID와, 그룹이 kill되면 닫히는 채널을 할당합니다
+func (s *Server) startProcessGroup() (int, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextPGID++
+	kill := make(chan struct{})
+	s.running[s.nextPGID] = kill
+	return s.nextPGID, kill
+}
+
+// endProcessGroup은 종료된 프로세스 그룹을 정리합니다
+func (s *Server) endProcessGroup(pgid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, pgid)
+}
+
+// killProcessGroup은 실행 중인 프로세스 그룹을 중단합니다
+func (s *Server) killProcessGroup(pgid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.running[pgid]; ok {
+		close(ch)
+		delete(s.running, pgid)
+	}
+}
@@ -0,0 +1,161 @@
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType은 명령어 실행 중 발생하는 이벤트 종류입니다
+type EventType string
+
+const (
+	EventCommandStart EventType = "start"  // 명령어 실행 시작
+	EventOutput       EventType = "output" // stdout/stderr 한 줄 출력
+	EventCommandExit  EventType = "exit"   // 명령어 종료 (ExitCode 포함)
+)
+
+// 출력 스트림 이름
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// OutputEvent는 명령어 실행 중 발생하는 스트리밍 이벤트입니다
+type OutputEvent struct {
+	Type         EventType `json:"type"`
+	Host         string    `json:"host,omitempty"` // 명령어를 실행한 최종 호스트
+	CommandIndex int       `json:"commandIndex"`   // finalCommands 내 명령어 순번 (0부터)
+	Command      string    `json:"command"`
+	Stream       string    `json:"stream,omitempty"` // stdout 또는 stderr (output 이벤트)
+	Line         string    `json:"line,omitempty"`   // 줄바꿈을 제외한 출력 한 줄 (output 이벤트)
+	ExitCode     int       `json:"exitCode"`         // 종료 코드 (exit 이벤트)
+	Time         time.Time `json:"time"`
+}
+
+// OutputHandler는 스트리밍 이벤트를 받는 콜백입니다
+// 한 번에 하나의 이벤트만 전달되므로 핸들러에서 별도의 동기화는 필요 없습니다
+type OutputHandler func(event OutputEvent)
+
+// ChannelHandler는 이벤트를 채널로 전달하는 OutputHandler를 생성합니다
+// 채널이 가득 차면 명령어 출력 읽기가 대기하므로 충분한 버퍼를 두거나 빠르게 소비해야 합니다
+func ChannelHandler(ch chan<- OutputEvent) OutputHandler {
+	return func(event OutputEvent) {
+		ch <- event
+	}
+}
+
+// outputHandlerKey는 컨텍스트에 OutputHandler를 저장할 때 사용하는 키입니다
+type outputHandlerKey struct{}
+
+// WithOutputHandler는 ctx로 실행되는 모든 명령어의 이벤트를 handler에도 전달하는 컨텍스트를 반환합니다
+// 호출부를 바꾸지 않고 여러 단계의 실행 과정을 한곳에서 기록할 때 사용하며,
+// 여러 실행이 동시에 이벤트를 보낼 수 있으므로 handler는 동시 호출에 안전해야 합니다
+func WithOutputHandler(ctx context.Context, handler OutputHandler) context.Context {
+	if parent := outputHandlerFromContext(ctx); parent != nil {
+		next := handler
+		handler = func(event OutputEvent) {
+			parent(event)
+			next(event)
+		}
+	}
+	return context.WithValue(ctx, outputHandlerKey{}, handler)
+}
+
+// outputHandlerFromContext는 WithOutputHandler로 등록된 handler를 반환합니다
+func outputHandlerFromContext(ctx context.Context) OutputHandler {
+	handler, _ := ctx.Value(outputHandlerKey{}).(OutputHandler)
+	return handler
+}
+
+// hostHandler는 handler와 컨텍스트의 handler에 host를 채운 이벤트를 한 번에 하나씩 전달하는 OutputHandler를 생성합니다
+func hostHandler(ctx context.Context, host string, handler OutputHandler) OutputHandler {
+	ctxHandler := outputHandlerFromContext(ctx)
+	if handler == nil && ctxHandler == nil {
+		return func(OutputEvent) {}
+	}
+
+	return synchronizedHandler(func(event OutputEvent) {
+		event.Host = host
+		if handler != nil {
+			handler(event)
+		}
+		if ctxHandler != nil {
+			ctxHandler(event)
+		}
+	})
+}
+
+// synchronizedHandler는 stdout/stderr 읽기 고루틴에서 동시에 호출되어도
+// 이벤트가 하나씩 전달되도록 handler를 감쌉니다. handler가 nil이면 아무 것도 하지 않습니다
+func synchronizedHandler(handler OutputHandler) OutputHandler {
+	if handler == nil {
+		return func(OutputEvent) {}
+	}
+
+	var mu sync.Mutex
+	return func(event OutputEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		handler(event)
+	}
+}
+
+// outputBuffer는 읽기 고루틴과 결과 수집이 동시에 접근할 수 있는 출력 버퍼입니다
+type outputBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+// WriteString은 버퍼에 문자열을 추가합니다
+func (b *outputBuffer) WriteString(s string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.WriteString(s)
+}
+
+// String은 지금까지 누적된 출력을 반환합니다
+func (b *outputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// readLines는 r에서 읽은 내용을 buf에 그대로 누적하면서 한 줄씩 onLine을 호출합니다
+// buf가 nil이면 누적하지 않고 줄바꿈이 포함된 원본 줄을 onLine에 전달합니다
+func readLines(r io.Reader, buf *outputBuffer, onLine func(line string)) {
+	reader := bufio.NewReader(r)
+	for {
+		chunk, err := reader.ReadString('\n')
+		if len(chunk) > 0 {
+			if buf == nil {
+				onLine(chunk)
+			} else {
+				buf.WriteString(chunk)
+				onLine(strings.TrimRight(chunk, "\r\n"))
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readLinesAfterMarker는 readLines와 같지만, marker로 시작하는 첫 줄은 결과에 포함하지 않고
+// marker 뒤의 값을 markerCh로 전달합니다
+func readLinesAfterMarker(r io.Reader, buf *outputBuffer, marker string, markerCh chan<- string, onLine func(line string)) {
+	found := false
+	readLines(r, nil, func(raw string) {
+		line := strings.TrimRight(raw, "\r\n")
+		if !found && strings.HasPrefix(line, marker) {
+			found = true
+			markerCh <- strings.TrimPrefix(line, marker)
+			return
+		}
+		buf.WriteString(raw)
+		onLine(line)
+	})
+}
@@ -0,0 +1,114 @@
+package ssh
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SudoMode는 최종 호스트에서 관리자 권한 명령어를 실행하는 방식입니다
+type SudoMode string
+
+const (
+	// SudoPassword는 sudo 비밀번호를 세션 stdin으로 전달합니다
+	SudoPassword SudoMode = "password"
+	// SudoNoPassword는 NOPASSWD로 설정된 sudo를 사용합니다
+	SudoNoPassword SudoMode = "nopasswd"
+	// SudoRoot는 root로 로그인한 호스트로, sudo 없이 명령어를 실행합니다
+	SudoRoot SudoMode = "root"
+)
+
+// sudoPattern은 명령어에 sudo 호출이 포함되어 있는지 확인합니다
+var sudoPattern = regexp.MustCompile(`(^|[^A-Za-z0-9_-])sudo([^A-Za-z0-9_-]|$)`)
+
+// SudoProbeCommand는 비밀번호 없이 sudo를 실행할 수 있는지 확인하는 명령어입니다
+const SudoProbeCommand = "sudo -n true"
+
+// sudoConfig는 명령어 실행 시 sudo 처리에 필요한 정보입니다
+type sudoConfig struct {
+	mode     SudoMode
+	password string
+}
+
+// newSudoConfig는 최종 hop 설정에서 sudo 처리 방식을 결정합니다
+// SudoMode가 비어 있으면 root 사용자는 root, 비밀번호가 있으면 password, 없으면 nopasswd로 간주합니다
+// 비밀번호가 있어도 NOPASSWD로 설정된 호스트는 Client.ProbeSudo로 먼저 확인해야 합니다
+func newSudoConfig(hop HopConfig) sudoConfig {
+	password := hop.SudoPassword
+	if password == "" {
+		password = hop.Password
+	}
+
+	mode := hop.SudoMode
+	if mode == "" {
+		switch {
+		case hop.Username == "root":
+			mode = SudoRoot
+		case password != "":
+			mode = SudoPassword
+		default:
+			mode = SudoNoPassword
+		}
+	}
+	return sudoConfig{mode: mode, password: password}
+}
+
+// wrap은 명령어 앞에 sudo 셸 함수를 정의하는 구문을 붙이고, 세션 stdin으로 보낼 내용을 반환합니다
+// 비밀번호는 명령줄이나 프로세스 목록에 나타나지 않도록 stdin으로만 전달되며, 셸 변수로 읽은 뒤
+// 내장 명령어 printf로 sudo에 넘깁니다. sudo가 포함되지 않은 명령어는 그대로 반환합니다
+func (s sudoConfig) wrap(cmd string) (string, io.Reader) {
+	if !sudoPattern.MatchString(cmd) {
+		return cmd, nil
+	}
+
+	switch s.mode {
+	case SudoRoot:
+		// 이미 root이므로 sudo 옵션을 제거하고 그대로 실행 (sudo가 없는 호스트 대응)
+		return `sudo() { while [ $# -gt 0 ]; do case "$1" in -S|-E|-H|-k|-n) shift ;; *) break ;; esac; done; env "$@"; }; ` + cmd, nil
+	case SudoNoPassword:
+		return `sudo() { command sudo -n "$@"; }; ` + cmd, nil
+	default:
+		// -k로 캐시된 인증을 무시해 항상 비밀번호를 읽게 하여, 비밀번호가 명령어 입력으로 새지 않도록 합니다
+		// 비밀번호 뒤에 원래 stdin을 이어 붙여 "... | sudo tee" 같은 파이프도 그대로 동작합니다
+		preamble := `IFS= read -r __k8scontrol_sudo_pw; ` +
+			`sudo() { { printf '%s\n' "$__k8scontrol_sudo_pw"; cat; } | command sudo -S -k -p '' "$@"; }; `
+		return preamble + cmd, strings.NewReader(s.password + "\n")
+	}
+}
+
+// ProbeSudo는 SudoMode가 비어 있어 password 모드로 판단되는 hop에서 비밀번호 없이 sudo를 실행할 수 있는지 확인합니다
+// NOPASSWD 호스트의 sudo -S는 비밀번호를 읽지 않아 비밀번호가 명령어의 입력으로 넘어가므로,
+// 확인에 성공하면 SudoMode를 nopasswd로 설정한 hop을 반환합니다. 그 외에는 hop을 그대로 반환합니다
+func (c *Client) ProbeSudo(hop HopConfig) HopConfig {
+	if hop.SudoMode != "" || newSudoConfig(hop).mode != SudoPassword {
+		return hop
+	}
+	session, err := c.NewSession()
+	if err != nil {
+		return hop
+	}
+	defer session.Close()
+	if session.Run(SudoProbeCommand) == nil {
+		hop.SudoMode = SudoNoPassword
+	}
+	return hop
+}
+
+// SudoCommand는 직접 생성한 세션에서 sudo가 포함된 명령어를 실행할 수 있도록 명령어를 변환하고 stdin을 설정합니다
+// hop은 같은 연결에서 Client.ProbeSudo로 확인한 값을 사용하며,
+// ExecuteCommands 계열 함수는 내부적으로 같은 처리를 하므로 별도로 호출할 필요가 없습니다
+func SudoCommand(session *ssh.Session, hop HopConfig, cmd string) string {
+	wrapped, stdin := newSudoConfig(hop).wrap(cmd)
+	if stdin != nil {
+		session.Stdin = stdin
+	}
+	return wrapped
+}
+
+// AsRoot는 명령어 전체를 관리자 권한으로 실행하도록 감쌉니다
+// 명령어 템플릿의 RunAsRoot 처리에 사용되며, 비밀번호는 실행 시 stdin으로 전달됩니다
+func AsRoot(cmd string) string {
+	return "sudo sh -c " + ShellQuote(cmd)
+}
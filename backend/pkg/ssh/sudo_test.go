@@ -0,0 +1,67 @@
+package ssh
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewSudoConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		hop          HopConfig
+		wantMode     SudoMode
+		wantPassword string
+	}{
+		{"root 사용자", HopConfig{Username: "root", Password: "pw"}, SudoRoot, "pw"},
+		{"로그인 비밀번호 사용", HopConfig{Username: "ubuntu", Password: "pw"}, SudoPassword, "pw"},
+		{"sudo 비밀번호 우선", HopConfig{Username: "ubuntu", Password: "pw", SudoPassword: "sudo-pw"}, SudoPassword, "sudo-pw"},
+		{"비밀번호 없음", HopConfig{Username: "ubuntu", PrivateKey: "key"}, SudoNoPassword, ""},
+		{"명시적 모드", HopConfig{Username: "ubuntu", Password: "pw", SudoMode: SudoNoPassword}, SudoNoPassword, "pw"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newSudoConfig(tt.hop)
+			if got.mode != tt.wantMode || got.password != tt.wantPassword {
+				t.Errorf("newSudoConfig = {%s %q}, want {%s %q}", got.mode, got.password, tt.wantMode, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestSudoWrap(t *testing.T) {
+	// sudo가 없는 명령어는 그대로 실행
+	cmd, stdin := sudoConfig{mode: SudoPassword, password: "pw"}.wrap("echo pseudo-sudo")
+	if cmd != "echo pseudo-sudo" || stdin != nil {
+		t.Errorf("sudo 없는 명령어 변환 = %q, stdin=%v", cmd, stdin)
+	}
+
+	// password 모드는 비밀번호를 stdin으로만 전달
+	cmd, stdin = sudoConfig{mode: SudoPassword, password: "s3cret"}.wrap("sudo kubectl get nodes")
+	if strings.Contains(cmd, "s3cret") {
+		t.Errorf("명령어에 비밀번호가 포함됨: %q", cmd)
+	}
+	if !strings.HasSuffix(cmd, "sudo kubectl get nodes") {
+		t.Errorf("원래 명령어가 유지되지 않음: %q", cmd)
+	}
+	data, _ := io.ReadAll(stdin)
+	if string(data) != "s3cret\n" {
+		t.Errorf("stdin = %q, want %q", data, "s3cret\n")
+	}
+
+	// nopasswd, root 모드는 stdin을 사용하지 않음
+	for _, mode := range []SudoMode{SudoNoPassword, SudoRoot} {
+		if _, stdin := (sudoConfig{mode: mode, password: "pw"}).wrap("sudo ls"); stdin != nil {
+			t.Errorf("%s 모드에서 stdin이 설정됨", mode)
+		}
+	}
+}
+
+func TestAsRoot(t *testing.T) {
+	got := AsRoot("echo 'a b' > /etc/x")
+	want := `sudo sh -c 'echo '\''a b'\'' > /etc/x'`
+	if got != want {
+		t.Errorf("AsRoot = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,420 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+const (
+	defaultFileMode os.FileMode = 0644
+	defaultDirMode  os.FileMode = 0755
+)
+
+// FileUpload는 원격 서버에 업로드할 파일 하나를 정의합니다
+type FileUpload struct {
+	RemotePath string      `json:"remote_path"`
+	Content    []byte      `json:"-"`
+	Mode       os.FileMode `json:"mode"` // 0이면 0644
+}
+
+// TransferResult는 파일 하나의 전송 결과입니다
+type TransferResult struct {
+	LocalPath  string      `json:"local_path,omitempty"`
+	RemotePath string      `json:"remote_path"`
+	Size       int64       `json:"size"`
+	Checksum   string      `json:"checksum"` // SHA256 (hex)
+	Mode       os.FileMode `json:"mode"`
+}
+
+// Upload는 r의 내용을 홉 체인의 최종 호스트 remotePath에 업로드합니다
+// 같은 디렉토리의 임시 파일에 쓰고 체크섬을 확인한 뒤 rename하므로 쓰는 도중의 파일이 노출되지 않습니다
+func (s *SSHService) Upload(ctx context.Context, hops []HopConfig, remotePath string, r io.Reader, mode os.FileMode, timeout time.Duration) (TransferResult, error) {
+	var result TransferResult
+	err := s.withSFTP(ctx, hops, timeout, func(client *Client, sc *sftp.Client) error {
+		var err error
+		result, err = uploadFile(client, sc, remotePath, r, mode)
+		return err
+	})
+	return result, err
+}
+
+// UploadFiles는 여러 파일을 하나의 SFTP 세션으로 업로드합니다
+func (s *SSHService) UploadFiles(ctx context.Context, hops []HopConfig, files []FileUpload, timeout time.Duration) ([]TransferResult, error) {
+	var results []TransferResult
+	err := s.withSFTP(ctx, hops, timeout, func(client *Client, sc *sftp.Client) error {
+		for _, file := range files {
+			result, err := uploadFile(client, sc, file.RemotePath, bytes.NewReader(file.Content), file.Mode)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// UploadDir는 로컬 디렉토리를 원격 remoteDir 아래에 재귀적으로 업로드합니다
+// 파일과 디렉토리의 권한은 로컬과 동일하게 설정하며, 일반 파일이 아닌 항목(심볼릭 링크 등)은 건너뜁니다
+func (s *SSHService) UploadDir(ctx context.Context, hops []HopConfig, localDir, remoteDir string, timeout time.Duration) ([]TransferResult, error) {
+	var results []TransferResult
+	err := s.withSFTP(ctx, hops, timeout, func(client *Client, sc *sftp.Client) error {
+		return filepath.WalkDir(localDir, func(localPath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(localDir, localPath)
+			if err != nil {
+				return err
+			}
+			remotePath := path.Join(remoteDir, filepath.ToSlash(rel))
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if err := sc.MkdirAll(remotePath); err != nil {
+					return transferError(hops, remotePath, "failed to create directory: %s", err)
+				}
+				if err := sc.Chmod(remotePath, info.Mode().Perm()); err != nil {
+					return transferError(hops, remotePath, "failed to set directory mode: %s", err)
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			f, err := os.Open(localPath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			result, err := uploadFile(client, sc, remotePath, f, info.Mode().Perm())
+			if err != nil {
+				return err
+			}
+			result.LocalPath = localPath
+			results = append(results, result)
+			return nil
+		})
+	})
+	return results, err
+}
+
+// Download는 원격 remotePath의 내용을 w로 내려받습니다
+// 원격 호스트에 sha256sum이 있으면 받은 내용의 체크섬과 비교합니다
+func (s *SSHService) Download(ctx context.Context, hops []HopConfig, remotePath string, w io.Writer, timeout time.Duration) (TransferResult, error) {
+	var result TransferResult
+	err := s.withSFTP(ctx, hops, timeout, func(client *Client, sc *sftp.Client) error {
+		var err error
+		result, err = downloadFile(client, sc, remotePath, w)
+		return err
+	})
+	return result, err
+}
+
+// ReadFile은 원격 파일 전체를 읽어 반환합니다
+func (s *SSHService) ReadFile(ctx context.Context, hops []HopConfig, remotePath string, timeout time.Duration) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := s.Download(ctx, hops, remotePath, &buf, timeout); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadDir는 원격 디렉토리를 로컬 localDir 아래에 재귀적으로 내려받습니다
+// 각 파일은 임시 파일에 받은 뒤 rename하며, 권한은 원격과 동일하게 설정합니다
+func (s *SSHService) DownloadDir(ctx context.Context, hops []HopConfig, remoteDir, localDir string, timeout time.Duration) ([]TransferResult, error) {
+	var results []TransferResult
+	err := s.withSFTP(ctx, hops, timeout, func(client *Client, sc *sftp.Client) error {
+		walker := sc.Walk(remoteDir)
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				return transferError(hops, walker.Path(), "failed to walk directory: %s", err)
+			}
+
+			rel := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), remoteDir), "/")
+			localPath := filepath.Join(localDir, filepath.FromSlash(rel))
+			info := walker.Stat()
+
+			if info.IsDir() {
+				if err := os.MkdirAll(localPath, info.Mode().Perm()); err != nil {
+					return err
+				}
+				continue
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+
+			result, err := downloadToLocalFile(client, sc, walker.Path(), localPath)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// withSFTP는 홉 체인 연결을 빌려 SFTP 세션을 열고 fn을 실행합니다
+// ctx가 취소되거나 timeout이 지나면 SFTP 세션을 닫아 진행 중인 전송을 중단합니다
+func (s *SSHService) withSFTP(ctx context.Context, hops []HopConfig, timeout time.Duration, fn func(client *Client, sc *sftp.Client) error) error {
+	if timeout == 0 {
+		timeout = DefaultTimeout()
+	}
+
+	if len(hops) == 0 {
+		return SSHError{
+			Type:    ValidationError,
+			Message: "At least one hop configuration is required",
+		}
+	}
+	host := hops[len(hops)-1].Host
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := s.AcquireContext(ctx, hops, timeout)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	sc, err := sftp.NewClient(client.Client)
+	if err != nil {
+		return SSHError{
+			Type:    TransferFailed,
+			Message: fmt.Sprintf("Failed to start SFTP session: %s", err.Error()),
+			Host:    host,
+			Err:     err,
+		}
+	}
+	defer sc.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		sc.Close()
+	})
+	defer stop()
+
+	if err := fn(client, sc); err != nil {
+		if ctx.Err() != nil {
+			return canceledError(ctx, host, "")
+		}
+		if errors.As(err, new(SSHError)) {
+			return err
+		}
+		return SSHError{
+			Type:    TransferFailed,
+			Message: err.Error(),
+			Host:    host,
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// uploadFile은 임시 파일에 업로드하고 권한 설정과 체크섬 확인 후 대상 경로로 rename합니다
+func uploadFile(client *Client, sc *sftp.Client, remotePath string, r io.Reader, mode os.FileMode) (TransferResult, error) {
+	result := TransferResult{RemotePath: remotePath}
+	if mode == 0 {
+		mode = defaultFileMode
+	}
+
+	dir := path.Dir(remotePath)
+	if err := sc.MkdirAll(dir); err != nil {
+		return result, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmpPath := path.Join(dir, fmt.Sprintf(".%s.tmp-%s", path.Base(remotePath), randomSuffix()))
+	f, err := sc.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return result, fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(f, io.TeeReader(r, h))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		sc.Remove(tmpPath)
+		return result, fmt.Errorf("failed to write %s: %w", remotePath, err)
+	}
+
+	if err := sc.Chmod(tmpPath, mode); err != nil {
+		sc.Remove(tmpPath)
+		return result, fmt.Errorf("failed to set mode on %s: %w", remotePath, err)
+	}
+
+	checksum := hex.EncodeToString(h.Sum(nil))
+	remoteChecksum, ok := remoteSHA256(client, tmpPath)
+	if !ok {
+		// sha256sum이 없는 호스트는 SFTP로 다시 읽어 확인
+		remoteChecksum, err = sftpSHA256(sc, tmpPath)
+		if err != nil {
+			sc.Remove(tmpPath)
+			return result, fmt.Errorf("failed to verify %s: %w", remotePath, err)
+		}
+	}
+	if remoteChecksum != checksum {
+		sc.Remove(tmpPath)
+		return result, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", remotePath, checksum, remoteChecksum)
+	}
+
+	if err := sc.PosixRename(tmpPath, remotePath); err != nil {
+		// posix-rename 확장을 지원하지 않는 서버는 기존 파일을 지우고 rename
+		sc.Remove(remotePath)
+		if err := sc.Rename(tmpPath, remotePath); err != nil {
+			sc.Remove(tmpPath)
+			return result, fmt.Errorf("failed to rename %s: %w", remotePath, err)
+		}
+	}
+
+	result.Size = size
+	result.Checksum = checksum
+	result.Mode = mode
+	return result, nil
+}
+
+// downloadFile은 원격 파일을 w로 복사하면서 체크섬을 계산합니다
+func downloadFile(client *Client, sc *sftp.Client, remotePath string, w io.Writer) (TransferResult, error) {
+	result := TransferResult{RemotePath: remotePath}
+
+	f, err := sc.Open(remotePath)
+	if err != nil {
+		return result, fmt.Errorf("failed to open %s: %w", remotePath, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return result, fmt.Errorf("failed to stat %s: %w", remotePath, err)
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, h), f)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", remotePath, err)
+	}
+
+	checksum := hex.EncodeToString(h.Sum(nil))
+	if remoteChecksum, ok := remoteSHA256(client, remotePath); ok && remoteChecksum != checksum {
+		return result, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", remotePath, remoteChecksum, checksum)
+	}
+
+	result.Size = size
+	result.Checksum = checksum
+	result.Mode = info.Mode().Perm()
+	return result, nil
+}
+
+// downloadToLocalFile은 원격 파일을 로컬 임시 파일에 받은 뒤 localPath로 rename합니다
+func downloadToLocalFile(client *Client, sc *sftp.Client, remotePath, localPath string) (TransferResult, error) {
+	if err := os.MkdirAll(filepath.Dir(localPath), defaultDirMode); err != nil {
+		return TransferResult{RemotePath: remotePath}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".tmp-*")
+	if err != nil {
+		return TransferResult{RemotePath: remotePath}, err
+	}
+	tmpPath := tmp.Name()
+
+	result, err := downloadFile(client, sc, remotePath, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, result.Mode)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, localPath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return result, err
+	}
+
+	result.LocalPath = localPath
+	return result, nil
+}
+
+// remoteSHA256은 원격 호스트의 sha256sum으로 파일 체크섬을 계산합니다
+// 명령어를 사용할 수 없으면 false를 반환합니다
+func remoteSHA256(client *Client, remotePath string) (string, bool) {
+	session, err := client.NewSession()
+	if err != nil {
+		return "", false
+	}
+	defer session.Close()
+
+	output, err := session.Output("sha256sum " + ShellQuote(remotePath))
+	if err != nil {
+		return "", false
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", false
+	}
+	return fields[0], true
+}
+
+// sftpSHA256은 SFTP로 원격 파일을 다시 읽어 체크섬을 계산합니다
+func sftpSHA256(sc *sftp.Client, remotePath string) (string, error) {
+	f, err := sc.Open(remotePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// transferError는 전송 실패 오류를 생성합니다
+func transferError(hops []HopConfig, remotePath, format string, err error) SSHError {
+	return SSHError{
+		Type:    TransferFailed,
+		Message: fmt.Sprintf("%s: "+format, remotePath, err.Error()),
+		Host:    hops[len(hops)-1].Host,
+		Err:     err,
+	}
+}
+
+// randomSuffix는 임시 파일 이름에 사용할 무작위 문자열을 생성합니다
+func randomSuffix() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ShellQuote는 문자열을 원격 셸에서 하나의 인자로 해석되도록 작은따옴표로 감쌉니다
+// 작은따옴표 안에서는 어떤 문자도 해석되지 않으므로 명령어에 넣는 모든 외부 값에 사용합니다
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}